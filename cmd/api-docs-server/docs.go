@@ -0,0 +1,159 @@
+// cmd/api-docs-server/docs.go
+// Serving the document and the "try it" proxy
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tryPrefix is where the UI sends "try it" requests; the rest of the path
+// is forwarded to the live server unchanged
+const tryPrefix = "/try"
+
+type docsServer struct {
+	cfg    config
+	client *http.Client
+	proxy  *httputil.ReverseProxy
+
+	mu        sync.Mutex
+	spec      []byte
+	fetchedAt time.Time
+}
+
+func newDocsServer(cfg config) *docsServer {
+	d := &docsServer{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+	d.proxy = &httputil.ReverseProxy{Rewrite: d.rewrite}
+	return d
+}
+
+// rewrite forwards a "try it" request to the live server. The configured
+// tenant credentials are only added to requests without their own and
+// never to the admin API; browser cookies are not forwarded.
+func (d *docsServer) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(d.cfg.Endpoint)
+	path := strings.TrimPrefix(pr.In.URL.Path, tryPrefix)
+	pr.Out.URL.Path = strings.TrimRight(d.cfg.Endpoint.Path, "/") + path
+	pr.Out.URL.RawPath = ""
+	pr.Out.Header.Del("Cookie")
+	pr.SetXForwarded()
+
+	if strings.HasPrefix(path, "/v1/admin/") || pr.Out.Header.Get("Authorization") != "" {
+		return
+	}
+	if d.cfg.TenantToken != "" {
+		pr.Out.Header.Set("Authorization", "Bearer "+d.cfg.TenantToken)
+	}
+	if d.cfg.TenantID != "" && pr.Out.Header.Get("X-Tenant-ID") == "" && pr.Out.URL.Query().Get("tenant_id") == "" {
+		pr.Out.Header.Set("X-Tenant-ID", d.cfg.TenantID)
+	}
+}
+
+// document returns the decorated OpenAPI document, refetching it from the
+// live server once the cached copy is older than SpecTTL. A stale copy is
+// served if the server cannot be reached.
+func (d *docsServer) document() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.spec != nil && time.Since(d.fetchedAt) < d.cfg.SpecTTL {
+		return d.spec, nil
+	}
+	doc, err := d.fetch()
+	if err != nil {
+		if d.spec != nil {
+			return d.spec, nil
+		}
+		return nil, err
+	}
+
+	decorate(doc, d.cfg)
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	d.spec, d.fetchedAt = spec, time.Now()
+	return spec, nil
+}
+
+func (d *docsServer) fetch() (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, d.cfg.Endpoint.String()+"/v1/admin/openapi.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.cfg.AdminToken)
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch OpenAPI document: %s", resp.Status)
+	}
+
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// decorate points the document's server at the proxy and attaches a Go SDK
+// example to each operation the SDK covers
+func decorate(doc map[string]interface{}, cfg config) {
+	doc["servers"] = []interface{}{map[string]interface{}{
+		"url":         tryPrefix,
+		"description": "Proxied to " + cfg.Endpoint.String(),
+	}}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		ops, _ := item.(map[string]interface{})
+		for method, op := range ops {
+			op, ok := op.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			snippet, ok := goSnippet(strings.ToUpper(method), path, cfg)
+			if !ok {
+				continue
+			}
+			op["x-codeSamples"] = []interface{}{map[string]interface{}{
+				"lang": "Go", "label": "Go SDK", "source": snippet,
+			}}
+			// Swagger UI ignores x-codeSamples, so repeat it in the description
+			desc, _ := op["description"].(string)
+			op["description"] = strings.TrimSpace(desc + "\n\n**Go SDK**\n\n```go\n" + snippet + "\n```")
+		}
+	}
+}
+
+func (d *docsServer) handleSpec(w http.ResponseWriter, r *http.Request) {
+	spec, err := d.document()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(spec)
+}
+
+func (d *docsServer) handleSwagger(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerPage))
+}
+
+func (d *docsServer) handleRedoc(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(redocPage))
+}
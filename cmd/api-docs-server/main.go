@@ -0,0 +1,81 @@
+// cmd/api-docs-server/main.go
+// Interactive API documentation backed by a live server. The OpenAPI
+// document is fetched from the server's /v1/admin/openapi.json, decorated
+// with Go SDK examples and rendered with Swagger UI or Redoc; "try it"
+// requests are proxied to the same server with the configured tenant's
+// credentials.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// config is read from flags, defaulting to MINIO_DOCS_* variables
+type config struct {
+	Listen string
+
+	// Endpoint is the live server, e.g. https://minio.example.com:9000
+	Endpoint *url.URL
+
+	// AdminToken fetches the OpenAPI document; it is never forwarded
+	AdminToken string
+
+	// TenantID and TenantToken are added to proxied requests that carry no
+	// credentials of their own
+	TenantID    string
+	TenantToken string
+
+	// SpecTTL is how long a fetched document is served before refetching
+	SpecTTL time.Duration
+}
+
+func main() {
+	listen := flag.String("listen", envOr("MINIO_DOCS_LISTEN", ":8080"), "address to serve the docs on")
+	endpoint := flag.String("endpoint", envOr("MINIO_DOCS_ENDPOINT", "http://localhost:9000"), "live server to document and proxy to")
+	adminToken := flag.String("admin-token", os.Getenv("MINIO_DOCS_ADMIN_TOKEN"), "admin token for fetching the OpenAPI document")
+	tenantID := flag.String("tenant", os.Getenv("MINIO_DOCS_TENANT_ID"), "tenant for \"try it\" requests")
+	tenantToken := flag.String("tenant-token", os.Getenv("MINIO_DOCS_TENANT_TOKEN"), "tenant token for \"try it\" requests")
+	specTTL := flag.Duration("spec-ttl", time.Minute, "how long to cache the OpenAPI document")
+	flag.Parse()
+
+	target, err := url.Parse(strings.TrimRight(*endpoint, "/"))
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		log.Fatalf("invalid endpoint %q", *endpoint)
+	}
+	cfg := config{
+		Listen:      *listen,
+		Endpoint:    target,
+		AdminToken:  *adminToken,
+		TenantID:    *tenantID,
+		TenantToken: *tenantToken,
+		SpecTTL:     *specTTL,
+	}
+	if cfg.AdminToken == "" {
+		log.Fatal("an admin token is required to fetch the OpenAPI document")
+	}
+
+	docs := newDocsServer(cfg)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", docs.handleSwagger)
+	mux.HandleFunc("/redoc", docs.handleRedoc)
+	mux.HandleFunc("/openapi.json", docs.handleSpec)
+	mux.Handle(tryPrefix+"/", docs.proxy)
+
+	log.Printf("API docs for %s on %s", cfg.Endpoint, cfg.Listen)
+	server := &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Fatal(server.ListenAndServe())
+}
+
+// envOr returns the variable's value, or def if it is unset
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
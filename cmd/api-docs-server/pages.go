@@ -0,0 +1,65 @@
+// cmd/api-docs-server/pages.go
+// Swagger UI and Redoc pages, both rendering /openapi.json
+package main
+
+const swaggerPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>MinIO Enterprise API - Swagger UI</title>
+    <link rel="stylesheet" type="text/css" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css" />
+    <style>
+        body { margin: 0; padding: 0; }
+        .topbar { display: none; }
+        .swagger-ui .info { margin: 20px 0; }
+        .docs-nav { font-family: sans-serif; padding: 8px 20px; text-align: right; }
+    </style>
+</head>
+<body>
+    <div class="docs-nav"><a href="./redoc">Redoc</a> · <a href="./openapi.json">openapi.json</a></div>
+    <div id="swagger-ui"></div>
+
+    <script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js"></script>
+    <script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-standalone-preset.js"></script>
+    <script>
+        window.onload = function() {
+            window.ui = SwaggerUIBundle({
+                url: "./openapi.json",
+                dom_id: '#swagger-ui',
+                deepLinking: true,
+                presets: [
+                    SwaggerUIBundle.presets.apis,
+                    SwaggerUIStandalonePreset
+                ],
+                layout: "StandaloneLayout",
+                defaultModelsExpandDepth: 1,
+                defaultModelExpandDepth: 1,
+                docExpansion: "list",
+                displayRequestDuration: true,
+                filter: true,
+                showRequestHeaders: true,
+                tryItOutEnabled: true
+            });
+        };
+    </script>
+</body>
+</html>
+`
+
+const redocPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>MinIO Enterprise API - Redoc</title>
+    <style>
+        body { margin: 0; padding: 0; }
+    </style>
+</head>
+<body>
+    <redoc spec-url="./openapi.json"></redoc>
+    <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
@@ -0,0 +1,272 @@
+// cmd/bench/main.go
+// Load generator for a running server: workers upload, download and delete
+// objects across bench tenants, and the run reports throughput and latency
+// per operation. With -soak it runs for hours, churning tenants and kicking
+// anti-entropy alongside the object load, and fails if the server leaks
+// goroutines, its RSS grows without bound or its latency drifts.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", envOr("MINIO_ENDPOINT", "http://localhost:9000"), "server URL")
+	metricsURL := flag.String("metrics", "http://localhost:9001/metrics", "server metrics URL, sampled in soak mode")
+	token := flag.String("token", os.Getenv("MINIO_ADMIN_TOKEN"), "admin token, to create and delete the bench tenants")
+	tenants := flag.Int("tenants", 4, "bench tenants to spread the workers over")
+	workers := flag.Int("workers", 16, "concurrent workers")
+	keys := flag.Int("keys", 100, "objects per worker")
+	size := flag.String("size", "64K", "object size")
+	mixFlag := flag.String("mix", "put=40,get=50,delete=10", "operation weights")
+	duration := flag.Duration("duration", time.Minute, "how long to run (4h with -soak unless set)")
+	soak := flag.Bool("soak", false, "run a soak: churn tenants and replication too, and check for leaks and drift")
+	sample := flag.Duration("sample", time.Minute, "soak: interval between samples and latency windows")
+	warmup := flag.Duration("warmup", 10*time.Minute, "soak: time before the baseline sample")
+	churn := flag.Duration("churn", time.Minute, "soak: interval between tenant churn cycles and anti-entropy kicks")
+	settleFor := flag.Duration("settle", 30*time.Second, "soak: how long goroutines get to return to the baseline after the load stops")
+	maxGoroutines := flag.Int64("max-goroutine-growth", 50, "soak: goroutines allowed over the baseline once the load stops")
+	maxRSS := flag.Float64("max-rss-growth", 0.5, "soak: RSS growth allowed over the baseline, as a fraction")
+	maxDrift := flag.Float64("max-latency-drift", 1.0, "soak: p99 growth allowed from the first window to the last, as a fraction")
+	asJSON := flag.Bool("json", false, "print results as JSON")
+	flag.Parse()
+
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	if *soak && !setFlags["duration"] {
+		*duration = 4 * time.Hour
+	}
+	if !*soak {
+		*sample = min(*sample, *duration)
+	}
+	if *token == "" {
+		fmt.Fprintf(os.Stderr, "-token (or MINIO_ADMIN_TOKEN) is required to create the bench tenants\n")
+		os.Exit(2)
+	}
+	payloadSize, err := parseBytes(*size)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-size: %v\n", err)
+		os.Exit(2)
+	}
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-mix: %v\n", err)
+		os.Exit(2)
+	}
+	if *tenants <= 0 || *workers <= 0 || *keys <= 0 || *sample <= 0 {
+		fmt.Fprintf(os.Stderr, "-tenants, -workers, -keys and -sample must be positive\n")
+		os.Exit(2)
+	}
+	limits := soakLimits{Warmup: *warmup, Settle: *settleFor, GoroutineGrowth: *maxGoroutines, RSSGrowth: *maxRSS, LatencyDrift: *maxDrift}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	hc := &http.Client{Timeout: time.Minute, Transport: &http.Transport{MaxIdleConnsPerHost: *workers + 2}}
+	w := &workload{
+		client:  &client{endpoint: strings.TrimRight(*endpoint, "/"), token: *token, http: hc},
+		rec:     newRecorder(),
+		mix:     mix,
+		keys:    *keys,
+		payload: make([]byte, payloadSize),
+	}
+	for i := 0; i < *tenants; i++ {
+		id, err := w.client.createTenant(ctx, "bench-"+strconv.Itoa(i))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		w.tenants = append(w.tenants, id)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.worker(runCtx, i)
+		}(i)
+	}
+	if *soak {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.background(runCtx, *churn)
+		}()
+	}
+
+	start := time.Now()
+	var result soakResult
+	ticker := time.NewTicker(*sample)
+	for running := true; running; {
+		select {
+		case <-runCtx.Done():
+			running = false
+			continue
+		case <-ticker.C:
+		}
+		ops := summarize(w.rec.rotate())
+		win := window{Server: serverSample{At: time.Since(start)}, Ops: ops}
+		if *soak {
+			s, err := scrape(ctx, hc, *metricsURL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			s.At = win.Server.At
+			win.Server = s
+			result.Windows = append(result.Windows, win)
+		}
+		if !*asJSON {
+			progress(win, *sample, *soak)
+		}
+	}
+	ticker.Stop()
+	wg.Wait()
+	elapsed := time.Since(start)
+	w.rec.rotate() // The partial last window counts only in the totals
+
+	cleanup := context.WithoutCancel(ctx)
+	for _, id := range w.tenants {
+		if err := w.client.deleteTenant(cleanup, id); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	if *soak && len(result.Windows) > 0 {
+		base := result.Windows[0].Server.Goroutines
+		for _, win := range result.Windows {
+			if win.Server.At >= limits.Warmup {
+				base = win.Server.Goroutines
+				break
+			}
+		}
+		if final, err := settle(cleanup, hc, *metricsURL, base, limits); err == nil {
+			final.At = time.Since(start)
+			result.Final = &final
+		}
+		result.check(limits)
+	}
+
+	totals := summarize(w.rec.total)
+	if *asJSON {
+		out := map[string]interface{}{"elapsed_ns": elapsed, "ops": totals}
+		if *soak {
+			out["soak"] = result
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(out)
+	} else {
+		report(totals, elapsed, result, *soak)
+	}
+	if len(result.Failures) > 0 {
+		os.Exit(1)
+	}
+}
+
+// progress prints one window's throughput and, in a soak, the server sample
+func progress(win window, interval time.Duration, soak bool) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%8s]", win.Server.At.Round(time.Second))
+	for _, s := range win.Ops {
+		fmt.Fprintf(&b, " %s %.0f/s p99 %s", s.Op, float64(s.Count)/interval.Seconds(), s.P99)
+		if s.Errors > 0 {
+			fmt.Fprintf(&b, " (%d errors)", s.Errors)
+		}
+	}
+	if soak {
+		fmt.Fprintf(&b, " | goroutines %d heap %s", win.Server.Goroutines, formatBytes(win.Server.HeapInuse))
+		if win.Server.RSS > 0 {
+			fmt.Fprintf(&b, " rss %s", formatBytes(win.Server.RSS))
+		}
+	}
+	fmt.Fprintln(os.Stderr, b.String())
+}
+
+func report(totals []opStats, elapsed time.Duration, result soakResult, soak bool) {
+	fmt.Printf("\n%s elapsed\n\n", elapsed.Round(time.Second))
+	fmt.Printf("%-13s %10s %8s %10s %10s %10s\n", "op", "count", "errors", "ops/s", "p50", "p99")
+	for _, s := range totals {
+		fmt.Printf("%-13s %10d %8d %10.1f %10s %10s\n", s.Op, s.Count, s.Errors, float64(s.Count)/elapsed.Seconds(), s.P50, s.P99)
+	}
+	if !soak {
+		return
+	}
+
+	if result.Baseline != nil && result.Final != nil {
+		var peak int64
+		for _, w := range result.Windows {
+			peak = max(peak, w.Server.RSS)
+		}
+		fmt.Printf("\ngoroutines: %d at baseline, %d after the load stopped\n", result.Baseline.Goroutines, result.Final.Goroutines)
+		if peak > 0 {
+			fmt.Printf("rss: %s at baseline, %s peak\n", formatBytes(result.Baseline.RSS), formatBytes(peak))
+		}
+	}
+	if len(result.Failures) == 0 {
+		fmt.Println("\nsoak passed")
+		return
+	}
+	fmt.Println("\nsoak failed:")
+	for _, f := range result.Failures {
+		fmt.Printf("  - %s\n", f)
+	}
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// parseBytes reads a byte count with an optional K, M, G or T (binary) suffix
+func parseBytes(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	shift := 0
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	case "T":
+		shift = 40
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
+
+// formatBytes prints n in the largest binary unit it fills
+func formatBytes(n int64) string {
+	for _, u := range []struct {
+		shift  uint
+		suffix string
+	}{{40, "T"}, {30, "G"}, {20, "M"}, {10, "K"}} {
+		if n >= 1<<u.shift {
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(int64(1)<<u.shift), u.suffix)
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
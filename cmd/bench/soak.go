@@ -0,0 +1,165 @@
+// cmd/bench/soak.go
+// Soak checks. The server's goroutines and memory are sampled from its
+// metrics endpoint every window; once warmed up, a baseline is taken, and
+// the run fails if goroutines do not return to it after the load stops,
+// if RSS climbs past a bound over it, or if p99 latency drifts from the
+// first window to the last
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverSample is the server's process metrics at one moment
+type serverSample struct {
+	At         time.Duration `json:"at_ns"` // Since the start of the run
+	Goroutines int64         `json:"goroutines"`
+	HeapInuse  int64         `json:"heap_inuse_bytes"`
+	RSS        int64         `json:"rss_bytes,omitempty"` // Zero where the server cannot report it
+}
+
+// scrape reads the process gauges from a Prometheus text endpoint
+func scrape(ctx context.Context, hc *http.Client, metricsURL string) (serverSample, error) {
+	var s serverSample
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL, nil)
+	if err != nil {
+		return s, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return s, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s, fmt.Errorf("metrics: %s", resp.Status)
+	}
+
+	found := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || strings.HasPrefix(name, "#") {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "go_goroutines":
+			s.Goroutines, found = n, true
+		case "go_memstats_heap_inuse_bytes":
+			s.HeapInuse = n
+		case "process_resident_memory_bytes":
+			s.RSS = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return s, err
+	}
+	if !found {
+		return s, fmt.Errorf("metrics: no go_goroutines gauge; is the server too old?")
+	}
+	return s, nil
+}
+
+// soakLimits bound what a soak may leak or drift
+type soakLimits struct {
+	Warmup          time.Duration
+	Settle          time.Duration // How long goroutines get to return to the baseline
+	GoroutineGrowth int64
+	RSSGrowth       float64 // Fraction over the baseline
+	LatencyDrift    float64 // Fraction of p99 growth, first window to last
+}
+
+// latencyFloor keeps drift checks from failing on sub-millisecond noise
+const latencyFloor = time.Millisecond
+
+// window is one sampling interval of a soak
+type window struct {
+	Server serverSample `json:"server"`
+	Ops    []opStats    `json:"ops"`
+}
+
+// soakResult is a soak's samples and the checks that failed
+type soakResult struct {
+	Baseline *serverSample `json:"baseline,omitempty"`
+	Final    *serverSample `json:"final,omitempty"`
+	Windows  []window      `json:"windows"`
+	Failures []string      `json:"failures"`
+}
+
+// check evaluates the limits over a soak's windows and the final sample,
+// taken after the load stopped
+func (r *soakResult) check(limits soakLimits) {
+	var warm []window
+	for _, w := range r.Windows {
+		if w.Server.At >= limits.Warmup {
+			warm = append(warm, w)
+		}
+	}
+	if len(warm) < 2 {
+		r.Failures = append(r.Failures, fmt.Sprintf("only %d samples after warmup; run longer or sample more often", len(warm)))
+		return
+	}
+	base := warm[0].Server
+	r.Baseline = &base
+
+	if r.Final != nil && r.Final.Goroutines > base.Goroutines+limits.GoroutineGrowth {
+		r.Failures = append(r.Failures, fmt.Sprintf("goroutines leaked: %d at baseline, %d %s after the load stopped",
+			base.Goroutines, r.Final.Goroutines, limits.Settle))
+	}
+
+	if base.RSS > 0 {
+		bound := int64(float64(base.RSS) * (1 + limits.RSSGrowth))
+		for _, w := range warm {
+			if w.Server.RSS > bound {
+				r.Failures = append(r.Failures, fmt.Sprintf("RSS unbounded: %s at baseline, %s at %s",
+					formatBytes(base.RSS), formatBytes(w.Server.RSS), w.Server.At.Round(time.Second)))
+				break
+			}
+		}
+	}
+
+	first, last := p99s(warm[0].Ops), p99s(warm[len(warm)-1].Ops)
+	for _, op := range []string{opPut, opGet, opDelete} {
+		from, to := first[op], last[op]
+		if from == 0 || to == 0 {
+			continue
+		}
+		if to > time.Duration(float64(max(from, latencyFloor))*(1+limits.LatencyDrift)) {
+			r.Failures = append(r.Failures, fmt.Sprintf("%s p99 drifted from %s to %s", op, from, to))
+		}
+	}
+}
+
+func p99s(ops []opStats) map[string]time.Duration {
+	out := make(map[string]time.Duration, len(ops))
+	for _, s := range ops {
+		out[s.Op] = s.P99
+	}
+	return out
+}
+
+// settle polls the server until its goroutines are back within limit of
+// the baseline or the settle time runs out, returning the last sample
+func settle(ctx context.Context, hc *http.Client, metricsURL string, base int64, limits soakLimits) (serverSample, error) {
+	deadline := time.Now().Add(limits.Settle)
+	for {
+		s, err := scrape(ctx, hc, metricsURL)
+		if err != nil || s.Goroutines <= base+limits.GoroutineGrowth || time.Now().After(deadline) {
+			return s, err
+		}
+		select {
+		case <-ctx.Done():
+			return s, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
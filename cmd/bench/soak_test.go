@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Soak checks fail past each limit, pass at it, and skip what the server
+// does not report
+func TestSoakCheck(t *testing.T) {
+	limits := soakLimits{Warmup: time.Minute, Settle: 30 * time.Second, GoroutineGrowth: 10, RSSGrowth: 0.5, LatencyDrift: 1}
+	sample := func(at time.Duration, goroutines, rss int64) serverSample {
+		return serverSample{At: at, Goroutines: goroutines, HeapInuse: 1 << 20, RSS: rss}
+	}
+	p99 := func(put, get time.Duration) []opStats {
+		return []opStats{{Op: opPut, Count: 100, P99: put}, {Op: opGet, Count: 100, P99: get}}
+	}
+	// windows returns two samples after warmup, preceded by a warmup one
+	// that must be ignored. The get p99 grows twentyfold but stays within
+	// the drift allowed over the latency floor.
+	windows := func(last serverSample, putP99 time.Duration) []window {
+		return []window{
+			{Server: sample(0, 5000, 10<<30), Ops: p99(time.Second, time.Second)},
+			{Server: sample(time.Minute, 100, 100<<20), Ops: p99(10*time.Millisecond, 100*time.Microsecond)},
+			{Server: last, Ops: p99(putP99, 2*time.Millisecond)},
+		}
+	}
+	final := func(goroutines int64) *serverSample {
+		s := sample(11*time.Minute, goroutines, 0)
+		return &s
+	}
+
+	for _, tc := range []struct {
+		name    string
+		windows []window
+		final   *serverSample
+		fail    string // Substring of the only failure, empty to pass
+	}{
+		{"pass", windows(sample(10*time.Minute, 500, 120<<20), 12*time.Millisecond), final(105), ""},
+		{"at limits", windows(sample(10*time.Minute, 500, 150<<20), 20*time.Millisecond), final(110), ""},
+		{"goroutines leaked", windows(sample(10*time.Minute, 500, 120<<20), 12*time.Millisecond), final(111), "goroutines leaked: 100 at baseline, 111"},
+		{"RSS unbounded", windows(sample(10*time.Minute, 500, 151<<20), 12*time.Millisecond), final(100), "RSS unbounded"},
+		{"latency drift", windows(sample(10*time.Minute, 500, 120<<20), 21*time.Millisecond), final(100), "put p99 drifted"},
+		{"no RSS", windows(sample(10*time.Minute, 500, 0), 12*time.Millisecond), final(100), ""},
+		{"no final sample", windows(sample(10*time.Minute, 500, 120<<20), 12*time.Millisecond), nil, ""},
+		{"too short", windows(sample(30*time.Second, 500, 120<<20), 12*time.Millisecond)[:2], final(100), "only 1 samples after warmup"},
+	} {
+		r := &soakResult{Windows: tc.windows, Final: tc.final}
+		r.check(limits)
+		switch {
+		case tc.fail == "" && len(r.Failures) > 0:
+			t.Errorf("%s: failed %q", tc.name, r.Failures)
+		case tc.fail != "" && (len(r.Failures) != 1 || !strings.Contains(r.Failures[0], tc.fail)):
+			t.Errorf("%s: failures %q, want one containing %q", tc.name, r.Failures, tc.fail)
+		}
+		if tc.fail == "" && (r.Baseline == nil || r.Baseline.At != time.Minute) {
+			t.Errorf("%s: baseline %+v, want the first sample after warmup", tc.name, r.Baseline)
+		}
+	}
+}
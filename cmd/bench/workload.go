@@ -0,0 +1,341 @@
+// cmd/bench/workload.go
+// The bench workload: workers uploading, downloading and deleting objects
+// in the bench tenants, tenant churn, and per-operation latency histograms
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations the bench times
+const (
+	opPut         = "put"
+	opGet         = "get"
+	opDelete      = "delete"
+	opChurn       = "churn"
+	opAntiEntropy = "anti-entropy"
+)
+
+// client calls the server's /v1 API
+type client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+// do sends a request and drains the response, returning an error for
+// statuses of 300 and above
+func (c *client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte) ([]byte, error) {
+	target := c.endpoint + "/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func (c *client) admin(ctx context.Context, method, path string, query url.Values, body []byte) ([]byte, error) {
+	return c.do(ctx, method, path, query, http.Header{"Authorization": {"Bearer " + c.token}}, body)
+}
+
+// createTenant provisions a tenant named name, returning its ID
+func (c *client) createTenant(ctx context.Context, name string) (string, error) {
+	spec, _ := json.Marshal(map[string]string{"name": name})
+	data, err := c.admin(ctx, http.MethodPost, "/admin/tenants", nil, spec)
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil || info.ID == "" {
+		return "", fmt.Errorf("create tenant %s: unexpected response %q", name, data)
+	}
+	return info.ID, nil
+}
+
+// deleteTenant deletes a tenant and its objects
+func (c *client) deleteTenant(ctx context.Context, id string) error {
+	_, err := c.admin(ctx, http.MethodDelete, "/admin/tenants", url.Values{"id": {id}, "purge": {"true"}}, nil)
+	return err
+}
+
+// tenantHeader addresses tenantID with the admin token, which the server
+// accepts for any tenant
+func (c *client) tenantHeader(tenantID string) http.Header {
+	return http.Header{"X-Tenant-ID": {tenantID}, "Authorization": {"Bearer " + c.token}}
+}
+
+func (c *client) put(ctx context.Context, tenantID, key string, data []byte) error {
+	_, err := c.do(ctx, http.MethodPut, "/upload", url.Values{"key": {key}}, c.tenantHeader(tenantID), data)
+	return err
+}
+
+func (c *client) get(ctx context.Context, tenantID, key string) error {
+	_, err := c.do(ctx, http.MethodGet, "/download", url.Values{"key": {key}}, c.tenantHeader(tenantID), nil)
+	return err
+}
+
+func (c *client) delete(ctx context.Context, tenantID, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/delete", url.Values{"key": {key}}, c.tenantHeader(tenantID), nil)
+	return err
+}
+
+// opMix picks object operations by weight
+type opMix struct {
+	ops     []string
+	weights []int
+	total   int
+}
+
+// parseMix reads "put=40,get=50,delete=10"
+func parseMix(s string) (opMix, error) {
+	var m opMix
+	for _, part := range strings.Split(s, ",") {
+		op, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		w, err := strconv.Atoi(weight)
+		if !ok || err != nil || w < 0 {
+			return m, fmt.Errorf("invalid mix entry %q", part)
+		}
+		switch op {
+		case opPut, opGet, opDelete:
+		default:
+			return m, fmt.Errorf("unknown operation %q in mix", op)
+		}
+		m.ops = append(m.ops, op)
+		m.weights = append(m.weights, w)
+		m.total += w
+	}
+	if m.total == 0 {
+		return m, fmt.Errorf("mix has no weight")
+	}
+	return m, nil
+}
+
+func (m opMix) pick(rng *rand.Rand) string {
+	n := rng.Intn(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.ops[i]
+		}
+		n -= w
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+// histogram counts latencies in buckets a quarter of a power of two wide,
+// so memory stays fixed however long the run
+type histogram struct {
+	buckets [160]uint64
+	count   uint64
+	errors  uint64
+}
+
+func bucketOf(d time.Duration) int {
+	us := float64(d.Microseconds())
+	return min(int(4*math.Log2(us+1)), len(histogram{}.buckets)-1)
+}
+
+func (h *histogram) add(d time.Duration) {
+	h.buckets[bucketOf(d)]++
+	h.count++
+}
+
+func (h *histogram) merge(o *histogram) {
+	for i, n := range o.buckets {
+		h.buckets[i] += n
+	}
+	h.count += o.count
+	h.errors += o.errors
+}
+
+// quantile returns the upper bound of the bucket holding quantile q
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	var seen uint64
+	for i, n := range h.buckets {
+		if seen += n; seen >= max(rank, 1) {
+			return time.Duration(math.Exp2(float64(i+1)/4)-1) * time.Microsecond
+		}
+	}
+	return 0
+}
+
+// recorder collects latencies per operation for the current window
+type recorder struct {
+	mu     sync.Mutex
+	window map[string]*histogram
+	total  map[string]*histogram
+}
+
+func newRecorder() *recorder {
+	return &recorder{window: make(map[string]*histogram), total: make(map[string]*histogram)}
+}
+
+func (r *recorder) record(op string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.window[op]
+	if h == nil {
+		h = &histogram{}
+		r.window[op] = h
+	}
+	if err != nil {
+		h.errors++
+		return
+	}
+	h.add(d)
+}
+
+// rotate returns the window's histograms, folding them into the totals,
+// and starts a new window
+func (r *recorder) rotate() map[string]*histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	window := r.window
+	r.window = make(map[string]*histogram)
+	for op, h := range window {
+		if r.total[op] == nil {
+			r.total[op] = &histogram{}
+		}
+		r.total[op].merge(h)
+	}
+	return window
+}
+
+// opStats summarizes one operation's latencies
+type opStats struct {
+	Op     string        `json:"op"`
+	Count  uint64        `json:"count"`
+	Errors uint64        `json:"errors"`
+	P50    time.Duration `json:"p50_ns"`
+	P99    time.Duration `json:"p99_ns"`
+}
+
+func summarize(hists map[string]*histogram) []opStats {
+	out := make([]opStats, 0, len(hists))
+	for op, h := range hists {
+		out = append(out, opStats{Op: op, Count: h.count, Errors: h.errors, P50: h.quantile(0.5), P99: h.quantile(0.99)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Op < out[j].Op })
+	return out
+}
+
+// workload drives the object operations
+type workload struct {
+	client  *client
+	rec     *recorder
+	tenants []string
+	mix     opMix
+	keys    int
+	payload []byte
+}
+
+// worker runs operations on its own keys in one tenant until ctx is done
+func (w *workload) worker(ctx context.Context, id int) {
+	rng := rand.New(rand.NewSource(int64(id)))
+	tenantID := w.tenants[id%len(w.tenants)]
+	stored := make([]bool, w.keys)
+	for ctx.Err() == nil {
+		i := rng.Intn(w.keys)
+		key := fmt.Sprintf("bench/%d/%d", id, i)
+		op := w.mix.pick(rng)
+		if op != opPut && !stored[i] {
+			op = opPut
+		}
+
+		start := time.Now()
+		var err error
+		switch op {
+		case opPut:
+			err = w.client.put(ctx, tenantID, key, w.payload)
+			stored[i] = stored[i] || err == nil
+		case opGet:
+			err = w.client.get(ctx, tenantID, key)
+		case opDelete:
+			if err = w.client.delete(ctx, tenantID, key); err == nil {
+				stored[i] = false
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.rec.record(op, time.Since(start), err)
+	}
+}
+
+// churn creates a tenant, fills it, reads it back and deletes it
+func (w *workload) churn(ctx context.Context, n int) error {
+	id, err := w.client.createTenant(ctx, fmt.Sprintf("bench-churn-%d-%d", time.Now().Unix(), n))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 10 && err == nil; i++ {
+		err = w.client.put(ctx, id, fmt.Sprintf("churn/%d", i), w.payload)
+	}
+	if err == nil {
+		err = w.client.get(ctx, id, "churn/0")
+	}
+	if derr := w.client.deleteTenant(context.WithoutCancel(ctx), id); err == nil {
+		err = derr
+	}
+	return err
+}
+
+// background churns a tenant and kicks an anti-entropy round every interval
+func (w *workload) background(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for n := 0; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		start := time.Now()
+		err := w.churn(ctx, n)
+		if ctx.Err() != nil {
+			return
+		}
+		w.rec.record(opChurn, time.Since(start), err)
+
+		start = time.Now()
+		_, err = w.client.admin(ctx, http.MethodPost, "/admin/replication/anti-entropy", nil, nil)
+		w.rec.record(opAntiEntropy, time.Since(start), err)
+	}
+}
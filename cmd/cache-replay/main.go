@@ -0,0 +1,155 @@
+// cmd/cache-replay/main.go
+// Replays an access trace recorded with MINIO_PLACEMENT_TRACE against
+// cache engines and reference eviction policies at one or more capacities,
+// for capacity planning and eviction-policy comparison
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/minio/enterprise/internal/cache"
+)
+
+func main() {
+	engines := flag.String("engines", "v3,"+strings.Join(cache.BoundedPolicies, ","), "comma-separated engines to compare: v3 and any of lru, lfu, fifo")
+	capacities := flag.String("capacity", "1G", "comma-separated cache capacities to sweep")
+	readThrough := flag.Bool("read-through", true, "fill the cache after a missed read, as the gateway does")
+	speed := flag.Float64("speed", 0, "replay at the trace's timing sped up this many times (0 for as fast as possible)")
+	payload := flag.String("payload", "64K", "most bytes written to the v3 engine per entry")
+	asJSON := flag.Bool("json", false, "print results as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: cache-replay [flags] TRACE\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var sizes []int64
+	for _, s := range strings.Split(*capacities, ",") {
+		n, err := parseBytes(strings.TrimSpace(s))
+		if err != nil || n == 0 {
+			fmt.Fprintf(os.Stderr, "-capacity: invalid size %q\n", s)
+			os.Exit(2)
+		}
+		sizes = append(sizes, n)
+	}
+	maxPayload, err := parseBytes(*payload)
+	if err != nil || maxPayload == 0 {
+		fmt.Fprintf(os.Stderr, "-payload: invalid size %q\n", *payload)
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	events, err := cache.ReadTrace(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	type row struct {
+		Capacity int64 `json:"capacity"`
+		cache.ReplayResult
+	}
+	var rows []row
+	opts := cache.ReplayOptions{ReadThrough: *readThrough, Speed: *speed}
+	for _, capacity := range sizes {
+		for _, name := range strings.Split(*engines, ",") {
+			res, err := replay(ctx, strings.TrimSpace(name), capacity, int(maxPayload), events, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(2)
+			}
+			rows = append(rows, row{capacity, res})
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+		return
+	}
+
+	fmt.Printf("%d events\n\n", len(events))
+	fmt.Printf("%-6s %10s %8s %8s %8s %10s %10s %10s %10s\n", "engine", "capacity", "gets", "hit", "byte hit", "evictions", "peak", "get p50", "get p99")
+	for _, r := range rows {
+		fmt.Printf("%-6s %10s %8d %7.1f%% %7.1f%% %10d %10s %10s %10s\n", r.Target, formatBytes(r.Capacity), r.Gets,
+			100*r.HitRatio(), 100*r.ByteHitRatio(), r.Evictions, formatBytes(r.PeakBytes), r.GetP50, r.GetP99)
+	}
+}
+
+// replay runs events against a fresh engine of capacity bytes. The v3
+// engine's L1 is sized in whole GiB, rounded up.
+func replay(ctx context.Context, engine string, capacity int64, maxPayload int, events []cache.TraceEvent, opts cache.ReplayOptions) (cache.ReplayResult, error) {
+	if engine != "v3" {
+		c, err := cache.NewBoundedCache(engine, capacity)
+		if err != nil {
+			return cache.ReplayResult{}, err
+		}
+		return cache.Replay(ctx, c, events, opts), nil
+	}
+
+	mgr, err := cache.NewV3CacheManager(&cache.V3CacheConfig{L1MaxSizeGB: (capacity + 1<<30 - 1) >> 30})
+	if err != nil {
+		return cache.ReplayResult{}, err
+	}
+	defer mgr.Shutdown(context.Background())
+	return cache.Replay(ctx, cache.NewV3Target(mgr, maxPayload), events, opts), nil
+}
+
+// parseBytes reads a byte count with an optional K, M, G or T (binary) suffix
+func parseBytes(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	shift := 0
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	case "T":
+		shift = 40
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
+
+// formatBytes prints n in the largest binary unit it fills
+func formatBytes(n int64) string {
+	for _, u := range []struct {
+		shift  uint
+		suffix string
+	}{{40, "T"}, {30, "G"}, {20, "M"}, {10, "K"}} {
+		if n >= 1<<u.shift {
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(int64(1)<<u.shift), u.suffix)
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,375 @@
+// cmd/minio-admin/main.go
+// Command-line client for the server's /admin API
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is one "<group> <name>" subcommand
+type command struct {
+	usage string
+	run   func(c *client, args []string) error
+}
+
+var commands = map[string]command{
+	"access top": {"[-tenant ID] [-window 15m] [-by requests|bytes] [-prefix P] [-depth N] [-n 20]", accessTop},
+	"access hot": {"", accessHot},
+
+	"cache stats":  {"[-shards]", cacheStats},
+	"cache detail": {"[-hottest 10] [-shards]", cacheDetail},
+	"cache top":    {"[-by size|hits] [-n 20]", cacheTop},
+	"cache flush":  {"[-tenant ID] [-prefix P]", cacheFlush},
+	"cache demote": {"-key K [-tenant ID] [-tier 1|2]", cacheDemote},
+
+	"health history": {"[-since 24h] [-subsystem S] [-component C] [-severity info|warning|critical] [-n 200]", healthHistory},
+
+	"quarantine list":    {"[-tenant ID] [-status held|discarded|released|deleted]", quarantineList},
+	"quarantine release": {"-id ID [-note TEXT]", quarantineRelease},
+	"quarantine delete":  {"-id ID [-note TEXT]", quarantineDelete},
+
+	"support bundle": {"[-o FILE] [-profiles=false] [-logs N]", supportBundle},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: minio-admin [-endpoint URL] [-token TOKEN] <group> <command> [flags]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-19s %s\n", name, commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	endpoint := flag.String("endpoint", envOr("MINIO_ENDPOINT", "http://localhost:9000"), "server URL")
+	token := flag.String("token", os.Getenv("MINIO_ADMIN_TOKEN"), "admin token")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		usage()
+	}
+
+	c := &client{endpoint: strings.TrimRight(*endpoint, "/"), token: *token, http: &http.Client{Timeout: 60 * time.Second}}
+	if err := cmd.run(c, args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// client calls the admin API and pretty-prints JSON responses
+type client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+func (c *client) do(method, path string, query url.Values) error {
+	resp, err := c.send(method, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	if json.Unmarshal(body, &v) != nil {
+		os.Stdout.Write(body)
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// download saves a response body to out, or to the file the server names
+// if out is empty, and reports where
+func (c *client) download(path string, query url.Values, out string) error {
+	resp, err := c.send(http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == "" {
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+			out = filepath.Base(params["filename"])
+		}
+		if out == "" || out == "." || out == "/" {
+			return fmt.Errorf("GET %s: the server named no file; pass -o", path)
+		}
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out)
+		return err
+	}
+	fmt.Printf("%s (%d bytes)\n", out, n)
+	return nil
+}
+
+// send makes a request, turning error responses into errors
+func (c *client) send(method, path string, query url.Values) (*http.Response, error) {
+	target := c.endpoint + "/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	var apiErr struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"requestId"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+		return nil, fmt.Errorf("%s %s: %s: %s (request %s)", method, path, apiErr.Code, apiErr.Message, apiErr.RequestID)
+	}
+	return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// ========== access ==========
+
+func accessTop(c *client, args []string) error {
+	fs := flag.NewFlagSet("access top", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "only this tenant's objects")
+	window := fs.String("window", "", "trailing window, e.g. 15m (default: all history)")
+	by := fs.String("by", "requests", "order by requests or bytes")
+	prefix := fs.String("prefix", "", "key prefix")
+	depth := fs.Int("depth", 0, "group keys by this many path segments")
+	n := fs.Int("n", 20, "number of results")
+	fs.Parse(args)
+
+	query := url.Values{"by": {*by}, "n": {strconv.Itoa(*n)}}
+	if *tenantID != "" {
+		query.Set("tenant_id", *tenantID)
+	}
+	if *window != "" {
+		query.Set("window", *window)
+	}
+	if *prefix != "" {
+		query.Set("prefix", *prefix)
+	}
+	if *depth > 0 {
+		query.Set("depth", strconv.Itoa(*depth))
+	}
+	return c.do(http.MethodGet, "/admin/access/top", query)
+}
+
+func accessHot(c *client, args []string) error {
+	return c.do(http.MethodGet, "/admin/access/hot", nil)
+}
+
+// ========== cache ==========
+
+func cacheStats(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache stats", flag.ExitOnError)
+	shards := fs.Bool("shards", false, "include per-shard occupancy")
+	fs.Parse(args)
+
+	query := url.Values{}
+	if *shards {
+		query.Set("shards", "true")
+	}
+	return c.do(http.MethodGet, "/admin/cache/stats", query)
+}
+
+func cacheDetail(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache detail", flag.ExitOnError)
+	hottest := fs.Int("hottest", 10, "number of busiest shards")
+	shards := fs.Bool("shards", false, "include every shard")
+	fs.Parse(args)
+
+	query := url.Values{"hottest": {strconv.Itoa(*hottest)}}
+	if *shards {
+		query.Set("shards", "true")
+	}
+	return c.do(http.MethodGet, "/admin/cache/detail", query)
+}
+
+func cacheTop(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache top", flag.ExitOnError)
+	by := fs.String("by", "size", "order by size or hits")
+	n := fs.Int("n", 20, "number of keys")
+	fs.Parse(args)
+
+	return c.do(http.MethodGet, "/admin/cache/top", url.Values{"by": {*by}, "n": {strconv.Itoa(*n)}})
+}
+
+func cacheFlush(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache flush", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "flush this tenant's RESP entries")
+	prefix := fs.String("prefix", "", "key prefix")
+	fs.Parse(args)
+
+	query := url.Values{"prefix": {*prefix}}
+	if *tenantID != "" {
+		query.Set("tenant_id", *tenantID)
+	}
+	return c.do(http.MethodPost, "/admin/cache/flush", query)
+}
+
+func cacheDemote(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache demote", flag.ExitOnError)
+	key := fs.String("key", "", "cache key")
+	tenantID := fs.String("tenant", "", "tenant owning a RESP key")
+	tier := fs.Int("tier", 1, "target tier (1=L2, 2=L3)")
+	fs.Parse(args)
+
+	if *key == "" {
+		return fmt.Errorf("-key is required")
+	}
+	query := url.Values{"key": {*key}, "tier": {strconv.Itoa(*tier)}}
+	if *tenantID != "" {
+		query.Set("tenant_id", *tenantID)
+	}
+	return c.do(http.MethodPost, "/admin/cache/demote", query)
+}
+
+// ========== health ==========
+
+func healthHistory(c *client, args []string) error {
+	fs := flag.NewFlagSet("health history", flag.ExitOnError)
+	since := fs.Duration("since", 0, "only events this recent, e.g. 24h (default: all kept)")
+	subsystem := fs.String("subsystem", "", "node, readiness, drain, breaker, disk_usage, clock_skew or selftest")
+	component := fs.String("component", "", "disk, region or drain ID")
+	severity := fs.String("severity", "", "lowest severity to include")
+	n := fs.Int("n", 200, "newest events to show")
+	fs.Parse(args)
+
+	query := url.Values{"limit": {strconv.Itoa(*n)}}
+	if *since > 0 {
+		query.Set("from", time.Now().Add(-*since).UTC().Format(time.RFC3339))
+	}
+	if *subsystem != "" {
+		query.Set("subsystem", *subsystem)
+	}
+	if *component != "" {
+		query.Set("component", *component)
+	}
+	if *severity != "" {
+		query.Set("severity", *severity)
+	}
+	return c.do(http.MethodGet, "/admin/health/history", query)
+}
+
+// ========== quarantine ==========
+
+func quarantineList(c *client, args []string) error {
+	fs := flag.NewFlagSet("quarantine list", flag.ExitOnError)
+	tenantID := fs.String("tenant", "", "only this tenant's detections")
+	status := fs.String("status", "", "only detections in this status")
+	fs.Parse(args)
+
+	query := url.Values{}
+	if *tenantID != "" {
+		query.Set("tenant", *tenantID)
+	}
+	if *status != "" {
+		query.Set("status", *status)
+	}
+	return c.do(http.MethodGet, "/admin/quarantine", query)
+}
+
+// quarantineReview parses -id and -note for release and delete
+func quarantineReview(name string, args []string) (url.Values, error) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	id := fs.String("id", "", "quarantine record ID")
+	note := fs.String("note", "", "reviewer's note, kept on the record")
+	fs.Parse(args)
+
+	if *id == "" {
+		return nil, fmt.Errorf("-id is required")
+	}
+	return url.Values{"id": {*id}, "note": {*note}}, nil
+}
+
+func quarantineRelease(c *client, args []string) error {
+	query, err := quarantineReview("quarantine release", args)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, "/admin/quarantine/release", query)
+}
+
+func quarantineDelete(c *client, args []string) error {
+	query, err := quarantineReview("quarantine delete", args)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodDelete, "/admin/quarantine", query)
+}
+
+// ========== support ==========
+
+func supportBundle(c *client, args []string) error {
+	fs := flag.NewFlagSet("support bundle", flag.ExitOnError)
+	out := fs.String("o", "", "file to write (default: the name the server gives)")
+	profiles := fs.Bool("profiles", true, "include goroutine and heap profiles")
+	logs := fs.Int("logs", 0, "latest log lines to include (default: all kept)")
+	fs.Parse(args)
+
+	query := url.Values{}
+	if !*profiles {
+		query.Set("profiles", "false")
+	}
+	if *logs > 0 {
+		query.Set("logs", strconv.Itoa(*logs))
+	}
+	return c.download("/admin/support/bundle", query, *out)
+}
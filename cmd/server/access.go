@@ -0,0 +1,157 @@
+// cmd/server/access.go
+// Object access statistics: the most accessed objects and prefixes per
+// tenant, and tier placement that promotes consistently hot prefixes to L1
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/enterprise/internal/cache"
+	"github.com/minio/enterprise/internal/monitoring"
+)
+
+// accessQuery reads ?window=, ?by=, ?prefix=, ?depth= and ?n= (default 20)
+func accessQuery(r *http.Request) (monitoring.AccessQuery, error) {
+	query := r.URL.Query()
+	q := monitoring.AccessQuery{Prefix: query.Get("prefix"), By: query.Get("by"), Limit: 20}
+
+	if raw := query.Get("window"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil || window <= 0 {
+			return q, fmt.Errorf("window must be a positive duration such as 15m")
+		}
+		q.Window = window
+	}
+	if raw := query.Get("depth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 0 {
+			return q, fmt.Errorf("depth must be a non-negative integer")
+		}
+		q.Depth = depth
+	}
+	if raw := query.Get("n"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 10000 {
+			return q, fmt.Errorf("n must be between 1 and 10000")
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
+
+// handleAccessTop lists a tenant's most accessed objects, or prefixes with
+// ?depth=, over the trailing ?window= (default: all history kept)
+func (s *MinIOServer) handleAccessTop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	q, err := accessQuery(r)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.TenantID = tenantID
+	s.writeAccessTop(w, r, q)
+}
+
+// handleAdminAccessTop is handleAccessTop across every tenant, or ?tenant_id=
+func (s *MinIOServer) handleAdminAccessTop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := accessQuery(r)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.TenantID = r.URL.Query().Get("tenant_id")
+	s.writeAccessTop(w, r, q)
+}
+
+func (s *MinIOServer) writeAccessTop(w http.ResponseWriter, r *http.Request, q monitoring.AccessQuery) {
+	top, err := s.accessStats.Top(q)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, top)
+}
+
+// handleAdminAccessHot lists the prefixes the latest placement pass found
+// consistently hot
+func (s *MinIOServer) handleAdminAccessHot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hot := []monitoring.AccessCount{}
+	if p := s.hotPrefixes.Load(); p != nil {
+		hot = *p
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled":    s.config.HotPrefixRequests > 0,
+		"depth":      s.config.HotPrefixDepth,
+		"window":     s.config.HotPrefixWindow.String(),
+		"requests":   s.config.HotPrefixRequests,
+		"prefixes":   hot,
+		"promotions": s.tierPromotions.Load(),
+	})
+}
+
+// accessPlacer forgets idle keys and promotes hot prefixes once per interval
+func (s *MinIOServer) accessPlacer() {
+	ticker := time.NewTicker(s.accessStats.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.accessStats.Prune()
+			if s.config.HotPrefixRequests > 0 {
+				if n := s.promoteHotPrefixes(); n > 0 {
+					log.Printf("Promoted %d cached objects under hot prefixes to L1", n)
+				}
+			}
+		}
+	}
+}
+
+// promoteHotPrefixes moves the cached objects under every consistently hot
+// prefix to L1, so the whole prefix is served warm and not only the keys
+// already read. It returns how many entries moved.
+func (s *MinIOServer) promoteHotPrefixes() int {
+	hot := s.accessStats.HotPrefixes(s.config.HotPrefixDepth, s.config.HotPrefixWindow, s.config.HotPrefixRequests)
+	s.hotPrefixes.Store(&hot)
+
+	var promoted int
+	for _, p := range hot {
+		for _, meta := range s.index.List(p.TenantID, p.Prefix) {
+			if cached(&meta) && s.cacheManager.Promote(cache.TenantKey(meta.Tenant, meta.Key), 0) {
+				promoted++
+			}
+		}
+	}
+	s.tierPromotions.Add(uint64(promoted))
+	return promoted
+}
@@ -0,0 +1,288 @@
+// cmd/server/acl.go
+// Per-object access control lists. A request made with a tenant token or
+// service account keys is allowed an object operation when its principal
+// belongs to the object's tenant and holds the matching permission, or
+// when the object's ACL grants it to the principal ("user:<subject>") or
+// to the principal's tenant ("tenant:<id>"). Requests withAuth admits
+// without credentials, or with admin credentials, are not checked.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// maxObjectGrants bounds an object's ACL, as S3 does
+const maxObjectGrants = 100
+
+// principal is who a request's credentials identify
+type principal struct {
+	tenantID    string
+	subject     string // Token subject or service account ID; "" for a plain tenant token
+	permissions []string
+}
+
+// nobody is the principal of credentials that do not verify, and of a
+// request without any where auth is required: it is granted nothing
+var nobody = &principal{}
+
+// requestPrincipal returns the principal withAuth bound to r, or verifies
+// r's credentials. It returns nil, which authorize allows, only for admin
+// credentials, a public read, whose bucket authorized it, or a request
+// without credentials where auth is not required.
+func (s *MinIOServer) requestPrincipal(r *http.Request) *principal {
+	ctx := r.Context()
+	if p, ok := ctx.Value(principalKey{}).(*principal); ok {
+		return p
+	}
+	if admin, _ := ctx.Value(adminKey{}).(bool); admin || isPublicRead(ctx) || s.isAdminRequest(r) {
+		return nil
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		claims, err := s.tenantManager.VerifyTenantToken(ctx, token)
+		if err != nil {
+			return nobody
+		}
+		return &principal{tenantID: claims.TenantID, subject: claims.Subject, permissions: claims.Permissions}
+	}
+	if accessKey, secretKey, ok := r.BasicAuth(); ok {
+		acct, err := s.tenantManager.VerifyServiceAccountCredential(ctx, accessKey, secretKey)
+		if err != nil {
+			return nobody
+		}
+		return &principal{tenantID: acct.TenantID, subject: acct.ID, permissions: acct.Permissions}
+	}
+	if s.config.RequireAuth {
+		return nobody
+	}
+	return nil
+}
+
+// can reports whether p's permissions include perm ("*" includes all)
+func (p *principal) can(perm string) bool {
+	for _, have := range p.permissions {
+		if have == perm || have == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// grantees returns the ACL grantees p matches on an object of tenantID
+func (p *principal) grantees(tenantID string) []string {
+	if p.tenantID == "" {
+		return nil
+	}
+	if p.tenantID != tenantID {
+		return []string{"tenant:" + p.tenantID}
+	}
+	if p.subject == "" {
+		return nil
+	}
+	return []string{"user:" + p.subject}
+}
+
+// actor names p in audit events, as the tenant named by a request
+// without credentials
+func (p *principal) actor(tenantID string) string {
+	switch {
+	case p == nil:
+		return tenantID
+	case p.subject != "":
+		return p.subject
+	}
+	return p.tenantID
+}
+
+// objectAction is an operation on an object: the permission a principal
+// of the object's tenant needs for it, and the ACL permission granting it
+type objectAction struct {
+	permission string
+	grant      string
+}
+
+var (
+	actionRead     = objectAction{"object:read", metadata.ACLRead}
+	actionWrite    = objectAction{"object:write", metadata.ACLWrite}
+	actionDelete   = objectAction{"object:delete", metadata.ACLWrite}
+	actionReadACL  = objectAction{"object:read", metadata.ACLReadACP}
+	actionWriteACL = objectAction{"object:write", metadata.ACLWriteACP}
+)
+
+// authorizeObject checks that r may perform act on tenantID/key
+func (s *MinIOServer) authorizeObject(r *http.Request, tenantID, key string, act objectAction) error {
+	return s.authorize(s.requestPrincipal(r), tenantID, key, act)
+}
+
+// authorize checks that p may perform act on tenantID/key; a nil p is a
+// request without credentials
+func (s *MinIOServer) authorize(p *principal, tenantID, key string, act objectAction) error {
+	if p == nil || p.tenantID == tenantID && p.can(act.permission) {
+		return nil
+	}
+	if meta, err := s.index.Get(tenantID, key); err == nil && meta.Granted(act.grant, p.grantees(tenantID)...) {
+		return nil
+	}
+	return errObjectDenied
+}
+
+// authorizeMove checks that p may move tenantID/src to dst, which deletes
+// the source and writes the destination
+func (s *MinIOServer) authorizeMove(p *principal, tenantID, src, dst string) error {
+	if err := s.authorize(p, tenantID, src, actionDelete); err != nil {
+		return err
+	}
+	return s.authorize(p, tenantID, dst, actionWrite)
+}
+
+// objectACL is the body of ACL requests and responses
+type objectACL struct {
+	Key       string           `json:"key,omitempty"`
+	VersionID string           `json:"version_id,omitempty"`
+	Owner     string           `json:"owner,omitempty"`
+	Grants    []metadata.Grant `json:"grants"`
+}
+
+// validateACL checks grants for an object of tenantID
+func (s *MinIOServer) validateACL(ctx context.Context, tenantID string, grants []metadata.Grant) error {
+	if len(grants) > maxObjectGrants {
+		return &httpError{http.StatusBadRequest, codeMalformedACL, fmt.Sprintf("An object has at most %d grants", maxObjectGrants)}
+	}
+	for _, g := range grants {
+		if !containsString(metadata.ACLPermissions, g.Permission) {
+			return &httpError{http.StatusBadRequest, codeMalformedACL,
+				fmt.Sprintf("Unknown permission %q; use %s", g.Permission, strings.Join(metadata.ACLPermissions, ", "))}
+		}
+		kind, id, _ := strings.Cut(g.Grantee, ":")
+		switch {
+		case id == "" || kind != "user" && kind != "tenant":
+			return &httpError{http.StatusBadRequest, codeMalformedACL, fmt.Sprintf("Grantee %q must be user:<subject> or tenant:<id>", g.Grantee)}
+		case kind == "tenant" && id == tenantID:
+			return &httpError{http.StatusBadRequest, codeMalformedACL, "Grant the object's own tenant's principals as user:<subject>"}
+		case kind == "tenant":
+			if _, err := s.tenantManager.GetTenant(ctx, id); err != nil {
+				return &httpError{http.StatusBadRequest, codeMalformedACL, fmt.Sprintf("Grantee tenant %q not found", id)}
+			}
+		}
+	}
+	return nil
+}
+
+// handleACL returns an object's ACL (GET) or replaces it (PUT, body
+// {"grants": [...]}; an empty list removes every grant)
+func (s *MinIOServer) handleACL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := tenantFromRequest(r)
+	key := r.URL.Query().Get("key")
+	if tenantID == "" || key == "" {
+		writeErrorMessage(w, r, "Missing tenant ID or key", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if err := s.authorizeObject(r, tenantID, key, actionReadACL); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		meta, err := s.index.Get(tenantID, key)
+		if err != nil {
+			writeError(w, r, errObjectNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, aclResponse(meta))
+		return
+
+	case http.MethodPut:
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	p := s.requestPrincipal(r)
+	if err := s.authorize(p, tenantID, key, actionWriteACL); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var req objectACL
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := s.validateACL(ctx, tenantID, req.Grants); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var grants []metadata.Grant
+	if len(req.Grants) > 0 {
+		grants = req.Grants
+	}
+	meta, err := s.putObjectACL(ctx, tenantID, key, grants, uploadCondition(r))
+	ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.acl", Resource: key,
+		Details: map[string]string{"grants": strconv.Itoa(len(grants))}}
+	if err != nil {
+		ev.Outcome = audit.OutcomeError
+		ev.Details["error"] = err.Error()
+	}
+	s.logAudit(ctx, ev)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, aclResponse(meta))
+}
+
+// putObjectACL replaces the ACL of tenantID/key, as putObjectTags does its
+// tags
+func (s *MinIOServer) putObjectACL(ctx context.Context, tenantID, key string, grants []metadata.Grant, cond writeCondition) (*metadata.ObjectMeta, error) {
+	unlock := s.writeLocks.Lock(tenantID, key)
+	defer unlock()
+
+	prev, err := s.index.Get(tenantID, key)
+	if err != nil {
+		return nil, errObjectNotFound
+	}
+	if err := cond.check(prev); err != nil {
+		return nil, err
+	}
+	meta := *prev
+	meta.ACL = grants
+
+	txn, err := s.intentLog.Begin(metadata.Op{Type: metadata.OpPut, Meta: meta, Prev: prev})
+	if err != nil {
+		return nil, errIntentFailed
+	}
+	s.index.Put(meta)
+	txn.OnAbort(func() {
+		s.index.Put(*prev)
+	})
+	if err := txn.Commit(); err != nil {
+		return nil, errCommitFailed
+	}
+	return &meta, nil
+}
+
+func aclResponse(meta *metadata.ObjectMeta) objectACL {
+	grants := meta.ACL
+	if grants == nil {
+		grants = []metadata.Grant{}
+	}
+	return objectACL{Key: meta.Key, VersionID: meta.VersionID, Owner: meta.Tenant, Grants: grants}
+}
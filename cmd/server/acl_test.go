@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// Another tenant's principals reach an object only through its ACL, and
+// only as far as their grant goes; only the owner's writers change the ACL
+func TestObjectACL(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	object := "?tenant_id=" + tenantID + "&key=doc.txt"
+	acl := "/v1/acl" + object
+	otherID := newTenant(t)
+	other := bearer(tenantToken(t, otherID, "carol", "*"))
+
+	// Full permissions in their own tenant give nothing here
+	refused := func(grant string) {
+		t.Helper()
+		for _, req := range []struct{ method, path string }{
+			{"PUT", "/v1/upload"}, {"DELETE", "/v1/delete"}, {"PUT", "/v1/acl"},
+		} {
+			w := do(t, req.method, req.path+object, map[string][]metadata.Grant{"grants": {}}, other)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s with %s: status %d, want 403", req.method, req.path, grant, w.Code)
+			}
+		}
+	}
+	for _, path := range []string{"/v1/download", "/v1/stat", "/v1/acl"} {
+		w := do(t, "GET", path+object, nil, other)
+		expectStatus(t, w, http.StatusForbidden)
+	}
+	refused("no grant")
+
+	grants := map[string][]metadata.Grant{"grants": {{Grantee: "tenant:" + otherID, Permission: metadata.ACLRead}}}
+	w := do(t, "PUT", acl, grants, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", "/v1/download"+object, nil, other)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "hello" {
+		t.Errorf("Downloaded %q, want hello", w.Body.String())
+	}
+	w = do(t, "GET", "/v1/stat"+object, nil, other)
+	expectStatus(t, w, http.StatusOK)
+	refused("a read grant")
+
+	// The owner's reader cannot change the ACL; its writer can
+	w = do(t, "PUT", acl, map[string][]metadata.Grant{"grants": {}}, bearer(tenantToken(t, tenantID, "bob", "object:read")))
+	expectStatus(t, w, http.StatusForbidden)
+	var got objectACL
+	w = do(t, "GET", acl, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &got); len(got.Grants) != 1 || got.Owner != tenantID {
+		t.Fatalf("ACL %+v after refused changes, want the read grant", got)
+	}
+	w = do(t, "PUT", acl, map[string][]metadata.Grant{"grants": {}}, bearer(tenantToken(t, tenantID, "alice", "object:write")))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", "/v1/download"+object, nil, other)
+	expectStatus(t, w, http.StatusForbidden)
+}
+
+// Credentials that do not verify are granted nothing, even where no
+// middleware checked them first; only public reads and requests without
+// credentials where auth is not required pass unchecked
+func TestRequestPrincipalUnverified(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	grants := map[string][]metadata.Grant{"grants": {{Grantee: "tenant:" + newTenant(t), Permission: metadata.ACLRead}}}
+	w := do(t, "PUT", "/v1/acl?tenant_id="+tenantID+"&key=doc.txt", grants, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	authorize := func(creds ...credential) error {
+		r := httptest.NewRequest("GET", "/v1/download?tenant_id="+tenantID+"&key=doc.txt", nil)
+		for _, c := range creds {
+			c(r)
+		}
+		return testServer.authorizeObject(r, tenantID, "doc.txt", actionRead)
+	}
+	public := func(r *http.Request) { *r = *r.WithContext(publicRead(r.Context())) }
+	for name, c := range map[string]credential{
+		"token":           bearer("not-a-token"),
+		"service account": basic("AKNOTREAL", "secret"),
+	} {
+		if err := authorize(c); err != errObjectDenied {
+			t.Errorf("Unverified %s: %v, want access denied", name, err)
+		}
+	}
+	if err := authorize(adminAuth); err != nil {
+		t.Errorf("Admin credentials: %v", err)
+	}
+	if err := authorize(); err != errObjectDenied {
+		t.Errorf("No credentials with auth required: %v, want access denied", err)
+	}
+	if err := authorize(public); err != nil {
+		t.Errorf("Public read: %v", err)
+	}
+	testServer.config.RequireAuth = false
+	defer func() { testServer.config.RequireAuth = true }()
+	if err := authorize(); err != nil {
+		t.Errorf("No credentials with auth not required: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/monitoring"
+)
+
+// A nearly full disk turns the node read-only: writes are refused with 507
+// until usage falls back below the resume threshold
+func TestDiskReadOnly(t *testing.T) {
+	tenantID := newTenant(t)
+	watcher := testServer.diskWatcher
+	defer func() { testServer.diskWatcher = watcher }()
+
+	// Any usage at all crosses these thresholds
+	full := monitoring.NewDiskWatcher(&monitoring.DiskWatcherConfig{
+		Paths:           map[string]string{"persistent": testServer.config.DataDir},
+		WarnPercent:     1e-9,
+		ReadOnlyPercent: 1e-9,
+		ResumePercent:   1e-9,
+	}, nil)
+	full.Check()
+	testServer.diskWatcher = full
+
+	w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=a.txt", "data", adminAuth)
+	expectStatus(t, w, http.StatusInsufficientStorage)
+	w = do(t, "GET", "/v1/admin/disks", nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "GET", "/v1/admin/disks", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var disks struct {
+		ReadOnly bool                   `json:"read_only"`
+		Disks    []monitoring.DiskUsage `json:"disks"`
+	}
+	decode(t, w, &disks)
+	if !disks.ReadOnly || len(disks.Disks) != 1 || disks.Disks[0].State != monitoring.DiskStateReadOnly {
+		t.Errorf("Disks %+v, want read-only", disks)
+	}
+	if m := scrape(t); !strings.Contains(m, "node_read_only 1\n") || !strings.Contains(m, `disk_used_percent{disk="persistent"}`) {
+		t.Error("Metrics do not report the read-only disk")
+	}
+
+	testServer.diskWatcher = watcher
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=a.txt", "data", adminAuth)
+	expectStatus(t, w, http.StatusOK)
+}
@@ -0,0 +1,191 @@
+// cmd/server/admission.go
+// Upload admission: when every upload slot is taken, uploads wait in
+// bounded per-tenant queues and freed slots go to tenants in weighted fair
+// order, so a burst from one tenant delays rather than starves the others.
+// An upload that cannot get a slot within the queueing deadline gets 503.
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// admissionQueue hands out a fixed number of upload slots
+type admissionQueue struct {
+	slots    int
+	maxQueue int           // Waiters per tenant
+	maxWait  time.Duration // Longest an upload waits for a slot
+	weight   func(tenantID string) float64
+
+	mu      sync.Mutex
+	held    int
+	vtime   float64 // Finish tag of the last waiter admitted
+	tenants map[string]*tenantWaiters
+	queued  int
+
+	admitted  atomic.Uint64
+	rejected  atomic.Uint64 // Tenant queue full
+	timedOut  atomic.Uint64
+	waited    atomic.Uint64 // Admitted after queueing
+	waitNanos atomic.Int64  // Total time those spent queued
+}
+
+// tenantWaiters is a tenant's queue, oldest first
+type tenantWaiters struct {
+	waiters []*waiter
+	lastTag float64 // Finish tag of the tenant's newest waiter
+}
+
+type waiter struct {
+	tenantID string
+	tag      float64
+	ready    chan struct{} // Closed when the waiter is given a slot
+}
+
+func newAdmissionQueue(slots, maxQueue int, maxWait time.Duration, weight func(string) float64) *admissionQueue {
+	return &admissionQueue{
+		slots:    slots,
+		maxQueue: maxQueue,
+		maxWait:  maxWait,
+		weight:   weight,
+		tenants:  make(map[string]*tenantWaiters),
+	}
+}
+
+// acquire claims a slot for tenantID, queueing up to maxWait if none is
+// free, and returns its release. It returns errTooManyUploads if the
+// tenant's queue is full or the wait runs out.
+func (q *admissionQueue) acquire(ctx context.Context, tenantID string) (func(), error) {
+	if q.slots <= 0 {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	if q.held < q.slots && q.queued == 0 {
+		q.held++
+		q.mu.Unlock()
+		q.admitted.Add(1)
+		return q.release, nil
+	}
+	t := q.tenants[tenantID]
+	if t != nil && len(t.waiters) >= q.maxQueue || q.maxQueue <= 0 || q.maxWait <= 0 {
+		q.mu.Unlock()
+		q.rejected.Add(1)
+		return nil, errTooManyUploads
+	}
+	if t == nil {
+		t = &tenantWaiters{}
+		q.tenants[tenantID] = t
+	}
+	// A tenant's tags advance by 1/weight per upload, so heavier tenants
+	// are admitted proportionally more often
+	w := &waiter{tenantID: tenantID, ready: make(chan struct{})}
+	w.tag = max(q.vtime, t.lastTag) + 1/q.weight(tenantID)
+	t.lastTag = w.tag
+	t.waiters = append(t.waiters, w)
+	q.queued++
+	q.mu.Unlock()
+
+	start := time.Now()
+	timer := time.NewTimer(q.maxWait)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	select {
+	case <-w.ready:
+		// Given a slot, possibly as the wait ended
+		q.mu.Unlock()
+		q.admitted.Add(1)
+		q.waited.Add(1)
+		q.waitNanos.Add(int64(time.Since(start)))
+		return q.release, nil
+	default:
+	}
+	q.remove(w)
+	q.mu.Unlock()
+	q.timedOut.Add(1)
+	return nil, errTooManyUploads
+}
+
+// release frees a slot, handing it straight to the next waiter if any
+func (q *admissionQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next *tenantWaiters
+	for _, t := range q.tenants {
+		if len(t.waiters) > 0 && (next == nil || t.waiters[0].tag < next.waiters[0].tag) {
+			next = t
+		}
+	}
+	if next == nil {
+		q.held--
+		return
+	}
+	w := next.waiters[0]
+	q.remove(w)
+	q.vtime = w.tag
+	close(w.ready)
+}
+
+// remove takes w out of its tenant's queue; the caller holds q.mu
+func (q *admissionQueue) remove(w *waiter) {
+	t := q.tenants[w.tenantID]
+	for i, other := range t.waiters {
+		if other == w {
+			t.waiters = append(t.waiters[:i], t.waiters[i+1:]...)
+			q.queued--
+			break
+		}
+	}
+	if len(t.waiters) == 0 {
+		delete(q.tenants, w.tenantID)
+	}
+}
+
+// inFlight returns the slots held
+func (q *admissionQueue) inFlight() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.held
+}
+
+// waiting returns the number of uploads queued
+func (q *admissionQueue) waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queued
+}
+
+// depths returns the number of uploads queued per tenant
+func (q *admissionQueue) depths() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := make(map[string]int, len(q.tenants))
+	for tenantID, t := range q.tenants {
+		out[tenantID] = len(t.waiters)
+	}
+	return out
+}
+
+// uploadWeight is tenantID's share of freed upload slots: its configured
+// weight, else its plan's, else 1
+func (s *MinIOServer) uploadWeight(tenantID string) float64 {
+	if w, ok := s.config.UploadQueueWeights[tenantID]; ok {
+		return w
+	}
+	if plan, err := s.tenantManager.TenantPlan(context.Background(), tenantID); err == nil {
+		if w, ok := s.config.UploadQueueWeights[plan]; ok {
+			return w
+		}
+	}
+	return 1
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until tenantID has n uploads queued in q
+func waitQueued(t *testing.T, q *admissionQueue, tenantID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for q.depths()[tenantID] != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queue depths %v, want %d for %s", q.depths(), n, tenantID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// With every slot taken, uploads queue up to the tenant's limit, proceed
+// when a slot frees, and get 503 when the queue is full or the wait runs out
+func TestUploadAdmission(t *testing.T) {
+	tenantID := newTenant(t)
+	uploads := testServer.uploads
+	q := newAdmissionQueue(1, 1, 200*time.Millisecond, testServer.uploadWeight)
+	testServer.uploads = q
+	defer func() { testServer.uploads = uploads }()
+	target := "/v1/upload?tenant_id=" + tenantID + "&key="
+
+	release, err := q.acquire(context.Background(), "holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- do(t, "PUT", target+"queued", "data", adminAuth) }()
+	waitQueued(t, q, tenantID, 1)
+	if body := scrape(t); !strings.Contains(body, fmt.Sprintf("upload_queue_depth{tenant=%q} 1", tenantID)) {
+		t.Error("Queued upload not in upload_queue_depth")
+	}
+	w := do(t, "PUT", target+"refused", "data", adminAuth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+
+	release()
+	expectStatus(t, <-done, http.StatusOK)
+	if q.inFlight() != 0 || q.waited.Load() != 1 {
+		t.Errorf("%d in flight, %d waited; want the slot returned after one wait", q.inFlight(), q.waited.Load())
+	}
+
+	release, err = q.acquire(context.Background(), "holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	w = do(t, "PUT", target+"late", "data", adminAuth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+	if body := scrape(t); !strings.Contains(body, "\nupload_queue_timeouts_total 1\n") || !strings.Contains(body, "\nuploads_rejected_total 2\n") {
+		t.Error("Refusals missing from metrics")
+	}
+}
+
+// Freed slots go to tenants in proportion to their weights rather than in
+// arrival order
+func TestAdmissionWeightedFairness(t *testing.T) {
+	weights := map[string]float64{"heavy": 2.5, "light": 1}
+	q := newAdmissionQueue(1, 8, 5*time.Second, func(tenantID string) float64 { return weights[tenantID] })
+	release, err := q.acquire(context.Background(), "holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 8)
+	enqueue := func(tenantID string, n int) {
+		for i := 0; i < n; i++ {
+			go func() {
+				release, err := q.acquire(context.Background(), tenantID)
+				if err != nil {
+					order <- err.Error()
+					return
+				}
+				order <- tenantID
+				release()
+			}()
+			waitQueued(t, q, tenantID, i+1)
+		}
+	}
+	// The light tenant's burst arrives first
+	enqueue("light", 3)
+	enqueue("heavy", 3)
+	release()
+
+	var got []string
+	for i := 0; i < 6; i++ {
+		got = append(got, <-order)
+	}
+	if want := "heavy heavy light heavy light light"; strings.Join(got, " ") != want {
+		t.Errorf("Admitted %v, want %s", got, want)
+	}
+}
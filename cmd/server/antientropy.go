@@ -0,0 +1,338 @@
+// cmd/server/antientropy.go
+// Anti-entropy between regions: on a schedule, every tenant's Merkle tree is
+// compared with each peer region's, and objects in the differing key ranges
+// that the peer lacks or holds an older version of are replicated again.
+// This catches losses the change feed path missed, such as changes dropped
+// before they shipped or a region restored from an old backup.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/replication"
+)
+
+// antiEntropy tracks the anti-entropy runs
+type antiEntropy struct {
+	kick chan struct{}
+
+	mu      sync.Mutex
+	last    *antiEntropyRun
+	running bool
+
+	runs          atomic.Uint64
+	divergent     atomic.Uint64 // Leaves found to differ
+	repaired      atomic.Uint64 // Objects replicated again
+	repairedBytes atomic.Uint64
+	errors        atomic.Uint64
+
+	// conflicts counts objects both regions hold at different versions,
+	// by whether last-write-wins kept the region's and whether the two
+	// were written closer together than the clocks' skew, so the pick may
+	// be wrong
+	conflicts [2][2]atomic.Uint64 // [region won][within skew]
+}
+
+// antiEntropyRun is the admin API view of one run
+type antiEntropyRun struct {
+	StartedAt  time.Time                     `json:"started_at"`
+	FinishedAt *time.Time                    `json:"finished_at,omitempty"`
+	Regions    map[string]*antiEntropyRegion `json:"regions"`
+}
+
+// antiEntropyRegion summarizes a run's comparison with one region
+type antiEntropyRegion struct {
+	Tenants         int    `json:"tenants"`
+	DivergentLeaves int    `json:"divergent_leaves"`
+	Repaired        int    `json:"repaired"`
+	RepairedBytes   int64  `json:"repaired_bytes"`
+	Conflicts       int    `json:"conflicts"`
+	WithinSkew      int    `json:"conflicts_within_skew"`
+	Error           string `json:"error,omitempty"`
+}
+
+func newAntiEntropy() *antiEntropy {
+	return &antiEntropy{kick: make(chan struct{}, 1)}
+}
+
+// runAntiEntropy compares regions every AntiEntropyInterval, or when kicked
+func (s *MinIOServer) runAntiEntropy() {
+	var tick <-chan time.Time
+	if s.config.AntiEntropyInterval > 0 {
+		ticker := time.NewTicker(s.config.AntiEntropyInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-tick:
+		case <-s.antiEntropy.kick:
+		}
+		s.antiEntropyPass()
+	}
+}
+
+// antiEntropyPass compares every tenant with every region that has an
+// endpoint configured
+func (s *MinIOServer) antiEntropyPass() {
+	ae := s.antiEntropy
+	run := &antiEntropyRun{StartedAt: time.Now().UTC(), Regions: make(map[string]*antiEntropyRegion)}
+	ae.mu.Lock()
+	ae.running, ae.last = true, run
+	ae.mu.Unlock()
+
+	for _, region := range s.replicationEngine.Regions() {
+		endpoint, ok := s.config.RegionEndpoints[region]
+		if !ok {
+			continue
+		}
+		st := &antiEntropyRegion{}
+		ae.mu.Lock()
+		run.Regions[region] = st
+		ae.mu.Unlock()
+
+		for _, tenantID := range s.index.Tenants() {
+			if s.ctx.Err() != nil {
+				break
+			}
+			if settings, err := s.tenantManager.Settings(s.ctx, tenantID); err == nil && !settings.AllowsRegion(region) {
+				continue
+			}
+			if err := s.antiEntropyTenant(region, endpoint[0], tenantID, st); err != nil {
+				log.Printf("Anti-entropy of %s with %s failed: %v", tenantID, region, err)
+				ae.errors.Add(1)
+				ae.mu.Lock()
+				st.Error = err.Error()
+				ae.mu.Unlock()
+			}
+		}
+	}
+
+	finished := time.Now().UTC()
+	ae.mu.Lock()
+	run.FinishedAt = &finished
+	ae.running = false
+	ae.mu.Unlock()
+	ae.runs.Add(1)
+}
+
+// antiEntropyTenant compares one tenant with region and replicates the
+// objects it is missing
+func (s *MinIOServer) antiEntropyTenant(region, endpoint, tenantID string, st *antiEntropyRegion) error {
+	ae := s.antiEntropy
+	depth := s.config.AntiEntropyDepth
+	local := s.index.Merkle(tenantID, depth)
+
+	var remote metadata.MerkleTree
+	if err := s.fetchDigest(region, endpoint, tenantID, local.Depth, -1, &remote); err != nil {
+		return err
+	}
+	ae.mu.Lock()
+	st.Tenants++
+	ae.mu.Unlock()
+	if remote.Root() == local.Root() {
+		return nil
+	}
+	leaves, err := local.Diff(&remote)
+	if err != nil {
+		return err
+	}
+	ae.divergent.Add(uint64(len(leaves)))
+	ae.mu.Lock()
+	st.DivergentLeaves += len(leaves)
+	ae.mu.Unlock()
+
+	for _, leaf := range leaves {
+		var theirs []metadata.KeyVersion
+		if err := s.fetchDigest(region, endpoint, tenantID, local.Depth, leaf, &theirs); err != nil {
+			return err
+		}
+		held := make(map[string]metadata.KeyVersion, len(theirs))
+		for _, kv := range theirs {
+			held[kv.Key] = kv
+		}
+		for _, kv := range s.index.MerkleLeafEntries(tenantID, local.Depth, leaf) {
+			meta, err := s.index.Get(tenantID, kv.Key)
+			if err != nil || !containsString(s.replicaRegions(s.ctx, meta), region) {
+				continue // Deleted since, or never replicated there
+			}
+			// Newer versions in the region are its own writes, not losses
+			if other, ok := held[kv.Key]; ok {
+				if other.VersionID == kv.VersionID || s.antiEntropyConflict(region, kv, other, st) {
+					continue
+				}
+			}
+			n, err := s.antiEntropyRepair(region, tenantID, kv.Key)
+			if err != nil {
+				return err
+			}
+			if n < 0 {
+				continue
+			}
+			ae.repaired.Add(1)
+			ae.repairedBytes.Add(uint64(n))
+			ae.mu.Lock()
+			st.Repaired++
+			st.RepairedBytes += n
+			ae.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// antiEntropyConflict counts an object held at different versions locally
+// and in region, and reports whether the region's version wins
+func (s *MinIOServer) antiEntropyConflict(region string, local, other metadata.KeyVersion, st *antiEntropyRegion) bool {
+	ae := s.antiEntropy
+	won, within := 0, 0
+	if other.ModTime >= local.ModTime {
+		won = 1
+	}
+	skew, _ := s.clockSkew.skew(region)
+	if skew != 0 && time.Duration(local.ModTime-other.ModTime).Abs() <= skew.Abs() {
+		within = 1
+	}
+
+	ae.conflicts[won][within].Add(1)
+	ae.mu.Lock()
+	st.Conflicts++
+	st.WithinSkew += within
+	ae.mu.Unlock()
+	return won == 1
+}
+
+// antiEntropyRepair replicates key to region, pacing to
+// AntiEntropyBandwidth. It returns the bytes sent, or -1 if the object
+// has gone since the tree was built.
+func (s *MinIOServer) antiEntropyRepair(region, tenantID, key string) (int64, error) {
+	meta, err := s.index.Get(tenantID, key)
+	if err != nil {
+		return -1, nil
+	}
+	src, err := s.replicaSource(s.ctx, meta)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+
+	var onRegion func(region string, ok bool)
+	if l := s.replicaLedger; l != nil {
+		l.shipping(meta.Tenant, meta.Key, meta.VersionID)
+		onRegion = func(region string, ok bool) { l.acked(meta.Tenant, meta.Key, meta.VersionID, region, ok) }
+	}
+	size := src.size()
+	err = s.enqueueReplica(replication.PriorityBulk, "default", meta.Key, meta.VersionID, "", src, []string{region}, onRegion, func(int) {
+		if s.replicaLedger != nil {
+			s.replicaLedger.done(meta.Tenant, meta.Key)
+		}
+	})
+	if err != nil {
+		src.close()
+		if s.replicaLedger != nil {
+			s.replicaLedger.done(meta.Tenant, meta.Key)
+		}
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+
+	if rate := s.config.AntiEntropyBandwidth; rate > 0 {
+		select {
+		case <-time.After(time.Duration(float64(size) / float64(rate) * float64(time.Second))):
+		case <-s.ctx.Done():
+		}
+	}
+	return size, nil
+}
+
+// fetchDigest reads tenantID's tree from a region's endpoint, or one
+// leaf's entries if leaf is not negative
+func (s *MinIOServer) fetchDigest(region, endpoint, tenantID string, depth, leaf int, out interface{}) error {
+	q := url.Values{"tenant_id": {tenantID}, "depth": {strconv.Itoa(depth)}}
+	if leaf >= 0 {
+		q.Set("leaf", strconv.Itoa(leaf))
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+apiV1+"/admin/replication/digest?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.RegionToken)
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	s.clockSkew.observe(region, sent, resp)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("digest request returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// handleAdminReplicationDigest serves a tenant's Merkle tree, or with
+// ?leaf= the objects in one leaf, to peer regions
+func (s *MinIOServer) handleAdminReplicationDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	stampPeerTime(w)
+	q := r.URL.Query()
+	tenantID := q.Get("tenant_id")
+	depth, err := strconv.Atoi(q.Get("depth"))
+	if tenantID == "" || err != nil && q.Get("depth") != "" {
+		writeErrorMessage(w, r, "tenant_id and a numeric depth are required", http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("leaf"); v != "" {
+		leaf, err := strconv.Atoi(v)
+		if err != nil || leaf < 0 {
+			writeErrorMessage(w, r, "Invalid leaf", http.StatusBadRequest)
+			return
+		}
+		entries := s.index.MerkleLeafEntries(tenantID, depth, leaf)
+		if entries == nil {
+			entries = []metadata.KeyVersion{}
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.index.Merkle(tenantID, depth))
+}
+
+// handleAdminAntiEntropy reports the last anti-entropy run (GET) or starts
+// one now (POST)
+func (s *MinIOServer) handleAdminAntiEntropy(w http.ResponseWriter, r *http.Request) {
+	ae := s.antiEntropy
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		select {
+		case ae.kick <- struct{}{}:
+		default:
+		}
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		status = http.StatusAccepted
+	}
+	writeJSON(w, status, map[string]interface{}{"running": ae.running, "last_run": ae.last})
+}
@@ -0,0 +1,128 @@
+// cmd/server/auth.go
+// Request authentication. A request naming a tenant must carry credentials:
+// a tenant token or service account keys for the tenant, a console session
+// of one of its users, or the admin token. The principal they identify is
+// bound to the request's context, so handlers authorize against it rather
+// than the tenant the request names. Credentials for another tenant are
+// accepted only on object routes, where the object's ACL decides.
+//
+// The admin API authenticates itself; public reads, share link downloads,
+// sign-in and node health are anonymous.
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// principalKey binds a request's authenticated principal to its context
+type principalKey struct{}
+
+// adminKey marks the context of a request admitted on admin credentials
+type adminKey struct{}
+
+// anonymousRoutes are served without credentials
+var anonymousRoutes = []string{"/public", "/health", "/sso/", "/iam/"}
+
+// aclRoutes are the object routes whose handlers authorize each object
+// against its ACL, and so admit another tenant's principals
+var aclRoutes = []string{"/download", "/stat", "/upload", "/delete", "/delete-batch",
+	"/tagging", "/acl", "/copy", "/rename", "/kv/batch"}
+
+// withAuth authenticates requests that name a tenant. Unless auth is
+// required, requests without credentials still pass as the tenant they
+// name.
+func (s *MinIOServer) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, path := tenantFromRequest(r), unversionedPath(r.URL.Path)
+		if tenantID == "" || strings.HasPrefix(path, "/admin/") || routeIn(path, anonymousRoutes) ||
+			path == "/download" && r.URL.Query().Get("share") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, admin, err := s.authenticate(r, tenantID)
+		switch {
+		case err != nil:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="minio"`)
+			writeError(w, r, err)
+			return
+		case admin:
+			r = r.WithContext(context.WithValue(r.Context(), adminKey{}, true))
+		case p == nil:
+			if s.config.RequireAuth {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="minio"`)
+				writeError(w, r, errNoCredentials)
+				return
+			}
+		case p.tenantID != tenantID && !routeIn(path, aclRoutes):
+			writeError(w, r, errOtherTenant)
+			return
+		default:
+			r = r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auditActor names who made the request behind ctx in audit events: its
+// principal, "admin" for admin credentials, or the tenant named by a
+// request without credentials
+func auditActor(ctx context.Context, tenantID string) string {
+	if p, ok := ctx.Value(principalKey{}).(*principal); ok {
+		return p.actor(tenantID)
+	}
+	if admin, _ := ctx.Value(adminKey{}).(bool); admin {
+		return "admin"
+	}
+	return tenantID
+}
+
+// routeIn reports whether path is one of routes, or under one ending in "/"
+func routeIn(path string, routes []string) bool {
+	for _, route := range routes {
+		if path == route || strings.HasSuffix(route, "/") && strings.HasPrefix(path, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate verifies r's credentials. It returns the principal they
+// identify, or admin for the admin token or an admin's session; neither
+// for a request without credentials. Credentials that fail to verify are
+// an error, not a request without credentials.
+func (s *MinIOServer) authenticate(r *http.Request, tenantID string) (p *principal, admin bool, err error) {
+	ctx := r.Context()
+	if s.isAdminRequest(r) {
+		return nil, true, nil
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		claims, err := s.tenantManager.VerifyTenantToken(ctx, token)
+		if errors.Is(err, tenant.ErrTokenExpired) {
+			return nil, false, &httpError{http.StatusUnauthorized, codeUnauthorized, "Tenant token expired"}
+		}
+		if err != nil {
+			return nil, false, &httpError{http.StatusUnauthorized, codeUnauthorized, "Invalid tenant token"}
+		}
+		return &principal{tenantID: claims.TenantID, subject: claims.Subject, permissions: claims.Permissions}, false, nil
+	}
+	if accessKey, secretKey, ok := r.BasicAuth(); ok {
+		acct, err := s.tenantManager.VerifyServiceAccountCredential(ctx, accessKey, secretKey)
+		if err != nil {
+			return nil, false, &httpError{http.StatusUnauthorized, codeUnauthorized, "Invalid service account credentials"}
+		}
+		return &principal{tenantID: acct.TenantID, subject: acct.ID, permissions: acct.Permissions}, false, nil
+	}
+	if sess, ok := s.session(r); ok {
+		if !sess.Identity.HasTenant(tenantID) {
+			return nil, false, errOtherTenant
+		}
+		return &principal{tenantID: tenantID, subject: sess.Identity.Subject, permissions: sess.Identity.Permissions()}, false, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// Requests naming a tenant need credentials that verify, and for another
+// tenant pass only on object routes, where the ACL decides
+func TestAuthentication(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	list := "/v1/list?tenant_id=" + tenantID
+
+	w := do(t, "GET", list, nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	if w.Header().Get("WWW-Authenticate") == "" {
+		t.Error("401 without WWW-Authenticate")
+	}
+	testServer.config.RequireAuth = false
+	w = do(t, "GET", list, nil)
+	testServer.config.RequireAuth = true
+	expectStatus(t, w, http.StatusOK)
+
+	w = do(t, "GET", list, nil, bearer("not-a-token"))
+	expectStatus(t, w, http.StatusUnauthorized)
+	expired, err := testServer.tenantManager.IssueSubjectToken(context.Background(), tenantID, "alice", []string{"object:read"}, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	w = do(t, "GET", list, nil, bearer(expired))
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "GET", list, nil, func(r *http.Request) { r.SetBasicAuth("AKNOTREAL", "secret") })
+	expectStatus(t, w, http.StatusUnauthorized)
+
+	w = do(t, "GET", list, nil, bearer(tenantToken(t, tenantID, "alice", "object:read")))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", list, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	otherID := newTenant(t)
+	other := bearer(tenantToken(t, otherID, "carol", "object:read"))
+	w = do(t, "GET", list, nil, other)
+	expectStatus(t, w, http.StatusForbidden)
+	download := "/v1/download?tenant_id=" + tenantID + "&key=doc.txt"
+	w = do(t, "GET", download, nil, other)
+	expectStatus(t, w, http.StatusForbidden)
+	grants := map[string][]metadata.Grant{"grants": {{Grantee: "tenant:" + otherID, Permission: metadata.ACLRead}}}
+	w = do(t, "PUT", "/v1/acl?tenant_id="+tenantID+"&key=doc.txt", grants, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", download, nil, other)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "hello" {
+		t.Errorf("Downloaded %q, want hello", w.Body.String())
+	}
+}
+
+// Anonymous routes are served without credentials even when auth is
+// required
+func TestAnonymousRoutes(t *testing.T) {
+	tenantID := newTenant(t)
+	for _, target := range []string{
+		"/v1/health",
+		"/v1/public?tenant_id=" + tenantID + "&key=doc.txt",
+		"/v1/sso/login?tenant_id=" + tenantID,
+		"/v1/iam/login?tenant_id=" + tenantID,
+		"/v1/download?tenant_id=" + tenantID + "&share=unknown",
+	} {
+		w := do(t, "GET", target, nil)
+		if w.Code == http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "" {
+			t.Errorf("GET %s: status %d, want no authentication challenge", target, w.Code)
+		}
+	}
+}
+
+// Object audit events record the principal that acted, not the tenant
+func TestAuditActor(t *testing.T) {
+	tenantID := newTenant(t)
+	if err := testServer.tenantManager.UpdateSettings(context.Background(), tenantID, tenant.TenantSettings{AuditLogging: true}); err != nil {
+		t.Fatal(err)
+	}
+	writer := bearer(tenantToken(t, tenantID, "alice", "object:write", "object:delete"))
+	w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=a.txt", "data", writer)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "DELETE", "/v1/delete?tenant_id="+tenantID+"&key=a.txt", nil, writer)
+	expectStatus(t, w, http.StatusNoContent)
+	upload(t, tenantID, "b.txt", "data")
+
+	want := map[string]string{"object.put a.txt": "alice", "object.delete a.txt": "alice", "object.put b.txt": "admin"}
+	for _, ev := range auditEvents(t, tenantID) {
+		if actor, ok := want[ev.Action+" "+ev.Resource]; ok {
+			if ev.Actor != actor {
+				t.Errorf("%s %s: actor %q, want %q", ev.Action, ev.Resource, ev.Actor, actor)
+			}
+			delete(want, ev.Action+" "+ev.Resource)
+		}
+	}
+	for event := range want {
+		t.Errorf("No %s audit event", event)
+	}
+}
+
+// auditEvents reads the audit log's events for tenantID
+func auditEvents(t *testing.T, tenantID string) []audit.Event {
+	t.Helper()
+	f, err := os.Open(testServer.auditLog.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []audit.Event
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var ev audit.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("Audit log: %v", err)
+		}
+		if ev.TenantID == tenantID {
+			events = append(events, ev)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
@@ -0,0 +1,304 @@
+// cmd/server/billing.go
+// Billing exports: each tenant's metered usage over a billing period, read
+// from the daily rollups and delivered as CSV and JSON under a bucket of
+// the billing tenant and to a webhook. A period is exported once it has
+// closed; regenerating it rewrites the same keys and resends under the
+// same delivery ID, and is skipped when nothing changed.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/notify"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// billingCheckInterval is how often the exporter looks for a closed period
+const billingCheckInterval = 10 * time.Minute
+
+// Webhook event and digest header of billing exports
+const (
+	billingEvent        = "billing.export"
+	headerBillingDigest = "X-MinIO-Billing-Digest"
+)
+
+// billingMeter maps a rolled up tenant counter to line items
+type billingMeter struct {
+	metric    string
+	usageType string
+	operation string
+	unit      string
+	scale     float64
+}
+
+// billingMeters lists what is billed: storage, requests by class, egress,
+// ingress and replication. Public reads' egress is billed as egress with
+// operation "public".
+func billingMeters() []billingMeter {
+	meters := []billingMeter{
+		{metricStorageByteMins, tenant.UsageStorage, "", tenant.UnitByteHours, 1.0 / 60},
+		{metricEgressBytes, tenant.UsageEgress, "", tenant.UnitBytes, 1},
+		{metricPublicEgress, tenant.UsageEgress, requestPublic, tenant.UnitBytes, 1},
+		{metricIngressBytes, tenant.UsageIngress, "", tenant.UnitBytes, 1},
+		{metricReplicationBytes, tenant.UsageReplication, "", tenant.UnitBytes, 1},
+	}
+	for _, class := range requestClasses {
+		meters = append(meters, billingMeter{metricRequests + "_" + class, tenant.UsageRequests, class, tenant.UnitRequests, 1})
+	}
+	return meters
+}
+
+// billingLedger records the digest of the export last delivered for each
+// period, persisted so a restart neither skips nor repeats a delivery
+type billingLedger struct {
+	path string
+
+	mu      sync.Mutex
+	digests map[string]string
+
+	exports  atomic.Uint64
+	failures atomic.Uint64
+}
+
+// openBillingLedger loads the ledger saved at path, if any
+func openBillingLedger(path string) (*billingLedger, error) {
+	l := &billingLedger{path: path, digests: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &l.digests); err != nil {
+		return nil, fmt.Errorf("failed to parse billing ledger: %w", err)
+	}
+	return l, nil
+}
+
+// delivered is the digest last delivered for period, "" if none
+func (l *billingLedger) delivered(period string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.digests[period]
+}
+
+// record notes that period's export with digest was delivered
+func (l *billingLedger) record(period, digest string) error {
+	l.mu.Lock()
+	l.digests[period] = digest
+	data, err := json.Marshal(l.digests)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// billingExportResult reports a regeneration
+type billingExportResult struct {
+	Period    string `json:"period"`
+	Digest    string `json:"digest"`
+	LineItems int    `json:"line_items"`
+	Delivered bool   `json:"delivered"` // False when unchanged since last delivered
+}
+
+// billingEnabled reports whether exports have a destination
+func (s *MinIOServer) billingEnabled() bool {
+	return s.config.BillingTenant != "" || s.config.BillingWebhookURL != ""
+}
+
+// lastClosedBillingPeriod is the latest period that ended at least
+// BillingExportDelay ago
+func (s *MinIOServer) lastClosedBillingPeriod() tenant.BillingPeriod {
+	current, _ := tenant.BillingPeriodAt(s.config.BillingPeriod, time.Now().Add(-s.config.BillingExportDelay))
+	return current.Previous()
+}
+
+// buildBillingExport collects every tenant's line items for period from
+// the daily rollups. Usage of deleted tenants is gone with their rollups.
+func (s *MinIOServer) buildBillingExport(ctx context.Context, period tenant.BillingPeriod) *tenant.BillingExport {
+	meters := billingMeters()
+	last := period.End.Add(-time.Nanosecond)
+
+	var items []tenant.BillingLineItem
+	for _, tenantID := range s.tenantManager.ListTenants(ctx) {
+		plan, _ := s.tenantManager.TenantPlan(ctx, tenantID)
+		for _, m := range meters {
+			key := monitoring.SeriesKey{TenantID: tenantID, Metric: m.metric}
+			points, _ := s.rollups.Query(key, monitoring.ResolutionDay, period.Start, last)
+			for _, p := range points {
+				if p.Value == 0 {
+					continue
+				}
+				items = append(items, tenant.BillingLineItem{
+					UsageStart:  p.Time,
+					UsageEnd:    p.Time.AddDate(0, 0, 1),
+					TenantID:    tenantID,
+					Plan:        plan,
+					UsageType:   m.usageType,
+					Operation:   m.operation,
+					UsageAmount: float64(p.Value) * m.scale,
+					Unit:        m.unit,
+				})
+			}
+		}
+	}
+	return tenant.NewBillingExport(period, items)
+}
+
+// exportBilling builds period's export and delivers it unless the same
+// export was already delivered
+func (s *MinIOServer) exportBilling(ctx context.Context, period tenant.BillingPeriod) (billingExportResult, error) {
+	export := s.buildBillingExport(ctx, period)
+	digest, err := export.Digest()
+	if err != nil {
+		return billingExportResult{}, err
+	}
+	result := billingExportResult{Period: export.Period, Digest: digest, LineItems: len(export.LineItems)}
+	if s.billing.delivered(export.Period) == digest {
+		return result, nil
+	}
+
+	if err := s.deliverBilling(ctx, export, digest); err != nil {
+		s.billing.failures.Add(1)
+		return result, err
+	}
+	s.billing.exports.Add(1)
+	result.Delivered = true
+	return result, s.billing.record(export.Period, digest)
+}
+
+// deliverBilling writes the export's files to the billing bucket and posts
+// the JSON to the billing webhook
+func (s *MinIOServer) deliverBilling(ctx context.Context, export *tenant.BillingExport, digest string) error {
+	body, err := export.JSON()
+	if err != nil {
+		return err
+	}
+	if s.config.BillingTenant != "" {
+		if _, err := s.tenantManager.GetTenant(ctx, s.config.BillingTenant); err != nil {
+			return fmt.Errorf("billing tenant %s not found", s.config.BillingTenant)
+		}
+		prefix := s.config.BillingBucket + "/" + export.Period + "/"
+		if err := s.putObject(ctx, s.config.BillingTenant, prefix+"usage.json", body); err != nil {
+			return fmt.Errorf("failed to write billing export: %w", err)
+		}
+		if err := s.putObject(ctx, s.config.BillingTenant, prefix+"usage.csv", export.CSV()); err != nil {
+			return fmt.Errorf("failed to write billing export: %w", err)
+		}
+	}
+	if s.config.BillingWebhookURL == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.BillingWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(notify.HeaderEvent, billingEvent)
+	req.Header.Set(notify.HeaderDelivery, "billing-"+export.Period) // The same for every regeneration
+	req.Header.Set(headerBillingDigest, digest)
+	if s.config.WebhookSecret != "" {
+		req.Header.Set(notify.HeaderSignature, notify.Sign(s.config.WebhookSecret, time.Now(), body))
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("billing webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("billing webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// billingExporter exports each period once it has closed, retrying a
+// failed delivery at the next check
+func (s *MinIOServer) billingExporter() {
+	if !s.billingEnabled() {
+		return
+	}
+	ticker := time.NewTicker(billingCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		period := s.lastClosedBillingPeriod()
+		if s.billing.delivered(period.String()) == "" {
+			if _, err := s.exportBilling(s.ctx, period); err != nil {
+				log.Printf("Billing export of %s failed: %v", period, err)
+			}
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleAdminBillingExport returns a period's export (GET, ?format=csv for
+// CSV) or regenerates and delivers it (POST). ?period= is a month or a
+// day, default the last closed period.
+func (s *MinIOServer) handleAdminBillingExport(w http.ResponseWriter, r *http.Request) {
+	period := s.lastClosedBillingPeriod()
+	if raw := r.URL.Query().Get("period"); raw != "" {
+		p, err := tenant.ParseBillingPeriod(raw)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		period = p
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		export := s.buildBillingExport(r.Context(), period)
+		switch r.URL.Query().Get("format") {
+		case "", "json":
+			writeJSON(w, http.StatusOK, export)
+		case "csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "billing-"+export.Period+".csv"))
+			w.Write(export.CSV())
+		default:
+			writeErrorMessage(w, r, "format must be json or csv", http.StatusBadRequest)
+		}
+
+	case http.MethodPost:
+		if !s.billingEnabled() {
+			writeErrorMessage(w, r, "No billing export destination is configured", http.StatusConflict)
+			return
+		}
+		result, err := s.exportBilling(r.Context(), period)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
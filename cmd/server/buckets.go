@@ -0,0 +1,205 @@
+// cmd/server/buckets.go
+// Bucket endpoints: tenants create, list, configure and delete buckets.
+// A bucket holds the keys that begin with its name and a "/"; its
+// configuration (object lock, append-only) applies to those keys on top
+// of the tenant's settings, and is replicated to peer regions with them.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/replication"
+	"github.com/minio/enterprise/internal/tenant"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// configBucket is the replication namespace for tenant configuration, kept
+// apart from object data
+const configBucket = "config"
+
+// createBucketRequest is the body of a bucket creation
+type createBucketRequest struct {
+	Name   string              `json:"name"`
+	Config tenant.BucketConfig `json:"config"`
+}
+
+// bucketFor returns the created bucket holding key, if any
+func (s *MinIOServer) bucketFor(ctx context.Context, tenantID, key string) (*tenant.Bucket, bool) {
+	name := bucketOf(key)
+	if name == "" {
+		return nil, false
+	}
+	b, err := s.tenantManager.GetBucket(ctx, tenantID, name)
+	return b, err == nil
+}
+
+// checkBucket refuses writes to keys outside a created bucket when
+// buckets are required
+func (s *MinIOServer) checkBucket(ctx context.Context, tenantID, key string) error {
+	if !s.config.RequireBuckets {
+		return nil
+	}
+	if _, ok := s.bucketFor(ctx, tenantID, key); !ok {
+		return errNoSuchBucket
+	}
+	return nil
+}
+
+// replicateBucket queues b's record for peer regions, so its config holds
+// wherever its objects are replicated
+func (s *MinIOServer) replicateBucket(ctx context.Context, b *tenant.Bucket) error {
+	data, err := json.Marshal(b)
+	if err == nil {
+		err = s.replicationEngine.EnqueueWithPriority(replication.PriorityInteractive, configBucket,
+			"tenants/"+b.TenantID+"/buckets/"+b.Name, newVersionID(), tracing.RequestID(ctx), data, nil, nil, nil)
+	}
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return errBucketNotQueued
+	}
+	return nil
+}
+
+// bucketError maps tenant manager bucket errors to responses
+func bucketError(err error) error {
+	var he *httpError
+	switch {
+	case errors.As(err, &he):
+		return he
+	case errors.Is(err, tenant.ErrBucketNotFound):
+		return errNoSuchBucket
+	case errors.Is(err, tenant.ErrBucketExists):
+		return errBucketExists
+	}
+	return &httpError{http.StatusBadRequest, codeInvalidRequest, err.Error()}
+}
+
+// handleBuckets lists buckets (GET) or returns one (GET ?bucket=), creates
+// one (POST), replaces a bucket's config (PUT ?bucket=) or deletes an
+// empty bucket (DELETE ?bucket=). Needs tenant credentials.
+func (s *MinIOServer) handleBuckets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantCredentials(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if _, err := s.tenantManager.GetTenant(ctx, tenantID); err != nil {
+		writeErrorMessage(w, r, "Tenant not found", http.StatusNotFound)
+		return
+	}
+	name := r.URL.Query().Get("bucket")
+	if name == "" && (r.Method == http.MethodPut || r.Method == http.MethodDelete) {
+		writeErrorMessage(w, r, "Missing bucket", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if name == "" {
+			writeJSON(w, http.StatusOK, s.tenantManager.ListBuckets(ctx, tenantID))
+			return
+		}
+		b, err := s.tenantManager.GetBucket(ctx, tenantID, name)
+		if err != nil {
+			writeError(w, r, bucketError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, b)
+
+	case http.MethodPost:
+		var req createBucketRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		// A config peers would not get is not kept
+		b, err := s.tenantManager.CreateBucket(ctx, tenantID, req.Name, req.Config)
+		if err == nil {
+			if err = s.replicateBucket(ctx, b); err != nil {
+				s.tenantManager.DeleteBucket(ctx, tenantID, req.Name)
+			}
+		}
+		s.auditBucket(ctx, tenantID, "bucket.create", req.Name, err)
+		if err != nil {
+			writeError(w, r, bucketError(err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, b)
+
+	case http.MethodPut:
+		var config tenant.BucketConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeErrorMessage(w, r, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		prev, err := s.tenantManager.GetBucket(ctx, tenantID, name)
+		var b *tenant.Bucket
+		if err == nil {
+			b, err = s.tenantManager.UpdateBucketConfig(ctx, tenantID, name, config)
+		}
+		if err == nil {
+			if err = s.replicateBucket(ctx, b); err != nil {
+				s.tenantManager.UpdateBucketConfig(ctx, tenantID, name, prev.Config)
+			}
+		}
+		s.auditBucket(ctx, tenantID, "bucket.config", name, err)
+		if err != nil {
+			writeError(w, r, bucketError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, b)
+
+	case http.MethodDelete:
+		err := s.deleteBucket(ctx, tenantID, name)
+		s.auditBucket(ctx, tenantID, "bucket.delete", name, err)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// deleteBucket removes tenantID's bucket name if it holds no objects
+func (s *MinIOServer) deleteBucket(ctx context.Context, tenantID, name string) error {
+	if _, err := s.tenantManager.GetBucket(ctx, tenantID, name); err != nil {
+		return bucketError(err)
+	}
+	if page := s.index.ListDelimited(tenantID, name+metadata.AggregateDelimiter, "", "", 1); len(page.Objects) > 0 {
+		return errBucketNotEmpty
+	}
+	if err := s.tenantManager.DeleteBucket(ctx, tenantID, name); err != nil {
+		return bucketError(err)
+	}
+	return nil
+}
+
+// auditBucket records a bucket change for tenants that audit
+func (s *MinIOServer) auditBucket(ctx context.Context, tenantID, action, name string, err error) {
+	if !s.auditsTenant(ctx, tenantID) {
+		return
+	}
+	ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: action, Resource: name}
+	if err != nil {
+		ev.Outcome = audit.OutcomeError
+		ev.Details = map[string]string{"error": err.Error()}
+	}
+	s.logAudit(ctx, ev)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// awaitReplicated waits for at least n more bytes than since to reach peer
+// regions
+func awaitReplicated(t *testing.T, since uint64, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for testServer.replicationEngine.GetStats().ReplicatedBytes.Load()-since < uint64(n) {
+		if time.Now().After(deadline) {
+			t.Fatal("Bucket config not replicated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// An append-only bucket takes new keys but refuses client overwrites and
+// deletes of its own, and its config is replicated to peer regions
+func TestAppendOnlyBucket(t *testing.T) {
+	tenantID := newTenant(t)
+	buckets := "/v1/buckets?tenant_id=" + tenantID
+	replicated := testServer.replicationEngine.GetStats().ReplicatedBytes.Load()
+	w := do(t, "POST", buckets, createBucketRequest{Name: "logs", Config: tenant.BucketConfig{AppendOnly: true}}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+	var b tenant.Bucket
+	decode(t, w, &b)
+	data, _ := json.Marshal(b)
+	awaitReplicated(t, replicated, len(data))
+
+	upload(t, tenantID, "logs/a.txt", "first")
+	upload(t, tenantID, "logs/b.txt", "second")
+	upload(t, tenantID, "other.txt", "other")
+	upload(t, tenantID, "other.txt", "replaced")
+	object := "?tenant_id=" + tenantID + "&key=logs/a.txt"
+	conflict := func(name string, w *httptest.ResponseRecorder) {
+		t.Helper()
+		var resp errorResponse
+		if w.Code != http.StatusConflict || json.Unmarshal(w.Body.Bytes(), &resp) != nil || resp.Code != codeAppendOnly {
+			t.Errorf("%s: status %d %s, want 409 AppendOnly", name, w.Code, w.Body.String())
+		}
+	}
+	conflict("overwrite", do(t, "PUT", "/v1/upload"+object, "again", adminAuth))
+	conflict("delete", do(t, "DELETE", "/v1/delete"+object, nil, adminAuth))
+	conflict("copy over", do(t, "PUT", "/v1/copy"+object+"&source_key=other.txt", nil, adminAuth))
+	conflict("rename out", do(t, "POST", "/v1/rename?tenant_id="+tenantID+"&source_key=logs/a.txt&key=c.txt", nil, adminAuth))
+	conflict("rename over", do(t, "POST", "/v1/rename?tenant_id="+tenantID+"&source_key=other.txt&key=logs/a.txt&overwrite=true", nil, adminAuth))
+
+	w = do(t, "POST", "/v1/kv/batch?tenant_id="+tenantID, map[string]interface{}{"delete": []string{"logs/b.txt"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var kv kvBatchResponse
+	if decode(t, w, &kv); len(kv.Results) != 1 || kv.Results[0].Status != http.StatusConflict {
+		t.Errorf("KV delete results %+v, want a conflict", kv.Results)
+	}
+	w = do(t, "POST", "/v1/delete-batch?tenant_id="+tenantID, deleteBatchRequest{Keys: []string{"logs/b.txt", "other.txt"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var batch struct {
+		Deleted, Failed int
+		Results         []deleteBatchResult
+	}
+	if decode(t, w, &batch); batch.Deleted != 1 || batch.Failed != 1 || batch.Results[0].Code != codeAppendOnly {
+		t.Errorf("Batch delete %+v, want logs/b.txt refused and other.txt deleted", batch)
+	}
+	w = do(t, "GET", "/v1/download"+object, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "first" {
+		t.Errorf("Downloaded %q, want the original", w.Body.String())
+	}
+
+	// Lifting the flag is replicated too
+	replicated = testServer.replicationEngine.GetStats().ReplicatedBytes.Load()
+	w = do(t, "PUT", buckets+"&bucket=logs", tenant.BucketConfig{}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &b)
+	data, _ = json.Marshal(b)
+	awaitReplicated(t, replicated, len(data))
+	w = do(t, "PUT", "/v1/upload"+object, "again", adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	// Lifecycle removals are not client deletes
+	w = do(t, "PUT", buckets+"&bucket=logs", tenant.BucketConfig{AppendOnly: true}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "DELETE", "/v1/admin/tenants?id="+tenantID+"&purge=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+}
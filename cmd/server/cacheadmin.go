@@ -0,0 +1,177 @@
+// cmd/server/cacheadmin.go
+// Cache administration: stats, per-tier and per-shard detail, top keys,
+// flushes and manual tier demotion
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/cache"
+)
+
+// adminCacheKey maps ?key= to a cache key: the tenant's RESP entry when
+// ?tenant_id= is given, otherwise the raw key, which for object data is
+// <tenant>/<key>
+func adminCacheKey(r *http.Request) string {
+	key := r.URL.Query().Get("key")
+	if tenantID := r.URL.Query().Get("tenant_id"); tenantID != "" {
+		return cache.TenantKey(tenantID, respCacheKey(key))
+	}
+	return key
+}
+
+// handleAdminCacheStats reports global counters, plus per-shard occupancy
+// with ?shards=true
+func (s *MinIOServer) handleAdminCacheStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	shards := s.cacheManager.Occupancy()
+	var entries, bytes int64
+	var busiest cache.ShardOccupancy
+	for _, sh := range shards {
+		entries += sh.Entries
+		bytes += sh.Bytes
+		if sh.Bytes > busiest.Bytes {
+			busiest = sh
+		}
+	}
+
+	stats := s.cacheManager.GetStats()
+	resp := map[string]interface{}{
+		"entries":       entries,
+		"bytes":         bytes,
+		"hits":          stats.TotalHits.Load(),
+		"misses":        stats.TotalMisses.Load(),
+		"evictions":     stats.TotalEvictions.Load(),
+		"expirations":   stats.Expirations.Load(),
+		"l1_hits":       stats.L1Hits.Load(),
+		"l2_hits":       stats.L2Hits.Load(),
+		"l3_hits":       stats.L3Hits.Load(),
+		"shard_count":   len(shards),
+		"busiest_shard": busiest,
+		"placement":     s.placement.Name(),
+		"filter": map[string]interface{}{
+			"negatives":           stats.FilterNegatives.Load(),
+			"false_positives":     stats.FilterFalsePositives.Load(),
+			"false_positive_rate": stats.FilterFalsePositiveRate(),
+			"rebuilds":            stats.FilterRebuilds.Load(),
+		},
+	}
+	if r.URL.Query().Get("shards") == "true" {
+		resp["shards"] = shards
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAdminCacheDetail reports per-tier and per-shard statistics with the
+// ?hottest= busiest shards (default 10), and every shard with ?shards=true
+func (s *MinIOServer) handleAdminCacheDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	hottest := 10
+	if raw := query.Get("hottest"); raw != "" {
+		var err error
+		if hottest, err = strconv.Atoi(raw); err != nil || hottest < 0 || hottest > 10000 {
+			writeErrorMessage(w, r, "hottest must be between 0 and 10000", http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.cacheManager.DetailedStats(hottest, query.Get("shards") == "true"))
+}
+
+// handleAdminCacheTop lists the largest (?by=size) or hottest (?by=hits)
+// entries, ?n= of them (default 20)
+func (s *MinIOServer) handleAdminCacheTop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	by := query.Get("by")
+	if by == "" {
+		by = cache.TopBySize
+	}
+	n := 20
+	if raw := query.Get("n"); raw != "" {
+		var err error
+		if n, err = strconv.Atoi(raw); err != nil || n <= 0 || n > 10000 {
+			writeErrorMessage(w, r, "n must be between 1 and 10000", http.StatusBadRequest)
+			return
+		}
+	}
+
+	keys, err := s.cacheManager.TopKeys(n, by)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// handleAdminCacheFlush drops cache entries (POST). With ?tenant_id= it
+// flushes that tenant's RESP entries under ?prefix=; without it, raw cache
+// keys under ?prefix=. Cached data of indexed objects is the stored copy,
+// so it is never flushed.
+func (s *MinIOServer) handleAdminCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	tenantID, prefix := query.Get("tenant_id"), query.Get("prefix")
+
+	var entries, bytes int64
+	if tenantID != "" {
+		entries, bytes = s.cacheManager.Tenant(tenantID).Flush(respCacheKey(prefix))
+	} else {
+		objects := make(map[string]bool)
+		for _, meta := range s.index.Snapshot() {
+			if cached(&meta) {
+				objects[cache.TenantKey(meta.Tenant, meta.Key)] = true
+			}
+		}
+		entries, bytes = s.cacheManager.FlushPrefix(prefix, func(key string) bool { return objects[key] })
+	}
+
+	s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "cache.flush", Resource: prefix,
+		Details: map[string]string{"entries": strconv.FormatInt(entries, 10), "bytes": strconv.FormatInt(bytes, 10)}})
+	writeJSON(w, http.StatusOK, map[string]int64{"entries": entries, "bytes": bytes})
+}
+
+// handleAdminCacheDemote moves ?key= to a colder ?tier= (default 1 = L2) (POST)
+func (s *MinIOServer) handleAdminCacheDemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tier := 1
+	if raw := r.URL.Query().Get("tier"); raw != "" {
+		var err error
+		if tier, err = strconv.Atoi(raw); err != nil || tier < 0 || tier > 255 {
+			writeErrorMessage(w, r, "Invalid tier", http.StatusBadRequest)
+			return
+		}
+	}
+
+	key := adminCacheKey(r)
+	info, err := s.cacheManager.Demote(key, uint8(tier))
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.logAudit(r.Context(), audit.Event{TenantID: r.URL.Query().Get("tenant_id"), Actor: "admin", Action: "cache.demote", Resource: r.URL.Query().Get("key"),
+		Details: map[string]string{"tier": strconv.Itoa(int(info.Tier))}})
+	writeJSON(w, http.StatusOK, info)
+}
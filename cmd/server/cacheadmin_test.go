@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/cache"
+)
+
+// Admins inspect the cache, demote entries and flush a tenant's RESP
+// entries, but never the cached data of indexed objects
+func TestAdminCache(t *testing.T) {
+	tenantID := newTenant(t)
+	c := newRESPClient(t)
+	c.do("AUTH", tenantToken(t, tenantID, "app", "object:read", "object:write"))
+	big := strings.Repeat("x", 1<<20)
+	c.do("SET", "a/1", big)
+	c.do("SET", "a/2", "small")
+	c.do("SET", "b/1", "small")
+	doc := strings.Repeat("d", testServer.config.InlineObjectBytes+1) // Cached, not inline
+	upload(t, tenantID, "doc.txt", doc)
+
+	w := do(t, "GET", "/v1/admin/cache/stats", nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "GET", "/v1/admin/cache/stats?shards=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var stats struct {
+		Entries int64                  `json:"entries"`
+		Shards  []cache.ShardOccupancy `json:"shards"`
+	}
+	if decode(t, w, &stats); stats.Entries < 4 || len(stats.Shards) == 0 {
+		t.Errorf("Stats report %d entries in %d shards", stats.Entries, len(stats.Shards))
+	}
+	w = do(t, "POST", "/v1/admin/cache/stats", nil, adminAuth)
+	expectStatus(t, w, http.StatusMethodNotAllowed)
+	w = do(t, "GET", "/v1/admin/cache/detail?hottest=-1", nil, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = do(t, "GET", "/v1/admin/cache/top?by=size&n=1", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var top []cache.KeyInfo
+	if decode(t, w, &top); len(top) != 1 || top[0].Size < int64(len(big)) {
+		t.Errorf("Largest entry %+v, want the 1 MiB value", top)
+	}
+	w = do(t, "GET", "/v1/admin/cache/top?by=age", nil, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "GET", "/v1/admin/cache/top?n=0", nil, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = do(t, "POST", "/v1/admin/cache/demote?tenant_id="+tenantID+"&key=b/1&tier=1", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var info cache.KeyInfo
+	if decode(t, w, &info); info.Tier != 1 {
+		t.Errorf("Demoted to tier %d, want 1", info.Tier)
+	}
+	w = do(t, "POST", "/v1/admin/cache/demote?tenant_id="+tenantID+"&key=missing", nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+	if got := c.do("GET", "b/1"); got != "small" {
+		t.Errorf("Demoted entry reads %q", got)
+	}
+
+	w = do(t, "POST", "/v1/admin/cache/flush?tenant_id="+tenantID+"&prefix=a/", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var flushed map[string]int64
+	if decode(t, w, &flushed); flushed["entries"] != 2 {
+		t.Errorf("Flushed %v, want 2 entries", flushed)
+	}
+	if got := c.do("MGET", "a/1", "a/2", "b/1"); got != "(nil),(nil),small" {
+		t.Errorf("After flush MGET = %q", got)
+	}
+
+	w = do(t, "POST", "/v1/admin/cache/flush?prefix="+cache.TenantKey(tenantID, ""), nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=doc.txt", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != doc {
+		t.Error("Object lost to a raw flush")
+	}
+}
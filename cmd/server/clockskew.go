@@ -0,0 +1,231 @@
+// cmd/server/clockskew.go
+// Clock skew between regions: responses to peer regions carry the node's
+// clock, and every request to a peer estimates the peer's offset as its
+// clock minus the midpoint of the round trip. Last-write-wins across
+// regions orders versions by these clocks, so skew past the thresholds
+// raises an alert, and the conflicts anti-entropy resolves are counted by
+// whether the versions were written closer together than the skew.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/enterprise/internal/monitoring"
+)
+
+// peerTimeHeader carries the responding node's clock in Unix nanoseconds
+const peerTimeHeader = "X-Minio-Peer-Time"
+
+// clockSamples is the samples kept per region; the estimate comes from the
+// one with the shortest round trip, whose midpoint is the least uncertain
+const clockSamples = 8
+
+// Clock skew statuses
+const (
+	clockStatusOK       = "ok"
+	clockStatusWarning  = "warning"
+	clockStatusCritical = "critical"
+)
+
+// clockSkew tracks the clock offsets of the peer regions
+type clockSkew struct {
+	warn, critical time.Duration
+	alerts         *monitoring.AlertManager
+
+	mu    sync.Mutex
+	peers map[string]*peerClock
+}
+
+// peerClock is one region's recent samples
+type peerClock struct {
+	samples [clockSamples]clockSample
+	n       int // Samples taken
+	status  string
+}
+
+type clockSample struct {
+	offset time.Duration // Peer clock minus ours
+	rtt    time.Duration
+	at     time.Time
+}
+
+// peerClockStatus is the admin API view of a region's clock
+type peerClockStatus struct {
+	Region    string    `json:"region"`
+	SkewMs    float64   `json:"skew_ms"` // Positive if the peer is ahead
+	RTTMs     float64   `json:"rtt_ms"`
+	Samples   int       `json:"samples"`
+	SampledAt time.Time `json:"sampled_at"`
+	Status    string    `json:"status"`
+}
+
+func newClockSkew(warn, critical time.Duration, alerts *monitoring.AlertManager) *clockSkew {
+	return &clockSkew{warn: warn, critical: critical, alerts: alerts, peers: make(map[string]*peerClock)}
+}
+
+// stampPeerTime adds this node's clock to a response for peer regions
+func stampPeerTime(w http.ResponseWriter) {
+	w.Header().Set(peerTimeHeader, strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// observe records a sample from a response to a request to region sent at
+// sent. Responses without the header, from older nodes, are ignored.
+func (c *clockSkew) observe(region string, sent time.Time, resp *http.Response) {
+	received := time.Now()
+	peer, err := strconv.ParseInt(resp.Header.Get(peerTimeHeader), 10, 64)
+	if err != nil {
+		return
+	}
+	rtt := received.Sub(sent)
+	sample := clockSample{
+		offset: time.Unix(0, peer).Sub(sent.Add(rtt / 2)),
+		rtt:    rtt,
+		at:     received,
+	}
+
+	c.mu.Lock()
+	pc := c.peers[region]
+	if pc == nil {
+		pc = &peerClock{status: clockStatusOK}
+		c.peers[region] = pc
+	}
+	pc.samples[pc.n%clockSamples] = sample
+	pc.n++
+	skew := pc.estimate().offset
+	prev, status := pc.status, c.classify(skew)
+	pc.status = status
+	c.mu.Unlock()
+
+	if status != prev {
+		c.alert(region, skew, prev, status)
+	}
+}
+
+// estimate returns the sample with the shortest round trip
+func (pc *peerClock) estimate() clockSample {
+	best := pc.samples[0]
+	for _, s := range pc.samples[1:min(pc.n, clockSamples)] {
+		if s.rtt < best.rtt {
+			best = s
+		}
+	}
+	return best
+}
+
+func (c *clockSkew) classify(skew time.Duration) string {
+	skew = skew.Abs()
+	switch {
+	case c.critical > 0 && skew >= c.critical:
+		return clockStatusCritical
+	case c.warn > 0 && skew >= c.warn:
+		return clockStatusWarning
+	}
+	return clockStatusOK
+}
+
+// alert raises or resolves region's skew alert on a status change
+func (c *clockSkew) alert(region string, skew time.Duration, prev, status string) {
+	alertID := "clock_skew_" + region
+	if status == clockStatusOK {
+		log.Printf("Clock skew with %s back to %v", region, skew)
+		c.alerts.Resolve(alertID)
+		return
+	}
+	log.Printf("Clock skew with %s is %v: last-write-wins may order writes wrongly", region, skew)
+	c.alerts.Raise(&monitoring.Alert{
+		ID:       alertID,
+		RuleID:   "clock_skew",
+		Severity: status,
+		Message:  fmt.Sprintf("clock of region %s is %v off this node's (was %s)", region, skew, prev),
+		Value:    skew.Seconds(),
+	})
+}
+
+// skew returns the estimated offset of region's clock, and false if it
+// has not been sampled
+func (c *clockSkew) skew(region string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pc := c.peers[region]
+	if pc == nil {
+		return 0, false
+	}
+	return pc.estimate().offset, true
+}
+
+// status returns every sampled region's clock, ordered by region
+func (c *clockSkew) status() []peerClockStatus {
+	c.mu.Lock()
+	out := make([]peerClockStatus, 0, len(c.peers))
+	for region, pc := range c.peers {
+		best := pc.estimate()
+		out = append(out, peerClockStatus{
+			Region:    region,
+			SkewMs:    float64(best.offset) / float64(time.Millisecond),
+			RTTMs:     float64(best.rtt) / float64(time.Millisecond),
+			Samples:   min(pc.n, clockSamples),
+			SampledAt: pc.samples[(pc.n-1)%clockSamples].at.UTC(),
+			Status:    pc.status,
+		})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Region < out[j].Region })
+	return out
+}
+
+// runClockSkew samples every peer region's clock each ClockSkewInterval,
+// so skew is caught between anti-entropy runs
+func (s *MinIOServer) runClockSkew() {
+	if s.config.ClockSkewInterval <= 0 || len(s.config.RegionEndpoints) == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.ClockSkewInterval)
+	defer ticker.Stop()
+	for {
+		for region, endpoints := range s.config.RegionEndpoints {
+			if err := s.samplePeerClock(region, endpoints[0]); err != nil {
+				log.Printf("Clock sample of %s failed: %v", region, err)
+			}
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// samplePeerClock times a health check of a region's endpoint
+func (s *MinIOServer) samplePeerClock(region, endpoint string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+apiV1+"/health", nil)
+	if err != nil {
+		return err
+	}
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	s.clockSkew.observe(region, sent, resp)
+	return nil
+}
+
+// handleAdminReplicationClocks reports the peer regions' clock skew
+func (s *MinIOServer) handleAdminReplicationClocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.clockSkew.status())
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/monitoring"
+)
+
+// Peer clocks are sampled from their health responses, reported through
+// the admin API and metrics, and alerted on past the thresholds
+func TestClockSkew(t *testing.T) {
+	w := do(t, "GET", "/v1/health", nil)
+	if _, err := strconv.ParseInt(w.Header().Get(peerTimeHeader), 10, 64); err != nil {
+		t.Errorf("Health response without the node's clock: %v", err)
+	}
+
+	var offset atomic.Int64
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(peerTimeHeader, strconv.FormatInt(time.Now().UnixNano()+offset.Load(), 10))
+	}))
+	defer peer.Close()
+	region := fmt.Sprintf("skew-%d", time.Now().UnixNano())
+	sample := func(n int) peerClockStatus {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if err := testServer.samplePeerClock(region, peer.URL); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w := do(t, "GET", "/v1/admin/replication/clocks", nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		var clocks []peerClockStatus
+		decode(t, w, &clocks)
+		for _, c := range clocks {
+			if c.Region == region {
+				return c
+			}
+		}
+		t.Fatalf("Region %s missing from %+v", region, clocks)
+		return peerClockStatus{}
+	}
+
+	// The estimate is off by at most half the round trip
+	offset.Store(int64(2 * time.Second))
+	if c := sample(1); c.Status != clockStatusWarning || math.Abs(c.SkewMs-2000) > c.RTTMs/2+50 || c.Samples != 1 {
+		t.Errorf("Clock %+v, want a warning at about 2s ahead", c)
+	}
+	if body := scrape(t); !strings.Contains(body, fmt.Sprintf("replication_peer_clock_skew_seconds{region=%q} ", region)) {
+		t.Error("No skew metric for the region")
+	}
+
+	// Once every kept sample is in sync, the alert resolves
+	offset.Store(0)
+	if c := sample(clockSamples); c.Status != clockStatusOK || c.Samples != clockSamples {
+		t.Errorf("Clock %+v, want ok", c)
+	}
+
+	w = do(t, "GET", "/v1/admin/health/history?subsystem=clock_skew&component="+region, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var history healthHistory
+	decode(t, w, &history)
+	var got []string
+	for _, ev := range history.Events {
+		got = append(got, ev.To+"/"+ev.Severity)
+	}
+	if want := "firing/" + clockStatusWarning + " resolved/" + monitoring.SeverityInfo; strings.Join(got, " ") != want {
+		t.Errorf("Health events %v, want %s", got, want)
+	}
+}
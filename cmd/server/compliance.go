@@ -0,0 +1,207 @@
+// cmd/server/compliance.go
+// Admin endpoints for per-tenant compliance modules, preset-constrained
+// security settings and compliance status reports
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// complianceRequest is the body of PUT /admin/compliance
+type complianceRequest struct {
+	Modules []string `json:"modules"`
+}
+
+// handleAdminCompliance reports (GET) or replaces (PUT) a tenant's compliance modules
+func (s *MinIOServer) handleAdminCompliance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := r.URL.Query().Get("tenant")
+
+	switch r.Method {
+	case http.MethodGet:
+		flags, err := s.tenantManager.ComplianceModules(ctx, tenantID)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"tenant":  tenantID,
+			"modules": tenant.ComplianceModuleNames(flags),
+		})
+
+	case http.MethodPut:
+		var req complianceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid compliance request", http.StatusBadRequest)
+			return
+		}
+		flags, err := tenant.ParseComplianceModules(req.Modules)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.tenantManager.SetComplianceModules(ctx, tenantID, flags); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		names := tenant.ComplianceModuleNames(flags)
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: "admin", Action: "compliance.update",
+			Details: map[string]string{"modules": strings.Join(names, ",")}})
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"tenant":  tenantID,
+			"modules": names,
+		})
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminTenantSettings reports (GET) or replaces (PUT) a tenant's security
+// settings; changes that violate an enabled preset are refused with 409
+func (s *MinIOServer) handleAdminTenantSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := r.URL.Query().Get("tenant")
+
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := s.tenantManager.Settings(ctx, tenantID)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, settings)
+
+	case http.MethodPut:
+		var settings tenant.TenantSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			writeErrorMessage(w, r, "Invalid settings", http.StatusBadRequest)
+			return
+		}
+
+		err := s.tenantManager.UpdateSettings(ctx, tenantID, settings)
+		switch {
+		case errors.Is(err, tenant.ErrComplianceViolation):
+			s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: "admin", Action: "settings.update",
+				Outcome: audit.OutcomeDenied, Details: map[string]string{"error": err.Error()}})
+			writeErrorMessage(w, r, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: "admin", Action: "settings.update"})
+		settings, _ = s.tenantManager.Settings(ctx, tenantID)
+		writeJSON(w, http.StatusOK, settings)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Compliance check statuses
+const (
+	CheckPass = "pass"
+	CheckFail = "fail"
+	CheckWarn = "warn"
+)
+
+// complianceCheck is one control evaluated by the status report
+type complianceCheck struct {
+	Control string `json:"control"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// complianceStatus is the report returned by GET /admin/compliance/status
+type complianceStatus struct {
+	Tenant      string                    `json:"tenant"`
+	Modules     []string                  `json:"modules"`
+	Presets     []tenant.CompliancePreset `json:"presets"`
+	Settings    tenant.TenantSettings     `json:"settings"`
+	Checks      []complianceCheck         `json:"checks"`
+	Compliant   bool                      `json:"compliant"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+}
+
+// handleAdminComplianceStatus evaluates a tenant's settings and stored data against its presets
+func (s *MinIOServer) handleAdminComplianceStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	tenantID := r.URL.Query().Get("tenant")
+
+	flags, err := s.tenantManager.ComplianceModules(ctx, tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	settings, _ := s.tenantManager.Settings(ctx, tenantID)
+
+	report := complianceStatus{
+		Tenant:      tenantID,
+		Modules:     tenant.ComplianceModuleNames(flags),
+		Presets:     tenant.CompliancePresets(flags),
+		Settings:    settings,
+		Checks:      make([]complianceCheck, 0),
+		Compliant:   true,
+		GeneratedAt: time.Now().UTC(),
+	}
+	check := func(control string, ok bool, detail string) {
+		c := complianceCheck{Control: control, Status: CheckPass}
+		if !ok {
+			c.Status, c.Detail = CheckFail, detail
+			report.Compliant = false
+		}
+		report.Checks = append(report.Checks, c)
+	}
+
+	violations := tenant.ValidateSettings(flags, settings)
+	check("settings", len(violations) == 0, strings.Join(violations, "; "))
+
+	// Objects written before encryption was enabled remain in plaintext
+	if s.encryptsAtRest(ctx, tenantID, settings) {
+		plaintext := 0
+		for _, meta := range s.index.List(tenantID, "") {
+			if !meta.Encrypted {
+				plaintext++
+			}
+		}
+		check("encryption_at_rest", plaintext == 0, fmt.Sprintf("%d objects stored unencrypted", plaintext))
+	}
+
+	if settings.AuditLogging {
+		check("audit_logging", s.auditLog.Errors() == 0, fmt.Sprintf("%d audit events failed to write", s.auditLog.Errors()))
+	}
+
+	// Replication only reaches regions the tenant allows, but this one
+	// holds every object written here
+	if len(settings.Regions) > 0 {
+		source := s.replicationEngine.SourceRegion()
+		check("data_residency", settings.AllowsRegion(source), "data is stored in "+source)
+	}
+
+	if settings.RequireTLS {
+		// TLS is enforced per request, but a plaintext listener still accepts connections
+		report.Checks = append(report.Checks, complianceCheck{
+			Control: "tls_only",
+			Status:  CheckWarn,
+			Detail:  "plaintext requests are refused; terminate TLS at a proxy setting X-Forwarded-Proto",
+		})
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// Enabling a preset tightens the tenant's settings, after which changes
+// that would fall short of it are refused with 409 and leave them as they were
+func TestCompliancePresetSettings(t *testing.T) {
+	for _, module := range []string{"HIPAA", "PCI"} {
+		tenantID := newTenant(t)
+		settingsPath := "/v1/admin/compliance/settings?tenant=" + tenantID
+		w := do(t, "PUT", "/v1/admin/compliance?tenant="+tenantID, complianceRequest{Modules: []string{module}}, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+
+		var applied tenant.TenantSettings
+		w = do(t, "GET", settingsPath, nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		if decode(t, w, &applied); !applied.RequireTLS || !applied.EncryptAtRest || !applied.AuditLogging || !applied.ObjectLock {
+			t.Fatalf("%s: settings %+v after enabling, want the preset's controls on", module, applied)
+		}
+
+		valid := applied
+		valid.Regions = []string{"us-east-1"}
+		for _, tc := range []struct {
+			name   string
+			change func(*tenant.TenantSettings)
+			status int
+		}{
+			{"TLS off", func(s *tenant.TenantSettings) { s.RequireTLS = false }, http.StatusConflict},
+			{"unencrypted", func(s *tenant.TenantSettings) { s.EncryptAtRest = false }, http.StatusConflict},
+			{"no audit", func(s *tenant.TenantSettings) { s.AuditLogging = false }, http.StatusConflict},
+			{"region outside the preset", func(s *tenant.TenantSettings) { s.Regions = []string{"us-east-1", "eu-west-1"} }, http.StatusConflict},
+			{"region inside the preset", func(s *tenant.TenantSettings) {}, http.StatusOK},
+		} {
+			// Only HIPAA restricts regions
+			if module == "PCI" && strings.HasPrefix(tc.name, "region") {
+				continue
+			}
+			settings := valid
+			tc.change(&settings)
+			w := do(t, "PUT", settingsPath, settings, adminAuth)
+			if w.Code != tc.status {
+				t.Errorf("%s %s: status %d, want %d", module, tc.name, w.Code, tc.status)
+			}
+			if tc.status == http.StatusConflict {
+				var got tenant.TenantSettings
+				w = do(t, "GET", settingsPath, nil, adminAuth)
+				if decode(t, w, &got); !reflect.DeepEqual(got, applied) {
+					t.Errorf("%s %s: settings %+v after a refused change, want %+v", module, tc.name, got, applied)
+				}
+			}
+		}
+	}
+}
+
+// The status report lists the tenant's presets and fails every control its
+// settings or stored data fall short of
+func TestComplianceStatus(t *testing.T) {
+	tenantID := newTenant(t)
+	statusPath := "/v1/admin/compliance/status?tenant=" + tenantID
+	report := func() complianceStatus {
+		t.Helper()
+		var status complianceStatus
+		w := do(t, "GET", statusPath, nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		decode(t, w, &status)
+		return status
+	}
+
+	if got := report(); !got.Compliant || got.Tenant != tenantID || len(got.Modules) != 0 || len(got.Presets) != 0 {
+		t.Fatalf("Report %+v without modules, want compliant", got)
+	}
+
+	// Written before encryption was required, so stored in plaintext
+	upload(t, tenantID, "old.txt", "hello")
+	w := do(t, "PUT", "/v1/admin/compliance?tenant="+tenantID, complianceRequest{Modules: []string{"HIPAA"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	settings := tenant.TenantSettings{RequireTLS: true, EncryptAtRest: true, AuditLogging: true, ObjectLock: true, Regions: []string{"us-west-2"}}
+	w = do(t, "PUT", "/v1/admin/compliance/settings?tenant="+tenantID, settings, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	got := report()
+	if got.Tenant != tenantID || !reflect.DeepEqual(got.Modules, []string{"HIPAA"}) ||
+		len(got.Presets) != 1 || got.Presets[0].Module != "HIPAA" || !reflect.DeepEqual(got.Settings, settings) {
+		t.Errorf("Report for %s with modules %v, presets %+v and settings %+v", got.Tenant, got.Modules, got.Presets, got.Settings)
+	}
+	checks := make(map[string]complianceCheck)
+	for _, c := range got.Checks {
+		checks[c.Control] = c
+	}
+	for control, status := range map[string]string{
+		"settings":           CheckPass,
+		"encryption_at_rest": CheckFail,
+		"audit_logging":      CheckPass,
+		"data_residency":     CheckFail,
+		"tls_only":           CheckWarn,
+	} {
+		if c, ok := checks[control]; !ok || c.Status != status {
+			t.Errorf("Check %s: %+v, want %s", control, c, status)
+		}
+	}
+	if c := checks["encryption_at_rest"]; c.Detail != "1 objects stored unencrypted" {
+		t.Errorf("Encryption check detail %q", c.Detail)
+	}
+	if c := checks["data_residency"]; c.Detail != "data is stored in us-east-1" {
+		t.Errorf("Residency check detail %q", c.Detail)
+	}
+	if got.Compliant || got.GeneratedAt.IsZero() {
+		t.Errorf("Report compliant %v generated at %v, want failing controls to fail it", got.Compliant, got.GeneratedAt)
+	}
+
+	w = do(t, "GET", "/v1/admin/compliance/status?tenant=unknown", nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Conditional downloads answer 304 without a body when the client's copy
+// is current and 412 when a precondition fails, If-Match and
+// If-None-Match taking precedence over the dates
+func TestConditionalDownload(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	target := "/v1/download?tenant_id=" + tenantID + "&key=doc.txt"
+	w := do(t, "GET", target, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	etag, version := w.Header().Get("ETag"), w.Header().Get("X-Version-ID")
+	modified, err := http.ParseTime(w.Header().Get("Last-Modified"))
+	if etag == "" || version == "" || err != nil {
+		t.Fatalf("ETag %q, version %q, Last-Modified %q", etag, version, w.Header().Get("Last-Modified"))
+	}
+	at := modified.Format(http.TimeFormat)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"unconditional", nil, http.StatusOK},
+		{"If-Match", map[string]string{"If-Match": etag}, http.StatusOK},
+		{"If-Match version", map[string]string{"If-Match": version}, http.StatusOK},
+		{"If-Match any", map[string]string{"If-Match": "*"}, http.StatusOK},
+		{"If-Match other", map[string]string{"If-Match": `"other", "tags"`}, http.StatusPreconditionFailed},
+		{"If-Match weak", map[string]string{"If-Match": "W/" + etag}, http.StatusPreconditionFailed},
+		{"If-None-Match", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"If-None-Match weak", map[string]string{"If-None-Match": "W/" + etag}, http.StatusNotModified},
+		{"If-None-Match other", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"If-Modified-Since now", map[string]string{"If-Modified-Since": at}, http.StatusNotModified},
+		{"If-Modified-Since before", map[string]string{"If-Modified-Since": before}, http.StatusOK},
+		{"If-Unmodified-Since now", map[string]string{"If-Unmodified-Since": at}, http.StatusOK},
+		{"If-Unmodified-Since before", map[string]string{"If-Unmodified-Since": before}, http.StatusPreconditionFailed},
+		{"If-Unmodified-Since invalid", map[string]string{"If-Unmodified-Since": "yesterday"}, http.StatusOK},
+		{"If-Match over If-Unmodified-Since", map[string]string{"If-Match": etag, "If-Unmodified-Since": before}, http.StatusOK},
+		{"If-None-Match over If-Modified-Since", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": at}, http.StatusOK},
+		{"If-None-Match with If-Modified-Since", map[string]string{"If-None-Match": etag, "If-Modified-Since": before}, http.StatusNotModified},
+		{"412 over 304", map[string]string{"If-Match": `"other"`, "If-None-Match": etag}, http.StatusPreconditionFailed},
+	} {
+		w := do(t, "GET", target, nil, adminAuth, func(r *http.Request) {
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+		})
+		if w.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.status)
+			continue
+		}
+		switch w.Code {
+		case http.StatusOK:
+			if w.Body.String() != "hello" {
+				t.Errorf("%s: body %q, want hello", tc.name, w.Body.String())
+			}
+		case http.StatusNotModified:
+			if w.Body.Len() != 0 || w.Header().Get("ETag") != etag || w.Header().Get("Last-Modified") != at {
+				t.Errorf("%s: 304 with body %q, ETag %q, Last-Modified %q", tc.name, w.Body.String(),
+					w.Header().Get("ETag"), w.Header().Get("Last-Modified"))
+			}
+		}
+	}
+}
@@ -0,0 +1,592 @@
+// cmd/server/config.go
+// Server configuration loaded from the environment
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/cache"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/multipart"
+	"github.com/minio/enterprise/internal/replication"
+	"github.com/minio/enterprise/internal/scan"
+	"github.com/minio/enterprise/internal/tenant"
+	"github.com/minio/enterprise/internal/tlsconfig"
+)
+
+// ServerConfig holds deployment-level settings
+type ServerConfig struct {
+	// DataDir is the root for on-disk state (journal, tiers)
+	DataDir string
+
+	// JournalDurability is how far intent log records get before writes
+	// return: "none", "flush" or "fsync". Records appended within
+	// JournalCommitWindow share one fsync.
+	JournalDurability   string
+	JournalCommitWindow time.Duration
+
+	// JournalArchive, if set, is a backend bucket config (JSON, as for
+	// gateways) closed journal segments are uploaded to. Archived segments
+	// older than JournalArchiveMaxAge or beyond the newest
+	// JournalArchiveKeep are deleted; 0 disables either limit.
+	JournalArchive       string
+	JournalArchiveMaxAge time.Duration
+	JournalArchiveKeep   int
+
+	// ChangeFeedRetain is how many object changes per tenant the change
+	// feed keeps for consumers to resume from
+	ChangeFeedRetain int
+
+	// TenantUsageStore is the file tenant usage counters are flushed to
+	// and restored from
+	TenantUsageStore string
+
+	// Every UsageSampleInterval each tenant's usage is sampled into the
+	// rollup store, kept at each resolution for its retention and saved
+	// to RollupFile. Samples for more than RollupMaxSeries series are
+	// dropped.
+	UsageSampleInterval   time.Duration
+	RollupFile            string
+	RollupMinuteRetention time.Duration
+	RollupHourRetention   time.Duration
+	RollupDayRetention    time.Duration
+	RollupMaxSeries       int
+
+	// RateBurst is how many seconds of its rate limit a tenant may spend
+	// at once
+	RateBurst float64
+
+	// PublicRateLimit is the anonymous requests per second each client
+	// address may make to public buckets, with the same burst (0:
+	// unlimited). Requests from TrustedProxies (addresses or CIDR
+	// prefixes) are attributed to the client in X-Forwarded-For.
+	PublicRateLimit int64
+	TrustedProxies  []string
+
+	// JournalCompactBytes triggers journal compaction once exceeded
+	JournalCompactBytes int64
+
+	// JournalCompactInterval is how often compaction is considered
+	JournalCompactInterval time.Duration
+
+	// GCPercent is the heap growth, as a percentage, that triggers a
+	// collection (negative: off; 0: as GOGC sets it). MemoryLimit, if set,
+	// is the soft limit the collector works to, in place of GOMEMLIMIT.
+	// GCBallastBytes allocates an untouched ballast that raises the heap
+	// goal, so small heaps collect less often.
+	GCPercent      int
+	MemoryLimit    int64
+	GCBallastBytes int64
+
+	// IndexMemoryBytes caps the key index's in-memory entries; above it the
+	// largest tenant's are spilled to disk segments (0 keeps all in memory).
+	// A tenant's segments are compacted into one past IndexMaxSegments.
+	IndexMemoryBytes int64
+	IndexMaxSegments int
+
+	// AdminToken authorizes /admin requests; empty disables the admin API
+	AdminToken string
+
+	// Cache tier directories watched for free space alongside DataDir
+	L2Dir string
+	L3Dir string
+
+	// CacheShards is the cache's shard count, a power of two
+	CacheShards int
+
+	// The startup self-test refuses to start on failures that would lose
+	// or misplace data, and starts degraded on the rest unless
+	// SelfTestStrict is set
+	SelfTestStrict bool
+
+	// AuditAnchorInterval is how often the audit chain head is anchored
+	AuditAnchorInterval time.Duration
+
+	// AuditNotaryURL, if set, receives each anchor as a JSON POST
+	AuditNotaryURL string
+
+	// TokenSecret signs tenant API tokens; empty uses a random per-process secret
+	TokenSecret string
+
+	// OIDC single sign-on; disabled unless OIDCIssuer is set
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCGroupsClaim  string
+	OIDCTenantClaim  string
+	// OIDCGroupTenants maps IdP groups to tenants: "group=tenant1|tenant2,..."
+	OIDCGroupTenants map[string][]string
+	OIDCAdminGroups  []string
+
+	// Console session lifetime and idle timeout
+	SessionTTL         time.Duration
+	SessionIdleTimeout time.Duration
+
+	// InlineObjectBytes is the largest stored object kept inline in the
+	// index instead of the cache tiers; 0 disables inlining
+	InlineObjectBytes int
+
+	// KVMemcacheAddr, if set, serves the memcached text protocol mapped onto
+	// KVTenant's objects under KVPrefix. The protocol has no authentication:
+	// bind it to a private interface.
+	KVMemcacheAddr string
+	KVTenant       string
+	KVPrefix       string
+
+	// RESPAddr, if set, serves a Redis-protocol subset on the cache tier
+	RESPAddr string
+
+	// InternodeAddr, if set, serves the storage tiers to peer nodes over
+	// the internode protocol; peers must hold InternodeSecret
+	InternodeAddr   string
+	InternodeSecret string
+
+	// The API and metrics are served over HTTPS when TLSCertFile and
+	// TLSKeyFile are set, reloaded when the files change. TLSClientCAFile
+	// enables mutual TLS; TLSClientAuth is "require" (default) or
+	// "verify_if_given". TLSCipherSuites restricts TLS 1.2 suites by Go name.
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSClientCAFile   string
+	TLSClientAuth     string
+	TLSMinVersion     string
+	TLSCipherSuites   []string
+	TLSReloadInterval time.Duration
+
+	// WebhookURLs receive tenant lifecycle events, signed with WebhookSecret
+	WebhookURLs   []string
+	WebhookSecret string
+
+	// Each BillingPeriod ("daily" or "monthly") is exported once
+	// BillingExportDelay has passed since it ended, under BillingBucket in
+	// BillingTenant and to BillingWebhookURL; neither set disables exports
+	BillingPeriod      string
+	BillingTenant      string
+	BillingBucket      string
+	BillingWebhookURL  string
+	BillingExportDelay time.Duration
+
+	// RegionKEKs maps replication regions to hex key-encryption keys that
+	// sealed objects' data keys are wrapped under when replicated there
+	RegionKEKs map[string][]string
+
+	// StrictListTimeout bounds how long ?strict=true listings wait for
+	// this node's writes to replicate
+	StrictListTimeout time.Duration
+
+	// MaxConcurrentUploads bounds uploads being received at once; 0
+	// disables the limit. Further uploads queue, up to UploadQueueLength per
+	// tenant for at most UploadQueueWait, and are refused with 503 beyond
+	// that. Freed slots go to tenants in proportion to UploadQueueWeights,
+	// keyed by tenant ID or plan (default 1).
+	MaxConcurrentUploads int
+	UploadQueueLength    int
+	UploadQueueWait      time.Duration
+	UploadQueueWeights   map[string]float64
+
+	// MaxObjectBytes caps every upload, alongside each tenant's own
+	// max_object_bytes setting; 0 disables the global cap. Uploads without
+	// a Content-Length (chunked) are refused with 411 unless ChunkedUploads
+	// is set, and are cut off at the cap when it is.
+	MaxObjectBytes int64
+	ChunkedUploads bool
+
+	// RequireAuth refuses requests naming a tenant that carry no
+	// credentials; otherwise they pass as the tenant they name
+	RequireAuth bool
+
+	// RequireBuckets refuses writes to keys outside a created bucket;
+	// otherwise keys need not begin with a bucket
+	RequireBuckets bool
+
+	// FaultInjection lets the admin API attach fault profiles to tenants,
+	// delaying, failing or throttling their requests; off in production
+	FaultInjection bool
+
+	// Uploads larger than StreamUploadBytes are streamed to the part store
+	// as they arrive, as multipart objects are, rather than held in memory;
+	// 0 holds every upload in memory. Uploads to tenants that cannot use
+	// multipart uploads are always held in memory.
+	StreamUploadBytes int64
+
+	// Multipart uploads stage parts under DataDir/multipart. Parts but the
+	// last must be at least MultipartMinPart bytes; uploads not completed
+	// within MultipartExpiry are aborted. MultipartDedup stores identical
+	// parts once.
+	MultipartMinPart int64
+	MultipartExpiry  time.Duration
+	MultipartDedup   bool
+
+	// VirtualHostDomains enable virtual-hosted style addressing: a request
+	// to <tenant>.<domain> addresses that tenant without X-Tenant-ID
+	VirtualHostDomains []string
+
+	// Anti-entropy compares every tenant's Merkle tree of AntiEntropyDepth
+	// with each region in RegionEndpoints (region=url) every
+	// AntiEntropyInterval (0: only on demand), authenticating with
+	// RegionToken, and replicates what a region lacks at no more than
+	// AntiEntropyBandwidth bytes per second (0: unlimited)
+	RegionEndpoints      map[string][]string
+	RegionToken          string
+	AntiEntropyInterval  time.Duration
+	AntiEntropyDepth     int
+	AntiEntropyBandwidth int64
+
+	// The peer regions' clocks are sampled every ClockSkewInterval (0:
+	// only during anti-entropy), alerting when one is ClockSkewWarn or
+	// ClockSkewCritical off this node's
+	ClockSkewInterval time.Duration
+	ClockSkewWarn     time.Duration
+	ClockSkewCritical time.Duration
+
+	// ReplicationRules map key prefixes or object tags to the regions
+	// their objects replicate to (prefix=region|region,
+	// tag:key:value=region|region); tag rules win over prefixes, the
+	// longest matching prefix over shorter ones, an empty prefix sets the
+	// default, and keys no rule matches go to every region. A tenant's residency setting narrows the set
+	// further. ReplicationFanout caps how many regions one object
+	// replicates to at once (0: all).
+	ReplicationRules  map[string][]string
+	ReplicationFanout int
+
+	// A region a replication fails to is retried up to ReplicationRetries
+	// times, while its retry budget lasts: ReplicationRetryBudget retries
+	// per first attempt to the region, saving up at most 10
+	ReplicationRetries     int
+	ReplicationRetryBudget float64
+
+	// ReplicationQueueAging is how long queued bulk or normal replication
+	// may wait unserved behind more urgent work
+	ReplicationQueueAging time.Duration
+
+	// Objects over ReplicationStreamBytes bytes replicate in
+	// ReplicationChunkBytes chunks, each checksummed, and a retry resumes
+	// from the last chunk the region acknowledged
+	ReplicationStreamBytes int64
+	ReplicationChunkBytes  int64
+
+	// ReadRepair re-replicates an object to regions a read finds missing
+	// it or holding an older version, at most once per ReadRepairBackoff
+	ReadRepair        bool
+	ReadRepairBackoff time.Duration
+
+	// NodeURL is the URL clients reach this node at. ClusterNodes, if set,
+	// are the URLs of every node in the cluster, advertised at /health so
+	// clients can balance across them.
+	NodeURL      string
+	ClusterNodes []string
+
+	// LegacyAPISunset, if set, is announced as the Sunset date of the
+	// unversioned API paths
+	LegacyAPISunset time.Time
+
+	// AccessLog logs one line per API request with its request ID
+	AccessLog bool
+
+	// Malware scanner for tenants with scan_uploads: an ICAP service URL
+	// (icap://host:1344/service) or a clamd address (host:port or socket path)
+	ScanICAPURL    string
+	ScanClamAVAddr string
+	ScanTimeout    time.Duration
+
+	// QuarantineTenant, if set, receives infected uploads under
+	// <tenant>/<key>; otherwise they are only refused
+	QuarantineTenant string
+
+	// External encoders for image formats the server cannot write itself,
+	// e.g. "cwebp -quiet -q {quality} {in} -o {out}"; ImageVariantTTL is
+	// how long transformed variants stay cached
+	ImageEncoderWebP string
+	ImageEncoderAVIF string
+	ImageVariantTTL  time.Duration
+
+	// DownloadChunkBytes is the range size advertised to parallel
+	// downloaders; ReadaheadBytes bounds the plaintext buffered for
+	// sequential ranged reads (0 disables readahead)
+	DownloadChunkBytes int64
+	ReadaheadBytes     int64
+
+	// PlacementPolicy names the cache tier placement policy ("size" or
+	// "learned"); PlacementTrace, if set, records writes, reads and deletes
+	// to that file for placement-sim and cache-replay. Tenants and keys are
+	// hashed with PlacementTraceSecret (random per start if empty) unless
+	// PlacementTraceRaw is set.
+	PlacementPolicy      string
+	PlacementTrace       string
+	PlacementTraceRaw    bool
+	PlacementTraceSecret string
+
+	// Access statistics keep AccessStatsIntervals intervals of
+	// AccessStatsInterval per key, for at most AccessStatsMaxKeys keys
+	AccessStatsInterval  time.Duration
+	AccessStatsIntervals int
+	AccessStatsMaxKeys   int
+
+	// Prefixes of HotPrefixDepth segments serving at least
+	// HotPrefixRequests in every interval of HotPrefixWindow are promoted
+	// to L1; 0 requests disables promotion
+	HotPrefixDepth    int
+	HotPrefixWindow   time.Duration
+	HotPrefixRequests int64
+
+	// Disk usage thresholds (percent used)
+	DiskWarnPercent     float64
+	DiskReadOnlyPercent float64
+	DiskResumePercent   float64
+
+	// HealthHistory is the subsystem health transitions kept for the
+	// admin health history
+	HealthHistory int
+
+	// Support bundles hold at most SupportBundleMaxBytes before
+	// compression and the last SupportLogLines log lines. SupportRedact
+	// adds patterns (regular expressions) redacted from every text entry.
+	SupportBundleMaxBytes int64
+	SupportLogLines       int
+	SupportRedact         []string
+}
+
+// loadConfig reads MINIO_* environment variables, falling back to defaults
+func loadConfig() *ServerConfig {
+	dataDir := envString("MINIO_DATA_DIR", "/data")
+
+	// MINIO_SYNC_JOURNAL=false predates durability levels
+	durability := "fsync"
+	if !envBool("MINIO_SYNC_JOURNAL", true) {
+		durability = "flush"
+	}
+
+	return &ServerConfig{
+		DataDir:                dataDir,
+		JournalDurability:      envString("MINIO_JOURNAL_DURABILITY", durability),
+		JournalCommitWindow:    envDuration("MINIO_JOURNAL_COMMIT_WINDOW", metadata.DefaultCommitWindow),
+		JournalArchive:         os.Getenv("MINIO_JOURNAL_ARCHIVE"),
+		JournalArchiveMaxAge:   envDuration("MINIO_JOURNAL_ARCHIVE_MAX_AGE", 7*24*time.Hour),
+		JournalArchiveKeep:     int(envInt64("MINIO_JOURNAL_ARCHIVE_KEEP", 0)),
+		ChangeFeedRetain:       int(envInt64("MINIO_CHANGE_FEED_RETAIN", metadata.DefaultChangeRetain)),
+		TenantUsageStore:       envString("MINIO_TENANT_USAGE_STORE", filepath.Join(dataDir, "tenants", "usage.json")),
+		UsageSampleInterval:    envDuration("MINIO_USAGE_SAMPLE_INTERVAL", time.Minute),
+		RollupFile:             envString("MINIO_ROLLUP_FILE", filepath.Join(dataDir, "rollups.json")),
+		RollupMinuteRetention:  envDuration("MINIO_ROLLUP_MINUTE_RETENTION", monitoring.DefaultRollupMinuteRetention),
+		RollupHourRetention:    envDuration("MINIO_ROLLUP_HOUR_RETENTION", monitoring.DefaultRollupHourRetention),
+		RollupDayRetention:     envDuration("MINIO_ROLLUP_DAY_RETENTION", monitoring.DefaultRollupDayRetention),
+		RollupMaxSeries:        int(envInt64("MINIO_ROLLUP_MAX_SERIES", monitoring.DefaultRollupMaxSeries)),
+		RateBurst:              envFloat("MINIO_RATE_BURST", tenant.DefaultRateBurst),
+		PublicRateLimit:        envInt64("MINIO_PUBLIC_RATE_LIMIT", tenant.DefaultPublicRateLimit),
+		TrustedProxies:         envList("MINIO_TRUSTED_PROXIES"),
+		JournalCompactBytes:    envInt64("MINIO_JOURNAL_COMPACT_BYTES", 256*1024*1024),
+		JournalCompactInterval: envDuration("MINIO_JOURNAL_COMPACT_INTERVAL", time.Minute),
+		GCPercent:              int(envInt64("MINIO_GC_PERCENT", defaultGCPercent())),
+		MemoryLimit:            envInt64("MINIO_MEMORY_LIMIT", 0),
+		GCBallastBytes:         envInt64("MINIO_GC_BALLAST_BYTES", 0),
+		IndexMemoryBytes:       envInt64("MINIO_INDEX_MEMORY_BYTES", 1<<30),
+		IndexMaxSegments:       int(envInt64("MINIO_INDEX_MAX_SEGMENTS", 4)),
+		AdminToken:             os.Getenv("MINIO_ADMIN_TOKEN"),
+		AuditAnchorInterval:    envDuration("MINIO_AUDIT_ANCHOR_INTERVAL", 5*time.Minute),
+		AuditNotaryURL:         os.Getenv("MINIO_AUDIT_NOTARY_URL"),
+		TokenSecret:            os.Getenv("MINIO_TOKEN_SECRET"),
+		OIDCIssuer:             os.Getenv("MINIO_OIDC_ISSUER"),
+		OIDCClientID:           os.Getenv("MINIO_OIDC_CLIENT_ID"),
+		OIDCClientSecret:       os.Getenv("MINIO_OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:        os.Getenv("MINIO_OIDC_REDIRECT_URL"),
+		OIDCGroupsClaim:        envString("MINIO_OIDC_GROUPS_CLAIM", "groups"),
+		OIDCTenantClaim:        os.Getenv("MINIO_OIDC_TENANT_CLAIM"),
+		OIDCGroupTenants:       envMapping("MINIO_OIDC_GROUP_TENANTS"),
+		OIDCAdminGroups:        envList("MINIO_OIDC_ADMIN_GROUPS"),
+		SessionTTL:             envDuration("MINIO_SESSION_TTL", 8*time.Hour),
+		SessionIdleTimeout:     envDuration("MINIO_SESSION_IDLE_TIMEOUT", 30*time.Minute),
+		InlineObjectBytes:      int(envInt64("MINIO_INLINE_OBJECT_BYTES", 4096)),
+		KVMemcacheAddr:         os.Getenv("MINIO_KV_MEMCACHE_ADDR"),
+		KVTenant:               os.Getenv("MINIO_KV_TENANT"),
+		KVPrefix:               envString("MINIO_KV_PREFIX", "kv/"),
+		RESPAddr:               os.Getenv("MINIO_RESP_ADDR"),
+		InternodeAddr:          os.Getenv("MINIO_INTERNODE_ADDR"),
+		TLSCertFile:            os.Getenv("MINIO_TLS_CERT_FILE"),
+		TLSKeyFile:             os.Getenv("MINIO_TLS_KEY_FILE"),
+		TLSClientCAFile:        os.Getenv("MINIO_TLS_CLIENT_CA_FILE"),
+		TLSClientAuth:          os.Getenv("MINIO_TLS_CLIENT_AUTH"),
+		TLSMinVersion:          os.Getenv("MINIO_TLS_MIN_VERSION"),
+		TLSCipherSuites:        envList("MINIO_TLS_CIPHER_SUITES"),
+		TLSReloadInterval:      envDuration("MINIO_TLS_RELOAD_INTERVAL", tlsconfig.DefaultReloadInterval),
+		InternodeSecret:        os.Getenv("MINIO_INTERNODE_SECRET"),
+		RegionKEKs:             envMapping("MINIO_REGION_KEKS"),
+		StrictListTimeout:      envDuration("MINIO_STRICT_LIST_TIMEOUT", 5*time.Second),
+		MaxConcurrentUploads:   int(envInt64("MINIO_MAX_CONCURRENT_UPLOADS", 1024)),
+		UploadQueueLength:      int(envInt64("MINIO_UPLOAD_QUEUE_LENGTH", 64)),
+		UploadQueueWait:        envDuration("MINIO_UPLOAD_QUEUE_WAIT", 2*time.Second),
+		UploadQueueWeights:     envWeights("MINIO_UPLOAD_QUEUE_WEIGHTS"),
+		MaxObjectBytes:         envInt64("MINIO_MAX_OBJECT_SIZE", 5<<30),
+		ChunkedUploads:         envBool("MINIO_CHUNKED_UPLOADS", false),
+		RequireAuth:            envBool("MINIO_REQUIRE_AUTH", true),
+		RequireBuckets:         envBool("MINIO_REQUIRE_BUCKETS", false),
+		FaultInjection:         envBool("MINIO_FAULT_INJECTION", false),
+		StreamUploadBytes:      envInt64("MINIO_STREAM_UPLOAD_BYTES", 32<<20),
+		MultipartMinPart:       envInt64("MINIO_MULTIPART_MIN_PART", multipart.DefaultMinPartSize),
+		MultipartExpiry:        envDuration("MINIO_MULTIPART_EXPIRY", 7*24*time.Hour),
+		MultipartDedup:         envBool("MINIO_MULTIPART_DEDUP", false),
+		WebhookURLs:            envList("MINIO_WEBHOOK_URLS"),
+		WebhookSecret:          os.Getenv("MINIO_WEBHOOK_SECRET"),
+		BillingPeriod:          envString("MINIO_BILLING_PERIOD", tenant.BillingMonthly),
+		BillingTenant:          os.Getenv("MINIO_BILLING_TENANT"),
+		BillingBucket:          envString("MINIO_BILLING_BUCKET", "billing"),
+		BillingWebhookURL:      os.Getenv("MINIO_BILLING_WEBHOOK_URL"),
+		BillingExportDelay:     envDuration("MINIO_BILLING_EXPORT_DELAY", time.Hour),
+		VirtualHostDomains:     envList("MINIO_DOMAIN"),
+		RegionEndpoints:        envMapping("MINIO_REGION_ENDPOINTS"),
+		RegionToken:            envString("MINIO_REGION_TOKEN", os.Getenv("MINIO_ADMIN_TOKEN")),
+		AntiEntropyInterval:    envDuration("MINIO_ANTI_ENTROPY_INTERVAL", 6*time.Hour),
+		AntiEntropyDepth:       int(envInt64("MINIO_ANTI_ENTROPY_DEPTH", metadata.DefaultMerkleDepth)),
+		AntiEntropyBandwidth:   envInt64("MINIO_ANTI_ENTROPY_BANDWIDTH", 32<<20),
+		ClockSkewInterval:      envDuration("MINIO_CLOCK_SKEW_INTERVAL", time.Minute),
+		ClockSkewWarn:          envDuration("MINIO_CLOCK_SKEW_WARN", 500*time.Millisecond),
+		ClockSkewCritical:      envDuration("MINIO_CLOCK_SKEW_CRITICAL", 5*time.Second),
+		ReplicationRules:       envMapping("MINIO_REPLICATION_RULES"),
+		ReplicationFanout:      int(envInt64("MINIO_REPLICATION_FANOUT", 8)),
+		ReplicationRetries:     int(envInt64("MINIO_REPLICATION_RETRIES", 3)),
+		ReplicationRetryBudget: envFloat("MINIO_REPLICATION_RETRY_BUDGET", 0.1),
+		ReplicationQueueAging:  envDuration("MINIO_REPLICATION_QUEUE_AGING", replication.DefaultQueueAging),
+		ReplicationStreamBytes: envInt64("MINIO_REPLICATION_STREAM_BYTES", replication.DefaultStreamThreshold),
+		ReplicationChunkBytes:  max(envInt64("MINIO_REPLICATION_CHUNK_BYTES", replication.DefaultStreamChunkSize), 64<<10),
+		ReadRepair:             envBool("MINIO_READ_REPAIR", true),
+		ReadRepairBackoff:      envDuration("MINIO_READ_REPAIR_BACKOFF", time.Minute),
+		NodeURL:                strings.TrimSuffix(os.Getenv("MINIO_NODE_URL"), "/"),
+		ClusterNodes:           envList("MINIO_CLUSTER_NODES"),
+		AccessLog:              envBool("MINIO_ACCESS_LOG", false),
+		LegacyAPISunset:        envTime("MINIO_LEGACY_API_SUNSET"),
+		ScanICAPURL:            os.Getenv("MINIO_SCAN_ICAP_URL"),
+		ScanClamAVAddr:         os.Getenv("MINIO_SCAN_CLAMAV_ADDR"),
+		ScanTimeout:            envDuration("MINIO_SCAN_TIMEOUT", scan.DefaultTimeout),
+		QuarantineTenant:       os.Getenv("MINIO_QUARANTINE_TENANT"),
+		ImageEncoderWebP:       os.Getenv("MINIO_IMAGE_ENCODER_WEBP"),
+		ImageEncoderAVIF:       os.Getenv("MINIO_IMAGE_ENCODER_AVIF"),
+		ImageVariantTTL:        envDuration("MINIO_IMAGE_VARIANT_TTL", 24*time.Hour),
+		DownloadChunkBytes:     max(envInt64("MINIO_DOWNLOAD_CHUNK_BYTES", 8<<20), 64<<10),
+		ReadaheadBytes:         envInt64("MINIO_READAHEAD_BYTES", 256<<20),
+		PlacementPolicy:        envString("MINIO_PLACEMENT_POLICY", "learned"),
+		PlacementTrace:         os.Getenv("MINIO_PLACEMENT_TRACE"),
+		PlacementTraceRaw:      envBool("MINIO_PLACEMENT_TRACE_RAW", false),
+		PlacementTraceSecret:   os.Getenv("MINIO_PLACEMENT_TRACE_SECRET"),
+		AccessStatsInterval:    envDuration("MINIO_ACCESS_STATS_INTERVAL", monitoring.DefaultAccessInterval),
+		AccessStatsIntervals:   int(envInt64("MINIO_ACCESS_STATS_INTERVALS", monitoring.DefaultAccessIntervals)),
+		AccessStatsMaxKeys:     int(envInt64("MINIO_ACCESS_STATS_MAX_KEYS", monitoring.DefaultAccessMaxKeys)),
+		HotPrefixDepth:         int(envInt64("MINIO_HOT_PREFIX_DEPTH", 1)),
+		HotPrefixWindow:        envDuration("MINIO_HOT_PREFIX_WINDOW", 10*time.Minute),
+		HotPrefixRequests:      envInt64("MINIO_HOT_PREFIX_REQUESTS", 60),
+		L2Dir:                  envString("MINIO_L2_DIR", filepath.Join(dataDir, "l2")),
+		L3Dir:                  envString("MINIO_L3_DIR", filepath.Join(dataDir, "l3")),
+		CacheShards:            int(envInt64("MINIO_CACHE_SHARDS", cache.V3ShardCount)),
+		SelfTestStrict:         envBool("MINIO_SELF_TEST_STRICT", false),
+		DiskWarnPercent:        envFloat("MINIO_DISK_WARN_PERCENT", monitoring.DefaultDiskWarnPercent),
+		DiskReadOnlyPercent:    envFloat("MINIO_DISK_READONLY_PERCENT", monitoring.DefaultDiskReadOnlyPercent),
+		DiskResumePercent:      envFloat("MINIO_DISK_RESUME_PERCENT", monitoring.DefaultDiskResumePercent),
+		HealthHistory:          int(envInt64("MINIO_HEALTH_HISTORY", monitoring.DefaultHealthHistory)),
+		SupportBundleMaxBytes:  envInt64("MINIO_SUPPORT_BUNDLE_MAX_BYTES", 64<<20),
+		SupportLogLines:        int(envInt64("MINIO_SUPPORT_LOG_LINES", 5000)),
+		SupportRedact:          envList("MINIO_SUPPORT_REDACT"),
+	}
+}
+
+// tlsOptions returns the TLS settings for the API and metrics listeners
+func (c *ServerConfig) tlsOptions() tlsconfig.Options {
+	return tlsconfig.Options{
+		CertFile:     c.TLSCertFile,
+		KeyFile:      c.TLSKeyFile,
+		ClientCAFile: c.TLSClientCAFile,
+		ClientAuth:   c.TLSClientAuth,
+		MinVersion:   c.TLSMinVersion,
+		CipherSuites: c.TLSCipherSuites,
+	}
+}
+
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+func envBool(name string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+func envInt64(name string, def int64) int64 {
+	if v, err := strconv.ParseInt(os.Getenv(name), 10, 64); err == nil {
+		return v
+	}
+	return def
+}
+
+func envFloat(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// envTime parses an RFC 3339 timestamp or date, returning zero if unset
+func envTime(name string) time.Time {
+	v := os.Getenv(name)
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// envList splits a comma-separated variable, dropping empty items
+func envList(name string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// envWeights parses "key=2,key2=0.5" into a map, dropping weights that
+// are not positive numbers
+func envWeights(name string) map[string]float64 {
+	out := make(map[string]float64)
+	for key, values := range envMapping(name) {
+		if w, err := strconv.ParseFloat(values[0], 64); err == nil && w > 0 {
+			out[key] = w
+		}
+	}
+	return out
+}
+
+// envMapping parses "key=v1|v2,key2=v3" into a map
+func envMapping(name string) map[string][]string {
+	out := make(map[string][]string)
+	for _, item := range envList(name) {
+		key, values, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		for _, v := range strings.Split(values, "|") {
+			if v = strings.TrimSpace(v); v != "" {
+				out[strings.TrimSpace(key)] = append(out[strings.TrimSpace(key)], v)
+			}
+		}
+	}
+	return out
+}
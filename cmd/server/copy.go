@@ -0,0 +1,275 @@
+// cmd/server/copy.go
+// Server-side copy, including cross-tenant copies authorized by share grants.
+// Objects in the part store are copied by sharing their parts; others are
+// written again from their plaintext without leaving the server. The
+// destination tenant's storage quota is charged, but not its ingress.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/multipart"
+	"github.com/minio/enterprise/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tenantFromRequest reads the tenant from X-Tenant-ID, falling back to ?tenant_id
+func tenantFromRequest(r *http.Request) string {
+	if tenantID := r.Header.Get("X-Tenant-ID"); tenantID != "" {
+		return tenantID
+	}
+	return r.URL.Query().Get("tenant_id")
+}
+
+// logAudit records an event, logging rather than failing the request on error
+func (s *MinIOServer) logAudit(ctx context.Context, ev audit.Event) {
+	if ev.RequestID == "" {
+		ev.RequestID = tracing.RequestID(ctx)
+	}
+	if err := s.auditLog.Log(ev); err != nil {
+		tracing.RecordError(ctx, err)
+	}
+}
+
+// Copy methods
+const (
+	copyShared = "shared_parts" // The copy's manifest shares the source's parts
+	copyData   = "data"         // The plaintext was written again
+)
+
+// copyObject copies srcTenant/srcKey to dstTenant/dstKey, checking cond
+// against the destination. The copy keeps the source's user metadata and
+// tags, or takes attrs instead when it is set. It returns the copy's
+// metadata and the method used.
+func (s *MinIOServer) copyObject(ctx context.Context, srcTenant, srcKey, dstTenant, dstKey string, cond writeCondition, attrs *metadata.Attributes) (*metadata.ObjectMeta, string, error) {
+	ctx = serverSide(ctx)
+
+	// Part store objects stay there when the destination can hold them
+	if src, err := s.index.Get(srcTenant, srcKey); err == nil && src.Manifest != "" && s.checkMultipart(ctx, dstTenant) == nil {
+		if attrs == nil {
+			attrs = &src.Attributes
+		}
+		m, err := s.parts.Copy(src.Manifest, dstTenant, dstKey)
+		if errors.Is(err, multipart.ErrNoSuchUpload) {
+			return nil, copyShared, errObjectNotFound // Replaced or deleted since
+		}
+		if err != nil {
+			return nil, copyShared, errStoreFailed
+		}
+		err = s.inspectParts(ctx, m)
+		var meta *metadata.ObjectMeta
+		if err == nil {
+			meta, err = s.completeObject(ctx, m, cond, *attrs)
+		}
+		if err != nil {
+			s.parts.Delete(m.ID)
+			return nil, copyShared, err
+		}
+		s.copiesShared.Add(1)
+		return meta, copyShared, nil
+	}
+
+	data, src, err := s.getObject(ctx, srcTenant, srcKey)
+	if err != nil {
+		return nil, copyData, err
+	}
+	if attrs == nil {
+		attrs = &src.Attributes
+	}
+	if err := s.inspectUpload(ctx, dstTenant, dstKey, data, *attrs); err != nil {
+		return nil, copyData, err
+	}
+	meta, err := s.putObjectIf(ctx, dstTenant, dstKey, data, cond, *attrs)
+	return meta, copyData, err
+}
+
+// copyAttributes returns the attributes a copy is written with: nil to
+// keep the source's, or with X-Amz-Metadata-Directive: REPLACE the user
+// metadata and tags the request carries
+func copyAttributes(r *http.Request) (*metadata.Attributes, error) {
+	switch r.Header.Get("X-Amz-Metadata-Directive") {
+	case "", "COPY":
+		return nil, nil
+	case "REPLACE":
+		attrs, err := requestAttributes(r)
+		return &attrs, err
+	}
+	return nil, &httpError{http.StatusBadRequest, codeInvalidRequest, "X-Amz-Metadata-Directive must be COPY or REPLACE"}
+}
+
+// handleCopy copies source_tenant/source_key to key in the requesting tenant.
+// The destination is charged for the copy; cross-tenant copies need a grant.
+// If-Match and If-None-Match apply to the destination.
+func (s *MinIOServer) handleCopy(w http.ResponseWriter, r *http.Request) {
+	tracer := tracing.GetTracer("http")
+	ctx, span := tracing.StartSpan(r.Context(), tracer, "PUT /copy",
+		attribute.String("http.method", r.Method),
+		attribute.String("http.url", r.URL.String()),
+	)
+	defer span.End()
+
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dstTenant := tenantFromRequest(r)
+	dstKey := query.Get("key")
+	srcTenant := query.Get("source_tenant")
+	srcKey := query.Get("source_key")
+	if srcTenant == "" {
+		srcTenant = dstTenant
+	}
+	tracing.AddSpanAttributes(ctx,
+		attribute.String("tenant.id", dstTenant),
+		attribute.String("object.key", dstKey),
+		attribute.String("source.tenant.id", srcTenant),
+		attribute.String("source.object.key", srcKey),
+	)
+
+	if dstTenant == "" || dstKey == "" || srcKey == "" {
+		writeErrorMessage(w, r, "Missing tenant ID, key or source_key", http.StatusBadRequest)
+		return
+	}
+	attrs, err := copyAttributes(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if srcTenant == dstTenant && srcKey == dstKey && attrs == nil {
+		writeErrorMessage(w, r, "Copying an object onto itself requires X-Amz-Metadata-Directive: REPLACE", http.StatusBadRequest)
+		return
+	}
+
+	details := map[string]string{
+		"source_tenant": srcTenant,
+		"source_key":    srcKey,
+		"dest_tenant":   dstTenant,
+		"dest_key":      dstKey,
+	}
+
+	if srcTenant != dstTenant {
+		grant, err := s.tenantManager.CheckGrant(ctx, srcTenant, dstTenant, srcKey)
+		if err != nil {
+			tracing.AddSpanEvent(ctx, "grant_denied")
+			s.logAudit(ctx, audit.Event{TenantID: srcTenant, Actor: dstTenant, Action: "object.copy_out", Resource: srcKey, Outcome: audit.OutcomeDenied, Details: details})
+			s.logAudit(ctx, audit.Event{TenantID: dstTenant, Actor: dstTenant, Action: "object.copy_in", Resource: dstKey, Outcome: audit.OutcomeDenied, Details: details})
+			writeErrorMessage(w, r, "No share grant covers source object", http.StatusForbidden)
+			return
+		}
+		details["grant_id"] = grant.ID
+	}
+
+	for _, tenantID := range []string{srcTenant, dstTenant} {
+		if err := s.checkTenantAccess(r, tenantID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	p := s.requestPrincipal(r)
+	err = s.authorize(p, srcTenant, srcKey, actionRead)
+	if err == nil {
+		err = s.authorize(p, dstTenant, dstKey, actionWrite)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	meta, method, err := s.copyObject(ctx, srcTenant, srcKey, dstTenant, dstKey, uploadCondition(r), attrs)
+	outcome := audit.OutcomeSuccess
+	details["method"] = method
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		outcome = audit.OutcomeError
+		if err == errQuotaExceeded {
+			outcome = audit.OutcomeDenied
+		}
+		details["error"] = err.Error()
+	} else {
+		details["size"] = strconv.FormatInt(meta.Size, 10)
+	}
+
+	if srcTenant != dstTenant {
+		s.logAudit(ctx, audit.Event{TenantID: srcTenant, Actor: dstTenant, Action: "object.copy_out", Resource: srcKey, Outcome: outcome, Details: details})
+	}
+	s.logAudit(ctx, audit.Event{TenantID: dstTenant, Actor: dstTenant, Action: "object.copy_in", Resource: dstKey, Outcome: outcome, Details: details})
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	tracing.AddSpanEvent(ctx, "copy_completed")
+	w.Header().Set("X-Version-ID", meta.VersionID)
+	w.Header().Set("ETag", `"`+meta.ETag+`"`)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":        "copied",
+		"key":           dstKey,
+		"source_tenant": srcTenant,
+		"source_key":    srcKey,
+		"size":          meta.Size,
+		"version_id":    meta.VersionID,
+		"etag":          meta.ETag,
+		"method":        method,
+	})
+}
+
+// grantRequest is the body of POST /admin/grants
+type grantRequest struct {
+	SourceTenant string    `json:"source_tenant"`
+	TargetTenant string    `json:"target_tenant"`
+	Prefix       string    `json:"prefix"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// handleAdminGrants creates (POST), lists (GET ?tenant=) or revokes (DELETE ?id=) share grants
+func (s *MinIOServer) handleAdminGrants(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodPost:
+		var req grantRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid grant request", http.StatusBadRequest)
+			return
+		}
+		grant, err := s.tenantManager.CreateGrant(ctx, req.SourceTenant, req.TargetTenant, req.Prefix, req.ExpiresAt)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logAudit(ctx, audit.Event{TenantID: grant.SourceTenant, Actor: "admin", Action: "grant.create", Resource: grant.ID,
+			Details: map[string]string{"target_tenant": grant.TargetTenant, "prefix": grant.Prefix}})
+		writeJSON(w, http.StatusCreated, grant)
+
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.tenantManager.ListGrants(ctx, r.URL.Query().Get("tenant")))
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if err := s.tenantManager.RevokeGrant(ctx, id); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logAudit(ctx, audit.Event{Actor: "admin", Action: "grant.revoke", Resource: id})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// usageOf returns tenantID's stored bytes and ingress
+func usageOf(t *testing.T, tenantID string) (storage, ingress int64) {
+	t.Helper()
+	usage, err := testServer.tenantManager.GetUsage(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usage.StorageUsed.Load(), usage.IngressUsed.Load()
+}
+
+// Copies within a tenant need its read and write permissions, across
+// tenants a share grant, and charge the destination's storage but not its
+// ingress
+func TestCopy(t *testing.T) {
+	srcID, dstID := newTenant(t), newTenant(t)
+	upload(t, srcID, "doc.txt", "hello")
+	copyTo := func(tenantID, key, srcTenant, srcKey string, creds ...credential) int {
+		t.Helper()
+		w := do(t, "PUT", "/v1/copy?tenant_id="+tenantID+"&key="+key+"&source_tenant="+srcTenant+"&source_key="+srcKey, nil, creds...)
+		return w.Code
+	}
+	download := func(tenantID, key string) string {
+		t.Helper()
+		w := do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key="+key, nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		return w.Body.String()
+	}
+
+	storage, ingress := usageOf(t, srcID)
+	if code := copyTo(srcID, "copy.txt", srcID, "doc.txt", bearer(tenantToken(t, srcID, "alice", "object:read"))); code != http.StatusForbidden {
+		t.Errorf("Copy without write permission: status %d, want 403", code)
+	}
+	if code := copyTo(srcID, "doc.txt", srcID, "doc.txt", adminAuth); code != http.StatusBadRequest {
+		t.Errorf("Copy onto itself: status %d, want 400", code)
+	}
+	if code := copyTo(srcID, "copy.txt", srcID, "doc.txt", bearer(tenantToken(t, srcID, "alice", "object:read", "object:write"))); code != http.StatusOK {
+		t.Fatalf("Copy within the tenant: status %d", code)
+	}
+	if got := download(srcID, "copy.txt"); got != "hello" {
+		t.Errorf("Copy holds %q, want hello", got)
+	}
+	if s, i := usageOf(t, srcID); s-storage != int64(len("hello")) || i != ingress {
+		t.Errorf("Copy charged %d bytes stored and %d ingress, want %d and none", s-storage, i-ingress, len("hello"))
+	}
+
+	// Neither the admin nor the destination's principals copy in without a grant
+	for name, creds := range map[string]credential{
+		"admin":             adminAuth,
+		"destination token": bearer(tenantToken(t, dstID, "bob", "*")),
+	} {
+		if code := copyTo(dstID, "in.txt", srcID, "doc.txt", creds); code != http.StatusForbidden {
+			t.Errorf("Copy in as %s without a grant: status %d, want 403", name, code)
+		}
+	}
+	if _, err := testServer.index.Get(dstID, "in.txt"); err == nil {
+		t.Fatal("Denied copy was written")
+	}
+
+	w := do(t, "POST", "/v1/admin/grants", grantRequest{SourceTenant: srcID, TargetTenant: dstID, Prefix: "shared/",
+		ExpiresAt: time.Now().Add(time.Hour)}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+	if code := copyTo(dstID, "in.txt", srcID, "doc.txt", adminAuth); code != http.StatusForbidden {
+		t.Errorf("Copy outside the grant's prefix: status %d, want 403", code)
+	}
+	upload(t, srcID, "shared/doc.txt", "shared")
+	storage, ingress = usageOf(t, dstID)
+	if code := copyTo(dstID, "in.txt", srcID, "shared/doc.txt", adminAuth); code != http.StatusOK {
+		t.Fatalf("Copy under a grant: status %d", code)
+	}
+	if got := download(dstID, "in.txt"); got != "shared" {
+		t.Errorf("Copy holds %q, want shared", got)
+	}
+	if s, i := usageOf(t, dstID); s-storage != int64(len("shared")) || i != ingress {
+		t.Errorf("Copy charged %d bytes stored and %d ingress, want %d and none", s-storage, i-ingress, len("shared"))
+	}
+}
@@ -0,0 +1,118 @@
+// cmd/server/deletebatch.go
+// Batch delete: removes up to 1000 keys in one request. Each key goes
+// through the same path as DELETE /delete, invalidating its cache entry and
+// releasing its storage quota, and fails or succeeds on its own.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/minio/enterprise/internal/audit"
+)
+
+const (
+	// deleteBatchMaxKeys bounds the keys in one batch delete, as in S3
+	deleteBatchMaxKeys = 1000
+
+	// deleteBatchMaxBody bounds a batch delete request body
+	deleteBatchMaxBody = 2 << 20
+)
+
+// deleteBatchRequest is the body of POST /delete-batch. Keys are deleted
+// in order; Quiet leaves successful deletes out of the results.
+type deleteBatchRequest struct {
+	Keys  []string `json:"keys"`
+	Quiet bool     `json:"quiet,omitempty"`
+}
+
+// deleteBatchResult is the outcome of deleting one key
+type deleteBatchResult struct {
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// batchDelete deletes one key of a batch with auditing
+func (s *MinIOServer) batchDelete(ctx context.Context, tenantID, key string) error {
+	err := s.checkDeletable(ctx, tenantID, key)
+	if err == nil {
+		err = s.deleteThrough(ctx, tenantID, key)
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.delete", Resource: key,
+			Details: map[string]string{"path": "batch"}}
+		if err != nil {
+			ev.Outcome = audit.OutcomeError
+			ev.Details["error"] = err.Error()
+		}
+		s.logAudit(ctx, ev)
+	}
+	return err
+}
+
+// handleDeleteBatch deletes the keys listed in the body (POST)
+func (s *MinIOServer) handleDeleteBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req deleteBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, deleteBatchMaxBody)).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid batch delete request", http.StatusBadRequest)
+		return
+	}
+	if n := len(req.Keys); n == 0 || n > deleteBatchMaxKeys {
+		writeErrorMessage(w, r, "Batch must contain between 1 and 1000 keys", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	p := s.requestPrincipal(r)
+	deleted, failed := 0, 0
+	results := make([]deleteBatchResult, 0, len(req.Keys))
+	for _, key := range req.Keys {
+		if ctx.Err() != nil {
+			break
+		}
+		var err error
+		if key == "" {
+			err = &httpError{http.StatusBadRequest, codeInvalidRequest, "Missing key"}
+		} else if err = s.authorize(p, tenantID, key, actionDelete); err == nil {
+			err = s.batchDelete(ctx, tenantID, key)
+		}
+		if err != nil {
+			he := asHTTPError(err)
+			results = append(results, deleteBatchResult{Key: key, Status: he.Status, Code: he.Code, Error: he.Message})
+			failed++
+			continue
+		}
+		deleted++
+		if !req.Quiet {
+			results = append(results, deleteBatchResult{Key: key, Status: http.StatusNoContent})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": deleted,
+		"failed":  failed,
+		"results": results,
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// A batch deletes the keys it can and reports each failure on its own,
+// releasing storage only for what it deleted
+func TestDeleteBatchPartialFailure(t *testing.T) {
+	tenantID := newTenant(t)
+	createBucket(t, tenantID, "logs", tenant.BucketConfig{AppendOnly: true})
+	for _, key := range []string{"a.txt", "b.txt", "logs/kept.txt"} {
+		upload(t, tenantID, key, "hello")
+	}
+	storage, _ := usageOf(t, tenantID)
+	batch := "/v1/delete-batch?tenant_id=" + tenantID
+
+	var resp struct {
+		Deleted int                 `json:"deleted"`
+		Failed  int                 `json:"failed"`
+		Results []deleteBatchResult `json:"results"`
+	}
+	w := do(t, "POST", batch, deleteBatchRequest{Keys: []string{"a.txt", "missing.txt", "", "logs/kept.txt", "a.txt", "b.txt"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &resp)
+	if resp.Deleted != 2 || resp.Failed != 4 {
+		t.Errorf("Deleted %d and failed %d, want 2 and 4", resp.Deleted, resp.Failed)
+	}
+	var got []int
+	for _, r := range resp.Results {
+		got = append(got, r.Status)
+		if r.Status != http.StatusNoContent && (r.Code == "" || r.Error == "") {
+			t.Errorf("Failure for %q without a code and message: %+v", r.Key, r)
+		}
+	}
+	want := []int{http.StatusNoContent, http.StatusNotFound, http.StatusBadRequest, http.StatusConflict, http.StatusNotFound, http.StatusNoContent}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Statuses %v, want %v", got, want)
+	}
+
+	if _, err := testServer.index.Get(tenantID, "logs/kept.txt"); err != nil {
+		t.Error("Refused key was deleted")
+	}
+	for _, key := range []string{"a.txt", "b.txt"} {
+		if _, err := testServer.index.Get(tenantID, key); err == nil {
+			t.Errorf("%s still indexed", key)
+		}
+	}
+	if s, _ := usageOf(t, tenantID); storage-s != int64(2*len("hello")) {
+		t.Errorf("Released %d bytes, want %d", storage-s, 2*len("hello"))
+	}
+
+	// A principal without delete permission is refused key by key
+	upload(t, tenantID, "c.txt", "hello")
+	w = do(t, "POST", batch, deleteBatchRequest{Keys: []string{"c.txt"}}, bearer(tenantToken(t, tenantID, "alice", "object:read")))
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &resp); resp.Deleted != 0 || len(resp.Results) != 1 || resp.Results[0].Status != http.StatusForbidden {
+		t.Errorf("Read-only batch answered %+v", resp)
+	}
+
+	// Quiet batches report only failures
+	resp.Results = nil
+	resp.Results = nil
+	w = do(t, "POST", batch, deleteBatchRequest{Keys: []string{"c.txt", "missing.txt"}, Quiet: true}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &resp); resp.Deleted != 1 || len(resp.Results) != 1 || resp.Results[0].Key != "missing.txt" {
+		t.Errorf("Quiet batch answered %+v", resp)
+	}
+}
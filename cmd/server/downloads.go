@@ -0,0 +1,38 @@
+// cmd/server/downloads.go
+// Interrupted downloads. Bodies are counted as they are written, so a
+// client that disconnects part way is charged only for the bytes it was
+// sent, and reads from the part store stop as soon as the request's
+// context ends. The client resumes with a Range request from where it
+// stopped, pinned with If-Range, and that request is charged for its range.
+package main
+
+import (
+	"context"
+	"io"
+)
+
+// sentWriter counts the payload bytes written through it
+type sentWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (sw sentWriter) Write(p []byte) (int, error) {
+	n, err := sw.w.Write(p)
+	*sw.n += int64(n)
+	return n, err
+}
+
+// ctxReader fails reads once ctx is done, so copies to a client that has
+// gone away stop without reading the rest of the object
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// brokenWriter is a client that disconnects after receiving left bytes
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+	left int
+}
+
+func (w *brokenWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.left {
+		w.left -= len(p)
+		return w.ResponseRecorder.Write(p)
+	}
+	n, _ := w.ResponseRecorder.Write(p[:w.left])
+	w.left = 0
+	return n, errors.New("broken pipe")
+}
+
+// egress returns the bytes charged to tenantID for downloads
+func egress(t *testing.T, tenantID string) int64 {
+	t.Helper()
+	usage, err := testServer.tenantManager.GetUsage(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usage.EgressUsed.Load()
+}
+
+// A download the client breaks off is charged for the bytes it was sent,
+// and the client resumes it with an If-Range request for the rest
+func TestInterruptedDownload(t *testing.T) {
+	tenantID := newTenant(t)
+	if err := testServer.tenantManager.UpdateSettings(context.Background(), tenantID, tenant.TenantSettings{AuditLogging: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := strings.Repeat("0123456789", 100)
+	upload(t, tenantID, "big.txt", data)
+	target := "/v1/download?tenant_id=" + tenantID + "&key=big.txt"
+	aborted, unsent := testServer.downloadsAborted.Load(), testServer.downloadBytesUnsent.Load()
+	before := egress(t, tenantID)
+
+	r := httptest.NewRequest("GET", target, nil)
+	adminAuth(r)
+	w := &brokenWriter{httptest.NewRecorder(), 300}
+	testServer.httpServer.Handler.ServeHTTP(w, r)
+	if w.Header().Get("Content-Length") != strconv.Itoa(len(data)) || w.Body.Len() != 300 {
+		t.Fatalf("Content-Length %q, received %d bytes", w.Header().Get("Content-Length"), w.Body.Len())
+	}
+	if charged := egress(t, tenantID) - before; charged != 300 {
+		t.Errorf("Charged %d bytes for 300 sent", charged)
+	}
+	if testServer.downloadsAborted.Load()-aborted != 1 || testServer.downloadBytesUnsent.Load()-unsent != uint64(len(data)-300) {
+		t.Errorf("%d aborted, %d unsent; want 1 and %d", testServer.downloadsAborted.Load()-aborted,
+			testServer.downloadBytesUnsent.Load()-unsent, len(data)-300)
+	}
+	found := false
+	for _, ev := range auditEvents(t, tenantID) {
+		if ev.Action == "object.get" && ev.Details["aborted"] == "true" {
+			found = ev.Details["sent"] == "300" && ev.Details["planned"] == strconv.Itoa(len(data))
+		}
+	}
+	if !found {
+		t.Error("No object.get audit event recording the abort")
+	}
+
+	etag := w.Header().Get("ETag")
+	resume := func(ifRange string) *httptest.ResponseRecorder {
+		return do(t, "GET", target, nil, adminAuth, func(r *http.Request) {
+			r.Header.Set("Range", "bytes=300-")
+			r.Header.Set("If-Range", ifRange)
+		})
+	}
+	before = egress(t, tenantID)
+	w2 := resume(etag)
+	expectStatus(t, w2, http.StatusPartialContent)
+	if w2.Body.String() != data[300:] {
+		t.Errorf("Resumed with %d bytes, want the remaining %d", w2.Body.Len(), len(data)-300)
+	}
+	if charged := egress(t, tenantID) - before; charged != int64(len(data)-300) {
+		t.Errorf("Charged %d bytes for the resumed range", charged)
+	}
+
+	// A changed object is sent whole
+	upload(t, tenantID, "big.txt", data+"!")
+	w2 = resume(etag)
+	expectStatus(t, w2, http.StatusOK)
+	if w2.Body.String() != data+"!" {
+		t.Errorf("Got %d bytes after the object changed, want all of it", w2.Body.Len())
+	}
+}
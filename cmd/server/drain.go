@@ -0,0 +1,267 @@
+// cmd/server/drain.go
+// Node decommission: stop accepting new data, re-replicate every object to
+// peers and report when the node is safe to remove
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/replication"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// Drain states
+const (
+	DrainStateDraining = "draining"
+	DrainStateDrained  = "drained"
+	DrainStateFailed   = "failed"
+	DrainStateCanceled = "canceled"
+)
+
+// drainJob tracks one decommission run
+type drainJob struct {
+	ID        string
+	StartedAt time.Time
+
+	total    atomic.Int64
+	migrated atomic.Int64
+	failed   atomic.Int64
+
+	mu         sync.Mutex
+	state      string
+	failedKeys []string
+	finishedAt time.Time
+
+	cancel context.CancelFunc
+}
+
+// drainStatus is the admin API view of a drain
+type drainStatus struct {
+	ID            string     `json:"id"`
+	State         string     `json:"state"`
+	Total         int64      `json:"total"`
+	Migrated      int64      `json:"migrated"`
+	Failed        int64      `json:"failed"`
+	FailedKeys    []string   `json:"failed_keys,omitempty"`
+	SafeToRemove  bool       `json:"safe_to_remove"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	PercentDone   float64    `json:"percent_done"`
+	TargetRegions []string   `json:"target_regions"`
+}
+
+func (j *drainJob) status(regions []string) drainStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	st := drainStatus{
+		ID:            j.ID,
+		State:         j.state,
+		Total:         j.total.Load(),
+		Migrated:      j.migrated.Load(),
+		Failed:        j.failed.Load(),
+		FailedKeys:    append([]string(nil), j.failedKeys...),
+		SafeToRemove:  j.state == DrainStateDrained,
+		StartedAt:     j.StartedAt,
+		TargetRegions: regions,
+	}
+	if !j.finishedAt.IsZero() {
+		finished := j.finishedAt
+		st.FinishedAt = &finished
+	}
+	if st.Total > 0 {
+		st.PercentDone = float64(st.Migrated+st.Failed) / float64(st.Total) * 100
+	} else if j.state == DrainStateDrained {
+		st.PercentDone = 100
+	}
+	return st
+}
+
+func (j *drainJob) finish(state string) {
+	j.mu.Lock()
+	j.state = state
+	j.finishedAt = time.Now()
+	j.mu.Unlock()
+}
+
+// finishDrain ends job in state and records it in the health history
+func (s *MinIOServer) finishDrain(job *drainJob, state string) {
+	job.finish(state)
+	s.recordDrainFinished(job, state)
+}
+
+// Draining reports whether the node refuses new data
+func (s *MinIOServer) Draining() bool {
+	return s.draining.Load()
+}
+
+// startDrain marks the node as draining and begins evacuating objects
+func (s *MinIOServer) startDrain() (*drainJob, error) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	if s.drain != nil {
+		s.drain.mu.Lock()
+		state := s.drain.state
+		s.drain.mu.Unlock()
+		if state == DrainStateDraining {
+			return nil, fmt.Errorf("drain %s already in progress", s.drain.ID)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	job := &drainJob{
+		ID:        fmt.Sprintf("drain-%d", time.Now().UnixNano()),
+		StartedAt: time.Now(),
+		state:     DrainStateDraining,
+		cancel:    cancel,
+	}
+	s.drain = job
+	s.draining.Store(true)
+	s.recordDrain(true, job.ID)
+
+	go s.runDrain(ctx, job)
+	return job, nil
+}
+
+// cancelDrain stops an in-progress drain and resumes accepting data
+func (s *MinIOServer) cancelDrain() error {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	if s.drain == nil {
+		return fmt.Errorf("node is not draining")
+	}
+	s.drain.cancel()
+	if s.draining.Swap(false) {
+		s.recordDrain(false, s.drain.ID)
+	}
+	return nil
+}
+
+func (s *MinIOServer) runDrain(ctx context.Context, job *drainJob) {
+	objects := s.index.Snapshot()
+	job.total.Store(int64(len(objects)))
+
+	var wg sync.WaitGroup
+	for _, meta := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+
+		key := meta.Key
+		regions := s.replicaRegions(ctx, &meta)
+		if len(regions) == 0 {
+			job.recordFailure(key) // No region may hold it
+			continue
+		}
+		src, err := s.replicaSource(ctx, &meta)
+		if err != nil {
+			job.recordFailure(key)
+			continue
+		}
+
+		wg.Add(1)
+		onComplete := func(regions int) {
+			defer wg.Done()
+			if regions > 0 {
+				job.migrated.Add(1)
+			} else {
+				job.recordFailure(key)
+			}
+		}
+
+		if err := s.enqueueWithBackoff(ctx, meta.Tenant, key, meta.VersionID, src, regions, onComplete); err != nil {
+			src.close()
+			wg.Done()
+			job.recordFailure(key)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.finishDrain(job, DrainStateCanceled)
+		return
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		s.finishDrain(job, DrainStateCanceled)
+	case job.failed.Load() > 0:
+		s.finishDrain(job, DrainStateFailed)
+	default:
+		s.finishDrain(job, DrainStateDrained)
+	}
+}
+
+// enqueueWithBackoff queues a backfill replication, retrying while the
+// replication queue is saturated
+func (s *MinIOServer) enqueueWithBackoff(ctx context.Context, bucket, key, versionID string, src *replicaSource, regions []string, onComplete func(int)) error {
+	for {
+		err := s.enqueueReplica(replication.PriorityBulk, bucket, key, versionID, tracing.RequestID(ctx), src, regions, nil, onComplete)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (j *drainJob) recordFailure(key string) {
+	j.failed.Add(1)
+	j.mu.Lock()
+	if len(j.failedKeys) < 1000 {
+		j.failedKeys = append(j.failedKeys, key)
+	}
+	j.mu.Unlock()
+}
+
+// handleAdminDrain starts (POST), reports (GET) or cancels (DELETE) a node drain
+func (s *MinIOServer) handleAdminDrain(w http.ResponseWriter, r *http.Request) {
+	regions := s.replicationEngine.Regions()
+
+	switch r.Method {
+	case http.MethodPost:
+		job, err := s.startDrain()
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, job.status(regions))
+
+	case http.MethodGet:
+		s.drainMu.Lock()
+		job := s.drain
+		s.drainMu.Unlock()
+		if job == nil {
+			writeErrorMessage(w, r, "Node has not been drained", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, job.status(regions))
+
+	case http.MethodDelete:
+		if err := s.cancelDrain(); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// A drain refuses new data from the moment it starts, until canceled, and
+// the node is safe to remove once every object is evacuated
+func TestAdminDrain(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "a.txt", "data")
+
+	w := do(t, "GET", "/v1/admin/drain", nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+	w = do(t, "POST", "/v1/admin/drain", nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "POST", "/v1/admin/drain", nil, adminAuth)
+	expectStatus(t, w, http.StatusAccepted)
+	defer testServer.cancelDrain()
+	var st drainStatus
+	if decode(t, w, &st); st.State != DrainStateDraining || st.SafeToRemove {
+		t.Errorf("Started %+v, want draining", st)
+	}
+
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=b.txt", "data", adminAuth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+
+	for deadline := time.Now().Add(10 * time.Second); st.State == DrainStateDraining; {
+		if time.Now().After(deadline) {
+			t.Fatal("Drain did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+		w = do(t, "GET", "/v1/admin/drain", nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		decode(t, w, &st)
+	}
+	if st.State != DrainStateDrained || !st.SafeToRemove || st.Total == 0 || st.Migrated != st.Total || st.FinishedAt == nil {
+		t.Errorf("Finished %+v, want every object migrated", st)
+	}
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=b.txt", "data", adminAuth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+
+	w = do(t, "DELETE", "/v1/admin/drain", nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=b.txt", "data", adminAuth)
+	expectStatus(t, w, http.StatusOK)
+}
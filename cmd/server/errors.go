@@ -0,0 +1,225 @@
+// cmd/server/errors.go
+// Error responses. Every API error is a machine-readable code with a
+// human-readable message, the resource it concerns and the request ID,
+// serialized as JSON or, for clients that ask for it, as S3-style XML.
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// Error codes. Names follow S3's where S3 has an equivalent so S3 tooling
+// recognizes them; the rest are specific to this server.
+const (
+	codeInvalidRequest      = "InvalidRequest"
+	codeUnauthorized        = "Unauthorized"
+	codeAccessDenied        = "AccessDenied"
+	codeNotFound            = "NotFound"
+	codeMethodNotAllowed    = "MethodNotAllowed"
+	codeConflict            = "Conflict"
+	codeGone                = "Gone"
+	codePreconditionFailed  = "PreconditionFailed"
+	codeEntityTooLarge      = "EntityTooLarge"
+	codeInternalError       = "InternalError"
+	codeBadGateway          = "BadGateway"
+	codeServiceUnavailable  = "ServiceUnavailable"
+	codeInsufficientStorage = "InsufficientStorage"
+
+	codeNoSuchKey         = "NoSuchKey"
+	codeSlowDown          = "SlowDown"
+	codeQuotaExceeded     = "QuotaExceeded"
+	codeNodeDraining      = "NodeDraining"
+	codeObjectExists      = "ObjectExists"
+	codeObjectLocked      = "ObjectLocked"
+	codeKeyShredded       = "KeyShredded"
+	codeTLSRequired       = "TLSRequired"
+	codeRegionForbidden   = "RegionForbidden"
+	codeAppendOnly        = "AppendOnly"
+	codeTenantSuspended   = "TenantSuspended"
+	codeGatewayFailed     = "GatewayFailed"
+	codeShareLinkExpired  = "ShareLinkExpired"
+	codeShareLinkPassword = "ShareLinkPasswordRequired"
+	codeInvalidContent    = "InvalidContentType"
+	codeMalwareDetected   = "MalwareDetected"
+	codeInvalidImage      = "InvalidImage"
+	codeChangesExpired    = "ChangesExpired"
+	codeMissingLength     = "MissingContentLength"
+	codeNoSuchUpload      = "NoSuchUpload"
+	codeInvalidPart       = "InvalidPart"
+	codeInvalidPartOrder  = "InvalidPartOrder"
+	codeEntityTooSmall    = "EntityTooSmall"
+	codeBadDigest         = "BadDigest"
+	codeNotImplemented    = "NotImplemented"
+	codeNoSuchBucket      = "NoSuchBucket"
+	codeBucketExists      = "BucketAlreadyExists"
+	codeBucketNotEmpty    = "BucketNotEmpty"
+	codeInvalidTag        = "InvalidTag"
+	codeMetadataTooLarge  = "MetadataTooLarge"
+	codeNotModified       = "NotModified"
+	codeMalformedACL      = "MalformedACLError"
+)
+
+// statusCodes gives the code for errors that carry only a status
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeInvalidRequest,
+	http.StatusUnauthorized:          codeUnauthorized,
+	http.StatusForbidden:             codeAccessDenied,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+	http.StatusConflict:              codeConflict,
+	http.StatusGone:                  codeGone,
+	http.StatusPreconditionFailed:    codePreconditionFailed,
+	http.StatusRequestEntityTooLarge: codeEntityTooLarge,
+	http.StatusInternalServerError:   codeInternalError,
+	http.StatusBadGateway:            codeBadGateway,
+	http.StatusServiceUnavailable:    codeServiceUnavailable,
+	http.StatusInsufficientStorage:   codeInsufficientStorage,
+}
+
+// codeForStatus returns the generic code for an HTTP status
+func codeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return strings.ReplaceAll(http.StatusText(status), " ", "")
+}
+
+// httpError carries the status code and error code a handler should
+// respond with
+type httpError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *httpError) Error() string {
+	return e.Message
+}
+
+var (
+	errNodeDraining    = &httpError{http.StatusServiceUnavailable, codeNodeDraining, "Node is draining"}
+	errNodeReadOnly    = &httpError{http.StatusInsufficientStorage, codeInsufficientStorage, "Node is read-only: insufficient disk space"}
+	errQuotaExceeded   = &httpError{http.StatusForbidden, codeQuotaExceeded, "Quota exceeded"}
+	errIngressQuota    = &httpError{http.StatusForbidden, codeQuotaExceeded, "Ingress quota exceeded"}
+	errEgressQuota     = &httpError{http.StatusForbidden, codeQuotaExceeded, "Egress quota exceeded"}
+	errIntentFailed    = &httpError{http.StatusInternalServerError, codeInternalError, "Failed to record intent"}
+	errStoreFailed     = &httpError{http.StatusInternalServerError, codeInternalError, "Failed to store object"}
+	errCommitFailed    = &httpError{http.StatusInternalServerError, codeInternalError, "Failed to commit upload"}
+	errObjectNotFound  = &httpError{http.StatusNotFound, codeNoSuchKey, "Object not found"}
+	errObjectExists    = &httpError{http.StatusConflict, codeObjectExists, "Destination object exists"}
+	errKeyShredded     = &httpError{http.StatusGone, codeKeyShredded, "Tenant data has been crypto-shredded"}
+	errKeyUnavailable  = &httpError{http.StatusInternalServerError, codeInternalError, "Tenant data key unavailable"}
+	errDecryptFailed   = &httpError{http.StatusInternalServerError, codeInternalError, "Failed to decrypt object"}
+	errTLSRequired     = &httpError{http.StatusForbidden, codeTLSRequired, "Tenant requires TLS"}
+	errObjectLocked    = &httpError{http.StatusConflict, codeObjectLocked, "Object is locked"}
+	errRegionForbidden = &httpError{http.StatusForbidden, codeRegionForbidden, "Tenant data may not be stored in this region"}
+	errTooManyUploads  = &httpError{http.StatusServiceUnavailable, codeSlowDown, "Too many concurrent uploads"}
+	errRateLimited     = &httpError{http.StatusTooManyRequests, codeSlowDown, "Tenant request rate limit exceeded"}
+	errVersionMismatch = &httpError{http.StatusPreconditionFailed, codePreconditionFailed, "Object version does not match"}
+	errModifiedSince   = &httpError{http.StatusPreconditionFailed, codePreconditionFailed, "Object was modified since If-Unmodified-Since"}
+	errNotModified     = &httpError{http.StatusNotModified, codeNotModified, "Object not modified"}
+	errBucketAppend    = &httpError{http.StatusConflict, codeAppendOnly, "Bucket is append-only"}
+	errNoRegionKey     = &httpError{http.StatusInternalServerError, codeInternalError, "Replication region key unavailable"}
+	errGatewayFailed   = &httpError{http.StatusBadGateway, codeGatewayFailed, "Tenant bucket unavailable"}
+	errLengthRequired  = &httpError{http.StatusLengthRequired, codeMissingLength, "Content-Length required; chunked uploads are disabled"}
+	errContentType     = &httpError{http.StatusUnsupportedMediaType, codeInvalidContent, "Content type not allowed for tenant"}
+	errMalwareDetected = &httpError{http.StatusUnprocessableEntity, codeMalwareDetected, "Object failed malware scan"}
+	errScanUnavailable = &httpError{http.StatusServiceUnavailable, codeServiceUnavailable, "Content scanner unavailable"}
+	errNotImage        = &httpError{http.StatusUnsupportedMediaType, codeInvalidImage, "Object is not a supported image"}
+	errImageTooLarge   = &httpError{http.StatusRequestEntityTooLarge, codeEntityTooLarge, "Image too large to transform"}
+	errNoTransforms    = &httpError{http.StatusForbidden, codeAccessDenied, "Image transformations are not enabled for tenant"}
+	errOwnerMismatch   = &httpError{http.StatusForbidden, codeAccessDenied, "Bucket is not owned by the expected tenant"}
+	errNoSuchUpload    = &httpError{http.StatusNotFound, codeNoSuchUpload, "Multipart upload not found"}
+	errNoCredentials   = &httpError{http.StatusUnauthorized, codeUnauthorized, "Tenant credentials required"}
+	errPartsEncrypted  = &httpError{http.StatusNotImplemented, codeNotImplemented, "Multipart uploads are not supported for tenants that encrypt at rest"}
+	errNoSuchBucket    = &httpError{http.StatusNotFound, codeNoSuchBucket, "Bucket not found"}
+	errBucketExists    = &httpError{http.StatusConflict, codeBucketExists, "Bucket already exists"}
+	errBucketNotEmpty  = &httpError{http.StatusConflict, codeBucketNotEmpty, "Bucket is not empty"}
+	errBucketNotQueued = &httpError{http.StatusServiceUnavailable, codeServiceUnavailable, "Bucket config could not be queued for replication"}
+	errObjectDenied    = &httpError{http.StatusForbidden, codeAccessDenied, "Credentials do not grant access to object"}
+	errNotPublic       = &httpError{http.StatusForbidden, codeAccessDenied, "Object is not public"}
+	errPublicLimited   = &httpError{http.StatusTooManyRequests, codeSlowDown, "Public request rate limit exceeded"}
+	errOtherTenant     = &httpError{http.StatusForbidden, codeAccessDenied, "Credentials are for another tenant"}
+	errNotLinkOwner    = &httpError{http.StatusForbidden, codeAccessDenied, "Share link was created by another principal"}
+)
+
+// asHTTPError returns err's httpError, reporting anything else as an
+// internal error
+func asHTTPError(err error) *httpError {
+	var he *httpError
+	if !errors.As(err, &he) {
+		he = &httpError{http.StatusInternalServerError, codeInternalError, err.Error()}
+	}
+	return he
+}
+
+// errorResponse is the body of every API error response
+type errorResponse struct {
+	XMLName   xml.Name `json:"-" xml:"Error"`
+	Code      string   `json:"code" xml:"Code"`
+	Message   string   `json:"message" xml:"Message"`
+	Resource  string   `json:"resource,omitempty" xml:"Resource,omitempty"`
+	RequestID string   `json:"requestId" xml:"RequestId"`
+}
+
+// errorResource names what r addressed: /tenant/key for object requests,
+// otherwise the path
+func errorResource(r *http.Request) string {
+	tenantID, key := tenantFromRequest(r), r.URL.Query().Get("key")
+	if tenantID != "" && key != "" {
+		return "/" + tenantID + "/" + key
+	}
+	return r.URL.Path
+}
+
+// wantsXML reports whether the client prefers S3-style XML errors
+func wantsXML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/xml") || strings.Contains(accept, "text/xml")
+}
+
+// writeError responds with err's status and code, defaulting to 500
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	he := asHTTPError(err)
+	if he.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	// 304 responses carry no body
+	if he.Status == http.StatusNotModified {
+		w.WriteHeader(he.Status)
+		return
+	}
+
+	resp := errorResponse{
+		Code:      he.Code,
+		Message:   he.Message,
+		Resource:  errorResource(r),
+		RequestID: tracing.RequestID(r.Context()),
+	}
+	if resp.Code == "" {
+		resp.Code = codeForStatus(he.Status)
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if wantsXML(r) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(he.Status)
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).Encode(resp)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(he.Status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// writeErrorMessage responds with message under the generic code for status
+func writeErrorMessage(w http.ResponseWriter, r *http.Request, message string, status int) {
+	writeError(w, r, &httpError{Status: status, Message: message})
+}
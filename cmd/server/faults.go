@@ -0,0 +1,260 @@
+// cmd/server/faults.go
+// Fault injection for test tenants. Operators attach a fault profile to a
+// tenant through the admin API, and that tenant's requests are then
+// delayed, failed or throttled at the configured rates, so customers can
+// exercise their retry and timeout handling against staging.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+)
+
+// faultProfile describes the faults injected into one tenant's requests
+type faultProfile struct {
+	// LatencyMs is added to every request, plus up to JitterMs more
+	LatencyMs int `json:"latency_ms,omitempty"`
+	JitterMs  int `json:"jitter_ms,omitempty"`
+
+	// ErrorPercent of requests fail with ErrorStatus (default 500), and
+	// ThrottlePercent are refused with 429 as if rate limited
+	ErrorPercent    float64 `json:"error_percent,omitempty"`
+	ErrorStatus     int     `json:"error_status,omitempty"`
+	ThrottlePercent float64 `json:"throttle_percent,omitempty"`
+
+	// Operations limits the faults to request classes (read, write,
+	// delete, list); empty applies them to all
+	Operations []string `json:"operations,omitempty"`
+
+	// ExpiresAt, if set, is when the profile stops applying
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// maxFaultLatency caps the delay a profile may add to a request
+const maxFaultLatency = 60 * time.Second
+
+func (p *faultProfile) validate() error {
+	if p.LatencyMs < 0 || p.JitterMs < 0 {
+		return errors.New("latency_ms and jitter_ms must not be negative")
+	}
+	if time.Duration(p.LatencyMs+p.JitterMs)*time.Millisecond > maxFaultLatency {
+		return fmt.Errorf("latency_ms plus jitter_ms must not exceed %d", maxFaultLatency.Milliseconds())
+	}
+	if p.ErrorPercent < 0 || p.ErrorPercent > 100 || p.ThrottlePercent < 0 || p.ThrottlePercent > 100 {
+		return errors.New("error_percent and throttle_percent must be between 0 and 100")
+	}
+	switch p.ErrorStatus {
+	case 0:
+		p.ErrorStatus = http.StatusInternalServerError
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return errors.New("error_status must be 500, 502, 503 or 504")
+	}
+	for _, op := range p.Operations {
+		if !containsString(requestClasses, op) {
+			return fmt.Errorf("unknown operation %q; use %s", op, strings.Join(requestClasses, ", "))
+		}
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at is in the past")
+	}
+	return nil
+}
+
+// applies reports whether the profile covers requests of class op at now
+func (p *faultProfile) applies(op string, now time.Time) bool {
+	if p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) {
+		return false
+	}
+	return len(p.Operations) == 0 || containsString(p.Operations, op)
+}
+
+// faultInjector holds the fault profiles of tenants, keyed by tenant ID
+type faultInjector struct {
+	mu       sync.RWMutex
+	profiles map[string]faultProfile
+
+	delayed   atomic.Uint64
+	failed    atomic.Uint64
+	throttled atomic.Uint64
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{profiles: make(map[string]faultProfile)}
+}
+
+func (f *faultInjector) get(tenantID string) (faultProfile, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	p, ok := f.profiles[tenantID]
+	return p, ok
+}
+
+func (f *faultInjector) set(tenantID string, p faultProfile) {
+	f.mu.Lock()
+	f.profiles[tenantID] = p
+	f.mu.Unlock()
+}
+
+func (f *faultInjector) remove(tenantID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.profiles[tenantID]
+	delete(f.profiles, tenantID)
+	return ok
+}
+
+// all returns the profiles in force, dropping expired ones
+func (f *faultInjector) all() map[string]faultProfile {
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]faultProfile, len(f.profiles))
+	for id, p := range f.profiles {
+		if p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) {
+			delete(f.profiles, id)
+			continue
+		}
+		out[id] = p
+	}
+	return out
+}
+
+// faultClass returns the request class of r for matching operations
+func faultClass(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		switch unversionedPath(r.URL.Path) {
+		case "/list", "/changes":
+			return requestList
+		case "/public":
+			return requestPublic
+		}
+		return requestRead
+	case http.MethodDelete:
+		return requestDelete
+	}
+	return requestWrite
+}
+
+// withFaults applies the fault profile of the tenant a request names, if
+// fault injection is enabled. It covers the same requests as rate limiting,
+// and marks each injected fault with an X-Fault-Injected header.
+func (s *MinIOServer) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := tenantFromRequest(r)
+		if !s.config.FaultInjection || tenantID == "" || strings.HasPrefix(unversionedPath(r.URL.Path), "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		p, ok := s.faults.get(tenantID)
+		if !ok || !p.applies(faultClass(r), time.Now()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if delay := time.Duration(p.LatencyMs) * time.Millisecond; delay > 0 || p.JitterMs > 0 {
+			if p.JitterMs > 0 {
+				delay += time.Duration(rand.Intn(p.JitterMs+1)) * time.Millisecond
+			}
+			w.Header().Add("X-Fault-Injected", "latency")
+			s.faults.delayed.Add(1)
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-r.Context().Done():
+				t.Stop()
+				return
+			}
+		}
+		if p.ThrottlePercent > 0 && rand.Float64()*100 < p.ThrottlePercent {
+			w.Header().Add("X-Fault-Injected", "throttle")
+			w.Header().Set("Retry-After", "1")
+			s.faults.throttled.Add(1)
+			writeError(w, r, errRateLimited)
+			return
+		}
+		if p.ErrorPercent > 0 && rand.Float64()*100 < p.ErrorPercent {
+			w.Header().Add("X-Fault-Injected", "error")
+			s.faults.failed.Add(1)
+			writeError(w, r, &httpError{p.ErrorStatus, codeForStatus(p.ErrorStatus), "Injected fault"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleAdminFaults lists fault profiles (GET, or one with ?tenant_id=),
+// attaches one to a tenant (PUT) or removes it (DELETE)
+func (s *MinIOServer) handleAdminFaults(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.URL.Query().Get("tenant_id")
+	if tenantID == "" && r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Missing tenant_id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profiles := s.faults.all()
+		if tenantID != "" {
+			p, ok := profiles[tenantID]
+			if !ok {
+				writeErrorMessage(w, r, "No fault profile for tenant", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+		writeJSON(w, http.StatusOK, profiles)
+
+	case http.MethodPut:
+		if !s.config.FaultInjection {
+			writeErrorMessage(w, r, "Fault injection is disabled; set MINIO_FAULT_INJECTION=true", http.StatusConflict)
+			return
+		}
+		if _, err := s.tenantManager.GetTenant(r.Context(), tenantID); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		var p faultProfile
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&p); err != nil {
+			writeErrorMessage(w, r, "Invalid fault profile", http.StatusBadRequest)
+			return
+		}
+		if err := p.validate(); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.faults.set(tenantID, p)
+
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "faults.configure",
+			Details: map[string]string{
+				"latency_ms":       strconv.Itoa(p.LatencyMs),
+				"error_percent":    strconv.FormatFloat(p.ErrorPercent, 'g', -1, 64),
+				"throttle_percent": strconv.FormatFloat(p.ThrottlePercent, 'g', -1, 64),
+				"operations":       strings.Join(p.Operations, ","),
+			}})
+		writeJSON(w, http.StatusOK, p)
+
+	case http.MethodDelete:
+		if !s.faults.remove(tenantID) {
+			writeErrorMessage(w, r, "No fault profile for tenant", http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "faults.remove"})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
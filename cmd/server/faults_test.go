@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// A tenant's fault profile delays, fails or throttles the operations it
+// names, and is dropped with the tenant
+func TestAdminFaults(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	faults := "/v1/admin/faults?tenant_id=" + tenantID
+	put := "/v1/upload?tenant_id=" + tenantID + "&key=new.txt"
+	get := "/v1/download?tenant_id=" + tenantID + "&key=doc.txt"
+
+	w := do(t, "PUT", faults, faultProfile{ErrorPercent: 100}, adminAuth)
+	expectStatus(t, w, http.StatusConflict)
+	testServer.config.FaultInjection = true
+	defer func() { testServer.config.FaultInjection = false }()
+
+	w = do(t, "PUT", faults, faultProfile{ErrorPercent: 100}, bearer(tenantToken(t, tenantID, "alice", "object:write")))
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "PUT", faults, faultProfile{ErrorPercent: 100, ErrorStatus: http.StatusTeapot}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "PUT", faults, faultProfile{Operations: []string{"rename"}}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "PUT", "/v1/admin/faults?tenant_id=unknown", faultProfile{ErrorPercent: 100}, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+
+	w = do(t, "PUT", faults, faultProfile{ErrorPercent: 100, ErrorStatus: http.StatusServiceUnavailable, Operations: []string{requestWrite}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "PUT", put, "data", adminAuth)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+	if w.Header().Get("X-Fault-Injected") != "error" {
+		t.Errorf("X-Fault-Injected %q, want error", w.Header().Get("X-Fault-Injected"))
+	}
+	w = do(t, "GET", get, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	w = do(t, "PUT", faults, faultProfile{ThrottlePercent: 100}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", get, nil, adminAuth)
+	expectStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Throttled without Retry-After")
+	}
+
+	w = do(t, "PUT", faults, faultProfile{LatencyMs: 50}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	start := time.Now()
+	w = do(t, "GET", get, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || w.Header().Get("X-Fault-Injected") != "latency" {
+		t.Errorf("Served in %v with X-Fault-Injected %q, want a 50ms delay", elapsed, w.Header().Get("X-Fault-Injected"))
+	}
+
+	var profile faultProfile
+	w = do(t, "GET", faults, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &profile); profile.LatencyMs != 50 {
+		t.Errorf("Profile %+v, want the latency one", profile)
+	}
+	w = do(t, "DELETE", faults, nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "DELETE", faults, nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+	w = do(t, "PUT", put, "data", adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	// An expired profile no longer applies
+	expires := time.Now().Add(50 * time.Millisecond)
+	w = do(t, "PUT", faults, faultProfile{ErrorPercent: 100, ExpiresAt: &expires}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	time.Sleep(60 * time.Millisecond)
+	w = do(t, "GET", get, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	w = do(t, "PUT", faults, faultProfile{ErrorPercent: 100}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "DELETE", "/v1/admin/tenants?id="+tenantID+"&purge=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	if _, ok := testServer.faults.get(tenantID); ok {
+		t.Error("Fault profile kept after the tenant was deleted")
+	}
+}
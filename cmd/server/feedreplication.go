@@ -0,0 +1,418 @@
+// cmd/server/feedreplication.go
+// Replication driven by the change feed: committed object changes are
+// shipped to the peer regions from a per-tenant cursor persisted across
+// restarts, so a crash between commit and delivery replays the change
+// rather than losing it, and a tenant can be rewound to backfill
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/replication"
+)
+
+const (
+	feedReplicationBatch    = 256
+	feedReplicationInterval = time.Second
+
+	// Changes up to interactiveReplicaBytes replicate ahead of larger
+	// ones; changes older than feedBackfillAge, replayed after a rewind
+	// or an outage, replicate behind both
+	interactiveReplicaBytes = 64 << 10
+	feedBackfillAge         = time.Minute
+)
+
+// feedReplication is the replication consumer's position in the change feed
+type feedReplication struct {
+	path string
+	kick chan struct{}
+
+	mu      sync.Mutex
+	tenants map[string]*replicationCursor
+	dirty   bool
+
+	shipped atomic.Uint64
+	skipped atomic.Uint64 // Deletes and changes superseded before shipping
+	local   atomic.Uint64 // Changes whose rules and residency allow no peer region
+	failed  atomic.Uint64
+	expired atomic.Uint64 // Changes dropped by the feed before shipping
+}
+
+// replicationCursor tracks one tenant. Every change up to acked has
+// finished replicating; those after it up to dispatched are in flight or
+// done out of order.
+type replicationCursor struct {
+	acked      uint64
+	dispatched uint64
+	inflight   map[uint64]bool
+	converge   map[uint64]uint64 // Change seq -> convergence seq
+}
+
+// replicationFeedStatus is the admin API view of a tenant's cursor
+type replicationFeedStatus struct {
+	TenantID string `json:"tenant_id"`
+	Acked    uint64 `json:"acked"`
+	Latest   uint64 `json:"latest"`
+	Lag      uint64 `json:"lag"`
+	InFlight int    `json:"in_flight"`
+}
+
+// openFeedReplication loads the cursors saved at path. Tenants without one
+// start from the oldest change the feed retains.
+func openFeedReplication(path string) (*feedReplication, error) {
+	f := &feedReplication{
+		path:    path,
+		kick:    make(chan struct{}, 1),
+		tenants: make(map[string]*replicationCursor),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication cursors: %w", err)
+	}
+	var acked map[string]uint64
+	if err := json.Unmarshal(data, &acked); err != nil {
+		return nil, fmt.Errorf("failed to parse replication cursors: %w", err)
+	}
+	for tenantID, seq := range acked {
+		f.cursor(tenantID).rewind(seq)
+	}
+	return f, nil
+}
+
+// cursor returns tenantID's cursor; the caller holds f.mu
+func (f *feedReplication) cursor(tenantID string) *replicationCursor {
+	c := f.tenants[tenantID]
+	if c == nil {
+		c = &replicationCursor{inflight: make(map[uint64]bool), converge: make(map[uint64]uint64)}
+		f.tenants[tenantID] = c
+	}
+	return c
+}
+
+func (c *replicationCursor) rewind(seq uint64) {
+	c.acked, c.dispatched = seq, seq
+}
+
+// begin marks seq dispatched and in flight
+func (f *feedReplication) begin(tenantID string, seq uint64) {
+	f.mu.Lock()
+	c := f.cursor(tenantID)
+	c.inflight[seq] = true
+	c.dispatched = max(c.dispatched, seq)
+	f.mu.Unlock()
+}
+
+// unship undoes begin for a change the engine would not take
+func (f *feedReplication) unship(tenantID string, seq uint64) {
+	f.mu.Lock()
+	c := f.cursor(tenantID)
+	delete(c.inflight, seq)
+	c.dispatched = seq - 1
+	f.mu.Unlock()
+}
+
+// finish marks seq done, advances acked past every finished change and
+// returns the convergence sequence tracking it (0 if none)
+func (f *feedReplication) finish(tenantID string, seq uint64) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := f.cursor(tenantID)
+	delete(c.inflight, seq)
+	acked := c.dispatched
+	for s := range c.inflight {
+		acked = min(acked, s-1)
+	}
+	if acked != c.acked {
+		c.acked = acked
+		f.dirty = true
+	}
+	converge := c.converge[seq]
+	delete(c.converge, seq)
+	return converge
+}
+
+// skipTo moves tenantID's cursor past changes the feed dropped before they
+// shipped, returning the convergence sequences tracking them
+func (f *feedReplication) skipTo(tenantID string, seq uint64) []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := f.cursor(tenantID)
+	c.dispatched = max(c.dispatched, seq)
+	if len(c.inflight) == 0 {
+		c.acked = c.dispatched
+		f.dirty = true
+	}
+	var converge []uint64
+	for s, cs := range c.converge {
+		if s <= seq {
+			converge = append(converge, cs)
+			delete(c.converge, s)
+		}
+	}
+	return converge
+}
+
+// save writes the acked cursors if they moved
+func (f *feedReplication) save() error {
+	f.mu.Lock()
+	if !f.dirty {
+		f.mu.Unlock()
+		return nil
+	}
+	acked := make(map[string]uint64, len(f.tenants))
+	for tenantID, c := range f.tenants {
+		acked[tenantID] = c.acked
+	}
+	f.dirty = false
+	f.mu.Unlock()
+
+	data, err := json.Marshal(acked)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o750); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// trackReplication holds strict readers back until newly committed puts
+// have replicated, and wakes the consumer
+func (s *MinIOServer) trackReplication(changes []metadata.Change) {
+	f := s.feedReplication
+	for _, ch := range changes {
+		if ch.Type == metadata.ChangeDelete {
+			continue
+		}
+		seq := s.convergence.Begin(ch.Tenant)
+		f.mu.Lock()
+		f.cursor(ch.Tenant).converge[ch.Seq] = seq
+		f.mu.Unlock()
+	}
+	select {
+	case f.kick <- struct{}{}:
+	default:
+	}
+}
+
+// runFeedReplication ships changes as they are committed, and retries
+// ones the engine had no room for
+func (s *MinIOServer) runFeedReplication() {
+	ticker := time.NewTicker(feedReplicationInterval)
+	defer ticker.Stop()
+
+	for {
+		s.replicateFeed()
+		if err := s.feedReplication.save(); err != nil {
+			log.Printf("Replication cursor save error: %v", err)
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.feedReplication.kick:
+		case <-ticker.C:
+		}
+	}
+}
+
+// replicateFeed dispatches every tenant's changes after its cursor, stopping
+// when the engine's queue is full
+func (s *MinIOServer) replicateFeed() {
+	f := s.feedReplication
+	for tenantID, latest := range s.changeFeed.Latest() {
+		for {
+			f.mu.Lock()
+			c := f.cursor(tenantID)
+			dispatched := c.dispatched
+			f.mu.Unlock()
+			if dispatched >= latest {
+				break
+			}
+
+			changes, oldest, err := s.changeFeed.Since(tenantID, dispatched, feedReplicationBatch)
+			if errors.Is(err, metadata.ErrChangesExpired) {
+				log.Printf("Replication of %s skipped changes %d to %d: no longer retained", tenantID, dispatched+1, oldest-1)
+				f.expired.Add(oldest - 1 - dispatched)
+				for _, seq := range f.skipTo(tenantID, oldest-1) {
+					s.convergence.Done(tenantID, seq, false)
+				}
+				continue
+			}
+			if len(changes) == 0 {
+				break
+			}
+			for _, ch := range changes {
+				if !s.shipChange(ch) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// shipChange hands a change to the replication engine, for the regions
+// its object may be held in. Deletes are not replicated, and a put whose
+// version has since been replaced or removed is left to the later change. It reports false if the engine's queue is
+// full and the change must be shipped again.
+func (s *MinIOServer) shipChange(ch metadata.Change) bool {
+	f := s.feedReplication
+	f.begin(ch.Tenant, ch.Seq)
+
+	meta, err := s.index.Get(ch.Tenant, ch.Key)
+	if ch.Type == metadata.ChangeDelete || err != nil || meta.VersionID != ch.VersionID {
+		f.skipped.Add(1)
+		s.feedReplicated(ch, true)
+		return true
+	}
+	regions := s.replicaRegions(s.ctx, meta)
+	if len(regions) == 0 {
+		f.local.Add(1)
+		s.feedReplicated(ch, true)
+		return true
+	}
+
+	src, err := s.replicaSource(s.ctx, meta)
+	if err != nil {
+		log.Printf("Replication of %s/%s (change %d) failed: %v", ch.Tenant, ch.Key, ch.Seq, err)
+		f.failed.Add(1)
+		s.feedReplicated(ch, false)
+		return true
+	}
+
+	var onRegion func(region string, ok bool)
+	if l := s.replicaLedger; l != nil {
+		l.shipping(meta.Tenant, meta.Key, meta.VersionID)
+		onRegion = func(region string, ok bool) { l.acked(meta.Tenant, meta.Key, meta.VersionID, region, ok) }
+	}
+	size := src.size()
+	priority := changePriority(ch, size)
+	err = s.enqueueReplica(priority, "default", meta.Key, meta.VersionID, "", src, regions, onRegion, func(replicated int) {
+		s.recordReplication(meta.Tenant, int64(replicated)*size)
+		if replicated > 0 {
+			f.shipped.Add(1)
+		} else {
+			f.failed.Add(1)
+		}
+		if s.replicaLedger != nil {
+			s.replicaLedger.done(meta.Tenant, meta.Key)
+		}
+		s.feedReplicated(ch, replicated > 0)
+	})
+	if err != nil {
+		src.close()
+		if s.replicaLedger != nil {
+			s.replicaLedger.done(meta.Tenant, meta.Key)
+		}
+		f.unship(ch.Tenant, ch.Seq)
+		return false
+	}
+	return true
+}
+
+// changePriority ranks a change in the replication queue
+func changePriority(ch metadata.Change, size int64) int {
+	switch {
+	case time.Since(ch.Time) > feedBackfillAge:
+		return replication.PriorityBulk
+	case size <= interactiveReplicaBytes:
+		return replication.PriorityInteractive
+	}
+	return replication.PriorityNormal
+}
+
+// feedReplicated finishes a change and releases strict readers waiting on it
+func (s *MinIOServer) feedReplicated(ch metadata.Change, ok bool) {
+	if seq := s.feedReplication.finish(ch.Tenant, ch.Seq); seq != 0 {
+		s.convergence.Done(ch.Tenant, seq, ok)
+	}
+}
+
+// replicationFeedStatuses reports every tenant's cursor, or just tenantID's
+func (s *MinIOServer) replicationFeedStatuses(tenantID string) []replicationFeedStatus {
+	latest := s.changeFeed.Latest()
+	f := s.feedReplication
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	statuses := []replicationFeedStatus{}
+	for id, last := range latest {
+		if tenantID != "" && id != tenantID {
+			continue
+		}
+		c := f.cursor(id)
+		statuses = append(statuses, replicationFeedStatus{
+			TenantID: id,
+			Acked:    c.acked,
+			Latest:   last,
+			Lag:      last - min(c.acked, last),
+			InFlight: len(c.inflight),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].TenantID < statuses[j].TenantID })
+	return statuses
+}
+
+// handleAdminReplicationFeed reports replication cursors (GET), or rewinds
+// ?tenant_id= to re-ship its changes after ?since= (POST)
+func (s *MinIOServer) handleAdminReplicationFeed(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.URL.Query().Get("tenant_id")
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.replicationFeedStatuses(tenantID))
+
+	case http.MethodPost:
+		since, err := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+		if tenantID == "" || err != nil {
+			writeErrorMessage(w, r, "tenant_id and since are required", http.StatusBadRequest)
+			return
+		}
+		if oldest := s.changeFeed.Oldest(tenantID); since+1 < oldest {
+			writeError(w, r, &httpError{http.StatusGone, codeChangesExpired,
+				fmt.Sprintf("Changes after %d are no longer retained; the oldest is %d", since, oldest)})
+			return
+		}
+		f := s.feedReplication
+		f.mu.Lock()
+		f.cursor(tenantID).rewind(since)
+		f.dirty = true
+		f.mu.Unlock()
+		select {
+		case f.kick <- struct{}{}:
+		default:
+		}
+		writeJSON(w, http.StatusOK, s.replicationFeedStatuses(tenantID))
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// feedReplicationLag totals the changes not yet replicated across tenants
+func (s *MinIOServer) feedReplicationLag() uint64 {
+	var lag uint64
+	for _, st := range s.replicationFeedStatuses("") {
+		lag += st.Lag
+	}
+	return lag
+}
@@ -0,0 +1,333 @@
+// cmd/server/fsck.go
+// Admin-triggered consistency checker cross-verifying the journal, key index,
+// cache tiers and tenant usage counters, with optional repair
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/cache"
+	"github.com/minio/enterprise/internal/encryption"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/multipart"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// Discrepancy kinds found outside the metadata package
+const (
+	DiscrepancyMissingData   = "missing_data"   // Indexed but absent from every cache tier
+	DiscrepancySizeMismatch  = "size_mismatch"  // Index and cache disagree on size
+	DiscrepancyOrphanData    = "orphan_data"    // Cached but not indexed for any tenant
+	DiscrepancyUsageMismatch = "usage_mismatch" // Tenant counter differs from indexed bytes
+)
+
+// fsckJob tracks one consistency check run
+type fsckJob struct {
+	ID         string    `json:"id"`
+	Repair     bool      `json:"repair"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+
+	phase    atomic.Value // string
+	checked  atomic.Int64
+	repaired atomic.Int64
+
+	mu            sync.Mutex
+	discrepancies []metadata.Discrepancy
+	err           string
+
+	done chan struct{}
+}
+
+// fsckProgress is a point-in-time view of a job, streamed to admin clients
+type fsckProgress struct {
+	ID            string                 `json:"id"`
+	Repair        bool                   `json:"repair"`
+	Phase         string                 `json:"phase"`
+	Checked       int64                  `json:"checked"`
+	Repaired      int64                  `json:"repaired"`
+	Found         int                    `json:"found"`
+	Done          bool                   `json:"done"`
+	Error         string                 `json:"error,omitempty"`
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    *time.Time             `json:"finished_at,omitempty"`
+	Discrepancies []metadata.Discrepancy `json:"discrepancies,omitempty"`
+}
+
+func (j *fsckJob) report(kind, tenantID, key, detail string) {
+	j.mu.Lock()
+	j.discrepancies = append(j.discrepancies, metadata.Discrepancy{Kind: kind, Tenant: tenantID, Key: key, Detail: detail})
+	j.mu.Unlock()
+}
+
+func (j *fsckJob) progress(withDetail bool) fsckProgress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	p := fsckProgress{
+		ID:        j.ID,
+		Repair:    j.Repair,
+		Phase:     j.phase.Load().(string),
+		Checked:   j.checked.Load(),
+		Repaired:  j.repaired.Load(),
+		Found:     len(j.discrepancies),
+		Error:     j.err,
+		StartedAt: j.StartedAt,
+	}
+	select {
+	case <-j.done:
+		p.Done = true
+		finished := j.FinishedAt
+		p.FinishedAt = &finished
+		if withDetail {
+			p.Discrepancies = append([]metadata.Discrepancy(nil), j.discrepancies...)
+		}
+	default:
+	}
+	return p
+}
+
+// startFsck launches a check unless one is already running
+func (s *MinIOServer) startFsck(repair bool) (*fsckJob, error) {
+	s.fsckMu.Lock()
+	defer s.fsckMu.Unlock()
+
+	if s.fsck != nil {
+		select {
+		case <-s.fsck.done:
+		default:
+			return nil, fmt.Errorf("consistency check %s already running", s.fsck.ID)
+		}
+	}
+
+	job := &fsckJob{
+		ID:        fmt.Sprintf("fsck-%d", time.Now().UnixNano()),
+		Repair:    repair,
+		StartedAt: time.Now(),
+		done:      make(chan struct{}),
+	}
+	job.phase.Store("starting")
+	s.fsck = job
+
+	go s.runFsck(s.ctx, job)
+	return job, nil
+}
+
+func (s *MinIOServer) runFsck(ctx context.Context, job *fsckJob) {
+	defer func() {
+		job.mu.Lock()
+		job.FinishedAt = time.Now()
+		job.mu.Unlock()
+		job.phase.Store("done")
+		close(job.done)
+	}()
+
+	phases := []struct {
+		name string
+		run  func(context.Context, *fsckJob) error
+	}{
+		{"journal", s.fsckJournal},
+		{"cache", s.fsckCache},
+		{"usage", s.fsckUsage},
+	}
+
+	for _, phase := range phases {
+		if ctx.Err() != nil {
+			return
+		}
+		job.phase.Store(phase.name)
+		if err := phase.run(ctx, job); err != nil {
+			job.mu.Lock()
+			job.err = fmt.Sprintf("%s: %v", phase.name, err)
+			job.mu.Unlock()
+			return
+		}
+	}
+}
+
+// fsckJournal compares the index with a journal replay. The live index is
+// authoritative, so repair re-checkpoints the journal from it.
+func (s *MinIOServer) fsckJournal(ctx context.Context, job *fsckJob) error {
+	report, err := metadata.CheckIndex(s.intentLog, s.index)
+	if err != nil {
+		return err
+	}
+	job.checked.Add(int64(report.Checked))
+	for _, d := range report.Discrepancies {
+		job.report(d.Kind, d.Tenant, d.Key, d.Detail)
+	}
+
+	if job.Repair && len(report.Discrepancies) > 0 {
+		if err := s.intentLog.Compact(s.index); err != nil {
+			return err
+		}
+		job.repaired.Add(int64(len(report.Discrepancies)))
+	}
+	return nil
+}
+
+// fsckCache verifies every indexed object is cached with a matching size
+// and that nothing is cached without an index entry
+func (s *MinIOServer) fsckCache(ctx context.Context, job *fsckJob) error {
+	indexed := make(map[string]bool)
+
+	for _, meta := range s.index.Snapshot() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		job.checked.Add(1)
+		if cached(&meta) {
+			indexed[cache.TenantKey(meta.Tenant, meta.Key)] = true
+		}
+
+		size, _, ok := s.cacheManager.Tenant(meta.Tenant).Stat(meta.Key)
+		if meta.Inline != nil {
+			size, ok = int64(len(meta.Inline)), true
+		}
+		if meta.Manifest != "" {
+			var m *multipart.Manifest
+			if m, ok = s.parts.Manifest(meta.Manifest); ok {
+				size = m.Size
+			}
+		}
+		if ok && meta.Encrypted {
+			size -= encryption.Overhead
+		}
+		switch {
+		case !ok:
+			job.report(DiscrepancyMissingData, meta.Tenant, meta.Key, "")
+			if job.Repair {
+				// Data is gone; drop the entry and release its quota
+				s.index.Delete(meta.Tenant, meta.Key)
+				s.tenantManager.UpdateQuota(ctx, meta.Tenant, -meta.Size, 0, tenant.Transfer{})
+				job.repaired.Add(1)
+			}
+		case size != meta.Size:
+			job.report(DiscrepancySizeMismatch, meta.Tenant, meta.Key,
+				fmt.Sprintf("index=%d cache=%d", meta.Size, size))
+			if job.Repair {
+				meta.Size = size
+				s.index.Put(meta)
+				job.repaired.Add(1)
+			}
+		}
+	}
+
+	var orphans []string
+	s.cacheManager.Range(func(key string, size int64, tier uint8) bool {
+		if cacheOnly(key) {
+			return true
+		}
+		job.checked.Add(1)
+		if !indexed[key] {
+			orphans = append(orphans, key)
+			job.report(DiscrepancyOrphanData, "", key, fmt.Sprintf("size=%d tier=L%d", size, tier+1))
+		}
+		return ctx.Err() == nil
+	})
+
+	if job.Repair {
+		for _, key := range orphans {
+			s.cacheManager.Delete(ctx, key)
+			job.repaired.Add(1)
+		}
+	}
+	return ctx.Err()
+}
+
+// cacheOnly reports whether a cache key holds data that is not an object:
+// RESP entries and image variants in a tenant's namespace, and the
+// server's own entries outside every namespace
+func cacheOnly(cacheKey string) bool {
+	_, key, ok := cache.SplitTenantKey(cacheKey)
+	return !ok || strings.HasPrefix(cacheKey, "\x00") || strings.HasPrefix(key, "\x00")
+}
+
+// fsckUsage recomputes each tenant's storage usage from the index
+func (s *MinIOServer) fsckUsage(ctx context.Context, job *fsckJob) error {
+	for _, tenantID := range s.tenantManager.ListTenants(ctx) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		job.checked.Add(1)
+
+		usage, err := s.tenantManager.GetUsage(ctx, tenantID)
+		if err != nil {
+			continue
+		}
+		_, indexedBytes := s.index.Usage(tenantID)
+		if used := usage.StorageUsed.Load(); used != indexedBytes {
+			job.report(DiscrepancyUsageMismatch, tenantID, "",
+				fmt.Sprintf("counter=%d indexed=%d", used, indexedBytes))
+			if job.Repair {
+				s.tenantManager.ReconcileStorageUsage(ctx, tenantID, indexedBytes)
+				job.repaired.Add(1)
+			}
+		}
+	}
+	return nil
+}
+
+// handleAdminFsck starts a check (POST ?repair=true) or reports on the
+// current one (GET). GET ?stream=true streams NDJSON progress until done.
+func (s *MinIOServer) handleAdminFsck(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		job, err := s.startFsck(r.URL.Query().Get("repair") == "true")
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, job.progress(false))
+
+	case http.MethodGet:
+		s.fsckMu.Lock()
+		job := s.fsck
+		s.fsckMu.Unlock()
+		if job == nil {
+			writeErrorMessage(w, r, "No consistency check has run", http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("stream") != "true" {
+			writeJSON(w, http.StatusOK, job.progress(true))
+			return
+		}
+		s.streamFsck(w, r, job)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *MinIOServer) streamFsck(w http.ResponseWriter, r *http.Request, job *fsckJob) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-job.done:
+			enc.Encode(job.progress(true))
+			return
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			enc.Encode(job.progress(false))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
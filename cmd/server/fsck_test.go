@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// A repairing check streams its progress and resets a drifted usage counter
+// to the bytes indexed
+func TestAdminFsckRepairsUsage(t *testing.T) {
+	ctx := context.Background()
+	tenantID := newTenant(t)
+	upload(t, tenantID, "a.txt", "hello")
+	if _, err := testServer.tenantManager.ReconcileStorageUsage(ctx, tenantID, 999); err != nil {
+		t.Fatal(err)
+	}
+
+	w := do(t, "POST", "/v1/admin/fsck?repair=true", nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "POST", "/v1/admin/fsck?repair=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusAccepted)
+	var started fsckProgress
+	decode(t, w, &started)
+	if !started.Repair || started.ID == "" {
+		t.Fatalf("Started %+v, want a repairing job", started)
+	}
+
+	// The stream ends with the finished job and its findings
+	w = do(t, "GET", "/v1/admin/fsck?stream=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type %q, want NDJSON", ct)
+	}
+	var last fsckProgress
+	for sc := bufio.NewScanner(w.Body); sc.Scan(); {
+		if err := json.Unmarshal(sc.Bytes(), &last); err != nil {
+			t.Fatalf("Decoding %q: %v", sc.Text(), err)
+		}
+	}
+	if last.ID != started.ID || !last.Done || last.Error != "" {
+		t.Fatalf("Stream ended with %+v, want job %s done", last, started.ID)
+	}
+	found := false
+	for _, d := range last.Discrepancies {
+		found = found || d.Kind == DiscrepancyUsageMismatch && d.Tenant == tenantID
+	}
+	if !found || last.Repaired == 0 {
+		t.Errorf("Found %+v, repaired %d; want the tenant's usage mismatch repaired", last.Discrepancies, last.Repaired)
+	}
+	usage, err := testServer.tenantManager.GetUsage(ctx, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := usage.StorageUsed.Load(); used != int64(len("hello")) {
+		t.Errorf("Storage used %d after repair, want %d", used, len("hello"))
+	}
+
+	w = do(t, "GET", "/v1/admin/fsck", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var report fsckProgress
+	if decode(t, w, &report); report.ID != started.ID || !report.Done {
+		t.Errorf("Report %+v, want the finished job", report)
+	}
+	w = do(t, "DELETE", "/v1/admin/fsck", nil, adminAuth)
+	expectStatus(t, w, http.StatusMethodNotAllowed)
+}
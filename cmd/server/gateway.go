@@ -0,0 +1,261 @@
+// cmd/server/gateway.go
+// Bring-your-own-bucket gateway mode: a tenant's objects live in a bucket
+// the tenant owns, and this node serves them through its cache tiers and
+// replication. Reads that miss locally are fetched from the bucket and
+// cached; writes reach the bucket before they commit (write-through) or are
+// uploaded in the background after (write-back).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/encryption"
+	"github.com/minio/enterprise/internal/gateway"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// gatewayFor returns the tenant's gateway, if configured
+func (s *MinIOServer) gatewayFor(tenantID string) (*gateway.Gateway, bool) {
+	s.gatewaysMu.RLock()
+	defer s.gatewaysMu.RUnlock()
+	g, ok := s.gateways[tenantID]
+	return g, ok
+}
+
+// setGateway installs (or replaces) a tenant's gateway and starts its
+// write-back worker. Writes still queued on a replaced gateway are flushed.
+func (s *MinIOServer) setGateway(tenantID string, g *gateway.Gateway) {
+	s.gatewaysMu.Lock()
+	old, ok := s.gateways[tenantID]
+	s.gateways[tenantID] = g
+	s.gatewaysMu.Unlock()
+
+	if ok {
+		s.retireGateway(tenantID, old)
+	}
+	g.Start(s.ctx)
+}
+
+// removeGateway stops and forgets a tenant's gateway
+func (s *MinIOServer) removeGateway(tenantID string) bool {
+	s.gatewaysMu.Lock()
+	g, ok := s.gateways[tenantID]
+	delete(s.gateways, tenantID)
+	s.gatewaysMu.Unlock()
+
+	if ok {
+		s.retireGateway(tenantID, g)
+	}
+	return ok
+}
+
+// retireGateway stops g after a last attempt to upload its queued writes
+func (s *MinIOServer) retireGateway(tenantID string, g *gateway.Gateway) {
+	g.Stop()
+	if err := g.Flush(s.ctx); err != nil {
+		log.Printf("Gateway for tenant %s retired with %d unflushed writes: %v", tenantID, g.Status().Pending, err)
+	}
+}
+
+// gatewayRecache fetches an indexed object whose bytes were evicted from the
+// cache tiers and caches it again, sealed if the object is
+func (s *MinIOServer) gatewayRecache(ctx context.Context, g *gateway.Gateway, meta *metadata.ObjectMeta) ([]byte, error) {
+	data, err := g.Get(ctx, meta.Key)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+
+	stored := data
+	if meta.Encrypted {
+		dataKey, err := s.dataKey(ctx, meta.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		if stored, err = encryption.Seal(dataKey, data, objectAAD(meta.Tenant, meta.Key)); err != nil {
+			return data, nil
+		}
+	}
+	if err := s.cacheManager.Tenant(meta.Tenant).Set(s.placementContext(ctx, meta.Tenant, meta.Key, len(stored)), meta.Key, stored); err == nil {
+		s.gatewayFills.Add(1)
+	}
+	return data, nil
+}
+
+// gatewayFill fetches a key missing from the index from the tenant's bucket
+// and stores it through the normal write path, so it is cached, counted
+// against quota and replicated like any other object. A fill that cannot be
+// stored locally still serves the data.
+func (s *MinIOServer) gatewayFill(ctx context.Context, tenantID, key string) ([]byte, *metadata.ObjectMeta, error) {
+	g, ok := s.gatewayFor(tenantID)
+	if !ok {
+		return nil, nil, errObjectNotFound
+	}
+
+	unlock := s.writeLocks.Lock(tenantID, key)
+	if _, err := s.index.Get(tenantID, key); err == nil {
+		// Filled or written while we waited for the lock
+		unlock()
+		return s.getObject(ctx, tenantID, key)
+	}
+	defer unlock()
+
+	data, err := g.Get(ctx, key)
+	if err != nil {
+		return nil, nil, gatewayError(err)
+	}
+
+	meta := metadata.ObjectMeta{
+		Tenant:    tenantID,
+		Key:       key,
+		Size:      int64(len(data)),
+		VersionID: newVersionID(),
+		ModTime:   time.Now().UnixNano(),
+		ETag:      objectETag(data),
+	}
+	if err := s.storeObject(ctx, meta, nil, data, nil); err != nil {
+		log.Printf("Gateway fill of %s/%s not cached: %v", tenantID, key, err)
+		s.gatewayFillErrs.Add(1)
+		return data, &meta, nil
+	}
+	s.gatewayFills.Add(1)
+	return data, &meta, nil
+}
+
+// deleteThrough removes key from the tenant's bucket, if it has one, and
+// then locally. In gateway mode a key held only by the bucket is not an error.
+func (s *MinIOServer) deleteThrough(ctx context.Context, tenantID, key string) error {
+	g, ok := s.gatewayFor(tenantID)
+	if !ok {
+		_, err := s.deleteObject(ctx, tenantID, key)
+		return err
+	}
+
+	if err := g.Delete(ctx, key); err != nil {
+		return errGatewayFailed
+	}
+	if _, err := s.deleteObject(ctx, tenantID, key); err != nil && err != errObjectNotFound {
+		return err
+	}
+	return nil
+}
+
+// gatewayError maps backend failures to HTTP errors
+func gatewayError(err error) error {
+	switch {
+	case errors.Is(err, gateway.ErrNotFound):
+		return errObjectNotFound
+	case errors.Is(err, gateway.ErrPreconditionFailed):
+		return errVersionMismatch
+	default:
+		return errGatewayFailed
+	}
+}
+
+// listGateway serves /list?source=gateway from the tenant's bucket rather
+// than the local index. Its continuation tokens carry the provider's own
+// page token, so such listings resume only from a continuation_token.
+func (s *MinIOServer) listGateway(w http.ResponseWriter, r *http.Request, tenantID, prefix, token string, maxKeys int) {
+	g, ok := s.gatewayFor(tenantID)
+	if !ok {
+		writeErrorMessage(w, r, "No gateway configured for tenant", http.StatusNotFound)
+		return
+	}
+
+	page, err := g.List(r.Context(), prefix, token, maxKeys)
+	if err != nil {
+		writeError(w, r, errGatewayFailed)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"source":       "gateway",
+		"objects":      page.Objects,
+		"count":        len(page.Objects),
+		"is_truncated": page.Truncated,
+	}
+	if page.Truncated {
+		resp["next_continuation_token"] = encodeContinuation(page.NextToken)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAdminGateway configures a tenant's bucket (PUT), reports gateways
+// (GET, all tenants unless ?tenant_id=), flushes queued write-backs (POST)
+// or removes the gateway (DELETE). Credentials are never returned.
+func (s *MinIOServer) handleAdminGateway(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.URL.Query().Get("tenant_id")
+	if tenantID == "" && r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Missing tenant_id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if tenantID != "" {
+			g, ok := s.gatewayFor(tenantID)
+			if !ok {
+				writeErrorMessage(w, r, "No gateway configured for tenant", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, g.Status())
+			return
+		}
+		s.gatewaysMu.RLock()
+		out := make(map[string]gateway.Status, len(s.gateways))
+		for id, g := range s.gateways {
+			out[id] = g.Status()
+		}
+		s.gatewaysMu.RUnlock()
+		writeJSON(w, http.StatusOK, out)
+
+	case http.MethodPut:
+		if _, err := s.tenantManager.GetTenant(r.Context(), tenantID); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		var cfg gateway.Config
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			writeErrorMessage(w, r, "Invalid gateway config", http.StatusBadRequest)
+			return
+		}
+		g, err := gateway.New(cfg)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.setGateway(tenantID, g)
+
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "gateway.configure",
+			Details: map[string]string{"provider": cfg.Provider, "bucket": cfg.Bucket, "prefix": cfg.Prefix}})
+		writeJSON(w, http.StatusOK, g.Status())
+
+	case http.MethodPost:
+		g, ok := s.gatewayFor(tenantID)
+		if !ok {
+			writeErrorMessage(w, r, "No gateway configured for tenant", http.StatusNotFound)
+			return
+		}
+		if err := g.Flush(r.Context()); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, http.StatusOK, g.Status())
+
+	case http.MethodDelete:
+		if !s.removeGateway(tenantID) {
+			writeErrorMessage(w, r, "No gateway configured for tenant", http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "gateway.remove"})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,132 @@
+// cmd/server/gctune.go
+// Garbage collector tuning. GOGC and GOMEMLIMIT are read once when the
+// runtime starts, so the server applies its own settings through
+// runtime/debug instead, and exports GC pauses so latency tuning can be
+// measured.
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+// Runtime metrics read back; reading the settings through runtime/debug
+// would mean setting them
+const (
+	gcPercentMetric = "/gc/gogc:percent"
+	gcLimitMetric   = "/gc/gomemlimit:bytes"
+	gcPauseMetric   = "/sched/pauses/total/gc:seconds" // Histogram of stop-the-world pauses
+)
+
+// gcPauseBuckets are the upper bounds GC pauses are exported in
+var gcPauseBuckets = []float64{10e-6, 50e-6, 100e-6, 250e-6, 500e-6, 1e-3, 2.5e-3, 5e-3, 10e-3, 50e-3, 100e-3}
+
+// gcBallast is an allocation that is never touched, so the heap goal is
+// computed on top of it; nil unless MINIO_GC_BALLAST_BYTES is set
+var gcBallast []byte
+
+// defaultGCPercent leaves GOGC in charge when it is set, and otherwise
+// collects at 50% heap growth for lower pause times
+func defaultGCPercent() int64 {
+	if os.Getenv("GOGC") != "" {
+		return 0
+	}
+	return 50
+}
+
+// tuneRuntime applies config's collector settings
+func tuneRuntime(config *ServerConfig) {
+	if config.GCPercent != 0 {
+		debug.SetGCPercent(config.GCPercent)
+	}
+	if config.MemoryLimit > 0 {
+		debug.SetMemoryLimit(config.MemoryLimit)
+	}
+	if config.GCBallastBytes > 0 {
+		gcBallast = make([]byte, config.GCBallastBytes)
+	}
+
+	gcPercent, limit := gcSettings()
+	limitText := "none"
+	if limit != math.MaxInt64 {
+		limitText = fmt.Sprintf("%d MB", limit>>20)
+	}
+	fmt.Printf("GC: GOGC=%d, memory limit %s, ballast %d MB\n", gcPercent, limitText, len(gcBallast)>>20)
+}
+
+// gcSettings returns the GC percentage (negative when off) and the
+// memory limit (math.MaxInt64 when none) in effect
+func gcSettings() (int64, int64) {
+	sample := []metrics.Sample{{Name: gcPercentMetric}, {Name: gcLimitMetric}}
+	metrics.Read(sample)
+	gcPercent := int64(-1)
+	if sample[0].Value.Kind() == metrics.KindUint64 {
+		gcPercent = int64(sample[0].Value.Uint64())
+	}
+	limit := int64(math.MaxInt64)
+	if sample[1].Value.Kind() == metrics.KindUint64 {
+		limit = int64(min(sample[1].Value.Uint64(), math.MaxInt64))
+	}
+	return gcPercent, limit
+}
+
+// writeGCMetrics writes the collector's settings, cycles and pauses
+func writeGCMetrics(w io.Writer, mem *runtime.MemStats) {
+	gcPercent, limit := gcSettings()
+	fmt.Fprintf(w, "\n# HELP go_gc_percent Heap growth that triggers a collection, as a percentage (negative: off)\n")
+	fmt.Fprintf(w, "# TYPE go_gc_percent gauge\n")
+	fmt.Fprintf(w, "go_gc_percent %d\n", gcPercent)
+
+	if limit != math.MaxInt64 {
+		fmt.Fprintf(w, "\n# HELP go_memory_limit_bytes Soft memory limit the collector works to\n")
+		fmt.Fprintf(w, "# TYPE go_memory_limit_bytes gauge\n")
+		fmt.Fprintf(w, "go_memory_limit_bytes %d\n", limit)
+	}
+
+	fmt.Fprintf(w, "\n# HELP go_gc_cycles_total Completed GC cycles\n")
+	fmt.Fprintf(w, "# TYPE go_gc_cycles_total counter\n")
+	fmt.Fprintf(w, "go_gc_cycles_total %d\n", mem.NumGC)
+
+	fmt.Fprintf(w, "\n# HELP go_gc_cpu_fraction Share of CPU time used by the collector since start\n")
+	fmt.Fprintf(w, "# TYPE go_gc_cpu_fraction gauge\n")
+	fmt.Fprintf(w, "go_gc_cpu_fraction %g\n", mem.GCCPUFraction)
+
+	if mem.NumGC > 0 {
+		last := mem.PauseNs[(mem.NumGC+255)%256]
+		fmt.Fprintf(w, "\n# HELP go_gc_last_pause_seconds Stop-the-world time of the latest GC cycle\n")
+		fmt.Fprintf(w, "# TYPE go_gc_last_pause_seconds gauge\n")
+		fmt.Fprintf(w, "go_gc_last_pause_seconds %g\n", time.Duration(last).Seconds())
+	}
+
+	sample := []metrics.Sample{{Name: gcPauseMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return
+	}
+	hist := sample[0].Value.Float64Histogram()
+	counts := make([]uint64, len(gcPauseBuckets))
+	var total uint64
+	for i, n := range hist.Counts {
+		total += n
+		// A runtime bucket counts toward every bound its upper edge is under
+		for j, bound := range gcPauseBuckets {
+			if hist.Buckets[i+1] <= bound {
+				counts[j] += n
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n# HELP go_gc_pause_seconds Stop-the-world GC pauses\n")
+	fmt.Fprintf(w, "# TYPE go_gc_pause_seconds histogram\n")
+	for j, bound := range gcPauseBuckets {
+		fmt.Fprintf(w, "go_gc_pause_seconds_bucket{le=\"%g\"} %d\n", bound, counts[j])
+	}
+	fmt.Fprintf(w, "go_gc_pause_seconds_bucket{le=\"+Inf\"} %d\n", total)
+	fmt.Fprintf(w, "go_gc_pause_seconds_sum %g\n", time.Duration(mem.PauseTotalNs).Seconds())
+	fmt.Fprintf(w, "go_gc_pause_seconds_count %d\n", total)
+}
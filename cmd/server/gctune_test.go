@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Collector settings applied at start are what the metrics report, and
+// the pause histogram is cumulative
+func TestGCTuning(t *testing.T) {
+	gcPercent := debug.SetGCPercent(100)
+	limit := debug.SetMemoryLimit(-1)
+	defer func() {
+		debug.SetGCPercent(gcPercent)
+		debug.SetMemoryLimit(limit)
+	}()
+	tuneRuntime(&ServerConfig{GCPercent: 75, MemoryLimit: 1 << 40})
+
+	series := make(map[string]float64)
+	var buckets []float64
+	for sc := bufio.NewScanner(strings.NewReader(scrape(t))); sc.Scan(); {
+		name, value, ok := strings.Cut(sc.Text(), " ")
+		if !ok || !strings.HasPrefix(name, "go_gc_") && !strings.HasPrefix(name, "go_memory_") {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("%s: %v", sc.Text(), err)
+		}
+		series[name] = v
+		if strings.HasPrefix(name, "go_gc_pause_seconds_bucket") {
+			buckets = append(buckets, v)
+		}
+	}
+
+	if series["go_gc_percent"] != 75 || series["go_memory_limit_bytes"] != 1<<40 {
+		t.Errorf("GOGC %v, limit %v; want 75 and 1 TB", series["go_gc_percent"], series["go_memory_limit_bytes"])
+	}
+	if series["go_gc_cycles_total"] < 1 {
+		t.Error("No GC cycles counted")
+	}
+	if len(buckets) != len(gcPauseBuckets)+1 {
+		t.Fatalf("%d pause buckets, want %d", len(buckets), len(gcPauseBuckets)+1)
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] < buckets[i-1] {
+			t.Errorf("Pause buckets %v not cumulative", buckets)
+			break
+		}
+	}
+	if inf := buckets[len(buckets)-1]; inf != series["go_gc_pause_seconds_count"] {
+		t.Errorf("+Inf bucket %v, count %v", inf, series["go_gc_pause_seconds_count"])
+	}
+}
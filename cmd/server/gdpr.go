@@ -0,0 +1,304 @@
+// cmd/server/gdpr.go
+// GDPR tooling: data subject export and crypto-shredding erasure, each
+// leaving a persisted evidence report
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// GDPR report types
+const (
+	GDPRReportExport = "export"
+	GDPRReportShred  = "crypto_shred"
+)
+
+// gdprManifestEntry describes one exported object
+type gdprManifestEntry struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	VersionID string    `json:"version_id,omitempty"`
+	Encrypted bool      `json:"encrypted_at_rest"`
+	SHA256    string    `json:"sha256"`
+}
+
+// gdprReport is the evidence retained for every export or erasure
+type gdprReport struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Tenant     string    `json:"tenant"`
+	Prefix     string    `json:"prefix,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Objects    int       `json:"objects"`
+	Bytes      int64     `json:"bytes"`
+	Errors     []string  `json:"errors,omitempty"`
+
+	// Export
+	ManifestSHA256 string `json:"manifest_sha256,omitempty"`
+
+	// Crypto-shred
+	KeyFingerprint   string `json:"key_fingerprint,omitempty"`
+	EncryptedObjects int    `json:"encrypted_objects,omitempty"`
+	PlaintextDeleted int    `json:"plaintext_deleted,omitempty"`
+	Verified         bool   `json:"verified,omitempty"`
+}
+
+func (s *MinIOServer) gdprReportDir() string {
+	return filepath.Join(s.config.DataDir, "gdpr", "reports")
+}
+
+// saveGDPRReport persists the report and records it in the audit log
+func (s *MinIOServer) saveGDPRReport(ctx context.Context, report *gdprReport) error {
+	outcome := audit.OutcomeSuccess
+	if len(report.Errors) > 0 {
+		outcome = audit.OutcomeError
+	}
+	s.logAudit(ctx, audit.Event{TenantID: report.Tenant, Actor: "admin", Action: "gdpr." + report.Type,
+		Resource: report.ID, Outcome: outcome,
+		Details: map[string]string{"objects": fmt.Sprintf("%d", report.Objects), "bytes": fmt.Sprintf("%d", report.Bytes)}})
+
+	if err := os.MkdirAll(s.gdprReportDir(), 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.gdprReportDir(), report.ID+".json"), data, 0o640)
+}
+
+// loadGDPRReports returns persisted reports, optionally for one tenant, oldest first
+func (s *MinIOServer) loadGDPRReports(tenantID string) ([]gdprReport, error) {
+	entries, err := os.ReadDir(s.gdprReportDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []gdprReport{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]gdprReport, 0, len(entries))
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.gdprReportDir(), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var report gdprReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			continue
+		}
+		if tenantID == "" || report.Tenant == tenantID {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].StartedAt.Before(reports[j].StartedAt) })
+	return reports, nil
+}
+
+// exportObjects streams every object under prefix as a tar archive,
+// followed by manifest.json describing them
+func (s *MinIOServer) exportObjects(ctx context.Context, tw *tar.Writer, report *gdprReport) error {
+	manifest := make([]gdprManifestEntry, 0)
+
+	for _, meta := range s.index.List(report.Tenant, report.Prefix) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		data, _, err := s.getObject(ctx, report.Tenant, meta.Key)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", meta.Key, err))
+			continue
+		}
+
+		modTime := time.Unix(0, meta.ModTime).UTC()
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    "objects/" + meta.Key,
+			Mode:    0o640,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest = append(manifest, gdprManifestEntry{
+			Key:       meta.Key,
+			Size:      int64(len(data)),
+			ModTime:   modTime,
+			VersionID: meta.VersionID,
+			Encrypted: meta.Encrypted,
+			SHA256:    hex.EncodeToString(sum[:]),
+		})
+		report.Objects++
+		report.Bytes += int64(len(data))
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(manifestData)
+	report.ManifestSHA256 = hex.EncodeToString(sum[:])
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    "manifest.json",
+		Mode:    0o640,
+		Size:    int64(len(manifestData)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err = tw.Write(manifestData)
+	return err
+}
+
+// handleGDPRExport streams a data subject export: GET /admin/gdpr/export?tenant=&prefix=
+func (s *MinIOServer) handleGDPRExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	tenantID := r.URL.Query().Get("tenant")
+	if !s.tenantManager.HasCompliance(ctx, tenantID, tenant.ComplianceGDPR) {
+		writeErrorMessage(w, r, "GDPR module is not enabled for tenant", http.StatusConflict)
+		return
+	}
+
+	report := &gdprReport{
+		ID:        fmt.Sprintf("gdpr-export-%d", time.Now().UnixNano()),
+		Type:      GDPRReportExport,
+		Tenant:    tenantID,
+		Prefix:    r.URL.Query().Get("prefix"),
+		StartedAt: time.Now().UTC(),
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.ID+".tar"))
+	w.Header().Set("X-Report-ID", report.ID)
+	w.WriteHeader(http.StatusOK)
+
+	tw := tar.NewWriter(w)
+	err := s.exportObjects(ctx, tw, report)
+	if err == nil {
+		err = tw.Close()
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	if err := s.saveGDPRReport(ctx, report); err != nil {
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Action: "gdpr.report", Resource: report.ID,
+			Outcome: audit.OutcomeError, Details: map[string]string{"error": err.Error()}})
+	}
+}
+
+// handleGDPRShred erases a tenant by destroying its data key and dropping
+// every object: POST /admin/gdpr/shred?tenant=
+func (s *MinIOServer) handleGDPRShred(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	tenantID := r.URL.Query().Get("tenant")
+	if !s.tenantManager.HasCompliance(ctx, tenantID, tenant.ComplianceGDPR) {
+		writeErrorMessage(w, r, "GDPR module is not enabled for tenant", http.StatusConflict)
+		return
+	}
+
+	report := &gdprReport{
+		ID:        fmt.Sprintf("gdpr-shred-%d", time.Now().UnixNano()),
+		Type:      GDPRReportShred,
+		Tenant:    tenantID,
+		StartedAt: time.Now().UTC(),
+	}
+
+	// Destroy the key first: from here on sealed data is unrecoverable even
+	// if a later step fails or a stale copy survives in a tier or replica
+	fingerprint, err := s.tenantManager.ShredDataKey(ctx, tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report.KeyFingerprint = fingerprint
+	s.readahead.dropTenant(tenantID)
+
+	var sample string
+	for _, meta := range s.index.List(tenantID, "") {
+		if _, err := s.deleteObject(ctx, tenantID, meta.Key); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", meta.Key, err))
+			continue
+		}
+		report.Objects++
+		report.Bytes += meta.Size
+		if meta.Encrypted {
+			report.EncryptedObjects++
+			sample = meta.Key
+		} else {
+			report.PlaintextDeleted++
+		}
+	}
+
+	// Evidence: the key can no longer be obtained, and nothing remains indexed
+	_, keyErr := s.tenantManager.DataKey(ctx, tenantID)
+	remaining, _ := s.index.Usage(tenantID)
+	report.Verified = errors.Is(keyErr, tenant.ErrKeyShredded) && remaining == 0
+	if sample != "" && report.Verified {
+		_, _, err := s.getObject(ctx, tenantID, sample)
+		report.Verified = err != nil
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	if err := s.saveGDPRReport(ctx, report); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to persist report: %v", err))
+	}
+
+	status := http.StatusOK
+	if len(report.Errors) > 0 || !report.Verified {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, report)
+}
+
+// handleGDPRReports lists evidence reports: GET /admin/gdpr/reports?tenant=
+func (s *MinIOServer) handleGDPRReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reports, err := s.loadGDPRReports(r.URL.Query().Get("tenant"))
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
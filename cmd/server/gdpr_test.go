@@ -0,0 +1,155 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// enableGDPR turns on tenantID's GDPR module
+func enableGDPR(t *testing.T, tenantID string) {
+	t.Helper()
+	w := do(t, "PUT", "/v1/admin/compliance?tenant="+tenantID, complianceRequest{Modules: []string{"GDPR"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+}
+
+// gdprReports lists tenantID's evidence reports
+func gdprReports(t *testing.T, tenantID string) []gdprReport {
+	t.Helper()
+	var reports []gdprReport
+	w := do(t, "GET", "/v1/admin/gdpr/reports?tenant="+tenantID, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &reports)
+	return reports
+}
+
+// An export archives the objects under the prefix with a manifest of
+// their checksums, and leaves a report naming the manifest
+func TestGDPRExport(t *testing.T) {
+	tenantID := newTenant(t)
+	export := "/v1/admin/gdpr/export?tenant=" + tenantID + "&prefix=subject/"
+	w := do(t, "GET", export, nil, adminAuth)
+	expectStatus(t, w, http.StatusConflict)
+
+	enableGDPR(t, tenantID)
+	objects := map[string]string{"subject/a.txt": "alpha", "subject/b.txt": "bravo"}
+	for key, data := range objects {
+		upload(t, tenantID, key, data)
+	}
+	upload(t, tenantID, "other.txt", "other")
+
+	w = do(t, "GET", export, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	id := w.Header().Get("X-Report-ID")
+	got := make(map[string]string)
+	var manifestData []byte
+	tr := tar.NewReader(w.Body)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == "manifest.json" {
+			manifestData = data
+			continue
+		}
+		got[hdr.Name] = string(data)
+	}
+	want := make(map[string]string)
+	for key, data := range objects {
+		want["objects/"+key] = data
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Archive holds %v, want %v", got, want)
+	}
+
+	var manifest []gdprManifestEntry
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		t.Fatalf("Manifest %q: %v", manifestData, err)
+	}
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Key < manifest[j].Key })
+	if len(manifest) != len(objects) {
+		t.Fatalf("Manifest lists %d objects, want %d", len(manifest), len(objects))
+	}
+	for _, e := range manifest {
+		sum := sha256.Sum256([]byte(objects[e.Key]))
+		if e.SHA256 != hex.EncodeToString(sum[:]) || e.Size != int64(len(objects[e.Key])) || !e.Encrypted {
+			t.Errorf("Manifest entry %+v", e)
+		}
+	}
+
+	reports := gdprReports(t, tenantID)
+	sum := sha256.Sum256(manifestData)
+	if len(reports) != 1 || reports[0].ID != id || reports[0].Type != GDPRReportExport || reports[0].Objects != 2 ||
+		reports[0].ManifestSHA256 != hex.EncodeToString(sum[:]) || len(reports[0].Errors) != 0 {
+		t.Errorf("Reports %+v after export %s", reports, id)
+	}
+}
+
+// Crypto-shredding destroys the tenant's data key and drops every object,
+// reporting the erasure as verified once the key cannot be had
+func TestGDPRShred(t *testing.T) {
+	ctx := context.Background()
+	tenantID := newTenant(t)
+	shred := "/v1/admin/gdpr/shred?tenant=" + tenantID
+	w := do(t, "POST", shred, nil, adminAuth)
+	expectStatus(t, w, http.StatusConflict)
+
+	// Written before the module sealed uploads
+	upload(t, tenantID, "plain.txt", "plaintext")
+	enableGDPR(t, tenantID)
+	upload(t, tenantID, "sealed.txt", "sensitive")
+	meta, err := testServer.index.Get(tenantID, "sealed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := testServer.storedBytes(ctx, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.Encrypted || bytes.Contains(stored, []byte("sensitive")) {
+		t.Fatal("Upload was not sealed at rest")
+	}
+
+	w = do(t, "POST", shred, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var report gdprReport
+	decode(t, w, &report)
+	if report.Type != GDPRReportShred || report.Objects != 2 || report.EncryptedObjects != 1 || report.PlaintextDeleted != 1 ||
+		report.Bytes != int64(len("plaintext")+len("sensitive")) || report.KeyFingerprint == "" || !report.Verified {
+		t.Errorf("Shred reported %+v", report)
+	}
+
+	if _, err := testServer.dataKey(ctx, tenantID); err != errKeyShredded {
+		t.Errorf("Data key after the shred: %v, want %v", err, errKeyShredded)
+	}
+	for _, key := range []string{"plain.txt", "sealed.txt"} {
+		w = do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key="+key, nil, adminAuth)
+		expectStatus(t, w, http.StatusNotFound)
+	}
+	if reports := gdprReports(t, tenantID); len(reports) != 1 || reports[0].ID != report.ID || !reports[0].Verified {
+		t.Errorf("Reports %+v after shred %s", reports, report.ID)
+	}
+	shredded := false
+	for _, ev := range auditEvents(t, tenantID) {
+		shredded = shredded || ev.Action == "gdpr.crypto_shred" && ev.Resource == report.ID
+	}
+	if !shredded {
+		t.Error("Shred not audited")
+	}
+}
@@ -0,0 +1,166 @@
+// cmd/server/healthhistory.go
+// Health history: every readiness flip, drain, replication breaker
+// transition and alert the node raises or resolves (disk usage, clock
+// skew, self-test) is recorded in a rolling timeline, so operators can line
+// up a user's complaint with what the node was going through at the time.
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/replication"
+)
+
+// Health history subsystems recorded by the server itself; alerts are
+// recorded under their rule ID
+const (
+	healthNode      = "node"
+	healthReadiness = "readiness"
+	healthDrain     = "drain"
+	healthBreaker   = "breaker"
+)
+
+// maxHealthEvents bounds one health history response
+const maxHealthEvents = 1000
+
+// healthHistory is the admin API view of the health timeline
+type healthHistory struct {
+	Node     nodeHealth               `json:"node"`
+	Breakers map[string]string        `json:"breakers,omitempty"`
+	Total    uint64                   `json:"total"` // Events recorded, including ones no longer kept
+	Events   []monitoring.HealthEvent `json:"events"`
+}
+
+// openHealthLog opens the saved health history, falling back to one kept
+// in memory if it cannot be read
+func openHealthLog(config *ServerConfig) *monitoring.HealthLog {
+	h, err := monitoring.NewHealthLog(config.HealthHistory, filepath.Join(config.DataDir, "meta", "health.log"))
+	if err != nil {
+		log.Printf("Warning: health history will not survive a restart: %v", err)
+		h, _ = monitoring.NewHealthLog(config.HealthHistory, "")
+	}
+	return h
+}
+
+// watchHealth records the node's health transitions in its history
+func (s *MinIOServer) watchHealth() {
+	s.alertManager.OnChange(func(a monitoring.Alert) {
+		ev := monitoring.HealthEvent{
+			Time:      a.Timestamp,
+			Subsystem: a.RuleID,
+			Component: strings.TrimPrefix(strings.TrimPrefix(a.ID, a.RuleID), "_"),
+			To:        a.Status,
+			Severity:  a.Severity,
+			Message:   a.Message,
+		}
+		if a.Status == "resolved" {
+			ev.From, ev.Severity = "firing", monitoring.SeverityInfo
+		}
+		s.healthLog.Record(ev)
+	})
+	s.diskWatcher.OnReadOnlyChange(func(readOnly bool) {
+		ev := monitoring.HealthEvent{Subsystem: healthReadiness, Component: "writes", From: "read_only", To: "accepting",
+			Message: "disk usage back below the resume threshold"}
+		if readOnly {
+			ev.From, ev.To, ev.Severity = "accepting", "read_only", monitoring.SeverityCritical
+			ev.Message = "disk usage above the read-only threshold; writes are refused"
+		}
+		s.healthLog.Record(ev)
+	})
+	s.replicationEngine.OnBreakerChange(func(region, from, to string) {
+		ev := monitoring.HealthEvent{Subsystem: healthBreaker, Component: region, From: from, To: to}
+		switch to {
+		case replication.BreakerOpen:
+			ev.Severity, ev.Message = monitoring.SeverityCritical, "replication to "+region+" suspended after repeated failures"
+		case replication.BreakerHalfOpen:
+			ev.Severity, ev.Message = monitoring.SeverityWarning, "probing "+region+" after the breaker timeout"
+		default:
+			ev.Message = "replication to " + region + " resumed"
+		}
+		s.healthLog.Record(ev)
+	})
+}
+
+// recordNode records the node starting or stopping, which the events
+// around a restart are read against
+func (s *MinIOServer) recordNode(from, to, message string) {
+	s.healthLog.Record(monitoring.HealthEvent{Subsystem: healthNode, Component: s.config.NodeURL, From: from, To: to,
+		Message: message})
+}
+
+// recordDrain records a change in the node's readiness for a drain
+func (s *MinIOServer) recordDrain(draining bool, id string) {
+	ev := monitoring.HealthEvent{Subsystem: healthReadiness, Component: "drain", From: "draining", To: "ready",
+		Message: "drain " + id + " stopped; accepting new data"}
+	if draining {
+		ev.From, ev.To, ev.Severity = "ready", "draining", monitoring.SeverityWarning
+		ev.Message = "drain " + id + " started; refusing new data"
+	}
+	s.healthLog.Record(ev)
+}
+
+// recordDrainFinished records how a drain ended
+func (s *MinIOServer) recordDrainFinished(job *drainJob, state string) {
+	ev := monitoring.HealthEvent{Subsystem: healthDrain, Component: job.ID, From: DrainStateDraining, To: state,
+		Message: fmt.Sprintf("%d of %d objects migrated, %d failed", job.migrated.Load(), job.total.Load(), job.failed.Load())}
+	if state == DrainStateFailed {
+		ev.Severity = monitoring.SeverityWarning
+	}
+	s.healthLog.Record(ev)
+}
+
+// handleAdminHealthHistory reports the health timeline (GET), oldest event
+// first, with the node's current health
+func (s *MinIOServer) handleAdminHealthHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	q := monitoring.HealthQuery{
+		Subsystem:   query.Get("subsystem"),
+		Component:   query.Get("component"),
+		MinSeverity: query.Get("severity"),
+		Limit:       200,
+	}
+	switch q.MinSeverity {
+	case "", monitoring.SeverityInfo, monitoring.SeverityWarning, monitoring.SeverityCritical:
+	default:
+		writeErrorMessage(w, r, "severity must be info, warning or critical", http.StatusBadRequest)
+		return
+	}
+	var err error
+	if raw := query.Get("from"); raw != "" {
+		if q.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			writeErrorMessage(w, r, "from must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if q.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			writeErrorMessage(w, r, "to must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if q.Limit, err = strconv.Atoi(raw); err != nil || q.Limit <= 0 {
+			writeErrorMessage(w, r, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		q.Limit = min(q.Limit, maxHealthEvents)
+	}
+
+	writeJSON(w, http.StatusOK, healthHistory{
+		Node:     s.nodeHealth(),
+		Breakers: s.replicationEngine.BreakerStates(),
+		Total:    s.healthLog.Total(),
+		Events:   s.healthLog.Events(q),
+	})
+}
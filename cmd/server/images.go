@@ -0,0 +1,112 @@
+// cmd/server/images.go
+// Image transformations on download. A GET with w, h, fit, crop, format or
+// q returns a resized, cropped or transcoded variant for tenants with
+// image_transforms enabled. Variants are cached as derived objects keyed by
+// the source version, so overwriting the source retires them.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/enterprise/internal/encryption"
+	"github.com/minio/enterprise/internal/imaging"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// variantKeyPrefix separates image variants in a tenant's cache namespace
+// from its object data
+const variantKeyPrefix = "\x00img/"
+
+// newImageEncoders returns the built-in encoders plus any configured
+// external ones
+func newImageEncoders(config *ServerConfig) imaging.Encoders {
+	encoders := imaging.DefaultEncoders()
+	if config.ImageEncoderWebP != "" {
+		encoders["webp"] = imaging.CommandEncoder{Command: config.ImageEncoderWebP}
+	}
+	if config.ImageEncoderAVIF != "" {
+		encoders["avif"] = imaging.CommandEncoder{Command: config.ImageEncoderAVIF}
+	}
+	return encoders
+}
+
+// variantCacheKey identifies the variant p of one version of an object
+func variantCacheKey(key, versionID string, p imaging.Params) string {
+	sum := sha256.Sum256([]byte(p.Key()))
+	return variantKeyPrefix + key + "@" + versionID + "/" + hex.EncodeToString(sum[:12])
+}
+
+// imageVariant returns the variant of data described by query and its
+// content type, from cache when it has been produced before
+func (s *MinIOServer) imageVariant(ctx context.Context, tenantID, key string, meta *metadata.ObjectMeta, data []byte, query url.Values) ([]byte, string, error) {
+	settings, err := s.tenantManager.Settings(ctx, tenantID)
+	if err != nil || !settings.ImageTransforms {
+		return nil, "", errNoTransforms
+	}
+	p, err := imaging.ParseParams(query)
+	if err != nil {
+		return nil, "", &httpError{http.StatusBadRequest, codeInvalidRequest, err.Error()}
+	}
+	if p.Format == "" {
+		if p.Format, err = imaging.SourceFormat(data); err != nil {
+			return nil, "", errNotImage
+		}
+	}
+
+	// Variants are sealed like their source
+	var dataKey []byte
+	if s.encryptsAtRest(ctx, tenantID, settings) {
+		if dataKey, err = s.dataKey(ctx, tenantID); err != nil {
+			return nil, "", err
+		}
+	}
+	cacheKey := variantCacheKey(key, meta.VersionID, p)
+	aad := encryption.ObjectAAD(tenantID, cacheKey)
+
+	if cached, err := s.cacheManager.Tenant(tenantID).Get(ctx, cacheKey); err == nil {
+		if dataKey != nil {
+			cached, err = encryption.Open(dataKey, cached, aad)
+		}
+		if err == nil {
+			s.imageVariantHits.Add(1)
+			return cached, imaging.ContentType(p.Format), nil
+		}
+	}
+
+	_, span := tracing.StartSpan(ctx, tracing.GetTracer("http"), "image_transform",
+		attribute.String("image.params", p.Key()))
+	out, err := imaging.Transform(ctx, data, p, s.imageEncoders)
+	span.End()
+	switch {
+	case errors.Is(err, imaging.ErrNotImage):
+		return nil, "", errNotImage
+	case errors.Is(err, imaging.ErrTooLarge):
+		return nil, "", errImageTooLarge
+	case errors.Is(err, imaging.ErrInvalidParams), errors.Is(err, imaging.ErrUnsupportedFormat):
+		return nil, "", &httpError{http.StatusBadRequest, codeInvalidRequest, err.Error()}
+	case err != nil:
+		log.Printf("Image transform of %s/%s failed: %v", tenantID, key, err)
+		return nil, "", &httpError{http.StatusInternalServerError, codeInternalError, "Image transform failed"}
+	}
+	s.imageTransforms.Add(1)
+
+	stored := out
+	if dataKey != nil {
+		if stored, err = encryption.Seal(dataKey, out, aad); err != nil {
+			return out, imaging.ContentType(p.Format), nil
+		}
+	}
+	if err := s.cacheManager.Tenant(tenantID).SetWithTTL(ctx, cacheKey, stored, s.config.ImageVariantTTL); err != nil {
+		log.Printf("Failed to cache image variant of %s/%s: %v", tenantID, key, err)
+	}
+	return out, imaging.ContentType(p.Format), nil
+}
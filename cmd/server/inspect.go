@@ -0,0 +1,107 @@
+// cmd/server/inspect.go
+// Upload content inspection. Object size is capped globally and by each
+// tenant's settings, which may also restrict uploads to sniffed content
+// types and require a malware scan; infected uploads are refused and
+// quarantined (see quarantine.go).
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/scan"
+	"github.com/minio/enterprise/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// newScanner returns the configured malware scanner, nil if none
+func newScanner(config *ServerConfig) scan.Scanner {
+	switch {
+	case config.ScanICAPURL != "":
+		return &scan.ICAP{URL: config.ScanICAPURL, Timeout: config.ScanTimeout}
+	case config.ScanClamAVAddr != "":
+		return &scan.ClamAV{Addr: config.ScanClamAVAddr, Timeout: config.ScanTimeout}
+	default:
+		return nil
+	}
+}
+
+// objectSizeLimit is the largest object tenantID may write: the lower of
+// the global and tenant caps, 0 if neither is set
+func (s *MinIOServer) objectSizeLimit(ctx context.Context, tenantID string) int64 {
+	limit := s.config.MaxObjectBytes
+	if settings, err := s.tenantManager.Settings(ctx, tenantID); err == nil && settings.MaxObjectBytes > 0 {
+		if limit <= 0 || settings.MaxObjectBytes < limit {
+			limit = settings.MaxObjectBytes
+		}
+	}
+	return limit
+}
+
+func objectTooLarge(limit int64) *httpError {
+	return &httpError{http.StatusRequestEntityTooLarge, codeEntityTooLarge, fmt.Sprintf("Object exceeds the %d-byte size limit", limit)}
+}
+
+// checkUploadLength refuses an upload from its headers alone, before any
+// of the body is read: one declaring more than limit bytes, or, unless
+// chunked uploads are enabled, one declaring no length
+func (s *MinIOServer) checkUploadLength(r *http.Request, limit int64) error {
+	if r.ContentLength < 0 && !s.config.ChunkedUploads {
+		s.uploadsLengthRequired.Add(1)
+		return errLengthRequired
+	}
+	if limit > 0 && r.ContentLength > limit {
+		s.uploadsTooLarge.Add(1)
+		return objectTooLarge(limit)
+	}
+	return nil
+}
+
+// inspectUpload applies the size cap and the tenant's content rules to
+// data before it is written under key with attrs
+func (s *MinIOServer) inspectUpload(ctx context.Context, tenantID, key string, data []byte, attrs metadata.Attributes) error {
+	if limit := s.objectSizeLimit(ctx, tenantID); limit > 0 && int64(len(data)) > limit {
+		s.uploadsTooLarge.Add(1)
+		return objectTooLarge(limit)
+	}
+	settings, err := s.tenantManager.Settings(ctx, tenantID)
+	if err != nil {
+		return nil // Unknown tenants are rejected further down the path
+	}
+
+	if len(settings.AllowedContentTypes) > 0 {
+		mediaType := scan.Sniff(data)
+		if !scan.Allowed(settings.AllowedContentTypes, mediaType) {
+			s.uploadsTypeRejected.Add(1)
+			return &httpError{errContentType.Status, errContentType.Code, "Content type " + mediaType + " not allowed for tenant"}
+		}
+	}
+
+	if !settings.ScanUploads {
+		return nil
+	}
+	if s.scanner == nil {
+		return errScanUnavailable
+	}
+	_, span := tracing.StartSpan(ctx, tracing.GetTracer("http"), "malware_scan")
+	verdict, err := s.scanner.Scan(ctx, data)
+	span.End()
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		log.Printf("Malware scan of %s/%s failed: %v", tenantID, key, err)
+		return errScanUnavailable
+	}
+	s.uploadsScanned.Add(1)
+	if !verdict.Infected {
+		return nil
+	}
+
+	s.uploadsInfected.Add(1)
+	tracing.AddSpanEvent(ctx, "malware_detected", attribute.String("signature", verdict.Signature))
+	s.quarantineUpload(ctx, tenantID, key, data, attrs, verdict)
+	return errMalwareDetected
+}
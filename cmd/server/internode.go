@@ -0,0 +1,43 @@
+// cmd/server/internode.go
+// Internode listener: peers rebalancing or healing fetch byte ranges of
+// this node's tier files over the binary internode protocol, sent from
+// disk with sendfile rather than through the HTTP API
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/minio/enterprise/internal/internode"
+)
+
+// startInternode listens on InternodeAddr until the server shuts down.
+// Names are l2/<path> and l3/<path> within the tier directories, and
+// multipart/<chunk> for the parts of multipart objects.
+func (s *MinIOServer) startInternode() error {
+	srv, err := internode.NewServer([]byte(s.config.InternodeSecret))
+	if err != nil {
+		return fmt.Errorf("internode: set MINIO_INTERNODE_SECRET: %w", err)
+	}
+	srv.Handle("l2", internode.DirOpener(s.config.L2Dir))
+	srv.Handle("l3", internode.DirOpener(s.config.L3Dir))
+	srv.Handle("multipart", internode.DirOpener(s.parts.ChunkDir()))
+
+	ln, err := net.Listen("tcp", s.config.InternodeAddr)
+	if err != nil {
+		return fmt.Errorf("internode listen failed: %w", err)
+	}
+	s.internode = srv
+	go func() {
+		<-s.ctx.Done()
+		srv.Close()
+	}()
+
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			log.Printf("Internode server error: %v", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Concurrent writes to one key apply one after another: the stored object
+// is one of them whole, and the tenant is charged for it alone
+func TestConcurrentSameKeyWrites(t *testing.T) {
+	tenantID := newTenant(t)
+	var wg sync.WaitGroup
+	codes := make([]int, 16)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := httptest.NewRequest("PUT", "/v1/upload?tenant_id="+tenantID+"&key=k",
+				strings.NewReader(strings.Repeat(fmt.Sprint(i%10), 100*(i+1))))
+			if i%4 == 3 {
+				r = httptest.NewRequest("DELETE", "/v1/delete?tenant_id="+tenantID+"&key=k", nil)
+			}
+			adminAuth(r)
+			w := httptest.NewRecorder()
+			testServer.httpServer.Handler.ServeHTTP(w, r)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusOK && code != http.StatusNoContent && code != http.StatusNotFound {
+			t.Errorf("Write %d: status %d", i, code)
+		}
+	}
+
+	usage, err := testServer.tenantManager.GetUsage(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := testServer.index.Get(tenantID, "k")
+	if err != nil {
+		if used := usage.StorageUsed.Load(); used != 0 {
+			t.Errorf("Charged %d bytes with the key deleted", used)
+		}
+		return
+	}
+	w := do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=k", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	body := w.Body.String()
+	if int64(len(body)) != meta.Size || strings.Trim(body, body[:1]) != "" {
+		t.Errorf("Stored %d bytes mixing writes, indexed as %d", len(body), meta.Size)
+	}
+	if used := usage.StorageUsed.Load(); used != meta.Size {
+		t.Errorf("Charged %d bytes for a %d-byte object", used, meta.Size)
+	}
+	if m := scrape(t); !strings.Contains(m, "object_write_conflicts_total ") {
+		t.Error("Metrics lack object_write_conflicts_total")
+	}
+}
@@ -0,0 +1,173 @@
+// cmd/server/kv.go
+// Small-object fast path: a batched multi-key API for tiny values, which
+// the write path stores inline in the index
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+const (
+	// kvMaxBatchOps bounds the operations in one batch request
+	kvMaxBatchOps = 1000
+
+	// kvMaxBodyBytes bounds a batch request body
+	kvMaxBodyBytes = 8 << 20
+)
+
+// kvBatchRequest is the body of POST /kv/batch. Operations run in order:
+// gets, then puts, then deletes. Each is applied on its own; a batch is
+// not atomic across keys.
+type kvBatchRequest struct {
+	Get    []string          `json:"get,omitempty"`
+	Put    map[string][]byte `json:"put,omitempty"` // Values are base64 in JSON
+	Delete []string          `json:"delete,omitempty"`
+}
+
+// kvResult is the outcome of one batched operation
+type kvResult struct {
+	Op     string `json:"op"`
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Value  []byte `json:"value,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func kvFailure(op, key string, err error) kvResult {
+	he := asHTTPError(err)
+	return kvResult{Op: op, Key: key, Status: he.Status, Code: he.Code, Error: he.Message}
+}
+
+// kvGet, kvPut and kvDelete run one small-object operation with auditing;
+// they back both the batch API and the memcache listener
+
+func (s *MinIOServer) kvGet(ctx context.Context, tenantID, key string) ([]byte, error) {
+	data, _, err := s.getObject(ctx, tenantID, key)
+	if s.auditsTenant(ctx, tenantID) {
+		s.logAudit(ctx, kvAuditEvent(ctx, tenantID, "object.get", key, err))
+	}
+	return data, err
+}
+
+func (s *MinIOServer) kvPut(ctx context.Context, tenantID, key string, value []byte) error {
+	err := s.checkWritable()
+	if err == nil {
+		err = s.inspectUpload(ctx, tenantID, key, value, metadata.Attributes{})
+	}
+	if err == nil {
+		err = s.putObject(ctx, tenantID, key, value)
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		s.logAudit(ctx, kvAuditEvent(ctx, tenantID, "object.put", key, err))
+	}
+	return err
+}
+
+func (s *MinIOServer) kvDelete(ctx context.Context, tenantID, key string) error {
+	err := s.checkWritable()
+	if err == nil {
+		err = s.checkDeletable(ctx, tenantID, key)
+	}
+	if err == nil {
+		err = s.deleteThrough(ctx, tenantID, key)
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		s.logAudit(ctx, kvAuditEvent(ctx, tenantID, "object.delete", key, err))
+	}
+	return err
+}
+
+func kvAuditEvent(ctx context.Context, tenantID, action, key string, err error) audit.Event {
+	ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: action, Resource: key,
+		Details: map[string]string{"path": "kv"}}
+	if err != nil {
+		ev.Outcome = audit.OutcomeError
+		ev.Details["error"] = err.Error()
+	}
+	return ev
+}
+
+// handleKVBatch applies a batch of small-object gets, puts and deletes (POST)
+func (s *MinIOServer) handleKVBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req kvBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, kvMaxBodyBytes)).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid batch request", http.StatusBadRequest)
+		return
+	}
+	if n := len(req.Get) + len(req.Put) + len(req.Delete); n == 0 || n > kvMaxBatchOps {
+		writeErrorMessage(w, r, "Batch must contain between 1 and 1000 operations", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	p := s.requestPrincipal(r)
+	results := make([]kvResult, 0, len(req.Get)+len(req.Put)+len(req.Delete))
+	for _, key := range req.Get {
+		if err := s.authorize(p, tenantID, key, actionRead); err != nil {
+			results = append(results, kvFailure("get", key, err))
+			continue
+		}
+		data, err := s.kvGet(ctx, tenantID, key)
+		if err != nil {
+			results = append(results, kvFailure("get", key, err))
+			continue
+		}
+		results = append(results, kvResult{Op: "get", Key: key, Status: http.StatusOK, Value: data})
+	}
+	putKeys := make([]string, 0, len(req.Put))
+	for key := range req.Put {
+		putKeys = append(putKeys, key)
+	}
+	sort.Strings(putKeys)
+	for _, key := range putKeys {
+		value := req.Put[key]
+		if key == "" {
+			results = append(results, kvFailure("put", key, &httpError{http.StatusBadRequest, codeInvalidRequest, "Missing key"}))
+			continue
+		}
+		if err := s.authorize(p, tenantID, key, actionWrite); err != nil {
+			results = append(results, kvFailure("put", key, err))
+			continue
+		}
+		if err := s.kvPut(ctx, tenantID, key, value); err != nil {
+			results = append(results, kvFailure("put", key, err))
+			continue
+		}
+		results = append(results, kvResult{Op: "put", Key: key, Status: http.StatusOK})
+	}
+	for _, key := range req.Delete {
+		err := s.authorize(p, tenantID, key, actionDelete)
+		if err == nil {
+			err = s.kvDelete(ctx, tenantID, key)
+		}
+		if err != nil {
+			results = append(results, kvFailure("delete", key, err))
+			continue
+		}
+		results = append(results, kvResult{Op: "delete", Key: key, Status: http.StatusNoContent})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
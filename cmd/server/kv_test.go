@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// kvBatchResponse is the response to a KV batch
+type kvBatchResponse struct {
+	Results []kvResult `json:"results"`
+}
+
+// Batches apply gets, then puts, then deletes, each authorized and reported
+// on its own; small values are stored inline in the index
+func TestKVBatch(t *testing.T) {
+	tenantID := newTenant(t)
+	batch := "/v1/kv/batch?tenant_id=" + tenantID
+	large := strings.Repeat("x", testServer.config.InlineObjectBytes+1)
+
+	w := do(t, "POST", batch, map[string]interface{}{}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "GET", batch, nil, adminAuth)
+	expectStatus(t, w, http.StatusMethodNotAllowed)
+
+	w = do(t, "POST", batch, map[string]interface{}{
+		"get": []string{"a"},
+		"put": map[string][]byte{"a": []byte("one"), "big": []byte(large), "": []byte("no key")},
+	}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var resp kvBatchResponse
+	decode(t, w, &resp)
+	want := []struct {
+		op, key string
+		status  int
+	}{{"get", "a", http.StatusNotFound}, {"put", "", http.StatusBadRequest}, {"put", "a", http.StatusOK}, {"put", "big", http.StatusOK}}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("Results %+v", resp.Results)
+	}
+	for i, res := range resp.Results {
+		if res.Op != want[i].op || res.Key != want[i].key || res.Status != want[i].status {
+			t.Errorf("Result %d = %+v, want %s %q %d", i, res, want[i].op, want[i].key, want[i].status)
+		}
+	}
+	if meta, err := testServer.index.Get(tenantID, "a"); err != nil || string(meta.Inline) != "one" {
+		t.Errorf("Small value not inline: %+v, %v", meta, err)
+	}
+	if meta, err := testServer.index.Get(tenantID, "big"); err != nil || meta.Inline != nil {
+		t.Errorf("Large value inline: %v", err)
+	}
+
+	reader := bearer(tenantToken(t, tenantID, "reader", "object:read"))
+	w = do(t, "POST", batch, map[string]interface{}{
+		"get":    []string{"a", "big"},
+		"put":    map[string][]byte{"b": []byte("two")},
+		"delete": []string{"a"},
+	}, reader)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &resp)
+	if len(resp.Results) != 4 || string(resp.Results[0].Value) != "one" || string(resp.Results[1].Value) != large ||
+		resp.Results[2].Status != http.StatusForbidden || resp.Results[3].Status != http.StatusForbidden {
+		t.Errorf("Reader's results %+v, want both gets and neither write", resp.Results)
+	}
+
+	w = do(t, "POST", batch, map[string]interface{}{"delete": []string{"a", "a"}}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	decode(t, w, &resp)
+	if len(resp.Results) != 2 || resp.Results[0].Status != http.StatusNoContent || resp.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Delete results %+v, want deleted then not found", resp.Results)
+	}
+}
+
+// The memcache listener serves the KV tenant's objects under its prefix
+func TestMemcache(t *testing.T) {
+	tenantID := newTenant(t)
+	kvTenant, kvPrefix := testServer.config.KVTenant, testServer.config.KVPrefix
+	testServer.config.KVTenant, testServer.config.KVPrefix = tenantID, "mc/"
+	defer func() { testServer.config.KVTenant, testServer.config.KVPrefix = kvTenant, kvPrefix }()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go testServer.serveMemcache(server)
+	r := bufio.NewReader(client)
+	exchange := func(cmd string, lines int) string {
+		t.Helper()
+		if _, err := io.WriteString(client, cmd); err != nil {
+			t.Fatal(err)
+		}
+		var reply strings.Builder
+		for i := 0; i < lines; i++ {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("%q: %v", cmd, err)
+			}
+			reply.WriteString(line)
+		}
+		return reply.String()
+	}
+
+	for _, c := range []struct {
+		cmd   string
+		lines int
+		want  string
+	}{
+		{"set k 0 0 5\r\nhello\r\n", 1, "STORED\r\n"},
+		{"add k 0 0 3\r\nbye\r\n", 1, "NOT_STORED\r\n"},
+		{"replace nope 0 0 3\r\nbye\r\n", 1, "NOT_STORED\r\n"},
+		{"get k nope\r\n", 3, "VALUE k 0 5\r\nhello\r\nEND\r\n"},
+		{"delete k\r\n", 1, "DELETED\r\n"},
+		{"delete k\r\n", 1, "NOT_FOUND\r\n"},
+		{"version\r\n", 1, "VERSION " + memcacheVersion + "\r\n"},
+		{"bogus\r\n", 1, "ERROR\r\n"},
+	} {
+		if got := exchange(c.cmd, c.lines); got != c.want {
+			t.Errorf("%q replied %q, want %q", c.cmd, got, c.want)
+		}
+	}
+
+	exchange("set k 0 0 2\r\nhi\r\n", 1)
+	if meta, err := testServer.index.Get(tenantID, "mc/k"); err != nil || string(meta.Inline) != "hi" {
+		t.Errorf("Memcache set stored %+v, %v; want mc/k inline", meta, err)
+	}
+}
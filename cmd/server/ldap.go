@@ -0,0 +1,288 @@
+// cmd/server/ldap.go
+// Per-tenant LDAP/Active Directory IAM: backend configuration, scheduled
+// sync, and password login for console and CLI users
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/identity"
+)
+
+// ldapSubject qualifies a directory DN with its tenant so identical DNs in
+// different tenants' directories never share sessions
+func ldapSubject(tenantID, dn string) string {
+	return "ldap:" + tenantID + ":" + dn
+}
+
+// directory returns the tenant's LDAP backend, if configured
+func (s *MinIOServer) directory(tenantID string) (*identity.Directory, bool) {
+	s.directoriesMu.RLock()
+	defer s.directoriesMu.RUnlock()
+	d, ok := s.directories[tenantID]
+	return d, ok
+}
+
+// setDirectory installs (or replaces) a tenant's backend and starts syncing.
+// Users removed from the directory lose their console sessions.
+func (s *MinIOServer) setDirectory(tenantID string, d *identity.Directory) {
+	s.directoriesMu.Lock()
+	if old, ok := s.directories[tenantID]; ok {
+		old.Stop()
+	}
+	s.directories[tenantID] = d
+	s.directoriesMu.Unlock()
+
+	d.Start(s.ctx, func(result *identity.SyncResult, err error) {
+		if err != nil {
+			s.logAudit(s.ctx, audit.Event{TenantID: tenantID, Actor: "system", Action: "iam.ldap_sync", Outcome: audit.OutcomeError,
+				Details: map[string]string{"error": err.Error()}})
+			return
+		}
+		for _, dn := range result.Removed {
+			s.sessions.RevokeSubject(ldapSubject(tenantID, dn))
+		}
+		if len(result.Added) > 0 || len(result.Removed) > 0 {
+			s.logAudit(s.ctx, audit.Event{TenantID: tenantID, Actor: "system", Action: "iam.ldap_sync",
+				Details: map[string]string{
+					"users":   strconv.Itoa(result.Users),
+					"added":   strconv.Itoa(len(result.Added)),
+					"removed": strconv.Itoa(len(result.Removed)),
+				}})
+		}
+	})
+}
+
+// removeDirectory stops and forgets a tenant's backend
+func (s *MinIOServer) removeDirectory(tenantID string) bool {
+	s.directoriesMu.Lock()
+	defer s.directoriesMu.Unlock()
+
+	d, ok := s.directories[tenantID]
+	if ok {
+		d.Stop()
+		delete(s.directories, tenantID)
+	}
+	return ok
+}
+
+// ldapConfigRequest is the body of PUT /admin/iam/ldap
+type ldapConfigRequest struct {
+	identity.DirectoryConfig
+	SyncInterval string `json:"sync_interval"` // Go duration, default 15m
+}
+
+// ldapBackendStatus is the admin API view of a tenant's backend
+type ldapBackendStatus struct {
+	TenantID string                   `json:"tenant_id"`
+	Config   identity.DirectoryConfig `json:"config"`
+	Status   identity.DirectoryStatus `json:"status"`
+}
+
+func backendStatus(tenantID string, d *identity.Directory) ldapBackendStatus {
+	return ldapBackendStatus{TenantID: tenantID, Config: d.Config(), Status: d.Status()}
+}
+
+// handleAdminLDAP configures a tenant's directory (PUT), reports backends
+// (GET, all tenants unless ?tenant_id=), forces a sync (POST) or removes
+// the backend (DELETE)
+func (s *MinIOServer) handleAdminLDAP(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.URL.Query().Get("tenant_id")
+	if tenantID == "" && r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Missing tenant_id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if tenantID != "" {
+			d, ok := s.directory(tenantID)
+			if !ok {
+				writeErrorMessage(w, r, "No directory configured for tenant", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, backendStatus(tenantID, d))
+			return
+		}
+		s.directoriesMu.RLock()
+		out := make([]ldapBackendStatus, 0, len(s.directories))
+		for id, d := range s.directories {
+			out = append(out, backendStatus(id, d))
+		}
+		s.directoriesMu.RUnlock()
+		writeJSON(w, http.StatusOK, out)
+
+	case http.MethodPut:
+		if _, err := s.tenantManager.GetTenant(r.Context(), tenantID); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		var req ldapConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid directory config", http.StatusBadRequest)
+			return
+		}
+		if req.SyncInterval != "" {
+			interval, err := time.ParseDuration(req.SyncInterval)
+			if err != nil || interval < time.Minute {
+				writeErrorMessage(w, r, "sync_interval must be at least 1m", http.StatusBadRequest)
+				return
+			}
+			req.DirectoryConfig.SyncInterval = interval
+		}
+
+		d, err := identity.NewDirectory(req.DirectoryConfig)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.setDirectory(tenantID, d)
+
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "iam.ldap_configure",
+			Details: map[string]string{"url": req.URL, "bind_dn": req.BindDN}})
+		writeJSON(w, http.StatusOK, backendStatus(tenantID, d))
+
+	case http.MethodPost:
+		d, ok := s.directory(tenantID)
+		if !ok {
+			writeErrorMessage(w, r, "No directory configured for tenant", http.StatusNotFound)
+			return
+		}
+		result, err := d.Sync(r.Context())
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadGateway)
+			return
+		}
+		for _, dn := range result.Removed {
+			s.sessions.RevokeSubject(ldapSubject(tenantID, dn))
+		}
+		writeJSON(w, http.StatusOK, result)
+
+	case http.MethodDelete:
+		if !s.removeDirectory(tenantID) {
+			writeErrorMessage(w, r, "No directory configured for tenant", http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "iam.ldap_remove"})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminIAMUsers lists a tenant's synced directory users and their policies
+func (s *MinIOServer) handleAdminIAMUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	d, ok := s.directory(r.URL.Query().Get("tenant_id"))
+	if !ok {
+		writeErrorMessage(w, r, "No directory configured for tenant", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, d.Users())
+}
+
+// iamLoginRequest is the body of POST /iam/login
+type iamLoginRequest struct {
+	TenantID  string `json:"tenant_id"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	ExpiresIn string `json:"expires_in"` // Go duration, default 1h
+}
+
+// handleIAMLogin authenticates a directory user by LDAP bind, starts a
+// console session and returns a tenant token scoped to the user's policies
+func (s *MinIOServer) handleIAMLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req iamLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid login request", http.StatusBadRequest)
+		return
+	}
+
+	ttl := time.Hour
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			writeErrorMessage(w, r, "Invalid expires_in", http.StatusBadRequest)
+			return
+		}
+	}
+	if ttl > maxExchangedTokenTTL {
+		ttl = maxExchangedTokenTTL
+	}
+
+	d, ok := s.directory(req.TenantID)
+	if !ok {
+		writeErrorMessage(w, r, "No directory configured for tenant", http.StatusNotFound)
+		return
+	}
+
+	user, err := d.Authenticate(r.Context(), req.Username, req.Password)
+	switch {
+	case errors.Is(err, identity.ErrInvalidCredentials), errors.Is(err, identity.ErrNoPolicy):
+		s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: req.Username, Action: "iam.login", Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"error": err.Error()}})
+		status := http.StatusUnauthorized
+		if errors.Is(err, identity.ErrNoPolicy) {
+			status = http.StatusForbidden
+		}
+		writeErrorMessage(w, r, err.Error(), status)
+		return
+	case err != nil:
+		s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: req.Username, Action: "iam.login", Outcome: audit.OutcomeError,
+			Details: map[string]string{"error": err.Error()}})
+		writeErrorMessage(w, r, "Directory unavailable", http.StatusBadGateway)
+		return
+	}
+
+	id := &identity.Identity{
+		Subject:  ldapSubject(req.TenantID, user.DN),
+		Email:    user.Email,
+		Name:     user.Name,
+		Groups:   user.Groups,
+		Tenants:  []string{req.TenantID},
+		Policies: user.Policies,
+		Expiry:   time.Now().Add(ttl).UTC(),
+	}
+
+	token, err := s.tenantManager.IssueSubjectToken(r.Context(), req.TenantID, id.Subject, id.Permissions(), ttl)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	sess := s.sessions.Create(id)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sess.ID,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		HttpOnly: true,
+		Secure:   requestIsTLS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: id.Subject, Action: "iam.login", Resource: sess.ID[:8],
+		Details: map[string]string{"policies": strings.Join(user.Policies, ",")}})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"tenant_id":    req.TenantID,
+		"expires_at":   id.Expiry,
+		"policies":     user.Policies,
+	})
+}
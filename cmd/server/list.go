@@ -0,0 +1,232 @@
+// cmd/server/list.go
+// Object listing and stat. Writes update the index before they are
+// acknowledged, so both are read-your-writes on the accepting node;
+// ?strict=true additionally waits until the node's earlier writes for the
+// tenant have replicated (bounded by StrictListTimeout).
+//
+// Listings page by key: each page resumes strictly after the last key of the
+// previous one (an opaque continuation token, or a raw ?marker=), so keys
+// that exist throughout a paged listing are returned exactly once even while
+// other keys are written or deleted.
+//
+// With ?delimiter= keys are rolled up into common prefixes ("folders") so
+// browsers can walk a hierarchy one level at a time, and
+// ?aggregate=prefix&delimiter=/ reports object counts and bytes per child
+// prefix from totals the index keeps up to date on every write.
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+const (
+	defaultMaxKeys = 1000
+	maxMaxKeys     = 10000
+)
+
+// objectInfo is the API view of an indexed object
+type objectInfo struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"last_modified"`
+	VersionID    string            `json:"version_id,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	UserMeta     map[string]string `json:"user_meta,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+func newObjectInfo(meta *metadata.ObjectMeta) objectInfo {
+	return objectInfo{
+		Key:          meta.Key,
+		Size:         meta.Size,
+		LastModified: time.Unix(0, meta.ModTime).UTC(),
+		VersionID:    meta.VersionID,
+		ETag:         meta.ETag,
+		UserMeta:     meta.UserMeta,
+		Tags:         meta.Tags,
+	}
+}
+
+// encodeContinuation makes the resume token for a page ending at key
+func encodeContinuation(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeContinuation(token string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	return string(key), err
+}
+
+// awaitConvergence honours ?strict=true, reporting convergence in headers
+func (s *MinIOServer) awaitConvergence(w http.ResponseWriter, r *http.Request, tenantID string) {
+	converged := true
+	if r.URL.Query().Get("strict") == "true" {
+		ctx, cancel := context.WithTimeout(r.Context(), s.config.StrictListTimeout)
+		converged = s.convergence.Wait(ctx, tenantID, s.convergence.Current())
+		cancel()
+	}
+	w.Header().Set("X-Index-Converged", strconv.FormatBool(converged && s.convergence.Lag(tenantID) == 0))
+	w.Header().Set("X-Replication-Lag-Ms", strconv.FormatInt(s.convergence.Lag(tenantID).Milliseconds(), 10))
+}
+
+// handleList lists a tenant's objects under ?prefix=, up to ?max_keys=,
+// resuming after ?continuation_token= or ?marker=, grouping keys that contain
+// ?delimiter= past the prefix into common_prefixes
+func (s *MinIOServer) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	switch query.Get("aggregate") {
+	case "":
+	case "prefix":
+		s.handleListAggregate(w, r, tenantID)
+		return
+	default:
+		writeErrorMessage(w, r, "Invalid aggregate", http.StatusBadRequest)
+		return
+	}
+
+	maxKeys := defaultMaxKeys
+	if raw := query.Get("max_keys"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeErrorMessage(w, r, "Invalid max_keys", http.StatusBadRequest)
+			return
+		}
+		if n < maxMaxKeys {
+			maxKeys = n
+		} else {
+			maxKeys = maxMaxKeys
+		}
+	}
+
+	marker := query.Get("marker")
+	if token := query.Get("continuation_token"); token != "" {
+		key, err := decodeContinuation(token)
+		if err != nil {
+			writeErrorMessage(w, r, "Invalid continuation_token", http.StatusBadRequest)
+			return
+		}
+		marker = key
+	}
+
+	if query.Get("source") == "gateway" {
+		if query.Get("marker") != "" {
+			writeErrorMessage(w, r, "Gateway listings resume from continuation_token only", http.StatusBadRequest)
+			return
+		}
+		if query.Get("delimiter") != "" {
+			writeErrorMessage(w, r, "Gateway listings do not support delimiter", http.StatusBadRequest)
+			return
+		}
+		s.listGateway(w, r, tenantID, query.Get("prefix"), marker, maxKeys)
+		return
+	}
+
+	s.awaitConvergence(w, r, tenantID)
+
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	page := s.index.ListDelimited(tenantID, prefix, delimiter, marker, maxKeys)
+
+	objects := make([]objectInfo, len(page.Objects))
+	for i := range page.Objects {
+		objects[i] = newObjectInfo(&page.Objects[i])
+	}
+
+	if s.auditsTenant(r.Context(), tenantID) {
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: auditActor(r.Context(), tenantID), Action: "object.list", Resource: prefix})
+	}
+	resp := map[string]interface{}{
+		"objects":      objects,
+		"count":        len(objects),
+		"is_truncated": page.Truncated,
+	}
+	if delimiter != "" {
+		prefixes := page.CommonPrefixes
+		if prefixes == nil {
+			prefixes = []string{}
+		}
+		resp["delimiter"] = delimiter
+		resp["common_prefixes"] = prefixes
+	}
+	if page.Truncated {
+		resp["next_continuation_token"] = encodeContinuation(page.NextMarker)
+	}
+	s.recordRequest(tenantID, prefix, requestList, 0, 0)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleListAggregate reports the totals under ?prefix= broken down by the
+// next ?delimiter= level. Only metadata.AggregateDelimiter is maintained.
+func (s *MinIOServer) handleListAggregate(w http.ResponseWriter, r *http.Request, tenantID string) {
+	query := r.URL.Query()
+	if delimiter := query.Get("delimiter"); delimiter != metadata.AggregateDelimiter {
+		writeErrorMessage(w, r, "Aggregation requires delimiter="+metadata.AggregateDelimiter, http.StatusBadRequest)
+		return
+	}
+
+	prefix := query.Get("prefix")
+	s.awaitConvergence(w, r, tenantID)
+	agg, err := s.index.AggregatePrefix(tenantID, prefix)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if s.auditsTenant(r.Context(), tenantID) {
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: auditActor(r.Context(), tenantID), Action: "object.aggregate", Resource: prefix})
+	}
+	writeJSON(w, http.StatusOK, agg)
+}
+
+// handleStat returns the metadata of ?key= without its data
+func (s *MinIOServer) handleStat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, key := tenantFromRequest(r), r.URL.Query().Get("key")
+	if tenantID == "" || key == "" {
+		writeErrorMessage(w, r, "Missing tenant ID or key", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.authorizeObject(r, tenantID, key, actionRead); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	s.awaitConvergence(w, r, tenantID)
+
+	meta, err := s.index.Get(tenantID, key)
+	if err != nil {
+		writeError(w, r, errObjectNotFound)
+		return
+	}
+	s.checkReplicas(meta)
+	writeJSON(w, http.StatusOK, newObjectInfo(meta))
+}
@@ -0,0 +1,197 @@
+// cmd/server/memcache.go
+// memcached text-protocol listener mapped onto one tenant's objects under a
+// key prefix, so existing memcache clients can use the small-object path.
+// Flags are not persisted (always returned as 0) and expiry is ignored.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	memcacheMaxKey   = 250
+	memcacheMaxValue = 1 << 20
+	memcacheVersion  = "1.6.0-minio"
+)
+
+// startMemcache listens on KVMemcacheAddr until the server shuts down
+func (s *MinIOServer) startMemcache() error {
+	if s.config.KVTenant == "" {
+		return fmt.Errorf("MINIO_KV_TENANT is required with MINIO_KV_MEMCACHE_ADDR")
+	}
+	if _, err := s.tenantManager.GetTenant(s.ctx, s.config.KVTenant); err != nil {
+		log.Printf("Warning: memcache tenant %s does not exist yet: %v", s.config.KVTenant, err)
+	}
+
+	ln, err := net.Listen("tcp", s.config.KVMemcacheAddr)
+	if err != nil {
+		return fmt.Errorf("memcache listen failed: %w", err)
+	}
+	go func() {
+		<-s.ctx.Done()
+		ln.Close()
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if s.ctx.Err() == nil {
+					log.Printf("Memcache accept error: %v", err)
+				}
+				return
+			}
+			go s.serveMemcache(conn)
+		}
+	}()
+	return nil
+}
+
+func validMemcacheKey(key string) bool {
+	if key == "" || len(key) > memcacheMaxKey {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MinIOServer) serveMemcache(conn net.Conn) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	tenantID, prefix := s.config.KVTenant, s.config.KVPrefix
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			w.WriteString("ERROR\r\n")
+			w.Flush()
+			continue
+		}
+
+		switch cmd := fields[0]; cmd {
+		case "get", "gets":
+			for _, key := range fields[1:] {
+				if !validMemcacheKey(key) {
+					continue
+				}
+				data, err := s.kvGet(ctx, tenantID, prefix+key)
+				if err != nil {
+					continue
+				}
+				if cmd == "gets" {
+					fmt.Fprintf(w, "VALUE %s 0 %d 0\r\n", key, len(data))
+				} else {
+					fmt.Fprintf(w, "VALUE %s 0 %d\r\n", key, len(data))
+				}
+				w.Write(data)
+				w.WriteString("\r\n")
+			}
+			w.WriteString("END\r\n")
+
+		case "set", "add", "replace":
+			// <cmd> <key> <flags> <exptime> <bytes> [noreply]
+			if len(fields) < 5 {
+				w.WriteString("ERROR\r\n")
+				break
+			}
+			size, err := strconv.Atoi(fields[4])
+			if err != nil || size < 0 {
+				w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+				break
+			}
+			if size > memcacheMaxValue {
+				// Swallow the value so the stream stays in sync
+				io.CopyN(io.Discard, r, int64(size)+2)
+				w.WriteString("SERVER_ERROR object too large for cache\r\n")
+				break
+			}
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			if string(data[size:]) != "\r\n" {
+				w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+				break
+			}
+			noreply := len(fields) > 5 && fields[5] == "noreply"
+			key := fields[1]
+			if !validMemcacheKey(key) {
+				if !noreply {
+					w.WriteString("CLIENT_ERROR bad key\r\n")
+				}
+				break
+			}
+
+			_, existsErr := s.index.Get(tenantID, prefix+key)
+			exists := existsErr == nil
+			reply := "STORED\r\n"
+			switch {
+			case cmd == "add" && exists, cmd == "replace" && !exists:
+				reply = "NOT_STORED\r\n"
+			default:
+				if err := s.kvPut(ctx, tenantID, prefix+key, data[:size]); err != nil {
+					reply = memcacheServerError(err)
+				}
+			}
+			if !noreply {
+				w.WriteString(reply)
+			}
+
+		case "delete":
+			if len(fields) < 2 {
+				w.WriteString("ERROR\r\n")
+				break
+			}
+			noreply := fields[len(fields)-1] == "noreply"
+			reply := "DELETED\r\n"
+			if err := s.kvDelete(ctx, tenantID, prefix+fields[1]); err != nil {
+				if errors.Is(err, errObjectNotFound) {
+					reply = "NOT_FOUND\r\n"
+				} else {
+					reply = memcacheServerError(err)
+				}
+			}
+			if !noreply {
+				w.WriteString(reply)
+			}
+
+		case "version":
+			w.WriteString("VERSION " + memcacheVersion + "\r\n")
+
+		case "quit":
+			w.Flush()
+			return
+
+		default:
+			w.WriteString("ERROR\r\n")
+		}
+		w.Flush()
+	}
+}
+
+func memcacheServerError(err error) string {
+	return "SERVER_ERROR " + strings.ReplaceAll(err.Error(), "\r\n", " ") + "\r\n"
+}
@@ -0,0 +1,676 @@
+// cmd/server/multipart.go
+// Multipart uploads: an object too large to buffer is uploaded in parts,
+// each streamed to the part store as it arrives, so a failed part can be
+// sent again without restarting the upload. Completing the upload indexes
+// the object as a manifest of its parts; its bytes stay in the part store
+// and are streamed from there on download and replication.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/multipart"
+	"github.com/minio/enterprise/internal/scan"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// multipartSweepInterval is how often uploads past MultipartExpiry are aborted
+const multipartSweepInterval = time.Hour
+
+// multipartError maps part store errors to API errors
+func multipartError(err error) error {
+	switch {
+	case errors.Is(err, multipart.ErrNoSuchUpload):
+		return errNoSuchUpload
+	case errors.Is(err, multipart.ErrInvalidPart):
+		return &httpError{http.StatusBadRequest, codeInvalidPart, err.Error()}
+	case errors.Is(err, multipart.ErrInvalidPartOrder):
+		return &httpError{http.StatusBadRequest, codeInvalidPartOrder, err.Error()}
+	case errors.Is(err, multipart.ErrEntityTooSmall):
+		return &httpError{http.StatusBadRequest, codeEntityTooSmall, err.Error()}
+	case errors.Is(err, multipart.ErrBadDigest):
+		return &httpError{http.StatusBadRequest, codeBadDigest, err.Error()}
+	case errors.Is(err, multipart.ErrInvalidPartNum):
+		return &httpError{http.StatusBadRequest, codeInvalidRequest, err.Error()}
+	}
+	return err
+}
+
+// readParts returns the whole of a multipart object, for callers that
+// need it in memory
+func (s *MinIOServer) readParts(meta *metadata.ObjectMeta) ([]byte, error) {
+	obj, err := s.parts.OpenAt(meta.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	data := make([]byte, obj.Size())
+	if len(data) > 0 {
+		if _, err := obj.ReadAt(data, 0); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// releaseParts drops the manifest of a replaced or deleted multipart
+// object; readers still open keep its parts until they close
+func (s *MinIOServer) releaseParts(prev *metadata.ObjectMeta) {
+	if prev == nil || prev.Manifest == "" {
+		return
+	}
+	if err := s.parts.Delete(prev.Manifest); err != nil {
+		log.Printf("Failed to release parts of %s/%s: %v", prev.Tenant, prev.Key, err)
+	}
+}
+
+// sweepManifests deletes manifests no indexed object refers to, left when
+// a completion was rolled back or the server stopped before releasing a
+// replaced object's parts
+func (s *MinIOServer) sweepManifests() int {
+	swept := 0
+	for _, id := range s.parts.ManifestIDs() {
+		m, ok := s.parts.Manifest(id)
+		if !ok {
+			continue
+		}
+		if meta, err := s.index.Get(m.Tenant, m.Key); err == nil && meta.Manifest == id {
+			continue
+		}
+		if s.parts.Delete(id) == nil {
+			swept++
+		}
+	}
+	return swept
+}
+
+// multipartExpirer aborts uploads not completed within MultipartExpiry
+func (s *MinIOServer) multipartExpirer() {
+	if s.config.MultipartExpiry <= 0 {
+		return
+	}
+	ticker := time.NewTicker(multipartSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.parts.AbortStale(time.Now().Add(-s.config.MultipartExpiry)); n > 0 {
+				log.Printf("Aborted %d expired multipart uploads", n)
+			}
+		}
+	}
+}
+
+// tenantUpload returns tenantID's upload uploadID
+func (s *MinIOServer) tenantUpload(tenantID, uploadID string) (*multipart.Upload, error) {
+	up, err := s.parts.Get(uploadID)
+	if err != nil || up.Tenant != tenantID {
+		return nil, errNoSuchUpload
+	}
+	return up, nil
+}
+
+// checkMultipart refuses multipart uploads to tenants whose writes need
+// the whole object in memory: sealed at rest, scanned, or written through
+// to a gateway bucket
+func (s *MinIOServer) checkMultipart(ctx context.Context, tenantID string) error {
+	settings, err := s.tenantManager.Settings(ctx, tenantID)
+	if err != nil {
+		return nil // Unknown tenants are rejected further down the path
+	}
+	if s.encryptsAtRest(ctx, tenantID, settings) {
+		return errPartsEncrypted
+	}
+	if settings.ScanUploads {
+		return &httpError{http.StatusNotImplemented, codeNotImplemented, "Multipart uploads are not supported for tenants that scan uploads"}
+	}
+	if _, ok := s.gatewayFor(tenantID); ok {
+		return &httpError{http.StatusNotImplemented, codeNotImplemented, "Multipart uploads are not supported in gateway mode"}
+	}
+	return nil
+}
+
+// multipartUpload is an upload as listed to clients
+type multipartUpload struct {
+	UploadID string          `json:"upload_id"`
+	Key      string          `json:"key"`
+	Created  time.Time       `json:"created"`
+	Parts    []multipartPart `json:"parts,omitempty"`
+}
+
+// multipartPart is a received part as listed to clients
+type multipartPart struct {
+	PartNumber int       `json:"part_number"`
+	Size       int64     `json:"size"`
+	ETag       string    `json:"etag"`
+	SHA256     string    `json:"sha256"`
+	Uploaded   time.Time `json:"uploaded"`
+}
+
+func newMultipartPart(p multipart.Part) multipartPart {
+	return multipartPart{PartNumber: p.Number, Size: p.Size, ETag: p.ETag, SHA256: p.SHA256, Uploaded: p.Uploaded}
+}
+
+// completeRequest is the body of a completion: the parts making up the
+// object, in order. S3's CompleteMultipartUpload XML is accepted too.
+type completeRequest struct {
+	XMLName xml.Name                  `json:"-" xml:"CompleteMultipartUpload"`
+	Parts   []multipart.CompletedPart `json:"parts" xml:"Part"`
+}
+
+// handleMultipart starts (POST ?key=), lists (GET, or GET ?upload_id= for
+// one upload's parts) and aborts (DELETE ?upload_id=) multipart uploads.
+// Starting or aborting an upload needs write access to its key, and
+// listing read access; uploads the principal cannot read are not listed.
+func (s *MinIOServer) handleMultipart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	uploadID := r.URL.Query().Get("upload_id")
+	p := s.requestPrincipal(r)
+
+	switch r.Method {
+	case http.MethodPost:
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			writeErrorMessage(w, r, "Missing key", http.StatusBadRequest)
+			return
+		}
+		err := s.authorize(p, tenantID, key, actionWrite)
+		if err == nil {
+			err = s.checkWritable()
+		}
+		if err == nil {
+			err = s.checkMultipart(ctx, tenantID)
+		}
+		if err == nil {
+			err = s.checkBucket(ctx, tenantID, key)
+		}
+		var attrs metadata.Attributes
+		if err == nil {
+			attrs, err = requestAttributes(r)
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		up, err := s.parts.Initiate(tenantID, key, attrs)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, multipartUpload{UploadID: up.ID, Key: up.Key, Created: up.Created})
+
+	case http.MethodGet:
+		if uploadID == "" {
+			uploads := s.parts.Uploads(tenantID)
+			resp := make([]multipartUpload, 0, len(uploads))
+			for _, up := range uploads {
+				if s.authorize(p, tenantID, up.Key, actionRead) == nil {
+					resp = append(resp, multipartUpload{UploadID: up.ID, Key: up.Key, Created: up.Created})
+				}
+			}
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		up, err := s.tenantUpload(tenantID, uploadID)
+		if err == nil {
+			err = s.authorize(p, tenantID, up.Key, actionRead)
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resp := multipartUpload{UploadID: up.ID, Key: up.Key, Created: up.Created, Parts: []multipartPart{}}
+		for _, p := range up.Parts {
+			resp.Parts = append(resp.Parts, newMultipartPart(p))
+		}
+		sort.Slice(resp.Parts, func(i, j int) bool { return resp.Parts[i].PartNumber < resp.Parts[j].PartNumber })
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodDelete:
+		up, err := s.tenantUpload(tenantID, uploadID)
+		if err == nil {
+			err = s.authorize(p, tenantID, up.Key, actionWrite)
+		}
+		if err == nil {
+			err = multipartError(s.parts.Abort(up.ID))
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleUploadPart stores part ?part_number= of ?upload_id=, replacing any
+// part already sent under that number. Content-MD5 and
+// X-Amz-Checksum-Sha256, when sent, are checked. It needs write access to
+// the upload's key.
+func (s *MinIOServer) handleUploadPart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPut {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("part_number"))
+	if err != nil {
+		writeErrorMessage(w, r, "Invalid part_number", http.StatusBadRequest)
+		return
+	}
+	up, err := s.tenantUpload(tenantID, r.URL.Query().Get("upload_id"))
+	if err == nil {
+		err = s.authorizeObject(r, tenantID, up.Key, actionWrite)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// No part may exceed the object size limit
+	limit := s.objectSizeLimit(ctx, tenantID)
+	if err := s.checkUploadLength(r, limit); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	release, err := s.acquireUpload(ctx, tenantID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer release()
+
+	// A part streams to disk, so it may take longer than the server's
+	// read timeout allows a buffered upload
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+	body := r.Body
+	if limit > 0 {
+		body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	part, err := s.parts.PutPart(up.ID, number, body, multipart.Digests{
+		MD5:    r.Header.Get("Content-MD5"),
+		SHA256: r.Header.Get("X-Amz-Checksum-Sha256"),
+	})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.uploadsTooLarge.Add(1)
+			err = objectTooLarge(limit)
+		}
+		writeError(w, r, multipartError(err))
+		return
+	}
+	w.Header().Set("ETag", `"`+part.ETag+`"`)
+	writeJSON(w, http.StatusOK, newMultipartPart(part))
+}
+
+// handleCompleteMultipart completes ?upload_id= from the listed parts and
+// stores the object under the upload's key. The upload is consumed even
+// when storing the object is refused.
+func (s *MinIOServer) handleCompleteMultipart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	up, err := s.tenantUpload(tenantID, r.URL.Query().Get("upload_id"))
+	if err == nil {
+		err = s.authorizeObject(r, tenantID, up.Key, actionWrite)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req completeRequest
+	body := io.LimitReader(r.Body, 4<<20)
+	if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+		err = xml.NewDecoder(body).Decode(&req)
+	} else {
+		err = json.NewDecoder(body).Decode(&req)
+	}
+	if err != nil || len(req.Parts) == 0 {
+		writeErrorMessage(w, r, "Invalid part list", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	m, err := s.parts.Complete(up.ID, req.Parts)
+	if err != nil {
+		writeError(w, r, multipartError(err))
+		return
+	}
+	err = s.inspectParts(ctx, m)
+	var meta *metadata.ObjectMeta
+	if err == nil {
+		meta, err = s.completeObject(ctx, m, uploadCondition(r), up.Attributes)
+	}
+	if err != nil {
+		s.parts.Delete(m.ID)
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.put", Resource: m.Key,
+			Details: map[string]string{"upload_id": m.ID, "parts": strconv.Itoa(len(m.Parts))}}
+		if err != nil {
+			ev.Outcome = audit.OutcomeError
+			ev.Details["error"] = err.Error()
+		}
+		s.logAudit(ctx, ev)
+	}
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("X-Version-ID", meta.VersionID)
+	w.Header().Set("ETag", `"`+m.ETag+`"`)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":          "uploaded",
+		"key":             m.Key,
+		"size":            m.Size,
+		"etag":            m.ETag,
+		"checksum_sha256": m.ChecksumSHA256,
+		"version_id":      meta.VersionID,
+	})
+}
+
+// inspectParts applies the size cap and the tenant's allowed content
+// types to a completed upload, sniffing the type from its first bytes
+func (s *MinIOServer) inspectParts(ctx context.Context, m *multipart.Manifest) error {
+	if limit := s.objectSizeLimit(ctx, m.Tenant); limit > 0 && m.Size > limit {
+		s.uploadsTooLarge.Add(1)
+		return objectTooLarge(limit)
+	}
+	settings, err := s.tenantManager.Settings(ctx, m.Tenant)
+	if err != nil || len(settings.AllowedContentTypes) == 0 {
+		return nil
+	}
+	obj, err := s.parts.OpenAt(m.ID)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	head := make([]byte, min(obj.Size(), 512))
+	if _, err := obj.ReadAt(head, 0); err != nil && err != io.EOF {
+		return err
+	}
+	if mediaType := scan.Sniff(head); !scan.Allowed(settings.AllowedContentTypes, mediaType) {
+		s.uploadsTypeRejected.Add(1)
+		return &httpError{errContentType.Status, errContentType.Code, "Content type " + mediaType + " not allowed for tenant"}
+	}
+	return nil
+}
+
+// completeObject indexes m's object under its key with attrs, checking
+// cond under the key's write lock, and returns the new version's metadata
+func (s *MinIOServer) completeObject(ctx context.Context, m *multipart.Manifest, cond writeCondition, attrs metadata.Attributes) (*metadata.ObjectMeta, error) {
+	unlock := s.writeLocks.Lock(m.Tenant, m.Key)
+	defer unlock()
+
+	prev, _ := s.index.Get(m.Tenant, m.Key)
+	if err := cond.check(prev); err != nil {
+		return nil, err
+	}
+	meta := metadata.ObjectMeta{
+		Tenant:    m.Tenant,
+		Key:       m.Key,
+		Size:      m.Size,
+		VersionID: newVersionID(),
+		ModTime:   time.Now().UnixNano(),
+		ETag:      m.ETag,
+		Manifest:  m.ID,
+
+		Attributes: attrs,
+	}
+	if err := s.storeObject(ctx, meta, prev, nil, nil); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// streamUpload answers an upload too large to hold in memory by streaming
+// body into the part store as a one-part object, which replication and
+// downloads then read from disk. limit is the size cap body enforces.
+func (s *MinIOServer) streamUpload(w http.ResponseWriter, r *http.Request, tenantID, key string, body io.Reader, limit int64, attrs metadata.Attributes) {
+	ctx := r.Context()
+	s.uploadsStreamed.Add(1)
+	m, err := s.parts.PutObject(tenantID, key, body)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.uploadsTooLarge.Add(1)
+			writeError(w, r, objectTooLarge(limit))
+			return
+		}
+		writeErrorMessage(w, r, "Failed to read body", http.StatusInternalServerError)
+		return
+	}
+
+	err = s.inspectParts(ctx, m)
+	var meta *metadata.ObjectMeta
+	if err == nil {
+		meta, err = s.completeObject(ctx, m, uploadCondition(r), attrs)
+	}
+	if err != nil {
+		s.parts.Delete(m.ID)
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.put", Resource: key,
+			Details: map[string]string{"streamed": "true"}}
+		if err != nil {
+			ev.Outcome = audit.OutcomeError
+			ev.Details["error"] = err.Error()
+		}
+		s.logAudit(ctx, ev)
+	}
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		writeError(w, r, err)
+		return
+	}
+
+	tracing.AddSpanEvent(ctx, "upload_completed")
+	w.Header().Set("X-Version-ID", meta.VersionID)
+	w.Header().Set("ETag", `"`+m.ETag+`"`)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "uploaded",
+		"key":        key,
+		"size":       m.Size,
+		"version_id": meta.VersionID,
+		"etag":       m.ETag,
+	})
+}
+
+// openParts opens tenantID/key in the part store if it is a multipart
+// object, returning nil if it is not. A version replaced between the
+// lookup and the open is looked up again.
+func (s *MinIOServer) openParts(tenantID, key string) (*metadata.ObjectMeta, *multipart.ObjectReader) {
+	for attempt := 0; attempt < 2; attempt++ {
+		meta, err := s.index.Get(tenantID, key)
+		if err != nil || meta.Manifest == "" {
+			return nil, nil
+		}
+		if obj, err := s.parts.OpenAt(meta.Manifest); err == nil {
+			return meta, obj
+		}
+	}
+	return nil, nil
+}
+
+// streamParts answers a download of a multipart object from the part
+// store, honoring conditional headers, Range and If-Range, without
+// buffering it
+func (s *MinIOServer) streamParts(w http.ResponseWriter, r *http.Request, meta *metadata.ObjectMeta, obj *multipart.ObjectReader, shareToken string) {
+	ctx := r.Context()
+	fail := func(err error) {
+		if shareToken != "" {
+			s.tenantManager.ReleaseShareLink(ctx, shareToken)
+		}
+		writeError(w, r, err)
+	}
+	s.checkReplicas(meta)
+
+	if err := checkReadConditions(w.Header(), r, meta); err != nil {
+		fail(err)
+		return
+	}
+
+	size := obj.Size()
+	var ranges []byteRange
+	if rng := r.Header.Get("Range"); rng != "" && rangeApplies(r, meta) {
+		s.rangeRequests.Add(1)
+		var err error
+		if ranges, _, err = parseRanges(rng, size); err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			fail(err)
+			return
+		}
+	}
+	served := size
+	if ranges != nil {
+		served = rangedBytes(ranges)
+	}
+	if err := s.checkEgress(ctx, meta, served); err != nil {
+		fail(err)
+		return
+	}
+
+	h := w.Header()
+	h.Set("X-Version-ID", meta.VersionID)
+	h.Set("ETag", `"`+meta.ETag+`"`)
+	setLastModified(h, meta)
+	setAttributeHeaders(h, meta)
+	h.Set("Accept-Ranges", "bytes")
+	// Large objects take longer to send than the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	// Part reads stop as soon as the client goes away
+	var sent int64
+	var err error
+	if ranges != nil {
+		err = writeRanges(w, "application/octet-stream", size, ranges, func(w io.Writer, i int) error {
+			_, err := io.Copy(sentWriter{w, &sent}, ctxReader{ctx, io.NewSectionReader(obj, ranges[i].start, ranges[i].length())})
+			return err
+		})
+	} else {
+		h.Set("Content-Type", "application/octet-stream")
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(sentWriter{w, &sent}, ctxReader{ctx, io.NewSectionReader(obj, 0, size)})
+	}
+	if err != nil {
+		tracing.RecordError(ctx, err)
+	}
+	s.accountRead(ctx, meta, served, sent)
+}
+
+// replicaSource is what replication ships for an object: its payload, or
+// for a multipart object a reader over its parts
+type replicaSource struct {
+	payload []byte
+	parts   *multipart.ObjectReader
+}
+
+// replicaSource opens meta for replication. A source that is not
+// enqueued must be closed.
+func (s *MinIOServer) replicaSource(ctx context.Context, meta *metadata.ObjectMeta) (*replicaSource, error) {
+	if meta.Manifest != "" {
+		obj, err := s.parts.OpenAt(meta.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		return &replicaSource{parts: obj}, nil
+	}
+	stored, err := s.storedBytes(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.replicaPayload(ctx, meta, stored)
+	if err != nil {
+		return nil, err
+	}
+	return &replicaSource{payload: payload}, nil
+}
+
+// size is the number of bytes src ships
+func (src *replicaSource) size() int64 {
+	if src.parts != nil {
+		return src.parts.Size()
+	}
+	return int64(len(src.payload))
+}
+
+func (src *replicaSource) close() {
+	if src.parts != nil {
+		src.parts.Close()
+	}
+}
+
+// enqueueReplica queues replication of src, streaming multipart objects
+// a chunk at a time; src is closed once replication completes
+func (s *MinIOServer) enqueueReplica(priority int, bucket, key, versionID, requestID string, src *replicaSource, regions []string,
+	onRegion func(region string, ok bool), onComplete func(replicatedRegions int)) error {
+	if src.parts == nil {
+		return s.replicationEngine.EnqueueWithPriority(priority, bucket, key, versionID, requestID, src.payload, regions, onRegion, onComplete)
+	}
+	return s.replicationEngine.EnqueueStream(priority, bucket, key, versionID, requestID, src.parts, src.parts.Size(), regions, onRegion,
+		func(replicated int) {
+			src.close()
+			if onComplete != nil {
+				onComplete(replicated)
+			}
+		})
+}
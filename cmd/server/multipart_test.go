@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Parts and aborts need write access to the upload's key, and listing
+// needs read access
+func TestMultipartAuthorization(t *testing.T) {
+	tenantID := newTenant(t)
+	base := "/v1/multipart?tenant_id=" + tenantID
+	writer := bearer(tenantToken(t, tenantID, "writer", "object:write"))
+	reader := bearer(tenantToken(t, tenantID, "reader", "object:read"))
+	nobody := bearer(tenantToken(t, tenantID, "nobody"))
+
+	w := do(t, "POST", base+"&key=big.bin", nil, reader)
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "POST", base+"&key=big.bin", nil, writer)
+	expectStatus(t, w, http.StatusOK)
+	var up multipartUpload
+	decode(t, w, &up)
+
+	part := "/v1/multipart/part?tenant_id=" + tenantID + "&upload_id=" + up.UploadID + "&part_number=1"
+	w = do(t, "PUT", part, "part one", reader)
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "PUT", part, "part one", writer)
+	expectStatus(t, w, http.StatusOK)
+
+	var uploads []multipartUpload
+	w = do(t, "GET", base, nil, nobody)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &uploads); len(uploads) != 0 {
+		t.Errorf("Listed %d uploads without read access, want none", len(uploads))
+	}
+	w = do(t, "GET", base, nil, reader)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &uploads); len(uploads) != 1 || uploads[0].UploadID != up.UploadID {
+		t.Errorf("Listed %+v, want the upload", uploads)
+	}
+	w = do(t, "GET", base+"&upload_id="+up.UploadID, nil, nobody)
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "GET", base+"&upload_id="+up.UploadID, nil, reader)
+	expectStatus(t, w, http.StatusOK)
+	var detail multipartUpload
+	if decode(t, w, &detail); len(detail.Parts) != 1 || detail.Parts[0].Size != int64(len("part one")) {
+		t.Errorf("Parts = %+v, want part 1", detail.Parts)
+	}
+
+	w = do(t, "DELETE", base+"&upload_id="+up.UploadID, nil, reader)
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "DELETE", base+"&upload_id="+up.UploadID, nil, writer)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "GET", base+"&upload_id="+up.UploadID, nil, reader)
+	expectStatus(t, w, http.StatusNotFound)
+}
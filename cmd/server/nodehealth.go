@@ -0,0 +1,101 @@
+// cmd/server/nodehealth.go
+// Load balancing hints: every node reports a health score clients weigh it
+// by, and in cluster mode the nodes of the cluster, so a client given one
+// endpoint can spread its traffic over all of them
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Node statuses
+const (
+	nodeStatusOK       = "ok"
+	nodeStatusReadOnly = "read_only"
+	nodeStatusDraining = "draining"
+	nodeStatusDegraded = "degraded"
+)
+
+// nodeHealth is this node's /health document
+type nodeHealth struct {
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+	Node      string `json:"node,omitempty"`
+
+	// Score ranks the node for new requests, from 0 (send none) to 1
+	// (idle); Load is the share of upload slots held or queued for
+	Score           float64 `json:"score"`
+	Load            float64 `json:"load"`
+	UploadsInFlight int     `json:"uploads_in_flight"`
+	UploadsQueued   int     `json:"uploads_queued"`
+
+	// Nodes lists the cluster's nodes, this one included
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Degraded lists the startup self-test checks that failed
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// readOnlyScore scales the score of a node refusing writes: it still
+// serves reads, but clients should prefer nodes that take both
+const readOnlyScore = 0.25
+
+// nodeHealth scores this node
+func (s *MinIOServer) nodeHealth() nodeHealth {
+	h := nodeHealth{
+		Status:          nodeStatusOK,
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Node:            s.config.NodeURL,
+		UploadsInFlight: s.uploads.inFlight(),
+		UploadsQueued:   s.uploads.waiting(),
+		Nodes:           s.clusterNodes(),
+		Degraded:        s.degraded,
+	}
+	if slots := s.config.MaxConcurrentUploads; slots > 0 {
+		h.Load = min(float64(h.UploadsInFlight+h.UploadsQueued)/float64(slots), 1)
+	}
+	h.Score = 1 - h.Load
+
+	switch {
+	case s.Draining():
+		h.Status, h.Score = nodeStatusDraining, 0
+	case s.diskWatcher.ReadOnly():
+		h.Status, h.Score = nodeStatusReadOnly, h.Score*readOnlyScore
+	case len(s.degraded) > 0:
+		h.Status, h.Score = nodeStatusDegraded, h.Score*degradedScore
+	}
+	return h
+}
+
+// clusterNodes returns the configured nodes plus this one, or nil outside
+// cluster mode
+func (s *MinIOServer) clusterNodes() []string {
+	if len(s.config.ClusterNodes) == 0 {
+		return nil
+	}
+	nodes := make([]string, 0, len(s.config.ClusterNodes)+1)
+	self := false
+	for _, n := range s.config.ClusterNodes {
+		n = strings.TrimSuffix(n, "/")
+		nodes = append(nodes, n)
+		self = self || n == s.config.NodeURL
+	}
+	if !self && s.config.NodeURL != "" {
+		nodes = append(nodes, s.config.NodeURL)
+	}
+	return nodes
+}
+
+// handleNodeHealth reports this node's health score. It needs no
+// credentials, so clients can probe nodes before authenticating.
+func (s *MinIOServer) handleNodeHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	stampPeerTime(w)
+	writeJSON(w, http.StatusOK, s.nodeHealth())
+}
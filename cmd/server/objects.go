@@ -0,0 +1,474 @@
+// cmd/server/objects.go
+// Transactional object write path shared by upload, copy and other writers
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/encryption"
+	"github.com/minio/enterprise/internal/gateway"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/tenant"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// checkWritable refuses new data while draining or low on disk
+func (s *MinIOServer) checkWritable() error {
+	// A draining node accepts no new data; clients should retry elsewhere
+	if s.Draining() {
+		return errNodeDraining
+	}
+	// Refuse writes while a disk is nearly full
+	if s.diskWatcher.ReadOnly() {
+		return errNodeReadOnly
+	}
+	return nil
+}
+
+// acquireUpload claims a concurrent upload slot, returning its release
+func (s *MinIOServer) acquireUpload(ctx context.Context, tenantID string) (func(), error) {
+	return s.uploads.acquire(ctx, tenantID)
+}
+
+// encryptsAtRest reports whether the tenant's objects are sealed under its data key
+func (s *MinIOServer) encryptsAtRest(ctx context.Context, tenantID string, settings tenant.TenantSettings) bool {
+	return settings.EncryptAtRest || s.tenantManager.HasCompliance(ctx, tenantID, tenant.ComplianceGDPR)
+}
+
+// requestIsTLS reports whether the client connected over TLS, directly or via a proxy
+func requestIsTLS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// checkTenantAccess refuses suspended tenants and enforces the tenant's
+// transport requirements
+func (s *MinIOServer) checkTenantAccess(r *http.Request, tenantID string) error {
+	if _, suspended := s.tenantManager.Suspended(r.Context(), tenantID); suspended {
+		return errTenantSuspended
+	}
+	settings, err := s.tenantManager.Settings(r.Context(), tenantID)
+	if err != nil {
+		return nil // Unknown tenants are rejected further down the path
+	}
+	if settings.RequireTLS && !requestIsTLS(r) {
+		return errTLSRequired
+	}
+	return nil
+}
+
+// auditsTenant reports whether object-level access should be audited for the tenant
+func (s *MinIOServer) auditsTenant(ctx context.Context, tenantID string) bool {
+	settings, err := s.tenantManager.Settings(ctx, tenantID)
+	return err == nil && settings.AuditLogging
+}
+
+// objectAAD binds sealed data to its tenant and key
+func objectAAD(tenantID, key string) []byte {
+	return encryption.ObjectAAD(tenantID, key)
+}
+
+// dataKey fetches the tenant data key, mapping failures to HTTP errors
+func (s *MinIOServer) dataKey(ctx context.Context, tenantID string) ([]byte, error) {
+	key, err := s.tenantManager.DataKey(ctx, tenantID)
+	if errors.Is(err, tenant.ErrKeyShredded) {
+		return nil, errKeyShredded
+	}
+	if err != nil {
+		return nil, errKeyUnavailable
+	}
+	return key, nil
+}
+
+// storedBytes returns an object's stored (possibly sealed) bytes from the
+// index when inlined, the part store when completed from a multipart
+// upload, and otherwise from the cache tiers
+func (s *MinIOServer) storedBytes(ctx context.Context, meta *metadata.ObjectMeta) ([]byte, error) {
+	if meta.Inline != nil {
+		return meta.Inline, nil
+	}
+	if meta.Manifest != "" {
+		return s.readParts(meta)
+	}
+	return s.cacheManager.Tenant(meta.Tenant).Get(ctx, meta.Key)
+}
+
+// cached reports whether meta's stored bytes are in the cache tiers
+func cached(meta *metadata.ObjectMeta) bool {
+	return meta.Inline == nil && meta.Manifest == ""
+}
+
+// inlines reports whether stored bytes of this size are kept in the index
+func (s *MinIOServer) inlines(size int) bool {
+	return size <= s.config.InlineObjectBytes
+}
+
+// getObject returns the plaintext of an indexed object
+func (s *MinIOServer) getObject(ctx context.Context, tenantID, key string) ([]byte, *metadata.ObjectMeta, error) {
+	meta, err := s.index.Get(tenantID, key)
+	if err != nil {
+		return s.gatewayFill(ctx, tenantID, key)
+	}
+
+	data, err := s.readObject(ctx, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, meta, nil
+}
+
+// readObject returns the plaintext of meta from local storage, restoring
+// evicted bytes from the tenant's bucket in gateway mode
+func (s *MinIOServer) readObject(ctx context.Context, meta *metadata.ObjectMeta) ([]byte, error) {
+	data, err := s.storedBytes(ctx, meta)
+	if err != nil {
+		g, ok := s.gatewayFor(meta.Tenant)
+		if !ok {
+			return nil, errObjectNotFound
+		}
+		return s.gatewayRecache(ctx, g, meta)
+	}
+
+	if meta.Encrypted {
+		dataKey, err := s.dataKey(ctx, meta.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = encryption.Open(dataKey, data, objectAAD(meta.Tenant, meta.Key)); err != nil {
+			return nil, errDecryptFailed
+		}
+	}
+	return data, nil
+}
+
+// writeCondition makes a put conditional on the key's current version
+type writeCondition struct {
+	IfVersion string // Current version or ETag must equal this
+	IfAbsent  bool   // Key must not exist
+}
+
+// check compares the condition against the current entry (nil if absent)
+func (c writeCondition) check(current *metadata.ObjectMeta) error {
+	if c.IfAbsent && current != nil {
+		return errVersionMismatch
+	}
+	if c.IfVersion != "" && (current == nil || current.VersionID != c.IfVersion && current.ETag != c.IfVersion) {
+		return errVersionMismatch
+	}
+	return nil
+}
+
+// uploadCondition reads a compare-and-swap precondition from If-Match (or
+// ?if_version=), naming a version or ETag, and If-None-Match: * (or
+// ?if_absent=true)
+func uploadCondition(r *http.Request) writeCondition {
+	query := r.URL.Query()
+	cond := writeCondition{
+		IfVersion: strings.Trim(r.Header.Get("If-Match"), `"`),
+		IfAbsent:  r.Header.Get("If-None-Match") == "*" || query.Get("if_absent") == "true",
+	}
+	if cond.IfVersion == "" {
+		cond.IfVersion = query.Get("if_version")
+	}
+	return cond
+}
+
+// newVersionID returns a fresh random object version
+func newVersionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// objectETag is the hex MD5 of an object's plaintext, as S3 reports for
+// single-part uploads, so clients can compare local files without downloading
+func objectETag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// putObject stores data under tenantID/key as one transaction covering the
+// cache, the key index and the tenant quota; replication follows the commit.
+// Concurrent writes to the key are applied one after another.
+func (s *MinIOServer) putObject(ctx context.Context, tenantID, key string, data []byte) error {
+	_, err := s.putObjectIf(ctx, tenantID, key, data, writeCondition{}, metadata.Attributes{})
+	return err
+}
+
+// putObjectIf is putObject that writes the object with attrs and first
+// checks cond against the current version under the key's write lock,
+// returning the new version's metadata
+func (s *MinIOServer) putObjectIf(ctx context.Context, tenantID, key string, data []byte, cond writeCondition, attrs metadata.Attributes) (*metadata.ObjectMeta, error) {
+	unlock := s.writeLocks.Lock(tenantID, key)
+	defer unlock()
+
+	prev, _ := s.index.Get(tenantID, key)
+	if err := cond.check(prev); err != nil {
+		return nil, err
+	}
+
+	meta := metadata.ObjectMeta{
+		Tenant:    tenantID,
+		Key:       key,
+		Size:      int64(len(data)),
+		VersionID: newVersionID(),
+		ModTime:   time.Now().UnixNano(),
+		ETag:      objectETag(data),
+
+		Attributes: attrs,
+	}
+	// In gateway mode the tenant's bucket must also hold the condition
+	g, _ := s.gatewayFor(tenantID)
+	gcond := gateway.Condition{IfAbsent: cond.IfAbsent}
+	var writeThrough func() error
+	if g != nil && !g.Queues(gcond) {
+		writeThrough = func() error {
+			if err := g.Put(ctx, key, data, gcond); err != nil {
+				return gatewayError(err)
+			}
+			return nil
+		}
+	}
+
+	if err := s.storeObject(ctx, meta, prev, data, writeThrough); err != nil {
+		return nil, err
+	}
+	if g != nil && g.Queues(gcond) {
+		g.Put(ctx, key, data, gcond)
+	}
+	return &meta, nil
+}
+
+// storeObject writes meta over prev; the caller holds the key's write lock.
+// writeThrough, if set, runs once the local write is in place and before it
+// is replicated; its failure rolls the write back.
+func (s *MinIOServer) storeObject(ctx context.Context, meta metadata.ObjectMeta, prev *metadata.ObjectMeta, data []byte, writeThrough func() error) error {
+	tracer := tracing.GetTracer("http")
+	tenantID, key := meta.Tenant, meta.Key
+
+	// Overwrites are charged only for the size difference
+	op := metadata.Op{Type: metadata.OpPut, Meta: meta, Prev: prev}
+	delta := meta.Size
+	if op.Prev != nil {
+		delta -= op.Prev.Size
+	}
+
+	settings, _ := s.tenantManager.Settings(ctx, tenantID)
+	if err := s.checkBucket(ctx, tenantID, key); err != nil {
+		return err
+	}
+	bucket, inBucket := s.bucketFor(ctx, tenantID, key)
+	if (settings.ObjectLock || inBucket && bucket.Config.ObjectLock) && op.Prev != nil {
+		return errObjectLocked
+	}
+	if inBucket && bucket.Config.AppendOnly && op.Prev != nil {
+		return errBucketAppend
+	}
+	if !settings.AllowsRegion(s.replicationEngine.SourceRegion()) {
+		return errRegionForbidden
+	}
+
+	// Quota and usage are charged on plaintext size; the sealed bytes go to the cache
+	stored := data
+	if s.encryptsAtRest(ctx, tenantID, settings) {
+		if meta.Manifest != "" {
+			return errPartsEncrypted
+		}
+		dataKey, err := s.dataKey(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		if stored, err = encryption.Seal(dataKey, data, objectAAD(tenantID, key)); err != nil {
+			tracing.RecordError(ctx, err)
+			return errStoreFailed
+		}
+		meta.Encrypted = true
+	}
+	if meta.Manifest == "" && s.inlines(len(stored)) {
+		meta.Inline = stored
+	}
+	op.Meta = meta
+
+	// Check quota
+	_, quotaSpan := tracing.StartSpan(ctx, tracer, "check_quota")
+	canUpload, err := s.tenantManager.CheckQuota(ctx, tenantID, delta)
+	quotaSpan.End()
+	if err != nil {
+		return errQuotaExceeded
+	}
+	if !canUpload {
+		tracing.AddSpanEvent(ctx, "quota_exceeded")
+		return s.quotaExceeded(ctx, tenantID, delta)
+	}
+
+	// Record intent so a crash mid-way is rolled back on restart
+	txn, err := s.intentLog.Begin(op)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return errIntentFailed
+	}
+
+	// Store in cache, or drop a cached predecessor when the object is inlined
+	// or in the part store
+	_, cacheSpan := tracing.StartSpan(ctx, tracer, "cache_set")
+	tc := s.cacheManager.Tenant(tenantID)
+	var previous []byte
+	if op.Prev != nil && cached(op.Prev) {
+		previous, _ = tc.Get(ctx, key)
+	}
+	if !cached(&meta) {
+		tc.Delete(ctx, key)
+		if s.placementTrace != nil && meta.Inline != nil {
+			plan, _ := s.tenantManager.TenantPlan(ctx, tenantID)
+			s.observeWrite(tenantID, plan, key, len(stored))
+		}
+	} else {
+		err = tc.Set(s.placementContext(ctx, tenantID, key, len(stored)), key, stored)
+	}
+	cacheSpan.End()
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		txn.Abort()
+		return errStoreFailed
+	}
+	txn.OnAbort(func() {
+		if previous != nil {
+			tc.Set(context.Background(), key, previous)
+		} else {
+			tc.Delete(context.Background(), key)
+		}
+	})
+
+	// Insert into index
+	s.index.Put(meta)
+	txn.OnAbort(func() {
+		if op.Prev != nil {
+			s.index.Put(*op.Prev)
+		} else {
+			s.index.Delete(tenantID, key)
+		}
+	})
+
+	// Update quota
+	_, updateQuotaSpan := tracing.StartSpan(ctx, tracer, "update_quota")
+	ingress := ingressOf(ctx, meta.Size)
+	err = s.tenantManager.UpdateQuota(ctx, tenantID, delta, 1, tenant.Transfer{Ingress: ingress})
+	updateQuotaSpan.End()
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		txn.Abort()
+		if errors.Is(err, tenant.ErrBandwidthQuotaExceeded) {
+			return errIngressQuota
+		}
+		return s.quotaExceeded(ctx, tenantID, delta)
+	}
+	txn.OnAbort(func() {
+		s.tenantManager.UpdateQuota(context.Background(), tenantID, -delta, 0, tenant.Transfer{})
+	})
+
+	// Write through to the tenant's own bucket before anything irreversible
+	if writeThrough != nil {
+		if err := writeThrough(); err != nil {
+			tracing.RecordError(ctx, err)
+			txn.Abort()
+			return err
+		}
+	}
+
+	// Replication ships the write from the change feed once it commits;
+	// refuse it now if it never could
+	if err := s.checkReplicable(ctx, &meta); err != nil {
+		tracing.RecordError(ctx, err)
+		txn.Abort()
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		tracing.RecordError(ctx, err)
+		return errCommitFailed
+	}
+	s.releaseParts(op.Prev)
+	s.recordRequest(tenantID, key, requestWrite, ingress, 0)
+	return nil
+}
+
+// serverSideKey marks a context whose writes copy data already on the
+// server, so they are not charged as ingress
+type serverSideKey struct{}
+
+func serverSide(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serverSideKey{}, true)
+}
+
+// ingressOf returns the ingress charged for writing size bytes under ctx
+func ingressOf(ctx context.Context, size int64) int64 {
+	if ctx.Value(serverSideKey{}) != nil {
+		return 0
+	}
+	return size
+}
+
+// checkDeletable refuses client deletes in append-only buckets. Lifecycle
+// removals (erasure requests, tenant purge) call deleteObject directly.
+func (s *MinIOServer) checkDeletable(ctx context.Context, tenantID, key string) error {
+	if bucket, ok := s.bucketFor(ctx, tenantID, key); ok && bucket.Config.AppendOnly {
+		return errBucketAppend
+	}
+	return nil
+}
+
+// deleteObject removes tenantID/key from the cache and index as one
+// transaction and releases its quota
+func (s *MinIOServer) deleteObject(ctx context.Context, tenantID, key string) (*metadata.ObjectMeta, error) {
+	unlock := s.writeLocks.Lock(tenantID, key)
+	defer unlock()
+
+	prev, err := s.index.Get(tenantID, key)
+	if err != nil {
+		return nil, errObjectNotFound
+	}
+
+	txn, err := s.intentLog.Begin(metadata.Op{Type: metadata.OpDelete, Meta: *prev, Prev: prev})
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, errIntentFailed
+	}
+
+	tc := s.cacheManager.Tenant(tenantID)
+	var previous []byte
+	if cached(prev) {
+		previous, _ = tc.Get(ctx, key)
+		tc.Delete(ctx, key)
+	}
+	txn.OnAbort(func() {
+		if previous != nil {
+			tc.Set(context.Background(), key, previous)
+		}
+	})
+
+	s.index.Delete(tenantID, key)
+	txn.OnAbort(func() {
+		s.index.Put(*prev)
+	})
+
+	if err := s.tenantManager.UpdateQuota(ctx, tenantID, -prev.Size, 1, tenant.Transfer{}); err != nil {
+		tracing.RecordError(ctx, err)
+		txn.Abort()
+		return nil, errStoreFailed
+	}
+
+	if err := txn.Commit(); err != nil {
+		tracing.RecordError(ctx, err)
+		return nil, errCommitFailed
+	}
+	s.releaseParts(prev)
+	s.observeDelete(tenantID, key, prev.Size)
+	s.recordRequest(tenantID, key, requestDelete, 0, 0)
+	return prev, nil
+}
@@ -0,0 +1,312 @@
+// cmd/server/openapi.go
+// OpenAPI 3 document generated from the route table. Operations come from
+// each apiRoute's Ops and schemas are reflected from the Go types handlers
+// decode and encode, so the document cannot drift from what the server
+// actually serves. It is available at /v1/admin/openapi.json.
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiOp documents one method of a route
+type apiOp struct {
+	Method  string
+	Summary string
+	Params  []apiParam
+
+	// Body is a value of the request body's type; rawBody for object data
+	Body interface{}
+
+	// Result is a value of the success response's type; rawBody for
+	// object data, nil for no body
+	Result interface{}
+
+	// Status is the success status, http.StatusOK if zero
+	Status int
+}
+
+// apiParam is a query parameter, or a header when In is "header"
+type apiParam struct {
+	Name        string
+	In          string
+	Required    bool
+	Description string
+}
+
+// rawBody marks an application/octet-stream body
+type rawBody struct{}
+
+// shape describes a JSON object assembled ad hoc by a handler: each value
+// is a sample of the property's type
+type shape map[string]interface{}
+
+// Parameters shared by many operations
+var (
+	paramTenant = apiParam{Name: "tenant_id", Description: "Tenant; alternatively the X-Tenant-ID header or a virtual host"}
+	paramKey    = apiParam{Name: "key", Required: true, Description: "Object key"}
+	paramAdmin  = apiParam{Name: "tenant", Description: "Restrict to one tenant"}
+)
+
+// openAPIDoc builds the document for routes served under /v1
+func openAPIDoc(routes []apiRoute) map[string]interface{} {
+	gen := &schemaGen{schemas: make(map[string]interface{}), names: make(map[reflect.Type]string)}
+	paths := make(map[string]interface{})
+
+	for _, rt := range routes {
+		item := make(map[string]interface{})
+		for _, op := range rt.Ops {
+			item[strings.ToLower(op.Method)] = gen.operation(rt, op)
+		}
+		if len(item) > 0 {
+			paths[apiV1+rt.Path] = item
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "MinIO Enterprise API",
+			"version": Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+				"basic":  map[string]interface{}{"type": "http", "scheme": "basic"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}, map[string]interface{}{"basic": []string{}}},
+	}
+}
+
+func (g *schemaGen) operation(rt apiRoute, op apiOp) map[string]interface{} {
+	out := map[string]interface{}{
+		"summary":     op.Summary,
+		"operationId": operationID(op.Method, rt.Path),
+		"tags":        []string{routeTag(rt.Path)},
+	}
+	if rt.Deprecated != nil {
+		out["deprecated"] = true
+	}
+
+	var params []interface{}
+	for _, p := range op.Params {
+		in := p.In
+		if in == "" {
+			in = "query"
+		}
+		params = append(params, map[string]interface{}{
+			"name":        p.Name,
+			"in":          in,
+			"required":    p.Required,
+			"description": p.Description,
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	if params != nil {
+		out["parameters"] = params
+	}
+
+	if op.Body != nil {
+		out["requestBody"] = map[string]interface{}{"required": true, "content": g.content(op.Body)}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if op.Result != nil {
+		success["content"] = g.content(op.Result)
+	}
+	out["responses"] = map[string]interface{}{
+		strconv.Itoa(status): success,
+		"default": map[string]interface{}{
+			"description": "Error",
+			"content":     g.content(errorResponse{}),
+		},
+	}
+	return out
+}
+
+// content describes a body of v's type
+func (g *schemaGen) content(v interface{}) map[string]interface{} {
+	if _, ok := v.(rawBody); ok {
+		return map[string]interface{}{
+			"application/octet-stream": map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": g.valueSchema(v)},
+	}
+}
+
+// operationID derives a stable identifier such as postAdminTenantsBatch
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// routeTag groups operations by their first path segment, or the first two
+// for the admin API
+func routeTag(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case parts[0] == "admin" && len(parts) > 1:
+		return "admin/" + parts[1]
+	case parts[0] == "upload", parts[0] == "download", parts[0] == "copy", parts[0] == "rename",
+		parts[0] == "list", parts[0] == "stat", parts[0] == "kv":
+		return "objects"
+	default:
+		return parts[0]
+	}
+}
+
+// schemaGen reflects Go types into OpenAPI schemas, naming each struct
+// once under components/schemas
+type schemaGen struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// valueSchema describes v, expanding shapes property by property
+func (g *schemaGen) valueSchema(v interface{}) map[string]interface{} {
+	if sh, ok := v.(shape); ok {
+		props := make(map[string]interface{}, len(sh))
+		for name, sample := range sh {
+			props[name] = g.valueSchema(sample)
+		}
+		return map[string]interface{}{"type": "object", "properties": props}
+	}
+	if v == nil {
+		return map[string]interface{}{}
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			props := make(map[string]interface{})
+			g.fields(t, props)
+			return map[string]interface{}{"type": "object", "properties": props}
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + g.define(t)}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// define registers struct t under components/schemas, returning its name
+func (g *schemaGen) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.schemas[name] = map[string]interface{}{} // Placeholder for recursive types
+
+	props := make(map[string]interface{})
+	g.fields(t, props)
+	g.schemas[name] = map[string]interface{}{"type": "object", "properties": props}
+	return name
+}
+
+// fields adds t's JSON-encoded fields to props, flattening embedded structs
+// the way encoding/json does
+func (g *schemaGen) fields(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() || isUnencodable(f.Type) {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
+
+// isUnencodable reports types encoding/json cannot marshal
+func isUnencodable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON map[string]interface{}
+)
+
+// handleAdminOpenAPI serves the generated OpenAPI document
+func (s *MinIOServer) handleAdminOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	openAPIOnce.Do(func() { openAPIJSON = openAPIDoc(s.apiRoutes()) })
+	writeJSON(w, http.StatusOK, openAPIJSON)
+}
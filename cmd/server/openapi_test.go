@@ -0,0 +1,182 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The served document has an operation for every method of every route
+// the server registers, each with a distinct ID, and every schema it
+// references is defined
+func TestOpenAPIRoutes(t *testing.T) {
+	w := do(t, "GET", "/v1/admin/openapi.json", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var doc struct {
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	decode(t, w, &doc)
+
+	ids := make(map[string]string)
+	for _, rt := range testServer.apiRoutes() {
+		if len(rt.Ops) == 0 {
+			t.Errorf("Route %s is undocumented", rt.Path)
+			continue
+		}
+		item, ok := doc.Paths[apiV1+rt.Path]
+		if !ok {
+			t.Errorf("Route %s missing from the document", rt.Path)
+			continue
+		}
+		for _, op := range rt.Ops {
+			got, ok := item[strings.ToLower(op.Method)]
+			if !ok {
+				t.Errorf("%s %s missing from the document", op.Method, rt.Path)
+				continue
+			}
+			id, _ := got["operationId"].(string)
+			if prev, dup := ids[id]; dup {
+				t.Errorf("Operation ID %q used by %s and %s %s", id, prev, op.Method, rt.Path)
+			}
+			ids[id] = op.Method + " " + rt.Path
+			if (rt.Deprecated != nil) != (got["deprecated"] == true) {
+				t.Errorf("%s %s deprecated %v in the document", op.Method, rt.Path, got["deprecated"])
+			}
+		}
+	}
+	if n := len(doc.Paths); n != len(testServer.apiRoutes()) {
+		t.Errorf("Document has %d paths, want one per route", n)
+	}
+
+	// Every reference resolves
+	var refs func(v interface{})
+	refs = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := doc.Components.Schemas[name]; !ok {
+					t.Errorf("Reference %s is not defined", ref)
+				}
+			}
+			for _, e := range v {
+				refs(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				refs(e)
+			}
+		}
+	}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			refs(op)
+		}
+	}
+	refs(doc.Components.Schemas)
+}
+
+type openAPIEmbedded struct {
+	Region string `json:"region"`
+}
+
+type openAPIItem struct {
+	Name string `json:"name"`
+}
+
+type openAPIBody struct {
+	openAPIEmbedded
+	ID       string            `json:"id"`
+	Size     int64             `json:"size,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  bool              `json:"enabled"`
+	Created  time.Time         `json:"created"`
+	Expires  *time.Time        `json:"expires,omitempty"`
+	Data     []byte            `json:"data"`
+	Tags     map[string]string `json:"tags"`
+	Items    []openAPIItem     `json:"items"`
+	Parent   *openAPIBody      `json:"parent,omitempty"`
+	Untagged string
+	Secret   string `json:"-"`
+	Callback func() `json:"callback"`
+	internal string
+}
+
+// Schemas follow encoding/json: embedded fields are flattened, tag names
+// are used, and skipped or unencodable fields are left out
+func TestOpenAPISchema(t *testing.T) {
+	doc := openAPIDoc([]apiRoute{{Path: "/things", Ops: []apiOp{
+		{Method: http.MethodPost, Summary: "Create a thing", Params: []apiParam{paramKey, {Name: "X-Trace", In: "header"}},
+			Body: openAPIBody{}, Result: shape{"id": "", "count": 0}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Summary: "Fetch a thing", Result: rawBody{}},
+	}}})
+	op := doc["paths"].(map[string]interface{})["/v1/things"].(map[string]interface{})
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	typ := func(name string) map[string]interface{} { return map[string]interface{}{"type": name} }
+
+	want := map[string]interface{}{"type": "object", "properties": map[string]interface{}{
+		"region":   typ("string"),
+		"id":       typ("string"),
+		"size":     typ("integer"),
+		"ratio":    typ("number"),
+		"enabled":  typ("boolean"),
+		"created":  map[string]interface{}{"type": "string", "format": "date-time"},
+		"expires":  map[string]interface{}{"type": "string", "format": "date-time"},
+		"data":     map[string]interface{}{"type": "string", "format": "byte"},
+		"tags":     map[string]interface{}{"type": "object", "additionalProperties": typ("string")},
+		"items":    map[string]interface{}{"type": "array", "items": ref("OpenAPIItem")},
+		"parent":   ref("OpenAPIBody"),
+		"Untagged": typ("string"),
+	}}
+	if got := schemas["OpenAPIBody"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenAPIBody schema %v, want %v", got, want)
+	}
+	if got := schemas["OpenAPIItem"]; !reflect.DeepEqual(got, map[string]interface{}{"type": "object",
+		"properties": map[string]interface{}{"name": typ("string")}}) {
+		t.Errorf("OpenAPIItem schema %v", got)
+	}
+
+	post := op["post"].(map[string]interface{})
+	if post["operationId"] != "postThings" || !reflect.DeepEqual(post["tags"], []string{"things"}) {
+		t.Errorf("Operation ID %v, tags %v", post["operationId"], post["tags"])
+	}
+	params := post["parameters"].([]interface{})
+	if len(params) != 2 || params[0].(map[string]interface{})["in"] != "query" ||
+		params[0].(map[string]interface{})["required"] != true || params[1].(map[string]interface{})["in"] != "header" {
+		t.Errorf("Parameters %v", params)
+	}
+	body := post["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"]
+	if !reflect.DeepEqual(body, map[string]interface{}{"schema": ref("OpenAPIBody")}) {
+		t.Errorf("Request body %v", body)
+	}
+	responses := post["responses"].(map[string]interface{})
+	created := responses["201"].(map[string]interface{})["content"].(map[string]interface{})["application/json"]
+	if !reflect.DeepEqual(created, map[string]interface{}{"schema": map[string]interface{}{"type": "object",
+		"properties": map[string]interface{}{"id": typ("string"), "count": typ("integer")}}}) {
+		t.Errorf("Created response %v", created)
+	}
+	if _, ok := responses["default"]; !ok {
+		t.Error("No error response")
+	}
+	if _, ok := schemas["ErrorResponse"]; !ok {
+		t.Error("Error response schema not defined")
+	}
+
+	get := op["get"].(map[string]interface{})
+	raw := get["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"]
+	if !reflect.DeepEqual(raw, map[string]interface{}{"application/octet-stream": map[string]interface{}{
+		"schema": map[string]interface{}{"type": "string", "format": "binary"}}}) {
+		t.Errorf("Object data response %v", raw)
+	}
+	if _, ok := get["parameters"]; ok {
+		t.Error("Parameters on an operation without any")
+	}
+}
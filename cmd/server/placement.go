@@ -0,0 +1,82 @@
+// cmd/server/placement.go
+// Tier placement: the tenant hints the cache's placement policy needs for
+// each write, reads fed to learning policies, and optional trace recording
+// for evaluating policies and engines offline with placement-sim and
+// cache-replay
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/minio/enterprise/internal/cache"
+)
+
+// openPlacementTrace opens config.PlacementTrace for appending trace
+// events; tracing is a diagnostic, so a file that cannot be opened only
+// disables it
+func openPlacementTrace(config *ServerConfig) (*cache.TraceWriter, *os.File) {
+	if config.PlacementTrace == "" {
+		return nil, nil
+	}
+
+	var anon *cache.TraceAnonymizer
+	if !config.PlacementTraceRaw {
+		var err error
+		if anon, err = cache.NewTraceAnonymizer([]byte(config.PlacementTraceSecret)); err != nil {
+			log.Printf("Warning: placement trace disabled: %v", err)
+			return nil, nil
+		}
+	}
+
+	f, err := os.OpenFile(config.PlacementTrace, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		log.Printf("Warning: placement trace disabled: %v", err)
+		return nil, nil
+	}
+	return cache.NewTraceWriter(f, anon), f
+}
+
+// placementContext attaches the tenant and its plan to ctx for the cache's
+// placement policy, recording the write when tracing
+func (s *MinIOServer) placementContext(ctx context.Context, tenantID, key string, size int) context.Context {
+	plan, _ := s.tenantManager.TenantPlan(ctx, tenantID)
+	s.observeWrite(tenantID, plan, key, size)
+	return cache.WithPlacementHints(ctx, cache.PlacementHints{TenantID: tenantID, TenantClass: plan})
+}
+
+// observeWrite records a write of size bytes when tracing. Inlined objects
+// never reach the cache but are recorded too, so the trace holds every
+// object its reads refer to.
+func (s *MinIOServer) observeWrite(tenantID, plan, key string, size int) {
+	if s.placementTrace != nil {
+		s.placementTrace.Record(cache.TraceEvent{Time: time.Now().UTC(), Op: cache.TracePut,
+			TenantID: tenantID, TenantClass: plan, Key: key, Size: int64(size)})
+	}
+}
+
+// observeRead counts a download of class that served bytes of an object of
+// size in the access statistics, teaches a learning placement policy, and records
+// the read when tracing
+func (s *MinIOServer) observeRead(tenantID, key, class string, size, served int64) {
+	now := time.Now()
+	s.accessStats.Record(tenantID, key, served)
+	s.recordRequest(tenantID, key, class, 0, served)
+	if learner, ok := s.placement.(cache.PlacementLearner); ok {
+		learner.Observe(tenantID, key, now)
+	}
+	if s.placementTrace != nil {
+		s.placementTrace.Record(cache.TraceEvent{Time: now.UTC(), Op: cache.TraceGet,
+			TenantID: tenantID, Key: key, Size: size})
+	}
+}
+
+// observeDelete records the removal of an object of size when tracing
+func (s *MinIOServer) observeDelete(tenantID, key string, size int64) {
+	if s.placementTrace != nil {
+		s.placementTrace.Record(cache.TraceEvent{Time: time.Now().UTC(), Op: cache.TraceDelete,
+			TenantID: tenantID, Key: key, Size: size})
+	}
+}
@@ -0,0 +1,209 @@
+// cmd/server/public.go
+// Anonymous reads of public buckets. Buckets configured public_read serve
+// GET and HEAD on /public to anyone, rate limited per client address
+// instead of against the tenant, and, with referer_tokens, only to pages
+// on a host the tenant issued a referer token for. Public traffic is
+// counted and billed apart from the tenant's own reads.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// defaultRefererTokenTTL is the lifetime of referer tokens issued without
+// expires_in
+const defaultRefererTokenTTL = 30 * 24 * time.Hour
+
+// publicKey marks the context of an anonymous public read
+type publicKey struct{}
+
+func publicRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, publicKey{}, true)
+}
+
+// isPublicRead reports whether ctx is an anonymous public read's
+func isPublicRead(ctx context.Context) bool {
+	return ctx.Value(publicKey{}) != nil
+}
+
+// refererTokenRequest is the body of a referer token request
+type refererTokenRequest struct {
+	Bucket    string `json:"bucket"`
+	Referer   string `json:"referer"`    // Host name or URL of the linking site
+	ExpiresIn string `json:"expires_in"` // Go duration, e.g. "720h"
+}
+
+// refererTokenResponse is an issued referer token
+type refererTokenResponse struct {
+	Token     string    `json:"token"`
+	TenantID  string    `json:"tenant_id"`
+	Bucket    string    `json:"bucket"`
+	Referer   string    `json:"referer"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// parseTrustedProxies parses addresses and CIDR prefixes, logging and
+// skipping invalid ones
+func parseTrustedProxies(items []string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, item := range items {
+		if p, err := netip.ParsePrefix(item); err == nil {
+			out = append(out, p.Masked())
+		} else if a, err := netip.ParseAddr(item); err == nil {
+			out = append(out, netip.PrefixFrom(a, a.BitLen()))
+		} else {
+			log.Printf("Ignoring invalid trusted proxy %q", item)
+		}
+	}
+	return out
+}
+
+// clientAddr returns the address r came from. Connections from trusted
+// proxies are attributed to the last address in X-Forwarded-For that is
+// not itself a trusted proxy.
+func (s *MinIOServer) clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	trusted := func(addr string) bool {
+		a, err := netip.ParseAddr(addr)
+		if err != nil {
+			return false
+		}
+		for _, p := range s.trustedProxies {
+			if p.Contains(a.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+	if !trusted(host) {
+		return host
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !trusted(hop) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
+
+// handlePublic serves GET and HEAD ?key= anonymously when the key's bucket
+// is public_read, as /download would, charging the tenant's egress. A
+// bucket with referer_tokens also needs ?token= (or X-Referer-Token)
+// issued for the host of the request's Referer.
+func (s *MinIOServer) handlePublic(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tenantID, key := tenantFromRequest(r), r.URL.Query().Get("key")
+	if tenantID == "" || key == "" {
+		writeErrorMessage(w, r, "Missing tenant ID or key", http.StatusBadRequest)
+		return
+	}
+
+	// Missing tenants and buckets look private, so they cannot be probed
+	b, ok := s.bucketFor(ctx, tenantID, key)
+	if !ok || !b.Config.PublicRead {
+		writeError(w, r, errNotPublic)
+		return
+	}
+	if b.Config.RefererTokens {
+		token := r.Header.Get("X-Referer-Token")
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if _, err := s.tenantManager.VerifyRefererToken(ctx, token, tenantID, b.Name, r.Referer()); err != nil {
+			s.publicRefererDenied.Add(1)
+			writeError(w, r, &httpError{http.StatusForbidden, codeAccessDenied, "Referer token required: " + err.Error()})
+			return
+		}
+	}
+	s.publicRequests.Add(1)
+
+	// Whatever credentials came with the request, it is served as
+	// anonymous, and only ever the object the key names
+	pr := r.Clone(publicRead(ctx))
+	pr.Header.Del("Authorization")
+	q := pr.URL.Query()
+	q.Del("share")
+	q.Del("password")
+	pr.URL.RawQuery = q.Encode()
+	s.handleDownload(w, pr)
+}
+
+// handleRefererTokens issues a referer token (POST) for one of the
+// tenant's public buckets. Needs tenant credentials.
+func (s *MinIOServer) handleRefererTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantCredentials(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req refererTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	ttl := defaultRefererTokenTTL
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			writeErrorMessage(w, r, "Invalid expires_in", http.StatusBadRequest)
+			return
+		}
+	}
+
+	token, claims, err := s.tenantManager.IssueRefererToken(ctx, tenantID, req.Bucket, req.Referer, ttl)
+	ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "public.token", Resource: req.Bucket,
+		Details: map[string]string{"referer": req.Referer}}
+	if err != nil {
+		ev.Outcome = audit.OutcomeError
+		ev.Details["error"] = err.Error()
+	}
+	s.logAudit(ctx, ev)
+	if errors.Is(err, tenant.ErrBucketNotFound) {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, refererTokenResponse{Token: token, TenantID: tenantID, Bucket: claims.Bucket,
+		Referer: claims.Referer, ExpiresAt: claims.ExpiresAt})
+}
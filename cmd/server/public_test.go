@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// from sends a request from addr, through a proxy if forwarded is set
+func from(addr, forwarded string) credential {
+	return func(r *http.Request) {
+		r.RemoteAddr = addr + ":40000"
+		if forwarded != "" {
+			r.Header.Set("X-Forwarded-For", forwarded)
+		}
+	}
+}
+
+// createBucket creates name in tenantID as the admin
+func createBucket(t *testing.T, tenantID, name string, config tenant.BucketConfig) {
+	t.Helper()
+	w := do(t, "POST", "/v1/buckets?tenant_id="+tenantID, createBucketRequest{Name: name, Config: config}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+}
+
+// Public reads serve only public_read buckets, and with referer_tokens
+// only to the pages a token was issued for
+func TestPublicRead(t *testing.T) {
+	tenantID := newTenant(t)
+	createBucket(t, tenantID, "pub", tenant.BucketConfig{PublicRead: true})
+	createBucket(t, tenantID, "priv", tenant.BucketConfig{})
+	createBucket(t, tenantID, "site", tenant.BucketConfig{PublicRead: true, RefererTokens: true})
+	for _, key := range []string{"pub/a.txt", "priv/a.txt", "site/a.txt", "a.txt"} {
+		upload(t, tenantID, key, "hello")
+	}
+	public := "/v1/public?tenant_id=" + tenantID + "&key="
+	client := from("198.51.100.1", "")
+	testServer.tenantManager.SetPublicRateLimit(0)
+	defer testServer.tenantManager.SetPublicRateLimit(testServer.config.PublicRateLimit)
+
+	w := do(t, "GET", public+"pub/a.txt", nil, client)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "hello" {
+		t.Errorf("Served %q, want hello", w.Body.String())
+	}
+	// Credentials for another tenant do not widen a public read
+	w = do(t, "GET", public+"priv/a.txt", nil, client, bearer(tenantToken(t, tenantID, "alice", "*")))
+	expectStatus(t, w, http.StatusForbidden)
+	for _, key := range []string{"priv/a.txt", "none/a.txt", "a.txt"} {
+		w = do(t, "GET", public+key, nil, client)
+		expectStatus(t, w, http.StatusForbidden)
+	}
+	w = do(t, "GET", "/v1/public?tenant_id=unknown&key=pub/a.txt", nil, client)
+	expectStatus(t, w, http.StatusForbidden)
+
+	tokens := "/v1/public/tokens?tenant_id=" + tenantID
+	w = do(t, "POST", tokens, refererTokenRequest{Bucket: "site", Referer: "example.org"})
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "POST", tokens, refererTokenRequest{Bucket: "priv", Referer: "example.org"}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	issue := func(bucket string) string {
+		t.Helper()
+		w := do(t, "POST", tokens, refererTokenRequest{Bucket: bucket, Referer: "https://example.org/page"}, adminAuth)
+		expectStatus(t, w, http.StatusCreated)
+		var resp refererTokenResponse
+		if decode(t, w, &resp); resp.Referer != "example.org" {
+			t.Errorf("Token for referer %q, want example.org", resp.Referer)
+		}
+		return resp.Token
+	}
+	token, pubToken := issue("site"), issue("pub")
+
+	denied := testServer.publicRefererDenied.Load()
+	referer := func(page string) credential {
+		return func(r *http.Request) {
+			if page != "" {
+				r.Header.Set("Referer", page)
+			}
+		}
+	}
+	page := "https://example.org/page"
+	for _, tc := range []struct{ name, query, page string }{
+		{"no token", "", page},
+		{"wrong token", "&token=mr1.bogus.sig", page},
+		{"tampered token", "&token=" + token + "x", page},
+		{"other bucket's token", "&token=" + pubToken, page},
+		{"wrong referer", "&token=" + token, "https://evil.example/page"},
+		{"no referer", "&token=" + token, ""},
+	} {
+		if w := do(t, "GET", public+"site/a.txt"+tc.query, nil, client, referer(tc.page)); w.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", tc.name, w.Code)
+		}
+	}
+	if n := testServer.publicRefererDenied.Load() - denied; n != 6 {
+		t.Errorf("%d referer denials counted, want 6", n)
+	}
+	w = do(t, "GET", public+"site/a.txt&token="+token, nil, client, referer("https://example.org/other"))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", public+"site/a.txt", nil, client, referer("https://example.org/"),
+		func(r *http.Request) { r.Header.Set("X-Referer-Token", token) })
+	expectStatus(t, w, http.StatusOK)
+}
+
+// Public reads are limited per client address, which X-Forwarded-For
+// gives only when the request comes from a trusted proxy
+func TestPublicRateLimit(t *testing.T) {
+	tenantID := newTenant(t)
+	createBucket(t, tenantID, "pub", tenant.BucketConfig{PublicRead: true})
+	upload(t, tenantID, "pub/a.txt", "hello")
+	public := "/v1/public?tenant_id=" + tenantID + "&key=pub/a.txt"
+
+	testServer.tenantManager.SetPublicRateLimit(1)
+	testServer.trustedProxies = parseTrustedProxies([]string{"10.0.0.0/8"})
+	defer func() {
+		testServer.tenantManager.SetPublicRateLimit(testServer.config.PublicRateLimit)
+		testServer.trustedProxies = parseTrustedProxies(testServer.config.TrustedProxies)
+	}()
+
+	// exhaust spends client's burst, returning the refusal
+	exhaust := func(client credential) *http.Response {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if w := do(t, "GET", public, nil, client); w.Code != http.StatusOK {
+				expectStatus(t, w, http.StatusTooManyRequests)
+				return w.Result()
+			}
+		}
+		t.Fatal("No request refused under a limit of 1 per second")
+		return nil
+	}
+	// Addresses of their own, as buckets outlive a run
+	run := time.Now().UnixNano() / int64(time.Millisecond) % 250
+	addr := func(client int) string { return fmt.Sprintf("198.18.%d.%d", run, client) }
+
+	direct := addr(1)
+	refused := exhaust(from(direct, ""))
+	if refused.Header.Get("Retry-After") == "" || refused.Header.Get("X-RateLimit-Limit") != "1" {
+		t.Errorf("Refused with headers %v, want Retry-After and the limit", refused.Header)
+	}
+	// Another address has a bucket of its own, which an untrusted peer
+	// cannot claim by forwarding
+	w := do(t, "GET", public, nil, from(addr(2), ""))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", public, nil, from(direct, addr(3)))
+	expectStatus(t, w, http.StatusTooManyRequests)
+
+	// Behind a trusted proxy each forwarded client is limited apart, and
+	// the proxy's own address is not spent
+	exhaust(from("10.0.0.1", addr(4)))
+	w = do(t, "GET", public, nil, from("10.0.0.1", addr(5)))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", public, nil, from("10.0.0.1", addr(4)+", 10.0.0.2"))
+	expectStatus(t, w, http.StatusTooManyRequests)
+}
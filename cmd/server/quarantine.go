@@ -0,0 +1,307 @@
+// cmd/server/quarantine.go
+// Quarantine of uploads that failed the malware scan. Every detection is
+// recorded; when MINIO_QUARANTINE_TENANT is set the object itself is held
+// there under <tenant>/<record ID> until an admin releases it to its
+// original key or deletes it. The owning tenant is notified by webhook at
+// each step.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/notify"
+	"github.com/minio/enterprise/internal/scan"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// Quarantine record statuses
+const (
+	QuarantineHeld      = "held"      // Object kept in the quarantine tenant
+	QuarantineDiscarded = "discarded" // Refused without keeping the object
+	QuarantineReleased  = "released"  // Written to its original key by an admin
+	QuarantineDeleted   = "deleted"   // Held copy deleted by an admin
+)
+
+// quarantineRecord describes one detection
+type quarantineRecord struct {
+	ID         string    `json:"id"`
+	Tenant     string    `json:"tenant"`
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"`
+	Signature  string    `json:"signature,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	DetectedAt time.Time `json:"detected_at"`
+	Status     string    `json:"status"`
+
+	// Location is the held copy, as quarantine-tenant/key
+	Location   string    `json:"location,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at,omitempty"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// quarantineStore persists records as one JSON file each
+type quarantineStore struct {
+	dir string
+
+	mu      sync.Mutex
+	records map[string]*quarantineRecord
+
+	// reviewMu serializes admin releases and deletes
+	reviewMu sync.Mutex
+}
+
+// openQuarantineStore loads the records under dir
+func openQuarantineStore(dir string) (*quarantineStore, error) {
+	q := &quarantineStore{dir: dir, records: make(map[string]*quarantineRecord)}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var rec quarantineRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			log.Printf("Warning: skipping corrupt quarantine record %s: %v", e.Name(), err)
+			continue
+		}
+		q.records[rec.ID] = &rec
+	}
+	return q, nil
+}
+
+// save writes rec and makes it current
+func (q *quarantineStore) save(rec quarantineRecord) error {
+	if err := os.MkdirAll(q.dir, 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(q.dir, rec.ID+".json"), data, 0o640); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	q.records[rec.ID] = &rec
+	q.mu.Unlock()
+	return nil
+}
+
+func (q *quarantineStore) get(id string) (quarantineRecord, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rec, ok := q.records[id]
+	if !ok {
+		return quarantineRecord{}, false
+	}
+	return *rec, true
+}
+
+// list returns records, optionally for one tenant and status, oldest first
+func (q *quarantineStore) list(tenantID, status string) []quarantineRecord {
+	q.mu.Lock()
+	out := make([]quarantineRecord, 0, len(q.records))
+	for _, rec := range q.records {
+		if (tenantID == "" || rec.Tenant == tenantID) && (status == "" || rec.Status == status) {
+			out = append(out, *rec)
+		}
+	}
+	q.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].DetectedAt.Before(out[j].DetectedAt) })
+	return out
+}
+
+// counts returns the number of records in each status
+func (q *quarantineStore) counts() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := map[string]int{QuarantineHeld: 0, QuarantineDiscarded: 0, QuarantineReleased: 0, QuarantineDeleted: 0}
+	for _, rec := range q.records {
+		out[rec.Status]++
+	}
+	return out
+}
+
+// quarantineUpload records an infected upload, holds the object in the
+// quarantine tenant if one is configured, and notifies the tenant
+func (s *MinIOServer) quarantineUpload(ctx context.Context, tenantID, key string, data []byte, attrs metadata.Attributes, verdict scan.Verdict) {
+	rec := quarantineRecord{
+		ID:         "q-" + newVersionID(),
+		Tenant:     tenantID,
+		Key:        key,
+		Size:       int64(len(data)),
+		Signature:  verdict.Signature,
+		RequestID:  tracing.RequestID(ctx),
+		DetectedAt: time.Now().UTC(),
+		Status:     QuarantineDiscarded,
+	}
+	if qt := s.config.QuarantineTenant; qt != "" && qt != tenantID {
+		qkey := quarantineKey(tenantID, rec.ID)
+		if _, err := s.putObjectIf(ctx, qt, qkey, data, writeCondition{}, attrs); err != nil {
+			log.Printf("Failed to quarantine %s/%s: %v", tenantID, key, err)
+			rec.Note = "not held: " + err.Error()
+		} else {
+			rec.Status, rec.Location = QuarantineHeld, qt+"/"+qkey
+		}
+	}
+	if err := s.quarantine.save(rec); err != nil {
+		log.Printf("Failed to record quarantine of %s/%s: %v", tenantID, key, err)
+	}
+
+	s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.quarantine", Resource: key,
+		Outcome: audit.OutcomeDenied, Details: map[string]string{"id": rec.ID, "signature": rec.Signature, "status": rec.Status}})
+	s.emitTenantEvent(notify.EventObjectQuarantined, tenantID, quarantineEventData(rec))
+}
+
+// quarantineKey is where a held object is kept in the quarantine tenant
+func quarantineKey(tenantID, id string) string {
+	return tenantID + "/" + id
+}
+
+func quarantineEventData(rec quarantineRecord) map[string]string {
+	return map[string]string{
+		"id":        rec.ID,
+		"key":       rec.Key,
+		"size":      strconv.FormatInt(rec.Size, 10),
+		"signature": rec.Signature,
+		"status":    rec.Status,
+	}
+}
+
+// heldQuarantine returns the held record with id and where its copy is
+func (s *MinIOServer) heldQuarantine(id string) (rec quarantineRecord, qtenant, qkey string, err error) {
+	rec, ok := s.quarantine.get(id)
+	if !ok {
+		return rec, "", "", &httpError{Status: http.StatusNotFound, Message: "Quarantine record not found"}
+	}
+	if rec.Status != QuarantineHeld {
+		return rec, "", "", &httpError{Status: http.StatusConflict, Message: "Quarantined object is " + rec.Status}
+	}
+	qtenant, qkey, _ = strings.Cut(rec.Location, "/")
+	return rec, qtenant, qkey, nil
+}
+
+// releaseQuarantine writes a held object to its original key, bypassing the
+// scan, and removes the held copy
+func (s *MinIOServer) releaseQuarantine(ctx context.Context, id, note string) (quarantineRecord, error) {
+	s.quarantine.reviewMu.Lock()
+	defer s.quarantine.reviewMu.Unlock()
+
+	rec, qtenant, qkey, err := s.heldQuarantine(id)
+	if err != nil {
+		return rec, err
+	}
+	if err := s.checkWritable(); err != nil {
+		return rec, err
+	}
+
+	data, held, err := s.getObject(ctx, qtenant, qkey)
+	if err != nil {
+		return rec, err
+	}
+	if _, err := s.putObjectIf(ctx, rec.Tenant, rec.Key, data, writeCondition{}, held.Attributes); err != nil {
+		return rec, err
+	}
+	if err := s.deleteThrough(ctx, qtenant, qkey); err != nil {
+		log.Printf("Released %s but failed to remove the held copy: %v", rec.ID, err)
+	}
+
+	rec.Status, rec.ReviewedAt, rec.Note = QuarantineReleased, time.Now().UTC(), note
+	return rec, s.quarantine.save(rec)
+}
+
+// deleteQuarantine deletes a held object for good
+func (s *MinIOServer) deleteQuarantine(ctx context.Context, id, note string) (quarantineRecord, error) {
+	s.quarantine.reviewMu.Lock()
+	defer s.quarantine.reviewMu.Unlock()
+
+	rec, qtenant, qkey, err := s.heldQuarantine(id)
+	if err != nil {
+		return rec, err
+	}
+	if err := s.deleteThrough(ctx, qtenant, qkey); err != nil && err != errObjectNotFound {
+		return rec, err
+	}
+
+	rec.Status, rec.ReviewedAt, rec.Note = QuarantineDeleted, time.Now().UTC(), note
+	return rec, s.quarantine.save(rec)
+}
+
+// handleAdminQuarantine lists detections (GET ?tenant=&status=) or deletes a
+// held object (DELETE ?id=&note=)
+func (s *MinIOServer) handleAdminQuarantine(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.quarantine.list(query.Get("tenant"), query.Get("status")))
+
+	case http.MethodDelete:
+		rec, err := s.deleteQuarantine(ctx, query.Get("id"), query.Get("note"))
+		s.auditQuarantineReview(ctx, "quarantine.delete", rec, err)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		s.emitTenantEvent(notify.EventObjectQuarantineDeleted, rec.Tenant, quarantineEventData(rec))
+		writeJSON(w, http.StatusOK, rec)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminQuarantineRelease releases a held object: POST ?id=&note=
+func (s *MinIOServer) handleAdminQuarantineRelease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	rec, err := s.releaseQuarantine(ctx, query.Get("id"), query.Get("note"))
+	s.auditQuarantineReview(ctx, "quarantine.release", rec, err)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.emitTenantEvent(notify.EventObjectReleased, rec.Tenant, quarantineEventData(rec))
+	writeJSON(w, http.StatusOK, rec)
+}
+
+func (s *MinIOServer) auditQuarantineReview(ctx context.Context, action string, rec quarantineRecord, err error) {
+	ev := audit.Event{TenantID: rec.Tenant, Actor: "admin", Action: action, Resource: rec.Key,
+		Details: map[string]string{"id": rec.ID}}
+	if err != nil {
+		ev.Outcome = audit.OutcomeError
+		ev.Details["error"] = err.Error()
+	}
+	s.logAudit(ctx, ev)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/scan"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// scannerFunc adapts a function to scan.Scanner
+type scannerFunc func(data []byte) scan.Verdict
+
+func (f scannerFunc) Scan(_ context.Context, data []byte) (scan.Verdict, error) {
+	return f(data), nil
+}
+
+// Infected uploads are held in the quarantine tenant until an admin
+// releases them to their key or deletes them
+func TestAdminQuarantine(t *testing.T) {
+	ctx := context.Background()
+	tenantID, holdID := newTenant(t), newTenant(t)
+	if err := testServer.tenantManager.UpdateSettings(ctx, tenantID, tenant.TenantSettings{ScanUploads: true}); err != nil {
+		t.Fatal(err)
+	}
+	scanner, qt := testServer.scanner, testServer.config.QuarantineTenant
+	testServer.scanner = scannerFunc(func(data []byte) scan.Verdict {
+		return scan.Verdict{Infected: strings.HasPrefix(string(data), "virus"), Signature: "Test.Sig"}
+	})
+	testServer.config.QuarantineTenant = holdID
+	defer func() { testServer.scanner, testServer.config.QuarantineTenant = scanner, qt }()
+
+	upload(t, tenantID, "clean.txt", "clean")
+	for _, key := range []string{"a.bin", "b.bin"} {
+		w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key="+key, "virus "+key, adminAuth)
+		expectStatus(t, w, http.StatusUnprocessableEntity)
+	}
+
+	w := do(t, "GET", "/v1/admin/quarantine?tenant="+tenantID, nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "GET", "/v1/admin/quarantine?tenant="+tenantID+"&status="+QuarantineHeld, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var held []quarantineRecord
+	decode(t, w, &held)
+	if len(held) != 2 || held[0].Key != "a.bin" || held[1].Key != "b.bin" || held[0].Signature != "Test.Sig" {
+		t.Fatalf("Held %+v, want a.bin and b.bin", held)
+	}
+	if _, err := testServer.index.Get(holdID, quarantineKey(tenantID, held[0].ID)); err != nil {
+		t.Errorf("Held copy missing: %v", err)
+	}
+
+	w = do(t, "POST", "/v1/admin/quarantine/release?id="+held[0].ID+"&note=false+positive", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var rec quarantineRecord
+	if decode(t, w, &rec); rec.Status != QuarantineReleased || rec.Note != "false positive" {
+		t.Errorf("Released %+v", rec)
+	}
+	w = do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=a.bin", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "virus a.bin" {
+		t.Errorf("Released object %q", w.Body.String())
+	}
+	if _, err := testServer.index.Get(holdID, quarantineKey(tenantID, held[0].ID)); err == nil {
+		t.Error("Held copy kept after release")
+	}
+	w = do(t, "POST", "/v1/admin/quarantine/release?id="+held[0].ID, nil, adminAuth)
+	expectStatus(t, w, http.StatusConflict)
+
+	w = do(t, "DELETE", "/v1/admin/quarantine?id="+held[1].ID, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &rec); rec.Status != QuarantineDeleted {
+		t.Errorf("Deleted %+v", rec)
+	}
+	w = do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=b.bin", nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+	w = do(t, "DELETE", "/v1/admin/quarantine?id=q-unknown", nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+
+	w = do(t, "GET", "/v1/admin/quarantine?tenant="+tenantID+"&status="+QuarantineHeld, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &held); len(held) != 0 {
+		t.Errorf("Still held: %+v", held)
+	}
+	if body := scrape(t); !strings.Contains(body, `quarantine_records{status="released"}`) {
+		t.Error("No quarantine_records metric")
+	}
+}
@@ -0,0 +1,493 @@
+// cmd/server/ranges.go
+// Ranged downloads. Range may name several spans, answered as
+// multipart/byteranges, and If-Range falls back to the whole object once
+// it has changed. Spans of objects held unencrypted in the cache tiers are
+// copied straight out of the cache. Other objects are read whole (and
+// decrypted) for every range, so HEAD /download advertises a chunk size for
+// clients to align parallel ranges to, and once ranged reads of an object
+// are seen walking forward its plaintext is kept in a bounded readahead
+// buffer so the following ranges are served from memory.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/tenant"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// readaheadIdle is how long a buffered object outlives its last range read
+const readaheadIdle = 30 * time.Second
+
+var errRangeNotSatisfiable = &httpError{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "Requested range not satisfiable"}
+
+// maxRanges is the most ranges one request may ask for; a longer header
+// is ignored and the whole object served
+const maxRanges = 64
+
+// byteRange is an inclusive span of an object
+type byteRange struct {
+	start, end int64
+}
+
+func (br byteRange) length() int64 {
+	return br.end - br.start + 1
+}
+
+// contentRange is br's Content-Range in an object of size bytes
+func (br byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", br.start, br.end, size)
+}
+
+// parseRanges reads a "bytes=" header of one or more ranges against an
+// object of size bytes, returning the satisfiable spans in order with
+// overlapping and adjacent ones merged. ok is false for headers that are
+// ignored (other units, malformed or too many ranges), which serve the
+// whole object; err is set when no range overlaps the object.
+func parseRanges(header string, size int64) (ranges []byteRange, ok bool, err error) {
+	set, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		return nil, false, nil
+	}
+	specs := strings.Split(set, ",")
+	if len(specs) > maxRanges {
+		return nil, false, nil
+	}
+	for _, spec := range specs {
+		br, satisfiable, ok := parseRangeSpec(strings.TrimSpace(spec), size)
+		if !ok {
+			return nil, false, nil
+		}
+		if satisfiable {
+			ranges = append(ranges, br)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, false, errRangeNotSatisfiable
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	merged := ranges[:1]
+	for _, br := range ranges[1:] {
+		if last := &merged[len(merged)-1]; br.start <= last.end+1 {
+			last.end = max(last.end, br.end)
+		} else {
+			merged = append(merged, br)
+		}
+	}
+	return merged, true, nil
+}
+
+// parseRangeSpec reads one "first-last" or "-suffix" range. ok is false if
+// it is malformed; satisfiable is false if it lies outside the object.
+func parseRangeSpec(spec string, size int64) (br byteRange, satisfiable, ok bool) {
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return br, false, false
+	}
+
+	if first == "" {
+		// Suffix range: the final n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return br, false, false
+		}
+		if n == 0 || size == 0 {
+			return br, false, true
+		}
+		return byteRange{max(0, size-n), size - 1}, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return br, false, false
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return br, false, false
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return br, false, true
+	}
+	return byteRange{start, end}, true, true
+}
+
+// rangeApplies reports whether a download honors its Range header under
+// If-Range, which must name the object's current ETag or version, or the
+// second it was last modified; otherwise the whole object is served
+func rangeApplies(r *http.Request, meta *metadata.ObjectMeta) bool {
+	cond := r.Header.Get("If-Range")
+	if cond == "" {
+		return true
+	}
+	if tag, found := strings.CutPrefix(cond, `"`); found {
+		tag = strings.TrimSuffix(tag, `"`)
+		return tag == meta.VersionID || (meta.ETag != "" && tag == meta.ETag)
+	}
+	// Weak tags never match
+	t, err := http.ParseTime(cond)
+	return err == nil && meta.ModTime != 0 && time.Unix(0, meta.ModTime).Unix() == t.Unix()
+}
+
+// setLastModified sets the Last-Modified header that If-Range dates compare to
+func setLastModified(h http.Header, meta *metadata.ObjectMeta) {
+	if meta.ModTime != 0 {
+		h.Set("Last-Modified", time.Unix(0, meta.ModTime).UTC().Format(http.TimeFormat))
+	}
+}
+
+// writeRanges answers 206 with ranges of an object of size bytes, each
+// written by read given its index: one range as the body, several as multipart/byteranges
+// parts. Headers other than the body's framing must already be set.
+func writeRanges(w http.ResponseWriter, contentType string, size int64, ranges []byteRange, read func(w io.Writer, i int) error) error {
+	h := w.Header()
+	if len(ranges) == 1 {
+		h.Set("Content-Type", contentType)
+		h.Set("Content-Range", ranges[0].contentRange(size))
+		h.Set("Content-Length", strconv.FormatInt(ranges[0].length(), 10))
+		w.WriteHeader(http.StatusPartialContent)
+		return read(w, 0)
+	}
+
+	boundary := newVersionID() + newVersionID()
+	partHeader := func(i int, br byteRange) string {
+		header := fmt.Sprintf("--%s\r\nContent-Type: %s\r\nContent-Range: %s\r\n\r\n", boundary, contentType, br.contentRange(size))
+		if i > 0 {
+			header = "\r\n" + header
+		}
+		return header
+	}
+	closing := "\r\n--" + boundary + "--\r\n"
+	length := int64(len(closing))
+	for i, br := range ranges {
+		length += int64(len(partHeader(i, br))) + br.length()
+	}
+
+	h.Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	for i, br := range ranges {
+		if _, err := io.WriteString(w, partHeader(i, br)); err != nil {
+			return err
+		}
+		if err := read(w, i); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, closing)
+	return err
+}
+
+// rangedBytes is the number of bytes ranges cover
+func rangedBytes(ranges []byteRange) int64 {
+	var n int64
+	for _, br := range ranges {
+		n += br.length()
+	}
+	return n
+}
+
+// readStream tracks ranged reads of one object version
+type readStream struct {
+	tenant  string
+	data    []byte // Buffered plaintext, nil until reads are seen to continue
+	next    int64  // One past the furthest byte served
+	served  int64
+	lastUse time.Time
+}
+
+// readahead buffers objects being read range by range, up to limit bytes
+type readahead struct {
+	limit int64
+
+	mu        sync.Mutex
+	streams   map[string]*readStream
+	buffered  int64
+	lastSweep time.Time
+
+	hits atomic.Uint64
+}
+
+func newReadahead(limit int64) *readahead {
+	return &readahead{limit: limit, streams: make(map[string]*readStream)}
+}
+
+// readStreamID identifies a version of an object; an overwrite starts a new stream
+func readStreamID(meta *metadata.ObjectMeta) string {
+	return meta.Tenant + "/" + meta.Key + "@" + meta.VersionID
+}
+
+// get returns the buffered plaintext of a stream
+func (ra *readahead) get(id string) ([]byte, bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	st, ok := ra.streams[id]
+	if !ok || st.data == nil {
+		return nil, false
+	}
+	st.lastUse = time.Now()
+	ra.hits.Add(1)
+	return st.data, true
+}
+
+// observe records that bytes [start, end] of data were served. A read that
+// begins within what has already been served marks the stream sequential
+// and its data is buffered; it is dropped once every byte has been served.
+func (ra *readahead) observe(id, tenantID string, data []byte, start, end int64) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(ra.lastSweep) > readaheadIdle {
+		ra.sweep(now)
+	}
+
+	st, ok := ra.streams[id]
+	if !ok {
+		st = &readStream{tenant: tenantID}
+		ra.streams[id] = st
+	}
+	sequential := ok && start > 0 && start <= st.next
+	st.next = max(st.next, end+1)
+	st.served += end - start + 1
+	st.lastUse = now
+
+	if st.served >= int64(len(data)) {
+		ra.drop(id)
+		return
+	}
+	if sequential && st.data == nil && int64(len(data)) <= ra.limit {
+		ra.evict(now, int64(len(data)))
+		if ra.buffered+int64(len(data)) <= ra.limit {
+			st.data = data
+			ra.buffered += int64(len(data))
+		}
+	}
+}
+
+// sweep forgets idle streams
+func (ra *readahead) sweep(now time.Time) {
+	for id, st := range ra.streams {
+		if now.Sub(st.lastUse) > readaheadIdle {
+			ra.drop(id)
+		}
+	}
+	ra.lastSweep = now
+}
+
+// evict releases the least recently used buffers until n more bytes fit
+func (ra *readahead) evict(now time.Time, n int64) {
+	for ra.buffered+n > ra.limit {
+		var oldest string
+		var oldestUse time.Time
+		for id, st := range ra.streams {
+			if st.data != nil && (oldest == "" || st.lastUse.Before(oldestUse)) {
+				oldest, oldestUse = id, st.lastUse
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		ra.drop(oldest)
+	}
+}
+
+func (ra *readahead) drop(id string) {
+	if st, ok := ra.streams[id]; ok {
+		ra.buffered -= int64(len(st.data))
+		delete(ra.streams, id)
+	}
+}
+
+// dropTenant discards a tenant's buffers, e.g. when its key is shredded
+func (ra *readahead) dropTenant(tenantID string) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for id, st := range ra.streams {
+		if st.tenant == tenantID {
+			ra.drop(id)
+		}
+	}
+}
+
+// bufferedBytes returns the plaintext currently held
+func (ra *readahead) bufferedBytes() int64 {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return ra.buffered
+}
+
+// downloadObject returns an object for a download, from the readahead
+// buffer when a ranged read of the current version is under way
+func (s *MinIOServer) downloadObject(ctx context.Context, r *http.Request, tenantID, key string) ([]byte, *metadata.ObjectMeta, error) {
+	if r.Header.Get("Range") != "" {
+		if meta, err := s.index.Get(tenantID, key); err == nil {
+			if data, ok := s.readahead.get(readStreamID(meta)); ok {
+				return data, meta, nil
+			}
+		}
+	}
+	data, meta, err := s.getObject(ctx, tenantID, key)
+	if err == nil {
+		s.checkReplicas(meta)
+	}
+	return data, meta, err
+}
+
+// chunkCount is the number of download chunks in an object of size bytes
+func (s *MinIOServer) chunkCount(size int64) int64 {
+	return max(1, (size+s.config.DownloadChunkBytes-1)/s.config.DownloadChunkBytes)
+}
+
+// headDownload answers HEAD /download with the object's size, version and
+// chunk layout, for clients planning parallel ranged reads
+func (s *MinIOServer) headDownload(w http.ResponseWriter, r *http.Request, tenantID, key string) {
+	meta, err := s.index.Get(tenantID, key)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := checkReadConditions(w.Header(), r, meta); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/octet-stream")
+	h.Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+	h.Set("Accept-Ranges", "bytes")
+	h.Set("X-Version-ID", meta.VersionID)
+	if meta.ETag != "" {
+		h.Set("ETag", `"`+meta.ETag+`"`)
+	}
+	setLastModified(h, meta)
+	setAttributeHeaders(h, meta)
+	h.Set("X-Chunk-Size", strconv.FormatInt(s.config.DownloadChunkBytes, 10))
+	h.Set("X-Chunk-Count", strconv.FormatInt(s.chunkCount(meta.Size), 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// serveCachedRanges answers a ranged download of an object held
+// unencrypted in the cache tiers by copying only the requested bytes out
+// of the cache. It reports false, having written nothing, when the object
+// must be read whole instead.
+func (s *MinIOServer) serveCachedRanges(w http.ResponseWriter, r *http.Request, tenantID, key, shareToken string) bool {
+	ctx := r.Context()
+	meta, err := s.index.Get(tenantID, key)
+	if err != nil || !cached(meta) || meta.Encrypted || !rangeApplies(r, meta) {
+		return false
+	}
+	if size, _, ok := s.cacheManager.Tenant(meta.Tenant).Stat(meta.Key); !ok || size != meta.Size {
+		return false
+	}
+	fail := func(err error) bool {
+		if shareToken != "" {
+			s.tenantManager.ReleaseShareLink(ctx, shareToken)
+		}
+		writeError(w, r, err)
+		return true
+	}
+
+	if err := checkReadConditions(w.Header(), r, meta); err != nil {
+		return fail(err)
+	}
+	ranges, ok, err := parseRanges(r.Header.Get("Range"), meta.Size)
+	if err != nil {
+		s.rangeRequests.Add(1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", meta.Size))
+		return fail(err)
+	}
+	if !ok {
+		return false
+	}
+	if err := s.checkEgress(ctx, meta, rangedBytes(ranges)); err != nil {
+		return fail(err)
+	}
+
+	spans := make([][]byte, len(ranges))
+	for i, br := range ranges {
+		spans[i] = make([]byte, br.length())
+		if _, err := s.cacheManager.Tenant(meta.Tenant).ReadAt(ctx, meta.Key, spans[i], br.start); err != nil {
+			return false
+		}
+	}
+	// An overwrite between the reads may have mixed two versions
+	if current, err := s.index.Get(tenantID, key); err != nil || current.VersionID != meta.VersionID {
+		return false
+	}
+
+	s.rangeRequests.Add(1)
+	s.rangeCacheReads.Add(1)
+	s.checkReplicas(meta)
+
+	h := w.Header()
+	h.Set("X-Version-ID", meta.VersionID)
+	if meta.ETag != "" {
+		h.Set("ETag", `"`+meta.ETag+`"`)
+	}
+	setLastModified(h, meta)
+	setAttributeHeaders(h, meta)
+	h.Set("Accept-Ranges", "bytes")
+	var sent int64
+	err = writeRanges(w, "application/octet-stream", meta.Size, ranges, func(w io.Writer, i int) error {
+		_, err := sentWriter{w, &sent}.Write(spans[i])
+		return err
+	})
+	if err != nil {
+		tracing.RecordError(ctx, err)
+	}
+	s.accountRead(ctx, meta, rangedBytes(ranges), sent)
+	return true
+}
+
+// checkEgress refuses a download of served bytes that would take the
+// tenant past its egress or bandwidth quota
+func (s *MinIOServer) checkEgress(ctx context.Context, meta *metadata.ObjectMeta, served int64) error {
+	if err := s.tenantManager.CheckEgress(ctx, meta.Tenant, served); errors.Is(err, tenant.ErrBandwidthQuotaExceeded) {
+		return errEgressQuota
+	}
+	return nil
+}
+
+// accountRead charges the bytes of a download of meta that reached the
+// client to the tenant's quota and the access statistics, and audits it.
+// A download that sent fewer than the served bytes it planned, because the
+// client went away, is charged for the sent bytes only.
+func (s *MinIOServer) accountRead(ctx context.Context, meta *metadata.ObjectMeta, served, sent int64) {
+	// The request's context is already cancelled when the client has gone
+	ctx = context.WithoutCancel(ctx)
+	var details map[string]string
+	if sent < served {
+		s.downloadsAborted.Add(1)
+		s.downloadBytesUnsent.Add(uint64(served - sent))
+		details = map[string]string{"aborted": "true", "sent": strconv.FormatInt(sent, 10), "planned": strconv.FormatInt(served, 10)}
+	}
+	if err := s.tenantManager.UpdateQuota(ctx, meta.Tenant, 0, 1, tenant.Transfer{Egress: sent}); err != nil {
+		log.Printf("Failed to update quota: %v", err)
+	}
+	class, actor := requestRead, meta.Tenant
+	if isPublicRead(ctx) {
+		class, actor = requestPublic, "anonymous"
+		s.publicEgressBytes.Add(uint64(sent))
+	}
+	s.observeRead(meta.Tenant, meta.Key, class, meta.Size, sent)
+	if s.auditsTenant(ctx, meta.Tenant) {
+		s.logAudit(ctx, audit.Event{TenantID: meta.Tenant, Actor: actor, Action: "object.get", Resource: meta.Key,
+			Details: details})
+	}
+}
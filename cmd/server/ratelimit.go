@@ -0,0 +1,100 @@
+// cmd/server/ratelimit.go
+// Request rate limiting: every request naming a tenant takes a token from
+// the tenant's bucket, and from its service account's if it is made as
+// one, and is refused with 429 once either is empty. Anonymous reads of
+// public buckets take a token from their client address's bucket instead.
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// withRateLimit charges requests that name a tenant against its rate limit
+// and their service account's, reporting the more constrained bucket in
+// X-RateLimit-* headers. The admin API and unknown tenants are not limited.
+func (s *MinIOServer) withRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unversionedPath(r.URL.Path) == "/public" {
+			s.limitPublic(w, r, next)
+			return
+		}
+		tenantID := tenantFromRequest(r)
+		if tenantID == "" || strings.HasPrefix(unversionedPath(r.URL.Path), "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// An account over its limit is refused without spending the tenant's
+		var status tenant.RateStatus
+		var err error
+		if accountID := s.requestAccount(r, tenantID); accountID != "" {
+			status, err = s.tenantManager.AllowAccountRequest(r.Context(), accountID)
+		}
+		if !errors.Is(err, tenant.ErrRateLimited) {
+			var tenantStatus tenant.RateStatus
+			tenantStatus, err = s.tenantManager.AllowRequest(r.Context(), tenantID)
+			if err != nil || status.Limit == 0 || tenantStatus.Limit > 0 && tenantStatus.Remaining < status.Remaining {
+				status = tenantStatus
+			}
+		}
+
+		setRateHeaders(w.Header(), status)
+		if errors.Is(err, tenant.ErrRateLimited) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(status.RetryAfter.Seconds()))))
+			writeError(w, r, errRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitPublic charges an anonymous public read against its client
+// address's rate limit, which hotlinkers cannot spend the tenant's
+func (s *MinIOServer) limitPublic(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	status, err := s.tenantManager.AllowPublicRequest(s.clientAddr(r))
+	setRateHeaders(w.Header(), status)
+	if errors.Is(err, tenant.ErrRateLimited) {
+		s.publicThrottled.Add(1)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(status.RetryAfter.Seconds()))))
+		writeError(w, r, errPublicLimited)
+		return
+	}
+	next.ServeHTTP(w, r)
+}
+
+// setRateHeaders reports a rate limited bucket in X-RateLimit-* headers
+func setRateHeaders(h http.Header, status tenant.RateStatus) {
+	if status.Limit > 0 {
+		h.Set("X-RateLimit-Limit", strconv.FormatInt(status.Limit, 10))
+		h.Set("X-RateLimit-Remaining", strconv.FormatInt(status.Remaining, 10))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(status.Reset).Unix(), 10))
+	}
+}
+
+// requestAccount returns the service account r is made as: the subject of
+// a token for tenantID, or the holder of access keys presented with Basic
+// auth. It returns "" for other requests.
+func (s *MinIOServer) requestAccount(r *http.Request, tenantID string) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		claims, err := s.tenantManager.VerifyTenantToken(r.Context(), token)
+		if err != nil || claims.TenantID != tenantID {
+			return ""
+		}
+		return claims.Subject
+	}
+	if accessKey, secretKey, ok := r.BasicAuth(); ok {
+		acct, err := s.tenantManager.VerifyServiceAccountCredential(r.Context(), accessKey, secretKey)
+		if err != nil || acct.TenantID != tenantID {
+			return ""
+		}
+		return acct.ID
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+// cmd/server/readrepair.go
+// Read repair: the node remembers which version of each recently replicated
+// object every region last acknowledged, and a read that finds a region
+// missing the version it serves, or holding an older one, re-replicates the
+// served version to that region in the background
+package main
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/replication"
+)
+
+// replicaLedgerObjects bounds the objects the ledger tracks; beyond it,
+// idle entries are forgotten at random and their objects go unchecked
+const replicaLedgerObjects = 100000
+
+// replicaLedger tracks region acknowledgements per object
+type replicaLedger struct {
+	backoff time.Duration // Least time between repairs of one object
+
+	mu      sync.Mutex
+	objects map[string]*replicaState // Keyed by tenant and object key
+
+	missing  atomic.Uint64 // Regions found without the object
+	stale    atomic.Uint64 // Regions found with an older version
+	repaired atomic.Uint64
+	failed   atomic.Uint64
+	inFlight atomic.Int64
+}
+
+// replicaState is one object's replication as this node knows it
+type replicaState struct {
+	version  string            // Newest version shipped
+	regions  map[string]string // Region -> version it acknowledged
+	pending  int               // Replications in flight
+	repairAt time.Time         // Earliest next repair
+}
+
+func newReplicaLedger(backoff time.Duration) *replicaLedger {
+	return &replicaLedger{backoff: backoff, objects: make(map[string]*replicaState)}
+}
+
+func replicaKey(tenantID, key string) string {
+	return tenantID + "\x00" + key
+}
+
+// shipping records that version of an object is being replicated
+func (l *replicaLedger) shipping(tenantID, key, version string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := replicaKey(tenantID, key)
+	st := l.objects[k]
+	if st == nil {
+		if len(l.objects) >= replicaLedgerObjects {
+			l.evictLocked()
+		}
+		st = &replicaState{regions: make(map[string]string)}
+		l.objects[k] = st
+	}
+	st.version = version
+	st.pending++
+}
+
+// evictLocked forgets an idle object; the caller holds l.mu
+func (l *replicaLedger) evictLocked() {
+	for k, st := range l.objects {
+		if st.pending == 0 {
+			delete(l.objects, k)
+			return
+		}
+	}
+}
+
+// acked records a region's outcome for version
+func (l *replicaLedger) acked(tenantID, key, version, region string, ok bool) {
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	if st := l.objects[replicaKey(tenantID, key)]; st != nil {
+		st.regions[region] = version
+	}
+	l.mu.Unlock()
+}
+
+// done records that a replication of the object finished
+func (l *replicaLedger) done(tenantID, key string) {
+	l.mu.Lock()
+	if st := l.objects[replicaKey(tenantID, key)]; st != nil && st.pending > 0 {
+		st.pending--
+	}
+	l.mu.Unlock()
+}
+
+// divergent returns the regions whose copy of meta is missing or older
+// than meta, and claims the repair. Objects with replication in flight or
+// repaired within the backoff are not checked, nor are objects whose
+// served version has not been shipped yet.
+func (l *replicaLedger) divergent(meta *metadata.ObjectMeta, regions []string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.objects[replicaKey(meta.Tenant, meta.Key)]
+	now := time.Now()
+	if st == nil || st.pending > 0 || st.version != meta.VersionID || now.Before(st.repairAt) {
+		return nil
+	}
+	var out []string
+	for _, region := range regions {
+		switch st.regions[region] {
+		case st.version:
+			continue
+		case "":
+			l.missing.Add(1)
+		default:
+			l.stale.Add(1)
+		}
+		out = append(out, region)
+	}
+	if len(out) > 0 {
+		st.pending++
+		st.repairAt = now.Add(l.backoff)
+	}
+	return out
+}
+
+// checkReplicas starts a read repair of meta if any region has diverged
+func (s *MinIOServer) checkReplicas(meta *metadata.ObjectMeta) {
+	if s.replicaLedger == nil {
+		return
+	}
+	if regions := s.replicaLedger.divergent(meta, s.replicaRegions(s.ctx, meta)); len(regions) > 0 {
+		go s.readRepair(meta, regions)
+	}
+}
+
+// readRepair re-replicates meta's version to regions
+func (s *MinIOServer) readRepair(meta *metadata.ObjectMeta, regions []string) {
+	l := s.replicaLedger
+	l.inFlight.Add(1)
+	finish := func(int) {
+		l.done(meta.Tenant, meta.Key)
+		l.inFlight.Add(-1)
+	}
+
+	src, err := s.replicaSource(s.ctx, meta)
+	if err == nil {
+		err = s.enqueueReplica(replication.PriorityInteractive, "default", meta.Key, meta.VersionID, "", src, regions,
+			func(region string, ok bool) {
+				if ok {
+					l.repaired.Add(1)
+				} else {
+					l.failed.Add(1)
+				}
+				l.acked(meta.Tenant, meta.Key, meta.VersionID, region, ok)
+			}, finish)
+		if err != nil {
+			src.close()
+		}
+	}
+	if err != nil {
+		log.Printf("Read repair of %s/%s to %v failed: %v", meta.Tenant, meta.Key, regions, err)
+		l.failed.Add(uint64(len(regions)))
+		finish(0)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// replicaVersions waits for replication of tenantID/key to settle and
+// returns the version each region acknowledged, and the newest shipped
+func replicaVersions(t *testing.T, tenantID, key string) (map[string]string, string) {
+	t.Helper()
+	l := testServer.replicaLedger
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		l.mu.Lock()
+		st := l.objects[replicaKey(tenantID, key)]
+		if st != nil && st.pending == 0 {
+			regions := make(map[string]string, len(st.regions))
+			for region, version := range st.regions {
+				regions[region] = version
+			}
+			l.mu.Unlock()
+			return regions, st.version
+		}
+		l.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("Replication of %s/%s did not settle", tenantID, key)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// A read that finds regions missing or behind on the served version
+// re-replicates it to them, at most once per backoff
+func TestReadRepair(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	regions := testServer.replicationEngine.Regions()
+	acked, version := replicaVersions(t, tenantID, "doc.txt")
+	for _, region := range regions {
+		if acked[region] != version {
+			t.Fatalf("Region %s acknowledged %q, want %q", region, acked[region], version)
+		}
+	}
+
+	l := testServer.replicaLedger
+	missing, stale, repaired := l.missing.Load(), l.stale.Load(), l.repaired.Load()
+	diverge := func() {
+		l.mu.Lock()
+		st := l.objects[replicaKey(tenantID, "doc.txt")]
+		st.regions[regions[0]] = "older"
+		delete(st.regions, regions[1])
+		l.mu.Unlock()
+	}
+	diverge()
+	w := do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=doc.txt", nil, adminAuth)
+	expectStatus(t, w, 200)
+	acked, _ = replicaVersions(t, tenantID, "doc.txt")
+	for _, region := range regions {
+		if acked[region] != version {
+			t.Errorf("Region %s at %q after repair, want %q", region, acked[region], version)
+		}
+	}
+	if l.missing.Load()-missing != 1 || l.stale.Load()-stale != 1 || l.repaired.Load()-repaired != 2 {
+		t.Errorf("%d missing, %d stale, %d repaired; want 1, 1 and 2",
+			l.missing.Load()-missing, l.stale.Load()-stale, l.repaired.Load()-repaired)
+	}
+
+	// Within the backoff the object is not checked again
+	diverge()
+	w = do(t, "GET", "/v1/stat?tenant_id="+tenantID+"&key=doc.txt", nil, adminAuth)
+	expectStatus(t, w, 200)
+	if acked, _ = replicaVersions(t, tenantID, "doc.txt"); acked[regions[0]] != "older" || l.stale.Load()-stale != 1 {
+		t.Errorf("Repaired again within the backoff: %v", acked)
+	}
+}
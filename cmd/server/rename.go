@@ -0,0 +1,291 @@
+// cmd/server/rename.go
+// Rename/move of single keys and whole prefixes ("folders"). Plain objects
+// are moved in place: the index entry and cache entry are re-keyed in one
+// transaction without copying data. Sealed objects are bound to their key
+// by the encryption AAD, so they cross that boundary by copy+delete.
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/tenant"
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// Rename methods
+const (
+	renameInPlace = "in_place"
+	renameCopy    = "copy"
+)
+
+// renameFailure reports one key a prefix move could not rename
+type renameFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// renameObject moves tenantID/src to dst, returning the method used. An
+// existing dst is replaced only when overwrite is set.
+func (s *MinIOServer) renameObject(ctx context.Context, tenantID, src, dst string, overwrite bool) (string, error) {
+	// Hold both keys for an in-place move; the copy path locks per step
+	unlock := s.writeLocks.LockPair(tenantID, src, dst)
+	locked := true
+	defer func() {
+		if locked {
+			unlock()
+		}
+	}()
+
+	prev, err := s.index.Get(tenantID, src)
+	if err != nil {
+		return "", errObjectNotFound
+	}
+	if src == dst {
+		return renameInPlace, nil
+	}
+
+	settings, _ := s.tenantManager.Settings(ctx, tenantID)
+	if settings.ObjectLock {
+		return "", errObjectLocked
+	}
+	if err := s.checkDeletable(ctx, tenantID, src); err != nil {
+		return "", err
+	}
+	dstPrev, _ := s.index.Get(tenantID, dst)
+	if dstPrev != nil && !overwrite {
+		return "", errObjectExists
+	}
+	// Replacing the destination deletes it
+	if dstPrev != nil {
+		if err := s.checkDeletable(ctx, tenantID, dst); err != nil {
+			return "", err
+		}
+	}
+
+	// Tenant buckets have no rename, so gateway tenants always copy
+	if _, gw := s.gatewayFor(tenantID); prev.Encrypted || gw {
+		unlock()
+		locked = false
+		return renameCopy, s.renameByCopy(ctx, tenantID, src, dst)
+	}
+	return renameInPlace, s.renameInPlace(ctx, tenantID, prev, dstPrev, dst)
+}
+
+// renameInPlace re-keys the index and cache entries of prev as one transaction
+func (s *MinIOServer) renameInPlace(ctx context.Context, tenantID string, prev, dstPrev *metadata.ObjectMeta, dst string) error {
+	src := prev.Key
+	meta := *prev
+	meta.Key = dst
+
+	txn, err := s.intentLog.Begin(
+		metadata.Op{Type: metadata.OpDelete, Meta: *prev, Prev: prev},
+		metadata.Op{Type: metadata.OpPut, Meta: meta, Prev: dstPrev},
+	)
+	if err != nil {
+		tracing.RecordError(ctx, err)
+		return errIntentFailed
+	}
+
+	// Move the cached bytes, keeping any replaced destination data for undo
+	tc := s.cacheManager.Tenant(tenantID)
+	var replaced []byte
+	if dstPrev != nil && cached(dstPrev) {
+		replaced, _ = tc.Get(ctx, dst)
+	}
+	if cached(prev) {
+		if !tc.Rename(src, dst) {
+			txn.Abort()
+			return errObjectNotFound
+		}
+	} else if replaced != nil {
+		tc.Delete(ctx, dst)
+	}
+	txn.OnAbort(func() {
+		if cached(prev) {
+			tc.Rename(dst, src)
+		}
+		if replaced != nil {
+			tc.Set(context.Background(), dst, replaced)
+		}
+	})
+
+	s.index.Delete(tenantID, src)
+	s.index.Put(meta)
+	txn.OnAbort(func() {
+		if dstPrev != nil {
+			s.index.Put(*dstPrev)
+		} else {
+			s.index.Delete(tenantID, dst)
+		}
+		s.index.Put(*prev)
+	})
+
+	// Replacing the destination frees its bytes
+	if dstPrev != nil {
+		if err := s.tenantManager.UpdateQuota(ctx, tenantID, -dstPrev.Size, 1, tenant.Transfer{}); err != nil {
+			txn.Abort()
+			return errStoreFailed
+		}
+		txn.OnAbort(func() {
+			s.tenantManager.UpdateQuota(context.Background(), tenantID, dstPrev.Size, 0, tenant.Transfer{})
+		})
+	}
+
+	if err := s.checkReplicable(ctx, &meta); err != nil {
+		tracing.RecordError(ctx, err)
+		txn.Abort()
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		tracing.RecordError(ctx, err)
+		return errCommitFailed
+	}
+	s.releaseParts(dstPrev)
+	return nil
+}
+
+// renameByCopy writes dst from src's plaintext and ACL, then deletes src,
+// removing dst again if that fails
+func (s *MinIOServer) renameByCopy(ctx context.Context, tenantID, src, dst string) error {
+	ctx = serverSide(ctx)
+	data, meta, err := s.getObject(ctx, tenantID, src)
+	if err != nil {
+		return err
+	}
+	if _, err := s.putObjectIf(ctx, tenantID, dst, data, writeCondition{}, meta.Attributes); err != nil {
+		return err
+	}
+	if len(meta.ACL) > 0 {
+		if _, err := s.putObjectACL(ctx, tenantID, dst, meta.ACL, writeCondition{}); err != nil {
+			s.deleteThrough(ctx, tenantID, dst)
+			return err
+		}
+	}
+	if err := s.deleteThrough(ctx, tenantID, src); err != nil {
+		s.deleteThrough(ctx, tenantID, dst)
+		return err
+	}
+	return nil
+}
+
+// handleRename moves ?source_key= to ?key=, or every key under
+// ?source_prefix= to the same suffix under ?prefix=. Pass ?overwrite=true
+// to replace existing destinations.
+func (s *MinIOServer) handleRename(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	tenantID := tenantFromRequest(r)
+	overwrite := query.Get("overwrite") == "true"
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if srcPrefix := query.Get("source_prefix"); srcPrefix != "" {
+		s.renamePrefix(w, r, tenantID, srcPrefix, query.Get("prefix"), overwrite)
+		return
+	}
+
+	src, dst := query.Get("source_key"), query.Get("key")
+	if src == "" || dst == "" {
+		writeErrorMessage(w, r, "Missing source_key or key", http.StatusBadRequest)
+		return
+	}
+	if err := s.authorizeMove(s.requestPrincipal(r), tenantID, src, dst); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	method, err := s.renameObject(ctx, tenantID, src, dst, overwrite)
+	details := map[string]string{"source_key": src, "method": method}
+	if err != nil {
+		details["error"] = err.Error()
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.rename", Resource: dst,
+			Outcome: audit.OutcomeError, Details: details})
+		writeError(w, r, err)
+		return
+	}
+	if s.auditsTenant(ctx, tenantID) {
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.rename", Resource: dst, Details: details})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "renamed",
+		"key":        dst,
+		"source_key": src,
+		"method":     method,
+	})
+}
+
+// renamePrefix moves each key under srcPrefix independently, reporting the
+// keys that could not be moved
+func (s *MinIOServer) renamePrefix(w http.ResponseWriter, r *http.Request, tenantID, srcPrefix, dstPrefix string, overwrite bool) {
+	if dstPrefix == "" || dstPrefix == srcPrefix {
+		writeErrorMessage(w, r, "prefix must be set and differ from source_prefix", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	p := s.requestPrincipal(r)
+	moved := map[string]int{renameInPlace: 0, renameCopy: 0}
+	failed := make([]renameFailure, 0)
+	for _, meta := range s.index.List(tenantID, srcPrefix) {
+		if ctx.Err() != nil {
+			break
+		}
+		dst := dstPrefix + strings.TrimPrefix(meta.Key, srcPrefix)
+		err := s.authorizeMove(p, tenantID, meta.Key, dst)
+		var method string
+		if err == nil {
+			method, err = s.renameObject(ctx, tenantID, meta.Key, dst, overwrite)
+		}
+		if err != nil {
+			failed = append(failed, renameFailure{Key: meta.Key, Error: err.Error()})
+			continue
+		}
+		moved[method]++
+	}
+
+	total := moved[renameInPlace] + moved[renameCopy]
+	outcome := audit.OutcomeSuccess
+	if len(failed) > 0 {
+		outcome = audit.OutcomeError
+	}
+	s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.rename_prefix", Resource: dstPrefix,
+		Outcome: outcome, Details: map[string]string{
+			"source_prefix": srcPrefix,
+			"moved":         strconv.Itoa(total),
+			"failed":        strconv.Itoa(len(failed)),
+		}})
+
+	status := http.StatusOK
+	if total == 0 && len(failed) > 0 {
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"source_prefix": srcPrefix,
+		"prefix":        dstPrefix,
+		"moved":         total,
+		"in_place":      moved[renameInPlace],
+		"copied":        moved[renameCopy],
+		"failed":        failed,
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/encryption"
+)
+
+// An in-place rename that fails after re-keying undoes each step on abort:
+// the cached bytes of both keys, both index entries and the storage the
+// replaced destination freed are all restored
+func TestRenameInPlaceAbort(t *testing.T) {
+	ctx := context.Background()
+	tenantID := newTenant(t)
+	src := strings.Repeat("s", testServer.config.InlineObjectBytes+1) // Cached, not inline
+	dst := strings.Repeat("d", testServer.config.InlineObjectBytes+2)
+	upload(t, tenantID, "src.txt", src)
+	upload(t, tenantID, "dst.txt", dst)
+	prev, err := testServer.index.Get(tenantID, "src.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstPrev, err := testServer.index.Get(tenantID, "dst.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, _ := usageOf(t, tenantID)
+
+	// A sealed object cannot replicate without a KEK for every region, so
+	// the rename fails at its last step before committing
+	keys := testServer.regionKeys
+	testServer.regionKeys, _ = encryption.NewKeyring(nil)
+	defer func() { testServer.regionKeys = keys }()
+	sealed := *prev
+	sealed.Encrypted = true
+	if err := testServer.renameInPlace(ctx, tenantID, &sealed, dstPrev, "dst.txt"); err != errNoRegionKey {
+		t.Fatalf("Rename: %v, want %v", err, errNoRegionKey)
+	}
+
+	for key, want := range map[string]string{"src.txt": src, "dst.txt": dst} {
+		if got, err := testServer.cacheManager.Tenant(tenantID).Get(ctx, key); err != nil || string(got) != want {
+			t.Errorf("Cached %s holds %d bytes (%v) after the abort, want %d", key, len(got), err, len(want))
+		}
+	}
+	if got, err := testServer.index.Get(tenantID, "src.txt"); err != nil || !reflect.DeepEqual(*got, sealed) {
+		t.Errorf("Indexed src.txt %+v (%v) after the abort, want %+v", got, err, sealed)
+	}
+	if got, err := testServer.index.Get(tenantID, "dst.txt"); err != nil || !reflect.DeepEqual(got, dstPrev) {
+		t.Errorf("Indexed dst.txt %+v (%v) after the abort, want %+v", got, err, dstPrev)
+	}
+	if s, _ := usageOf(t, tenantID); s != storage {
+		t.Errorf("Storage %d after the abort, want %d", s, storage)
+	}
+}
@@ -0,0 +1,104 @@
+// cmd/server/replicaregions.go
+// Per-object replication destinations. Replication rules map key prefixes
+// or object tags to regions and a tenant's residency setting narrows them,
+// so each task carries only the regions its object may be held in instead
+// of fanning out to every configured region
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// tagRulePrefix starts rules that match a tag instead of a key prefix
+const tagRulePrefix = "tag:"
+
+// replicationRule sends objects under Prefix, or tagged Tag=Value, to
+// Regions
+type replicationRule struct {
+	Prefix  string
+	Tag     string
+	Value   string
+	Regions []string
+}
+
+// matches reports whether the rule covers meta
+func (rule replicationRule) matches(meta *metadata.ObjectMeta) bool {
+	if rule.Tag != "" {
+		v, ok := meta.Tags[rule.Tag]
+		return ok && v == rule.Value
+	}
+	return strings.HasPrefix(meta.Key, rule.Prefix)
+}
+
+// parseReplicationRules reads prefix=region|region and
+// tag:key:value=region|region rules: tag rules first, by tag, then
+// prefixes, longest first. Every region must be a configured destination.
+func parseReplicationRules(mapping map[string][]string, regions []string) ([]replicationRule, error) {
+	rules := make([]replicationRule, 0, len(mapping))
+	for name, dests := range mapping {
+		for _, d := range dests {
+			if !containsString(regions, d) {
+				return nil, fmt.Errorf("replication rule %q: unknown region %s (configured: %s)", name, d, strings.Join(regions, ","))
+			}
+		}
+		rule := replicationRule{Prefix: name, Regions: dests}
+		if spec, ok := strings.CutPrefix(name, tagRulePrefix); ok {
+			tag, value, ok := strings.Cut(spec, ":")
+			if !ok || tag == "" {
+				return nil, fmt.Errorf("replication rule %q: want tag:key:value", name)
+			}
+			rule = replicationRule{Tag: tag, Value: value, Regions: dests}
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if (a.Tag != "") != (b.Tag != "") {
+			return a.Tag != ""
+		}
+		if a.Tag != "" {
+			return a.Tag+":"+a.Value < b.Tag+":"+b.Value
+		}
+		return len(a.Prefix) > len(b.Prefix)
+	})
+	return rules, nil
+}
+
+// replicaRegions returns the regions meta replicates to: the first
+// matching rule's, or every region, less those the tenant's residency
+// excludes. Empty means the object stays in this region.
+func (s *MinIOServer) replicaRegions(ctx context.Context, meta *metadata.ObjectMeta) []string {
+	regions := s.replicationEngine.Regions()
+	for _, rule := range s.replicationRules {
+		if rule.matches(meta) {
+			regions = rule.Regions
+			break
+		}
+	}
+
+	settings, err := s.tenantManager.Settings(ctx, meta.Tenant)
+	if err != nil || len(settings.Regions) == 0 {
+		return regions
+	}
+	allowed := make([]string, 0, len(regions))
+	for _, r := range regions {
+		if settings.AllowsRegion(r) {
+			allowed = append(allowed, r)
+		}
+	}
+	return allowed
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+// cmd/server/replicate.go
+// Encryption context at region boundaries: sealed objects replicate with
+// their tenant data key re-wrapped under each destination region's KEK, and
+// nothing is enqueued unless every key involved is available
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/minio/enterprise/internal/encryption"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// loadRegionKeys builds the KEK keyring for regions from region=hexkey
+// configuration. Regions left unconfigured get an ephemeral KEK so the node
+// still starts, but their replicas will not open after a restart.
+func loadRegionKeys(configured map[string][]string, regions []string) (*encryption.Keyring, error) {
+	keks := make(map[string][]byte, len(regions))
+	for region, values := range configured {
+		kek, err := hex.DecodeString(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid KEK for region %s: %w", region, err)
+		}
+		keks[region] = kek
+	}
+	for _, region := range regions {
+		if _, ok := keks[region]; ok {
+			continue
+		}
+		log.Printf("WARNING: no KEK configured for region %s; using an ephemeral key", region)
+		kek, err := encryption.NewKey()
+		if err != nil {
+			return nil, err
+		}
+		keks[region] = kek
+	}
+	return encryption.NewKeyring(keks)
+}
+
+// checkReplicable fails as replicaPayload would for meta, without sealing
+// anything, so a write that could never replicate is refused before it commits
+func (s *MinIOServer) checkReplicable(ctx context.Context, meta *metadata.ObjectMeta) error {
+	if !meta.Encrypted {
+		return nil
+	}
+	if _, err := s.dataKey(ctx, meta.Tenant); err != nil {
+		s.replicaKeyRefusals.Add(1)
+		return err
+	}
+	for _, region := range s.replicationEngine.Regions() {
+		if _, ok := s.regionKeys.KEK(region); !ok {
+			s.replicaKeyRefusals.Add(1)
+			return errNoRegionKey
+		}
+	}
+	return nil
+}
+
+// replicaPayload returns the bytes to replicate for meta. Plain objects ship
+// as stored; sealed objects ship with their data key wrapped per region.
+// Fails without enqueueing anything if the tenant key has been shredded or
+// any destination region lacks a KEK.
+func (s *MinIOServer) replicaPayload(ctx context.Context, meta *metadata.ObjectMeta, stored []byte) ([]byte, error) {
+	if !meta.Encrypted {
+		return stored, nil
+	}
+
+	dataKey, err := s.dataKey(ctx, meta.Tenant)
+	if err != nil {
+		s.replicaKeyRefusals.Add(1)
+		return nil, err
+	}
+
+	regions := s.replicationEngine.Regions()
+	envelopes := make([]encryption.Envelope, 0, len(regions))
+	for _, region := range regions {
+		kek, ok := s.regionKeys.KEK(region)
+		if !ok {
+			s.replicaKeyRefusals.Add(1)
+			return nil, errNoRegionKey
+		}
+		env, err := encryption.WrapKey(kek, dataKey, encryption.Context{TenantID: meta.Tenant, Key: meta.Key, Region: region})
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, *env)
+	}
+	return encryption.EncodeReplica(envelopes, stored)
+}
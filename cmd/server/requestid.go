@@ -0,0 +1,101 @@
+// cmd/server/requestid.go
+// Per-request correlation IDs. Every API call gets an ID, either the
+// caller's X-Request-ID or a generated one, which is echoed in the response
+// and carried through audit events, traces, replication tasks, error bodies
+// and the access log.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/minio/enterprise/internal/tracing"
+)
+
+// requestIDHeader carries the correlation ID in requests and responses
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds client-supplied IDs; replication tasks store at
+// most this many bytes
+const maxRequestIDLen = 64
+
+// newRequestID returns a random 128-bit hex ID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID accepts a client ID of printable ASCII without spaces, so
+// it can be logged and echoed in headers verbatim
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder captures the response status and size for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// withRequestID assigns the request its correlation ID, keeping a valid
+// client-supplied one, and writes an access log line when enabled
+func (s *MinIOServer) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		r.Header.Set(requestIDHeader, id)
+		w.Header().Set(requestIDHeader, id)
+		r = r.WithContext(tracing.WithRequestID(r.Context(), id))
+
+		if !s.config.AccessLog {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		log.Printf("access: %s %s %s %d %dB %s tenant=%q request_id=%s",
+			r.RemoteAddr, r.Method, r.URL.Path, rec.status, rec.bytes, time.Since(start).Round(time.Microsecond),
+			tenantFromRequest(r), id)
+	})
+}
@@ -0,0 +1,382 @@
+// cmd/server/resp.go
+// Redis protocol (RESP2) listener exposing GET/SET/DEL/TTL/MGET on the
+// cache tier. Clients pick a tenant with AUTH <tenant> <tenant token> (or
+// AUTH <token>); keys are namespaced per tenant and never touch object data.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+const (
+	// respKeyPrefix separates RESP entries in a tenant's cache namespace
+	// from its object data
+	respKeyPrefix = "\x00resp/"
+
+	respMaxArgs  = 1024
+	respMaxBulk  = 16 << 20
+	respMaxLine  = 64 << 10
+	respIdleTime = 5 * time.Minute
+)
+
+var errRESPProtocol = errors.New("protocol error")
+
+func respCacheKey(key string) string {
+	return respKeyPrefix + key
+}
+
+// startRESP listens on RESPAddr until the server shuts down
+func (s *MinIOServer) startRESP() error {
+	ln, err := net.Listen("tcp", s.config.RESPAddr)
+	if err != nil {
+		return fmt.Errorf("RESP listen failed: %w", err)
+	}
+	go func() {
+		<-s.ctx.Done()
+		ln.Close()
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if s.ctx.Err() == nil {
+					log.Printf("RESP accept error: %v", err)
+				}
+				return
+			}
+			go s.serveRESP(conn)
+		}
+	}()
+	return nil
+}
+
+// respConn is one client connection and its authenticated tenant
+type respConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	w      *bufio.Writer
+	claims *tenant.TokenClaims
+}
+
+// readCommand parses a RESP array of bulk strings or an inline command
+func (c *respConn) readCommand() ([]string, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > respMaxArgs {
+		return nil, errRESPProtocol
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(header) == 0 || header[0] != '$' {
+			return nil, errRESPProtocol
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil || size < 0 || size > respMaxBulk {
+			return nil, errRESPProtocol
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		if string(buf[size:]) != "\r\n" {
+			return nil, errRESPProtocol
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func (c *respConn) readLine() (string, error) {
+	line, err := c.r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errRESPProtocol
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+func (c *respConn) simple(s string) { c.w.WriteString("+" + s + "\r\n") }
+func (c *respConn) err(s string)    { c.w.WriteString("-" + s + "\r\n") }
+func (c *respConn) integer(n int64) { c.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n") }
+func (c *respConn) null()           { c.w.WriteString("$-1\r\n") }
+func (c *respConn) array(n int)     { c.w.WriteString("*" + strconv.Itoa(n) + "\r\n") }
+
+func (c *respConn) bulk(b []byte) {
+	c.w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	c.w.Write(b)
+	c.w.WriteString("\r\n")
+}
+
+func (s *MinIOServer) serveRESP(netConn net.Conn) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		netConn.Close()
+	}()
+
+	c := &respConn{
+		conn: netConn,
+		r:    bufio.NewReaderSize(netConn, respMaxLine),
+		w:    bufio.NewWriter(netConn),
+	}
+	for {
+		netConn.SetReadDeadline(time.Now().Add(respIdleTime))
+		args, err := c.readCommand()
+		if err != nil {
+			if errors.Is(err, errRESPProtocol) {
+				c.err("ERR Protocol error")
+				c.w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if !s.respDispatch(ctx, c, strings.ToUpper(args[0]), args[1:]) {
+			c.w.Flush()
+			return
+		}
+		if c.r.Buffered() == 0 {
+			c.w.Flush() // Flush once per pipelined batch
+		}
+	}
+}
+
+// respDispatch runs one command, returning false to close the connection
+func (s *MinIOServer) respDispatch(ctx context.Context, c *respConn, cmd string, args []string) bool {
+	switch cmd {
+	case "PING":
+		if len(args) > 0 {
+			c.bulk([]byte(args[0]))
+		} else {
+			c.simple("PONG")
+		}
+		return true
+	case "QUIT":
+		c.simple("OK")
+		return false
+	case "AUTH":
+		s.respAuth(ctx, c, args)
+		return true
+	case "SELECT":
+		if len(args) == 1 && args[0] == "0" {
+			c.simple("OK")
+		} else {
+			c.err("ERR only database 0 is supported")
+		}
+		return true
+	case "CLIENT":
+		c.simple("OK")
+		return true
+	}
+
+	if c.claims == nil || time.Now().After(c.claims.ExpiresAt) {
+		c.claims = nil
+		c.err("NOAUTH Authentication required.")
+		return true
+	}
+	tenantID := c.claims.TenantID
+	tc := s.cacheManager.Tenant(tenantID)
+
+	switch cmd {
+	case "GET":
+		if len(args) != 1 {
+			c.err("ERR wrong number of arguments for 'get' command")
+		} else if !c.claims.HasPermission("object:read") {
+			c.err("NOPERM this token cannot read")
+		} else if data, err := tc.Get(ctx, respCacheKey(args[0])); err != nil {
+			c.null()
+		} else {
+			c.bulk(data)
+		}
+
+	case "MGET":
+		if len(args) == 0 {
+			c.err("ERR wrong number of arguments for 'mget' command")
+			break
+		}
+		if !c.claims.HasPermission("object:read") {
+			c.err("NOPERM this token cannot read")
+			break
+		}
+		c.array(len(args))
+		for _, key := range args {
+			if data, err := tc.Get(ctx, respCacheKey(key)); err != nil {
+				c.null()
+			} else {
+				c.bulk(data)
+			}
+		}
+
+	case "SET":
+		s.respSet(ctx, c, tenantID, args)
+
+	case "DEL":
+		if len(args) == 0 {
+			c.err("ERR wrong number of arguments for 'del' command")
+			break
+		}
+		if !c.claims.HasPermission("object:delete") {
+			c.err("NOPERM this token cannot delete")
+			break
+		}
+		var deleted int64
+		for _, key := range args {
+			ck := respCacheKey(key)
+			if _, ok := tc.TTL(ck); ok && tc.Delete(ctx, ck) {
+				deleted++
+			}
+		}
+		c.integer(deleted)
+
+	case "TTL", "PTTL":
+		if len(args) != 1 {
+			c.err("ERR wrong number of arguments for '" + strings.ToLower(cmd) + "' command")
+			break
+		}
+		if !c.claims.HasPermission("object:read") {
+			c.err("NOPERM this token cannot read")
+			break
+		}
+		ttl, ok := tc.TTL(respCacheKey(args[0]))
+		switch {
+		case !ok:
+			c.integer(-2)
+		case ttl == 0:
+			c.integer(-1)
+		case cmd == "PTTL":
+			c.integer(ttl.Milliseconds())
+		default:
+			c.integer(int64((ttl + time.Second - 1) / time.Second))
+		}
+
+	default:
+		c.err(fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(cmd)))
+	}
+	return true
+}
+
+// respAuth handles AUTH <token> and AUTH <tenant> <token>
+func (s *MinIOServer) respAuth(ctx context.Context, c *respConn, args []string) {
+	var tenantID, token string
+	switch len(args) {
+	case 1:
+		token = args[0]
+	case 2:
+		tenantID, token = args[0], args[1]
+	default:
+		c.err("ERR wrong number of arguments for 'auth' command")
+		return
+	}
+
+	claims, err := s.tenantManager.VerifyTenantToken(ctx, token)
+	if err == nil && tenantID != "" && claims.TenantID != tenantID {
+		err = tenant.ErrTokenInvalid
+	}
+	if err == nil {
+		if _, suspended := s.tenantManager.Suspended(ctx, claims.TenantID); suspended {
+			err = tenant.ErrTenantSuspended
+		}
+	}
+	if err != nil {
+		c.claims = nil
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Action: "resp.auth", Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"remote": c.conn.RemoteAddr().String(), "error": err.Error()}})
+		c.err("WRONGPASS invalid username-password pair or user is disabled.")
+		return
+	}
+
+	c.claims = claims
+	if s.auditsTenant(ctx, claims.TenantID) {
+		s.logAudit(ctx, audit.Event{TenantID: claims.TenantID, Actor: claims.Subject, Action: "resp.auth",
+			Details: map[string]string{"remote": c.conn.RemoteAddr().String()}})
+	}
+	c.simple("OK")
+}
+
+// respSet handles SET key value [EX seconds|PX milliseconds] [NX|XX]
+func (s *MinIOServer) respSet(ctx context.Context, c *respConn, tenantID string, args []string) {
+	if len(args) < 2 {
+		c.err("ERR wrong number of arguments for 'set' command")
+		return
+	}
+	if !c.claims.HasPermission("object:write") {
+		c.err("NOPERM this token cannot write")
+		return
+	}
+
+	var ttl time.Duration
+	var nx, xx bool
+	for i := 2; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				c.err("ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				c.err("ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+			ttl = time.Duration(n) * unit
+			i++
+		default:
+			c.err("ERR syntax error")
+			return
+		}
+	}
+	if nx && xx {
+		c.err("ERR syntax error")
+		return
+	}
+
+	tc := s.cacheManager.Tenant(tenantID)
+	key := respCacheKey(args[0])
+	if nx || xx {
+		if _, exists := tc.TTL(key); (nx && exists) || (xx && !exists) {
+			c.null()
+			return
+		}
+	}
+	if err := tc.SetWithTTL(ctx, key, []byte(args[1]), ttl); err != nil {
+		c.err("ERR " + err.Error())
+		return
+	}
+	c.simple("OK")
+}
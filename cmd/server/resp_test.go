@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// respClient speaks RESP to serveRESP over a pipe
+type respClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newRESPClient(t *testing.T) *respClient {
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go testServer.serveRESP(server)
+	return &respClient{t: t, conn: client, r: bufio.NewReader(client)}
+}
+
+// do sends a command and returns its reply, with bulk strings unwrapped,
+// null as "(nil)" and arrays as their elements joined by ","
+func (c *respClient) do(args ...string) string {
+	c.t.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		c.t.Fatal(err)
+	}
+	return c.reply()
+}
+
+func (c *respClient) reply() string {
+	c.t.Helper()
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	switch line[0] {
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "(nil)"
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			c.t.Fatal(err)
+		}
+		return string(data[:n])
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		elems := make([]string, n)
+		for i := range elems {
+			elems[i] = c.reply()
+		}
+		return strings.Join(elems, ",")
+	}
+	return line
+}
+
+// Commands need AUTH, act on the token's tenant's namespace alone, and are
+// limited by its permissions
+func TestRESP(t *testing.T) {
+	tenantID := newTenant(t)
+	c := newRESPClient(t)
+
+	if got := c.do("PING"); got != "+PONG" {
+		t.Errorf("PING = %q", got)
+	}
+	if got := c.do("GET", "k"); !strings.HasPrefix(got, "-NOAUTH") {
+		t.Errorf("GET before AUTH = %q, want NOAUTH", got)
+	}
+	if got := c.do("AUTH", "not-a-token"); !strings.HasPrefix(got, "-WRONGPASS") {
+		t.Errorf("AUTH with a bad token = %q, want WRONGPASS", got)
+	}
+	token := tenantToken(t, tenantID, "app", "object:read", "object:write", "object:delete")
+	if got := c.do("AUTH", newTenant(t), token); !strings.HasPrefix(got, "-WRONGPASS") {
+		t.Errorf("AUTH naming another tenant = %q, want WRONGPASS", got)
+	}
+	if got := c.do("AUTH", tenantID, token); got != "+OK" {
+		t.Fatalf("AUTH = %q", got)
+	}
+
+	for _, step := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"SET", "k", "v1", "EX", "100"}, "+OK"},
+		{[]string{"GET", "k"}, "v1"},
+		{[]string{"TTL", "k"}, ":100"},
+		{[]string{"SET", "k", "v2", "NX"}, "(nil)"},
+		{[]string{"SET", "k", "v2", "XX"}, "+OK"},
+		{[]string{"TTL", "k"}, ":-1"},
+		{[]string{"MGET", "k", "missing"}, "v2,(nil)"},
+		{[]string{"SET", "k", "v", "EX", "0"}, "-ERR invalid expire time in 'set' command"},
+		{[]string{"DEL", "k", "missing"}, ":1"},
+		{[]string{"TTL", "k"}, ":-2"},
+		{[]string{"SELECT", "1"}, "-ERR only database 0 is supported"},
+		{[]string{"FLUSHALL"}, "-ERR unknown command 'flushall'"},
+	} {
+		if got := c.do(step.args...); got != step.want {
+			t.Errorf("%v = %q, want %q", step.args, got, step.want)
+		}
+	}
+	if _, err := testServer.index.Get(tenantID, "k"); err == nil {
+		t.Error("RESP key stored as an object")
+	}
+
+	c.do("SET", "shared", "mine")
+	other := newRESPClient(t)
+	otherID := newTenant(t)
+	other.do("AUTH", tenantToken(t, otherID, "app", "object:read"))
+	if got := other.do("GET", "shared"); got != "(nil)" {
+		t.Errorf("Another tenant read %q", got)
+	}
+	if got := other.do("SET", "shared", "theirs"); !strings.HasPrefix(got, "-NOPERM") {
+		t.Errorf("SET without write permission = %q, want NOPERM", got)
+	}
+}
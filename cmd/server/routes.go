@@ -0,0 +1,587 @@
+// cmd/server/routes.go
+// API routes and versioning. Every endpoint of the proprietary API is
+// served under /v1; the original unversioned paths remain as a
+// compatibility shim that answers identically but announces its retirement
+// with Deprecation, Sunset and Link headers.
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/cache"
+	"github.com/minio/enterprise/internal/gateway"
+	"github.com/minio/enterprise/internal/identity"
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// apiV1 prefixes the current API version's paths
+const apiV1 = "/v1"
+
+// apiRoute is one endpoint of the versioned API
+type apiRoute struct {
+	// Path is relative to the version prefix
+	Path    string
+	Handler http.HandlerFunc
+
+	// Deprecated, if set, is announced on every response from the route
+	Deprecated *deprecation
+
+	// Ops document the route's methods
+	Ops []apiOp
+}
+
+// deprecation announces that a route will be removed, using the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers
+type deprecation struct {
+	// Since is when the route was deprecated; zero announces it without a date
+	Since time.Time
+
+	// Sunset is when the route stops answering; zero if not yet scheduled
+	Sunset time.Time
+
+	// Successor is the path clients should move to, if any
+	Successor string
+}
+
+// wrap sets the deprecation headers before calling next
+func (d *deprecation) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		if d.Since.IsZero() {
+			h.Set("Deprecation", "true")
+		} else {
+			h.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		}
+		if !d.Sunset.IsZero() {
+			h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != "" {
+			h.Add("Link", "<"+d.Successor+`>; rel="successor-version"`)
+		}
+		next(w, r)
+	}
+}
+
+// apiRoutes lists the versioned API. Ops document each route for the
+// generated OpenAPI document.
+func (s *MinIOServer) apiRoutes() []apiRoute {
+	var (
+		tenantQuery  = apiParam{Name: "tenant", Required: true, Description: "Tenant ID"}
+		idQuery      = apiParam{Name: "id", Required: true}
+		uploadQuery  = apiParam{Name: "upload_id", Required: true}
+		tenantIDReq  = apiParam{Name: "tenant_id", Required: true, Description: "Tenant ID"}
+		accessParams = []apiParam{{Name: "window", Description: "trailing duration, e.g. 15m"},
+			{Name: "by", Description: "requests or bytes"}, {Name: "prefix"},
+			{Name: "depth", Description: "group keys by this many path segments"}, {Name: "n"}}
+		attrHeaders = []apiParam{{Name: "X-Amz-Meta-*", In: "header", Description: "User metadata, returned on download"},
+			{Name: "X-Amz-Tagging", In: "header", Description: "Tags as a URL-encoded query string, k1=v1&k2=v2"}}
+		tokenResult = shape{"access_token": "", "token_type": "", "tenant_id": "", "expires_at": time.Time{}}
+		readConds   = []apiParam{{Name: "If-Match", In: "header", Description: "Only serve these ETags or versions, else 412"},
+			{Name: "If-None-Match", In: "header", Description: "304 if the object has one of these ETags or versions"},
+			{Name: "If-Modified-Since", In: "header", Description: "304 if not modified since this date"},
+			{Name: "If-Unmodified-Since", In: "header", Description: "412 if modified since this date"}}
+	)
+
+	return []apiRoute{
+		{Path: "/health", Handler: s.handleNodeHealth, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report the node's health score and, in cluster mode, the cluster's nodes",
+				Result: nodeHealth{}},
+		}},
+		{Path: "/upload", Handler: s.handleUpload, Ops: []apiOp{
+			{Method: http.MethodPut, Summary: "Upload an object", Body: rawBody{},
+				Params: append([]apiParam{paramTenant, paramKey,
+					{Name: "if_version", Description: "Only overwrite this version or ETag (or If-Match)"},
+					{Name: "if_absent", Description: "true to only create (or If-None-Match: *)"}}, attrHeaders...),
+				Result: shape{"status": "", "key": "", "size": 0, "version_id": "", "etag": ""}},
+		}},
+		{Path: "/multipart", Handler: s.handleMultipart, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Start a multipart upload", Params: append([]apiParam{paramTenant, paramKey}, attrHeaders...),
+				Result: multipartUpload{}},
+			{Method: http.MethodGet, Summary: "List the tenant's multipart uploads, or one upload's parts",
+				Params: []apiParam{paramTenant, {Name: "upload_id", Description: "List this upload's parts"}},
+				Result: []multipartUpload{}},
+			{Method: http.MethodDelete, Summary: "Abort a multipart upload",
+				Params: []apiParam{paramTenant, uploadQuery}, Status: http.StatusNoContent},
+		}},
+		{Path: "/multipart/part", Handler: s.handleUploadPart, Ops: []apiOp{
+			{Method: http.MethodPut, Summary: "Upload or replace a part", Body: rawBody{},
+				Params: []apiParam{paramTenant, uploadQuery,
+					{Name: "part_number", Required: true, Description: "1 to 10000"},
+					{Name: "Content-MD5", In: "header", Description: "Base64 MD5 of the part, checked if sent"},
+					{Name: "X-Amz-Checksum-Sha256", In: "header", Description: "Base64 SHA-256 of the part, checked if sent"}},
+				Result: multipartPart{}},
+		}},
+		{Path: "/multipart/complete", Handler: s.handleCompleteMultipart, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Complete a multipart upload from its parts, in order",
+				Params: []apiParam{paramTenant, uploadQuery,
+					{Name: "If-Match", In: "header", Description: "Only overwrite this version or ETag"},
+					{Name: "If-None-Match", In: "header", Description: "* to only create"}},
+				Body:   completeRequest{},
+				Result: shape{"status": "", "key": "", "size": 0, "etag": "", "checksum_sha256": "", "version_id": ""}},
+		}},
+		{Path: "/download", Handler: s.handleDownload, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Download an object, or a shared object by token",
+				Params: append([]apiParam{paramTenant, {Name: "key", Description: "Object key"},
+					{Name: "share", Description: "Share link token, instead of tenant and key"},
+					{Name: "password", Description: "Share link password (or X-Share-Password)"},
+					{Name: "w", Description: "Image width (tenants with image_transforms)"},
+					{Name: "h", Description: "Image height"},
+					{Name: "fit", Description: "contain, cover or fill"},
+					{Name: "crop", Description: "x,y,width,height"},
+					{Name: "format", Description: "jpeg, png, gif, webp or avif"},
+					{Name: "q", Description: "Lossy quality 1-100"},
+					{Name: "Range", In: "header", Description: "Byte ranges, e.g. bytes=0-8388607; several are answered as multipart/byteranges"},
+					{Name: "If-Range", In: "header", Description: "ETag, version or Last-Modified date the Range applies to"}}, readConds...),
+				Result: rawBody{}},
+			{Method: http.MethodHead, Summary: "Get an object's size, version and chunk layout (X-Chunk-Size, X-Chunk-Count)",
+				Params: append([]apiParam{paramTenant, paramKey}, readConds...)},
+		}},
+		{Path: "/public", Handler: s.handlePublic, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Download an object of a public_read bucket anonymously, rate limited per client address",
+				Params: append([]apiParam{paramTenant, paramKey,
+					{Name: "token", Description: "Referer token, for buckets with referer_tokens (or X-Referer-Token)"},
+					{Name: "Referer", In: "header", Description: "Page linking the object; must be on the referer token's host"},
+					{Name: "Range", In: "header", Description: "Byte ranges, as for /download"}}, readConds...),
+				Result: rawBody{}},
+			{Method: http.MethodHead, Summary: "Get a public object's size, version and chunk layout",
+				Params: append([]apiParam{paramTenant, paramKey, {Name: "token"}}, readConds...)},
+		}},
+		{Path: "/public/tokens", Handler: s.handleRefererTokens, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Issue a referer token for a public bucket; needs tenant credentials",
+				Params: []apiParam{paramTenant}, Body: refererTokenRequest{}, Result: refererTokenResponse{},
+				Status: http.StatusCreated},
+		}},
+		{Path: "/tagging", Handler: s.handleTagging, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Get an object's tags", Params: []apiParam{paramTenant, paramKey},
+				Result: objectTagging{}},
+			{Method: http.MethodPut, Summary: "Replace an object's tags, keeping its data and version",
+				Params: []apiParam{paramTenant, paramKey, {Name: "If-Match", In: "header", Description: "Only tag this version"}},
+				Body:   objectTagging{}, Result: objectTagging{}},
+			{Method: http.MethodDelete, Summary: "Remove an object's tags", Params: []apiParam{paramTenant, paramKey},
+				Status: http.StatusNoContent},
+		}},
+		{Path: "/acl", Handler: s.handleACL, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Get an object's access control list", Params: []apiParam{paramTenant, paramKey},
+				Result: objectACL{}},
+			{Method: http.MethodPut, Summary: "Replace an object's access control list, keeping its data and version",
+				Params: []apiParam{paramTenant, paramKey, {Name: "If-Match", In: "header", Description: "Only change this version"}},
+				Body:   objectACL{}, Result: objectACL{}},
+		}},
+		{Path: "/delete", Handler: s.handleDelete, Ops: []apiOp{
+			{Method: http.MethodDelete, Summary: "Delete an object", Params: []apiParam{paramTenant, paramKey},
+				Status: http.StatusNoContent},
+		}},
+		{Path: "/delete-batch", Handler: s.handleDeleteBatch, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Delete up to 1000 objects in one call",
+				Params: []apiParam{paramTenant}, Body: deleteBatchRequest{},
+				Result: shape{"deleted": 0, "failed": 0, "results": []deleteBatchResult{}}},
+		}},
+		{Path: "/copy", Handler: s.handleCopy, Ops: []apiOp{
+			{Method: http.MethodPut, Summary: "Copy an object server-side, across tenants under a grant",
+				Params: append([]apiParam{paramTenant, paramKey,
+					{Name: "source_key", Required: true},
+					{Name: "source_tenant", Description: "Defaults to the destination tenant"},
+					{Name: "If-Match", In: "header", Description: "Only overwrite this destination version or ETag"},
+					{Name: "If-None-Match", In: "header", Description: "* to only create"},
+					{Name: "X-Amz-Metadata-Directive", In: "header", Description: "COPY (default) keeps the source's metadata and tags; REPLACE takes the request's"}},
+					attrHeaders...),
+				Result: shape{"status": "", "key": "", "source_tenant": "", "source_key": "", "size": 0,
+					"version_id": "", "etag": "", "method": ""}},
+		}},
+		{Path: "/rename", Handler: s.handleRename, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Rename a key, or move every key under a prefix",
+				Params: []apiParam{paramTenant,
+					{Name: "source_key"}, {Name: "key"},
+					{Name: "source_prefix"}, {Name: "prefix"},
+					{Name: "overwrite", Description: "true to replace existing destinations"}},
+				Result: shape{"status": "", "key": "", "source_key": "", "method": ""}},
+		}},
+		{Path: "/share", Handler: s.handleShare, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Create a share link", Params: []apiParam{paramTenant, paramKey},
+				Body: shareLinkRequest{}, Result: shareLinkResponse{}},
+			{Method: http.MethodGet, Summary: "List share links", Params: []apiParam{paramTenant},
+				Result: []shareLinkResponse{}},
+			{Method: http.MethodDelete, Summary: "Revoke a share link",
+				Params: []apiParam{paramTenant, {Name: "token", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/kv/batch", Handler: s.handleKVBatch, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Get, put and delete small objects in one call",
+				Params: []apiParam{paramTenant}, Body: kvBatchRequest{}, Result: shape{"results": []kvResult{}}},
+		}},
+		{Path: "/list", Handler: s.handleList, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List objects",
+				Params: []apiParam{paramTenant, {Name: "prefix"}, {Name: "delimiter"},
+					{Name: "max_keys"}, {Name: "continuation_token"}, {Name: "marker"},
+					{Name: "aggregate", Description: "prefix for per-prefix counts and sizes"},
+					{Name: "strict", Description: "true to wait for replication convergence"},
+					{Name: "source", Description: "gateway to list the tenant's own bucket"}},
+				Result: shape{"objects": []objectInfo{}, "count": 0, "is_truncated": false,
+					"next_continuation_token": "", "delimiter": "", "common_prefixes": []string{}}},
+		}},
+		{Path: "/stat", Handler: s.handleStat, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Get object metadata",
+				Params: []apiParam{paramTenant, paramKey}, Result: objectInfo{}},
+		}},
+		{Path: "/access/top", Handler: s.handleAccessTop, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List the most accessed objects or prefixes",
+				Params: append([]apiParam{paramTenant}, accessParams...),
+				Result: []monitoring.AccessCount{}},
+		}},
+		{Path: "/changes", Handler: s.handleChanges, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Read the object change feed",
+				Params: []apiParam{paramTenant,
+					{Name: "since", Description: "Sequence number to resume after"},
+					{Name: "limit", Description: "Maximum changes returned (default 1000)"},
+					{Name: "wait", Description: "Long-poll for up to this duration (max 60s)"}},
+				Result: changesResult{}},
+		}},
+		{Path: "/quota", Handler: s.handleQuota, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report storage used against the storage quota; needs tenant credentials",
+				Params: []apiParam{paramTenant}, Result: quotaInfo{}},
+		}},
+		{Path: "/usage", Handler: s.handleUsage, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report current usage and quotas; needs tenant credentials",
+				Params: []apiParam{paramTenant}, Result: tenantUsage{}},
+		}},
+		{Path: "/usage/history", Handler: s.handleUsageHistory, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report daily usage; needs tenant credentials",
+				Params: []apiParam{paramTenant,
+					{Name: "from", Description: "First UTC date, YYYY-MM-DD (default 29 days before to)"},
+					{Name: "to", Description: "Last UTC date, YYYY-MM-DD (default today)"}},
+				Result: usageHistory{}},
+		}},
+		{Path: "/usage/series", Handler: s.handleUsageSeries, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report a rolled up usage metric, or list the tenant's series without metric; needs tenant credentials",
+				Params: []apiParam{paramTenant,
+					{Name: "metric", Description: "storage_bytes, objects, requests, requests_<class>, bandwidth_bytes, ingress_bytes, egress_bytes, ..."},
+					{Name: "bucket", Description: "Bucket whose series to report (default the tenant's totals)"},
+					{Name: "resolution", Description: "1m, 1h or 1d (default 1h)"},
+					{Name: "from", Description: "RFC 3339 start (default the resolution's retention before to)"},
+					{Name: "to", Description: "RFC 3339 end (default now)"}},
+				Result: usageSeries{}},
+		}},
+		{Path: "/usage/buckets", Handler: s.handleUsageBuckets, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report objects and bytes per bucket (first key segment); needs tenant credentials",
+				Params: []apiParam{paramTenant}, Result: bucketUsage{}},
+		}},
+		{Path: "/buckets", Handler: s.handleBuckets, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List buckets, or return one with bucket; needs tenant credentials",
+				Params: []apiParam{paramTenant, {Name: "bucket", Description: "Bucket name"}}, Result: []tenant.Bucket{}},
+			{Method: http.MethodPost, Summary: "Create a bucket; needs tenant credentials",
+				Params: []apiParam{paramTenant}, Body: createBucketRequest{}, Result: tenant.Bucket{},
+				Status: http.StatusCreated},
+			{Method: http.MethodPut, Summary: "Replace a bucket's config; needs tenant credentials",
+				Params: []apiParam{paramTenant, {Name: "bucket", Required: true, Description: "Bucket name"}}, Body: tenant.BucketConfig{}, Result: tenant.Bucket{}},
+			{Method: http.MethodDelete, Summary: "Delete an empty bucket; needs tenant credentials",
+				Params: []apiParam{paramTenant, {Name: "bucket", Required: true, Description: "Bucket name"}}, Status: http.StatusNoContent},
+		}},
+
+		// Single sign-on
+		{Path: "/sso/login", Handler: s.handleSSOLogin, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Redirect to the identity provider",
+				Params: []apiParam{{Name: "return_to"}}, Status: http.StatusFound},
+		}},
+		{Path: "/sso/callback", Handler: s.handleSSOCallback, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Complete an identity provider login",
+				Params: []apiParam{{Name: "code"}, {Name: "state"}}, Status: http.StatusFound},
+		}},
+		{Path: "/sso/session", Handler: s.handleSSOSession, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Describe the current console session", Result: identity.Session{}},
+		}},
+		{Path: "/sso/logout", Handler: s.handleSSOLogout, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "End the console session",
+				Result: shape{"status": "", "end_session_url": ""}},
+		}},
+		{Path: "/sso/token", Handler: s.handleSSOToken, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Exchange an identity for a tenant API token",
+				Body: tokenExchangeRequest{}, Result: tokenResult},
+		}},
+		{Path: "/iam/login", Handler: s.handleIAMLogin, Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Log in with directory credentials",
+				Body: iamLoginRequest{}, Result: shape{"access_token": "", "token_type": "", "tenant_id": "",
+					"expires_at": time.Time{}, "policies": []string{}}},
+		}},
+		{Path: "/iam/service-accounts/credentials", Handler: s.handleServiceAccountCredentials, Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Fetch current credentials with service account basic auth",
+				Result: shape{"account": tenant.ServiceAccount{}, "credentials": tenant.ServiceAccountCredentials{},
+					"access_token": "", "token_type": "", "expires_at": time.Time{}}},
+		}},
+
+		// Admin API
+		{Path: "/admin/openapi.json", Handler: s.requireAdmin(s.handleAdminOpenAPI), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "This document", Result: map[string]interface{}{}},
+		}},
+		{Path: "/admin/fsck", Handler: s.requireAdmin(s.handleAdminFsck), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Start a consistency check",
+				Params: []apiParam{{Name: "repair", Description: "true to repair discrepancies"}},
+				Result: fsckProgress{}, Status: http.StatusAccepted},
+			{Method: http.MethodGet, Summary: "Report consistency check progress",
+				Params: []apiParam{{Name: "stream", Description: "true for NDJSON progress until done"}},
+				Result: fsckProgress{}},
+		}},
+		{Path: "/admin/disks", Handler: s.requireAdmin(s.handleAdminDisks), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report disk usage",
+				Result: shape{"read_only": false, "disks": []monitoring.DiskUsage{}}},
+		}},
+		{Path: "/admin/health/history", Handler: s.requireAdmin(s.handleAdminHealthHistory), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report the timeline of readiness, disk, breaker, clock and self-test transitions",
+				Params: []apiParam{
+					{Name: "from", Description: "RFC 3339 start"},
+					{Name: "to", Description: "RFC 3339 end (default now)"},
+					{Name: "subsystem", Description: "node, readiness, drain, breaker, disk_usage, clock_skew or selftest"},
+					{Name: "component", Description: "Disk, region or drain ID within the subsystem"},
+					{Name: "severity", Description: "Lowest severity to include: info, warning or critical"},
+					{Name: "limit", Description: "Newest events to return (default 200, at most 1000)"}},
+				Result: healthHistory{}},
+		}},
+		{Path: "/admin/support/bundle", Handler: s.requireAdmin(s.handleAdminSupportBundle), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Download a support bundle (tar.gz) of redacted config, logs, stats, profiles and health history",
+				Params: []apiParam{
+					{Name: "profiles", Description: "false to leave out the goroutine and heap profiles"},
+					{Name: "logs", Description: "Latest log lines to include (default all kept)"}}},
+		}},
+		{Path: "/admin/cache/stats", Handler: s.requireAdmin(s.handleAdminCacheStats), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report cache statistics",
+				Params: []apiParam{{Name: "shards", Description: "true to include every shard"}},
+				Result: shape{"entries": 0, "bytes": 0, "hits": 0, "misses": 0, "evictions": 0, "expirations": 0,
+					"l1_hits": 0, "l2_hits": 0, "l3_hits": 0, "shard_count": 0,
+					"busiest_shard": cache.ShardOccupancy{}, "placement": "",
+					"filter": shape{"negatives": 0, "false_positives": 0, "false_positive_rate": 0.0, "rebuilds": 0},
+					"shards": []cache.ShardOccupancy{}}},
+		}},
+		{Path: "/admin/cache/detail", Handler: s.requireAdmin(s.handleAdminCacheDetail), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report per-tier and per-shard cache statistics, lock waits and shard skew",
+				Params: []apiParam{{Name: "hottest", Description: "Busiest shards to list (default 10)"},
+					{Name: "shards", Description: "true to include every shard"}},
+				Result: cache.DetailedStats{}},
+		}},
+		{Path: "/admin/cache/top", Handler: s.requireAdmin(s.handleAdminCacheTop), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List the hottest or largest cached keys",
+				Params: []apiParam{{Name: "by", Description: "hits or size"}, {Name: "n"}},
+				Result: []cache.KeyInfo{}},
+		}},
+		{Path: "/admin/cache/flush", Handler: s.requireAdmin(s.handleAdminCacheFlush), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Flush cached entries",
+				Params: []apiParam{{Name: "tenant_id"}, {Name: "prefix"}},
+				Result: shape{"entries": 0, "bytes": 0}},
+		}},
+		{Path: "/admin/cache/demote", Handler: s.requireAdmin(s.handleAdminCacheDemote), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Move a cached key to a lower tier",
+				Params: []apiParam{{Name: "tenant_id"}, paramKey, {Name: "tier"}},
+				Result: cache.KeyInfo{}},
+		}},
+		{Path: "/admin/access/top", Handler: s.requireAdmin(s.handleAdminAccessTop), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List the most accessed objects or prefixes across tenants",
+				Params: append([]apiParam{{Name: "tenant_id"}}, accessParams...),
+				Result: []monitoring.AccessCount{}},
+		}},
+		{Path: "/admin/access/hot", Handler: s.requireAdmin(s.handleAdminAccessHot), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List the prefixes promoted to L1 as consistently hot",
+				Result: shape{"enabled": false, "depth": 0, "window": "", "requests": 0,
+					"prefixes": []monitoring.AccessCount{}, "promotions": 0}},
+		}},
+		{Path: "/admin/replication/feed", Handler: s.requireAdmin(s.handleAdminReplicationFeed), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report replication progress through the change feed",
+				Params: []apiParam{{Name: "tenant_id"}}, Result: []replicationFeedStatus{}},
+			{Method: http.MethodPost, Summary: "Rewind a tenant's replication to re-ship its changes",
+				Params: []apiParam{{Name: "tenant_id"}, {Name: "since", Description: "Sequence number to re-ship after"}},
+				Result: []replicationFeedStatus{}},
+		}},
+		{Path: "/admin/replication/digest", Handler: s.requireAdmin(s.handleAdminReplicationDigest), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Serve a tenant's Merkle tree, or one leaf's objects, to peer regions",
+				Params: []apiParam{tenantIDReq, {Name: "depth", Description: "Tree depth (default 10, max 16)"},
+					{Name: "leaf", Description: "Leaf whose objects to list instead"}},
+				Result: metadata.MerkleTree{}},
+		}},
+		{Path: "/admin/replication/anti-entropy", Handler: s.requireAdmin(s.handleAdminAntiEntropy), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report the last anti-entropy run",
+				Result: shape{"running": false, "last_run": antiEntropyRun{}}},
+			{Method: http.MethodPost, Summary: "Start an anti-entropy run now", Status: http.StatusAccepted,
+				Result: shape{"running": false, "last_run": antiEntropyRun{}}},
+		}},
+		{Path: "/admin/replication/clocks", Handler: s.requireAdmin(s.handleAdminReplicationClocks), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report the clock skew of the peer regions", Result: []peerClockStatus{}},
+		}},
+		{Path: "/admin/drain", Handler: s.requireAdmin(s.handleAdminDrain), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Start draining this node", Result: drainStatus{}, Status: http.StatusAccepted},
+			{Method: http.MethodGet, Summary: "Report drain progress", Result: drainStatus{}},
+			{Method: http.MethodDelete, Summary: "Cancel a drain", Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/tenants", Handler: s.requireAdmin(s.handleAdminTenants), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Provision a tenant", Body: tenant.TenantSpec{},
+				Result: tenantInfo{}, Status: http.StatusCreated},
+			{Method: http.MethodGet, Summary: "List tenants and plans",
+				Result: shape{"tenants": []tenantInfo{}, "plans": []tenant.Plan{}}},
+			{Method: http.MethodPut, Summary: "Change a tenant's storage, bandwidth, ingress or egress quotas",
+				Params: []apiParam{idQuery}, Body: tenant.QuotaLimits{}, Result: tenantInfo{}},
+			{Method: http.MethodDelete, Summary: "Delete a tenant",
+				Params: []apiParam{idQuery, {Name: "purge", Description: "true to delete its objects"}},
+				Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/tenants/batch", Handler: s.requireAdmin(s.handleAdminTenantsBatch), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Provision tenants from a manifest", Body: provisionRequest{},
+				Result: shape{"results": []tenant.ProvisionResult{}, "created": 0, "existing": 0, "failed": 0}},
+		}},
+		{Path: "/admin/tenants/suspend", Handler: s.requireAdmin(s.handleAdminTenantSuspend), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Suspend a tenant",
+				Params: []apiParam{idQuery, {Name: "reason"}}, Result: tenantInfo{}},
+		}},
+		{Path: "/admin/tenants/resume", Handler: s.requireAdmin(s.handleAdminTenantResume), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Resume a suspended tenant", Params: []apiParam{idQuery}, Result: tenantInfo{}},
+		}},
+		{Path: "/admin/grants", Handler: s.requireAdmin(s.handleAdminGrants), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Grant a tenant read access to another's prefix",
+				Body: grantRequest{}, Result: tenant.ShareGrant{}, Status: http.StatusCreated},
+			{Method: http.MethodGet, Summary: "List grants", Params: []apiParam{paramAdmin}, Result: []tenant.ShareGrant{}},
+			{Method: http.MethodDelete, Summary: "Revoke a grant", Params: []apiParam{idQuery}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/sharelinks", Handler: s.requireAdmin(s.handleAdminShareLinks), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List share links", Params: []apiParam{paramAdmin}, Result: []tenant.ShareLink{}},
+			{Method: http.MethodDelete, Summary: "Revoke a share link",
+				Params: []apiParam{{Name: "token", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/sessions", Handler: s.requireAdmin(s.handleAdminSessions), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List console sessions", Result: []identity.Session{}},
+			{Method: http.MethodDelete, Summary: "Revoke sessions by ID or subject",
+				Params: []apiParam{{Name: "id"}, {Name: "subject"}}, Result: shape{"revoked": 0}},
+		}},
+		{Path: "/admin/iam/ldap", Handler: s.requireAdmin(s.handleAdminLDAP), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Describe directory backends",
+				Params: []apiParam{{Name: "tenant_id"}}, Result: []ldapBackendStatus{}},
+			{Method: http.MethodPut, Summary: "Configure a tenant's directory",
+				Params: []apiParam{tenantIDReq}, Body: ldapConfigRequest{}, Result: ldapBackendStatus{}},
+			{Method: http.MethodPost, Summary: "Synchronize a tenant's directory now",
+				Params: []apiParam{tenantIDReq}, Result: identity.SyncResult{}},
+			{Method: http.MethodDelete, Summary: "Remove a tenant's directory",
+				Params: []apiParam{tenantIDReq}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/iam/users", Handler: s.requireAdmin(s.handleAdminIAMUsers), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List directory users",
+				Params: []apiParam{tenantIDReq}, Result: []identity.DirectoryUser{}},
+		}},
+		{Path: "/admin/gateway", Handler: s.requireAdmin(s.handleAdminGateway), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Describe tenant gateways",
+				Params: []apiParam{{Name: "tenant_id"}}, Result: map[string]gateway.Status{}},
+			{Method: http.MethodPut, Summary: "Front a tenant with its own bucket",
+				Params: []apiParam{tenantIDReq}, Body: gateway.Config{}, Result: gateway.Status{}},
+			{Method: http.MethodPost, Summary: "Flush pending write-back uploads",
+				Params: []apiParam{tenantIDReq}, Result: gateway.Status{}},
+			{Method: http.MethodDelete, Summary: "Remove a tenant's gateway",
+				Params: []apiParam{tenantIDReq}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/faults", Handler: s.requireAdmin(s.handleAdminFaults), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Describe tenant fault profiles",
+				Params: []apiParam{{Name: "tenant_id"}}, Result: map[string]faultProfile{}},
+			{Method: http.MethodPut, Summary: "Inject faults into a test tenant's requests",
+				Params: []apiParam{tenantIDReq}, Body: faultProfile{}, Result: faultProfile{}},
+			{Method: http.MethodDelete, Summary: "Remove a tenant's fault profile",
+				Params: []apiParam{tenantIDReq}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/service-accounts", Handler: s.requireAdmin(s.handleAdminServiceAccounts), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Create a service account", Body: serviceAccountRequest{},
+				Result: shape{"account": tenant.ServiceAccount{}, "credentials": tenant.ServiceAccountCredentials{}},
+				Status: http.StatusCreated},
+			{Method: http.MethodGet, Summary: "List service accounts",
+				Params: []apiParam{{Name: "tenant_id"}}, Result: []tenant.ServiceAccount{}},
+			{Method: http.MethodPut, Summary: "Set a service account's rate limit", Params: []apiParam{idQuery},
+				Body: serviceAccountLimitRequest{}, Result: tenant.ServiceAccount{}},
+			{Method: http.MethodDelete, Summary: "Delete a service account", Params: []apiParam{idQuery},
+				Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/service-accounts/rotate", Handler: s.requireAdmin(s.handleAdminServiceAccountRotate), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Rotate a service account's credentials now",
+				Params: []apiParam{idQuery}, Result: tenant.CredentialRotation{}},
+		}},
+		{Path: "/admin/audit/verify", Handler: s.requireAdmin(s.handleAdminAuditVerify), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Verify the audit hash chain", Result: audit.VerifyReport{}},
+			{Method: http.MethodPost, Summary: "Anchor the audit chain head now", Result: audit.Anchor{}},
+		}},
+		{Path: "/admin/billing/export", Handler: s.requireAdmin(s.handleAdminBillingExport), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Generate a billing period's export without delivering it",
+				Params: []apiParam{
+					{Name: "period", Description: "Month (YYYY-MM) or day (YYYY-MM-DD); default the last closed period"},
+					{Name: "format", Description: "json (default) or csv"}},
+				Result: tenant.BillingExport{}},
+			{Method: http.MethodPost, Summary: "Regenerate and deliver a billing period's export",
+				Params: []apiParam{{Name: "period", Description: "Month (YYYY-MM) or day (YYYY-MM-DD); default the last closed period"}},
+				Result: billingExportResult{}},
+		}},
+		{Path: "/admin/compliance", Handler: s.requireAdmin(s.handleAdminCompliance), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List a tenant's compliance modules",
+				Params: []apiParam{tenantQuery}, Result: shape{"tenant": "", "modules": []string{}}},
+			{Method: http.MethodPut, Summary: "Set a tenant's compliance modules",
+				Params: []apiParam{tenantQuery}, Body: complianceRequest{}, Result: shape{"tenant": "", "modules": []string{}}},
+		}},
+		{Path: "/admin/compliance/settings", Handler: s.requireAdmin(s.handleAdminTenantSettings), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Get a tenant's security settings",
+				Params: []apiParam{tenantQuery}, Result: tenant.TenantSettings{}},
+			{Method: http.MethodPut, Summary: "Update a tenant's security settings",
+				Params: []apiParam{tenantQuery}, Body: tenant.TenantSettings{}, Result: tenant.TenantSettings{}},
+		}},
+		{Path: "/admin/compliance/status", Handler: s.requireAdmin(s.handleAdminComplianceStatus), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Report a tenant's compliance status",
+				Params: []apiParam{tenantQuery}, Result: complianceStatus{}},
+		}},
+		{Path: "/admin/quarantine", Handler: s.requireAdmin(s.handleAdminQuarantine), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List malware detections",
+				Params: []apiParam{paramAdmin, {Name: "status", Description: "held, discarded, released or deleted"}},
+				Result: []quarantineRecord{}},
+			{Method: http.MethodDelete, Summary: "Delete a held object",
+				Params: []apiParam{idQuery, {Name: "note"}}, Result: quarantineRecord{}},
+		}},
+		{Path: "/admin/quarantine/release", Handler: s.requireAdmin(s.handleAdminQuarantineRelease), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Release a held object to its original key",
+				Params: []apiParam{idQuery, {Name: "note"}}, Result: quarantineRecord{}},
+		}},
+		{Path: "/admin/gdpr/export", Handler: s.requireAdmin(s.handleGDPRExport), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "Export a tenant's data as a tar archive",
+				Params: []apiParam{tenantQuery, {Name: "prefix"}}, Result: rawBody{}},
+		}},
+		{Path: "/admin/gdpr/shred", Handler: s.requireAdmin(s.handleGDPRShred), Ops: []apiOp{
+			{Method: http.MethodPost, Summary: "Crypto-shred a tenant's data",
+				Params: []apiParam{tenantQuery}, Result: gdprReport{}},
+		}},
+		{Path: "/admin/gdpr/reports", Handler: s.requireAdmin(s.handleGDPRReports), Ops: []apiOp{
+			{Method: http.MethodGet, Summary: "List GDPR evidence reports",
+				Params: []apiParam{paramAdmin}, Result: []gdprReport{}},
+		}},
+	}
+}
+
+// registerRoutes serves each route under /v1 and, through the deprecated
+// compatibility shim, at its unversioned path
+func (s *MinIOServer) registerRoutes(mux *http.ServeMux, routes []apiRoute) {
+	for _, rt := range routes {
+		handler := rt.Handler
+		if rt.Deprecated != nil {
+			handler = rt.Deprecated.wrap(handler)
+		}
+		mux.HandleFunc(apiV1+rt.Path, handler)
+
+		legacy := &deprecation{Sunset: s.config.LegacyAPISunset, Successor: apiV1 + rt.Path}
+		mux.HandleFunc(rt.Path, s.countLegacy(legacy.wrap(handler)))
+	}
+}
+
+// countLegacy counts requests to unversioned paths so operators can tell
+// when clients have migrated
+func (s *MinIOServer) countLegacy(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.legacyRequests.Add(1)
+		next(w, r)
+	}
+}
+
+// unversionedPath strips the version prefix from path
+func unversionedPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, apiV1); ok && strings.HasPrefix(rest, "/") {
+		return rest
+	}
+	return path
+}
@@ -0,0 +1,195 @@
+// cmd/server/selftest.go
+// Startup self-test. The configuration is checked before anything is
+// opened, and the data directories, cache and replication are exercised
+// before the node serves requests. Failures that would lose or misplace
+// data refuse startup; the rest start the node degraded, reported in
+// /health, the alerts and the metrics.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/cache"
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/replication"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// selfTestBucket is the replication namespace of the startup probe
+const selfTestBucket = "selftest"
+
+// selfTestTimeout bounds the replication probe
+const selfTestTimeout = 5 * time.Second
+
+// degradedScore scales the health score of a node that failed part of
+// its self-test
+const degradedScore = 0.5
+
+// selfCheck is the outcome of one startup check
+type selfCheck struct {
+	Name  string
+	Err   error
+	Fatal bool // Startup is refused when it fails
+}
+
+// isPowerOfTwo reports whether n is a positive power of two
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// validateConfig checks config for settings that would fail silently:
+// shard and queue sizes that are indexed by mask must be powers of two,
+// and thresholds must be in order
+func validateConfig(config *ServerConfig) error {
+	var errs []error
+	for _, size := range []struct {
+		name string
+		n    int
+	}{
+		{"MINIO_CACHE_SHARDS", config.CacheShards},
+		{"tenant shard count", tenant.V3TenantShardCount},
+		{"cache ring buffer size", cache.V3RingBufferSize},
+		{"replication queue size", replication.V3MaxInflight},
+	} {
+		if !isPowerOfTwo(size.n) {
+			errs = append(errs, fmt.Errorf("%s is %d, not a power of two", size.name, size.n))
+		}
+	}
+
+	if !(config.DiskWarnPercent <= config.DiskReadOnlyPercent && config.DiskReadOnlyPercent <= 100) {
+		errs = append(errs, fmt.Errorf("disk thresholds out of order: warn %.0f%%, read-only %.0f%% (at most 100%%)",
+			config.DiskWarnPercent, config.DiskReadOnlyPercent))
+	}
+	if config.DiskResumePercent >= config.DiskReadOnlyPercent {
+		errs = append(errs, fmt.Errorf("MINIO_DISK_RESUME_PERCENT (%.0f) must be below MINIO_DISK_READONLY_PERCENT (%.0f)",
+			config.DiskResumePercent, config.DiskReadOnlyPercent))
+	}
+	if config.MultipartMinPart <= 0 {
+		errs = append(errs, errors.New("MINIO_MULTIPART_MIN_PART must be positive"))
+	}
+	if config.InternodeAddr != "" && config.InternodeSecret == "" {
+		errs = append(errs, errors.New("MINIO_INTERNODE_ADDR is set without MINIO_INTERNODE_SECRET"))
+	}
+	if err := config.tlsOptions().Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := tenant.BillingPeriodAt(config.BillingPeriod, time.Now()); err != nil {
+		errs = append(errs, err)
+	}
+	if config.BillingTenant != "" && config.BillingBucket == "" {
+		errs = append(errs, errors.New("MINIO_BILLING_TENANT is set with an empty MINIO_BILLING_BUCKET"))
+	}
+	return errors.Join(errs...)
+}
+
+// selfTest exercises the node before it serves. It returns an error if a
+// fatal check fails, or any check in strict mode; other failures leave
+// the node degraded.
+func (s *MinIOServer) selfTest() error {
+	checks := []selfCheck{
+		{Name: "data directory writable", Err: checkWritableDir(s.config.DataDir), Fatal: true},
+		{Name: "multipart directory writable", Err: checkWritableDir(filepath.Join(s.config.DataDir, "multipart")), Fatal: true},
+		{Name: "L2 directory writable", Err: checkWritableDir(s.config.L2Dir)},
+		{Name: "L3 directory writable", Err: checkWritableDir(s.config.L3Dir)},
+		{Name: "cache round trip", Err: s.probeCache(), Fatal: true},
+		{Name: "replication", Err: s.probeReplication()},
+	}
+
+	var fatal, degraded []string
+	for _, c := range checks {
+		if c.Err == nil {
+			fmt.Printf("  - %s: ok\n", c.Name)
+			continue
+		}
+		fmt.Printf("  - %s: FAILED: %v\n", c.Name, c.Err)
+		problem := c.Name + ": " + c.Err.Error()
+		if c.Fatal || s.config.SelfTestStrict {
+			fatal = append(fatal, problem)
+		} else {
+			degraded = append(degraded, problem)
+		}
+	}
+	if len(fatal) > 0 {
+		return fmt.Errorf("self-test failed: %s", strings.Join(fatal, "; "))
+	}
+
+	s.degraded = degraded
+	if len(degraded) > 0 {
+		s.alertManager.Raise(&monitoring.Alert{
+			ID:       "selftest",
+			RuleID:   "selftest",
+			Severity: "warning",
+			Message:  "node started degraded: " + strings.Join(degraded, "; "),
+			Value:    float64(len(degraded)),
+		})
+	}
+	return nil
+}
+
+// checkWritableDir writes, syncs and removes a file in dir
+func checkWritableDir(dir string) error {
+	f, err := os.CreateTemp(dir, ".selftest-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write([]byte("selftest"))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// probeCache stores, reads back and deletes a value under a key no
+// object can have
+func (s *MinIOServer) probeCache() error {
+	ctx := context.Background()
+	key := "\x00selftest/" + newVersionID()
+	want := []byte("selftest " + key)
+	if err := s.cacheManager.Set(ctx, key, want); err != nil {
+		return err
+	}
+	defer s.cacheManager.Delete(ctx, key)
+	got, err := s.cacheManager.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return errors.New("value read back differs")
+	}
+	return nil
+}
+
+// probeReplication replicates a small object to every region and waits
+// for each to acknowledge it
+func (s *MinIOServer) probeReplication() error {
+	regions := s.replicationEngine.Regions()
+	if len(regions) == 0 {
+		return nil
+	}
+	done := make(chan int, 1)
+	err := s.replicationEngine.EnqueueWithPriority(replication.PriorityInteractive, selfTestBucket, "probe", newVersionID(), "",
+		[]byte("selftest"), regions, nil, func(replicated int) { done <- replicated })
+	if err != nil {
+		return err
+	}
+	select {
+	case n := <-done:
+		if n < len(regions) {
+			return fmt.Errorf("%d of %d regions acknowledged", n, len(regions))
+		}
+		return nil
+	case <-time.After(selfTestTimeout):
+		return fmt.Errorf("no acknowledgement within %s", selfTestTimeout)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+const testAdminToken = "test-admin-token"
+
+// testServer is shared by the package's tests, which keep apart by working
+// in tenants of their own: each server reserves the V3 cache's slab pool,
+// about 2 GB of heap
+var testServer *MinIOServer
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "minio-server-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("MINIO_DATA_DIR", dir)
+	os.Setenv("MINIO_ADMIN_TOKEN", testAdminToken)
+	os.Setenv("MINIO_DOMAIN", "s3.example.com")
+	log.SetOutput(io.Discard)
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	testServer, err = NewMinIOServer(loadConfig())
+	os.Stdout = stdout
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "NewMinIOServer:", err)
+		os.Exit(1)
+	}
+	// Delivery to the configured regions is simulated
+	if err := testServer.replicationEngine.Start(testServer.ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Starting replication:", err)
+		os.Exit(1)
+	}
+	go testServer.runFeedReplication()
+
+	code := m.Run()
+	testServer.cancel()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// credential authenticates a test request
+type credential func(r *http.Request)
+
+// adminAuth sends the admin token
+func adminAuth(r *http.Request) { r.Header.Set("Authorization", "Bearer "+testAdminToken) }
+
+// bearer sends a tenant token
+func bearer(token string) credential {
+	return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+}
+
+// basic sends service account keys
+func basic(accessKey, secretKey string) credential {
+	return func(r *http.Request) { r.SetBasicAuth(accessKey, secretKey) }
+}
+
+// do sends a request through the server's middleware and routes. A string
+// body is sent as is; anything else but nil as JSON.
+func do(t *testing.T, method, target string, body interface{}, creds ...credential) *httptest.ResponseRecorder {
+	t.Helper()
+	var rd io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		rd = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rd = strings.NewReader(string(data))
+	}
+	r := httptest.NewRequest(method, target, rd)
+	r.RemoteAddr = "192.0.2.1:40000"
+	for _, c := range creds {
+		c(r)
+	}
+	w := httptest.NewRecorder()
+	testServer.httpServer.Handler.ServeHTTP(w, r)
+	return w
+}
+
+// decode parses w's JSON body into v
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("Decoding %q: %v", w.Body.String(), err)
+	}
+}
+
+// expectStatus fails t unless w has status
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("Status %d, want %d: %s", w.Code, status, strings.TrimSpace(w.Body.String()))
+	}
+}
+
+// newTenant provisions an enterprise tenant (no quotas or rate limit)
+func newTenant(t *testing.T) string {
+	t.Helper()
+	w := do(t, "POST", "/v1/admin/tenants", map[string]string{"name": "t" + fmt.Sprint(time.Now().UnixNano()), "plan": "enterprise"}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+	var info tenantInfo
+	decode(t, w, &info)
+	return info.ID
+}
+
+// tenantToken issues a token for subject in tenantID with permissions
+func tenantToken(t *testing.T, tenantID, subject string, permissions ...string) string {
+	t.Helper()
+	token, err := testServer.tenantManager.IssueSubjectToken(context.Background(), tenantID, subject, permissions, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// serviceAccount creates an account in tenantID, returning its credential
+func serviceAccount(t *testing.T, tenantID string, permissions ...string) credential {
+	t.Helper()
+	_, creds, err := testServer.tenantManager.CreateServiceAccount(context.Background(), tenantID,
+		tenant.ServiceAccountOptions{Name: "sa", Permissions: permissions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return basic(creds.AccessKey, creds.SecretKey)
+}
+
+// upload stores key in tenantID as the admin
+func upload(t *testing.T, tenantID, key, data string) {
+	t.Helper()
+	w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key="+key, data, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+}
+
+// scrape reads the server's Prometheus metrics
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	testServer.handleMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+	expectStatus(t, w, http.StatusOK)
+	return w.Body.String()
+}
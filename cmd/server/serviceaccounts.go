@@ -0,0 +1,211 @@
+// cmd/server/serviceaccounts.go
+// Service account administration, scheduled key rotation, and the
+// credentials endpoint CI systems poll for the current key
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// serviceAccountRotationCheck is how often rotation deadlines are checked
+const serviceAccountRotationCheck = time.Minute
+
+// serviceAccountRotator rotates keys as their periods elapse
+func (s *MinIOServer) serviceAccountRotator() {
+	ticker := time.NewTicker(serviceAccountRotationCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			rotations, err := s.tenantManager.RotateDueServiceAccounts(s.ctx)
+			if err != nil {
+				log.Printf("Service account rotation error: %v", err)
+			}
+			for _, rot := range rotations {
+				s.auditRotation(s.ctx, rot)
+			}
+		}
+	}
+}
+
+func (s *MinIOServer) auditRotation(ctx context.Context, rot tenant.CredentialRotation) {
+	s.logAudit(ctx, audit.Event{TenantID: rot.TenantID, Actor: "system", Action: "iam.sa_rotate", Resource: rot.AccountID,
+		Details: map[string]string{
+			"reason":         rot.Reason,
+			"old_access_key": rot.OldAccessKey,
+			"new_access_key": rot.NewAccessKey,
+			"old_expires_at": rot.OldExpiresAt.Format(time.RFC3339),
+		}})
+}
+
+// serviceAccountRequest is the body of POST /admin/service-accounts
+type serviceAccountRequest struct {
+	TenantID       string   `json:"tenant_id"`
+	Name           string   `json:"name"`
+	Permissions    []string `json:"permissions"`
+	RotationPeriod string   `json:"rotation_period"` // Go duration, default 24h
+	OverlapWindow  string   `json:"overlap_window"`  // Go duration, default 1h
+	RateLimit      int64    `json:"rate_limit"`      // Requests per second, default only the tenant's limit
+}
+
+// serviceAccountLimitRequest is the body of PUT /admin/service-accounts
+type serviceAccountLimitRequest struct {
+	RateLimit int64 `json:"rate_limit"`
+}
+
+// handleAdminServiceAccounts creates (POST), lists (GET, optionally by
+// ?tenant_id=), sets the rate limit of (PUT ?id=) or deletes (DELETE ?id=)
+// service accounts
+func (s *MinIOServer) handleAdminServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req serviceAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid service account request", http.StatusBadRequest)
+			return
+		}
+		opts := tenant.ServiceAccountOptions{Name: req.Name, Permissions: req.Permissions, RateLimit: req.RateLimit}
+		for _, d := range []struct {
+			raw string
+			dst *time.Duration
+		}{{req.RotationPeriod, &opts.RotationPeriod}, {req.OverlapWindow, &opts.OverlapWindow}} {
+			if d.raw == "" {
+				continue
+			}
+			v, err := time.ParseDuration(d.raw)
+			if err != nil {
+				writeErrorMessage(w, r, "Invalid duration: "+d.raw, http.StatusBadRequest)
+				return
+			}
+			*d.dst = v
+		}
+
+		acct, creds, err := s.tenantManager.CreateServiceAccount(r.Context(), req.TenantID, opts)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: "admin", Action: "iam.sa_create", Resource: acct.ID,
+			Details: map[string]string{"name": acct.Name, "permissions": strings.Join(acct.Permissions, ","), "access_key": creds.AccessKey}})
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"account": acct, "credentials": creds})
+
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.tenantManager.ListServiceAccounts(r.Context(), r.URL.Query().Get("tenant_id")))
+
+	case http.MethodPut:
+		var req serviceAccountLimitRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid service account request", http.StatusBadRequest)
+			return
+		}
+		id := r.URL.Query().Get("id")
+		acct, err := s.tenantManager.SetServiceAccountRateLimit(r.Context(), "", id, req.RateLimit)
+		if errors.Is(err, tenant.ErrServiceAccountNotFound) {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: acct.TenantID, Actor: "admin", Action: "iam.sa_limit", Resource: id,
+			Details: map[string]string{"rate_limit": strconv.FormatInt(req.RateLimit, 10)}})
+		writeJSON(w, http.StatusOK, acct)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if err := s.tenantManager.DeleteServiceAccount(r.Context(), "", id); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{Actor: "admin", Action: "iam.sa_delete", Resource: id})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminServiceAccountRotate rotates ?id= immediately (POST), e.g. after a leak
+func (s *MinIOServer) handleAdminServiceAccountRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rot, err := s.tenantManager.RotateServiceAccount(r.Context(), r.URL.Query().Get("id"))
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.auditRotation(r.Context(), *rot)
+	writeJSON(w, http.StatusOK, rot)
+}
+
+// handleServiceAccountCredentials returns the current key pair and a scoped
+// tenant token to a caller presenting any still-valid key via HTTP Basic
+// auth (access key as user, secret as password). CI jobs poll this within
+// the overlap window to follow rotations.
+func (s *MinIOServer) handleServiceAccountCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	accessKey, secretKey, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="service-accounts"`)
+		writeErrorMessage(w, r, "Missing service account credentials", http.StatusUnauthorized)
+		return
+	}
+
+	acct, err := s.tenantManager.VerifyServiceAccountCredential(r.Context(), accessKey, secretKey)
+	if err != nil {
+		s.logAudit(r.Context(), audit.Event{Actor: accessKey, Action: "iam.sa_fetch", Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"error": err.Error()}})
+		status := http.StatusUnauthorized
+		if errors.Is(err, tenant.ErrCredentialExpired) {
+			status = http.StatusGone
+		}
+		writeErrorMessage(w, r, err.Error(), status)
+		return
+	}
+
+	creds, err := s.tenantManager.ServiceAccountCredentialsFor(r.Context(), acct.ID)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ttl := time.Hour
+	if remaining := time.Until(creds.ExpiresAt); remaining < ttl {
+		ttl = remaining
+	}
+	token, err := s.tenantManager.IssueSubjectToken(r.Context(), acct.TenantID, acct.ID, acct.Permissions, ttl)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	s.logAudit(r.Context(), audit.Event{TenantID: acct.TenantID, Actor: acct.ID, Action: "iam.sa_fetch",
+		Details: map[string]string{"presented_key": accessKey, "current_key": creds.AccessKey}})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"account":      acct,
+		"credentials":  creds,
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_at":   time.Now().Add(ttl).UTC(),
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// serviceAccountCreated is the response to creating a service account
+type serviceAccountCreated struct {
+	Account     tenant.ServiceAccount            `json:"account"`
+	Credentials tenant.ServiceAccountCredentials `json:"credentials"`
+}
+
+// After a rotation both keys fetch the current credentials until the old
+// one's overlap window ends, and a deleted account's keys stop working
+func TestServiceAccountRotation(t *testing.T) {
+	tenantID := newTenant(t)
+	w := do(t, "POST", "/v1/admin/service-accounts", map[string]interface{}{"tenant_id": tenantID, "name": "ci"})
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "POST", "/v1/admin/service-accounts", map[string]interface{}{"tenant_id": tenantID, "name": "ci", "rotation_period": "soon"}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "POST", "/v1/admin/service-accounts", map[string]interface{}{
+		"tenant_id": tenantID, "name": "ci", "permissions": []string{"object:read"}, "rotation_period": "48h", "overlap_window": "2h",
+	}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+	var created serviceAccountCreated
+	decode(t, w, &created)
+	acct, old := created.Account, created.Credentials
+	if acct.TenantID != tenantID || acct.RotationPeriod != "48h0m0s" || old.SecretKey == "" {
+		t.Fatalf("Created %+v", created)
+	}
+
+	var listed []tenant.ServiceAccount
+	w = do(t, "GET", "/v1/admin/service-accounts?tenant_id="+tenantID, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &listed); len(listed) != 1 || listed[0].ID != acct.ID {
+		t.Errorf("Listed %+v, want the account", listed)
+	}
+
+	w = do(t, "POST", "/v1/admin/service-accounts/rotate?id="+acct.ID, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var rot tenant.CredentialRotation
+	if decode(t, w, &rot); rot.OldAccessKey != old.AccessKey || rot.NewAccessKey == old.AccessKey {
+		t.Fatalf("Rotation %+v, want a new key", rot)
+	}
+
+	fetch := "/v1/iam/service-accounts/credentials"
+	w = do(t, "GET", fetch, nil)
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "GET", fetch, nil, basic(old.AccessKey, "wrong"))
+	expectStatus(t, w, http.StatusUnauthorized)
+	var current struct {
+		Credentials tenant.ServiceAccountCredentials `json:"credentials"`
+		AccessToken string                           `json:"access_token"`
+	}
+	w = do(t, "GET", fetch, nil, basic(old.AccessKey, old.SecretKey))
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &current); current.Credentials.AccessKey != rot.NewAccessKey || current.AccessToken == "" {
+		t.Fatalf("Fetched %+v, want the new key and a token", current)
+	}
+	w = do(t, "GET", fetch, nil, basic(current.Credentials.AccessKey, current.Credentials.SecretKey))
+	expectStatus(t, w, http.StatusOK)
+
+	// The token carries the account's permissions
+	w = do(t, "GET", "/v1/list?tenant_id="+tenantID, nil, bearer(current.AccessToken))
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=a.txt", "data", bearer(current.AccessToken))
+	expectStatus(t, w, http.StatusForbidden)
+
+	w = do(t, "DELETE", "/v1/admin/service-accounts?id="+acct.ID, nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "GET", fetch, nil, basic(current.Credentials.AccessKey, current.Credentials.SecretKey))
+	expectStatus(t, w, http.StatusUnauthorized)
+	w = do(t, "DELETE", "/v1/admin/service-accounts?id="+acct.ID, nil, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,161 @@
+// cmd/server/sharelinks.go
+// Tenant and admin endpoints for managed share links
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// shareLinkRequest is the optional JSON body of POST /share
+type shareLinkRequest struct {
+	ExpiresIn    string `json:"expires_in"` // Go duration, e.g. "24h"
+	MaxDownloads int64  `json:"max_downloads"`
+	Password     string `json:"password"`
+}
+
+// shareLinkResponse is a share link with the URL that redeems it, on the
+// tenant's virtual host when virtual-hosted addressing is enabled
+type shareLinkResponse struct {
+	tenant.ShareLink
+	URL string `json:"url"`
+}
+
+// redeemShareLink validates token (password via X-Share-Password or ?password)
+// and maps share link failures onto HTTP statuses
+func (s *MinIOServer) redeemShareLink(r *http.Request, token string) (*tenant.ShareLink, error) {
+	password := r.Header.Get("X-Share-Password")
+	if password == "" {
+		password = r.URL.Query().Get("password")
+	}
+
+	link, err := s.tenantManager.RedeemShareLink(r.Context(), token, password)
+	if err != nil {
+		s.logAudit(r.Context(), audit.Event{Action: "share.download", Resource: tenant.ShareTokenID(token), Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"error": err.Error()}})
+
+		switch {
+		case errors.Is(err, tenant.ErrShareLinkPassword):
+			return nil, &httpError{http.StatusUnauthorized, codeShareLinkPassword, err.Error()}
+		case errors.Is(err, tenant.ErrShareLinkExpired), errors.Is(err, tenant.ErrShareLinkExhausted):
+			return nil, &httpError{http.StatusGone, codeShareLinkExpired, err.Error()}
+		default:
+			return nil, &httpError{http.StatusNotFound, codeNotFound, err.Error()}
+		}
+	}
+
+	s.logAudit(r.Context(), audit.Event{TenantID: link.TenantID, Action: "share.download", Resource: link.Key,
+		Details: map[string]string{"link": link.ID, "downloads": strconv.FormatInt(link.Downloads, 10)}})
+	return link, nil
+}
+
+// handleShare creates (POST ?key=), lists (GET) or revokes (DELETE ?token=)
+// the requesting tenant's share links. Creating a link needs read access to
+// the object; a principal lists and revokes only the links it created.
+func (s *MinIOServer) handleShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	p := s.requestPrincipal(r)
+
+	switch r.Method {
+	case http.MethodPost:
+		key := r.URL.Query().Get("key")
+		if err := s.authorize(p, tenantID, key, actionRead); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, err := s.index.Get(tenantID, key); err != nil {
+			writeErrorMessage(w, r, "Object not found", http.StatusNotFound)
+			return
+		}
+
+		var req shareLinkRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeErrorMessage(w, r, "Invalid share link request", http.StatusBadRequest)
+				return
+			}
+		}
+		opts := tenant.ShareLinkOptions{MaxDownloads: req.MaxDownloads, Password: req.Password, CreatedBy: p.actor(tenantID)}
+		if req.ExpiresIn != "" {
+			ttl, err := time.ParseDuration(req.ExpiresIn)
+			if err != nil || ttl <= 0 {
+				writeErrorMessage(w, r, "Invalid expires_in", http.StatusBadRequest)
+				return
+			}
+			opts.ExpiresAt = time.Now().Add(ttl).UTC()
+		}
+
+		link, err := s.tenantManager.CreateShareLink(ctx, tenantID, key, opts)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "share.create", Resource: key,
+			Details: map[string]string{"link": link.ID}})
+		writeJSON(w, http.StatusOK, shareLinkResponse{*link, s.shareURL(r, tenantID, link.Token)})
+
+	case http.MethodGet:
+		links := s.tenantManager.ListShareLinks(ctx, tenantID)
+		resp := make([]shareLinkResponse, 0, len(links))
+		for _, link := range links {
+			if p == nil || link.CreatedBy == p.actor(tenantID) {
+				resp = append(resp, shareLinkResponse{link, s.shareURL(r, tenantID, link.Token)})
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodDelete:
+		token := r.URL.Query().Get("token")
+		if link, err := s.tenantManager.GetShareLink(ctx, token); err == nil && link.TenantID == tenantID &&
+			p != nil && link.CreatedBy != p.actor(tenantID) {
+			writeError(w, r, errNotLinkOwner)
+			return
+		}
+		if err := s.tenantManager.RevokeShareLink(ctx, tenantID, token); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logAudit(ctx, audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "share.revoke", Resource: tenant.ShareTokenID(token)})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminShareLinks lists (GET ?tenant=) or revokes (DELETE ?token=) any share link
+func (s *MinIOServer) handleAdminShareLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.tenantManager.ListShareLinks(ctx, r.URL.Query().Get("tenant")))
+
+	case http.MethodDelete:
+		token := r.URL.Query().Get("token")
+		if err := s.tenantManager.RevokeShareLink(ctx, "", token); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logAudit(ctx, audit.Event{Actor: "admin", Action: "share.revoke", Resource: tenant.ShareTokenID(token)})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// Creating a share link needs read access to the object, and principals
+// see and revoke only the links they created
+func TestShareLinkAuthorization(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	base := "/v1/share?tenant_id=" + tenantID
+
+	writer := bearer(tenantToken(t, tenantID, "writer", "object:write"))
+	w := do(t, "POST", base+"&key=doc.txt", nil, writer)
+	expectStatus(t, w, http.StatusForbidden)
+
+	alice := bearer(tenantToken(t, tenantID, "alice", "object:read"))
+	w = do(t, "POST", base+"&key=doc.txt", nil, alice)
+	expectStatus(t, w, http.StatusOK)
+	var link shareLinkResponse
+	decode(t, w, &link)
+	if link.CreatedBy != "alice" {
+		t.Errorf("CreatedBy = %q, want alice", link.CreatedBy)
+	}
+
+	bob := bearer(tenantToken(t, tenantID, "bob", "object:read"))
+	var links []shareLinkResponse
+	w = do(t, "GET", base, nil, bob)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &links); len(links) != 0 {
+		t.Errorf("Bob listed %d links, want none", len(links))
+	}
+	w = do(t, "GET", base, nil, alice)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &links); len(links) != 1 || links[0].Token != link.Token {
+		t.Errorf("Alice listed %+v, want her link", links)
+	}
+	w = do(t, "GET", base, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if decode(t, w, &links); len(links) != 1 {
+		t.Errorf("Admin listed %d links, want 1", len(links))
+	}
+
+	w = do(t, "DELETE", base+"&token="+link.Token, nil, bob)
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "DELETE", base+"&token="+link.Token, nil, alice)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "GET", "/v1/download?share="+link.Token, nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+// A suspended tenant's principals cannot create share links
+func TestShareLinkSuspendedTenant(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	w := do(t, "POST", "/v1/admin/tenants/suspend?id="+tenantID+"&reason=billing", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+
+	reader := bearer(tenantToken(t, tenantID, "alice", "object:read"))
+	w = do(t, "POST", "/v1/share?tenant_id="+tenantID+"&key=doc.txt", nil, reader)
+	expectStatus(t, w, http.StatusForbidden)
+}
+
+// Audit events name share links by ID and never record their tokens
+func TestShareLinkAudit(t *testing.T) {
+	tenantID := newTenant(t)
+	if err := testServer.tenantManager.UpdateSettings(context.Background(), tenantID, tenant.TenantSettings{AuditLogging: true}); err != nil {
+		t.Fatal(err)
+	}
+	upload(t, tenantID, "doc.txt", "hello")
+	w := do(t, "POST", "/v1/share?tenant_id="+tenantID+"&key=doc.txt", nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var link shareLinkResponse
+	decode(t, w, &link)
+	if link.ID == "" || link.ID != tenant.ShareTokenID(link.Token) {
+		t.Fatalf("Link ID %q, want the token's", link.ID)
+	}
+	w = do(t, "GET", "/v1/download?share="+link.Token, nil)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "DELETE", "/v1/share?tenant_id="+tenantID+"&token="+link.Token, nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	w = do(t, "GET", "/v1/download?share="+link.Token, nil)
+	expectStatus(t, w, http.StatusNotFound)
+
+	data, err := os.ReadFile(testServer.auditLog.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), link.Token) {
+		t.Error("Audit log records a share token")
+	}
+	actions := make(map[string]int)
+	for _, ev := range auditEvents(t, tenantID) {
+		if ev.Resource == link.ID || ev.Details["link"] == link.ID {
+			actions[ev.Action]++
+		}
+	}
+	if actions["share.create"] != 1 || actions["share.download"] != 1 || actions["share.revoke"] != 1 {
+		t.Errorf("Audit events naming the link: %v", actions)
+	}
+	if !strings.Contains(string(data), `"resource":"`+link.ID+`","outcome":"denied"`) {
+		t.Error("No denied download naming the link")
+	}
+}
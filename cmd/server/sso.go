@@ -0,0 +1,233 @@
+// cmd/server/sso.go
+// OIDC single sign-on for the admin and tenant console: browser login,
+// console sessions, and exchange of IdP identities for tenant API tokens
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/identity"
+)
+
+const (
+	sessionCookie = "minio_session"
+
+	// maxExchangedTokenTTL caps API tokens obtained through SSO
+	maxExchangedTokenTTL = 12 * time.Hour
+)
+
+// ssoEnabled reports whether an identity provider is configured
+func (s *MinIOServer) ssoEnabled() bool {
+	return s.oidc != nil
+}
+
+// session returns the console session attached to r, if any
+func (s *MinIOServer) session(r *http.Request) (*identity.Session, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || s.sessions == nil {
+		return nil, false
+	}
+	return s.sessions.Get(cookie.Value)
+}
+
+// requestIdentity authenticates r by console session or a Bearer IdP ID token
+func (s *MinIOServer) requestIdentity(r *http.Request) (*identity.Identity, error) {
+	if sess, ok := s.session(r); ok {
+		return sess.Identity, nil
+	}
+	raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if raw == "" || !s.ssoEnabled() {
+		return nil, &httpError{http.StatusUnauthorized, codeUnauthorized, "Not logged in"}
+	}
+	id, err := s.oidc.VerifyIDToken(r.Context(), raw)
+	if err != nil {
+		return nil, &httpError{http.StatusUnauthorized, codeUnauthorized, err.Error()}
+	}
+	return id, nil
+}
+
+// safeReturnTo only allows redirects back into this server
+func safeReturnTo(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return "/"
+	}
+	return target
+}
+
+// handleSSOLogin redirects the browser to the identity provider
+func (s *MinIOServer) handleSSOLogin(w http.ResponseWriter, r *http.Request) {
+	if !s.ssoEnabled() {
+		writeErrorMessage(w, r, "SSO not configured", http.StatusNotFound)
+		return
+	}
+
+	target, err := s.oidc.AuthCodeURL(r.Context(), safeReturnTo(r.URL.Query().Get("return_to")))
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleSSOCallback completes the login and starts a console session
+func (s *MinIOServer) handleSSOCallback(w http.ResponseWriter, r *http.Request) {
+	if !s.ssoEnabled() {
+		writeErrorMessage(w, r, "SSO not configured", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	if idpErr := query.Get("error"); idpErr != "" {
+		s.logAudit(r.Context(), audit.Event{Action: "sso.login", Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"error": idpErr, "description": query.Get("error_description")}})
+		writeErrorMessage(w, r, "Login failed: "+idpErr, http.StatusUnauthorized)
+		return
+	}
+
+	id, returnTo, err := s.oidc.Exchange(r.Context(), query.Get("code"), query.Get("state"))
+	if err != nil {
+		s.logAudit(r.Context(), audit.Event{Action: "sso.login", Outcome: audit.OutcomeDenied,
+			Details: map[string]string{"error": err.Error()}})
+		writeErrorMessage(w, r, "Login failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	sess := s.sessions.Create(id)
+	s.logAudit(r.Context(), audit.Event{Actor: id.Subject, Action: "sso.login", Resource: sess.ID[:8],
+		Details: map[string]string{"email": id.Email, "tenants": strings.Join(id.Tenants, ","), "admin": strconv.FormatBool(id.Admin)}})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sess.ID,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		HttpOnly: true,
+		Secure:   requestIsTLS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// handleSSOSession describes the caller's console session (GET)
+func (s *MinIOServer) handleSSOSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(r)
+	if !ok {
+		writeErrorMessage(w, r, "Not logged in", http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, sess)
+}
+
+// handleSSOLogout ends the caller's console session (POST)
+func (s *MinIOServer) handleSSOLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if sess, ok := s.session(r); ok {
+		s.sessions.Revoke(sess.ID)
+		s.logAudit(r.Context(), audit.Event{Actor: sess.Identity.Subject, Action: "sso.logout", Resource: sess.ID[:8]})
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+
+	resp := map[string]string{"status": "logged_out"}
+	if s.ssoEnabled() {
+		if endSession := s.oidc.EndSessionURL(r.Context()); endSession != "" {
+			resp["end_session_url"] = endSession
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tokenExchangeRequest is the body of POST /sso/token
+type tokenExchangeRequest struct {
+	TenantID  string `json:"tenant_id"`
+	ExpiresIn string `json:"expires_in"` // Go duration, default 1h
+}
+
+// handleSSOToken exchanges an SSO identity for a tenant API token (POST)
+func (s *MinIOServer) handleSSOToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := s.requestIdentity(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req tokenExchangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid token request", http.StatusBadRequest)
+		return
+	}
+
+	ttl := time.Hour
+	if req.ExpiresIn != "" {
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			writeErrorMessage(w, r, "Invalid expires_in", http.StatusBadRequest)
+			return
+		}
+	}
+	if ttl > maxExchangedTokenTTL {
+		ttl = maxExchangedTokenTTL
+	}
+
+	if !id.HasTenant(req.TenantID) && !id.Admin {
+		s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: id.Subject, Action: "sso.token_exchange", Outcome: audit.OutcomeDenied})
+		writeErrorMessage(w, r, "Identity is not mapped to tenant", http.StatusForbidden)
+		return
+	}
+
+	token, err := s.tenantManager.IssueSubjectToken(r.Context(), req.TenantID, id.Subject, id.Permissions(), ttl)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	s.logAudit(r.Context(), audit.Event{TenantID: req.TenantID, Actor: id.Subject, Action: "sso.token_exchange",
+		Details: map[string]string{"expires_in": ttl.String()}})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"tenant_id":    req.TenantID,
+		"expires_at":   time.Now().Add(ttl).UTC(),
+	})
+}
+
+// handleAdminSessions lists console sessions (GET) or revokes them by
+// ?id= or by IdP ?subject= (DELETE)
+func (s *MinIOServer) handleAdminSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.sessions.List())
+
+	case http.MethodDelete:
+		query := r.URL.Query()
+		revoked := 0
+		if id := query.Get("id"); id != "" && s.sessions.Revoke(id) {
+			revoked = 1
+		}
+		if subject := query.Get("subject"); subject != "" {
+			revoked += s.sessions.RevokeSubject(subject)
+		}
+		if revoked == 0 {
+			writeErrorMessage(w, r, "No matching session", http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{Actor: "admin", Action: "sso.session_revoke",
+			Details: map[string]string{"subject": query.Get("subject"), "count": strconv.Itoa(revoked)}})
+		writeJSON(w, http.StatusOK, map[string]int{"revoked": revoked})
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
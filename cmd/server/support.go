@@ -0,0 +1,502 @@
+// cmd/server/support.go
+// Support bundles: one tar.gz an operator can attach to a support case,
+// holding the node's configuration and environment, recent log lines,
+// stats snapshots, goroutine and heap profiles and health history.
+// Secrets are redacted from the configuration and environment by name,
+// and from every text entry by pattern and by value. Bundles are capped
+// in size; entries that do not fit are cut or left out, and the manifest
+// says which.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+)
+
+const redacted = "[REDACTED]"
+
+// manifestReserve is the part of a bundle's size cap kept for its manifest
+const manifestReserve = 64 << 10
+
+// maxLogLine bounds one captured log line
+const maxLogLine = 4096
+
+// secretName matches the configuration fields and environment variables
+// whose values are redacted outright
+var secretName = regexp.MustCompile(`(?i)secret|token|password|passwd|kek|credential|private`)
+
+// secretPatterns match secrets in free text. The first group is kept, then
+// the secret is redacted, then the second group, if any, is kept.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[^\s"',;]+`),
+	regexp.MustCompile(`(?i)(basic\s+)[A-Za-z0-9+/=]{8,}`),
+	regexp.MustCompile(`\b(m[tr]1\.)[A-Za-z0-9_\-.]+`),
+	regexp.MustCompile(`(?i)([?&](?:token|share|password|secret|signature|x-amz-signature|access_token)=)[^&\s"']+`),
+	regexp.MustCompile(`(://[^/\s:@]+:)[^@\s/]+(@)`),
+}
+
+// logRing keeps the last lines written to the standard logger
+type logRing struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+}
+
+func newLogRing(capacity int) *logRing {
+	return &logRing{lines: make([]string, 0, max(capacity, 1))}
+}
+
+// Write records one log line; the logger writes each line in one call
+func (l *logRing) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p[:min(len(p), maxLogLine)]), "\n")
+	l.mu.Lock()
+	if len(l.lines) < cap(l.lines) {
+		l.lines = append(l.lines, line)
+	} else {
+		l.lines[l.next] = line
+		l.next = (l.next + 1) % len(l.lines)
+	}
+	l.mu.Unlock()
+	return len(p), nil
+}
+
+// tail returns the last n lines (all kept if n <= 0), oldest first
+func (l *logRing) tail(n int) []string {
+	l.mu.Lock()
+	out := append(append(make([]string, 0, len(l.lines)), l.lines[l.next:]...), l.lines[:l.next]...)
+	l.mu.Unlock()
+	if n > 0 && len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+// parseRedactPatterns compiles the operator's redaction patterns, logging
+// and skipping invalid ones
+func parseRedactPatterns(items []string) []*regexp.Regexp {
+	var out []*regexp.Regexp
+	for _, item := range items {
+		re, err := regexp.Compile(item)
+		if err != nil {
+			log.Printf("Ignoring invalid support redaction pattern %q: %v", item, err)
+			continue
+		}
+		out = append(out, re)
+	}
+	return out
+}
+
+// redactor removes secrets from what goes into a support bundle
+type redactor struct {
+	values   []string         // The configured secrets themselves
+	patterns []*regexp.Regexp // The operator's; whole matches are redacted
+}
+
+func (s *MinIOServer) newRedactor() *redactor {
+	rd := &redactor{patterns: s.supportRedact}
+	var collect func(v reflect.Value)
+	collect = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.String:
+			// Short values would redact unrelated text that contains them
+			if len(v.String()) >= 6 {
+				rd.values = append(rd.values, v.String())
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				collect(v.Index(i))
+			}
+		case reflect.Map:
+			for _, k := range v.MapKeys() {
+				collect(v.MapIndex(k))
+			}
+		}
+	}
+	cfg := reflect.ValueOf(s.config).Elem()
+	for i := 0; i < cfg.NumField(); i++ {
+		if secretName.MatchString(cfg.Type().Field(i).Name) {
+			collect(cfg.Field(i))
+		}
+	}
+	// Longest first, so a secret containing another is redacted whole
+	sort.Slice(rd.values, func(i, j int) bool { return len(rd.values[i]) > len(rd.values[j]) })
+	return rd
+}
+
+// text redacts the configured secrets, the built-in patterns and the
+// operator's patterns from s
+func (rd *redactor) text(s string) string {
+	for _, v := range rd.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	}
+	for _, re := range rd.patterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// value renders v for the bundle's configuration, redacting its strings
+// outright if secret, and as text otherwise. Durations are rendered as
+// text, and map keys are kept.
+func (rd *redactor) value(v reflect.Value, secret bool) interface{} {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if secret && v.String() != "" {
+			return redacted
+		}
+		return rd.text(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = rd.value(v.Index(i), secret)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			out[rd.text(fmt.Sprint(k.Interface()))] = rd.value(v.MapIndex(k), secret)
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return rd.value(v.Elem(), secret)
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				out[f.Name] = rd.value(v.Field(i), secret || secretName.MatchString(f.Name))
+			}
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// environment returns the MINIO_* variables, secrets redacted by name
+func (rd *redactor) environment() map[string]string {
+	out := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, "MINIO_") {
+			continue
+		}
+		if secretName.MatchString(name) && value != "" {
+			value = redacted
+		}
+		out[name] = rd.text(value)
+	}
+	return out
+}
+
+// bundleEntry is the manifest's record of one file in a bundle
+type bundleEntry struct {
+	Name      string `json:"name"`
+	Bytes     int    `json:"bytes"`
+	Truncated bool   `json:"truncated,omitempty"` // Cut to fit the size cap
+	Omitted   bool   `json:"omitted,omitempty"`   // Left out to fit the size cap
+	Error     string `json:"error,omitempty"`     // Could not be collected
+}
+
+// bundleManifest describes a support bundle
+type bundleManifest struct {
+	Node      string        `json:"node,omitempty"`
+	Version   string        `json:"version"`
+	CreatedAt time.Time     `json:"created_at"`
+	MaxBytes  int64         `json:"max_bytes"`
+	Redaction []string      `json:"redaction"`
+	Entries   []bundleEntry `json:"entries"`
+}
+
+// bundleWriter writes entries to a tar stream within a size budget
+type bundleWriter struct {
+	tw        *tar.Writer
+	dir       string
+	modTime   time.Time
+	remaining int64
+	entries   []bundleEntry
+}
+
+// write adds a file to the tar stream without charging the budget
+func (b *bundleWriter) write(name string, data []byte) error {
+	hdr := &tar.Header{Name: b.dir + "/" + name, Mode: 0o640, Size: int64(len(data)), ModTime: b.modTime}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+// addText adds a text entry, cut to the remaining budget. Logs keep their
+// tail, where the latest lines are; everything else keeps its head.
+func (b *bundleWriter) addText(name, text string, keepTail bool) error {
+	e := bundleEntry{Name: name}
+	if over := int64(len(text)) - b.remaining; over > 0 {
+		marker := fmt.Sprintf("\n[... %d bytes cut to fit the support bundle size cap ...]\n", over)
+		keep := max(b.remaining-int64(len(marker)), 0)
+		if keep == 0 {
+			e.Omitted = true
+			b.entries = append(b.entries, e)
+			return nil
+		}
+		if keepTail {
+			text = marker + text[int64(len(text))-keep:]
+		} else {
+			text = text[:keep] + marker
+		}
+		e.Truncated = true
+	}
+	e.Bytes = len(text)
+	b.remaining -= int64(len(text))
+	b.entries = append(b.entries, e)
+	return b.write(name, []byte(text))
+}
+
+// addBinary adds an entry that cannot be cut, leaving it out if it does
+// not fit
+func (b *bundleWriter) addBinary(name string, data []byte) error {
+	e := bundleEntry{Name: name}
+	if int64(len(data)) > b.remaining {
+		e.Omitted = true
+		b.entries = append(b.entries, e)
+		return nil
+	}
+	e.Bytes = len(data)
+	b.remaining -= int64(len(data))
+	b.entries = append(b.entries, e)
+	return b.write(name, data)
+}
+
+// addJSON adds v as indented JSON
+func (b *bundleWriter) addJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.failed(name, err)
+		return nil
+	}
+	return b.addText(name, string(data)+"\n", false)
+}
+
+// failed records an entry that could not be collected
+func (b *bundleWriter) failed(name string, err error) {
+	b.entries = append(b.entries, bundleEntry{Name: name, Error: err.Error()})
+}
+
+// snapshotWriter captures a handler's response for a bundle
+type snapshotWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *snapshotWriter) Header() http.Header         { return w.header }
+func (w *snapshotWriter) Write(p []byte) (int, error) { return w.body.Write(p) }
+func (w *snapshotWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// snapshot runs an admin GET handler and returns its response body
+func (s *MinIOServer) snapshot(r *http.Request, handler http.HandlerFunc, query string) (string, error) {
+	req := r.Clone(r.Context())
+	req.Method, req.Body = http.MethodGet, http.NoBody
+	req.URL.RawQuery = query
+	w := &snapshotWriter{header: make(http.Header)}
+	handler(w, req)
+	if w.status >= 300 {
+		return "", fmt.Errorf("status %d: %s", w.status, strings.TrimSpace(w.body.String()))
+	}
+	return w.body.String(), nil
+}
+
+// runtimeInfo describes the Go runtime of the node
+func runtimeInfo() map[string]interface{} {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	gcPercent, limit := gcSettings()
+	return map[string]interface{}{
+		"go_version":      runtime.Version(),
+		"os":              runtime.GOOS,
+		"arch":            runtime.GOARCH,
+		"cpus":            runtime.NumCPU(),
+		"gomaxprocs":      runtime.GOMAXPROCS(0),
+		"goroutines":      runtime.NumGoroutine(),
+		"gc_percent":      gcPercent,
+		"memory_limit":    limit,
+		"heap_alloc":      ms.HeapAlloc,
+		"heap_inuse":      ms.HeapInuse,
+		"heap_sys":        ms.HeapSys,
+		"sys":             ms.Sys,
+		"num_gc":          ms.NumGC,
+		"gc_pause_total":  time.Duration(ms.PauseTotalNs).String(),
+		"last_gc":         time.Unix(0, int64(ms.LastGC)).UTC(),
+		"gc_cpu_fraction": ms.GCCPUFraction,
+	}
+}
+
+// handleAdminSupportBundle streams a support bundle (GET) as a tar.gz.
+// ?profiles=false leaves out the goroutine and heap profiles, and ?logs=
+// limits the log lines.
+func (s *MinIOServer) handleAdminSupportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	profiles := query.Get("profiles") != "false"
+	logLines := 0
+	if raw := query.Get("logs"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeErrorMessage(w, r, "Invalid logs", http.StatusBadRequest)
+			return
+		}
+		logLines = n
+	}
+
+	now := time.Now().UTC()
+	dir := "minio-support-" + now.Format("20060102T150405Z")
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+dir+`.tar.gz"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	gz := gzip.NewWriter(w)
+	b := &bundleWriter{tw: tar.NewWriter(gz), dir: dir, modTime: now.Truncate(time.Second),
+		remaining: max(s.config.SupportBundleMaxBytes-manifestReserve, 0)}
+	rd := s.newRedactor()
+	err := s.writeBundle(r, b, rd, profiles, logLines)
+	if err == nil {
+		err = b.write("manifest.json", s.bundleManifest(b, rd, now))
+	}
+	if err == nil {
+		err = b.tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+
+	ev := audit.Event{Actor: "admin", Action: "support.bundle", Resource: dir,
+		Details: map[string]string{"entries": strconv.Itoa(len(b.entries))}}
+	if err != nil {
+		// The response is under way; the client sees a truncated archive
+		log.Printf("Support bundle %s: %v", dir, err)
+		ev.Outcome = audit.OutcomeError
+		ev.Details["error"] = err.Error()
+	}
+	s.logAudit(r.Context(), ev)
+}
+
+// writeBundle adds every entry but the manifest
+func (s *MinIOServer) writeBundle(r *http.Request, b *bundleWriter, rd *redactor, profiles bool, logLines int) error {
+	config := rd.value(reflect.ValueOf(*s.config), false)
+	if err := b.addJSON("config.json", config); err != nil {
+		return err
+	}
+	if err := b.addJSON("env.json", rd.environment()); err != nil {
+		return err
+	}
+	if err := b.addJSON("runtime.json", runtimeInfo()); err != nil {
+		return err
+	}
+
+	// Health first: it is small and the first thing to read
+	for _, snap := range []struct {
+		name    string
+		handler http.HandlerFunc
+		query   string
+	}{
+		{"health.json", s.handleAdminHealthHistory, "limit=" + strconv.Itoa(maxHealthEvents)},
+		{"stats/disks.json", s.handleAdminDisks, ""},
+		{"stats/cache.json", s.handleAdminCacheStats, ""},
+		{"stats/replication-clocks.json", s.handleAdminReplicationClocks, ""},
+		{"metrics.txt", s.handleMetrics, ""},
+	} {
+		body, err := s.snapshot(r, snap.handler, snap.query)
+		if err != nil {
+			b.failed(snap.name, err)
+			continue
+		}
+		if err := b.addText(snap.name, rd.text(body), false); err != nil {
+			return err
+		}
+	}
+
+	if s.recentLogs == nil {
+		b.failed("logs.txt", fmt.Errorf("log capture is not enabled"))
+	} else {
+		lines := s.recentLogs.tail(logLines)
+		for i, line := range lines {
+			lines[i] = rd.text(line)
+		}
+		if err := b.addText("logs.txt", strings.Join(lines, "\n")+"\n", true); err != nil {
+			return err
+		}
+	}
+
+	if !profiles {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		b.failed("goroutines.txt", err)
+	} else if err := b.addText("goroutines.txt", rd.text(buf.String()), false); err != nil {
+		return err
+	}
+	buf.Reset()
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		b.failed("heap.pprof", err)
+	} else if err := b.addBinary("heap.pprof", buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// bundleManifest renders the manifest of b's entries
+func (s *MinIOServer) bundleManifest(b *bundleWriter, rd *redactor, now time.Time) []byte {
+	m := bundleManifest{
+		Node:      s.config.NodeURL,
+		Version:   Version,
+		CreatedAt: now,
+		MaxBytes:  s.config.SupportBundleMaxBytes,
+		Redaction: []string{
+			"config.json and env.json: values of fields and MINIO_* variables whose names match " + secretName.String(),
+			"all text: the values of those fields, bearer and basic credentials, mt1./mr1. tokens, secret query parameters and URL passwords",
+		},
+		Entries: b.entries,
+	}
+	if len(rd.patterns) > 0 {
+		m.Redaction = append(m.Redaction, fmt.Sprintf("all text: %d MINIO_SUPPORT_REDACT patterns", len(rd.patterns)))
+	}
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return append(data, '\n')
+}
@@ -0,0 +1,245 @@
+// cmd/server/tagging.go
+// Object user metadata and tags. Uploads carry them as x-amz-meta-*
+// headers and an X-Amz-Tagging query string, downloads return the
+// metadata as the same headers, and the tagging endpoint reads and
+// replaces an object's tags without rewriting its data. Tags are indexed
+// with the object and published on the change feed, where replication
+// rules match them.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// Limits on an object's tags and user metadata, as S3 sets them
+const (
+	maxObjectTags    = 10
+	maxTagKeyLen     = 128
+	maxTagValueLen   = 256
+	maxUserMetaBytes = 2 << 10
+)
+
+// userMetaPrefix starts the headers carrying user metadata
+const userMetaPrefix = "X-Amz-Meta-"
+
+// objectTagging is the body of tagging requests and responses
+type objectTagging struct {
+	Key       string            `json:"key,omitempty"`
+	VersionID string            `json:"version_id,omitempty"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// requestAttributes reads the user metadata and tags an upload is
+// written with from its headers
+func requestAttributes(r *http.Request) (metadata.Attributes, error) {
+	var attrs metadata.Attributes
+	size := 0
+	for name, values := range r.Header {
+		if !strings.HasPrefix(name, userMetaPrefix) || len(name) == len(userMetaPrefix) {
+			continue
+		}
+		if attrs.UserMeta == nil {
+			attrs.UserMeta = make(map[string]string)
+		}
+		k := strings.ToLower(name[len(userMetaPrefix):])
+		v := strings.Join(values, ",")
+		attrs.UserMeta[k] = v
+		size += len(k) + len(v)
+	}
+	if size > maxUserMetaBytes {
+		return attrs, &httpError{http.StatusBadRequest, codeMetadataTooLarge,
+			fmt.Sprintf("User metadata is %d bytes; at most %d are allowed", size, maxUserMetaBytes)}
+	}
+
+	if raw := r.Header.Get("X-Amz-Tagging"); raw != "" {
+		tags, err := parseTagging(raw)
+		if err != nil {
+			return attrs, err
+		}
+		attrs.Tags = tags
+	}
+	return attrs, nil
+}
+
+// parseTagging reads tags from a URL-encoded query string, k1=v1&k2=v2
+func parseTagging(raw string) (map[string]string, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, codeInvalidTag, "Tagging must be a URL-encoded query string"}
+	}
+	tags := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) > 1 {
+			return nil, &httpError{http.StatusBadRequest, codeInvalidTag, fmt.Sprintf("Tag %q is repeated", k)}
+		}
+		tags[k] = v[0]
+	}
+	return tags, validateTags(tags)
+}
+
+// validateTags checks tags against S3's limits
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxObjectTags {
+		return &httpError{http.StatusBadRequest, codeInvalidTag, fmt.Sprintf("An object has at most %d tags", maxObjectTags)}
+	}
+	for k, v := range tags {
+		if n := utf8.RuneCountInString(k); n == 0 || n > maxTagKeyLen {
+			return &httpError{http.StatusBadRequest, codeInvalidTag, fmt.Sprintf("Tag keys must be 1 to %d characters", maxTagKeyLen)}
+		}
+		if utf8.RuneCountInString(v) > maxTagValueLen {
+			return &httpError{http.StatusBadRequest, codeInvalidTag, fmt.Sprintf("Tag %q: values are at most %d characters", k, maxTagValueLen)}
+		}
+	}
+	return nil
+}
+
+// setAttributeHeaders returns an object's user metadata as x-amz-meta-*
+// headers and the number of its tags, as S3 does
+func setAttributeHeaders(h http.Header, meta *metadata.ObjectMeta) {
+	for k, v := range meta.UserMeta {
+		h.Set(userMetaPrefix+k, v)
+	}
+	if len(meta.Tags) > 0 {
+		h.Set("X-Amz-Tagging-Count", strconv.Itoa(len(meta.Tags)))
+	}
+}
+
+// handleTagging returns an object's tags (GET), replaces them (PUT, body
+// {"tags": {...}}) or removes them (DELETE)
+func (s *MinIOServer) handleTagging(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := tenantFromRequest(r)
+	key := r.URL.Query().Get("key")
+	if tenantID == "" || key == "" {
+		writeErrorMessage(w, r, "Missing tenant ID or key", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	act := actionWrite
+	if r.Method == http.MethodGet {
+		act = actionRead
+	}
+	if err := s.authorizeObject(r, tenantID, key, act); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var tags map[string]string
+	switch r.Method {
+	case http.MethodGet:
+		meta, err := s.index.Get(tenantID, key)
+		if err != nil {
+			writeError(w, r, errObjectNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, objectTagging{Key: key, VersionID: meta.VersionID, Tags: nonNilTags(meta.Tags)})
+		return
+
+	case http.MethodPut:
+		var req objectTagging
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeErrorMessage(w, r, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := validateTags(req.Tags); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(req.Tags) > 0 {
+			tags = req.Tags
+		}
+
+	case http.MethodDelete:
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.checkWritable(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	meta, err := s.putObjectTags(ctx, tenantID, key, tags, uploadCondition(r))
+	if s.auditsTenant(ctx, tenantID) {
+		ev := audit.Event{TenantID: tenantID, Actor: auditActor(ctx, tenantID), Action: "object.tagging", Resource: key,
+			Details: map[string]string{"tags": strings.Join(sortedTagKeys(tags), ",")}}
+		if err != nil {
+			ev.Outcome = audit.OutcomeError
+			ev.Details["error"] = err.Error()
+		}
+		s.logAudit(ctx, ev)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, objectTagging{Key: key, VersionID: meta.VersionID, Tags: nonNilTags(meta.Tags)})
+}
+
+// putObjectTags replaces the tags of tenantID/key, checking cond under the
+// key's write lock. The object keeps its data and version; only its index
+// entry is rewritten, so the change is journaled and published like any
+// other write.
+func (s *MinIOServer) putObjectTags(ctx context.Context, tenantID, key string, tags map[string]string, cond writeCondition) (*metadata.ObjectMeta, error) {
+	unlock := s.writeLocks.Lock(tenantID, key)
+	defer unlock()
+
+	prev, err := s.index.Get(tenantID, key)
+	if err != nil {
+		return nil, errObjectNotFound
+	}
+	if err := cond.check(prev); err != nil {
+		return nil, err
+	}
+	meta := *prev
+	meta.Tags = tags
+
+	txn, err := s.intentLog.Begin(metadata.Op{Type: metadata.OpPut, Meta: meta, Prev: prev})
+	if err != nil {
+		return nil, errIntentFailed
+	}
+	s.index.Put(meta)
+	txn.OnAbort(func() {
+		s.index.Put(*prev)
+	})
+	if err := txn.Commit(); err != nil {
+		return nil, errCommitFailed
+	}
+	return &meta, nil
+}
+
+// nonNilTags returns tags, or an empty map so responses carry {}
+func nonNilTags(tags map[string]string) map[string]string {
+	if tags == nil {
+		return map[string]string{}
+	}
+	return tags
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,304 @@
+// cmd/server/tenants.go
+// Tenant provisioning (single tenants or whole manifests for platforms
+// onboarding customers programmatically), suspension and deletion, with
+// lifecycle events sent to the configured webhooks
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/minio/enterprise/internal/audit"
+	"github.com/minio/enterprise/internal/notify"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// quotaEventInterval is the minimum gap between quota-exceeded events for
+// one tenant, so a client retrying a rejected upload doesn't flood webhooks
+const quotaEventInterval = time.Hour
+
+var errTenantSuspended = &httpError{http.StatusForbidden, codeTenantSuspended, "Tenant is suspended"}
+
+// tenantInfo is the admin API view of a tenant
+type tenantInfo struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Plan           string    `json:"plan,omitempty"`
+	StorageQuota   int64     `json:"storage_quota"`
+	BandwidthQuota int64     `json:"bandwidth_quota"`
+	IngressQuota   int64     `json:"ingress_quota"`
+	EgressQuota    int64     `json:"egress_quota"`
+	RateLimit      int64     `json:"rate_limit"`
+	Regions        []string  `json:"regions,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Suspension *tenant.Suspension `json:"suspension,omitempty"`
+}
+
+// provisionRequest is the body of POST /admin/tenants/batch
+type provisionRequest struct {
+	Tenants []tenant.TenantSpec `json:"tenants"`
+}
+
+func (s *MinIOServer) tenantInfo(r *http.Request, tenantID string) (tenantInfo, error) {
+	ctx := r.Context()
+	config, err := s.tenantManager.GetTenant(ctx, tenantID)
+	if err != nil {
+		return tenantInfo{}, err
+	}
+	plan, _ := s.tenantManager.TenantPlan(ctx, tenantID)
+	settings, _ := s.tenantManager.Settings(ctx, tenantID)
+	suspension, _ := s.tenantManager.Suspended(ctx, tenantID)
+	return tenantInfo{
+		ID:             tenantID,
+		Name:           string(config.Name[:config.NameLen]),
+		Plan:           plan,
+		StorageQuota:   config.StorageQuota.Load(),
+		BandwidthQuota: config.BandwidthQuota.Load(),
+		IngressQuota:   config.IngressQuota.Load(),
+		EgressQuota:    config.EgressQuota.Load(),
+		RateLimit:      config.RateLimit.Load(),
+		Regions:        settings.Regions,
+		CreatedAt:      time.Unix(0, config.CreatedAt).UTC(),
+		Suspension:     suspension,
+	}, nil
+}
+
+// provisionRegions are the regions a tenant may be restricted to
+func (s *MinIOServer) provisionRegions() []string {
+	return append([]string{s.replicationEngine.SourceRegion()}, s.replicationEngine.Regions()...)
+}
+
+// emitTenantEvent sends a lifecycle event to the webhooks
+func (s *MinIOServer) emitTenantEvent(eventType, tenantID string, data map[string]string) {
+	s.webhooks.Emit(notify.Event{Type: eventType, TenantID: tenantID, Data: data})
+}
+
+// quotaExceeded reports a rejected write, emitting at most one event per
+// tenant per quotaEventInterval
+func (s *MinIOServer) quotaExceeded(ctx context.Context, tenantID string, requested int64) error {
+	now := time.Now()
+	if last, ok := s.quotaEvents.Load(tenantID); ok && now.Sub(last.(time.Time)) < quotaEventInterval {
+		return errQuotaExceeded
+	}
+	s.quotaEvents.Store(tenantID, now)
+
+	data := map[string]string{"requested_bytes": strconv.FormatInt(requested, 10)}
+	if usage, err := s.tenantManager.GetUsage(ctx, tenantID); err == nil {
+		data["storage_used"] = strconv.FormatInt(usage.StorageUsed.Load(), 10)
+	}
+	if config, err := s.tenantManager.GetTenant(ctx, tenantID); err == nil {
+		data["storage_quota"] = strconv.FormatInt(config.StorageQuota.Load(), 10)
+	}
+	s.emitTenantEvent(notify.EventTenantQuotaExceeded, tenantID, data)
+	return errQuotaExceeded
+}
+
+func (s *MinIOServer) auditProvision(ctx context.Context, res tenant.ProvisionResult) {
+	if res.Error != "" || res.Existing {
+		return
+	}
+	s.logAudit(ctx, audit.Event{TenantID: res.TenantID, Actor: "admin", Action: "tenant.create",
+		Details: map[string]string{"name": res.Name, "plan": res.Plan}})
+	s.emitTenantEvent(notify.EventTenantCreated, res.TenantID, map[string]string{"name": res.Name, "plan": res.Plan})
+}
+
+// deleteTenant removes every object and cache entry of the tenant, then
+// the tenant itself
+func (s *MinIOServer) deleteTenant(ctx context.Context, tenantID string) (int, error) {
+	removed := 0
+	for _, meta := range s.index.List(tenantID, "") {
+		if _, err := s.deleteObject(ctx, tenantID, meta.Key); err != nil && err != errObjectNotFound {
+			return removed, err
+		}
+		removed++
+	}
+	s.cacheManager.Tenant(tenantID).Flush("")
+	s.removeDirectory(tenantID)
+	s.quotaEvents.Delete(tenantID)
+	s.rollups.DeleteTenant(tenantID)
+	s.faults.remove(tenantID)
+
+	return removed, s.tenantManager.DeleteTenant(ctx, tenantID)
+}
+
+// handleAdminTenants provisions one tenant (POST), lists tenants (GET),
+// changes one's quotas (PUT ?id=) or deletes one (DELETE ?id=); deleting a
+// tenant that still has objects requires ?purge=true
+func (s *MinIOServer) handleAdminTenants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var spec tenant.TenantSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeErrorMessage(w, r, "Invalid tenant spec", http.StatusBadRequest)
+			return
+		}
+		res, err := s.tenantManager.ProvisionTenant(r.Context(), spec, s.provisionRegions())
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.auditProvision(r.Context(), res)
+
+		status := http.StatusCreated
+		if res.Existing {
+			status = http.StatusOK
+		}
+		info, _ := s.tenantInfo(r, res.TenantID)
+		writeJSON(w, status, info)
+
+	case http.MethodGet:
+		ids := s.tenantManager.ListTenants(r.Context())
+		sort.Strings(ids)
+		tenants := make([]tenantInfo, 0, len(ids))
+		for _, id := range ids {
+			if info, err := s.tenantInfo(r, id); err == nil {
+				tenants = append(tenants, info)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tenants": tenants, "plans": tenant.Plans()})
+
+	case http.MethodPut:
+		tenantID := r.URL.Query().Get("id")
+		var limits tenant.QuotaLimits
+		if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+			writeErrorMessage(w, r, "Invalid quotas", http.StatusBadRequest)
+			return
+		}
+		if _, err := s.tenantManager.GetTenant(r.Context(), tenantID); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err := s.tenantManager.SetQuotas(r.Context(), tenantID, limits); err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		info, err := s.tenantInfo(r, tenantID)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "tenant.quotas",
+			Details: map[string]string{
+				"storage_quota":   strconv.FormatInt(info.StorageQuota, 10),
+				"bandwidth_quota": strconv.FormatInt(info.BandwidthQuota, 10),
+				"ingress_quota":   strconv.FormatInt(info.IngressQuota, 10),
+				"egress_quota":    strconv.FormatInt(info.EgressQuota, 10),
+			}})
+		writeJSON(w, http.StatusOK, info)
+
+	case http.MethodDelete:
+		tenantID := r.URL.Query().Get("id")
+		info, err := s.tenantInfo(r, tenantID)
+		if err != nil {
+			writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+			return
+		}
+		if objects, _ := s.index.Usage(tenantID); objects > 0 && r.URL.Query().Get("purge") != "true" {
+			writeErrorMessage(w, r, "Tenant has objects; pass purge=true to delete them", http.StatusConflict)
+			return
+		}
+
+		removed, err := s.deleteTenant(r.Context(), tenantID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		details := map[string]string{"name": info.Name, "objects_removed": strconv.Itoa(removed)}
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "tenant.delete", Details: details})
+		s.emitTenantEvent(notify.EventTenantDeleted, tenantID, details)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminTenantsBatch provisions every tenant in a manifest. Entries are
+// independent: the response carries one result per entry, in order.
+func (s *MinIOServer) handleAdminTenantsBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req provisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorMessage(w, r, "Invalid tenant manifest", http.StatusBadRequest)
+		return
+	}
+	results, err := s.tenantManager.ProvisionTenants(r.Context(), req.Tenants, s.provisionRegions())
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	created, existing, failed := 0, 0, 0
+	for _, res := range results {
+		switch {
+		case res.Error != "":
+			failed++
+		case res.Existing:
+			existing++
+		default:
+			created++
+		}
+		s.auditProvision(r.Context(), res)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"results":  results,
+		"created":  created,
+		"existing": existing,
+		"failed":   failed,
+	})
+}
+
+// handleAdminTenantSuspend suspends ?id= (POST, optional ?reason=); its
+// data stays in place but every request for it is refused
+func (s *MinIOServer) handleAdminTenantSuspend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID, reason := r.URL.Query().Get("id"), r.URL.Query().Get("reason")
+	changed, err := s.tenantManager.SuspendTenant(r.Context(), tenantID, reason)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	if changed {
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "tenant.suspend",
+			Details: map[string]string{"reason": reason}})
+		s.emitTenantEvent(notify.EventTenantSuspended, tenantID, map[string]string{"reason": reason})
+	}
+
+	info, _ := s.tenantInfo(r, tenantID)
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleAdminTenantResume lifts a suspension of ?id= (POST)
+func (s *MinIOServer) handleAdminTenantResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tenantID := r.URL.Query().Get("id")
+	changed, err := s.tenantManager.ResumeTenant(r.Context(), tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusNotFound)
+		return
+	}
+	if changed {
+		s.logAudit(r.Context(), audit.Event{TenantID: tenantID, Actor: "admin", Action: "tenant.resume"})
+		s.emitTenantEvent(notify.EventTenantResumed, tenantID, nil)
+	}
+
+	info, _ := s.tenantInfo(r, tenantID)
+	writeJSON(w, http.StatusOK, info)
+}
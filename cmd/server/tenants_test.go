@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// storedUsage reads tenantID's record from the usage store
+func storedUsage(t *testing.T, tenantID string) (tenant.UsageRecord, bool) {
+	t.Helper()
+	store, err := tenant.OpenFileTenantStore(testServer.config.TenantUsageStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := store.LoadUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if rec.TenantID == tenantID {
+			return rec, true
+		}
+	}
+	return tenant.UsageRecord{}, false
+}
+
+// Usage reaches the usage store soon after it changes, and leaves it with
+// the tenant
+func TestTenantUsageFlush(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "a.txt", "hello")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec, ok := storedUsage(t, tenantID)
+		if ok && rec.StorageUsed == int64(len("hello")) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Stored usage %+v, want %d bytes", rec, len("hello"))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	body := scrape(t)
+	for _, series := range []string{"tenant_usage_flushed_total ", "tenant_usage_flush_errors_total 0", "tenant_usage_flush_lag_seconds "} {
+		if !strings.Contains(body, "\n"+series) {
+			t.Errorf("No %q in metrics", series)
+		}
+	}
+
+	w := do(t, "DELETE", "/v1/admin/tenants?id="+tenantID+"&purge=true", nil, adminAuth)
+	expectStatus(t, w, http.StatusNoContent)
+	if rec, ok := storedUsage(t, tenantID); ok {
+		t.Errorf("Usage %+v kept after the tenant was deleted", rec)
+	}
+}
+
+// A tenant is limited to its plan's rate, refusals carrying Retry-After
+// and counted in tenant_rate_limited_total
+func TestTenantRateLimit(t *testing.T) {
+	w := do(t, "POST", "/v1/admin/tenants", map[string]string{"name": "t" + fmt.Sprint(time.Now().UnixNano()), "plan": "free"}, adminAuth)
+	expectStatus(t, w, http.StatusCreated)
+	var info tenantInfo
+	if decode(t, w, &info); info.RateLimit != 100 {
+		t.Fatalf("Rate limit %d on the free plan, want 100", info.RateLimit)
+	}
+
+	list := "/v1/list?tenant_id=" + info.ID
+	limited := testServer.tenantManager.GetStats().RateLimited.Load()
+	refused := false
+	for i := 0; i < 1000 && !refused; i++ {
+		w = do(t, "GET", list, nil, adminAuth)
+		if w.Code != http.StatusTooManyRequests {
+			expectStatus(t, w, http.StatusOK)
+			continue
+		}
+		refused = true
+		if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry < 1 {
+			t.Errorf("Retry-After %q, want whole seconds", w.Header().Get("Retry-After"))
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "100" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+			t.Errorf("Refused with headers %v, want the plan's limit and none remaining", w.Header())
+		}
+	}
+	if !refused {
+		t.Fatal("No request refused under a limit of 100 per second")
+	}
+	if n := testServer.tenantManager.GetStats().RateLimited.Load() - limited; n != 1 {
+		t.Errorf("%d refusals counted, want 1", n)
+	}
+	if !strings.Contains(scrape(t), fmt.Sprintf("\ntenant_rate_limited_total %d\n", limited+1)) {
+		t.Error("Refusal not exported in tenant_rate_limited_total")
+	}
+}
+
+// Uploads are charged to the ingress quota and downloads to the egress
+// quota, each refused apart once spent, and the storage quota bounds
+// stored bytes; lifting a quota lets requests through again
+func TestTenantQuotas(t *testing.T) {
+	tenantID := newTenant(t)
+	admin := "/v1/admin/tenants?id=" + tenantID
+	target := "/v1/download?tenant_id=" + tenantID + "&key=a.txt"
+	refused := func(w *httptest.ResponseRecorder, message string) {
+		t.Helper()
+		expectStatus(t, w, http.StatusForbidden)
+		var resp errorResponse
+		if decode(t, w, &resp); resp.Code != codeQuotaExceeded || resp.Message != message {
+			t.Errorf("Refused with %s %q, want %s %q", resp.Code, resp.Message, codeQuotaExceeded, message)
+		}
+	}
+
+	w := do(t, "PUT", admin, map[string]int64{"ingress_quota": 10, "egress_quota": 12}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var info tenantInfo
+	if decode(t, w, &info); info.IngressQuota != 10 || info.EgressQuota != 12 {
+		t.Fatalf("Quotas %d and %d after the update, want 10 and 12", info.IngressQuota, info.EgressQuota)
+	}
+	upload(t, tenantID, "a.txt", "12345678")
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=b.txt", "12345678", adminAuth)
+	refused(w, errIngressQuota.Message)
+	if _, err := testServer.index.Get(tenantID, "b.txt"); err == nil {
+		t.Error("Upload over the ingress quota was stored")
+	}
+
+	w = do(t, "GET", target, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", target, nil, adminAuth)
+	refused(w, errEgressQuota.Message)
+	if strings.Contains(w.Body.String(), "12345678") {
+		t.Error("Download over the egress quota was served")
+	}
+	storage, ingress := usageOf(t, tenantID)
+	usage, err := testServer.tenantManager.GetUsage(context.Background(), tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storage != 8 || ingress != 8 || usage.EgressUsed.Load() != 8 || usage.BandwidthUsed.Load() != 16 {
+		t.Errorf("Usage %d stored, %d in, %d out and %d bandwidth; want 8, 8, 8 and 16",
+			storage, ingress, usage.EgressUsed.Load(), usage.BandwidthUsed.Load())
+	}
+	body := scrape(t)
+	for _, series := range []string{"tenant_ingress_bytes_total", "tenant_egress_bytes_total"} {
+		if !strings.Contains(body, fmt.Sprintf("\n%s{tenant=%q} 8\n", series, tenantID)) {
+			t.Errorf("%s not exported for the tenant", series)
+		}
+	}
+
+	// Lifting the transfer quotas leaves the storage quota to refuse
+	w = do(t, "PUT", admin, map[string]int64{"ingress_quota": 0, "egress_quota": 0, "storage_quota": 12}, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", target, nil, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=b.txt", "12345678", adminAuth)
+	refused(w, errQuotaExceeded.Message)
+	upload(t, tenantID, "b.txt", "1234")
+
+	w = do(t, "PUT", admin, map[string]int64{"egress_quota": -1}, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	w = do(t, "PUT", "/v1/admin/tenants?id=unknown", map[string]int64{"egress_quota": 1}, adminAuth)
+	expectStatus(t, w, http.StatusNotFound)
+}
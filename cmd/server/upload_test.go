@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// A buffered upload's memory follows the body received, not the length
+// its header declares
+func TestUploadOverstatedLength(t *testing.T) {
+	tenantID := newTenant(t)
+	// Encrypted tenants are never streamed
+	if err := testServer.tenantManager.UpdateSettings(context.Background(), tenantID, tenant.TenantSettings{EncryptAtRest: true}); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("PUT", "/v1/upload?tenant_id="+tenantID+"&key=big.bin", strings.NewReader("short"))
+	r.ContentLength = 1 << 30
+	adminAuth(r)
+	w := httptest.NewRecorder()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	testServer.httpServer.Handler.ServeHTTP(w, r)
+	runtime.ReadMemStats(&after)
+	if w.Code == http.StatusOK {
+		t.Errorf("Short body accepted: %s", w.Body.String())
+	}
+	if grown := after.TotalAlloc - before.TotalAlloc; grown > 256<<20 {
+		t.Errorf("Allocated %d bytes for a %d-byte body", grown, len("short"))
+	}
+}
+
+// Streamed and buffered uploads answer alike
+func TestUploadResponse(t *testing.T) {
+	tenantID := newTenant(t)
+	threshold := testServer.config.StreamUploadBytes
+	testServer.config.StreamUploadBytes = 16
+	defer func() { testServer.config.StreamUploadBytes = threshold }()
+
+	for _, data := range []string{"small", strings.Repeat("streamed ", 8)} {
+		w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key=obj", data, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		var resp struct {
+			Status    string `json:"status"`
+			Size      int    `json:"size"`
+			VersionID string `json:"version_id"`
+			ETag      string `json:"etag"`
+		}
+		decode(t, w, &resp)
+		if resp.Status != "uploaded" || resp.Size != len(data) || resp.VersionID == "" {
+			t.Errorf("%d-byte upload answered %+v", len(data), resp)
+		}
+		if resp.ETag == "" || `"`+resp.ETag+`"` != w.Header().Get("ETag") {
+			t.Errorf("%d-byte upload: etag %q, header %q", len(data), resp.ETag, w.Header().Get("ETag"))
+		}
+	}
+}
+
+// Of concurrent writes conditional on the same version, or on the key
+// being absent, exactly one succeeds and the rest get 412, buffered or
+// streamed
+func TestUploadCompareAndSwapRace(t *testing.T) {
+	tenantID := newTenant(t)
+	threshold := testServer.config.StreamUploadBytes
+	testServer.config.StreamUploadBytes = 16
+	defer func() { testServer.config.StreamUploadBytes = threshold }()
+
+	// race sends n writes of key at once, returning their statuses and bodies
+	race := func(key string, n int, size int, cond credential) map[int][]string {
+		t.Helper()
+		statuses := make([]int, n)
+		bodies := make([]string, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			bodies[i] = fmt.Sprintf("%0*d", size, i)
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				statuses[i] = do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key="+key, bodies[i], adminAuth, cond).Code
+			}(i)
+		}
+		wg.Wait()
+		out := make(map[int][]string)
+		for i, code := range statuses {
+			out[code] = append(out[code], bodies[i])
+		}
+		return out
+	}
+	download := func(key string) string {
+		t.Helper()
+		w := do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key="+key, nil, adminAuth)
+		expectStatus(t, w, http.StatusOK)
+		return w.Body.String()
+	}
+
+	for _, size := range []int{8, 64} {
+		key := fmt.Sprintf("cas-%d", size)
+		absent := func(r *http.Request) { r.Header.Set("If-None-Match", "*") }
+		got := race(key, 8, size, absent)
+		if len(got[http.StatusOK]) != 1 || len(got[http.StatusPreconditionFailed]) != 7 {
+			t.Fatalf("%d-byte creates: %d succeeded and %d got 412, want 1 and 7 (%v)", size,
+				len(got[http.StatusOK]), len(got[http.StatusPreconditionFailed]), got)
+		}
+		if body := download(key); body != got[http.StatusOK][0] {
+			t.Errorf("%d-byte creates: stored %q, want the winner's %q", size, body, got[http.StatusOK][0])
+		}
+
+		meta, err := testServer.index.Get(tenantID, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		match := func(r *http.Request) { r.Header.Set("If-Match", `"`+meta.VersionID+`"`) }
+		got = race(key, 8, size, match)
+		if len(got[http.StatusOK]) != 1 || len(got[http.StatusPreconditionFailed]) != 7 {
+			t.Fatalf("%d-byte swaps: %d succeeded and %d got 412, want 1 and 7 (%v)", size,
+				len(got[http.StatusOK]), len(got[http.StatusPreconditionFailed]), got)
+		}
+		if body := download(key); body != got[http.StatusOK][0] {
+			t.Errorf("%d-byte swaps: stored %q, want the winner's %q", size, body, got[http.StatusOK][0])
+		}
+
+		// The version the losers expected is gone
+		w := do(t, "PUT", "/v1/upload?tenant_id="+tenantID+"&key="+key+"&if_version="+meta.VersionID, "late", adminAuth)
+		expectStatus(t, w, http.StatusPreconditionFailed)
+	}
+}
@@ -0,0 +1,533 @@
+// cmd/server/usage.go
+// Tenant-facing usage API: current usage and quota, daily usage history
+// and metric series from the rollup store, and a per-bucket breakdown.
+// Buckets are the first "/"-separated segment of object keys, whether or
+// not the bucket was created.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+	"github.com/minio/enterprise/internal/monitoring"
+	"github.com/minio/enterprise/internal/tenant"
+)
+
+// Rolled up metrics. storage_bytes and objects are gauges; the rest count
+// per interval. Object requests are also counted per class as
+// "requests_<class>". Anonymous reads of public buckets are counted as
+// requests_public, and their egress as public_egress_bytes rather than
+// egress_bytes.
+const (
+	metricStorageBytes     = "storage_bytes"
+	metricStorageByteMins  = "storage_byte_minutes"
+	metricObjects          = "objects"
+	metricRequests         = "requests"
+	metricBandwidthBytes   = "bandwidth_bytes"
+	metricIngressBytes     = "ingress_bytes"
+	metricEgressBytes      = "egress_bytes"
+	metricReplicationBytes = "replication_bytes"
+	metricPublicEgress     = "public_egress_bytes"
+)
+
+// Object request classes
+const (
+	requestRead   = "read"
+	requestWrite  = "write"
+	requestDelete = "delete"
+	requestList   = "list"
+	requestPublic = "public" // Anonymous reads of public buckets
+)
+
+// requestClasses lists the request classes
+var requestClasses = []string{requestRead, requestWrite, requestDelete, requestList, requestPublic}
+
+// rollupSaveInterval is how often the rollups are pruned and saved
+const rollupSaveInterval = 5 * time.Minute
+
+// defaultUsageDays is how many days of history are returned without ?from=
+const defaultUsageDays = 30
+
+// usageDateLayout is the ?from= and ?to= date format
+const usageDateLayout = "2006-01-02"
+
+// tenantUsage is a tenant's current usage against its quotas; a quota of
+// 0 is unlimited. Bandwidth is ingress plus egress.
+type tenantUsage struct {
+	TenantID       string    `json:"tenant_id"`
+	Objects        int64     `json:"objects"`
+	StorageUsed    int64     `json:"storage_used"`
+	StorageQuota   int64     `json:"storage_quota"`
+	BandwidthUsed  int64     `json:"bandwidth_used"`
+	BandwidthQuota int64     `json:"bandwidth_quota"`
+	IngressUsed    int64     `json:"ingress_used"`
+	IngressQuota   int64     `json:"ingress_quota"`
+	EgressUsed     int64     `json:"egress_used"`
+	EgressQuota    int64     `json:"egress_quota"`
+	Requests       int64     `json:"requests"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// quotaInfo is a tenant's storage against its storage quota
+type quotaInfo struct {
+	TenantID   string  `json:"tenant_id"`
+	Used       int64   `json:"used"`
+	Limit      int64   `json:"limit"`
+	Percentage float64 `json:"percentage"`
+}
+
+// usageHistory is a tenant's daily usage between two dates
+type usageHistory struct {
+	TenantID string       `json:"tenant_id"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Days     []dailyUsage `json:"days"`
+}
+
+// dailyUsage is one UTC day of a tenant's usage
+type dailyUsage struct {
+	Date             string `json:"date"`
+	StorageBytes     int64  `json:"storage_bytes"` // Last sampled that day
+	StoragePeakBytes int64  `json:"storage_peak_bytes"`
+	Requests         int64  `json:"requests"`
+	BandwidthBytes   int64  `json:"bandwidth_bytes"`
+	IngressBytes     int64  `json:"ingress_bytes"`
+	EgressBytes      int64  `json:"egress_bytes"`
+	PublicRequests   int64  `json:"public_requests"`
+	PublicEgress     int64  `json:"public_egress_bytes"`
+}
+
+// usageSeries is one rolled up metric of a tenant or one of its buckets
+type usageSeries struct {
+	monitoring.SeriesKey
+	Resolution string                   `json:"resolution"`
+	Points     []monitoring.RollupPoint `json:"points"`
+}
+
+// usageSeriesList lists the series held for a tenant
+type usageSeriesList struct {
+	TenantID string                 `json:"tenant_id"`
+	Series   []monitoring.SeriesKey `json:"series"`
+}
+
+// usageCounters are a tenant's cumulative counters at the last sample
+type usageCounters struct {
+	at                  time.Time
+	requests, bandwidth int64
+	byteMinutes         float64 // Storage byte-minutes not yet recorded, under one
+}
+
+// bucketUsage is a tenant's storage broken down by bucket. Objects whose
+// keys have no "/" belong to no bucket.
+type bucketUsage struct {
+	TenantID          string         `json:"tenant_id"`
+	Buckets           []bucketTotals `json:"buckets"`
+	UnbucketedObjects int64          `json:"unbucketed_objects"`
+	UnbucketedBytes   int64          `json:"unbucketed_bytes"`
+}
+
+// bucketTotals are one bucket's object count and bytes
+type bucketTotals struct {
+	Bucket  string `json:"bucket"`
+	Created bool   `json:"created"` // False for a key prefix no bucket was created for
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// checkTenantCredentials requires r to carry credentials for tenantID: a
+// tenant token, service account keys, a user's session, or admin
+// credentials
+func (s *MinIOServer) checkTenantCredentials(r *http.Request, tenantID string) error {
+	if s.isAdminRequest(r) {
+		return nil
+	}
+	if p := s.requestPrincipal(r); p != nil && p.tenantID == tenantID {
+		return nil
+	}
+	return errNoCredentials
+}
+
+// usageTenant resolves and authorizes the tenant a usage request is for,
+// writing the error response if it fails
+func (s *MinIOServer) usageTenant(w http.ResponseWriter, r *http.Request) (*tenant.V3QuotaUsage, string, bool) {
+	if r.Method != http.MethodGet {
+		writeErrorMessage(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, "", false
+	}
+	tenantID := tenantFromRequest(r)
+	if tenantID == "" {
+		writeErrorMessage(w, r, "Missing tenant ID", http.StatusBadRequest)
+		return nil, "", false
+	}
+	if err := s.checkTenantCredentials(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return nil, "", false
+	}
+	if err := s.checkTenantAccess(r, tenantID); err != nil {
+		writeError(w, r, err)
+		return nil, "", false
+	}
+	usage, err := s.tenantManager.GetUsage(r.Context(), tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, "Tenant not found", http.StatusNotFound)
+		return nil, "", false
+	}
+	return usage, tenantID, true
+}
+
+// handleUsage reports the tenant's current usage and quotas
+func (s *MinIOServer) handleUsage(w http.ResponseWriter, r *http.Request) {
+	usage, tenantID, ok := s.usageTenant(w, r)
+	if !ok {
+		return
+	}
+	config, err := s.tenantManager.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, "Tenant not found", http.StatusNotFound)
+		return
+	}
+	objects, _ := s.index.Usage(tenantID)
+	writeJSON(w, http.StatusOK, tenantUsage{
+		TenantID:       tenantID,
+		Objects:        objects,
+		StorageUsed:    usage.StorageUsed.Load(),
+		StorageQuota:   config.StorageQuota.Load(),
+		BandwidthUsed:  usage.BandwidthUsed.Load(),
+		BandwidthQuota: config.BandwidthQuota.Load(),
+		IngressUsed:    usage.IngressUsed.Load(),
+		IngressQuota:   config.IngressQuota.Load(),
+		EgressUsed:     usage.EgressUsed.Load(),
+		EgressQuota:    config.EgressQuota.Load(),
+		Requests:       usage.RequestCount.Load(),
+		UpdatedAt:      time.Unix(0, usage.LastUpdated.Load()).UTC(),
+	})
+}
+
+// handleQuota reports the tenant's storage against its storage quota
+func (s *MinIOServer) handleQuota(w http.ResponseWriter, r *http.Request) {
+	usage, tenantID, ok := s.usageTenant(w, r)
+	if !ok {
+		return
+	}
+	config, err := s.tenantManager.GetTenant(r.Context(), tenantID)
+	if err != nil {
+		writeErrorMessage(w, r, "Tenant not found", http.StatusNotFound)
+		return
+	}
+	info := quotaInfo{TenantID: tenantID, Used: usage.StorageUsed.Load(), Limit: config.StorageQuota.Load()}
+	if info.Limit > 0 {
+		info.Percentage = float64(info.Used) / float64(info.Limit) * 100
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleUsageHistory reports the tenant's daily usage from ?from= through
+// ?to= (UTC dates, default the last 30 days). Days the node took no sample
+// on are missing.
+func (s *MinIOServer) handleUsageHistory(w http.ResponseWriter, r *http.Request) {
+	_, tenantID, ok := s.usageTenant(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	if raw := query.Get("to"); raw != "" {
+		t, err := time.Parse(usageDateLayout, raw)
+		if err != nil {
+			writeErrorMessage(w, r, "to must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -defaultUsageDays+1)
+	if raw := query.Get("from"); raw != "" {
+		t, err := time.Parse(usageDateLayout, raw)
+		if err != nil {
+			writeErrorMessage(w, r, "from must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if from.After(to) {
+		writeErrorMessage(w, r, "from is after to", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, usageHistory{
+		TenantID: tenantID,
+		From:     from.Format(usageDateLayout),
+		To:       to.Format(usageDateLayout),
+		Days:     s.usageDays(tenantID, from, to),
+	})
+}
+
+// usageDays merges the tenant's daily rollups between from and to
+func (s *MinIOServer) usageDays(tenantID string, from, to time.Time) []dailyUsage {
+	days := make(map[time.Time]*dailyUsage)
+	day := func(t time.Time) *dailyUsage {
+		d := days[t]
+		if d == nil {
+			d = &dailyUsage{Date: t.Format(usageDateLayout)}
+			days[t] = d
+		}
+		return d
+	}
+	query := func(metric string, apply func(d *dailyUsage, p monitoring.RollupPoint)) {
+		key := monitoring.SeriesKey{TenantID: tenantID, Metric: metric}
+		points, _ := s.rollups.Query(key, monitoring.ResolutionDay, from, to)
+		for _, p := range points {
+			apply(day(p.Time), p)
+		}
+	}
+	query(metricStorageBytes, func(d *dailyUsage, p monitoring.RollupPoint) {
+		d.StorageBytes, d.StoragePeakBytes = p.Value, p.Max
+	})
+	query(metricRequests, func(d *dailyUsage, p monitoring.RollupPoint) { d.Requests = p.Value })
+	query(metricBandwidthBytes, func(d *dailyUsage, p monitoring.RollupPoint) { d.BandwidthBytes = p.Value })
+	query(metricIngressBytes, func(d *dailyUsage, p monitoring.RollupPoint) { d.IngressBytes = p.Value })
+	query(metricEgressBytes, func(d *dailyUsage, p monitoring.RollupPoint) { d.EgressBytes = p.Value })
+	query(metricRequests+"_"+requestPublic, func(d *dailyUsage, p monitoring.RollupPoint) { d.PublicRequests = p.Value })
+	query(metricPublicEgress, func(d *dailyUsage, p monitoring.RollupPoint) { d.PublicEgress = p.Value })
+
+	out := make([]dailyUsage, 0, len(days))
+	for _, d := range days {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out
+}
+
+// handleUsageSeries reports one rolled up metric of the tenant, or of one
+// of its buckets with ?bucket=, at ?resolution= (1m, 1h or 1d; default 1h)
+// between ?from= and ?to= (RFC 3339, default the resolution's whole
+// retention). Without ?metric= it lists the tenant's series.
+func (s *MinIOServer) handleUsageSeries(w http.ResponseWriter, r *http.Request) {
+	_, tenantID, ok := s.usageTenant(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	key := monitoring.SeriesKey{TenantID: tenantID, Bucket: query.Get("bucket"), Metric: query.Get("metric")}
+	if key.Metric == "" {
+		series := s.rollups.Series(tenantID)
+		if series == nil {
+			series = []monitoring.SeriesKey{}
+		}
+		writeJSON(w, http.StatusOK, usageSeriesList{TenantID: tenantID, Series: series})
+		return
+	}
+
+	resolution := query.Get("resolution")
+	if resolution == "" {
+		resolution = monitoring.ResolutionHour
+	}
+	retention, err := s.rollups.Retention(resolution)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to := time.Now()
+	if raw := query.Get("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			writeErrorMessage(w, r, "to must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	from := to.Add(-retention)
+	if raw := query.Get("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			writeErrorMessage(w, r, "from must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	if from.After(to) {
+		writeErrorMessage(w, r, "from is after to", http.StatusBadRequest)
+		return
+	}
+
+	points, err := s.rollups.Query(key, resolution, from, to)
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, usageSeries{SeriesKey: key, Resolution: resolution, Points: points})
+}
+
+// handleUsageBuckets reports the tenant's objects and bytes per bucket
+func (s *MinIOServer) handleUsageBuckets(w http.ResponseWriter, r *http.Request) {
+	_, tenantID, ok := s.usageTenant(w, r)
+	if !ok {
+		return
+	}
+	agg, err := s.index.AggregatePrefix(tenantID, "")
+	if err != nil {
+		writeErrorMessage(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	out := bucketUsage{
+		TenantID:          tenantID,
+		Buckets:           make([]bucketTotals, 0, len(agg.Prefixes)),
+		UnbucketedObjects: agg.DirectObjects,
+		UnbucketedBytes:   agg.DirectBytes,
+	}
+	created := make(map[string]bool)
+	for _, b := range s.tenantManager.ListBuckets(r.Context(), tenantID) {
+		created[b.Name] = true
+	}
+	for _, p := range agg.Prefixes {
+		name := strings.TrimSuffix(p.Prefix, metadata.AggregateDelimiter)
+		out.Buckets = append(out.Buckets, bucketTotals{Bucket: name, Created: created[name], Objects: p.Objects, Bytes: p.Bytes})
+		delete(created, name)
+	}
+	// Created buckets with no objects yet
+	for name := range created {
+		out.Buckets = append(out.Buckets, bucketTotals{Bucket: name, Created: true})
+	}
+	sort.Slice(out.Buckets, func(i, j int) bool { return out.Buckets[i].Bucket < out.Buckets[j].Bucket })
+	writeJSON(w, http.StatusOK, out)
+}
+
+// usageSampler samples every tenant's usage into the rollups, pruning and
+// saving them every rollupSaveInterval
+func (s *MinIOServer) usageSampler() {
+	if s.config.UsageSampleInterval <= 0 {
+		return
+	}
+	s.sampleUsage()
+	ticker := time.NewTicker(s.config.UsageSampleInterval)
+	defer ticker.Stop()
+
+	saved := time.Now()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sampleUsage()
+			if now.Sub(saved) >= rollupSaveInterval {
+				s.rollups.Prune()
+				s.saveRollups()
+				saved = now
+			}
+		}
+	}
+}
+
+// sampleUsage records every tenant's storage and objects, and its
+// requests, bandwidth and storage byte-minutes since the last sample. A
+// tenant's first sample only sets its baseline.
+func (s *MinIOServer) sampleUsage() {
+	ctx := context.Background()
+	sampled := time.Now()
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, tenantID := range s.tenantManager.ListTenants(ctx) {
+		usage, err := s.tenantManager.GetUsage(ctx, tenantID)
+		if err != nil {
+			continue
+		}
+		seen[tenantID] = true
+		key := func(bucket, metric string) monitoring.SeriesKey {
+			return monitoring.SeriesKey{TenantID: tenantID, Bucket: bucket, Metric: metric}
+		}
+
+		storage := usage.StorageUsed.Load()
+		now := usageCounters{at: sampled, requests: usage.RequestCount.Load(), bandwidth: usage.BandwidthUsed.Load()}
+		if prev, ok := s.usageBase[tenantID]; ok {
+			s.rollups.Add(key("", metricRequests), counterDelta(prev.requests, now.requests))
+			s.rollups.Add(key("", metricBandwidthBytes), counterDelta(prev.bandwidth, now.bandwidth))
+			// Charged at the storage now held for the whole interval
+			byteMinutes := prev.byteMinutes + float64(storage)*sampled.Sub(prev.at).Minutes()
+			whole := int64(byteMinutes)
+			s.rollups.Add(key("", metricStorageByteMins), whole)
+			now.byteMinutes = byteMinutes - float64(whole)
+		}
+		s.usageBase[tenantID] = now
+
+		objects, _ := s.index.Usage(tenantID)
+		s.rollups.Set(key("", metricStorageBytes), storage)
+		s.rollups.Set(key("", metricObjects), objects)
+		if agg, err := s.index.AggregatePrefix(tenantID, ""); err == nil {
+			for _, p := range agg.Prefixes {
+				bucket := strings.TrimSuffix(p.Prefix, metadata.AggregateDelimiter)
+				s.rollups.Set(key(bucket, metricStorageBytes), p.Bytes)
+				s.rollups.Set(key(bucket, metricObjects), p.Objects)
+			}
+		}
+	}
+	for tenantID := range s.usageBase {
+		if !seen[tenantID] {
+			delete(s.usageBase, tenantID)
+		}
+	}
+}
+
+// counterDelta is how far a counter moved from prev to now; a counter
+// that went backwards was reset
+func counterDelta(prev, now int64) int64 {
+	if now < prev {
+		return now
+	}
+	return now - prev
+}
+
+// saveRollups writes the rollups to the rollup file
+func (s *MinIOServer) saveRollups() {
+	if err := s.rollups.Save(s.config.RollupFile); err != nil {
+		log.Printf("Failed to save usage rollups: %v", err)
+	}
+}
+
+// recordRequest counts a completed object request of class with the bytes
+// it received and sent against the tenant and the key's bucket
+func (s *MinIOServer) recordRequest(tenantID, key, class string, ingress, egress int64) {
+	add := func(bucket, metric string, delta int64) {
+		s.rollups.Add(monitoring.SeriesKey{TenantID: tenantID, Bucket: bucket, Metric: metric}, delta)
+	}
+	egressMetric := metricEgressBytes
+	if class == requestPublic {
+		egressMetric = metricPublicEgress
+	}
+	add("", metricRequests+"_"+class, 1)
+	if ingress > 0 {
+		add("", metricIngressBytes, ingress)
+	}
+	if egress > 0 {
+		add("", egressMetric, egress)
+	}
+	bucket := bucketOf(key)
+	if bucket == "" {
+		return
+	}
+	add(bucket, metricRequests, 1)
+	if ingress > 0 {
+		add(bucket, metricIngressBytes, ingress)
+	}
+	if egress > 0 {
+		add(bucket, egressMetric, egress)
+	}
+}
+
+// recordReplication counts bytes shipped to peer regions for the tenant
+func (s *MinIOServer) recordReplication(tenantID string, bytes int64) {
+	if bytes > 0 {
+		s.rollups.Add(monitoring.SeriesKey{TenantID: tenantID, Metric: metricReplicationBytes}, bytes)
+	}
+}
+
+// bucketOf is key's bucket, "" when the key has no "/"
+func bucketOf(key string) string {
+	if i := strings.Index(key, metadata.AggregateDelimiter); i > 0 {
+		return key[:i]
+	}
+	return ""
+}
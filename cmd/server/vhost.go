@@ -0,0 +1,136 @@
+// cmd/server/vhost.go
+// Virtual-hosted style addressing. With MINIO_DOMAIN set, a request to
+// <tenant>.<domain> addresses that tenant's namespace the way
+// <bucket>.s3.amazonaws.com addresses a bucket, alongside the path style
+// (X-Tenant-ID or ?tenant_id=) every endpoint already accepts.
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// virtualHosts resolves request hosts under the configured domains
+type virtualHosts struct {
+	domains []string // Lowercase, without leading dots
+}
+
+func newVirtualHosts(domains []string) *virtualHosts {
+	v := &virtualHosts{}
+	for _, d := range domains {
+		if d = strings.Trim(strings.ToLower(d), "."); d != "" {
+			v.domains = append(v.domains, d)
+		}
+	}
+	return v
+}
+
+// Enabled reports whether any domain is configured
+func (v *virtualHosts) Enabled() bool {
+	return len(v.domains) > 0
+}
+
+// Resolve returns the bucket label of host when it is exactly one DNS label
+// under a configured domain. Deeper names are not resolved: a wildcard
+// certificate for *.domain covers only one level.
+func (v *virtualHosts) Resolve(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	for _, domain := range v.domains {
+		label, ok := strings.CutSuffix(host, "."+domain)
+		if ok && validBucketLabel(label) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// validBucketLabel accepts a single DNS label: lowercase letters, digits and
+// inner hyphens, at most 63 characters
+func validBucketLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// withVirtualHost maps a virtual host's bucket label onto X-Tenant-ID so
+// handlers see virtual-hosted and path-style requests alike. The admin API
+// addresses tenants explicitly and is left alone.
+func (s *MinIOServer) withVirtualHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket, ok := s.vhosts.Resolve(r.Host)
+		if !ok || strings.HasPrefix(unversionedPath(r.URL.Path), "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if explicit := tenantFromRequest(r); explicit != "" && explicit != bucket {
+			writeErrorMessage(w, r, "Host addresses tenant "+bucket+" but the request names "+explicit, http.StatusBadRequest)
+			return
+		}
+		r.Header.Set("X-Tenant-ID", bucket)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// expectedOwnerHeader names the tenant a client expects to own the bucket
+// it addresses, guarding against a mistyped tenant ID or virtual host
+// reaching another tenant's namespace
+const expectedOwnerHeader = "X-Expected-Bucket-Owner"
+
+// checkExpectedOwner refuses r if it names an expected owner other than tenantID
+func checkExpectedOwner(r *http.Request, tenantID string) error {
+	if owner := r.Header.Get(expectedOwnerHeader); owner != "" && owner != tenantID {
+		return errOwnerMismatch
+	}
+	return nil
+}
+
+// withExpectedOwner enforces X-Expected-Bucket-Owner for requests that name
+// a tenant. Share links are checked once redeemed, when their tenant is known.
+func (s *MinIOServer) withExpectedOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenantID := tenantFromRequest(r); tenantID != "" && !strings.HasPrefix(unversionedPath(r.URL.Path), "/admin/") {
+			if err := checkExpectedOwner(r, tenantID); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tenantEndpoint returns the base URL clients should use for tenantID: its
+// virtual host under the first configured domain, otherwise the host the
+// request came in on. The request's scheme and port are kept.
+func (s *MinIOServer) tenantEndpoint(r *http.Request, tenantID string) string {
+	scheme := "http"
+	if requestIsTLS(r) {
+		scheme = "https"
+	}
+	if !s.vhosts.Enabled() {
+		return scheme + "://" + r.Host
+	}
+
+	host := tenantID + "." + s.vhosts.domains[0]
+	if _, port, err := net.SplitHostPort(r.Host); err == nil {
+		host = net.JoinHostPort(host, port)
+	}
+	return scheme + "://" + host
+}
+
+// shareURL returns the download URL of a share link token
+func (s *MinIOServer) shareURL(r *http.Request, tenantID, token string) string {
+	return s.tenantEndpoint(r, tenantID) + apiV1 + "/download?share=" + url.QueryEscape(token)
+}
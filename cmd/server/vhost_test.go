@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// host addresses the request to h
+func host(h string) credential {
+	return func(r *http.Request) { r.Host = h }
+}
+
+// A tenant's virtual host addresses its namespace like the path style, and
+// share links point at it
+func TestVirtualHost(t *testing.T) {
+	tenantID := newTenant(t)
+	upload(t, tenantID, "doc.txt", "hello")
+	vhost := host(tenantID + ".s3.example.com:9000")
+
+	w := do(t, "GET", "/v1/download?key=doc.txt", nil, vhost, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	if w.Body.String() != "hello" {
+		t.Errorf("Downloaded %q, want hello", w.Body.String())
+	}
+	w = do(t, "GET", "/v1/download?tenant_id="+tenantID+"&key=doc.txt", nil, vhost, adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	w = do(t, "GET", "/v1/download?tenant_id=other&key=doc.txt", nil, vhost, adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+	// Deeper names are not virtual hosts
+	w = do(t, "GET", "/v1/download?key=doc.txt", nil, host("a."+tenantID+".s3.example.com"), adminAuth)
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = do(t, "GET", "/v1/download?key=doc.txt", nil, vhost, adminAuth,
+		func(r *http.Request) { r.Header.Set(expectedOwnerHeader, "other") })
+	expectStatus(t, w, http.StatusForbidden)
+	w = do(t, "GET", "/v1/download?key=doc.txt", nil, vhost, adminAuth,
+		func(r *http.Request) { r.Header.Set(expectedOwnerHeader, tenantID) })
+	expectStatus(t, w, http.StatusOK)
+
+	w = do(t, "POST", "/v1/share?tenant_id="+tenantID+"&key=doc.txt", nil, host("localhost:9000"), adminAuth)
+	expectStatus(t, w, http.StatusOK)
+	var link shareLinkResponse
+	decode(t, w, &link)
+	if want := "http://" + tenantID + ".s3.example.com:9000/v1/download?share=" + link.Token; link.URL != want {
+		t.Errorf("Share URL %q, want %q", link.URL, want)
+	}
+}
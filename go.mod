@@ -9,4 +9,11 @@ require (
 	go.opentelemetry.io/otel/trace v1.21.0
 )
 
+require (
+	github.com/go-logr/logr v1.3.0 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.21.0 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+)
+
 // V3 implementations use standard library only for maximum portability
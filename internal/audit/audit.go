@@ -0,0 +1,218 @@
+// internal/audit/audit.go
+// Append-only, hash-chained audit log of security-relevant actions, one JSON
+// event per line. Each event carries the hash of its predecessor so any
+// edit, deletion or reordering breaks the chain.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeError   = "error"
+)
+
+// GenesisHash is the PrevHash of the first event in a log
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// maxLineSize bounds a single audit record when reading the log back
+const maxLineSize = 1 << 20
+
+// Event is a single audited action
+type Event struct {
+	Seq       uint64            `json:"seq"`
+	Time      time.Time         `json:"time"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Actor     string            `json:"actor,omitempty"`
+	Action    string            `json:"action"`
+	Resource  string            `json:"resource,omitempty"`
+	Outcome   string            `json:"outcome"`
+	RequestID string            `json:"request_id,omitempty"` // API request that caused the action
+	Details   map[string]string `json:"details,omitempty"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// computeHash hashes the event with its Hash field cleared
+func computeHash(ev Event) (string, error) {
+	ev.Hash = ""
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Logger writes events to an append-only file
+type Logger struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+
+	// Chain head, guarded by mu
+	seq  uint64
+	head string
+
+	// Statistics (lock-free)
+	events atomic.Uint64
+	errors atomic.Uint64
+}
+
+// NewLogger opens (creating if necessary) the audit log at path and resumes
+// its hash chain from the last intact record
+func NewLogger(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	seq, head, err := lastEvent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &Logger{path: path, file: file, seq: seq, head: head}, nil
+}
+
+// lastEvent returns the sequence and hash of the final record in path
+func lastEvent(path string) (uint64, string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, GenesisHash, nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	seq, head := uint64(0), GenesisHash
+	validEnd := int64(0)
+	scanner := newLineScanner(f)
+	for scanner.Scan() {
+		validEnd += int64(len(scanner.Bytes())) + 1
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err == nil {
+			seq, head = ev.Seq, ev.Hash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Drop a record torn by a crash so the next event starts on a fresh line
+	if info, err := f.Stat(); err == nil && info.Size() > validEnd {
+		if err := os.Truncate(path, validEnd); err != nil {
+			return 0, "", fmt.Errorf("failed to truncate torn audit record: %w", err)
+		}
+	}
+	return seq, head, nil
+}
+
+// newLineScanner yields newline-terminated lines, ignoring an unterminated
+// tail (a torn write, or a record still being appended)
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	})
+	return scanner
+}
+
+// Log appends an event, stamping its time if unset and linking it to the chain
+func (l *Logger) Log(ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	if ev.Outcome == "" {
+		ev.Outcome = OutcomeSuccess
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ev.Seq = l.seq + 1
+	ev.PrevHash = l.head
+	hash, err := computeHash(ev)
+	if err != nil {
+		l.errors.Add(1)
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	ev.Hash = hash
+
+	line, err := json.Marshal(ev)
+	if err != nil {
+		l.errors.Add(1)
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		l.errors.Add(1)
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+
+	l.seq, l.head = ev.Seq, ev.Hash
+	l.events.Add(1)
+	return nil
+}
+
+// Head returns the sequence number and hash of the latest event
+func (l *Logger) Head() (uint64, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq, l.head
+}
+
+// Path returns the log file location
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Events returns the number of events written
+func (l *Logger) Events() uint64 {
+	return l.events.Load()
+}
+
+// Errors returns the number of events that failed to write
+func (l *Logger) Errors() uint64 {
+	return l.errors.Load()
+}
+
+// Close syncs and closes the log
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.file.Sync(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
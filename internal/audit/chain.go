@@ -0,0 +1,153 @@
+// internal/audit/chain.go
+// Chain anchoring and tamper verification
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Anchor records the chain head at a point in time. Anchors are stored
+// outside the log (and optionally with an external notary) so truncating
+// or rewriting the log wholesale is still detected.
+type Anchor struct {
+	Seq  uint64    `json:"seq"`
+	Hash string    `json:"hash"`
+	Time time.Time `json:"time"`
+}
+
+// AppendAnchor appends the logger's current head to the anchors file at path
+func (l *Logger) AppendAnchor(path string) (Anchor, error) {
+	seq, hash := l.Head()
+	anchor := Anchor{Seq: seq, Hash: hash, Time: time.Now().UTC()}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return anchor, fmt.Errorf("failed to create anchor directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return anchor, fmt.Errorf("failed to open anchors: %w", err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(anchor)
+	if err != nil {
+		return anchor, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return anchor, fmt.Errorf("failed to write anchor: %w", err)
+	}
+	return anchor, f.Sync()
+}
+
+// ReadAnchors loads every anchor in path; a missing file has none
+func ReadAnchors(path string) ([]Anchor, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var anchors []Anchor
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var a Anchor
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			return nil, fmt.Errorf("corrupt anchor record %d: %w", len(anchors)+1, err)
+		}
+		anchors = append(anchors, a)
+	}
+	return anchors, scanner.Err()
+}
+
+// VerifyReport is the result of checking a log against its chain and anchors
+type VerifyReport struct {
+	Entries     uint64   `json:"entries"`
+	Anchors     int      `json:"anchors"`
+	HeadSeq     uint64   `json:"head_seq"`
+	HeadHash    string   `json:"head_hash"`
+	Valid       bool     `json:"valid"`
+	FirstBadSeq uint64   `json:"first_bad_seq,omitempty"`
+	Problems    []string `json:"problems,omitempty"`
+}
+
+func (r *VerifyReport) fail(seq uint64, format string, args ...interface{}) {
+	if r.Valid || seq < r.FirstBadSeq {
+		r.FirstBadSeq = seq
+	}
+	r.Valid = false
+	if len(r.Problems) < 100 {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// Verify walks the log at logPath, recomputing every hash and link, then
+// checks each anchor in anchorsPath (if non-empty) against the log
+func Verify(logPath, anchorsPath string) (*VerifyReport, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	report := &VerifyReport{Valid: true, HeadHash: GenesisHash}
+	hashes := make(map[uint64]string)
+
+	line := uint64(0)
+	scanner := newLineScanner(f)
+	for scanner.Scan() {
+		line++
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			report.fail(report.HeadSeq+1, "line %d: undecodable record", line)
+			continue
+		}
+		report.Entries++
+
+		if ev.Seq != report.HeadSeq+1 {
+			report.fail(ev.Seq, "seq %d: expected seq %d (events missing or reordered)", ev.Seq, report.HeadSeq+1)
+		}
+		if ev.PrevHash != report.HeadHash {
+			report.fail(ev.Seq, "seq %d: prev_hash does not match preceding event", ev.Seq)
+		}
+		if want, err := computeHash(ev); err != nil || want != ev.Hash {
+			report.fail(ev.Seq, "seq %d: content does not match hash (modified)", ev.Seq)
+		}
+
+		report.HeadSeq, report.HeadHash = ev.Seq, ev.Hash
+		hashes[ev.Seq] = ev.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if anchorsPath == "" {
+		return report, nil
+	}
+	anchors, err := ReadAnchors(anchorsPath)
+	if err != nil {
+		return nil, err
+	}
+	report.Anchors = len(anchors)
+	for _, a := range anchors {
+		if a.Seq == 0 {
+			continue
+		}
+		hash, ok := hashes[a.Seq]
+		switch {
+		case !ok:
+			report.fail(a.Seq, "anchor at seq %d (%s): event missing (log truncated)", a.Seq, a.Time.Format(time.RFC3339))
+		case hash != a.Hash:
+			report.fail(a.Seq, "anchor at seq %d (%s): hash mismatch (log rewritten)", a.Seq, a.Time.Format(time.RFC3339))
+		}
+	}
+	return report, nil
+}
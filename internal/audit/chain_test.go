@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	anchorsPath := filepath.Join(dir, "anchors.log")
+
+	l, err := NewLogger(logPath)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	for _, action := range []string{"object.put", "object.get", "grant.create"} {
+		if err := l.Log(Event{TenantID: "t1", Action: action}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+	}
+	if _, err := l.AppendAnchor(anchorsPath); err != nil {
+		t.Fatalf("AppendAnchor() error = %v", err)
+	}
+	l.Close()
+
+	// Reopening resumes the chain rather than restarting it
+	l, _ = NewLogger(logPath)
+	l.Log(Event{TenantID: "t1", Action: "object.delete"})
+	l.Close()
+
+	report, err := Verify(logPath, anchorsPath)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.Valid || report.Entries != 4 {
+		t.Fatalf("Verify() = %+v, want 4 valid entries", report)
+	}
+
+	original, _ := os.ReadFile(logPath)
+
+	// Modify an event in place
+	os.WriteFile(logPath, bytes.Replace(original, []byte("object.get"), []byte("object.gex"), 1), 0o640)
+	if report, _ := Verify(logPath, anchorsPath); report.Valid || report.FirstBadSeq != 2 {
+		t.Errorf("Verify() after edit = %+v, want failure at seq 2", report)
+	}
+
+	// Drop the tail, including the anchored event
+	lines := bytes.SplitAfter(original, []byte("\n"))
+	os.WriteFile(logPath, bytes.Join(lines[:2], nil), 0o640)
+	if report, _ := Verify(logPath, anchorsPath); report.Valid {
+		t.Errorf("Verify() after truncation = %+v, want anchor failure", report)
+	}
+}
@@ -0,0 +1,179 @@
+// internal/cache/cache_admin_v3.go
+// Administrative operations on the V3 cache: prefix flushes, shard
+// occupancy, top keys and tier placement
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ShardOccupancy describes one cache shard
+type ShardOccupancy struct {
+	Shard   int   `json:"shard"`
+	Entries int64 `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// KeyInfo describes one cached entry
+type KeyInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	Hits         uint64    `json:"hits"`
+	Tier         uint8     `json:"tier"` // 0=L1, 1=L2, 2=L3
+	LastAccessed time.Time `json:"last_accessed"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// Orders for TopKeys
+const (
+	TopBySize = "size"
+	TopByHits = "hits"
+)
+
+// maxTier is the lowest (coldest) tier
+const maxTier = 2
+
+// FlushPrefix deletes every entry whose key starts with prefix, except keys
+// for which keep returns true (keep may be nil). It returns what was removed.
+func (m *V3CacheManager) FlushPrefix(prefix string, keep func(key string) bool) (entries int64, bytes int64) {
+	for _, shard := range m.shards {
+		var removed []*V3CacheEntry
+
+		shard.lock()
+		for key, entry := range shard.entries {
+			if !strings.HasPrefix(key, prefix) || (keep != nil && keep(key)) {
+				continue
+			}
+			size := int64(entry.DataSize.Load())
+			delete(shard.entries, key)
+			shard.usedSize.Add(-size)
+			shard.entryCount.Add(-1)
+			removed = append(removed, entry)
+			entries++
+			bytes += size
+		}
+		shard.entriesLock.Unlock()
+
+		for _, entry := range removed {
+			m.releaseEntry(entry)
+		}
+	}
+	return entries, bytes
+}
+
+// Occupancy reports every shard's entry count, size and hit counters
+func (m *V3CacheManager) Occupancy() []ShardOccupancy {
+	out := make([]ShardOccupancy, len(m.shards))
+	for i, shard := range m.shards {
+		out[i] = ShardOccupancy{
+			Shard:   i,
+			Entries: shard.entryCount.Load(),
+			Bytes:   shard.usedSize.Load(),
+			Hits:    shard.hitCount.Load(),
+			Misses:  shard.missCount.Load(),
+		}
+	}
+	return out
+}
+
+// TopKeys returns up to n live entries ordered by size or hits, descending
+func (m *V3CacheManager) TopKeys(n int, by string) ([]KeyInfo, error) {
+	if by != TopBySize && by != TopByHits {
+		return nil, fmt.Errorf("unknown order %q (want %s or %s)", by, TopBySize, TopByHits)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	less := func(a, b *KeyInfo) bool {
+		if by == TopByHits {
+			return a.Hits > b.Hits
+		}
+		return a.Size > b.Size
+	}
+
+	// Keep a bounded, sorted candidate list so memory stays O(n)
+	top := make([]KeyInfo, 0, n)
+	now := time.Now().UnixNano()
+	for _, shard := range m.shards {
+		shard.rlock()
+		for key, entry := range shard.entries {
+			exp := entry.ExpiresAt.Load()
+			if exp != 0 && now >= exp {
+				continue
+			}
+			info := KeyInfo{
+				Key:          key,
+				Size:         int64(entry.DataSize.Load()),
+				Hits:         entry.AccessCount.Load(),
+				Tier:         entry.Tier,
+				LastAccessed: time.Unix(0, entry.LastAccessed.Load()).UTC(),
+			}
+			if exp != 0 {
+				info.ExpiresAt = time.Unix(0, exp).UTC()
+			}
+			if len(top) == n && !less(&info, &top[n-1]) {
+				continue
+			}
+			i := sort.Search(len(top), func(i int) bool { return less(&info, &top[i]) })
+			if len(top) < n {
+				top = append(top, KeyInfo{})
+			}
+			copy(top[i+1:], top[i:])
+			top[i] = info
+		}
+		shard.entriesLock.RUnlock()
+	}
+	return top, nil
+}
+
+// Demote moves key to a colder tier (1=L2, 2=L3). It is a no-op if the
+// entry already sits in that tier or a colder one.
+func (m *V3CacheManager) Demote(key string, tier uint8) (KeyInfo, error) {
+	if tier == 0 || tier > maxTier {
+		return KeyInfo{}, fmt.Errorf("demotion target must be tier 1 (L2) or 2 (L3)")
+	}
+	shard := m.shards[m.fastHash(key)&m.shardMask]
+
+	shard.lock()
+	defer shard.entriesLock.Unlock()
+
+	entry, exists := shard.entries[key]
+	if !exists {
+		return KeyInfo{}, fmt.Errorf("cache miss: %s", key)
+	}
+	if entry.Tier < tier {
+		entry.Tier = tier
+	}
+	return KeyInfo{
+		Key:          key,
+		Size:         int64(entry.DataSize.Load()),
+		Hits:         entry.AccessCount.Load(),
+		Tier:         entry.Tier,
+		LastAccessed: time.Unix(0, entry.LastAccessed.Load()).UTC(),
+	}, nil
+}
+
+// Promote moves key to a warmer tier (0=L1, 1=L2). It reports whether the
+// entry moved; entries already in that tier or a warmer one stay put.
+func (m *V3CacheManager) Promote(key string, tier uint8) bool {
+	if tier >= maxTier {
+		return false
+	}
+	shard := m.shards[m.fastHash(key)&m.shardMask]
+
+	shard.lock()
+	defer shard.entriesLock.Unlock()
+
+	entry, exists := shard.entries[key]
+	if !exists || entry.Tier <= tier {
+		return false
+	}
+	entry.Tier = tier
+	return true
+}
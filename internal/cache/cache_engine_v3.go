@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -51,7 +52,9 @@ type V3CacheEntry struct {
 	CompressedSize atomic.Uint64
 	AccessCount    atomic.Uint64
 	LastAccessed   atomic.Int64 // Unix nano
+	ExpiresAt      atomic.Int64 // Unix nano, 0 = never
 	CreatedAt      int64
+	Owner          string // Tenant whose namespace holds it, "" if none
 	Tier           uint8  // 0=L1, 1=L2, 2=L3
 	Flags          uint8  // Bit flags for compression, etc
 	RefCount       atomic.Int32
@@ -82,6 +85,19 @@ type V3CacheShard struct {
 	// Ring buffer for async operations
 	asyncOps    *LockFreeRingBuffer
 
+	// Filter over the shard's keys, nil when disabled
+	filter      atomic.Pointer[shardFilter]
+
+	// Inserts that found the shard over its size budget, and expired entries
+	evictionsQueued atomic.Int64
+	expirations     atomic.Int64
+
+	// Lock acquisitions, and the waits of every lockSampleEvery'th
+	lockOps         atomic.Uint64
+	lockSamples     atomic.Int64
+	lockWaitNs      atomic.Int64
+	lockWaitMaxNs   atomic.Int64
+
 	_padding    [CacheLineSize - 8]byte
 }
 
@@ -114,8 +130,9 @@ type V3CacheManager struct {
 	promotionPool   *V3WorkerPool
 	evictionPool    *V3WorkerPool
 
-	// Statistics (lock-free)
+	// Statistics (lock-free), and per-shard rates over the last window
 	stats *V3CacheStats
+	rates atomic.Pointer[shardRates]
 
 	// Lifecycle
 	ctx        context.Context
@@ -134,6 +151,13 @@ type V3CacheConfig struct {
 	EnablePrefetch    bool
 	PrefetchAggressive bool
 	MaxWorkers        int
+
+	// Placement chooses new entries' tiers (default: DefaultSizePolicy)
+	Placement         PlacementPolicy
+
+	// FilterBitsPerKey sizes the per-shard key filters (default
+	// DefaultFilterBitsPerKey); negative disables them
+	FilterBitsPerKey  int
 }
 
 type V3CacheStats struct {
@@ -148,6 +172,17 @@ type V3CacheStats struct {
 	ThroughputOps   atomic.Uint64
 	ThroughputBytes atomic.Uint64
 	AllocatedBytes  atomic.Int64
+	Expirations     atomic.Uint64
+
+	// Key filters: misses answered without the shard lock, lookups of
+	// absent keys the filters let through, and filter rebuilds
+	FilterNegatives      atomic.Uint64
+	FilterFalsePositives atomic.Uint64
+	FilterRebuilds       atomic.Uint64
+
+	// TenantMismatches counts tenant-scoped lookups that found an entry
+	// owned by someone else and treated it as a miss
+	TenantMismatches atomic.Uint64
 	_padding        [CacheLineSize - 8]byte
 }
 
@@ -163,9 +198,20 @@ func NewV3CacheManager(config *V3CacheConfig) (*V3CacheManager, error) {
 	if config.ShardCount == 0 {
 		config.ShardCount = V3ShardCount
 	}
+	// Keys pick a shard by masking their hash, which only covers every
+	// shard when the count is a power of two
+	if config.ShardCount < 0 || config.ShardCount&(config.ShardCount-1) != 0 {
+		return nil, fmt.Errorf("cache shard count %d is not a power of two", config.ShardCount)
+	}
 	if config.MaxWorkers == 0 {
 		config.MaxWorkers = runtime.NumCPU() * 4
 	}
+	if config.Placement == nil {
+		config.Placement = DefaultSizePolicy()
+	}
+	if config.FilterBitsPerKey == 0 {
+		config.FilterBitsPerKey = DefaultFilterBitsPerKey
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -194,6 +240,9 @@ func NewV3CacheManager(config *V3CacheConfig) (*V3CacheManager, error) {
 			entries:  make(map[string]*V3CacheEntry, 1000),
 			asyncOps: newLockFreeRingBuffer(V3RingBufferSize),
 		}
+		if config.FilterBitsPerKey > 0 {
+			mgr.shards[i].filter.Store(newShardFilter(minFilterKeys, config.FilterBitsPerKey))
+		}
 	}
 
 	// Create massive worker pools
@@ -222,16 +271,20 @@ func NewV3CacheManager(config *V3CacheConfig) (*V3CacheManager, error) {
 
 // Get with zero-copy fast path
 func (m *V3CacheManager) Get(ctx context.Context, key string) ([]byte, error) {
+	return m.get(key, anyOwner)
+}
+
+func (m *V3CacheManager) get(key string, o owner) ([]byte, error) {
 	start := time.Now().UnixNano()
 
 	// Fast hash calculation
-	shardIdx := m.fastHash(key) & m.shardMask
-	shard := m.shards[shardIdx]
+	hash := m.fastHash(key)
+	shard := m.shards[hash&m.shardMask]
 
-	// Lock-free read attempt
-	shard.entriesLock.RLock()
-	entry, exists := shard.entries[key]
-	shard.entriesLock.RUnlock()
+	entry, exists := m.lookupOwned(shard, key, hash, o)
+	if exists && m.expireIfDue(shard, key, entry, start) {
+		exists = false
+	}
 
 	if !exists {
 		shard.missCount.Add(1)
@@ -239,6 +292,60 @@ func (m *V3CacheManager) Get(ctx context.Context, key string) ([]byte, error) {
 		return nil, fmt.Errorf("cache miss: %s", key)
 	}
 
+	m.recordHit(shard, entry)
+
+	// Zero-copy data access
+	dataSize := entry.DataSize.Load()
+	data := make([]byte, dataSize)
+
+	// Direct memory copy (unsafe but fast)
+	if entry.Data != nil {
+		copyMemory(data, entry.Data, int(dataSize))
+	}
+
+	// Record latency
+	latency := time.Now().UnixNano() - start
+	m.stats.AvgLatencyNs.Store(latency)
+
+	return data, nil
+}
+
+// ReadAt copies len(p) bytes of key's value starting at off into p without
+// copying the rest of it. Like io.ReaderAt, it returns io.EOF with the
+// bytes copied when the value ends first.
+func (m *V3CacheManager) ReadAt(ctx context.Context, key string, p []byte, off int64) (int, error) {
+	return m.readAt(key, anyOwner, p, off)
+}
+
+func (m *V3CacheManager) readAt(key string, o owner, p []byte, off int64) (int, error) {
+	hash := m.fastHash(key)
+	shard := m.shards[hash&m.shardMask]
+
+	entry, exists := m.lookupOwned(shard, key, hash, o)
+	if !exists || m.expireIfDue(shard, key, entry, time.Now().UnixNano()) {
+		shard.missCount.Add(1)
+		m.stats.TotalMisses.Add(1)
+		return 0, fmt.Errorf("cache miss: %s", key)
+	}
+	m.recordHit(shard, entry)
+
+	size := int64(entry.DataSize.Load())
+	if off < 0 {
+		return 0, fmt.Errorf("cache read of %s at negative offset %d", key, off)
+	}
+	if off >= size || entry.Data == nil {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(p)), size-off))
+	copyMemory(p, unsafe.Add(entry.Data, off), n)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// recordHit counts a read of entry and promotes it a tier
+func (m *V3CacheManager) recordHit(shard *V3CacheShard, entry *V3CacheEntry) {
 	// Atomic access tracking (lock-free)
 	entry.AccessCount.Add(1)
 	entry.LastAccessed.Store(time.Now().UnixNano())
@@ -256,25 +363,38 @@ func (m *V3CacheManager) Get(ctx context.Context, key string) ([]byte, error) {
 		m.stats.L3Hits.Add(1)
 	}
 
-	// Zero-copy data access
-	dataSize := entry.DataSize.Load()
-	data := make([]byte, dataSize)
-
-	// Direct memory copy (unsafe but fast)
-	if entry.Data != nil {
-		copyMemory(data, entry.Data, int(dataSize))
-	}
-
 	// Async promotion to higher tier (non-blocking)
 	if entry.Tier > 0 {
 		m.asyncPromote(entry, entry.Tier-1)
 	}
+}
 
-	// Record latency
-	latency := time.Now().UnixNano() - start
-	m.stats.AvgLatencyNs.Store(latency)
+// lookup finds key in shard, consulting the shard's filter first so a key
+// never set there is a miss without taking the lock
+func (m *V3CacheManager) lookup(shard *V3CacheShard, key string, hash uint64) (*V3CacheEntry, bool) {
+	if m.filterExcludes(shard, hash) {
+		return nil, false
+	}
 
-	return data, nil
+	shard.rlock()
+	entry, exists := shard.entries[key]
+	shard.entriesLock.RUnlock()
+
+	if !exists && shard.filter.Load() != nil {
+		m.stats.FilterFalsePositives.Add(1)
+	}
+	return entry, exists
+}
+
+// lookupOwned is lookup restricted to entries o owns; an entry owned by
+// another tenant is a miss
+func (m *V3CacheManager) lookupOwned(shard *V3CacheShard, key string, hash uint64, o owner) (*V3CacheEntry, bool) {
+	entry, exists := m.lookup(shard, key, hash)
+	if exists && !o.owns(entry) {
+		m.stats.TenantMismatches.Add(1)
+		return nil, false
+	}
+	return entry, exists
 }
 
 // BatchGet with massive parallelism
@@ -323,8 +443,19 @@ func (m *V3CacheManager) BatchGet(ctx context.Context, keys []string) (map[strin
 
 // Set with zero-allocation fast path
 func (m *V3CacheManager) Set(ctx context.Context, key string, data []byte) error {
+	return m.SetWithTTL(ctx, key, data, 0)
+}
+
+// SetWithTTL stores data that expires after ttl (0 = never)
+func (m *V3CacheManager) SetWithTTL(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return m.set(ctx, key, "", data, ttl)
+}
+
+// set stores data at key owned by tenantID ("" for no tenant)
+func (m *V3CacheManager) set(ctx context.Context, key, tenantID string, data []byte, ttl time.Duration) error {
 	// Acquire entry from pool or create new
 	entry := m.acquireEntry()
+	entry.Owner = tenantID
 
 	// Copy key to fixed array (avoid heap allocation)
 	keyLen := len(key)
@@ -344,22 +475,25 @@ func (m *V3CacheManager) Set(ctx context.Context, key string, data []byte) error
 	entry.CreatedAt = time.Now().UnixNano()
 	entry.LastAccessed.Store(time.Now().UnixNano())
 	entry.AccessCount.Store(0)
+	if ttl > 0 {
+		entry.ExpiresAt.Store(entry.CreatedAt + int64(ttl))
+	}
 
 	// Intelligent tier placement
-	if dataSize < 100*1024*1024 { // <100MB
-		entry.Tier = 0 // L1
-	} else if dataSize < 1024*1024*1024 { // <1GB
-		entry.Tier = 1 // L2
-	} else {
-		entry.Tier = 2 // L3
-	}
+	entry.Tier = min(m.config.Placement.Place(PlacementInput{
+		Key:            key,
+		Size:           int64(dataSize),
+		Time:           time.Unix(0, entry.CreatedAt),
+		PlacementHints: placementHints(ctx),
+	}), maxTier)
 
 	// Fast shard lookup
-	shardIdx := m.fastHash(key) & m.shardMask
-	shard := m.shards[shardIdx]
+	hash := m.fastHash(key)
+	shard := m.shards[hash&m.shardMask]
 
 	// Insert with minimal locking
-	shard.entriesLock.Lock()
+	shard.lock()
+	m.filterAdd(shard, hash)
 
 	// Evict if necessary (using lock-free counters)
 	maxShardSize := (m.config.L1MaxSizeGB * 1024 * 1024 * 1024) / int64(len(m.shards))
@@ -367,14 +501,24 @@ func (m *V3CacheManager) Set(ctx context.Context, key string, data []byte) error
 
 	if currentSize+int64(dataSize) > maxShardSize {
 		// Async eviction (non-blocking)
+		shard.evictionsQueued.Add(1)
 		m.asyncEvict(shard, int64(dataSize))
 	}
 
+	prev, replaced := shard.entries[key]
 	shard.entries[key] = entry
 	shard.usedSize.Add(int64(dataSize))
-	shard.entryCount.Add(1)
+	if replaced {
+		shard.usedSize.Add(-int64(prev.DataSize.Load()))
+	} else {
+		shard.entryCount.Add(1)
+	}
 	shard.entriesLock.Unlock()
 
+	if replaced {
+		m.releaseEntry(prev)
+	}
+
 	// Async compression for large objects
 	if dataSize > 64*1024 {
 		m.asyncCompress(entry)
@@ -428,11 +572,21 @@ func (m *V3CacheManager) BatchSet(ctx context.Context, items map[string][]byte)
 
 // Delete with lock-free reference counting
 func (m *V3CacheManager) Delete(ctx context.Context, key string) error {
+	m.delete(key, anyOwner)
+	return nil
+}
+
+// delete removes key if o owns it, reporting whether it did
+func (m *V3CacheManager) delete(key string, o owner) bool {
 	shardIdx := m.fastHash(key) & m.shardMask
 	shard := m.shards[shardIdx]
 
-	shard.entriesLock.Lock()
+	shard.lock()
 	entry, exists := shard.entries[key]
+	if exists && !o.owns(entry) {
+		m.stats.TenantMismatches.Add(1)
+		exists = false
+	}
 	if exists {
 		delete(shard.entries, key)
 		shard.usedSize.Add(-int64(entry.DataSize.Load()))
@@ -443,8 +597,165 @@ func (m *V3CacheManager) Delete(ctx context.Context, key string) error {
 	if exists {
 		m.releaseEntry(entry)
 	}
+	return exists
+}
 
-	return nil
+// Rename moves the entry at oldKey to newKey without copying its data,
+// replacing any entry already at newKey. It reports false, leaving the cache
+// unchanged, if oldKey is not cached.
+func (m *V3CacheManager) Rename(oldKey, newKey string) bool {
+	return m.rename(oldKey, newKey, anyOwner)
+}
+
+// rename is Rename of entries o owns; it refuses to replace an entry at
+// newKey that o does not own
+func (m *V3CacheManager) rename(oldKey, newKey string, o owner) bool {
+	newHash := m.fastHash(newKey)
+	oldIdx := m.fastHash(oldKey) & m.shardMask
+	newIdx := newHash & m.shardMask
+	oldShard, newShard := m.shards[oldIdx], m.shards[newIdx]
+
+	// Lock both shards in index order so concurrent renames can't deadlock
+	first, second := oldShard, newShard
+	if newIdx < oldIdx {
+		first, second = newShard, oldShard
+	}
+	first.lock()
+	if second != first {
+		second.lock()
+	}
+
+	entry, exists := oldShard.entries[oldKey]
+	if exists {
+		// Expired entries are left for Get to reap
+		if exp := entry.ExpiresAt.Load(); exp != 0 && time.Now().UnixNano() >= exp {
+			exists = false
+		}
+	}
+	if exists {
+		// Only o's entries move, and never over another tenant's
+		if dst, ok := newShard.entries[newKey]; !o.owns(entry) || (ok && !o.owns(dst)) {
+			m.stats.TenantMismatches.Add(1)
+			exists = false
+		}
+	}
+	var prev *V3CacheEntry
+	if exists {
+		size := int64(entry.DataSize.Load())
+		delete(oldShard.entries, oldKey)
+		oldShard.usedSize.Add(-size)
+		oldShard.entryCount.Add(-1)
+
+		copy(entry.Key[:], newKey)
+		entry.KeyLen = uint16(min(len(newKey), 255))
+
+		var replaced bool
+		if prev, replaced = newShard.entries[newKey]; replaced {
+			newShard.usedSize.Add(-int64(prev.DataSize.Load()))
+			newShard.entryCount.Add(-1)
+		}
+		m.filterAdd(newShard, newHash)
+		newShard.entries[newKey] = entry
+		newShard.usedSize.Add(size)
+		newShard.entryCount.Add(1)
+	}
+
+	if second != first {
+		second.entriesLock.Unlock()
+	}
+	first.entriesLock.Unlock()
+
+	if prev != nil {
+		m.releaseEntry(prev)
+	}
+	return exists
+}
+
+// expireIfDue removes entry if its TTL has passed, reporting whether it did
+func (m *V3CacheManager) expireIfDue(shard *V3CacheShard, key string, entry *V3CacheEntry, now int64) bool {
+	exp := entry.ExpiresAt.Load()
+	if exp == 0 || now < exp {
+		return false
+	}
+
+	shard.lock()
+	current, exists := shard.entries[key]
+	if exists && current == entry {
+		delete(shard.entries, key)
+		shard.usedSize.Add(-int64(entry.DataSize.Load()))
+		shard.entryCount.Add(-1)
+	}
+	shard.entriesLock.Unlock()
+
+	if exists && current == entry {
+		m.releaseEntry(entry)
+		m.stats.Expirations.Add(1)
+		shard.expirations.Add(1)
+	}
+	return true
+}
+
+// TTL returns the time left before key expires (0 if it never does)
+func (m *V3CacheManager) TTL(key string) (ttl time.Duration, ok bool) {
+	shard := m.shards[m.fastHash(key)&m.shardMask]
+
+	shard.rlock()
+	entry, exists := shard.entries[key]
+	shard.entriesLock.RUnlock()
+
+	now := time.Now().UnixNano()
+	if !exists || m.expireIfDue(shard, key, entry, now) {
+		return 0, false
+	}
+	if exp := entry.ExpiresAt.Load(); exp != 0 {
+		return time.Duration(exp - now), true
+	}
+	return 0, true
+}
+
+// Stat reports an entry's size and tier without copying its data
+func (m *V3CacheManager) Stat(key string) (size int64, tier uint8, ok bool) {
+	return m.stat(key, anyOwner)
+}
+
+func (m *V3CacheManager) stat(key string, o owner) (size int64, tier uint8, ok bool) {
+	hash := m.fastHash(key)
+	shard := m.shards[hash&m.shardMask]
+
+	entry, exists := m.lookupOwned(shard, key, hash, o)
+	if !exists || m.expireIfDue(shard, key, entry, time.Now().UnixNano()) {
+		return 0, 0, false
+	}
+	return int64(entry.DataSize.Load()), entry.Tier, true
+}
+
+// Range calls fn for every live cached key until fn returns false.
+// Each shard is read-locked only while its keys are collected.
+func (m *V3CacheManager) Range(fn func(key string, size int64, tier uint8) bool) {
+	type item struct {
+		key  string
+		size int64
+		tier uint8
+	}
+
+	for _, shard := range m.shards {
+		now := time.Now().UnixNano()
+		shard.rlock()
+		items := make([]item, 0, len(shard.entries))
+		for key, entry := range shard.entries {
+			if exp := entry.ExpiresAt.Load(); exp != 0 && now >= exp {
+				continue
+			}
+			items = append(items, item{key, int64(entry.DataSize.Load()), entry.Tier})
+		}
+		shard.entriesLock.RUnlock()
+
+		for _, it := range items {
+			if !fn(it.key, it.size, it.tier) {
+				return
+			}
+		}
+	}
 }
 
 // Fast hashing using FNV-1a
@@ -584,12 +895,18 @@ func (m *V3CacheManager) statsCollector() {
 	defer ticker.Stop()
 
 	var lastOps, lastBytes uint64
+	window := newShardWindow(m.shards, time.Now())
 
 	for {
 		select {
 		case <-m.shutdownCh:
 			return
-		case <-ticker.C:
+		case now := <-ticker.C:
+			if now.Sub(window.start) >= statsWindow {
+				m.rates.Store(window.rates(m.shards, now))
+				window = newShardWindow(m.shards, now)
+			}
+
 			// Calculate throughput
 			currentOps := m.stats.TotalHits.Load()
 			currentBytes := m.stats.AllocatedBytes.Load()
@@ -638,6 +955,8 @@ func newLockFreeRingBuffer(size int) *LockFreeRingBuffer {
 	}
 }
 
+// Push claims a slot by advancing head, then publishes item into it once
+// the consumer of the slot's previous lap has emptied it
 func (rb *LockFreeRingBuffer) Push(item unsafe.Pointer) bool {
 	for {
 		head := rb.head.Load()
@@ -650,12 +969,17 @@ func (rb *LockFreeRingBuffer) Push(item unsafe.Pointer) bool {
 
 		// Try to reserve slot
 		if rb.head.CompareAndSwap(head, head+1) {
-			rb.buffer[head&rb.mask] = item
+			slot := &rb.buffer[head&rb.mask]
+			for !atomic.CompareAndSwapPointer(slot, nil, item) {
+				runtime.Gosched()
+			}
 			return true
 		}
 	}
 }
 
+// Pop claims a slot by advancing tail, then waits for its producer to
+// publish into it
 func (rb *LockFreeRingBuffer) Pop() unsafe.Pointer {
 	for {
 		tail := rb.tail.Load()
@@ -668,7 +992,13 @@ func (rb *LockFreeRingBuffer) Pop() unsafe.Pointer {
 
 		// Try to claim slot
 		if rb.tail.CompareAndSwap(tail, tail+1) {
-			return rb.buffer[tail&rb.mask]
+			slot := &rb.buffer[tail&rb.mask]
+			for {
+				if item := atomic.SwapPointer(slot, nil); item != nil {
+					return item
+				}
+				runtime.Gosched()
+			}
 		}
 	}
 }
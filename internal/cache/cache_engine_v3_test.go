@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestReadAt(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+	value := []byte("0123456789")
+	if err := mgr.Set(ctx, "k", value); err != nil {
+		t.Fatal(err)
+	}
+
+	p := make([]byte, 4)
+	if n, err := mgr.ReadAt(ctx, "k", p, 3); n != 4 || err != nil || !bytes.Equal(p, value[3:7]) {
+		t.Errorf("ReadAt(3) = %d %q, %v", n, p[:n], err)
+	}
+	if n, err := mgr.ReadAt(ctx, "k", p, 8); n != 2 || err != io.EOF || !bytes.Equal(p[:n], value[8:]) {
+		t.Errorf("ReadAt(8) = %d %q, %v; want the final 2 bytes and io.EOF", n, p[:n], err)
+	}
+	if n, err := mgr.ReadAt(ctx, "k", p, 10); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt(10) = %d, %v; want io.EOF", n, err)
+	}
+	if _, err := mgr.ReadAt(ctx, "absent", p, 0); err == nil || err == io.EOF {
+		t.Errorf("ReadAt(absent) = %v, want a miss", err)
+	}
+
+	if hits := mgr.GetStats().TotalHits.Load(); hits != 3 {
+		t.Errorf("Expected 3 hits, got %d", hits)
+	}
+}
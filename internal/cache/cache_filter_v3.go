@@ -0,0 +1,108 @@
+// internal/cache/cache_filter_v3.go
+// Per-shard bloom filters in front of the V3 shard maps: a lookup of a key
+// the filter has never seen is a miss without taking the shard lock
+package cache
+
+import "sync/atomic"
+
+const (
+	// DefaultFilterBitsPerKey gives about a 1% false positive rate
+	DefaultFilterBitsPerKey = 10
+
+	filterHashes  = 7
+	minFilterKeys = 256
+)
+
+// shardFilter is a bloom filter over the keys set in one shard. Readers
+// test it without the shard lock; keys are added under the write lock
+// before they become visible in the map and are never removed, so it has
+// no false negatives. Deleted keys leave their bits set until the filter
+// is rebuilt.
+type shardFilter struct {
+	words    []atomic.Uint64
+	m        uint64
+	capacity int // Keys it was sized for
+	added    int // Keys added, deleted ones included; guarded by the shard lock
+}
+
+func newShardFilter(keys, bitsPerKey int) *shardFilter {
+	m := uint64(max(keys*bitsPerKey, 64))
+	return &shardFilter{words: make([]atomic.Uint64, (m+63)/64), m: m, capacity: keys}
+}
+
+func (f *shardFilter) add(hash uint64) {
+	h1, h2 := filterHashPair(hash)
+	for i := uint64(0); i < filterHashes; i++ {
+		bit := (h1 + i*h2) % f.m
+		// Writers hold the shard lock, so only readers race with the store
+		word := &f.words[bit/64]
+		word.Store(word.Load() | 1<<(bit%64))
+	}
+	f.added++
+}
+
+// mayContain is false only if the key was never added
+func (f *shardFilter) mayContain(hash uint64) bool {
+	h1, h2 := filterHashPair(hash)
+	for i := uint64(0); i < filterHashes; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.words[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// filterHashPair mixes a key's fastHash, whose low bits select its shard
+// and so are shared by every key in it, into two independent hashes
+func filterHashPair(hash uint64) (uint64, uint64) {
+	x := hash
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x & 0xffffffff, x>>32 | 1
+}
+
+// filterExcludes reports whether shard's filter rules out the key with
+// hash, counting it as a filtered miss
+func (m *V3CacheManager) filterExcludes(shard *V3CacheShard, hash uint64) bool {
+	f := shard.filter.Load()
+	if f == nil || f.mayContain(hash) {
+		return false
+	}
+	m.stats.FilterNegatives.Add(1)
+	return true
+}
+
+// filterAdd records a key in shard's filter. Once the keys added since the
+// last rebuild reach its capacity, stale bits from deleted keys and an
+// outgrown size both raise the false positive rate, so the filter is first
+// rebuilt from the live keys at twice their number. Caller holds
+// shard.entriesLock for writing.
+func (m *V3CacheManager) filterAdd(shard *V3CacheShard, hash uint64) {
+	f := shard.filter.Load()
+	if f == nil {
+		return
+	}
+	if f.added >= f.capacity {
+		f = newShardFilter(max(2*len(shard.entries), minFilterKeys), m.config.FilterBitsPerKey)
+		for key := range shard.entries {
+			f.add(m.fastHash(key))
+		}
+		shard.filter.Store(f)
+		m.stats.FilterRebuilds.Add(1)
+	}
+	f.add(hash)
+}
+
+// FilterFalsePositiveRate is the fraction of lookups of absent keys that
+// the shard filters let through to the map
+func (s *V3CacheStats) FilterFalsePositiveRate() float64 {
+	fp := s.FilterFalsePositives.Load()
+	if total := fp + s.FilterNegatives.Load(); total > 0 {
+		return float64(fp) / float64(total)
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func newFilterTestCache(t *testing.T, bitsPerKey int) *V3CacheManager {
+	mgr, err := NewV3CacheManager(&V3CacheConfig{ShardCount: 16, L1MaxSizeGB: 1, FilterBitsPerKey: bitsPerKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mgr.Shutdown(context.Background()) })
+	return mgr
+}
+
+func TestShardFilterMisses(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 10000; i++ {
+		mgr.Set(ctx, fmt.Sprintf("k%d", i), []byte("v"))
+	}
+	for i := 0; i < 10000; i++ {
+		if _, err := mgr.Get(ctx, fmt.Sprintf("k%d", i)); err != nil {
+			t.Fatalf("Expected no false negatives, k%d missed", i)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		if _, err := mgr.Get(ctx, fmt.Sprintf("absent%d", i)); err == nil {
+			t.Fatal("Expected a miss")
+		}
+	}
+
+	stats := mgr.GetStats()
+	if stats.FilterRebuilds.Load() == 0 {
+		t.Error("Expected the filters rebuilt as the shards grew")
+	}
+	if rate := stats.FilterFalsePositiveRate(); rate > 0.05 {
+		t.Errorf("Expected about 1%% false positives, got %.3f", rate)
+	}
+	if stats.FilterNegatives.Load()+stats.FilterFalsePositives.Load() != 10000 {
+		t.Errorf("Expected every absent lookup counted, got %d + %d",
+			stats.FilterNegatives.Load(), stats.FilterFalsePositives.Load())
+	}
+}
+
+func TestShardFilterChurn(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+
+	// Deleted keys leave stale bits; rebuilds must keep the rate down
+	for i := 0; i < 8000; i++ {
+		key := fmt.Sprintf("churn%d", i)
+		mgr.Set(ctx, key, []byte("v"))
+		mgr.Delete(ctx, key)
+	}
+	mgr.Set(ctx, "kept", []byte("v"))
+	for i := 0; i < 5000; i++ {
+		mgr.Stat(fmt.Sprintf("absent%d", i))
+	}
+	if rate := mgr.GetStats().FilterFalsePositiveRate(); rate > 0.05 {
+		t.Errorf("Expected rebuilds to drop deleted keys, got a %.3f false positive rate", rate)
+	}
+
+	if !mgr.Rename("kept", "renamed") {
+		t.Fatal("Expected rename")
+	}
+	if _, _, ok := mgr.Stat("renamed"); !ok {
+		t.Error("Expected a renamed key found through the filter")
+	}
+}
+
+func TestShardFilterDisabled(t *testing.T) {
+	mgr := newFilterTestCache(t, -1)
+	mgr.Get(context.Background(), "absent")
+	if n := mgr.GetStats().FilterNegatives.Load(); n != 0 {
+		t.Errorf("Expected no filtered misses, got %d", n)
+	}
+}
+
+func TestShardCountPowerOfTwo(t *testing.T) {
+	for _, n := range []int{3, 1000, -4} {
+		if mgr, err := NewV3CacheManager(&V3CacheConfig{ShardCount: n, L1MaxSizeGB: 1}); err == nil {
+			mgr.Shutdown(context.Background())
+			t.Errorf("NewV3CacheManager accepted %d shards", n)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// internal/cache/cache_stats_v3.go
+// Detailed V3 cache statistics: per-tier occupancy and hits, per-shard
+// hit ratios, rates and lock waits, the hottest shards and how unevenly
+// bytes and load are spread across shards
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+// statsWindow is the interval per-shard rates are averaged over
+const statsWindow = 10 * time.Second
+
+// lockSampleEvery is how often a shard lock acquisition is timed; timing
+// every one would cost more than most acquisitions wait
+const lockSampleEvery = 16
+
+// rlock read-locks the shard, timing a sample of acquisitions
+func (shard *V3CacheShard) rlock() {
+	if shard.lockOps.Add(1)%lockSampleEvery != 0 {
+		shard.entriesLock.RLock()
+		return
+	}
+	start := time.Now()
+	shard.entriesLock.RLock()
+	shard.recordLockWait(time.Since(start))
+}
+
+// lock write-locks the shard, timing a sample of acquisitions
+func (shard *V3CacheShard) lock() {
+	if shard.lockOps.Add(1)%lockSampleEvery != 0 {
+		shard.entriesLock.Lock()
+		return
+	}
+	start := time.Now()
+	shard.entriesLock.Lock()
+	shard.recordLockWait(time.Since(start))
+}
+
+// recordLockWait adds a sampled wait to the shard's totals
+func (shard *V3CacheShard) recordLockWait(wait time.Duration) {
+	shard.lockSamples.Add(1)
+	shard.lockWaitNs.Add(int64(wait))
+	for {
+		longest := shard.lockWaitMaxNs.Load()
+		if int64(wait) <= longest || shard.lockWaitMaxNs.CompareAndSwap(longest, int64(wait)) {
+			return
+		}
+	}
+}
+
+// TierStats describes one cache tier
+type TierStats struct {
+	Tier     uint8   `json:"tier"`
+	Name     string  `json:"name"`
+	Entries  int64   `json:"entries"`
+	Bytes    int64   `json:"bytes"`
+	Hits     uint64  `json:"hits"`
+	HitShare float64 `json:"hit_share"` // Share of all hits served from the tier
+}
+
+// ShardStats describes one cache shard in detail. Rates are per second
+// over the last complete window and zero until one has passed.
+type ShardStats struct {
+	ShardOccupancy
+	HitRatio          float64 `json:"hit_ratio"`
+	Expirations       int64   `json:"expirations"`
+	EvictionsQueued   int64   `json:"evictions_queued"` // Inserts that found the shard over its size budget
+	LookupsPerSec     float64 `json:"lookups_per_sec"`
+	HitsPerSec        float64 `json:"hits_per_sec"`
+	ExpirationsPerSec float64 `json:"expirations_per_sec"`
+	EvictionsPerSec   float64 `json:"evictions_queued_per_sec"`
+	LockWaitMeanNs    int64   `json:"lock_wait_mean_ns"` // Over sampled acquisitions
+	LockWaitMaxNs     int64   `json:"lock_wait_max_ns"`
+}
+
+// DetailedStats breaks the cache's statistics down by tier and shard
+type DetailedStats struct {
+	Tiers   []TierStats  `json:"tiers"`
+	Shards  []ShardStats `json:"shards,omitempty"`
+	Hottest []ShardStats `json:"hottest"` // By lookups per second, then by hits
+
+	// The largest shard's bytes, and the busiest shard's lookups per
+	// second, over the mean; 1 is perfectly even
+	BytesSkew float64 `json:"bytes_skew"`
+	LoadSkew  float64 `json:"load_skew"`
+
+	LockWaitMeanNs int64   `json:"lock_wait_mean_ns"`
+	LockWaitMaxNs  int64   `json:"lock_wait_max_ns"`
+	WindowSeconds  float64 `json:"window_seconds"`
+}
+
+// shardCounters is a snapshot of one shard's counters
+type shardCounters struct {
+	hits, misses, expirations, evictionsQueued int64
+}
+
+// shardWindow holds every shard's counters at the start of a window
+type shardWindow struct {
+	start    time.Time
+	counters []shardCounters
+}
+
+// shardRates are every shard's rates over a completed window
+type shardRates struct {
+	seconds float64
+	lookups []float64
+	hits    []float64
+	expired []float64
+	evicted []float64
+}
+
+func snapshotShard(shard *V3CacheShard) shardCounters {
+	return shardCounters{
+		hits:            shard.hitCount.Load(),
+		misses:          shard.missCount.Load(),
+		expirations:     shard.expirations.Load(),
+		evictionsQueued: shard.evictionsQueued.Load(),
+	}
+}
+
+func newShardWindow(shards []*V3CacheShard, now time.Time) *shardWindow {
+	w := &shardWindow{start: now, counters: make([]shardCounters, len(shards))}
+	for i, shard := range shards {
+		w.counters[i] = snapshotShard(shard)
+	}
+	return w
+}
+
+// rates closes the window at now
+func (w *shardWindow) rates(shards []*V3CacheShard, now time.Time) *shardRates {
+	n := len(shards)
+	r := &shardRates{
+		seconds: now.Sub(w.start).Seconds(),
+		lookups: make([]float64, n),
+		hits:    make([]float64, n),
+		expired: make([]float64, n),
+		evicted: make([]float64, n),
+	}
+	for i, shard := range shards {
+		c, prev := snapshotShard(shard), w.counters[i]
+		r.lookups[i] = float64(c.hits+c.misses-prev.hits-prev.misses) / r.seconds
+		r.hits[i] = float64(c.hits-prev.hits) / r.seconds
+		r.expired[i] = float64(c.expirations-prev.expirations) / r.seconds
+		r.evicted[i] = float64(c.evictionsQueued-prev.evictionsQueued) / r.seconds
+	}
+	return r
+}
+
+// DetailedStats reports per-tier and per-shard statistics with the
+// hottest shards, up to hottest of them. Every shard is listed only with
+// allShards. Tier occupancy is counted by visiting every entry.
+func (m *V3CacheManager) DetailedStats(hottest int, allShards bool) *DetailedStats {
+	out := &DetailedStats{}
+	rates := m.rates.Load()
+	if rates != nil {
+		out.WindowSeconds = rates.seconds
+	}
+
+	shards := make([]ShardStats, len(m.shards))
+	var totalBytes, maxBytes int64
+	var totalLoad, maxLoad float64
+	var samples, waitNs int64
+	for i, shard := range m.shards {
+		st := ShardStats{
+			ShardOccupancy: ShardOccupancy{
+				Shard:   i,
+				Entries: shard.entryCount.Load(),
+				Bytes:   shard.usedSize.Load(),
+				Hits:    shard.hitCount.Load(),
+				Misses:  shard.missCount.Load(),
+			},
+			Expirations:     shard.expirations.Load(),
+			EvictionsQueued: shard.evictionsQueued.Load(),
+			LockWaitMaxNs:   shard.lockWaitMaxNs.Load(),
+		}
+		if lookups := st.Hits + st.Misses; lookups > 0 {
+			st.HitRatio = float64(st.Hits) / float64(lookups)
+		}
+		if n := shard.lockSamples.Load(); n > 0 {
+			ns := shard.lockWaitNs.Load()
+			st.LockWaitMeanNs = ns / n
+			samples += n
+			waitNs += ns
+		}
+		if rates != nil && i < len(rates.lookups) {
+			st.LookupsPerSec = rates.lookups[i]
+			st.HitsPerSec = rates.hits[i]
+			st.ExpirationsPerSec = rates.expired[i]
+			st.EvictionsPerSec = rates.evicted[i]
+		}
+
+		totalBytes += st.Bytes
+		totalLoad += st.LookupsPerSec
+		maxBytes = max(maxBytes, st.Bytes)
+		maxLoad = max(maxLoad, st.LookupsPerSec)
+		out.LockWaitMaxNs = max(out.LockWaitMaxNs, st.LockWaitMaxNs)
+		shards[i] = st
+	}
+	out.BytesSkew = skew(float64(maxBytes), float64(totalBytes), len(shards))
+	out.LoadSkew = skew(maxLoad, totalLoad, len(shards))
+	if samples > 0 {
+		out.LockWaitMeanNs = waitNs / samples
+	}
+
+	out.Tiers = m.tierStats()
+
+	if allShards {
+		out.Shards = shards
+	}
+	hot := append([]ShardStats(nil), shards...)
+	sort.SliceStable(hot, func(i, j int) bool {
+		if hot[i].LookupsPerSec != hot[j].LookupsPerSec {
+			return hot[i].LookupsPerSec > hot[j].LookupsPerSec
+		}
+		return hot[i].Hits > hot[j].Hits
+	})
+	out.Hottest = hot[:min(max(hottest, 0), len(hot))]
+	return out
+}
+
+// skew is largest over the mean of total across n shards, 0 when empty
+func skew(largest, total float64, n int) float64 {
+	if total <= 0 || n == 0 {
+		return 0
+	}
+	return largest / (total / float64(n))
+}
+
+// tierStats counts the live entries and bytes in each tier
+func (m *V3CacheManager) tierStats() []TierStats {
+	tiers := make([]TierStats, maxTier+1)
+	for i := range tiers {
+		tiers[i].Tier = uint8(i)
+		tiers[i].Name = []string{"L1", "L2", "L3"}[i]
+	}
+
+	now := time.Now().UnixNano()
+	for _, shard := range m.shards {
+		shard.rlock()
+		for _, entry := range shard.entries {
+			if exp := entry.ExpiresAt.Load(); exp != 0 && now >= exp {
+				continue
+			}
+			t := &tiers[min(entry.Tier, maxTier)]
+			t.Entries++
+			t.Bytes += int64(entry.DataSize.Load())
+		}
+		shard.entriesLock.RUnlock()
+	}
+
+	tiers[0].Hits = m.stats.L1Hits.Load()
+	tiers[1].Hits = m.stats.L2Hits.Load()
+	tiers[2].Hits = m.stats.L3Hits.Load()
+	if total := tiers[0].Hits + tiers[1].Hits + tiers[2].Hits; total > 0 {
+		for i := range tiers {
+			tiers[i].HitShare = float64(tiers[i].Hits) / float64(total)
+		}
+	}
+	return tiers
+}
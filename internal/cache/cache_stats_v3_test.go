@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDetailedStats(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+
+	// Every key below lands in one shard, so it is the hottest and largest
+	hotShard := -1
+	var keys []string
+	for i := 0; len(keys) < 50; i++ {
+		key := fmt.Sprintf("k%d", i)
+		shard := int(mgr.fastHash(key) & mgr.shardMask)
+		if hotShard == -1 {
+			hotShard = shard
+		}
+		if shard == hotShard {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		mgr.Set(ctx, key, []byte("value"))
+		mgr.Get(ctx, key)
+	}
+	mgr.Get(ctx, keys[0]+"-absent")
+
+	before := time.Now()
+	mgr.rates.Store(newShardWindow(mgr.shards, before.Add(-time.Second)).rates(mgr.shards, before))
+
+	stats := mgr.DetailedStats(3, true)
+	if len(stats.Shards) != 16 || len(stats.Hottest) != 3 {
+		t.Fatalf("Expected 16 shards and 3 hottest, got %d and %d", len(stats.Shards), len(stats.Hottest))
+	}
+	if stats.Hottest[0].Shard != hotShard {
+		t.Errorf("Expected shard %d hottest, got %d", hotShard, stats.Hottest[0].Shard)
+	}
+	if stats.BytesSkew != 16 {
+		t.Errorf("Expected bytes skew 16 with one shard holding everything, got %v", stats.BytesSkew)
+	}
+
+	var entries int64
+	for _, tier := range stats.Tiers {
+		entries += tier.Entries
+	}
+	if entries != int64(len(keys)) {
+		t.Errorf("Expected %d entries across tiers, got %d", len(keys), entries)
+	}
+	hot := stats.Shards[hotShard]
+	if hot.Hits != int64(len(keys)) || hot.HitRatio <= 0.9 {
+		t.Errorf("Expected %d hits and a hit ratio above 0.9, got %+v", len(keys), hot)
+	}
+	if mgr.shards[hotShard].lockSamples.Load() == 0 {
+		t.Error("Expected the hot shard's lock waits sampled")
+	}
+	if stats.Hottest[0].LookupsPerSec != 0 {
+		t.Errorf("Expected no lookups in an empty window, got %v", stats.Hottest[0].LookupsPerSec)
+	}
+}
+
+func TestShardWindowRates(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	start := time.Now()
+	window := newShardWindow(mgr.shards, start)
+
+	mgr.Set(context.Background(), "k", []byte("v"))
+	for i := 0; i < 20; i++ {
+		mgr.Get(context.Background(), "k")
+	}
+	rates := window.rates(mgr.shards, start.Add(2*time.Second))
+	shard := mgr.fastHash("k") & mgr.shardMask
+	if rates.hits[shard] != 10 || rates.lookups[shard] != 10 {
+		t.Errorf("Expected 10 hits/s, got %v hits/s and %v lookups/s", rates.hits[shard], rates.lookups[shard])
+	}
+}
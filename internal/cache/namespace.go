@@ -0,0 +1,102 @@
+// internal/cache/namespace.go
+// Tenant namespaces. Each tenant's entries live under its own key prefix
+// and record the tenant that owns them, and a TenantCache reads and
+// writes only inside one tenant's namespace, so tenants using the same
+// object key never see or overwrite each other's data.
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// TenantKey returns the cache key of tenantID's key. Tenant IDs never
+// contain '/', so the encoding is unambiguous.
+func TenantKey(tenantID, key string) string {
+	return tenantID + "/" + key
+}
+
+// SplitTenantKey returns the tenant and key of a cache key made by
+// TenantKey; ok is false for keys outside every tenant's namespace
+func SplitTenantKey(cacheKey string) (tenantID, key string, ok bool) {
+	return strings.Cut(cacheKey, "/")
+}
+
+// owner identifies whose entries an operation may touch
+type owner struct {
+	tenant string
+	scoped bool
+}
+
+// anyOwner is the owner of unscoped operations, which touch every entry
+var anyOwner = owner{}
+
+func (o owner) owns(entry *V3CacheEntry) bool {
+	return !o.scoped || entry.Owner == o.tenant
+}
+
+// TenantCache is one tenant's view of the cache. Keys are relative to the
+// tenant's namespace, and entries another tenant owns are misses.
+type TenantCache struct {
+	m     *V3CacheManager
+	owner owner
+}
+
+// Tenant returns tenantID's view of the cache
+func (m *V3CacheManager) Tenant(tenantID string) TenantCache {
+	return TenantCache{m: m, owner: owner{tenant: tenantID, scoped: true}}
+}
+
+func (c TenantCache) key(key string) string {
+	return TenantKey(c.owner.tenant, key)
+}
+
+// Get returns a copy of key's value
+func (c TenantCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.m.get(c.key(key), c.owner)
+}
+
+// ReadAt copies part of key's value into p, as V3CacheManager.ReadAt
+func (c TenantCache) ReadAt(ctx context.Context, key string, p []byte, off int64) (int, error) {
+	return c.m.readAt(c.key(key), c.owner, p, off)
+}
+
+// Set stores data at key
+func (c TenantCache) Set(ctx context.Context, key string, data []byte) error {
+	return c.SetWithTTL(ctx, key, data, 0)
+}
+
+// SetWithTTL stores data at key that expires after ttl (0 = never)
+func (c TenantCache) SetWithTTL(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return c.m.set(ctx, c.key(key), c.owner.tenant, data, ttl)
+}
+
+// Delete removes key, reporting whether it was cached
+func (c TenantCache) Delete(ctx context.Context, key string) bool {
+	return c.m.delete(c.key(key), c.owner)
+}
+
+// Rename moves oldKey's entry to newKey, as V3CacheManager.Rename
+func (c TenantCache) Rename(oldKey, newKey string) bool {
+	return c.m.rename(c.key(oldKey), c.key(newKey), c.owner)
+}
+
+// Stat reports key's size and tier without copying its data
+func (c TenantCache) Stat(key string) (size int64, tier uint8, ok bool) {
+	return c.m.stat(c.key(key), c.owner)
+}
+
+// TTL returns the time left before key expires (0 if it never does)
+func (c TenantCache) TTL(key string) (time.Duration, bool) {
+	if _, _, ok := c.Stat(key); !ok {
+		return 0, false
+	}
+	return c.m.TTL(c.key(key))
+}
+
+// Flush deletes the tenant's entries under prefix, returning what was
+// removed
+func (c TenantCache) Flush(prefix string) (entries, bytes int64) {
+	return c.m.FlushPrefix(c.key(prefix), nil)
+}
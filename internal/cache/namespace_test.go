@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTenantCacheIsolation(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+	a, b := mgr.Tenant("tenant-a"), mgr.Tenant("tenant-b")
+
+	if err := a.Set(ctx, "report.pdf", []byte("a's report")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Get(ctx, "report.pdf"); err == nil {
+		t.Fatal("Expected tenant b to miss tenant a's key")
+	}
+	if _, _, ok := b.Stat("report.pdf"); ok {
+		t.Error("Expected Stat by tenant b to miss")
+	}
+	if b.Delete(ctx, "report.pdf") {
+		t.Error("Expected Delete by tenant b to find nothing")
+	}
+
+	if err := b.Set(ctx, "report.pdf", []byte("b's report")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := a.Get(ctx, "report.pdf"); err != nil || string(got) != "a's report" {
+		t.Errorf("tenant a Get = %q, %v", got, err)
+	}
+	if got, err := b.Get(ctx, "report.pdf"); err != nil || string(got) != "b's report" {
+		t.Errorf("tenant b Get = %q, %v", got, err)
+	}
+
+	if !b.Delete(ctx, "report.pdf") {
+		t.Error("Expected tenant b to delete its own key")
+	}
+	if _, err := a.Get(ctx, "report.pdf"); err != nil {
+		t.Errorf("Deleting b's key removed a's: %v", err)
+	}
+}
+
+func TestTenantCacheRefusesForeignEntries(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+	a := mgr.Tenant("tenant-a")
+
+	// An unscoped write into a's namespace has no owner, so a never reads it
+	mgr.Set(ctx, TenantKey("tenant-a", "planted"), []byte("not a's"))
+	if _, err := a.Get(ctx, "planted"); err == nil {
+		t.Error("Expected an entry a does not own to be a miss")
+	}
+	p := make([]byte, 3)
+	if _, err := a.ReadAt(ctx, "planted", p, 0); err == nil {
+		t.Error("Expected ReadAt of an entry a does not own to miss")
+	}
+	if a.Delete(ctx, "planted") {
+		t.Error("Expected a not to delete an entry it does not own")
+	}
+	if _, err := mgr.Get(ctx, TenantKey("tenant-a", "planted")); err != nil {
+		t.Errorf("Unscoped Get = %v, want the planted entry", err)
+	}
+
+	a.Set(ctx, "mine", []byte("a's"))
+	if a.Rename("mine", "planted") {
+		t.Error("Expected Rename not to replace an entry a does not own")
+	}
+	if a.Rename("planted", "moved") {
+		t.Error("Expected Rename not to move an entry a does not own")
+	}
+	if got, _ := a.Get(ctx, "mine"); string(got) != "a's" {
+		t.Errorf("Refused renames changed a's entry to %q", got)
+	}
+
+	if n := mgr.GetStats().TenantMismatches.Load(); n != 5 {
+		t.Errorf("Expected 5 tenant mismatches, got %d", n)
+	}
+}
+
+func TestTenantCacheRenameAndFlush(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+	a, b := mgr.Tenant("tenant-a"), mgr.Tenant("tenant-b")
+
+	a.Set(ctx, "old", []byte("a"))
+	b.Set(ctx, "new", []byte("b"))
+	if !a.Rename("old", "new") {
+		t.Fatal("Expected a to rename its own entry")
+	}
+	if got, _ := a.Get(ctx, "new"); string(got) != "a" {
+		t.Errorf("a's renamed entry = %q", got)
+	}
+	if got, _ := b.Get(ctx, "new"); string(got) != "b" {
+		t.Errorf("Rename by a changed b's entry to %q", got)
+	}
+
+	a.Set(ctx, "tmp/1", []byte("x"))
+	b.Set(ctx, "tmp/1", []byte("y"))
+	if entries, _ := a.Flush("tmp/"); entries != 1 {
+		t.Errorf("Flush removed %d entries, want 1", entries)
+	}
+	if _, err := b.Get(ctx, "tmp/1"); err != nil {
+		t.Errorf("Flush by a removed b's entry: %v", err)
+	}
+}
+
+func TestTenantCacheConcurrentIsolation(t *testing.T) {
+	mgr := newFilterTestCache(t, 0)
+	ctx := context.Background()
+	tenants := []string{"tenant-a", "tenant-b", "tenant-c", "tenant-d"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(tenants))
+	for _, tenantID := range tenants {
+		wg.Add(1)
+		go func(tc TenantCache, tenantID string) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("shared/%d", i%50)
+				want := []byte(tenantID + "/" + key)
+				if err := tc.Set(ctx, key, want); err != nil {
+					errs <- err
+					return
+				}
+				got, err := tc.Get(ctx, key)
+				if err != nil {
+					errs <- fmt.Errorf("%s missed its own %s: %v", tenantID, key, err)
+					return
+				}
+				if !bytes.Equal(got, want) {
+					errs <- fmt.Errorf("%s read %q at %s", tenantID, got, key)
+					return
+				}
+				if i%7 == 0 {
+					tc.Delete(ctx, key)
+				}
+			}
+		}(mgr.Tenant(tenantID), tenantID)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := mgr.GetStats().TenantMismatches.Load(); n != 0 {
+		t.Errorf("Expected no tenant mismatches, got %d", n)
+	}
+}
+
+func TestSplitTenantKey(t *testing.T) {
+	tenantID, key, ok := SplitTenantKey(TenantKey("tenant-a", "a/b/c"))
+	if !ok || tenantID != "tenant-a" || key != "a/b/c" {
+		t.Errorf("SplitTenantKey = %q, %q, %v", tenantID, key, ok)
+	}
+	if _, _, ok := SplitTenantKey("unscoped"); ok {
+		t.Error("Expected a key without a tenant not to split")
+	}
+}
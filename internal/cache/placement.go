@@ -0,0 +1,284 @@
+// internal/cache/placement.go
+// Tier placement policies: which tier a new entry is written to. The size
+// policy reproduces the original fixed thresholds; the learned policy
+// adjusts them per prefix from an hour-of-day access profile and the
+// tenant's class.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlacementPolicy chooses the tier (0=L1, 1=L2, 2=L3) of a new entry
+type PlacementPolicy interface {
+	Name() string
+	Place(in PlacementInput) uint8
+}
+
+// PlacementLearner is a policy that learns from object accesses
+type PlacementLearner interface {
+	PlacementPolicy
+	Observe(tenantID, key string, t time.Time)
+}
+
+// PlacementHints carry what the cache cannot see from a key alone
+type PlacementHints struct {
+	TenantID    string
+	TenantClass string // The tenant's plan, "" if unknown
+}
+
+// PlacementInput describes an entry being placed
+type PlacementInput struct {
+	Key  string
+	Size int64
+	Time time.Time
+	PlacementHints
+}
+
+type placementHintsKey struct{}
+
+// WithPlacementHints attaches hints to the context of a Set
+func WithPlacementHints(ctx context.Context, hints PlacementHints) context.Context {
+	return context.WithValue(ctx, placementHintsKey{}, hints)
+}
+
+func placementHints(ctx context.Context) PlacementHints {
+	hints, _ := ctx.Value(placementHintsKey{}).(PlacementHints)
+	return hints
+}
+
+var (
+	placementMu       sync.RWMutex
+	placementPolicies = map[string]func() PlacementPolicy{
+		"size":    func() PlacementPolicy { return DefaultSizePolicy() },
+		"learned": func() PlacementPolicy { return NewLearnedPolicy(LearnedConfig{}) },
+	}
+)
+
+// RegisterPlacementPolicy makes a policy available to NewPlacementPolicy
+func RegisterPlacementPolicy(name string, factory func() PlacementPolicy) {
+	placementMu.Lock()
+	defer placementMu.Unlock()
+	placementPolicies[name] = factory
+}
+
+// NewPlacementPolicy creates a registered policy by name
+func NewPlacementPolicy(name string) (PlacementPolicy, error) {
+	placementMu.RLock()
+	factory, ok := placementPolicies[name]
+	placementMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown placement policy %q (want one of %s)", name, strings.Join(PlacementPolicies(), ", "))
+	}
+	return factory(), nil
+}
+
+// PlacementPolicies lists the registered policy names
+func PlacementPolicies() []string {
+	placementMu.RLock()
+	defer placementMu.RUnlock()
+	names := make([]string, 0, len(placementPolicies))
+	for name := range placementPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ========== Size ==========
+
+// SizePolicy places entries by size alone
+type SizePolicy struct {
+	L1MaxBytes int64 // Entries smaller than this go to L1
+	L2MaxBytes int64 // ... then L2; the rest to L3
+}
+
+// DefaultSizePolicy is <100MB to L1, <1GB to L2
+func DefaultSizePolicy() SizePolicy {
+	return SizePolicy{L1MaxBytes: 100 << 20, L2MaxBytes: 1 << 30}
+}
+
+func (p SizePolicy) Name() string { return "size" }
+
+func (p SizePolicy) Place(in PlacementInput) uint8 {
+	switch {
+	case in.Size < p.L1MaxBytes:
+		return 0
+	case in.Size < p.L2MaxBytes:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ========== Learned ==========
+
+// LearnedConfig tunes LearnedPolicy; zero fields take defaults
+type LearnedConfig struct {
+	// Baseline is adjusted by at most one tier for access frequency
+	Baseline SizePolicy
+
+	// Depth is the number of "/"-separated key segments profiles are kept
+	// per (default 1)
+	Depth int
+
+	// Predicted requests per hour at or above which entries go one tier
+	// warmer (default 60), and at or below which one tier colder (default 0.5)
+	HotRate  float64
+	ColdRate float64
+
+	// ClassBias shifts the tier per tenant class; negative is warmer.
+	// Default: enterprise -1, free +1.
+	ClassBias map[string]int
+
+	// Smoothing is the weight of the latest day in each hour's learned
+	// rate (default 0.3)
+	Smoothing float64
+
+	// MaxPrefixes bounds the profiles kept (default 100000)
+	MaxPrefixes int
+}
+
+// LearnedPolicy learns, per tenant prefix, how often it is read at each
+// hour of the day. A prefix expected to be busy in the hour an entry is
+// written, or the next, goes one tier warmer than its size alone would
+// place it; one expected to be idle goes one tier colder. Prefixes never
+// seen keep the size placement. The tenant's class then shifts the tier.
+type LearnedPolicy struct {
+	config LearnedConfig
+
+	mu       sync.Mutex
+	profiles map[string]*hourProfile
+}
+
+// hourProfile is a prefix's learned requests per hour for each hour of the day
+type hourProfile struct {
+	rates [24]float64
+	hour  int64 // Hours since the epoch being counted
+	count float64
+}
+
+// NewLearnedPolicy creates a policy with nothing learned yet
+func NewLearnedPolicy(config LearnedConfig) *LearnedPolicy {
+	if config.Baseline == (SizePolicy{}) {
+		config.Baseline = DefaultSizePolicy()
+	}
+	if config.Depth <= 0 {
+		config.Depth = 1
+	}
+	if config.HotRate <= 0 {
+		config.HotRate = 60
+	}
+	if config.ColdRate <= 0 {
+		config.ColdRate = 0.5
+	}
+	if config.ClassBias == nil {
+		config.ClassBias = map[string]int{"enterprise": -1, "free": 1}
+	}
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = 0.3
+	}
+	if config.MaxPrefixes <= 0 {
+		config.MaxPrefixes = 100000
+	}
+	return &LearnedPolicy{config: config, profiles: make(map[string]*hourProfile)}
+}
+
+func (p *LearnedPolicy) Name() string { return "learned" }
+
+func (p *LearnedPolicy) Place(in PlacementInput) uint8 {
+	tier := int(p.config.Baseline.Place(in))
+	if rate, ok := p.Predict(in.TenantID, in.Key, in.Time); ok {
+		switch {
+		case rate >= p.config.HotRate:
+			tier--
+		case rate <= p.config.ColdRate:
+			tier++
+		}
+	}
+	tier += p.config.ClassBias[in.TenantClass]
+	return uint8(min(max(tier, 0), maxTier))
+}
+
+// Observe counts one read of key at t
+func (p *LearnedPolicy) Observe(tenantID, key string, t time.Time) {
+	name := p.profileName(tenantID, key)
+	hour := t.Unix() / 3600
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prof := p.profiles[name]
+	if prof == nil {
+		if len(p.profiles) >= p.config.MaxPrefixes {
+			return
+		}
+		prof = &hourProfile{hour: hour}
+		p.profiles[name] = prof
+	}
+	prof.advance(hour, p.config.Smoothing)
+	prof.count++
+}
+
+// Predict returns the requests per hour expected for key's prefix around
+// t, and false if the prefix has never been read
+func (p *LearnedPolicy) Predict(tenantID, key string, t time.Time) (float64, bool) {
+	name := p.profileName(tenantID, key)
+	hour := t.Unix() / 3600
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prof := p.profiles[name]
+	if prof == nil {
+		return 0, false
+	}
+	prof.advance(hour, p.config.Smoothing)
+	h := hour % 24
+	return max(prof.rates[h], prof.rates[(h+1)%24], prof.count), true
+}
+
+// Prefixes is the number of prefix profiles learned
+func (p *LearnedPolicy) Prefixes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.profiles)
+}
+
+func (p *LearnedPolicy) profileName(tenantID, key string) string {
+	end := 0
+	for i := 0; i < p.config.Depth; i++ {
+		j := strings.IndexByte(key[end:], '/')
+		if j < 0 {
+			break
+		}
+		end += j + 1
+	}
+	return tenantID + "/" + key[:end]
+}
+
+// maxIdleHours bounds the idle hours folded in at once; a month of zeros
+// leaves no trace of earlier activity at any useful smoothing
+const maxIdleHours = 24 * 31
+
+// advance folds the hour being counted, and any idle hours after it up to
+// hour, into the learned rates
+func (prof *hourProfile) advance(hour int64, smoothing float64) {
+	if hour <= prof.hour {
+		return
+	}
+	h := prof.hour % 24
+	prof.rates[h] = smoothing*prof.count + (1-smoothing)*prof.rates[h]
+	prof.count = 0
+
+	idle := min(hour-prof.hour-1, maxIdleHours)
+	for i := int64(1); i <= idle; i++ {
+		prof.rates[(hour-i)%24] *= 1 - smoothing
+	}
+	prof.hour = hour
+}
@@ -0,0 +1,114 @@
+// internal/cache/placement_sim.go
+// Offline simulator that replays a recorded trace against a placement
+// policy to compare where reads would have been served from
+package cache
+
+import "time"
+
+// SimConfig describes the simulated tiers
+type SimConfig struct {
+	// Capacity per tier in bytes; 0 is unlimited. An entry placed in a
+	// full tier spills to the next colder one with room.
+	Capacity [maxTier + 1]int64
+
+	// Latency of a read served from each tier
+	Latency [maxTier + 1]time.Duration
+}
+
+// DefaultSimConfig has 1GiB of L1, 10GiB of L2, unlimited L3 and
+// latencies typical of memory, NVMe and disk
+func DefaultSimConfig() SimConfig {
+	return SimConfig{
+		Capacity: [maxTier + 1]int64{1 << 30, 10 << 30, 0},
+		Latency:  [maxTier + 1]time.Duration{100 * time.Microsecond, time.Millisecond, 10 * time.Millisecond},
+	}
+}
+
+// SimResult is the outcome of replaying a trace
+type SimResult struct {
+	Policy      string             `json:"policy"`
+	Puts        int64              `json:"puts"`
+	Gets        int64              `json:"gets"`
+	Deletes     int64              `json:"deletes"`
+	Misses      int64              `json:"misses"` // Reads of keys never written in the trace
+	Hits        [maxTier + 1]int64 `json:"hits"`   // Reads served per tier
+	HitBytes    [maxTier + 1]int64 `json:"hit_bytes"`
+	Resident    [maxTier + 1]int64 `json:"resident"` // Bytes per tier at the end
+	Spills      int64              `json:"spills"`   // Entries placed colder than chosen for lack of room
+	Rejected    int64              `json:"rejected"` // Entries no tier had room for
+	MeanLatency time.Duration      `json:"mean_latency"`
+}
+
+// Simulate replays events in order: puts are placed by policy (within
+// cfg's capacities), deletes free their entry, gets are served from
+// wherever their key sits, and learning policies observe every get. Entries are never moved after
+// placement, so the result isolates the placement decision.
+func Simulate(policy PlacementPolicy, events []TraceEvent, cfg SimConfig) SimResult {
+	res := SimResult{Policy: policy.Name()}
+	learner, _ := policy.(PlacementLearner)
+
+	type placed struct {
+		tier uint8
+		size int64
+	}
+	entries := make(map[string]placed)
+	var latency time.Duration
+
+	for _, ev := range events {
+		id := ev.TenantID + "/" + ev.Key
+		switch ev.Op {
+		case TracePut:
+			res.Puts++
+			if prev, ok := entries[id]; ok {
+				res.Resident[prev.tier] -= prev.size
+				delete(entries, id)
+			}
+			tier := min(policy.Place(PlacementInput{
+				Key:            ev.Key,
+				Size:           ev.Size,
+				Time:           ev.Time,
+				PlacementHints: PlacementHints{TenantID: ev.TenantID, TenantClass: ev.TenantClass},
+			}), maxTier)
+			for chosen := tier; ; tier++ {
+				if tier > maxTier {
+					res.Rejected++
+					break
+				}
+				if c := cfg.Capacity[tier]; c == 0 || res.Resident[tier]+ev.Size <= c {
+					if tier != chosen {
+						res.Spills++
+					}
+					entries[id] = placed{tier, ev.Size}
+					res.Resident[tier] += ev.Size
+					break
+				}
+			}
+
+		case TraceDelete:
+			res.Deletes++
+			if prev, ok := entries[id]; ok {
+				res.Resident[prev.tier] -= prev.size
+				delete(entries, id)
+			}
+
+		case TraceGet:
+			res.Gets++
+			if learner != nil {
+				learner.Observe(ev.TenantID, ev.Key, ev.Time)
+			}
+			e, ok := entries[id]
+			if !ok {
+				res.Misses++
+				continue
+			}
+			res.Hits[e.tier]++
+			res.HitBytes[e.tier] += e.size
+			latency += cfg.Latency[e.tier]
+		}
+	}
+
+	if served := res.Gets - res.Misses; served > 0 {
+		res.MeanLatency = latency / time.Duration(served)
+	}
+	return res
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSizePolicy(t *testing.T) {
+	p := DefaultSizePolicy()
+	tests := []struct {
+		size int64
+		want uint8
+	}{
+		{1 << 10, 0},
+		{100<<20 - 1, 0},
+		{100 << 20, 1},
+		{1 << 30, 2},
+	}
+	for _, tt := range tests {
+		if got := p.Place(PlacementInput{Size: tt.size}); got != tt.want {
+			t.Errorf("Place(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestLearnedPolicy(t *testing.T) {
+	p := NewLearnedPolicy(LearnedConfig{HotRate: 10, ColdRate: 1})
+	morning := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// reports/ is read heavily every morning for a week; archive/ once
+	for day := 0; day < 7; day++ {
+		for i := 0; i < 50; i++ {
+			p.Observe("t1", "reports/q.csv", morning.AddDate(0, 0, day).Add(time.Duration(i)*time.Minute))
+		}
+	}
+	p.Observe("t1", "archive/old.tar", morning)
+
+	medium := PlacementInput{Size: 200 << 20, PlacementHints: PlacementHints{TenantID: "t1"}} // L2 by size
+	place := func(key string, at time.Time, class string) uint8 {
+		in := medium
+		in.Key, in.Time, in.TenantClass = key, at, class
+		return p.Place(in)
+	}
+
+	nextMorning := morning.AddDate(0, 0, 7).Add(-30 * time.Minute) // 08:30, the hour before the rush
+	if got := place("reports/new.csv", nextMorning, ""); got != 0 {
+		t.Errorf("Expected a busy prefix promoted to L1 ahead of its hour, got L%d", got+1)
+	}
+	if got := place("reports/new.csv", morning.AddDate(0, 0, 7).Add(8*time.Hour), ""); got != 2 {
+		t.Errorf("Expected the same prefix idle in the evening, got L%d", got+1)
+	}
+	if got := place("archive/new.tar", nextMorning, ""); got != 2 {
+		t.Errorf("Expected a rarely read prefix demoted to L3, got L%d", got+1)
+	}
+	if got := place("unseen/x", nextMorning, ""); got != 1 {
+		t.Errorf("Expected an unknown prefix left at its size placement, got L%d", got+1)
+	}
+	if got := place("unseen/x", nextMorning, "enterprise"); got != 0 {
+		t.Errorf("Expected the enterprise class one tier warmer, got L%d", got+1)
+	}
+	if got := place("archive/new.tar", nextMorning, "free"); got != 2 {
+		t.Errorf("Expected tiers clamped at L3, got L%d", got+1)
+	}
+	if p.Prefixes() != 2 {
+		t.Errorf("Expected 2 prefixes learned, got %d", p.Prefixes())
+	}
+}
+
+func TestHourProfileIdleDecay(t *testing.T) {
+	prof := &hourProfile{hour: 0, count: 10}
+	prof.advance(24, 0.5) // Fold hour 0, then 23 idle hours
+	if prof.rates[0] != 5 {
+		t.Fatalf("Expected rate 5 for hour 0, got %v", prof.rates[0])
+	}
+	prof.advance(48, 0.5) // Hour 24 is hour 0 of the next day, idle
+	if prof.rates[0] != 2.5 {
+		t.Errorf("Expected an idle day to halve the rate, got %v", prof.rates[0])
+	}
+	prof.advance(48+365*24, 0.5) // A year idle folds a bounded number of hours
+	if prof.rates[0] > 1e-9 {
+		t.Errorf("Expected the rate forgotten after a long idle, got %v", prof.rates[0])
+	}
+}
+
+func TestPlacementRegistry(t *testing.T) {
+	p, err := NewPlacementPolicy("learned")
+	if err != nil || p.Name() != "learned" {
+		t.Fatalf("Got %v, %v", p, err)
+	}
+	if _, err := NewPlacementPolicy("nope"); err == nil {
+		t.Error("Expected error for an unknown policy")
+	}
+
+	RegisterPlacementPolicy("all-l3", func() PlacementPolicy { return SizePolicy{} })
+	if names := strings.Join(PlacementPolicies(), ","); !strings.Contains(names, "all-l3") {
+		t.Errorf("Expected registered policy listed, got %s", names)
+	}
+}
+
+func TestCacheUsesPlacement(t *testing.T) {
+	mgr, err := NewV3CacheManager(&V3CacheConfig{ShardCount: 16, L1MaxSizeGB: 1,
+		Placement: NewLearnedPolicy(LearnedConfig{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Shutdown(context.Background())
+
+	ctx := WithPlacementHints(context.Background(), PlacementHints{TenantID: "t1", TenantClass: "free"})
+	mgr.Set(ctx, "k", []byte("small"))
+	if _, tier, ok := mgr.Stat("k"); !ok || tier != 1 {
+		t.Errorf("Expected a free tenant's small entry in L2, got L%d (%v)", tier+1, ok)
+	}
+	if !mgr.Promote("k", 0) || mgr.Promote("k", 0) {
+		t.Error("Expected Promote to move the entry once")
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var trace []TraceEvent
+	put := func(at time.Time, key string) {
+		trace = append(trace, TraceEvent{Time: at, Op: TracePut, TenantID: "t1", Key: key, Size: 100})
+	}
+	get := func(at time.Time, key string) {
+		trace = append(trace, TraceEvent{Time: at, Op: TraceGet, TenantID: "t1", Key: key, Size: 100})
+	}
+
+	// A day of history: hot/ is read all morning, cold/ once
+	put(start, "hot/seed")
+	put(start, "cold/seed")
+	for i := 0; i < 60; i++ {
+		get(start.Add(time.Duration(i)*time.Minute), "hot/seed")
+	}
+	get(start, "cold/seed")
+
+	// The next morning cold objects are written first, then hot ones that
+	// are read repeatedly; L1 only fits six entries
+	next := start.AddDate(0, 0, 1)
+	for i := 0; i < 4; i++ {
+		put(next, fmt.Sprintf("cold/%d", i))
+	}
+	for i := 0; i < 4; i++ {
+		put(next, fmt.Sprintf("hot/%d", i))
+		for j := 0; j < 10; j++ {
+			get(next.Add(time.Minute), fmt.Sprintf("hot/%d", i))
+		}
+	}
+	get(next, "missing")
+
+	cfg := DefaultSimConfig()
+	cfg.Capacity[0] = 600
+
+	size := Simulate(DefaultSizePolicy(), trace, cfg)
+	learned := Simulate(NewLearnedPolicy(LearnedConfig{}), trace, cfg)
+
+	if size.Puts != 10 || size.Gets != 102 || size.Misses != 1 {
+		t.Fatalf("Unexpected counts: %+v", size)
+	}
+	if size.Spills != 4 || size.Hits[0] != 61 || size.Hits[1] != 40 {
+		t.Errorf("Expected size placement to spill the hot objects to L2, got %+v", size)
+	}
+	if learned.Spills != 0 || learned.Hits[0] != 101 || learned.Resident[1] != 400 {
+		t.Errorf("Expected learned placement to keep L1 for hot/ and send cold/ to L2, got %+v", learned)
+	}
+	if learned.MeanLatency >= size.MeanLatency {
+		t.Errorf("Expected learned placement faster: %v vs %v", learned.MeanLatency, size.MeanLatency)
+	}
+}
+
+func TestSimulateDelete(t *testing.T) {
+	trace := []TraceEvent{
+		{Op: TracePut, TenantID: "t1", Key: "a", Size: 100},
+		{Op: TraceDelete, TenantID: "t1", Key: "a", Size: 100},
+		{Op: TraceGet, TenantID: "t1", Key: "a", Size: 100},
+	}
+	res := Simulate(DefaultSizePolicy(), trace, DefaultSimConfig())
+	if res.Deletes != 1 || res.Misses != 1 || res.Resident[0] != 0 {
+		t.Errorf("Expected the deleted entry freed, got %+v", res)
+	}
+}
@@ -0,0 +1,333 @@
+// internal/cache/replay.go
+// Trace replay: drives a cache engine with a recorded trace and measures
+// hit ratios, evictions and operation latency, for capacity planning and
+// comparing eviction policies on real workloads
+package cache
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ReplayTarget is a cache a trace is replayed against
+type ReplayTarget interface {
+	Name() string
+	Get(ev TraceEvent) bool
+	Set(ev TraceEvent)
+	Delete(ev TraceEvent)
+
+	// Bytes is the size of the entries held; Evictions how many entries
+	// were dropped for room
+	Bytes() int64
+	Evictions() int64
+}
+
+// ReplayOptions tune Replay
+type ReplayOptions struct {
+	// ReadThrough sets a key after a get misses, as the gateway does
+	ReadThrough bool
+
+	// Speed replays with the trace's own timing divided by Speed (2 is
+	// twice as fast as recorded); 0 replays as fast as possible
+	Speed float64
+}
+
+// ReplayResult is the outcome of replaying a trace against one target
+type ReplayResult struct {
+	Target    string        `json:"target"`
+	Events    int64         `json:"events"`
+	Puts      int64         `json:"puts"`
+	Gets      int64         `json:"gets"`
+	Deletes   int64         `json:"deletes"`
+	Hits      int64         `json:"hits"`
+	Misses    int64         `json:"misses"`
+	HitBytes  int64         `json:"hit_bytes"`
+	MissBytes int64         `json:"miss_bytes"`
+	Evictions int64         `json:"evictions"`
+	PeakBytes int64         `json:"peak_bytes"` // Most bytes held at once
+	Elapsed   time.Duration `json:"elapsed"`
+	GetP50    time.Duration `json:"get_p50"`
+	GetP99    time.Duration `json:"get_p99"`
+	SetP50    time.Duration `json:"set_p50"`
+	SetP99    time.Duration `json:"set_p99"`
+}
+
+// HitRatio is the fraction of gets served from the cache
+func (r ReplayResult) HitRatio() float64 {
+	if r.Gets == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Gets)
+}
+
+// ByteHitRatio is the fraction of bytes read served from the cache
+func (r ReplayResult) ByteHitRatio() float64 {
+	if total := r.HitBytes + r.MissBytes; total > 0 {
+		return float64(r.HitBytes) / float64(total)
+	}
+	return 0
+}
+
+// Replay drives target with events in order and measures the outcome.
+// It stops early, with what was measured so far, if ctx is cancelled.
+func Replay(ctx context.Context, target ReplayTarget, events []TraceEvent, opts ReplayOptions) ReplayResult {
+	res := ReplayResult{Target: target.Name()}
+	var gets, sets []time.Duration
+
+	set := func(ev TraceEvent) {
+		start := time.Now()
+		target.Set(ev)
+		sets = append(sets, time.Since(start))
+		res.PeakBytes = max(res.PeakBytes, target.Bytes())
+	}
+
+	began := time.Now()
+	for i, ev := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		if opts.Speed > 0 && i > 0 {
+			due := began.Add(time.Duration(float64(ev.Time.Sub(events[0].Time)) / opts.Speed))
+			if wait := time.Until(due); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+
+		res.Events++
+		switch ev.Op {
+		case TracePut:
+			res.Puts++
+			set(ev)
+
+		case TraceGet:
+			res.Gets++
+			start := time.Now()
+			hit := target.Get(ev)
+			gets = append(gets, time.Since(start))
+			if hit {
+				res.Hits++
+				res.HitBytes += ev.Size
+				continue
+			}
+			res.Misses++
+			res.MissBytes += ev.Size
+			if opts.ReadThrough {
+				set(ev)
+			}
+
+		case TraceDelete:
+			res.Deletes++
+			target.Delete(ev)
+		}
+	}
+
+	res.Elapsed = time.Since(began)
+	res.Evictions = target.Evictions()
+	res.GetP50, res.GetP99 = percentiles(gets)
+	res.SetP50, res.SetP99 = percentiles(sets)
+	return res
+}
+
+// percentiles returns the 50th and 99th percentile of d, which it sorts
+func percentiles(d []time.Duration) (p50, p99 time.Duration) {
+	if len(d) == 0 {
+		return 0, 0
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	return d[len(d)*50/100], d[len(d)*99/100]
+}
+
+// replayID scopes a trace key to its tenant, as the server's keys are
+func replayID(ev TraceEvent) string {
+	return TenantKey(ev.TenantID, ev.Key)
+}
+
+// ========== V3 engine ==========
+
+// V3Target replays against a V3CacheManager. Entries are written with at
+// most maxPayload bytes each so large traces fit in memory; hit bytes and
+// Bytes still count the sizes recorded in the trace.
+type V3Target struct {
+	mgr     *V3CacheManager
+	payload []byte
+	sizes   map[string]int64
+	bytes   int64
+}
+
+// NewV3Target replays against mgr, writing at most maxPayload bytes per entry
+func NewV3Target(mgr *V3CacheManager, maxPayload int) *V3Target {
+	return &V3Target{mgr: mgr, payload: make([]byte, maxPayload), sizes: make(map[string]int64)}
+}
+
+func (t *V3Target) Name() string { return "v3" }
+
+func (t *V3Target) Get(ev TraceEvent) bool {
+	id := replayID(ev)
+	if _, err := t.mgr.Get(context.Background(), id); err != nil {
+		// Gone from the engine without a delete: evicted or expired
+		t.forget(id)
+		return false
+	}
+	return true
+}
+
+func (t *V3Target) Set(ev TraceEvent) {
+	id := replayID(ev)
+	ctx := WithPlacementHints(context.Background(), PlacementHints{TenantID: ev.TenantID, TenantClass: ev.TenantClass})
+	t.mgr.Set(ctx, id, t.payload[:min(ev.Size, int64(len(t.payload)))])
+	t.forget(id)
+	t.sizes[id] = ev.Size
+	t.bytes += ev.Size
+}
+
+func (t *V3Target) Delete(ev TraceEvent) {
+	id := replayID(ev)
+	t.mgr.Delete(context.Background(), id)
+	t.forget(id)
+}
+
+func (t *V3Target) forget(id string) {
+	if size, ok := t.sizes[id]; ok {
+		t.bytes -= size
+		delete(t.sizes, id)
+	}
+}
+
+// Bytes is the trace size of the entries the engine holds
+func (t *V3Target) Bytes() int64 { return t.bytes }
+
+func (t *V3Target) Evictions() int64 { return int64(t.mgr.GetStats().TotalEvictions.Load()) }
+
+// ========== Bounded reference caches ==========
+
+// Eviction policies of BoundedCache
+var BoundedPolicies = []string{"lru", "lfu", "fifo"}
+
+// BoundedCache is a capacity-bounded cache holding only sizes, evicting by
+// LRU, LFU or FIFO. It is a reference to compare engines against and to
+// find the capacity a workload needs for a given hit ratio.
+type BoundedCache struct {
+	policy   string
+	capacity int64
+
+	entries   map[string]*boundedEntry
+	order     boundedHeap
+	clock     uint64
+	bytes     int64
+	evictions int64
+}
+
+type boundedEntry struct {
+	id       string
+	size     int64
+	inserted uint64 // Clock at insertion, for FIFO
+	used     uint64 // Clock at last access, for LRU and LFU ties
+	count    uint64 // Accesses, for LFU
+	index    int
+}
+
+// NewBoundedCache creates an empty cache of capacity bytes evicting by policy
+func NewBoundedCache(policy string, capacity int64) (*BoundedCache, error) {
+	switch policy {
+	case "lru", "lfu", "fifo":
+	default:
+		return nil, fmt.Errorf("unknown eviction policy %q (want lru, lfu or fifo)", policy)
+	}
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be positive")
+	}
+	c := &BoundedCache{policy: policy, capacity: capacity, entries: make(map[string]*boundedEntry)}
+	c.order.policy = policy
+	return c, nil
+}
+
+func (c *BoundedCache) Name() string { return c.policy }
+
+func (c *BoundedCache) Get(ev TraceEvent) bool {
+	e, ok := c.entries[replayID(ev)]
+	if !ok {
+		return false
+	}
+	c.clock++
+	e.used = c.clock
+	e.count++
+	heap.Fix(&c.order, e.index)
+	return true
+}
+
+// Set inserts or replaces an entry, evicting until it fits; entries larger
+// than the whole cache are not admitted
+func (c *BoundedCache) Set(ev TraceEvent) {
+	id := replayID(ev)
+	c.remove(id)
+	if ev.Size > c.capacity {
+		return
+	}
+	for c.bytes+ev.Size > c.capacity {
+		victim := heap.Pop(&c.order).(*boundedEntry)
+		delete(c.entries, victim.id)
+		c.bytes -= victim.size
+		c.evictions++
+	}
+	c.clock++
+	e := &boundedEntry{id: id, size: ev.Size, inserted: c.clock, used: c.clock, count: 1}
+	c.entries[id] = e
+	heap.Push(&c.order, e)
+	c.bytes += ev.Size
+}
+
+func (c *BoundedCache) Delete(ev TraceEvent) { c.remove(replayID(ev)) }
+
+func (c *BoundedCache) remove(id string) {
+	if e, ok := c.entries[id]; ok {
+		heap.Remove(&c.order, e.index)
+		delete(c.entries, id)
+		c.bytes -= e.size
+	}
+}
+
+func (c *BoundedCache) Bytes() int64     { return c.bytes }
+func (c *BoundedCache) Evictions() int64 { return c.evictions }
+
+// boundedHeap orders entries with the next eviction victim first
+type boundedHeap struct {
+	policy  string
+	entries []*boundedEntry
+}
+
+func (h boundedHeap) Len() int { return len(h.entries) }
+
+func (h boundedHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	switch h.policy {
+	case "fifo":
+		return a.inserted < b.inserted
+	case "lfu":
+		if a.count != b.count {
+			return a.count < b.count
+		}
+	}
+	return a.used < b.used
+}
+
+func (h boundedHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *boundedHeap) Push(x interface{}) {
+	e := x.(*boundedEntry)
+	e.index = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *boundedHeap) Pop() interface{} {
+	n := len(h.entries)
+	e := h.entries[n-1]
+	h.entries = h.entries[:n-1]
+	return e
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// scanTrace writes n keys of size 10 and then reads hot once per key,
+// interleaved with a single read of every cold key
+func scanTrace(n int) []TraceEvent {
+	var trace []TraceEvent
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ev := func(op, key string) {
+		trace = append(trace, TraceEvent{Time: at, Op: op, TenantID: "t1", Key: key, Size: 10})
+	}
+	ev(TracePut, "hot")
+	for i := 0; i < n; i++ {
+		ev(TraceGet, "hot")
+		ev(TraceGet, "hot")
+		ev(TraceGet, fmt.Sprintf("cold/%d", i))
+	}
+	return trace
+}
+
+func TestBoundedCachePolicies(t *testing.T) {
+	trace := scanTrace(100)
+	results := map[string]ReplayResult{}
+	for _, policy := range BoundedPolicies {
+		c, err := NewBoundedCache(policy, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[policy] = Replay(context.Background(), c, trace, ReplayOptions{ReadThrough: true})
+		if c.Bytes() > 30 {
+			t.Errorf("%s: %d bytes held over capacity", policy, c.Bytes())
+		}
+	}
+
+	lru, lfu, fifo := results["lru"], results["lfu"], results["fifo"]
+	if lru.Gets != 300 || lru.Hits != 200 || lru.Misses != 100 || lru.Evictions != 98 {
+		t.Errorf("Expected LRU to keep hot and miss every cold read, got %+v", lru)
+	}
+	if lfu.Hits != 200 {
+		t.Errorf("Expected LFU to keep hot, got %+v", lfu)
+	}
+	if fifo.Hits >= lru.Hits {
+		t.Errorf("Expected FIFO to evict hot and lose hits: %d vs %d", fifo.Hits, lru.Hits)
+	}
+	if lru.PeakBytes != 30 || lru.HitRatio() != 2.0/3 || lru.ByteHitRatio() != 2.0/3 {
+		t.Errorf("Unexpected peak or ratios: %+v", lru)
+	}
+
+	if _, err := NewBoundedCache("random", 1); err == nil {
+		t.Error("Expected error for an unknown policy")
+	}
+}
+
+func TestBoundedCacheDeleteAndOversize(t *testing.T) {
+	c, _ := NewBoundedCache("lru", 100)
+	c.Set(TraceEvent{Key: "a", Size: 60})
+	c.Set(TraceEvent{Key: "huge", Size: 101})
+	c.Delete(TraceEvent{Key: "a"})
+	if c.Bytes() != 0 || c.Get(TraceEvent{Key: "a"}) || c.Get(TraceEvent{Key: "huge"}) {
+		t.Errorf("Expected an empty cache, got %d bytes", c.Bytes())
+	}
+
+	// A replaced entry is not counted twice
+	c.Set(TraceEvent{Key: "a", Size: 60})
+	c.Set(TraceEvent{Key: "a", Size: 70})
+	if c.Bytes() != 70 || c.Evictions() != 0 {
+		t.Errorf("Expected 70 bytes and no evictions, got %d and %d", c.Bytes(), c.Evictions())
+	}
+}
+
+func TestReplayV3(t *testing.T) {
+	mgr, err := NewV3CacheManager(&V3CacheConfig{ShardCount: 16, L1MaxSizeGB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Shutdown(context.Background())
+
+	trace := scanTrace(10)
+	trace = append(trace, TraceEvent{Op: TraceDelete, TenantID: "t1", Key: "hot", Size: 10},
+		TraceEvent{Op: TraceGet, TenantID: "t1", Key: "hot", Size: 10})
+
+	target := NewV3Target(mgr, 4)
+	res := Replay(context.Background(), target, trace, ReplayOptions{})
+	if res.Gets != 31 || res.Hits != 20 || res.Deletes != 1 || res.HitBytes != 200 {
+		t.Errorf("Unexpected replay: %+v", res)
+	}
+	if size, _, ok := mgr.Stat("t1/cold/0"); ok || size != 0 {
+		t.Error("Expected misses not filled without read-through")
+	}
+	if target.Bytes() != 0 || res.PeakBytes != 10 {
+		t.Errorf("Expected trace sizes tracked, got %d bytes, peak %d", target.Bytes(), res.PeakBytes)
+	}
+}
+
+func TestReplaySpeedAndCancel(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	trace := []TraceEvent{
+		{Time: at, Op: TracePut, Key: "a", Size: 1},
+		{Time: at.Add(time.Second), Op: TraceGet, Key: "a", Size: 1},
+	}
+	c, _ := NewBoundedCache("lru", 10)
+	start := time.Now()
+	Replay(context.Background(), c, trace, ReplayOptions{Speed: 20})
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected the trace's second replayed in about 50ms, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := Replay(ctx, c, trace, ReplayOptions{}); res.Events != 0 {
+		t.Errorf("Expected nothing replayed after cancel, got %+v", res)
+	}
+}
@@ -0,0 +1,11 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/minio/enterprise/internal/lincheck"
+)
+
+func TestLockFreeRingBufferLinearizable(t *testing.T) {
+	lincheck.TestQueue(t, func(capacity int) lincheck.Queue { return newLockFreeRingBuffer(capacity) })
+}
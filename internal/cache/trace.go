@@ -0,0 +1,125 @@
+// internal/cache/trace.go
+// Access traces: writes, reads and deletes recorded as JSON lines, with
+// keys and tenants optionally anonymized, for offline placement simulation
+// and cache replay
+package cache
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trace operations
+const (
+	TracePut    = "put"
+	TraceGet    = "get"
+	TraceDelete = "delete"
+)
+
+// TraceEvent is one recorded write, read or delete
+type TraceEvent struct {
+	Time        time.Time `json:"time"`
+	Op          string    `json:"op"`
+	TenantID    string    `json:"tenant_id"`
+	TenantClass string    `json:"tenant_class,omitempty"`
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+}
+
+// TraceAnonymizer replaces tenant IDs and keys with keyed hashes. Each
+// "/"-separated key segment is hashed on its own, so objects sharing a
+// prefix still share one after anonymizing and prefix-based policies
+// behave as they would on the real keys.
+type TraceAnonymizer struct {
+	secret []byte
+}
+
+// NewTraceAnonymizer hashes with secret, or a random one if it is empty;
+// traces anonymized with the same secret can be concatenated
+func NewTraceAnonymizer(secret []byte) (*TraceAnonymizer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate trace secret: %w", err)
+		}
+	}
+	return &TraceAnonymizer{secret: secret}, nil
+}
+
+// Anonymize returns ev with its tenant ID and key hashed. The tenant class,
+// size, op and time are kept.
+func (a *TraceAnonymizer) Anonymize(ev TraceEvent) TraceEvent {
+	ev.TenantID = a.hash(ev.TenantID)
+	segments := strings.Split(ev.Key, "/")
+	for i, seg := range segments {
+		segments[i] = a.hash(seg)
+	}
+	ev.Key = strings.Join(segments, "/")
+	return ev
+}
+
+// hash is the first 8 bytes of HMAC-SHA256(s) in hex; empty stays empty so
+// a trailing "/" survives
+func (a *TraceAnonymizer) hash(s string) string {
+	if s == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// TraceWriter appends events to w as JSON lines; it is safe for concurrent use
+type TraceWriter struct {
+	mu   sync.Mutex
+	enc  *json.Encoder
+	anon *TraceAnonymizer
+}
+
+// NewTraceWriter records to w, anonymizing every event with anon unless it
+// is nil
+func NewTraceWriter(w io.Writer, anon *TraceAnonymizer) *TraceWriter {
+	return &TraceWriter{enc: json.NewEncoder(w), anon: anon}
+}
+
+// Record appends ev; write errors are ignored so tracing never fails a request
+func (t *TraceWriter) Record(ev TraceEvent) {
+	if t.anon != nil {
+		ev = t.anon.Anonymize(ev)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enc.Encode(ev)
+}
+
+// ReadTrace parses JSON-lines events
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	var events []TraceEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var ev TraceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch ev.Op {
+		case TracePut, TraceGet, TraceDelete:
+		default:
+			return nil, fmt.Errorf("line %d: unknown op %q", line, ev.Op)
+		}
+		events = append(events, ev)
+	}
+	return events, scanner.Err()
+}
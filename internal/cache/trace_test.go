@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTraceWriter(&buf, nil)
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	w.Record(TraceEvent{Time: at, Op: TracePut, TenantID: "t1", TenantClass: "free", Key: "a", Size: 3})
+	w.Record(TraceEvent{Time: at, Op: TraceGet, TenantID: "t1", Key: "a", Size: 3})
+	w.Record(TraceEvent{Time: at, Op: TraceDelete, TenantID: "t1", Key: "a", Size: 3})
+
+	events, err := ReadTrace(&buf)
+	if err != nil || len(events) != 3 || events[0].TenantClass != "free" || !events[1].Time.Equal(at) || events[2].Op != TraceDelete {
+		t.Fatalf("Got %+v, %v", events, err)
+	}
+
+	if _, err := ReadTrace(strings.NewReader(`{"op":"list"}`)); err == nil {
+		t.Error("Expected error for an unknown op")
+	}
+}
+
+func TestTraceAnonymizer(t *testing.T) {
+	anon, err := NewTraceAnonymizer([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := TraceEvent{Op: TraceGet, TenantID: "acme", TenantClass: "enterprise", Key: "reports/2026/q1.csv", Size: 42}
+
+	a := anon.Anonymize(ev)
+	if strings.Contains(a.Key, "reports") || a.TenantID == "acme" {
+		t.Fatalf("Expected tenant and key hashed, got %+v", a)
+	}
+	if a.Size != 42 || a.TenantClass != "enterprise" || strings.Count(a.Key, "/") != 2 {
+		t.Errorf("Expected size, class and key structure kept, got %+v", a)
+	}
+
+	// Siblings share their hashed prefix, and the same secret hashes the same
+	ev.Key = "reports/2026/q2.csv"
+	b := anon.Anonymize(ev)
+	if b.Key[:strings.LastIndex(b.Key, "/")] != a.Key[:strings.LastIndex(a.Key, "/")] || b.Key == a.Key {
+		t.Errorf("Expected a shared prefix and distinct names: %s, %s", a.Key, b.Key)
+	}
+	again, _ := NewTraceAnonymizer([]byte("secret"))
+	if again.Anonymize(ev) != b {
+		t.Error("Expected the same secret to hash identically")
+	}
+	other, _ := NewTraceAnonymizer(nil)
+	if other.Anonymize(ev).Key == b.Key {
+		t.Error("Expected a random secret to hash differently")
+	}
+
+	if got := anon.Anonymize(TraceEvent{Key: "logs/"}).Key; !strings.HasSuffix(got, "/") {
+		t.Errorf("Expected a trailing slash kept, got %q", got)
+	}
+}
+
+func TestTraceWriterAnonymizes(t *testing.T) {
+	var buf bytes.Buffer
+	anon, _ := NewTraceAnonymizer(nil)
+	NewTraceWriter(&buf, anon).Record(TraceEvent{Op: TracePut, TenantID: "acme", Key: "secret/plan.doc", Size: 1})
+	if strings.Contains(buf.String(), "acme") || strings.Contains(buf.String(), "plan") {
+		t.Errorf("Expected nothing identifying in the trace: %s", buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+// internal/encryption/envelope.go
+// Envelope re-wrap of tenant data keys at region boundaries. Sealed object
+// bytes replicate unchanged; the data key travels wrapped under each
+// destination region's key-encryption key (KEK) and bound to an encryption
+// context, so a replica only opens in the region, tenant and object it was
+// shipped for.
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrContextMismatch is returned when an envelope was issued for a different
+// tenant, object or region than the destination expects
+var ErrContextMismatch = errors.New("encryption context mismatch")
+
+// maxReplicaHeader bounds the envelope header of a replica payload
+const maxReplicaHeader = 1 << 20
+
+// Context binds a wrapped data key to where it may be used
+type Context struct {
+	TenantID string `json:"tenant_id"`
+	Key      string `json:"key"`
+	Region   string `json:"region"`
+}
+
+// aad is the canonical, unambiguous encoding authenticated with the wrap
+func (c Context) aad() []byte {
+	var buf bytes.Buffer
+	for _, field := range []string{c.TenantID, c.Key, c.Region} {
+		binary.Write(&buf, binary.BigEndian, uint32(len(field)))
+		buf.WriteString(field)
+	}
+	return buf.Bytes()
+}
+
+// Envelope is a data key wrapped for one region
+type Envelope struct {
+	Context    Context `json:"context"`
+	KEKID      string  `json:"kek_id"` // Fingerprint of the wrapping KEK
+	WrappedKey []byte  `json:"wrapped_key"`
+}
+
+// Keyring holds the KEK of each replication region
+type Keyring struct {
+	keks map[string][]byte
+}
+
+// NewKeyring creates a keyring from region -> KEK, rejecting malformed keys
+func NewKeyring(keks map[string][]byte) (*Keyring, error) {
+	kr := &Keyring{keks: make(map[string][]byte, len(keks))}
+	for region, kek := range keks {
+		if len(kek) != KeySize {
+			return nil, fmt.Errorf("invalid KEK length %d for region %s", len(kek), region)
+		}
+		kr.keks[region] = append([]byte(nil), kek...)
+	}
+	return kr, nil
+}
+
+// KEK returns a region's key-encryption key
+func (kr *Keyring) KEK(region string) ([]byte, bool) {
+	kek, ok := kr.keks[region]
+	return kek, ok
+}
+
+// Regions returns the regions with a KEK, sorted
+func (kr *Keyring) Regions() []string {
+	regions := make([]string, 0, len(kr.keks))
+	for region := range kr.keks {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// WrapKey seals dataKey under kek, bound to ctx
+func WrapKey(kek, dataKey []byte, ctx Context) (*Envelope, error) {
+	wrapped, err := Seal(kek, dataKey, ctx.aad())
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{Context: ctx, KEKID: Fingerprint(kek), WrappedKey: wrapped}, nil
+}
+
+// UnwrapKey validates that env was issued for expected, then recovers the
+// data key with kek
+func UnwrapKey(kek []byte, env *Envelope, expected Context) ([]byte, error) {
+	if env.Context != expected {
+		return nil, fmt.Errorf("%w: envelope for %s/%s in %s, expected %s/%s in %s", ErrContextMismatch,
+			env.Context.TenantID, env.Context.Key, env.Context.Region,
+			expected.TenantID, expected.Key, expected.Region)
+	}
+	if env.KEKID != Fingerprint(kek) {
+		return nil, fmt.Errorf("envelope wrapped under KEK %s, have %s", env.KEKID, Fingerprint(kek))
+	}
+	return Open(kek, env.WrappedKey, expected.aad())
+}
+
+// EncodeReplica frames sealed object bytes with their per-region envelopes:
+// a 4-byte big-endian header length, the JSON envelopes, then the data
+func EncodeReplica(envelopes []Envelope, sealed []byte) ([]byte, error) {
+	header, err := json.Marshal(envelopes)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 4, 4+len(header)+len(sealed))
+	binary.BigEndian.PutUint32(out, uint32(len(header)))
+	out = append(out, header...)
+	return append(out, sealed...), nil
+}
+
+// OpenReplica is run by the destination region: it finds the envelope for
+// expected.Region, validates its context, unwraps the data key with the
+// region's KEK and returns the plaintext
+func OpenReplica(kek, payload []byte, expected Context) ([]byte, error) {
+	if len(payload) < 4 {
+		return nil, fmt.Errorf("replica too short")
+	}
+	n := binary.BigEndian.Uint32(payload)
+	if n > maxReplicaHeader || int(n) > len(payload)-4 {
+		return nil, fmt.Errorf("invalid replica header length %d", n)
+	}
+
+	var envelopes []Envelope
+	if err := json.Unmarshal(payload[4:4+n], &envelopes); err != nil {
+		return nil, fmt.Errorf("invalid replica header: %w", err)
+	}
+	sealed := payload[4+n:]
+
+	for i := range envelopes {
+		if envelopes[i].Context.Region != expected.Region {
+			continue
+		}
+		dataKey, err := UnwrapKey(kek, &envelopes[i], expected)
+		if err != nil {
+			return nil, err
+		}
+		return Open(dataKey, sealed, ObjectAAD(expected.TenantID, expected.Key))
+	}
+	return nil, fmt.Errorf("%w: no envelope for region %s", ErrContextMismatch, expected.Region)
+}
@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReplicaEnvelope(t *testing.T) {
+	dataKey, _ := NewKey()
+	west, _ := NewKey()
+	east, _ := NewKey()
+
+	plaintext := []byte("personal data")
+	sealed, err := Seal(dataKey, plaintext, ObjectAAD("t1", "a"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	var envelopes []Envelope
+	for region, kek := range map[string][]byte{"us-west-2": west, "us-east-1": east} {
+		env, err := WrapKey(kek, dataKey, Context{TenantID: "t1", Key: "a", Region: region})
+		if err != nil {
+			t.Fatalf("WrapKey() error = %v", err)
+		}
+		envelopes = append(envelopes, *env)
+	}
+	payload, err := EncodeReplica(envelopes, sealed)
+	if err != nil {
+		t.Fatalf("EncodeReplica() error = %v", err)
+	}
+
+	got, err := OpenReplica(west, payload, Context{TenantID: "t1", Key: "a", Region: "us-west-2"})
+	if err != nil {
+		t.Fatalf("OpenReplica() error = %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("OpenReplica() = %q, want %q", got, plaintext)
+	}
+
+	// The same payload must not open under another object, tenant or region
+	for _, ctx := range []Context{
+		{TenantID: "t1", Key: "b", Region: "us-west-2"},
+		{TenantID: "t2", Key: "a", Region: "us-west-2"},
+		{TenantID: "t1", Key: "a", Region: "eu-west-1"},
+	} {
+		if _, err := OpenReplica(west, payload, ctx); !errors.Is(err, ErrContextMismatch) {
+			t.Errorf("OpenReplica(%+v) error = %v, want ErrContextMismatch", ctx, err)
+		}
+	}
+
+	// Nor with another region's KEK
+	if _, err := OpenReplica(east, payload, Context{TenantID: "t1", Key: "a", Region: "us-west-2"}); err == nil {
+		t.Error("OpenReplica() succeeded with the wrong region KEK")
+	}
+}
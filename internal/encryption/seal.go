@@ -0,0 +1,88 @@
+// internal/encryption/seal.go
+// AES-256-GCM sealing of object data under per-tenant data keys
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// KeySize is the data key length in bytes (AES-256)
+	KeySize = 32
+
+	nonceSize = 12
+	tagSize   = 16
+
+	// Overhead is the number of bytes Seal adds to the plaintext
+	Overhead = nonceSize + tagSize
+)
+
+// NewKey returns a random data key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// Fingerprint identifies a key without revealing it
+func Fingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ObjectAAD is the additional data binding an object's sealed bytes to its
+// tenant and key
+func ObjectAAD(tenantID, key string) []byte {
+	return []byte(tenantID + "/" + key)
+}
+
+// Seal encrypts plaintext, binding it to aad (e.g. tenant/key) so sealed
+// data cannot be replayed under another object name
+func Seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, nonceSize, nonceSize+len(plaintext)+tagSize)
+	if _, err := rand.Read(out); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(out, out[:nonceSize], plaintext, aad), nil
+}
+
+// Open decrypts data produced by Seal with the same key and aad
+func Open(key, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < Overhead {
+		return nil, fmt.Errorf("sealed data too short")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid data key length %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
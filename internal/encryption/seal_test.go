@@ -0,0 +1,42 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	plaintext := []byte("personal data")
+	sealed, err := Seal(key, plaintext, []byte("t1/a"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if len(sealed) != len(plaintext)+Overhead {
+		t.Errorf("Seal() length = %d, want %d", len(sealed), len(plaintext)+Overhead)
+	}
+
+	opened, err := Open(key, sealed, []byte("t1/a"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open() = %q, want %q", opened, plaintext)
+	}
+
+	if _, err := Open(key, sealed, []byte("t1/b")); err == nil {
+		t.Error("Open() succeeded with mismatched object name")
+	}
+
+	// A shredded (zeroed) key must not decrypt
+	for i := range key {
+		key[i] = 0
+	}
+	if _, err := Open(key, sealed, []byte("t1/a")); err == nil {
+		t.Error("Open() succeeded after key was destroyed")
+	}
+}
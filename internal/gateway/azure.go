@@ -0,0 +1,293 @@
+// internal/gateway/azure.go
+// Azure Blob Storage backend: block blobs in one container, authorized
+// with the storage account's Shared Key
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureVersion is the Blob service REST API version requests are made against
+const azureVersion = "2021-08-06"
+
+type azureBackend struct {
+	cfg     Config
+	account string
+	key     []byte
+	base    string // Endpoint plus container path, no trailing slash
+	client  *http.Client
+}
+
+func newAzureBackend(cfg Config) (Backend, error) {
+	if cfg.AccessKey == "" {
+		return nil, fmt.Errorf("access_key must name the storage account")
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("secret_key must be the base64 account key: %w", err)
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + cfg.AccessKey + ".blob.core.windows.net"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+
+	// Emulators address the account in the path (http://host/account)
+	return &azureBackend{
+		cfg:     cfg,
+		account: cfg.AccessKey,
+		key:     key,
+		base:    u.Scheme + "://" + u.Host + strings.TrimSuffix(u.EscapedPath(), "/") + "/" + s3Escape(cfg.Bucket, false),
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (b *azureBackend) Get(ctx context.Context, key string) ([]byte, *ObjectInfo, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil, ErrNotFound
+	default:
+		return nil, nil, azureError(resp, "get", key)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("azure get %s: %w", key, err)
+	}
+	info := &ObjectInfo{Key: key, Size: int64(len(data)), ETag: strings.Trim(resp.Header.Get("ETag"), `"`)}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return data, info, nil
+}
+
+// Put uploads a block blob in one request, conditional on the blob's ETag
+// or on its absence
+func (b *azureBackend) Put(ctx context.Context, key string, data []byte, cond Condition) (*ObjectInfo, error) {
+	header := http.Header{"X-Ms-Blob-Type": {"BlockBlob"}}
+	if cond.IfMatch != "" {
+		header.Set("If-Match", `"`+cond.IfMatch+`"`)
+	}
+	if cond.IfAbsent {
+		header.Set("If-None-Match", "*")
+	}
+
+	resp, err := b.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		// If-None-Match: * fails with 409 BlobAlreadyExists
+		return nil, ErrPreconditionFailed
+	default:
+		return nil, azureError(resp, "put", key)
+	}
+
+	info := &ObjectInfo{Key: key, Size: int64(len(data)), ETag: strings.Trim(resp.Header.Get("ETag"), `"`)}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info, nil
+}
+
+func (b *azureBackend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusNotFound:
+		return nil
+	default:
+		return azureError(resp, "delete", key)
+	}
+}
+
+// azureListResult is the subset of a List Blobs response we use
+type azureListResult struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ETag          string `xml:"Etag"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List uses List Blobs, whose NextMarker is an opaque continuation; a
+// listing is truncated exactly when one is returned
+func (b *azureBackend) List(ctx context.Context, prefix, token string, max int) (*ListPage, error) {
+	query := url.Values{
+		"restype":    {"container"},
+		"comp":       {"list"},
+		"maxresults": {strconv.Itoa(max)},
+	}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if token != "" {
+		query.Set("marker", token)
+	}
+
+	resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, azureError(resp, "list", prefix)
+	}
+	page, err := decodeAzureList(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("azure list %s: invalid response: %w", prefix, err)
+	}
+	return page, nil
+}
+
+// decodeAzureList decodes a List Blobs response body
+func decodeAzureList(r io.Reader) (*ListPage, error) {
+	var result azureListResult
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	page := &ListPage{
+		Objects:   make([]ObjectInfo, 0, len(result.Blobs)),
+		Truncated: result.NextMarker != "",
+		NextToken: result.NextMarker,
+	}
+	for _, blob := range result.Blobs {
+		modified, _ := http.ParseTime(blob.Properties.LastModified)
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:          blob.Name,
+			Size:         blob.Properties.ContentLength,
+			ETag:         strings.Trim(blob.Properties.ETag, `"`),
+			LastModified: modified,
+		})
+	}
+	return page, nil
+}
+
+// do sends a Shared Key request for key (the container itself when empty)
+// under the Azure retry policy
+func (b *azureBackend) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	target := b.base
+	if key != "" {
+		target += "/" + s3Escape(key, true)
+	}
+	if len(query) > 0 {
+		target += "?" + s3CanonicalQuery(query)
+	}
+
+	return azureRetry.send(ctx, b.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = int64(len(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+		req.Header.Set("X-Ms-Version", azureVersion)
+		b.sign(req)
+		return req, nil
+	}, func(resp *http.Response) error {
+		return azureError(resp, strings.ToLower(method), key)
+	})
+}
+
+// sign adds the Shared Key Authorization header
+func (b *azureBackend) sign(req *http.Request) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	resource := "/" + b.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, strings.ToLower(name))
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		resource += "\n" + name + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		resource,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+b.account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// azureError turns a Blob service error response into an error carrying its code
+func azureError(resp *http.Response, op, key string) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("azure %s %s: %s: %s", op, key, body.Code, strings.TrimSpace(body.Message))
+	}
+	if code := resp.Header.Get("X-Ms-Error-Code"); code != "" {
+		return fmt.Errorf("azure %s %s: %s", op, key, code)
+	}
+	return fmt.Errorf("azure %s %s: status %d", op, key, resp.StatusCode)
+}
@@ -0,0 +1,123 @@
+// internal/gateway/backend.go
+// Persistent object store backends a tenant's data can live in when the
+// server runs as a caching gateway in front of storage the tenant owns
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Providers
+const (
+	ProviderS3    = "s3"
+	ProviderAzure = "azure"
+	ProviderGCS   = "gcs"
+)
+
+var (
+	// ErrNotFound is returned when the backend has no object under a key
+	ErrNotFound = errors.New("object not found in backend")
+
+	// ErrPreconditionFailed is returned when a conditional Put does not hold
+	ErrPreconditionFailed = errors.New("backend precondition failed")
+)
+
+// ObjectInfo describes an object held by a backend
+type ObjectInfo struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+
+	// ETag identifies this version of the object for Condition.IfMatch
+	// (the generation number on GCS)
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ListPage is one page of a backend listing
+type ListPage struct {
+	Objects   []ObjectInfo `json:"objects"`
+	Truncated bool         `json:"is_truncated"`
+
+	// NextToken resumes the listing; it is opaque and provider-specific
+	NextToken string `json:"next_token,omitempty"`
+}
+
+// Condition makes a Put conditional on the object currently in the backend
+type Condition struct {
+	IfMatch  string // Current ETag must equal this
+	IfAbsent bool   // No object may exist under the key
+}
+
+// Backend is a persistent store addressed by object key
+type Backend interface {
+	// Get returns the object's bytes, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, *ObjectInfo, error)
+
+	// Put stores data under key, replacing any existing object, or returns
+	// ErrPreconditionFailed if cond does not hold
+	Put(ctx context.Context, key string, data []byte, cond Condition) (*ObjectInfo, error)
+
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+
+	// List returns up to max objects under prefix in key order, starting
+	// from the beginning or from the NextToken of a previous page
+	List(ctx context.Context, prefix, token string, max int) (*ListPage, error)
+}
+
+// Config selects and authenticates a tenant's backend bucket
+type Config struct {
+	Provider string `json:"provider"`           // "s3", "azure" or "gcs"
+	Endpoint string `json:"endpoint,omitempty"` // Defaults to the provider's public endpoint
+	Bucket   string `json:"bucket"`             // Container on Azure
+	Region   string `json:"region,omitempty"`
+
+	// Prefix is prepended to every key, confining the tenant to part of the bucket
+	Prefix string `json:"prefix,omitempty"`
+
+	// S3 access and secret key; Azure storage account name and base64
+	// account key
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+
+	// ServiceAccount is a GCS service account key file (JSON). Requests go
+	// unauthenticated without one, as emulators expect.
+	ServiceAccount string `json:"service_account,omitempty"`
+
+	// WriteBack acknowledges writes once cached locally and uploads them to
+	// the backend asynchronously; otherwise writes pass through synchronously
+	WriteBack bool `json:"write_back,omitempty"`
+
+	Timeout time.Duration `json:"-"` // Per request, default 30s
+}
+
+// Redacted returns the config with credentials removed
+func (c Config) Redacted() Config {
+	c.SecretKey = ""
+	c.ServiceAccount = ""
+	return c
+}
+
+// NewBackend creates the backend for cfg.Provider
+func NewBackend(cfg Config) (Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	switch cfg.Provider {
+	case ProviderS3:
+		return newS3Backend(cfg)
+	case ProviderAzure:
+		return newAzureBackend(cfg)
+	case ProviderGCS:
+		return newGCSBackend(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", cfg.Provider)
+	}
+}
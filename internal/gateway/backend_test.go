@@ -0,0 +1,407 @@
+package gateway
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStore is the bucket behind every fake provider. Each write gets a new
+// generation, which the fakes expose as their ETag or generation.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string]memObject
+	gen     int64
+	fail    int // Requests to answer with 503 before succeeding
+}
+
+type memObject struct {
+	data []byte
+	gen  int64
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string]memObject)}
+}
+
+// failing consumes one injected failure, if any
+func (m *memStore) failing(w http.ResponseWriter) bool {
+	if m.fail == 0 {
+		return false
+	}
+	m.fail--
+	http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	return true
+}
+
+// put stores data unless the precondition (ifGen, or absence) fails
+func (m *memStore) put(key string, data []byte, ifGen string, ifAbsent bool) (memObject, bool) {
+	cur, exists := m.objects[key]
+	if ifAbsent && exists {
+		return memObject{}, false
+	}
+	if ifGen != "" && (!exists || strconv.FormatInt(cur.gen, 10) != ifGen) {
+		return memObject{}, false
+	}
+	m.gen++
+	obj := memObject{data: data, gen: m.gen}
+	m.objects[key] = obj
+	return obj, true
+}
+
+// page returns up to max keys under prefix sorting after start
+func (m *memStore) page(prefix, start string, max int) (keys []string, truncated bool) {
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) && key > start {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > max {
+		return keys[:max], true
+	}
+	return keys, false
+}
+
+// fakeS3 serves path-style object requests and ListObjectsV2 for "bucket"
+func fakeS3(t *testing.T, m *memStore) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("x-amz-date") == "" {
+			http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+			return
+		}
+		if m.failing(w) {
+			return
+		}
+
+		key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+		switch {
+		case r.Method == http.MethodGet && key == "":
+			q := r.URL.Query()
+			max, _ := strconv.Atoi(q.Get("max-keys"))
+			keys, truncated := m.page(q.Get("prefix"), q.Get("continuation-token"), max)
+
+			var result s3ListResult
+			result.IsTruncated = truncated
+			if truncated {
+				result.NextContinuationToken = keys[len(keys)-1]
+			}
+			for _, k := range keys {
+				result.Contents = append(result.Contents, s3ListEntry{
+					Key:  k,
+					Size: int64(len(m.objects[k].data)),
+					ETag: fmt.Sprintf(`"%d"`, m.objects[k].gen),
+				})
+			}
+			xml.NewEncoder(w).Encode(struct {
+				XMLName xml.Name `xml:"ListBucketResult"`
+				s3ListResult
+			}{s3ListResult: result})
+		case r.Method == http.MethodGet:
+			obj, ok := m.objects[key]
+			if !ok {
+				http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, obj.gen))
+			w.Write(obj.data)
+		case r.Method == http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			obj, ok := m.put(key, data, strings.Trim(r.Header.Get("If-Match"), `"`), r.Header.Get("If-None-Match") == "*")
+			if !ok {
+				http.Error(w, "<Error><Code>PreconditionFailed</Code></Error>", http.StatusPreconditionFailed)
+				return
+			}
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, obj.gen))
+		case r.Method == http.MethodDelete:
+			delete(m.objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// fakeAzure serves one container emulator-style, at /account/container
+func fakeAzure(t *testing.T, m *memStore) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey acct:") || r.Header.Get("X-Ms-Version") == "" {
+			w.Header().Set("X-Ms-Error-Code", "AuthenticationFailed")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if m.failing(w) {
+			return
+		}
+
+		key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/acct/container"), "/")
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet && q.Get("comp") == "list":
+			max, _ := strconv.Atoi(q.Get("maxresults"))
+			keys, truncated := m.page(q.Get("prefix"), strings.TrimPrefix(q.Get("marker"), "after:"), max)
+			fmt.Fprint(w, "<EnumerationResults><Blobs>")
+			for _, k := range keys {
+				fmt.Fprintf(w, "<Blob><Name>%s</Name><Properties><Etag>\"%d\"</Etag><Content-Length>%d</Content-Length></Properties></Blob>",
+					k, m.objects[k].gen, len(m.objects[k].data))
+			}
+			fmt.Fprint(w, "</Blobs><NextMarker>")
+			if truncated {
+				fmt.Fprint(w, "after:"+keys[len(keys)-1])
+			}
+			fmt.Fprint(w, "</NextMarker></EnumerationResults>")
+		case r.Method == http.MethodGet:
+			obj, ok := m.objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, obj.gen))
+			w.Write(obj.data)
+		case r.Method == http.MethodPut:
+			if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			ifAbsent := r.Header.Get("If-None-Match") == "*"
+			obj, ok := m.put(key, data, strings.Trim(r.Header.Get("If-Match"), `"`), ifAbsent)
+			if !ok {
+				status := http.StatusPreconditionFailed
+				if ifAbsent {
+					status = http.StatusConflict
+				}
+				w.WriteHeader(status)
+				return
+			}
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, obj.gen))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete:
+			if _, ok := m.objects[key]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(m.objects, key)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/acct"
+}
+
+// fakeGCS serves the JSON API for "bucket" and a token endpoint that
+// checks service account assertions against pub
+func fakeGCS(t *testing.T, m *memStore, pub *rsa.PublicKey) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			parts := strings.Split(r.PostForm.Get("assertion"), ".")
+			if len(parts) != 3 {
+				http.Error(w, "invalid_grant", http.StatusBadRequest)
+				return
+			}
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+				http.Error(w, "invalid_grant", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if m.failing(w) {
+			return
+		}
+
+		resource := func(key string) map[string]string {
+			obj := m.objects[key]
+			return map[string]string{"name": key, "size": strconv.Itoa(len(obj.data)), "generation": strconv.FormatInt(obj.gen, 10)}
+		}
+		q := r.URL.Query()
+		path := r.URL.EscapedPath()
+		switch {
+		case r.Method == http.MethodPost && path == "/upload/storage/v1/b/bucket/o":
+			data, _ := io.ReadAll(r.Body)
+			ifGen := q.Get("ifGenerationMatch")
+			ifAbsent := ifGen == "0"
+			if ifAbsent {
+				ifGen = ""
+			}
+			if _, ok := m.put(q.Get("name"), data, ifGen, ifAbsent); !ok {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			json.NewEncoder(w).Encode(resource(q.Get("name")))
+		case r.Method == http.MethodGet && path == "/storage/v1/b/bucket/o":
+			max, _ := strconv.Atoi(q.Get("maxResults"))
+			keys, truncated := m.page(q.Get("prefix"), q.Get("pageToken"), max)
+			result := map[string]interface{}{}
+			items := []map[string]string{}
+			for _, k := range keys {
+				items = append(items, resource(k))
+			}
+			result["items"] = items
+			if truncated {
+				result["nextPageToken"] = keys[len(keys)-1]
+			}
+			json.NewEncoder(w).Encode(result)
+		case strings.HasPrefix(path, "/storage/v1/b/bucket/o/"):
+			key, _ := url.PathUnescape(strings.TrimPrefix(path, "/storage/v1/b/bucket/o/"))
+			obj, ok := m.objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				delete(m.objects, key)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("X-Goog-Generation", strconv.FormatInt(obj.gen, 10))
+			w.Write(obj.data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// exerciseBackend runs the Backend contract against b, whose bucket is m
+func exerciseBackend(t *testing.T, b Backend, m *memStore) {
+	ctx := context.Background()
+
+	if _, _, err := b.Get(ctx, "a/1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// A transient failure is retried
+	m.fail = 1
+	first, err := b.Put(ctx, "a/1", []byte("v1"), Condition{})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, err := b.Put(ctx, "a/1", []byte("x"), Condition{IfAbsent: true}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("Put(IfAbsent) over existing error = %v, want ErrPreconditionFailed", err)
+	}
+	second, err := b.Put(ctx, "a/1", []byte("v2"), Condition{IfMatch: first.ETag})
+	if err != nil {
+		t.Fatalf("Put(IfMatch current) error = %v", err)
+	}
+	if _, err := b.Put(ctx, "a/1", []byte("x"), Condition{IfMatch: first.ETag}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("Put(IfMatch stale) error = %v, want ErrPreconditionFailed", err)
+	}
+
+	data, info, err := b.Get(ctx, "a/1")
+	if err != nil || string(data) != "v2" || info.ETag != second.ETag {
+		t.Fatalf("Get() = %q, %+v, %v; want v2 with ETag %q", data, info, err, second.ETag)
+	}
+
+	for _, key := range []string{"a/2", "a/3 with space", "b/1"} {
+		if _, err := b.Put(ctx, key, []byte(key), Condition{IfAbsent: true}); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	var keys []string
+	token, pages := "", 0
+	for {
+		page, err := b.List(ctx, "a/", token, 2)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		pages++
+		for _, obj := range page.Objects {
+			keys = append(keys, obj.Key)
+		}
+		if !page.Truncated {
+			break
+		}
+		token = page.NextToken
+	}
+	if want := "a/1,a/2,a/3 with space"; strings.Join(keys, ",") != want || pages != 2 {
+		t.Fatalf("List() = %v in %d pages, want %s in 2", keys, pages, want)
+	}
+
+	if err := b.Delete(ctx, "a/2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := b.Delete(ctx, "a/2"); err != nil {
+		t.Fatalf("Delete(missing) error = %v", err)
+	}
+	if _, _, err := b.Get(ctx, "a/2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	m := newMemStore()
+	b, err := NewBackend(Config{Provider: ProviderS3, Endpoint: fakeS3(t, m), Bucket: "bucket", AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewBackend() error = %v", err)
+	}
+	exerciseBackend(t, b, m)
+}
+
+func TestAzureBackend(t *testing.T) {
+	m := newMemStore()
+	b, err := NewBackend(Config{Provider: ProviderAzure, Endpoint: fakeAzure(t, m), Bucket: "container",
+		AccessKey: "acct", SecretKey: base64.StdEncoding.EncodeToString([]byte("account-key"))})
+	if err != nil {
+		t.Fatalf("NewBackend() error = %v", err)
+	}
+	exerciseBackend(t, b, m)
+}
+
+func TestGCSBackend(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	m := newMemStore()
+	endpoint := fakeGCS(t, m, &key.PublicKey)
+	serviceAccount, _ := json.Marshal(map[string]string{
+		"client_email": "gateway@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    endpoint + "/token",
+	})
+
+	b, err := NewBackend(Config{Provider: ProviderGCS, Endpoint: endpoint, Bucket: "bucket", ServiceAccount: string(serviceAccount)})
+	if err != nil {
+		t.Fatalf("NewBackend() error = %v", err)
+	}
+	exerciseBackend(t, b, m)
+}
@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func FuzzDecodeS3List(f *testing.F) {
+	f.Add([]byte(`<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>abc</NextContinuationToken>` +
+		`<Contents><Key>a/b</Key><Size>3</Size><ETag>"e1"</ETag><LastModified>2024-01-02T03:04:05.000Z</LastModified></Contents></ListBucketResult>`))
+	f.Add([]byte(`<ListBucketResult><Contents><Size>-1</Size><LastModified>yesterday</LastModified></Contents>`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		page, err := decodeS3List(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		for _, obj := range page.Objects {
+			if strings.HasPrefix(obj.ETag, `"`) || strings.HasSuffix(obj.ETag, `"`) {
+				t.Errorf("ETag %q still quoted", obj.ETag)
+			}
+		}
+	})
+}
+
+func FuzzDecodeAzureList(f *testing.F) {
+	f.Add([]byte(`<EnumerationResults><Blobs><Blob><Name>a/b</Name><Properties><Content-Length>3</Content-Length>` +
+		`<Etag>"0x1"</Etag><Last-Modified>Tue, 02 Jan 2024 03:04:05 GMT</Last-Modified></Properties></Blob></Blobs>` +
+		`<NextMarker>m</NextMarker></EnumerationResults>`))
+	f.Add([]byte(`<EnumerationResults><Blobs><Blob><Properties><Content-Length>x</Content-Length></Properties></Blob>`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		page, err := decodeAzureList(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if page.Truncated != (page.NextToken != "") {
+			t.Errorf("Truncated = %v with next token %q", page.Truncated, page.NextToken)
+		}
+	})
+}
+
+func FuzzErrorResponses(f *testing.F) {
+	f.Add(403, []byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+	f.Add(500, []byte(`<Error><Code>`))
+	f.Add(404, []byte{})
+	f.Fuzz(func(t *testing.T, status int, data []byte) {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(data))}
+		if err := s3Error(resp, "get", "k"); err == nil {
+			t.Error("s3Error returned nil")
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if err := azureError(resp, "get", "k"); err == nil {
+			t.Error("azureError returned nil")
+		}
+	})
+}
+
+func FuzzS3Escape(f *testing.F) {
+	f.Add("photos/2024/a b+c.jpg", true)
+	f.Add("~x%2F\xff", false)
+	f.Fuzz(func(t *testing.T, s string, keepSlash bool) {
+		escaped := s3Escape(s, keepSlash)
+		unescaped, err := url.PathUnescape(escaped)
+		if err != nil || unescaped != s {
+			t.Fatalf("s3Escape(%q) = %q, which unescapes to %q, %v", s, escaped, unescaped, err)
+		}
+		if !keepSlash && strings.Contains(escaped, "/") {
+			t.Errorf("s3Escape(%q, false) = %q keeps a slash", s, escaped)
+		}
+	})
+}
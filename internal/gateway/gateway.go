@@ -0,0 +1,247 @@
+// internal/gateway/gateway.go
+// A tenant's gateway onto its own bucket: keys are confined to the
+// configured prefix, reads fetch from the backend, and writes either pass
+// through or are queued and uploaded in the background (write-back)
+package gateway
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// writeBackInterval is how often queued writes are retried after a failure
+const writeBackInterval = 5 * time.Second
+
+// Status reports a gateway's configuration and activity
+type Status struct {
+	Config            Config `json:"config"`
+	Pending           int    `json:"pending_write_backs"`
+	Fetches           uint64 `json:"fetches"`
+	WriteThroughs     uint64 `json:"write_throughs"`
+	WriteBacks        uint64 `json:"write_backs"`
+	WriteBackFailures uint64 `json:"write_back_failures"`
+	LastError         string `json:"last_error,omitempty"`
+}
+
+type pendingWrite struct {
+	data []byte
+	seq  uint64
+}
+
+// Gateway fronts one tenant's backend bucket
+type Gateway struct {
+	config  Config
+	backend Backend
+
+	mu      sync.Mutex
+	pending map[string]pendingWrite // Write-back queue, latest write per key
+	seq     uint64
+	lastErr string
+	cancel  context.CancelFunc
+	wake    chan struct{}
+
+	fetches           atomic.Uint64
+	writeThroughs     atomic.Uint64
+	writeBacks        atomic.Uint64
+	writeBackFailures atomic.Uint64
+}
+
+// New creates a gateway for cfg
+func New(cfg Config) (*Gateway, error) {
+	backend, err := NewBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithBackend(cfg, backend), nil
+}
+
+// NewWithBackend creates a gateway over an already constructed backend
+func NewWithBackend(cfg Config, backend Backend) *Gateway {
+	return &Gateway{
+		config:  cfg,
+		backend: backend,
+		pending: make(map[string]pendingWrite),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Config returns the gateway's configuration without credentials
+func (g *Gateway) Config() Config {
+	return g.config.Redacted()
+}
+
+// Queues reports whether a write under cond is acknowledged before reaching
+// the backend. Conditional writes are always checked synchronously.
+func (g *Gateway) Queues(cond Condition) bool {
+	return g.config.WriteBack && cond == (Condition{})
+}
+
+func (g *Gateway) backendKey(key string) string {
+	return g.config.Prefix + key
+}
+
+// Get fetches key from the backend, preferring a write still queued for it
+func (g *Gateway) Get(ctx context.Context, key string) ([]byte, error) {
+	g.mu.Lock()
+	p, ok := g.pending[key]
+	g.mu.Unlock()
+	if ok {
+		return p.data, nil
+	}
+
+	data, _, err := g.backend.Get(ctx, g.backendKey(key))
+	if err != nil {
+		return nil, err
+	}
+	g.fetches.Add(1)
+	return data, nil
+}
+
+// Put writes key through to the backend, or queues it when Queues(cond)
+func (g *Gateway) Put(ctx context.Context, key string, data []byte, cond Condition) error {
+	if g.Queues(cond) {
+		g.mu.Lock()
+		g.seq++
+		g.pending[key] = pendingWrite{data: data, seq: g.seq}
+		g.mu.Unlock()
+
+		select {
+		case g.wake <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	if _, err := g.backend.Put(ctx, g.backendKey(key), data, cond); err != nil {
+		if err != ErrPreconditionFailed {
+			g.setError(err)
+		}
+		return err
+	}
+	g.writeThroughs.Add(1)
+	return nil
+}
+
+// Delete removes key from the backend, discarding any queued write for it
+func (g *Gateway) Delete(ctx context.Context, key string) error {
+	g.mu.Lock()
+	delete(g.pending, key)
+	g.mu.Unlock()
+
+	if err := g.backend.Delete(ctx, g.backendKey(key)); err != nil {
+		g.setError(err)
+		return err
+	}
+	return nil
+}
+
+// List lists the backend under prefix, with keys relative to the gateway
+// prefix, resuming from a previous page's NextToken
+func (g *Gateway) List(ctx context.Context, prefix, token string, max int) (*ListPage, error) {
+	page, err := g.backend.List(ctx, g.backendKey(prefix), token, max)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Objects {
+		page.Objects[i].Key = strings.TrimPrefix(page.Objects[i].Key, g.config.Prefix)
+	}
+	return page, nil
+}
+
+// Flush uploads every queued write, returning the first failure. Writes that
+// fail stay queued unless superseded or deleted meanwhile.
+func (g *Gateway) Flush(ctx context.Context) error {
+	g.mu.Lock()
+	keys := make([]string, 0, len(g.pending))
+	for key := range g.pending {
+		keys = append(keys, key)
+	}
+	g.mu.Unlock()
+
+	var firstErr error
+	for _, key := range keys {
+		g.mu.Lock()
+		p, ok := g.pending[key]
+		g.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		_, err := g.backend.Put(ctx, g.backendKey(key), p.data, Condition{})
+		if err != nil {
+			g.writeBackFailures.Add(1)
+			g.setError(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		g.writeBacks.Add(1)
+
+		g.mu.Lock()
+		if cur, ok := g.pending[key]; ok && cur.seq == p.seq {
+			delete(g.pending, key)
+		}
+		g.mu.Unlock()
+	}
+	return firstErr
+}
+
+// Start uploads queued writes in the background until Stop
+func (g *Gateway) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g.mu.Lock()
+	g.cancel = cancel
+	g.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(writeBackInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := g.Flush(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Gateway write-back to %s failed: %v", g.config.Bucket, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-g.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop halts background write-back; queued writes are kept for Flush
+func (g *Gateway) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// Status returns the gateway's configuration and counters
+func (g *Gateway) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Status{
+		Config:            g.config.Redacted(),
+		Pending:           len(g.pending),
+		Fetches:           g.fetches.Load(),
+		WriteThroughs:     g.writeThroughs.Load(),
+		WriteBacks:        g.writeBacks.Load(),
+		WriteBackFailures: g.writeBackFailures.Load(),
+		LastError:         g.lastErr,
+	}
+}
+
+func (g *Gateway) setError(err error) {
+	g.mu.Lock()
+	g.lastErr = err.Error()
+	g.mu.Unlock()
+}
@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newTestGateway(t *testing.T, writeBack bool) (*Gateway, *memStore) {
+	m := newMemStore()
+	g, err := New(Config{
+		Provider:  ProviderS3,
+		Endpoint:  fakeS3(t, m),
+		Bucket:    "bucket",
+		Prefix:    "tenant/",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		WriteBack: writeBack,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return g, m
+}
+
+func TestGatewayWriteThrough(t *testing.T) {
+	ctx := context.Background()
+	g, m := newTestGateway(t, false)
+
+	if err := g.Put(ctx, "dir/a b.txt", []byte("hello"), Condition{}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := string(m.objects["tenant/dir/a b.txt"].data); got != "hello" {
+		t.Fatalf("backend object = %q, want the prefixed key to hold %q", got, "hello")
+	}
+	if err := g.Put(ctx, "dir/a b.txt", []byte("x"), Condition{IfAbsent: true}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("Put(IfAbsent) error = %v, want ErrPreconditionFailed", err)
+	}
+
+	data, err := g.Get(ctx, "dir/a b.txt")
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("Get() = %q, %v", data, err)
+	}
+
+	for _, key := range []string{"dir/b", "other"} {
+		if err := g.Put(ctx, key, []byte(key), Condition{}); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+	page, err := g.List(ctx, "dir/", "", 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(page.Objects) != 1 || page.Objects[0].Key != "dir/a b.txt" || !page.Truncated {
+		t.Fatalf("List() = %+v, want [dir/a b.txt] truncated", page)
+	}
+	if page, err = g.List(ctx, "dir/", page.NextToken, 1); err != nil || len(page.Objects) != 1 || page.Objects[0].Key != "dir/b" {
+		t.Fatalf("List(NextToken) = %+v, %v, want [dir/b]", page, err)
+	}
+
+	if s := g.Status(); s.WriteThroughs != 3 || s.Config.SecretKey != "" {
+		t.Fatalf("Status() = %+v", s)
+	}
+}
+
+func TestGatewayWriteBack(t *testing.T) {
+	ctx := context.Background()
+	g, m := newTestGateway(t, true)
+
+	if err := g.Put(ctx, "k", []byte("v1"), Condition{}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := g.Put(ctx, "k", []byte("v2"), Condition{}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := m.objects["tenant/k"]; ok {
+		t.Fatal("write-back Put reached the backend before Flush")
+	}
+	if data, _ := g.Get(ctx, "k"); string(data) != "v2" {
+		t.Fatalf("Get() of queued write = %q, want v2", data)
+	}
+
+	m.fail = s3Retry.attempts
+	if err := g.Flush(ctx); err == nil {
+		t.Fatal("Flush() succeeded against a failing backend")
+	}
+	if s := g.Status(); s.Pending != 1 || s.WriteBackFailures != 1 {
+		t.Fatalf("Status() after failed flush = %+v", s)
+	}
+
+	if err := g.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := string(m.objects["tenant/k"].data); got != "v2" {
+		t.Fatalf("backend object = %q, want v2", got)
+	}
+	if s := g.Status(); s.Pending != 0 || s.WriteBacks != 1 {
+		t.Fatalf("Status() after flush = %+v", s)
+	}
+
+	// Conditional writes are never queued
+	if err := g.Put(ctx, "k", []byte("v3"), Condition{IfAbsent: true}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("Put(IfAbsent) error = %v, want ErrPreconditionFailed", err)
+	}
+}
@@ -0,0 +1,343 @@
+// internal/gateway/gcs.go
+// Google Cloud Storage backend using the JSON API, authorized with OAuth2
+// access tokens minted from a service account key
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsScope      = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsTokenURI   = "https://oauth2.googleapis.com/token"
+	gcsTokenSlack = time.Minute // Refresh access tokens this long before expiry
+)
+
+type gcsBackend struct {
+	cfg      Config
+	endpoint string // scheme://host, no trailing slash
+	client   *http.Client
+	tokens   *gcsTokenSource // nil sends requests unauthenticated
+}
+
+func newGCSBackend(cfg Config) (Backend, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+
+	b := &gcsBackend{
+		cfg:      cfg,
+		endpoint: u.Scheme + "://" + u.Host,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+	if cfg.ServiceAccount != "" {
+		if b.tokens, err = newGCSTokenSource(cfg.ServiceAccount, b.client); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// gcsObject is the subset of a GCS object resource we use. Sizes and
+// generations are int64s encoded as JSON strings.
+type gcsObject struct {
+	Name       string    `json:"name"`
+	Size       string    `json:"size"`
+	Generation string    `json:"generation"`
+	Updated    time.Time `json:"updated"`
+}
+
+func (o *gcsObject) info() ObjectInfo {
+	size, _ := strconv.ParseInt(o.Size, 10, 64)
+	return ObjectInfo{Key: o.Name, Size: size, ETag: o.Generation, LastModified: o.Updated}
+}
+
+func (b *gcsBackend) objectPath(key string) string {
+	return "/storage/v1/b/" + url.PathEscape(b.cfg.Bucket) + "/o/" + url.PathEscape(key)
+}
+
+func (b *gcsBackend) Get(ctx context.Context, key string) ([]byte, *ObjectInfo, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.objectPath(key), url.Values{"alt": {"media"}}, nil, "get", key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil, ErrNotFound
+	default:
+		return nil, nil, gcsError(resp, "get", key)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gcs get %s: %w", key, err)
+	}
+	info := &ObjectInfo{Key: key, Size: int64(len(data)), ETag: resp.Header.Get("X-Goog-Generation")}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return data, info, nil
+}
+
+// Put uses a simple media upload. GCS preconditions are on the object
+// generation; generation 0 means the object must not exist.
+func (b *gcsBackend) Put(ctx context.Context, key string, data []byte, cond Condition) (*ObjectInfo, error) {
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	switch {
+	case cond.IfAbsent:
+		query.Set("ifGenerationMatch", "0")
+	case cond.IfMatch != "":
+		query.Set("ifGenerationMatch", cond.IfMatch)
+	}
+
+	path := "/upload/storage/v1/b/" + url.PathEscape(b.cfg.Bucket) + "/o"
+	resp, err := b.do(ctx, http.MethodPost, path, query, data, "put", key)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusPreconditionFailed:
+		return nil, ErrPreconditionFailed
+	default:
+		return nil, gcsError(resp, "put", key)
+	}
+
+	var obj gcsObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("gcs put %s: invalid response: %w", key, err)
+	}
+	info := obj.info()
+	return &info, nil
+}
+
+func (b *gcsBackend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, b.objectPath(key), nil, nil, "delete", key)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return gcsError(resp, "delete", key)
+	}
+}
+
+// List uses objects.list, resuming from its pageToken
+func (b *gcsBackend) List(ctx context.Context, prefix, token string, max int) (*ListPage, error) {
+	query := url.Values{"maxResults": {strconv.Itoa(max)}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if token != "" {
+		query.Set("pageToken", token)
+	}
+
+	path := "/storage/v1/b/" + url.PathEscape(b.cfg.Bucket) + "/o"
+	resp, err := b.do(ctx, http.MethodGet, path, query, nil, "list", prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, gcsError(resp, "list", prefix)
+	}
+	var result struct {
+		Items         []gcsObject `json:"items"`
+		NextPageToken string      `json:"nextPageToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("gcs list %s: invalid response: %w", prefix, err)
+	}
+
+	page := &ListPage{
+		Objects:   make([]ObjectInfo, 0, len(result.Items)),
+		Truncated: result.NextPageToken != "",
+		NextToken: result.NextPageToken,
+	}
+	for i := range result.Items {
+		page.Objects = append(page.Objects, result.Items[i].info())
+	}
+	return page, nil
+}
+
+// do sends an authorized request under the GCS retry policy
+func (b *gcsBackend) do(ctx context.Context, method, path string, query url.Values, body []byte, op, key string) (*http.Response, error) {
+	target := b.endpoint + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	return gcsRetry.send(ctx, b.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = int64(len(body))
+		if body != nil {
+			req.Header.Set("Content-Type", "application/octet-stream")
+		}
+		if b.tokens != nil {
+			token, err := b.tokens.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	}, func(resp *http.Response) error {
+		return gcsError(resp, op, key)
+	})
+}
+
+// gcsError turns a JSON API error response into an error carrying its message
+func gcsError(resp *http.Response, op, key string) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		return fmt.Errorf("gcs %s %s: status %d: %s", op, key, resp.StatusCode, body.Error.Message)
+	}
+	return fmt.Errorf("gcs %s %s: status %d", op, key, resp.StatusCode)
+}
+
+// gcsTokenSource exchanges a service account's signed JWT assertion for an
+// access token, caching it until shortly before it expires
+type gcsTokenSource struct {
+	email    string
+	tokenURI string
+	key      *rsa.PrivateKey
+	client   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newGCSTokenSource(serviceAccount string, client *http.Client) (*gcsTokenSource, error) {
+	var sa struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal([]byte(serviceAccount), &sa); err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("service account key needs client_email and private_key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = gcsTokenURI
+	}
+
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account private_key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, fmt.Errorf("service account private_key is not an RSA key")
+	}
+
+	return &gcsTokenSource{email: sa.ClientEmail, tokenURI: sa.TokenURI, key: key, client: client}, nil
+}
+
+// Token returns a valid access token, fetching a new one when needed
+func (ts *gcsTokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token != "" && time.Now().Before(ts.expires) {
+		return ts.token, nil
+	}
+
+	assertion, err := ts.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcs token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcs token: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("gcs token: invalid response")
+	}
+	ts.token = result.AccessToken
+	ts.expires = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - gcsTokenSlack)
+	return ts.token, nil
+}
+
+// assertion builds the RS256-signed JWT the token endpoint exchanges
+func (ts *gcsTokenSource) assertion(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   ts.email,
+		"scope": gcsScope,
+		"aud":   ts.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, ts.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,111 @@
+// internal/gateway/retry.go
+// Provider retry policies. Each provider documents which responses are
+// transient and how clients should back off; requests are rebuilt for every
+// attempt so bodies and signatures are fresh.
+package gateway
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryAfter caps how long a server-requested Retry-After is honoured
+const maxRetryAfter = 30 * time.Second
+
+// retryPolicy describes how a provider wants failed requests retried
+type retryPolicy struct {
+	attempts  int
+	base      time.Duration // Backoff before the second attempt, doubling after
+	jitter    bool          // Randomize each backoff in [0, backoff)
+	retryable func(status int) bool
+}
+
+var (
+	// S3 asks clients to retry 5xx (including SlowDown) and 429
+	s3Retry = retryPolicy{
+		attempts:  3,
+		base:      100 * time.Millisecond,
+		retryable: func(status int) bool { return status == http.StatusTooManyRequests || status >= 500 },
+	}
+
+	// Azure Storage retries timeouts, throttling and server busy, but not
+	// 501 or 505, which will never succeed
+	azureRetry = retryPolicy{
+		attempts: 4,
+		base:     200 * time.Millisecond,
+		retryable: func(status int) bool {
+			switch status {
+			case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+				http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				return true
+			}
+			return false
+		},
+	}
+
+	// GCS recommends truncated exponential backoff with jitter on 408, 429
+	// and 5xx
+	gcsRetry = retryPolicy{
+		attempts: 5,
+		base:     250 * time.Millisecond,
+		jitter:   true,
+		retryable: func(status int) bool {
+			return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+		},
+	}
+)
+
+// send issues the request built by newReq, retrying network failures and
+// retryable statuses. A final retryable response is turned into an error by
+// fail; any other response is returned for the caller to interpret.
+func (p retryPolicy) send(ctx context.Context, client *http.Client, newReq func() (*http.Request, error), fail func(*http.Response) error) (*http.Response, error) {
+	backoff := p.base
+	var lastErr error
+	var serverWait time.Duration // Retry-After of the last response, if any
+	for attempt := 0; attempt < p.attempts; attempt++ {
+		if attempt > 0 {
+			wait := backoff
+			if p.jitter {
+				wait = time.Duration(rand.Int63n(int64(backoff)))
+			}
+			if serverWait > 0 {
+				wait = serverWait
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			backoff *= 2
+		}
+
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr, serverWait = err, 0
+			continue
+		}
+		if !p.retryable(resp.StatusCode) {
+			return resp, nil
+		}
+		lastErr = fail(resp)
+		serverWait = retryAfter(resp)
+		resp.Body.Close()
+	}
+	return nil, lastErr
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, maxRetryAfter)
+}
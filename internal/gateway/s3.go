@@ -0,0 +1,313 @@
+// internal/gateway/s3.go
+// Amazon S3 (and S3-compatible) backend using path-style requests signed
+// with AWS Signature Version 4
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+)
+
+type s3Backend struct {
+	cfg      Config
+	endpoint string // scheme://host, no trailing slash
+	client   *http.Client
+}
+
+func newS3Backend(cfg Config) (Backend, error) {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	return &s3Backend{
+		cfg:      cfg,
+		endpoint: u.Scheme + "://" + u.Host,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (b *s3Backend) Get(ctx context.Context, key string) ([]byte, *ObjectInfo, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil, ErrNotFound
+	default:
+		return nil, nil, s3Error(resp, "get", key)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	info := &ObjectInfo{Key: key, Size: int64(len(data)), ETag: strings.Trim(resp.Header.Get("ETag"), `"`)}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return data, info, nil
+}
+
+// Put uses S3 conditional writes: If-Match on the ETag, If-None-Match: *
+// when the key must be absent
+func (b *s3Backend) Put(ctx context.Context, key string, data []byte, cond Condition) (*ObjectInfo, error) {
+	header := http.Header{}
+	if cond.IfMatch != "" {
+		header.Set("If-Match", `"`+cond.IfMatch+`"`)
+	}
+	if cond.IfAbsent {
+		header.Set("If-None-Match", "*")
+	}
+
+	resp, err := b.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		// 409 is a conditional write that raced another one
+		return nil, ErrPreconditionFailed
+	default:
+		return nil, s3Error(resp, "put", key)
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         int64(len(data)),
+		ETag:         strings.Trim(resp.Header.Get("ETag"), `"`),
+		LastModified: time.Now().UTC(),
+	}, nil
+}
+
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3Error(resp, "delete", key)
+	}
+}
+
+// s3ListResult is the subset of a ListObjectsV2 response we use
+type s3ListResult struct {
+	IsTruncated           bool          `xml:"IsTruncated"`
+	NextContinuationToken string        `xml:"NextContinuationToken"`
+	Contents              []s3ListEntry `xml:"Contents"`
+}
+
+type s3ListEntry struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	ETag         string    `xml:"ETag"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// List uses ListObjectsV2, resuming from its continuation token
+func (b *s3Backend) List(ctx context.Context, prefix, token string, max int) (*ListPage, error) {
+	query := url.Values{
+		"list-type": {"2"},
+		"max-keys":  {strconv.Itoa(max)},
+	}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+
+	resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, "list", prefix)
+	}
+	page, err := decodeS3List(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("s3 list %s: invalid response: %w", prefix, err)
+	}
+	return page, nil
+}
+
+// decodeS3List decodes a ListObjectsV2 response body
+func decodeS3List(r io.Reader) (*ListPage, error) {
+	var result s3ListResult
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	page := &ListPage{
+		Objects:   make([]ObjectInfo, 0, len(result.Contents)),
+		Truncated: result.IsTruncated,
+		NextToken: result.NextContinuationToken,
+	}
+	for _, c := range result.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:          c.Key,
+			Size:         c.Size,
+			ETag:         strings.Trim(c.ETag, `"`),
+			LastModified: c.LastModified,
+		})
+	}
+	return page, nil
+}
+
+// do sends a signed request for key (the bucket itself when empty) under
+// the S3 retry policy
+func (b *s3Backend) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	path := "/" + s3Escape(b.cfg.Bucket, false)
+	if key != "" {
+		path += "/" + s3Escape(key, true)
+	}
+	target := b.endpoint + path
+	if len(query) > 0 {
+		target += "?" + s3CanonicalQuery(query)
+	}
+
+	return s3Retry.send(ctx, b.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = int64(len(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		b.sign(req, body, time.Now().UTC())
+		return req, nil
+	}, func(resp *http.Response) error {
+		return s3Error(resp, strings.ToLower(method), key)
+	})
+}
+
+// sign adds SigV4 headers; requests without an access key go unsigned
+func (b *s3Backend) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if b.cfg.AccessKey == "" {
+		return
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.cfg.Region + "/s3/aws4_request"
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+b.cfg.SecretKey), date)
+	key = hmacSHA256(key, b.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, b.cfg.AccessKey, scope, s3SignedHeaders, signature))
+}
+
+// s3Error turns an S3 error response into an error carrying its code
+func s3Error(resp *http.Response, op, key string) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s: %s", op, key, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 %s %s: status %d", op, key, resp.StatusCode)
+}
+
+// s3Escape percent-encodes everything but RFC 3986 unreserved characters
+// (and '/' in object keys), as SigV4 canonicalization requires
+func s3Escape(s string, keepSlash bool) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && keepSlash:
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
+
+// s3CanonicalQuery encodes query sorted by key, as SigV4 requires
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3Escape(k, false)+"="+s3Escape(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
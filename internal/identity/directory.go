@@ -0,0 +1,482 @@
+// internal/identity/directory.go
+// LDAP/Active Directory identity backend for a tenant: scheduled user and
+// group sync, group-to-policy mapping and bind-based password login
+package identity
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultDirectorySyncInterval is how often users and groups are re-read
+	DefaultDirectorySyncInterval = 15 * time.Minute
+
+	// Timeout for each LDAP connection and round trip
+	directoryTimeout = 10 * time.Second
+)
+
+// Built-in policies and the token permissions they grant
+var Policies = map[string][]string{
+	"readonly":  {"object:read", "object:list"},
+	"readwrite": {"object:read", "object:list", "object:write", "object:delete"},
+	"admin":     {"*"},
+}
+
+// PolicyPermissions returns the union of permissions granted by policies
+func PolicyPermissions(policies []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, name := range policies {
+		for _, perm := range Policies[name] {
+			if !seen[perm] {
+				seen[perm] = true
+				out = append(out, perm)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ErrInvalidCredentials is returned for unknown users or wrong passwords
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrNoPolicy is returned when a user authenticates but no group maps to a policy
+var ErrNoPolicy = errors.New("user is not mapped to any policy")
+
+// DirectoryConfig configures one tenant's LDAP or Active Directory backend
+type DirectoryConfig struct {
+	// URL is ldap://host[:port] or ldaps://host[:port]
+	URL                string `json:"url"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+
+	// Service account used for sync and user lookups
+	BindDN       string `json:"bind_dn"`
+	BindPassword string `json:"bind_password,omitempty"`
+
+	UserBaseDN string `json:"user_base_dn"`
+	// UserFilter selects user entries (default "(objectClass=person)")
+	UserFilter string `json:"user_filter,omitempty"`
+	// UserAttr holds the login name: "uid", or "sAMAccountName" for AD
+	UserAttr string `json:"user_attr,omitempty"`
+
+	GroupBaseDN string `json:"group_base_dn,omitempty"`
+	// GroupFilter selects group entries (default "(|(objectClass=groupOfNames)(objectClass=group))")
+	GroupFilter string `json:"group_filter,omitempty"`
+	// GroupMemberAttr lists member DNs (default "member")
+	GroupMemberAttr string `json:"group_member_attr,omitempty"`
+
+	// GroupPolicies maps a group (by CN or full DN) to policy names
+	GroupPolicies map[string][]string `json:"group_policies"`
+
+	SyncInterval time.Duration `json:"-"`
+}
+
+// Validate fills defaults and checks required fields
+func (c *DirectoryConfig) Validate() error {
+	if !strings.HasPrefix(c.URL, "ldap://") && !strings.HasPrefix(c.URL, "ldaps://") {
+		return fmt.Errorf("LDAP URL must start with ldap:// or ldaps://")
+	}
+	if c.BindDN == "" || c.BindPassword == "" {
+		return fmt.Errorf("service bind DN and password are required")
+	}
+	if c.UserBaseDN == "" {
+		return fmt.Errorf("user base DN is required")
+	}
+	if c.UserFilter == "" {
+		c.UserFilter = "(objectClass=person)"
+	}
+	if c.UserAttr == "" {
+		c.UserAttr = "uid"
+	}
+	if c.GroupBaseDN == "" {
+		c.GroupBaseDN = c.UserBaseDN
+	}
+	if c.GroupFilter == "" {
+		c.GroupFilter = "(|(objectClass=groupOfNames)(objectClass=group))"
+	}
+	if c.GroupMemberAttr == "" {
+		c.GroupMemberAttr = "member"
+	}
+	if c.SyncInterval <= 0 {
+		c.SyncInterval = DefaultDirectorySyncInterval
+	}
+	for _, f := range []string{c.UserFilter, c.GroupFilter} {
+		if _, err := encodeFilter(f); err != nil {
+			return err
+		}
+	}
+	for group, policies := range c.GroupPolicies {
+		for _, p := range policies {
+			if _, ok := Policies[p]; !ok {
+				return fmt.Errorf("group %q maps to unknown policy %q", group, p)
+			}
+		}
+	}
+	return nil
+}
+
+// DirectoryUser is a synced directory user and their effective policies
+type DirectoryUser struct {
+	DN       string   `json:"dn"`
+	Username string   `json:"username"`
+	Email    string   `json:"email,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Policies []string `json:"policies,omitempty"`
+}
+
+// DirectoryStatus reports the outcome of the latest sync
+type DirectoryStatus struct {
+	URL       string    `json:"url"`
+	Users     int       `json:"users"`
+	Groups    int       `json:"groups"`
+	LastSync  time.Time `json:"last_sync,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Syncs     uint64    `json:"syncs"`
+	Failures  uint64    `json:"failures"`
+	Logins    uint64    `json:"logins"`
+	Denied    uint64    `json:"denied"`
+}
+
+// SyncResult describes what changed in a sync
+type SyncResult struct {
+	Users   int
+	Groups  int
+	Added   []string // Usernames
+	Removed []string // DNs of users no longer in the directory
+}
+
+// Directory is one tenant's LDAP backend
+type Directory struct {
+	config DirectoryConfig
+
+	mu       sync.RWMutex
+	users    map[string]*DirectoryUser // By lower-cased username
+	groups   int
+	lastSync time.Time
+	lastErr  string
+
+	// Statistics (lock-free)
+	syncs    atomic.Uint64
+	failures atomic.Uint64
+	logins   atomic.Uint64
+	denied   atomic.Uint64
+
+	cancel context.CancelFunc
+}
+
+// NewDirectory creates a backend; call Start to begin syncing
+func NewDirectory(config DirectoryConfig) (*Directory, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Directory{config: config, users: make(map[string]*DirectoryUser)}, nil
+}
+
+// Config returns the configuration with the bind password removed
+func (d *Directory) Config() DirectoryConfig {
+	cfg := d.config
+	cfg.BindPassword = ""
+	return cfg
+}
+
+func (d *Directory) dial() (*LDAPConn, error) {
+	var tlsConfig *tls.Config
+	if d.config.InsecureSkipVerify {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return DialLDAP(d.config.URL, tlsConfig, directoryTimeout)
+}
+
+// serviceConn returns a connection bound as the service account
+func (d *Directory) serviceConn() (*LDAPConn, error) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("service bind failed: %w", err)
+	}
+	return conn, nil
+}
+
+// userAttrs are requested for every user entry
+func (d *Directory) userAttrs() []string {
+	return []string{d.config.UserAttr, "mail", "cn", "displayName", "memberOf"}
+}
+
+func (d *Directory) userFromEntry(e *LDAPEntry) *DirectoryUser {
+	name := e.Get("displayName")
+	if name == "" {
+		name = e.Get("cn")
+	}
+	return &DirectoryUser{
+		DN:       e.DN,
+		Username: e.Get(d.config.UserAttr),
+		Email:    e.Get("mail"),
+		Name:     name,
+	}
+}
+
+// groupCN extracts the leading CN of a DN ("cn=devs,ou=groups" -> "devs")
+func groupCN(dn string) string {
+	first, _, _ := strings.Cut(dn, ",")
+	if k, v, ok := strings.Cut(first, "="); ok && strings.EqualFold(strings.TrimSpace(k), "cn") {
+		return strings.TrimSpace(v)
+	}
+	return dn
+}
+
+// policiesFor maps group DNs to the union of their policies
+func (d *Directory) policiesFor(groupDNs []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, dn := range groupDNs {
+		for group, policies := range d.config.GroupPolicies {
+			if !strings.EqualFold(group, dn) && !strings.EqualFold(group, groupCN(dn)) {
+				continue
+			}
+			for _, p := range policies {
+				if !seen[p] {
+					seen[p] = true
+					out = append(out, p)
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// finishUser records memberships and resolves policies
+func (d *Directory) finishUser(u *DirectoryUser, groupDNs []string) {
+	seen := make(map[string]bool)
+	u.Groups = u.Groups[:0]
+	for _, dn := range groupDNs {
+		key := strings.ToLower(dn)
+		if !seen[key] {
+			seen[key] = true
+			u.Groups = append(u.Groups, dn)
+		}
+	}
+	sort.Strings(u.Groups)
+	u.Policies = d.policiesFor(u.Groups)
+}
+
+// Sync re-reads every user and group from the directory
+func (d *Directory) Sync(ctx context.Context) (*SyncResult, error) {
+	d.syncs.Add(1)
+	result, err := d.sync(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.failures.Add(1)
+		d.lastErr = err.Error()
+		return nil, err
+	}
+	d.lastErr = ""
+	return result, nil
+}
+
+func (d *Directory) sync(ctx context.Context) (*SyncResult, error) {
+	conn, err := d.serviceConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	userEntries, err := conn.Search(d.config.UserBaseDN, ScopeWholeSubtree, d.config.UserFilter, d.userAttrs())
+	if err != nil {
+		return nil, fmt.Errorf("user search failed: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	groupEntries, err := conn.Search(d.config.GroupBaseDN, ScopeWholeSubtree, d.config.GroupFilter, []string{"cn", d.config.GroupMemberAttr})
+	if err != nil {
+		return nil, fmt.Errorf("group search failed: %w", err)
+	}
+
+	// Memberships come from both the groups' member lists and memberOf
+	memberships := make(map[string][]string) // Lower-cased user DN -> group DNs
+	for _, g := range groupEntries {
+		for _, member := range g.Values(d.config.GroupMemberAttr) {
+			key := strings.ToLower(member)
+			memberships[key] = append(memberships[key], g.DN)
+		}
+	}
+
+	users := make(map[string]*DirectoryUser, len(userEntries))
+	for i := range userEntries {
+		u := d.userFromEntry(&userEntries[i])
+		if u.Username == "" {
+			continue
+		}
+		groups := append(userEntries[i].Values("memberOf"), memberships[strings.ToLower(u.DN)]...)
+		d.finishUser(u, groups)
+		users[strings.ToLower(u.Username)] = u
+	}
+
+	d.mu.Lock()
+	result := &SyncResult{Users: len(users), Groups: len(groupEntries)}
+	for key, u := range users {
+		if _, ok := d.users[key]; !ok {
+			result.Added = append(result.Added, u.Username)
+		}
+	}
+	for key, u := range d.users {
+		if _, ok := users[key]; !ok {
+			result.Removed = append(result.Removed, u.DN)
+		}
+	}
+	d.users = users
+	d.groups = len(groupEntries)
+	d.lastSync = time.Now().UTC()
+	d.mu.Unlock()
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	return result, nil
+}
+
+// Start syncs immediately, then every SyncInterval until ctx is cancelled or
+// Stop is called. onSync, if set, observes every sync outcome.
+func (d *Directory) Start(ctx context.Context, onSync func(*SyncResult, error)) {
+	ctx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(d.config.SyncInterval)
+		defer ticker.Stop()
+
+		for {
+			result, err := d.Sync(ctx)
+			if err != nil {
+				log.Printf("Directory sync with %s failed: %v", d.config.URL, err)
+			}
+			if onSync != nil {
+				onSync(result, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends scheduled syncing
+func (d *Directory) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// Authenticate looks the user up with the service account, then binds as
+// them to check the password. Groups and policies are read live so a
+// membership change takes effect without waiting for the next sync.
+func (d *Directory) Authenticate(ctx context.Context, username, password string) (*DirectoryUser, error) {
+	if username == "" || password == "" {
+		d.denied.Add(1)
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := d.serviceConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", d.config.UserFilter, d.config.UserAttr, EscapeFilterValue(username))
+	entries, err := conn.Search(d.config.UserBaseDN, ScopeWholeSubtree, filter, d.userAttrs())
+	if err != nil {
+		return nil, fmt.Errorf("user search failed: %w", err)
+	}
+	if len(entries) != 1 {
+		d.denied.Add(1)
+		return nil, ErrInvalidCredentials
+	}
+	user := d.userFromEntry(&entries[0])
+
+	// Bind as the user on a separate connection so the service bind stays intact
+	userConn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	err = userConn.Bind(user.DN, password)
+	userConn.Close()
+	if err != nil {
+		var ldapErr *LDAPResultError
+		if errors.As(err, &ldapErr) {
+			d.denied.Add(1)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	groupFilter := fmt.Sprintf("(&%s(%s=%s))", d.config.GroupFilter, d.config.GroupMemberAttr, EscapeFilterValue(user.DN))
+	groups, err := conn.Search(d.config.GroupBaseDN, ScopeWholeSubtree, groupFilter, []string{"cn"})
+	if err != nil {
+		return nil, fmt.Errorf("group search failed: %w", err)
+	}
+	groupDNs := entries[0].Values("memberOf")
+	for _, g := range groups {
+		groupDNs = append(groupDNs, g.DN)
+	}
+	d.finishUser(user, groupDNs)
+
+	if len(user.Policies) == 0 {
+		d.denied.Add(1)
+		return nil, ErrNoPolicy
+	}
+	d.logins.Add(1)
+	return user, nil
+}
+
+// Users returns the synced users ordered by username
+func (d *Directory) Users() []DirectoryUser {
+	d.mu.RLock()
+	out := make([]DirectoryUser, 0, len(d.users))
+	for _, u := range d.users {
+		out = append(out, *u)
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Username < out[j].Username })
+	return out
+}
+
+// Status reports sync health and login counters
+func (d *Directory) Status() DirectoryStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return DirectoryStatus{
+		URL:       d.config.URL,
+		Users:     len(d.users),
+		Groups:    d.groups,
+		LastSync:  d.lastSync,
+		LastError: d.lastErr,
+		Syncs:     d.syncs.Load(),
+		Failures:  d.failures.Load(),
+		Logins:    d.logins.Load(),
+		Denied:    d.denied.Load(),
+	}
+}
@@ -0,0 +1,203 @@
+package identity
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeLDAP serves simple binds and searches over a fixed set of entries
+type fakeLDAP struct {
+	passwords map[string]string // DN -> password
+	entries   []LDAPEntry
+}
+
+func (f *fakeLDAP) serve(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return "ldap://" + ln.Addr().String()
+}
+
+func (f *fakeLDAP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		packet, err := readBERPacket(r)
+		if err != nil {
+			return
+		}
+		outer, _ := berParse(packet)
+		parts, _ := berParse(outer[0].data)
+		msgID := berInt(berInteger, berToInt(parts[0].data))
+		reply := func(op []byte) { conn.Write(berTLV(berSequence, msgID, op)) }
+		result := func(tag byte, code int) {
+			reply(berTLV(tag, berInt(berEnumerated, code), berString(berOctetString, ""), berString(berOctetString, "")))
+		}
+
+		fields, _ := berParse(parts[1].data)
+		switch parts[1].tag {
+		case ldapBindRequest:
+			dn, pw := string(fields[1].data), string(fields[2].data)
+			if want, ok := f.passwords[dn]; ok && want == pw {
+				result(ldapBindResponse, 0)
+			} else {
+				result(ldapBindResponse, LDAPResultInvalidCredentials)
+			}
+		case ldapSearchRequest:
+			base := strings.ToLower(string(fields[0].data))
+			for _, e := range f.entries {
+				if strings.HasSuffix(strings.ToLower(e.DN), base) && matchFilter(fields[6], &e) {
+					var attrs [][]byte
+					for name, values := range e.Attributes {
+						var vals [][]byte
+						for _, v := range values {
+							vals = append(vals, berString(berOctetString, v))
+						}
+						attrs = append(attrs, berTLV(berSequence, berString(berOctetString, name), berTLV(berSet, vals...)))
+					}
+					reply(berTLV(ldapSearchEntry, berString(berOctetString, e.DN), berTLV(berSequence, attrs...)))
+				}
+			}
+			result(ldapSearchDone, 0)
+		case ldapUnbindRequest:
+			return
+		}
+	}
+}
+
+func matchFilter(f berElement, e *LDAPEntry) bool {
+	children, _ := berParse(f.data)
+	switch f.tag {
+	case ldapFilterAnd:
+		for _, c := range children {
+			if !matchFilter(c, e) {
+				return false
+			}
+		}
+		return true
+	case ldapFilterOr:
+		for _, c := range children {
+			if matchFilter(c, e) {
+				return true
+			}
+		}
+		return false
+	case ldapFilterNot:
+		return !matchFilter(children[0], e)
+	case ldapFilterPresent:
+		return len(e.Values(string(f.data))) > 0
+	case ldapFilterEquality:
+		for _, v := range e.Values(string(children[0].data)) {
+			if strings.EqualFold(v, string(children[1].data)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestEncodeFilter(t *testing.T) {
+	for _, f := range []string{"(uid=alice)", "objectClass=*", "(&(a=1)(|(b=2)(!(c=3))))", "(cn=ab*cd*)", `(cn=a\2ab)`} {
+		if _, err := encodeFilter(f); err != nil {
+			t.Errorf("encodeFilter(%q) error = %v", f, err)
+		}
+	}
+	for _, f := range []string{"(uid=alice", "(=x)", "(&(a=1)", `(cn=\zz)`} {
+		if _, err := encodeFilter(f); err == nil {
+			t.Errorf("encodeFilter(%q) succeeded, want error", f)
+		}
+	}
+	if got := EscapeFilterValue("a*(b)"); got != `a\2a\28b\29` {
+		t.Errorf("EscapeFilterValue() = %q", got)
+	}
+}
+
+func TestDirectorySyncAndAuthenticate(t *testing.T) {
+	const (
+		svc   = "cn=svc,dc=example,dc=com"
+		alice = "uid=alice,ou=people,dc=example,dc=com"
+		bob   = "uid=bob,ou=people,dc=example,dc=com"
+	)
+	fake := &fakeLDAP{
+		passwords: map[string]string{svc: "svc-pw", alice: "alice-pw", bob: "bob-pw"},
+		entries: []LDAPEntry{
+			{DN: alice, Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"alice"}, "mail": {"alice@example.com"}}},
+			{DN: bob, Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"bob"}}},
+			{DN: "cn=devs,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"devs"}, "member": {alice}}},
+		},
+	}
+
+	d, err := NewDirectory(DirectoryConfig{
+		URL:           fake.serve(t),
+		BindDN:        svc,
+		BindPassword:  "svc-pw",
+		UserBaseDN:    "dc=example,dc=com",
+		GroupPolicies: map[string][]string{"devs": {"readwrite"}},
+	})
+	if err != nil {
+		t.Fatalf("NewDirectory() error = %v", err)
+	}
+
+	result, err := d.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.Users != 2 || result.Groups != 1 || len(result.Added) != 2 {
+		t.Fatalf("Sync() = %+v, want 2 users, 1 group, 2 added", result)
+	}
+	users := d.Users()
+	if users[0].Username != "alice" || len(users[0].Policies) != 1 || users[0].Policies[0] != "readwrite" {
+		t.Errorf("synced alice = %+v", users[0])
+	}
+
+	user, err := d.Authenticate(context.Background(), "alice", "alice-pw")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if user.DN != alice || user.Email != "alice@example.com" {
+		t.Errorf("Authenticate() = %+v", user)
+	}
+	if perms := PolicyPermissions(user.Policies); len(perms) != 4 {
+		t.Errorf("PolicyPermissions() = %v", perms)
+	}
+
+	for _, tc := range []struct {
+		user, password string
+		want           error
+	}{
+		{"alice", "wrong", ErrInvalidCredentials},
+		{"alice", "", ErrInvalidCredentials},
+		{"nobody", "pw", ErrInvalidCredentials},
+		{"*", "alice-pw", ErrInvalidCredentials},
+		{"bob", "bob-pw", ErrNoPolicy},
+	} {
+		if _, err := d.Authenticate(context.Background(), tc.user, tc.password); !errors.Is(err, tc.want) {
+			t.Errorf("Authenticate(%q, %q) error = %v, want %v", tc.user, tc.password, err, tc.want)
+		}
+	}
+
+	fake.entries = fake.entries[:1]
+	result, err = d.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("second Sync() error = %v", err)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != bob {
+		t.Errorf("second Sync() removed = %v, want [%s]", result.Removed, bob)
+	}
+}
@@ -0,0 +1,57 @@
+package identity
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func FuzzEncodeFilter(f *testing.F) {
+	for _, s := range []string{"(uid=alice)", "objectClass=*", "(&(a=1)(|(b=2)(!(c=3))))", "(cn=ab*cd*)", `(cn=a\2ab)`, "(&(a=1)", `(cn=\z)`} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, filter string) {
+		enc, err := encodeFilter(filter)
+		if err != nil {
+			return
+		}
+		if parts, err := berParse(enc); err != nil || len(parts) != 1 {
+			t.Fatalf("encodeFilter(%q) = %x, not one BER element (%v)", filter, enc, err)
+		}
+	})
+}
+
+func FuzzEscapeFilterValue(f *testing.F) {
+	f.Add("alice")
+	f.Add(`a*(b)\` + "\x00")
+	f.Fuzz(func(t *testing.T, value string) {
+		enc, err := encodeFilter("(uid=" + EscapeFilterValue(value) + ")")
+		if err != nil {
+			t.Fatalf("Escaped value %q rejected: %v", value, err)
+		}
+		parts, _ := berParse(enc)
+		if len(parts) != 1 || parts[0].tag != ldapFilterEquality {
+			t.Fatalf("Escaped value %q did not make an equality filter: %x", value, enc)
+		}
+		fields, err := berParse(parts[0].data)
+		if err != nil || len(fields) != 2 || string(fields[1].data) != value {
+			t.Fatalf("Escaped value %q encoded as %x", value, enc)
+		}
+	})
+}
+
+func FuzzParseEntry(f *testing.F) {
+	f.Add(berTLV(ldapSearchEntry, berString(berOctetString, "uid=alice,dc=example"),
+		berTLV(berSequence, berTLV(berSequence, berString(berOctetString, "mail"), berTLV(berSet, berString(berOctetString, "a@example.com"))))))
+	f.Add([]byte{0x04, 0x84, 0xff, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// The client parses the search entry out of a packet read off the wire
+		packet, err := readBERPacket(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return
+		}
+		if op, err := berParse(packet); err == nil && len(op) == 1 {
+			parseEntry(op[0].data)
+		}
+	})
+}
@@ -0,0 +1,532 @@
+// internal/identity/ldap.go
+// Minimal LDAPv3 client (simple bind and search) over plain TCP or TLS
+package identity
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BER tags used by the LDAP messages we exchange
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berBoolean     = 0x01
+	berSequence    = 0x30
+	berSet         = 0x31
+
+	ldapBindRequest      = 0x60
+	ldapBindResponse     = 0x61
+	ldapUnbindRequest    = 0x42
+	ldapSearchRequest    = 0x63
+	ldapSearchEntry      = 0x64
+	ldapSearchDone       = 0x65
+	ldapSearchReference  = 0x73
+	ldapAuthSimple       = 0x80
+	ldapFilterAnd        = 0xa0
+	ldapFilterOr         = 0xa1
+	ldapFilterNot        = 0xa2
+	ldapFilterEquality   = 0xa3
+	ldapFilterSubstrings = 0xa4
+	ldapFilterPresent    = 0x87
+)
+
+// Search scopes
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// LDAPResultError is a non-success LDAP result code
+type LDAPResultError struct {
+	Code    int
+	Message string
+}
+
+func (e *LDAPResultError) Error() string {
+	return fmt.Sprintf("LDAP result %d: %s", e.Code, e.Message)
+}
+
+// LDAPResultInvalidCredentials is returned by a bind with a wrong password
+const LDAPResultInvalidCredentials = 49
+
+// LDAPEntry is one search result
+type LDAPEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get returns the first value of attr (case-insensitive), or ""
+func (e *LDAPEntry) Get(attr string) string {
+	if v := e.Values(attr); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Values returns every value of attr (case-insensitive)
+func (e *LDAPEntry) Values(attr string) []string {
+	for name, v := range e.Attributes {
+		if strings.EqualFold(name, attr) {
+			return v
+		}
+	}
+	return nil
+}
+
+// ========== BER encoding ==========
+
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for v := n; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+func berTLV(tag byte, content ...[]byte) []byte {
+	size := 0
+	for _, c := range content {
+		size += len(c)
+	}
+	out := append([]byte{tag}, berLength(size)...)
+	for _, c := range content {
+		out = append(out, c...)
+	}
+	return out
+}
+
+func berInt(tag byte, v int) []byte {
+	b := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return berTLV(tag, b)
+}
+
+func berString(tag byte, s string) []byte {
+	return berTLV(tag, []byte(s))
+}
+
+type berElement struct {
+	tag  byte
+	data []byte
+}
+
+// berParse splits data into consecutive TLV elements
+func berParse(data []byte) ([]berElement, error) {
+	var out []berElement
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("truncated BER element")
+		}
+		tag, n, hdr := data[0], int(data[1]), 2
+		if n&0x80 != 0 {
+			octets := n & 0x7f
+			if octets == 0 || octets > 4 || len(data) < 2+octets {
+				return nil, fmt.Errorf("invalid BER length")
+			}
+			n = 0
+			for _, b := range data[2 : 2+octets] {
+				n = n<<8 | int(b)
+			}
+			hdr += octets
+		}
+		if len(data) < hdr+n {
+			return nil, fmt.Errorf("truncated BER element")
+		}
+		out = append(out, berElement{tag: tag, data: data[hdr : hdr+n]})
+		data = data[hdr+n:]
+	}
+	return out, nil
+}
+
+func berToInt(data []byte) int {
+	v := 0
+	for _, b := range data {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// readBERPacket reads one complete top-level TLV from r
+func readBERPacket(r *bufio.Reader) ([]byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	header := []byte{tag, first}
+	n := int(first)
+	if first&0x80 != 0 {
+		octets := int(first & 0x7f)
+		if octets == 0 || octets > 4 {
+			return nil, fmt.Errorf("invalid BER length")
+		}
+		lb := make([]byte, octets)
+		if _, err := io.ReadFull(r, lb); err != nil {
+			return nil, err
+		}
+		header = append(header, lb...)
+		n = berToInt(lb)
+	}
+	if n > 16<<20 {
+		return nil, fmt.Errorf("LDAP message too large")
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return append(header, body...), nil
+}
+
+// ========== Filters ==========
+
+// encodeFilter compiles an RFC 4515 filter string (and, or, not, equality,
+// presence and substring items) to BER
+func encodeFilter(filter string) ([]byte, error) {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	enc, rest, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("trailing characters in filter: %q", rest)
+	}
+	return enc, nil
+}
+
+func parseFilter(s string) ([]byte, string, error) {
+	if len(s) < 3 || s[0] != '(' {
+		return nil, "", fmt.Errorf("invalid filter: %q", s)
+	}
+	s = s[1:]
+
+	switch s[0] {
+	case '&', '|':
+		tag := byte(ldapFilterAnd)
+		if s[0] == '|' {
+			tag = ldapFilterOr
+		}
+		s = s[1:]
+		var items [][]byte
+		for len(s) > 0 && s[0] == '(' {
+			item, rest, err := parseFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			items = append(items, item)
+			s = rest
+		}
+		if len(s) == 0 || s[0] != ')' {
+			return nil, "", fmt.Errorf("unterminated filter")
+		}
+		return berTLV(tag, items...), s[1:], nil
+
+	case '!':
+		item, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		if len(rest) == 0 || rest[0] != ')' {
+			return nil, "", fmt.Errorf("unterminated filter")
+		}
+		return berTLV(ldapFilterNot, item), rest[1:], nil
+	}
+
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return nil, "", fmt.Errorf("unterminated filter")
+	}
+	item, rest := s[:end], s[end+1:]
+
+	attr, value, ok := strings.Cut(item, "=")
+	if !ok || attr == "" {
+		return nil, "", fmt.Errorf("invalid filter item: %q", item)
+	}
+
+	if value == "*" {
+		return berString(ldapFilterPresent, attr), rest, nil
+	}
+
+	if !strings.Contains(value, "*") {
+		v, err := unescapeFilterValue(value)
+		if err != nil {
+			return nil, "", err
+		}
+		return berTLV(ldapFilterEquality, berString(berOctetString, attr), berString(berOctetString, v)), rest, nil
+	}
+
+	parts := strings.Split(value, "*")
+	var subs [][]byte
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		v, err := unescapeFilterValue(p)
+		if err != nil {
+			return nil, "", err
+		}
+		tag := byte(0x81) // any
+		switch i {
+		case 0:
+			tag = 0x80 // initial
+		case len(parts) - 1:
+			tag = 0x82 // final
+		}
+		subs = append(subs, berString(tag, v))
+	}
+	return berTLV(ldapFilterSubstrings, berString(berOctetString, attr), berTLV(berSequence, subs...)), rest, nil
+}
+
+func unescapeFilterValue(v string) (string, error) {
+	if !strings.Contains(v, `\`) {
+		return v, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' {
+			b.WriteByte(v[i])
+			continue
+		}
+		if i+2 >= len(v) {
+			return "", fmt.Errorf("invalid escape in filter value")
+		}
+		decoded, err := hex.DecodeString(v[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in filter value")
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// EscapeFilterValue escapes user input for safe inclusion in a filter
+func EscapeFilterValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, `\%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ========== Client ==========
+
+// LDAPConn is a single LDAP connection
+type LDAPConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	msgID   int
+	timeout time.Duration
+}
+
+// DialLDAP connects to an ldap:// or ldaps:// URL
+func DialLDAP(rawURL string, tlsConfig *tls.Config, timeout time.Duration) (*LDAPConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+
+	host := u.Host
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	default:
+		return nil, fmt.Errorf("unsupported LDAP scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LDAP dial failed: %w", err)
+	}
+
+	return &LDAPConn{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}, nil
+}
+
+func (c *LDAPConn) send(op []byte) (int, error) {
+	c.msgID++
+	msg := berTLV(berSequence, berInt(berInteger, c.msgID), op)
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	_, err := c.conn.Write(msg)
+	return c.msgID, err
+}
+
+// receive reads the next message for msgID, returning its protocol op
+func (c *LDAPConn) receive(msgID int) (berElement, error) {
+	for {
+		packet, err := readBERPacket(c.reader)
+		if err != nil {
+			return berElement{}, fmt.Errorf("LDAP read failed: %w", err)
+		}
+		outer, err := berParse(packet)
+		if err != nil || len(outer) != 1 || outer[0].tag != berSequence {
+			return berElement{}, fmt.Errorf("malformed LDAP message")
+		}
+		parts, err := berParse(outer[0].data)
+		if err != nil || len(parts) < 2 {
+			return berElement{}, fmt.Errorf("malformed LDAP message")
+		}
+		if berToInt(parts[0].data) != msgID {
+			continue // Unsolicited notification
+		}
+		return parts[1], nil
+	}
+}
+
+func ldapResult(op berElement) error {
+	parts, err := berParse(op.data)
+	if err != nil || len(parts) < 3 {
+		return fmt.Errorf("malformed LDAP result")
+	}
+	if code := berToInt(parts[0].data); code != 0 {
+		return &LDAPResultError{Code: code, Message: string(parts[2].data)}
+	}
+	return nil
+}
+
+// Bind authenticates with a simple bind. An empty password would be an
+// unauthenticated bind that many servers accept, so it is refused here.
+func (c *LDAPConn) Bind(dn, password string) error {
+	if password == "" {
+		return &LDAPResultError{Code: LDAPResultInvalidCredentials, Message: "empty password"}
+	}
+
+	id, err := c.send(berTLV(ldapBindRequest,
+		berInt(berInteger, 3),
+		berString(berOctetString, dn),
+		berString(ldapAuthSimple, password),
+	))
+	if err != nil {
+		return err
+	}
+	op, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if op.tag != ldapBindResponse {
+		return fmt.Errorf("unexpected LDAP response 0x%x to bind", op.tag)
+	}
+	return ldapResult(op)
+}
+
+// Search returns every entry under baseDN matching filter
+func (c *LDAPConn) Search(baseDN string, scope int, filter string, attrs []string) ([]LDAPEntry, error) {
+	encFilter, err := encodeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	attrList := make([][]byte, 0, len(attrs))
+	for _, a := range attrs {
+		attrList = append(attrList, berString(berOctetString, a))
+	}
+
+	id, err := c.send(berTLV(ldapSearchRequest,
+		berString(berOctetString, baseDN),
+		berInt(berEnumerated, scope),
+		berInt(berEnumerated, 0), // neverDerefAliases
+		berInt(berInteger, 0),    // no size limit
+		berInt(berInteger, 0),    // no time limit
+		berTLV(berBoolean, []byte{0}),
+		encFilter,
+		berTLV(berSequence, attrList...),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LDAPEntry
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+		switch op.tag {
+		case ldapSearchEntry:
+			entry, err := parseEntry(op.data)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case ldapSearchReference:
+			// Referrals are not chased
+		case ldapSearchDone:
+			return entries, ldapResult(op)
+		default:
+			return nil, fmt.Errorf("unexpected LDAP response 0x%x to search", op.tag)
+		}
+	}
+}
+
+func parseEntry(data []byte) (LDAPEntry, error) {
+	parts, err := berParse(data)
+	if err != nil || len(parts) != 2 {
+		return LDAPEntry{}, fmt.Errorf("malformed search entry")
+	}
+	entry := LDAPEntry{DN: string(parts[0].data), Attributes: make(map[string][]string)}
+
+	attrs, err := berParse(parts[1].data)
+	if err != nil {
+		return LDAPEntry{}, fmt.Errorf("malformed search entry")
+	}
+	for _, attr := range attrs {
+		fields, err := berParse(attr.data)
+		if err != nil || len(fields) != 2 {
+			return LDAPEntry{}, fmt.Errorf("malformed attribute")
+		}
+		values, err := berParse(fields[1].data)
+		if err != nil {
+			return LDAPEntry{}, fmt.Errorf("malformed attribute values")
+		}
+		name := string(fields[0].data)
+		for _, v := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], string(v.data))
+		}
+	}
+	return entry, nil
+}
+
+// Close sends an unbind and closes the connection
+func (c *LDAPConn) Close() error {
+	c.send([]byte{ldapUnbindRequest, 0})
+	return c.conn.Close()
+}
@@ -0,0 +1,439 @@
+// internal/identity/oidc.go
+// OpenID Connect relying party: discovery, authorization code flow with
+// PKCE, ID token verification and mapping of IdP claims to tenants and roles
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Allowed clock skew when checking token times
+	clockSkew = time.Minute
+
+	// Pending logins are abandoned after this long
+	loginTTL = 10 * time.Minute
+
+	// Minimum gap between JWKS refreshes triggered by unknown key IDs
+	jwksRefreshInterval = time.Minute
+)
+
+// OIDCConfig configures the identity provider and claim mapping
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // Defaults to openid, profile, email, groups
+
+	// GroupsClaim names the claim listing the user's groups (default "groups")
+	GroupsClaim string
+
+	// TenantClaim, if set, names a claim whose value(s) are tenant IDs
+	TenantClaim string
+
+	// GroupTenants maps IdP groups to tenant IDs
+	GroupTenants map[string][]string
+
+	// AdminGroups grants the admin role to members of any listed group
+	AdminGroups []string
+}
+
+// Identity is an authenticated IdP user mapped onto tenants and roles
+type Identity struct {
+	Subject string    `json:"subject"`
+	Email   string    `json:"email,omitempty"`
+	Name    string    `json:"name,omitempty"`
+	Groups  []string  `json:"groups,omitempty"`
+	Tenants []string  `json:"tenants"`
+	Admin   bool      `json:"admin"`
+	Expiry  time.Time `json:"expiry"`
+
+	// Policies limit tokens exchanged for this identity; empty grants all
+	// permissions on mapped tenants (IdP identities)
+	Policies []string `json:"policies,omitempty"`
+}
+
+// Permissions returns the token permissions the identity may be issued
+func (id *Identity) Permissions() []string {
+	if len(id.Policies) == 0 {
+		return []string{"*"}
+	}
+	return PolicyPermissions(id.Policies)
+}
+
+// HasTenant reports whether the identity may act for tenantID
+func (id *Identity) HasTenant(tenantID string) bool {
+	for _, t := range id.Tenants {
+		if t == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// discovery is the subset of the provider metadata document we use
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// pendingLogin is an authorization request awaiting its callback
+type pendingLogin struct {
+	nonce     string
+	verifier  string
+	returnTo  string
+	createdAt time.Time
+}
+
+// Provider is an OIDC relying party. Discovery happens lazily so the server
+// starts even while the IdP is unreachable.
+type Provider struct {
+	config     OIDCConfig
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	meta        *discovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+	pending     map[string]*pendingLogin
+}
+
+// NewProvider creates a relying party for config
+func NewProvider(config OIDCConfig) (*Provider, error) {
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, fmt.Errorf("OIDC issuer and client ID are required")
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email", "groups"}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	return &Provider{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		pending:    make(map[string]*pendingLogin),
+	}, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// discover fetches provider metadata and signing keys once
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	meta := p.meta
+	p.mu.Unlock()
+	if meta != nil {
+		return meta, nil
+	}
+
+	var d discovery
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if d.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("OIDC issuer mismatch: configured %s, provider reports %s", p.config.Issuer, d.Issuer)
+	}
+
+	p.mu.Lock()
+	p.meta = &d
+	p.mu.Unlock()
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// refreshKeys reloads the JWKS, at most once per jwksRefreshInterval
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	p.mu.Lock()
+	if time.Since(p.keysFetched) < jwksRefreshInterval && p.keys != nil {
+		p.mu.Unlock()
+		return nil
+	}
+	jwksURI := p.meta.JWKSURI
+	p.mu.Unlock()
+
+	var set jwkSet
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	p.mu.Lock()
+	p.keys = set.publicKeys()
+	p.keysFetched = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AuthCodeURL starts a login, returning the IdP URL to redirect the browser to.
+// returnTo is handed back by Exchange once the login completes.
+func (p *Provider) AuthCodeURL(ctx context.Context, returnTo string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, nonce, verifier := randomString(16), randomString(16), randomString(32)
+	challenge := sha256.Sum256([]byte(verifier))
+
+	p.mu.Lock()
+	now := time.Now()
+	for s, pl := range p.pending {
+		if now.Sub(pl.createdAt) > loginTTL {
+			delete(p.pending, s)
+		}
+	}
+	p.pending[state] = &pendingLogin{nonce: nonce, verifier: verifier, returnTo: returnTo, createdAt: now}
+	p.mu.Unlock()
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange completes a login started by AuthCodeURL, returning the verified
+// identity and the returnTo passed at login
+func (p *Provider) Exchange(ctx context.Context, code, state string) (*Identity, string, error) {
+	p.mu.Lock()
+	pl := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+
+	if pl == nil || time.Since(pl.createdAt) > loginTTL {
+		return nil, "", fmt.Errorf("unknown or expired login state")
+	}
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {pl.verifier},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		return nil, "", fmt.Errorf("token exchange rejected: status %d %s", resp.StatusCode, tokens.Error)
+	}
+
+	id, err := p.verify(ctx, tokens.IDToken, pl.nonce)
+	if err != nil {
+		return nil, "", err
+	}
+	return id, pl.returnTo, nil
+}
+
+// VerifyIDToken verifies an ID token obtained outside the browser flow
+// (e.g. by a CLI) and maps it to an identity
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken string) (*Identity, error) {
+	return p.verify(ctx, rawToken, "")
+}
+
+func (p *Provider) verify(ctx context.Context, rawToken, nonce string) (*Identity, error) {
+	if _, err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	payload, err := verifyJWS(rawToken, keys)
+	if errors.Is(err, ErrUnknownKey) {
+		// The IdP may have rotated keys
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		keys = p.keys
+		p.mu.Unlock()
+		payload, err = verifyJWS(rawToken, keys)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	if err := p.validateClaims(claims, nonce); err != nil {
+		return nil, err
+	}
+	return p.mapIdentity(claims), nil
+}
+
+func (p *Provider) validateClaims(claims map[string]interface{}, nonce string) error {
+	now := time.Now()
+
+	if iss, _ := claims["iss"].(string); iss != p.config.Issuer {
+		return fmt.Errorf("token issuer %q not trusted", iss)
+	}
+	if !containsString(stringList(claims["aud"]), p.config.ClientID) {
+		return fmt.Errorf("token not issued for this client")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if nonce != "" {
+		if got, _ := claims["nonce"].(string); got != nonce {
+			return fmt.Errorf("token nonce mismatch")
+		}
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("token has no subject")
+	}
+	return nil
+}
+
+// mapIdentity applies the configured group and tenant claim mappings
+func (p *Provider) mapIdentity(claims map[string]interface{}) *Identity {
+	id := &Identity{Tenants: make([]string, 0)}
+	id.Subject, _ = claims["sub"].(string)
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		id.Expiry = time.Unix(int64(exp), 0).UTC()
+	}
+	id.Groups = stringList(claims[p.config.GroupsClaim])
+
+	addTenant := func(t string) {
+		if t != "" && !containsString(id.Tenants, t) {
+			id.Tenants = append(id.Tenants, t)
+		}
+	}
+	if p.config.TenantClaim != "" {
+		for _, t := range stringList(claims[p.config.TenantClaim]) {
+			addTenant(t)
+		}
+	}
+	for _, g := range id.Groups {
+		for _, t := range p.config.GroupTenants[g] {
+			addTenant(t)
+		}
+		if containsString(p.config.AdminGroups, g) {
+			id.Admin = true
+		}
+	}
+	return id
+}
+
+// EndSessionURL returns the IdP logout URL, if the provider advertises one
+func (p *Provider) EndSessionURL(ctx context.Context) string {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return ""
+	}
+	return meta.EndSessionEndpoint
+}
+
+// stringList accepts a claim holding a string or an array of strings
+func stringList(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	payload, _ := json.Marshal(claims)
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() error = %v", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestProviderLogin(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	var issuer, nonce string
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA", "kid": "k1", "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("code") != "good-code" || r.Form.Get("code_verifier") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id_token": signTestToken(t, key, map[string]interface{}{
+				"iss": issuer, "aud": "minio", "sub": "user-1", "email": "u@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce,
+				"groups": []string{"storage-admins", "team-a"},
+			})})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer idp.Close()
+	issuer = idp.URL
+
+	p, err := NewProvider(OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     "minio",
+		RedirectURL:  "http://localhost/sso/callback",
+		GroupTenants: map[string][]string{"team-a": {"tenant-a"}},
+		AdminGroups:  []string{"storage-admins"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	authURL, err := p.AuthCodeURL(context.Background(), "/console")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	state := u.Query().Get("state")
+	nonce = u.Query().Get("nonce")
+
+	id, returnTo, err := p.Exchange(context.Background(), "good-code", state)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if returnTo != "/console" || id.Subject != "user-1" || !id.Admin || !id.HasTenant("tenant-a") {
+		t.Errorf("Exchange() = %+v, %q", id, returnTo)
+	}
+
+	// State is single-use
+	if _, _, err := p.Exchange(context.Background(), "good-code", state); err == nil {
+		t.Error("Exchange() accepted a replayed state")
+	}
+
+	// Tokens for another audience are rejected
+	foreign := signTestToken(t, key, map[string]interface{}{
+		"iss": issuer, "aud": "other-app", "sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := p.VerifyIDToken(context.Background(), foreign); err == nil {
+		t.Error("VerifyIDToken() accepted a token for another client")
+	}
+}
@@ -0,0 +1,141 @@
+// internal/identity/session.go
+// Console sessions created by SSO logins
+package identity
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Session is a logged-in console user
+type Session struct {
+	ID        string    `json:"id"`
+	Identity  *Identity `json:"identity"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SessionStore holds sessions with an absolute lifetime and an idle timeout
+type SessionStore struct {
+	ttl  time.Duration
+	idle time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+
+	// Statistics (lock-free)
+	created atomic.Uint64
+	revoked atomic.Uint64
+	expired atomic.Uint64
+}
+
+// NewSessionStore creates a store; sessions end after ttl, or idle without use
+func NewSessionStore(ttl, idle time.Duration) *SessionStore {
+	return &SessionStore{
+		ttl:      ttl,
+		idle:     idle,
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create starts a session for id
+func (s *SessionStore) Create(id *Identity) *Session {
+	now := time.Now().UTC()
+	sess := &Session{
+		ID:        randomString(32),
+		Identity:  id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+		LastSeen:  now,
+	}
+
+	s.mu.Lock()
+	s.sessions[sess.ID] = sess
+	s.mu.Unlock()
+
+	s.created.Add(1)
+	cp := *sess
+	return &cp
+}
+
+func (s *SessionStore) expiredLocked(sess *Session, now time.Time) bool {
+	return now.After(sess.ExpiresAt) || (s.idle > 0 && now.Sub(sess.LastSeen) > s.idle)
+}
+
+// Get returns a live session and marks it as used
+func (s *SessionStore) Get(id string) (*Session, bool) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.sessions[id]
+	if sess == nil {
+		return nil, false
+	}
+	if s.expiredLocked(sess, now) {
+		delete(s.sessions, id)
+		s.expired.Add(1)
+		return nil, false
+	}
+	sess.LastSeen = now
+	cp := *sess
+	return &cp, true
+}
+
+// Revoke ends a session
+func (s *SessionStore) Revoke(id string) bool {
+	s.mu.Lock()
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	if ok {
+		s.revoked.Add(1)
+	}
+	return ok
+}
+
+// RevokeSubject ends every session of an IdP user, returning how many
+func (s *SessionStore) RevokeSubject(subject string) int {
+	s.mu.Lock()
+	n := 0
+	for id, sess := range s.sessions {
+		if sess.Identity.Subject == subject {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	s.mu.Unlock()
+
+	s.revoked.Add(uint64(n))
+	return n
+}
+
+// List returns live sessions, oldest first, dropping expired ones
+func (s *SessionStore) List() []Session {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	out := make([]Session, 0, len(s.sessions))
+	for id, sess := range s.sessions {
+		if s.expiredLocked(sess, now) {
+			delete(s.sessions, id)
+			s.expired.Add(1)
+			continue
+		}
+		out = append(out, *sess)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Stats returns created, revoked and expired session counts
+func (s *SessionStore) Stats() (created, revoked, expired uint64) {
+	return s.created.Load(), s.revoked.Load(), s.expired.Load()
+}
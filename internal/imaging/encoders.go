@@ -0,0 +1,95 @@
+// internal/imaging/encoders.go
+// Output encoders. JPEG, PNG and GIF are built in; formats the standard
+// library cannot write, such as WebP and AVIF, are produced by an external
+// command (cwebp, avifenc) when one is configured.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Encoder writes an image in one format
+type Encoder interface {
+	Encode(ctx context.Context, img image.Image, quality int) ([]byte, error)
+}
+
+// Encoders are keyed by format name
+type Encoders map[string]Encoder
+
+// DefaultEncoders returns the built-in encoders
+func DefaultEncoders() Encoders {
+	return Encoders{
+		"jpeg": EncoderFunc(func(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+			var buf bytes.Buffer
+			err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+			return buf.Bytes(), err
+		}),
+		"png": EncoderFunc(func(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+			var buf bytes.Buffer
+			err := png.Encode(&buf, img)
+			return buf.Bytes(), err
+		}),
+		"gif": EncoderFunc(func(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+			var buf bytes.Buffer
+			err := gif.Encode(&buf, img, nil)
+			return buf.Bytes(), err
+		}),
+	}
+}
+
+// EncoderFunc adapts a function to Encoder
+type EncoderFunc func(ctx context.Context, img image.Image, quality int) ([]byte, error)
+
+func (f EncoderFunc) Encode(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	return f(ctx, img, quality)
+}
+
+// CommandEncoder runs an external encoder on a PNG of the image. Command is
+// split on spaces (no shell) and {in}, {out} and {quality} in its arguments
+// are replaced, e.g. "cwebp -quiet -q {quality} {in} -o {out}".
+type CommandEncoder struct {
+	Command string
+}
+
+func (c CommandEncoder) Encode(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	args := strings.Fields(c.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: empty encoder command", ErrUnsupportedFormat)
+	}
+
+	dir, err := os.MkdirTemp("", "imaging-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(in, buf.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+
+	r := strings.NewReplacer("{in}", in, "{out}", out, "{quality}", strconv.Itoa(quality))
+	for i := range args {
+		args[i] = r.Replace(args[i])
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %v: %s", args[0], err, bytes.TrimSpace(output))
+	}
+	return os.ReadFile(out)
+}
@@ -0,0 +1,174 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decode(t *testing.T, data []byte) (image.Image, string) {
+	t.Helper()
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	return img, format
+}
+
+func TestParseParams(t *testing.T) {
+	p, err := ParseParams(url.Values{"w": {"100"}, "crop": {"10,20,30,40"}, "format": {"JPG"}, "q": {"50"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Params{Width: 100, Fit: FitContain, Crop: image.Rect(10, 20, 40, 60), Format: "jpeg", Quality: 50}
+	if p != want {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+
+	for _, q := range []url.Values{
+		{"w": {"0"}},
+		{"h": {"99999"}},
+		{"fit": {"squash"}},
+		{"crop": {"1,2,3"}},
+		{"crop": {"0,0,0,10"}},
+		{"format": {"bmp"}},
+		{"q": {"101"}},
+	} {
+		if _, err := ParseParams(q); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%v: got %v, want ErrInvalidParams", q, err)
+		}
+	}
+}
+
+func TestRequested(t *testing.T) {
+	if Requested(url.Values{"key": {"a"}, "q": {"50"}}) {
+		t.Error("quality alone should not request a transform")
+	}
+	if !Requested(url.Values{"w": {"10"}}) {
+		t.Error("width should request a transform")
+	}
+}
+
+func TestKeyDistinguishesParams(t *testing.T) {
+	a := Params{Width: 10, Fit: FitContain, Quality: 80}
+	b := a
+	b.Fit = FitCover
+	if a.Key() == b.Key() {
+		t.Error("different fits share a key")
+	}
+}
+
+func TestTransformResize(t *testing.T) {
+	src := testPNG(t, 200, 100)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		p    Params
+		w, h int
+	}{
+		{"width only", Params{Width: 50, Fit: FitContain}, 50, 25},
+		{"height only", Params{Height: 50, Fit: FitContain}, 100, 50},
+		{"contain", Params{Width: 50, Height: 50, Fit: FitContain}, 50, 25},
+		{"cover", Params{Width: 50, Height: 50, Fit: FitCover}, 50, 50},
+		{"fill", Params{Width: 30, Height: 70, Fit: FitFill}, 30, 70},
+		{"crop", Params{Crop: image.Rect(10, 10, 50, 30)}, 40, 20},
+		{"crop clipped", Params{Crop: image.Rect(180, 90, 300, 300)}, 20, 10},
+	}
+	for _, tt := range tests {
+		out, err := Transform(ctx, src, tt.p, DefaultEncoders())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		img, format := decode(t, out)
+		if format != "png" {
+			t.Errorf("%s: format %s, want source format png", tt.name, format)
+		}
+		if b := img.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("%s: got %dx%d, want %dx%d", tt.name, b.Dx(), b.Dy(), tt.w, tt.h)
+		}
+	}
+}
+
+func TestTransformCropContent(t *testing.T) {
+	out, err := Transform(context.Background(), testPNG(t, 100, 100), Params{Crop: image.Rect(30, 40, 35, 45)}, DefaultEncoders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, _ := decode(t, out)
+	r, g, _, _ := img.At(0, 0).RGBA()
+	if r>>8 != 30 || g>>8 != 40 {
+		t.Errorf("crop origin pixel is (%d,%d), want (30,40)", r>>8, g>>8)
+	}
+}
+
+func TestTransformFormats(t *testing.T) {
+	src := testPNG(t, 20, 20)
+	out, err := Transform(context.Background(), src, Params{Format: "jpeg", Quality: 70}, DefaultEncoders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, format := decode(t, out); format != "jpeg" {
+		t.Errorf("format %s, want jpeg", format)
+	}
+
+	if _, err := Transform(context.Background(), src, Params{Format: "webp"}, DefaultEncoders()); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("webp without an encoder: got %v", err)
+	}
+}
+
+func TestTransformRejects(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Transform(ctx, []byte("not an image"), Params{Width: 10}, DefaultEncoders()); !errors.Is(err, ErrNotImage) {
+		t.Errorf("text: got %v, want ErrNotImage", err)
+	}
+	if _, err := SourceFormat([]byte("GIF89")); !errors.Is(err, ErrNotImage) {
+		t.Errorf("truncated header: got %v, want ErrNotImage", err)
+	}
+
+	huge := image.NewGray(image.Rect(0, 0, 10000, 6000))
+	var buf bytes.Buffer
+	png.Encode(&buf, huge)
+	if _, err := Transform(ctx, buf.Bytes(), Params{Width: 10}, DefaultEncoders()); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("60MP source: got %v, want ErrTooLarge", err)
+	}
+
+	if _, err := Transform(ctx, testPNG(t, 10, 10), Params{Crop: image.Rect(20, 20, 30, 30)}, DefaultEncoders()); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("crop outside: got %v, want ErrInvalidParams", err)
+	}
+}
+
+func TestCommandEncoder(t *testing.T) {
+	enc := CommandEncoder{Command: "cp {in} {out}"}
+	out, err := enc.Encode(context.Background(), image.NewRGBA(image.Rect(0, 0, 4, 4)), 80)
+	if err != nil {
+		t.Skipf("cp unavailable: %v", err)
+	}
+	if _, format := decode(t, out); format != "png" {
+		t.Errorf("format %s, want the png handed to the command", format)
+	}
+
+	if _, err := (CommandEncoder{Command: "false"}).Encode(context.Background(), image.NewRGBA(image.Rect(0, 0, 1, 1)), 80); err == nil {
+		t.Error("failing command should error")
+	}
+}
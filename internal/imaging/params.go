@@ -0,0 +1,123 @@
+// internal/imaging/params.go
+// Transformation parameters as given on a download's query string
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxDimension bounds requested output width and height
+	MaxDimension = 4096
+
+	// DefaultQuality is used by lossy encoders when q is not given
+	DefaultQuality = 80
+)
+
+// Fit modes for resizing to both a width and a height
+const (
+	FitContain = "contain" // Scale to fit inside the box, keeping aspect ratio
+	FitCover   = "cover"   // Scale to cover the box, cropping the overflow
+	FitFill    = "fill"    // Stretch to exactly the box
+)
+
+// ErrInvalidParams reports malformed or out-of-range parameters
+var ErrInvalidParams = errors.New("invalid image parameters")
+
+// Params describe one transformation: crop, then resize, then encode
+type Params struct {
+	Width, Height int
+	Fit           string
+	Crop          image.Rectangle // Empty means no crop
+	Format        string          // Empty keeps the source format
+	Quality       int
+}
+
+// ParseParams reads w, h, fit, crop=x,y,width,height, format and q
+func ParseParams(query url.Values) (Params, error) {
+	p := Params{Fit: FitContain, Quality: DefaultQuality}
+	var err error
+
+	if p.Width, err = dimension(query, "w"); err != nil {
+		return p, err
+	}
+	if p.Height, err = dimension(query, "h"); err != nil {
+		return p, err
+	}
+
+	if fit := query.Get("fit"); fit != "" {
+		switch fit {
+		case FitContain, FitCover, FitFill:
+			p.Fit = fit
+		default:
+			return p, fmt.Errorf("%w: fit must be contain, cover or fill", ErrInvalidParams)
+		}
+	}
+
+	if crop := query.Get("crop"); crop != "" {
+		parts := strings.Split(crop, ",")
+		var v [4]int
+		if len(parts) != 4 {
+			return p, fmt.Errorf("%w: crop must be x,y,width,height", ErrInvalidParams)
+		}
+		for i, part := range parts {
+			if v[i], err = strconv.Atoi(strings.TrimSpace(part)); err != nil || v[i] < 0 {
+				return p, fmt.Errorf("%w: crop must be x,y,width,height", ErrInvalidParams)
+			}
+		}
+		if v[2] == 0 || v[3] == 0 {
+			return p, fmt.Errorf("%w: crop is empty", ErrInvalidParams)
+		}
+		p.Crop = image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3])
+	}
+
+	if format := strings.ToLower(query.Get("format")); format != "" {
+		if format == "jpg" {
+			format = "jpeg"
+		}
+		if _, ok := contentTypes[format]; !ok {
+			return p, fmt.Errorf("%w: unknown format %q", ErrInvalidParams, format)
+		}
+		p.Format = format
+	}
+
+	if q := query.Get("q"); q != "" {
+		if p.Quality, err = strconv.Atoi(q); err != nil || p.Quality < 1 || p.Quality > 100 {
+			return p, fmt.Errorf("%w: q must be 1-100", ErrInvalidParams)
+		}
+	}
+	return p, nil
+}
+
+func dimension(query url.Values, name string) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > MaxDimension {
+		return 0, fmt.Errorf("%w: %s must be 1-%d", ErrInvalidParams, name, MaxDimension)
+	}
+	return n, nil
+}
+
+// Requested reports whether the query asks for any transformation
+func Requested(query url.Values) bool {
+	for _, name := range []string{"w", "h", "crop", "format"} {
+		if query.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Key is a canonical form of p, identifying its output for caching
+func (p Params) Key() string {
+	return fmt.Sprintf("w=%d,h=%d,fit=%s,crop=%d:%d:%d:%d,format=%s,q=%d",
+		p.Width, p.Height, p.Fit, p.Crop.Min.X, p.Crop.Min.Y, p.Crop.Dx(), p.Crop.Dy(), p.Format, p.Quality)
+}
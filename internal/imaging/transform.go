@@ -0,0 +1,171 @@
+// internal/imaging/transform.go
+// Decoding, cropping, resampling and re-encoding. Only the first frame of
+// an animated GIF is used.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// MaxSourcePixels bounds the images decoded, so a small file declaring huge
+// dimensions cannot exhaust memory
+const MaxSourcePixels = 50_000_000
+
+var (
+	// ErrNotImage reports data that is not a decodable image
+	ErrNotImage = errors.New("not a supported image")
+
+	// ErrTooLarge reports a source image over MaxSourcePixels
+	ErrTooLarge = errors.New("image too large to transform")
+
+	// ErrUnsupportedFormat reports an output format with no encoder
+	ErrUnsupportedFormat = errors.New("output format not available")
+)
+
+// contentTypes maps the formats that may be requested to media types
+var contentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"avif": "image/avif",
+}
+
+// ContentType returns the media type of format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// SourceFormat returns the format of the encoded image in data
+func SourceFormat(data []byte) (string, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrNotImage
+	}
+	return format, nil
+}
+
+// Transform applies p to the encoded image in data, encoding the result in
+// p.Format, or the source format when that is empty
+func Transform(ctx context.Context, data []byte, p Params, encoders Encoders) ([]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxSourcePixels {
+		return nil, ErrTooLarge
+	}
+
+	if p.Format != "" {
+		format = p.Format
+	}
+	enc, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	img := toRGBA(src)
+
+	if !p.Crop.Empty() {
+		crop := p.Crop.Add(img.Rect.Min).Intersect(img.Rect)
+		if crop.Empty() {
+			return nil, fmt.Errorf("%w: crop is outside the image", ErrInvalidParams)
+		}
+		img = img.SubImage(crop).(*image.RGBA)
+	}
+
+	if p.Width > 0 || p.Height > 0 {
+		img = resize(img, p.Width, p.Height, p.Fit)
+	}
+
+	return enc.Encode(ctx, img, p.Quality)
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	return dst
+}
+
+// resize scales img to width x height under fit; a zero dimension follows
+// from the other and the aspect ratio
+func resize(img *image.RGBA, width, height int, fit string) *image.RGBA {
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+
+	switch {
+	case width == 0:
+		width = max(1, sw*height/sh)
+	case height == 0:
+		height = max(1, sh*width/sw)
+	case fit == FitContain:
+		if sw*height > sh*width {
+			height = max(1, sh*width/sw)
+		} else {
+			width = max(1, sw*height/sh)
+		}
+	case fit == FitCover:
+		// Scale to cover, then take the centered window
+		cw, ch := width, height
+		if sw*height > sh*width {
+			cw = max(width, sw*height/sh)
+		} else {
+			ch = max(height, sh*width/sw)
+		}
+		scaled := resample(img, cw, ch)
+		x, y := (cw-width)/2, (ch-height)/2
+		return scaled.SubImage(image.Rect(x, y, x+width, y+height)).(*image.RGBA)
+	}
+	return resample(img, width, height)
+}
+
+// resample scales src to exactly w x h, averaging the source area behind
+// each destination pixel (or sampling the nearest one when enlarging)
+func resample(src *image.RGBA, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	ox, oy := src.Rect.Min.X, src.Rect.Min.Y
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(ox+x0, oy+sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
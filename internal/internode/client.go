@@ -0,0 +1,159 @@
+// internal/internode/client.go
+// Fetching side of the internode protocol. Authenticated connections are
+// kept per peer and reused, one request at a time each.
+package internode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxIdlePerPeer is how many idle connections a client keeps to one peer
+const maxIdlePerPeer = 4
+
+// Client fetches byte ranges from peers
+type Client struct {
+	secret []byte
+	dialer net.Dialer
+
+	mu     sync.Mutex
+	idle   map[string][]net.Conn
+	closed bool
+}
+
+// NewClient creates a client authenticating with secret
+func NewClient(secret []byte) *Client {
+	return &Client{
+		secret: secret,
+		dialer: net.Dialer{Timeout: handshakeTimeout, KeepAlive: 30 * time.Second},
+		idle:   make(map[string][]net.Conn),
+	}
+}
+
+// Fetch copies length bytes of name from offset, or to the end of the
+// file if length is negative, from the peer at addr into w. It returns
+// the bytes copied. Copying into an *os.File splices from the socket.
+func (c *Client) Fetch(ctx context.Context, addr, name string, offset, length int64, w io.Writer) (int64, error) {
+	conn, err := c.conn(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+
+	n, err := c.fetch(conn, name, offset, length, w)
+	if !stop() {
+		// The context cut the deadline short
+		conn.Close()
+		if err != nil {
+			err = ctx.Err()
+		}
+		return n, err
+	}
+	if err != nil && !isReply(err) {
+		conn.Close()
+		return n, err
+	}
+	conn.SetDeadline(time.Time{})
+	c.put(addr, conn)
+	return n, err
+}
+
+func (c *Client) fetch(conn net.Conn, name string, offset, length int64, w io.Writer) (int64, error) {
+	if err := writeRequest(conn, request{name: name, offset: offset, length: length}); err != nil {
+		return 0, err
+	}
+	size, err := readReply(conn)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.CopyN(w, conn, size)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Close drops the idle connections; later fetches fail
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for addr, conns := range c.idle {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		delete(c.idle, addr)
+	}
+	return nil
+}
+
+func (c *Client) conn(ctx context.Context, addr string) (net.Conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, net.ErrClosed
+	}
+	if conns := c.idle[addr]; len(conns) > 0 {
+		conn := conns[len(conns)-1]
+		c.idle[addr] = conns[:len(conns)-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	c.mu.Unlock()
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.handshake(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *Client) handshake(conn net.Conn) error {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(conn, nonce); err != nil {
+		return fmt.Errorf("internode handshake: %w", err)
+	}
+	if _, err := conn.Write(proof(c.secret, nonce)); err != nil {
+		return fmt.Errorf("internode handshake: %w", err)
+	}
+	var status [1]byte
+	if _, err := io.ReadFull(conn, status[:]); err != nil {
+		return fmt.Errorf("internode handshake: %w", err)
+	}
+	if status[0] != statusOK {
+		return ErrDenied
+	}
+	return nil
+}
+
+func (c *Client) put(addr string, conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || len(c.idle[addr]) >= maxIdlePerPeer {
+		conn.Close()
+		return
+	}
+	c.idle[addr] = append(c.idle[addr], conn)
+}
+
+// isReply reports whether err is a refusal the peer sent, after which the
+// connection is still in step
+func isReply(err error) bool {
+	var re *replyError
+	return errors.As(err, &re)
+}
@@ -0,0 +1,135 @@
+package internode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startServer(t *testing.T, secret string) (*Server, string, []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	content := make([]byte, 1<<20+123)
+	for i := range content {
+		content[i] = byte(i * 31)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "ab"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ab", "chunk"), content, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := NewServer([]byte(secret))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	srv.Handle("chunks", DirOpener(dir))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return srv, ln.Addr().String(), content
+}
+
+// Whole files and ranges arrive intact over one reused connection, and
+// refusals leave it usable
+func TestFetch(t *testing.T) {
+	srv, addr, content := startServer(t, "s3cret")
+	c := NewClient([]byte("s3cret"))
+	defer c.Close()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if n, err := c.Fetch(ctx, addr, "chunks/ab/chunk", 0, -1, &buf); err != nil || n != int64(len(content)) {
+		t.Fatalf("Fetch whole file: %d, %v", n, err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Error("Whole file differs")
+	}
+
+	buf.Reset()
+	if _, err := c.Fetch(ctx, addr, "chunks/ab/chunk", 1000, 4096, &buf); err != nil {
+		t.Fatalf("Fetch range: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), content[1000:1000+4096]) {
+		t.Error("Range differs")
+	}
+
+	for name, want := range map[string]error{
+		"chunks/ab/missing":   ErrNotFound,
+		"chunks/../chunk":     ErrNotFound,
+		"other/ab/chunk":      ErrNotFound,
+		"chunks/ab":           ErrNotFound,
+		"chunks/ab/chunk#big": ErrNotFound,
+	} {
+		if _, err := c.Fetch(ctx, addr, name, 0, -1, &buf); !errors.Is(err, want) {
+			t.Errorf("Fetch %s: %v, want %v", name, err, want)
+		}
+	}
+	if _, err := c.Fetch(ctx, addr, "chunks/ab/chunk", int64(len(content))-1, 2, &buf); !errors.Is(err, ErrBadRange) {
+		t.Errorf("Fetch past the end: %v, want ErrBadRange", err)
+	}
+
+	// A file fetched into a file splices from the socket
+	out, err := os.Create(filepath.Join(t.TempDir(), "copy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	if _, err := c.Fetch(ctx, addr, "chunks/ab/chunk", 0, -1, out); err != nil {
+		t.Fatalf("Fetch into file: %v", err)
+	}
+	if got, _ := os.ReadFile(out.Name()); !bytes.Equal(got, content) {
+		t.Error("File copy differs")
+	}
+
+	st := srv.Stats()
+	if st.Connections != 1 {
+		t.Errorf("Connections = %d, want the one reused", st.Connections)
+	}
+	if st.Requests != 9 || st.BytesSent != uint64(2*len(content)+4096) {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+// A peer without the cluster secret is turned away
+func TestFetchDenied(t *testing.T) {
+	srv, addr, _ := startServer(t, "s3cret")
+	c := NewClient([]byte("wrong"))
+	defer c.Close()
+
+	if _, err := c.Fetch(context.Background(), addr, "chunks/ab/chunk", 0, -1, &bytes.Buffer{}); !errors.Is(err, ErrDenied) {
+		t.Fatalf("Fetch with the wrong secret: %v, want ErrDenied", err)
+	}
+	if _, err := NewServer(nil); err == nil {
+		t.Error("NewServer accepted an empty secret")
+	}
+	deadline := time.Now().Add(time.Second)
+	for srv.Stats().Denied != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if srv.Stats().Denied != 1 {
+		t.Errorf("Denied = %d, want 1", srv.Stats().Denied)
+	}
+}
+
+// A cancelled fetch returns the context's error
+func TestFetchCancelled(t *testing.T) {
+	_, addr, _ := startServer(t, "s3cret")
+	c := NewClient([]byte("s3cret"))
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Fetch(ctx, addr, "chunks/ab/chunk", 0, -1, &bytes.Buffer{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch with a cancelled context: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+// internal/internode/server.go
+// Serving side of the internode protocol. Names are namespace/path, and
+// each namespace is handled by an Opener, typically a directory of
+// immutable chunk files.
+package internode
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a connection may wait between requests
+const DefaultIdleTimeout = 2 * time.Minute
+
+// handshakeTimeout bounds authentication of a new connection
+const handshakeTimeout = 10 * time.Second
+
+// Opener opens the file a name within a namespace refers to
+type Opener func(name string) (*os.File, error)
+
+// DirOpener serves the regular files under dir, refusing names that
+// would leave it
+func DirOpener(dir string) Opener {
+	return func(name string) (*os.File, error) {
+		if !fs.ValidPath(name) || name == "." {
+			return nil, fs.ErrNotExist
+		}
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		if st, err := f.Stat(); err != nil || !st.Mode().IsRegular() {
+			f.Close()
+			return nil, fs.ErrNotExist
+		}
+		return f, nil
+	}
+}
+
+// Stats counts a server's traffic
+type Stats struct {
+	Connections int64  `json:"connections"` // Open now
+	Requests    uint64 `json:"requests"`
+	BytesSent   uint64 `json:"bytes_sent"`
+	Errors      uint64 `json:"errors"`
+	Denied      uint64 `json:"denied"` // Connections that failed the handshake
+}
+
+// Server answers internode requests
+type Server struct {
+	secret      []byte
+	IdleTimeout time.Duration // 0: DefaultIdleTimeout
+
+	mu       sync.RWMutex
+	handlers map[string]Opener
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+
+	connections atomic.Int64
+	requests    atomic.Uint64
+	bytesSent   atomic.Uint64
+	errors      atomic.Uint64
+	denied      atomic.Uint64
+}
+
+// NewServer creates a server that admits peers holding secret
+func NewServer(secret []byte) (*Server, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("internode: cluster secret is required")
+	}
+	return &Server{
+		secret:   secret,
+		handlers: make(map[string]Opener),
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Handle serves names under namespace/ with open
+func (s *Server) Handle(namespace string, open Opener) {
+	s.mu.Lock()
+	s.handlers[namespace] = open
+	s.mu.Unlock()
+}
+
+// Serve accepts connections on ln until Close
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return net.ErrClosed
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.RLock()
+			closed := s.closed
+			s.mu.RUnlock()
+			if closed {
+				return nil
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting, closes open connections and waits for their
+// handlers to return
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// Stats returns the server's counts
+func (s *Server) Stats() Stats {
+	return Stats{
+		Connections: s.connections.Load(),
+		Requests:    s.requests.Load(),
+		BytesSent:   s.bytesSent.Load(),
+		Errors:      s.errors.Load(),
+		Denied:      s.denied.Load(),
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	s.connections.Add(1)
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.connections.Add(-1)
+		s.wg.Done()
+	}()
+
+	if !s.handshake(conn) {
+		s.denied.Add(1)
+		return
+	}
+
+	idle := s.IdleTimeout
+	if idle <= 0 {
+		idle = DefaultIdleTimeout
+	}
+	for {
+		conn.SetReadDeadline(time.Now().Add(idle))
+		req, err := readRequest(conn)
+		if err != nil {
+			if errors.Is(err, ErrProtocol) {
+				s.errors.Add(1)
+				writeStatus(conn, statusError, err.Error())
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Time{})
+
+		s.requests.Add(1)
+		if err := s.serveRequest(conn, req); err != nil {
+			s.errors.Add(1)
+			log.Printf("internode: %s from %s: %v", req.name, conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+func (s *Server) handshake(conn net.Conn) bool {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return false
+	}
+	if _, err := conn.Write(nonce); err != nil {
+		return false
+	}
+	got := make([]byte, len(proof(s.secret, nonce)))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		return false
+	}
+	if !hmac.Equal(got, proof(s.secret, nonce)) {
+		writeStatus(conn, statusDenied, "bad cluster secret")
+		return false
+	}
+	_, err := conn.Write([]byte{statusOK})
+	return err == nil
+}
+
+// serveRequest answers req on conn. Refusals are written as replies and
+// leave the connection open; an error means it must close.
+func (s *Server) serveRequest(conn net.Conn, req request) error {
+	f, err := s.open(req.name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return writeStatus(conn, statusNotFound, req.name)
+		}
+		s.errors.Add(1)
+		return writeStatus(conn, statusError, err.Error())
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		s.errors.Add(1)
+		return writeStatus(conn, statusError, err.Error())
+	}
+	length := req.length
+	if length < 0 {
+		length = st.Size() - req.offset
+	}
+	if req.offset < 0 || length < 0 || req.offset+length > st.Size() {
+		return writeStatus(conn, statusBadRange, fmt.Sprintf("%d+%d of %d", req.offset, length, st.Size()))
+	}
+	if _, err := f.Seek(req.offset, io.SeekStart); err != nil {
+		s.errors.Add(1)
+		return writeStatus(conn, statusError, err.Error())
+	}
+
+	if err := writeOK(conn, length); err != nil {
+		return err
+	}
+	// A TCP connection copies from a limited *os.File with sendfile
+	n, err := io.Copy(conn, io.LimitReader(f, length))
+	s.bytesSent.Add(uint64(n))
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (s *Server) open(name string) (*os.File, error) {
+	namespace, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	s.mu.RLock()
+	open := s.handlers[namespace]
+	s.mu.RUnlock()
+	if open == nil {
+		return nil, fs.ErrNotExist
+	}
+	return open(rest)
+}
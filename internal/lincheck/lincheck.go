@@ -0,0 +1,117 @@
+// internal/lincheck/lincheck.go
+// Linearizability checking for the lock-free structures' tests: concurrent
+// operations are recorded with call and return times from one logical
+// clock, and the history is searched for an order that respects real time
+// and that a sequential model of the structure accepts
+package lincheck
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxOps is the longest history Check searches
+const MaxOps = 64
+
+// Op is one completed operation
+type Op struct {
+	Client int
+	Call   int64 // Logical time the operation started
+	Return int64 // Logical time it returned
+	Input  any
+	Output any
+}
+
+func (o Op) String() string {
+	return fmt.Sprintf("client %d [%d,%d] %v -> %v", o.Client, o.Call, o.Return, o.Input, o.Output)
+}
+
+// Model is the sequential specification of a structure. Step applies an
+// operation's input to state and reports whether the specification allows
+// the output the operation returned, and the resulting state. Key encodes
+// a state so equal states are searched once.
+type Model[S any] struct {
+	Init func() S
+	Step func(state S, input, output any) (bool, S)
+	Key  func(state S) string
+}
+
+// Recorder collects a history from concurrent clients
+type Recorder struct {
+	clock atomic.Int64
+
+	mu  sync.Mutex
+	ops []Op
+}
+
+// Begin marks the start of an operation and returns its call time
+func (r *Recorder) Begin() int64 {
+	return r.clock.Add(1)
+}
+
+// End records an operation started at call
+func (r *Recorder) End(client int, call int64, input, output any) {
+	ret := r.clock.Add(1)
+	r.mu.Lock()
+	r.ops = append(r.ops, Op{Client: client, Call: call, Return: ret, Input: input, Output: output})
+	r.mu.Unlock()
+}
+
+// History returns the operations recorded, in call order
+func (r *Recorder) History() []Op {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ops := append([]Op(nil), r.ops...)
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Call < ops[j].Call })
+	return ops
+}
+
+// Check reports whether history is linearizable under model: whether its
+// operations can be ordered so that each takes effect between its call
+// and return and the model accepts every output in that order. Histories
+// longer than MaxOps are rejected.
+func Check[S any](model Model[S], history []Op) (bool, error) {
+	if len(history) > MaxOps {
+		return false, fmt.Errorf("history of %d operations exceeds %d", len(history), MaxOps)
+	}
+	c := checker[S]{model: model, ops: history, seen: make(map[string]bool)}
+	return c.search(0, model.Init()), nil
+}
+
+type checker[S any] struct {
+	model Model[S]
+	ops   []Op
+	seen  map[string]bool // Linearized set and state already found to fail
+}
+
+// search tries to linearize the operations not in done, from state
+func (c *checker[S]) search(done uint64, state S) bool {
+	if done == 1<<len(c.ops)-1 {
+		return true
+	}
+	memo := fmt.Sprintf("%x/%s", done, c.model.Key(state))
+	if c.seen[memo] {
+		return false
+	}
+
+	// An operation can go next only if every pending operation that
+	// returned before it was called has already gone
+	deadline := int64(-1)
+	for i, op := range c.ops {
+		if done&(1<<i) == 0 && (deadline < 0 || op.Return < deadline) {
+			deadline = op.Return
+		}
+	}
+	for i, op := range c.ops {
+		if done&(1<<i) != 0 || op.Call > deadline {
+			continue
+		}
+		if ok, next := c.model.Step(state, op.Input, op.Output); ok && c.search(done|1<<i, next) {
+			return true
+		}
+	}
+	c.seen[memo] = true
+	return false
+}
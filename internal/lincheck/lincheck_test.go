@@ -0,0 +1,125 @@
+package lincheck
+
+import (
+	"sync"
+	"testing"
+	"testing/quick"
+	"unsafe"
+)
+
+func TestCheckQueueHistories(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []Op
+		want    bool
+	}{
+		{"sequential", []Op{
+			{Call: 1, Return: 2, Input: Push{1}, Output: true},
+			{Call: 3, Return: 4, Input: Push{2}, Output: true},
+			{Call: 5, Return: 6, Input: Pop{}, Output: 1},
+			{Call: 7, Return: 8, Input: Pop{}, Output: 2},
+			{Call: 9, Return: 10, Input: Pop{}, Output: 0},
+		}, true},
+		{"overlapping pop sees a push in flight", []Op{
+			{Call: 1, Return: 4, Input: Push{1}, Output: true},
+			{Call: 2, Return: 3, Input: Pop{}, Output: 1},
+		}, true},
+		{"pop before the push was called", []Op{
+			{Call: 1, Return: 2, Input: Pop{}, Output: 1},
+			{Call: 3, Return: 4, Input: Push{1}, Output: true},
+		}, false},
+		{"value popped twice", []Op{
+			{Call: 1, Return: 2, Input: Push{1}, Output: true},
+			{Call: 3, Return: 6, Input: Pop{}, Output: 1},
+			{Call: 4, Return: 5, Input: Pop{}, Output: 1},
+		}, false},
+		{"out of order", []Op{
+			{Call: 1, Return: 2, Input: Push{1}, Output: true},
+			{Call: 3, Return: 4, Input: Push{2}, Output: true},
+			{Call: 5, Return: 6, Input: Pop{}, Output: 2},
+		}, false},
+		{"full queue refuses", []Op{
+			{Call: 1, Return: 2, Input: Push{1}, Output: true},
+			{Call: 3, Return: 4, Input: Push{2}, Output: true},
+			{Call: 5, Return: 6, Input: Push{3}, Output: false},
+		}, true},
+		{"refused with room", []Op{
+			{Call: 1, Return: 2, Input: Push{1}, Output: false},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check(QueueModel(2), tt.history)
+			if err != nil || got != tt.want {
+				t.Errorf("Check = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMapHistories(t *testing.T) {
+	ok, _ := Check(MapModel(), []Op{
+		{Call: 1, Return: 4, Input: Insert{"a"}},
+		{Call: 2, Return: 3, Input: Get{"a"}, Output: true},
+		{Call: 5, Return: 6, Input: Remove{"a"}, Output: true},
+		{Call: 7, Return: 8, Input: Get{"a"}, Output: false},
+	})
+	if !ok {
+		t.Error("Valid map history rejected")
+	}
+	ok, _ = Check(MapModel(), []Op{
+		{Call: 1, Return: 2, Input: Insert{"a"}},
+		{Call: 3, Return: 4, Input: Get{"a"}, Output: false},
+	})
+	if ok {
+		t.Error("Lost insert accepted")
+	}
+}
+
+func TestCheckRejectsLongHistories(t *testing.T) {
+	if _, err := Check(QueueModel(1), make([]Op, MaxOps+1)); err == nil {
+		t.Error("Check accepted a history past MaxOps")
+	}
+}
+
+// lockedQueue is a queue that is linearizable by construction
+type lockedQueue struct {
+	mu    sync.Mutex
+	items []unsafe.Pointer
+	cap   int
+}
+
+func (q *lockedQueue) Push(item unsafe.Pointer) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) >= q.cap {
+		return false
+	}
+	q.items = append(q.items, item)
+	return true
+}
+
+func (q *lockedQueue) Pop() unsafe.Pointer {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item
+}
+
+func TestRunQueueOnLockedQueue(t *testing.T) {
+	linearizable := func(seed int64) bool {
+		history := RunQueue(&lockedQueue{cap: 3}, 4, 6, seed)
+		ok, err := Check(QueueModel(3), history)
+		if !ok || err != nil {
+			t.Logf("Locked queue history not linearizable (%v):\n%s", err, formatHistory(history))
+		}
+		return ok && err == nil
+	}
+	if err := quick.Check(linearizable, QuickConfig(t, 50)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,245 @@
+// internal/lincheck/models.go
+// Sequential models of the lock-free structures, a driver that records
+// concurrent histories of a queue, and a conformance test for queues
+package lincheck
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+	"unsafe"
+)
+
+// Queue operations. A push outputs whether it was accepted; a pop outputs
+// the value it took, 0 if the queue was empty.
+type (
+	Push struct{ Value int }
+	Pop  struct{}
+)
+
+// QueueModel is a FIFO queue holding at most capacity values
+func QueueModel(capacity int) Model[[]int] {
+	return Model[[]int]{
+		Init: func() []int { return nil },
+		Step: func(q []int, input, output any) (bool, []int) {
+			switch in := input.(type) {
+			case Push:
+				if len(q) >= capacity {
+					return output == false, q
+				}
+				return output == true, append(q[:len(q):len(q)], in.Value)
+			case Pop:
+				if len(q) == 0 {
+					return output == 0, q
+				}
+				return output == q[0], q[1:]
+			}
+			return false, q
+		},
+		Key: func(q []int) string { return fmt.Sprint(q) },
+	}
+}
+
+// Map operations. Insert outputs nothing, Remove whether the key was
+// present, and Get whether it is.
+type (
+	Insert struct{ Key string }
+	Remove struct{ Key string }
+	Get    struct{ Key string }
+)
+
+// MapModel is a set of keys
+func MapModel() Model[map[string]bool] {
+	with := func(m map[string]bool, key string, present bool) map[string]bool {
+		out := make(map[string]bool, len(m)+1)
+		for k := range m {
+			out[k] = true
+		}
+		if present {
+			out[key] = true
+		} else {
+			delete(out, key)
+		}
+		return out
+	}
+	return Model[map[string]bool]{
+		Init: func() map[string]bool { return map[string]bool{} },
+		Step: func(m map[string]bool, input, output any) (bool, map[string]bool) {
+			switch in := input.(type) {
+			case Insert:
+				return true, with(m, in.Key, true)
+			case Remove:
+				return output == m[in.Key], with(m, in.Key, false)
+			case Get:
+				return output == m[in.Key], m
+			}
+			return false, m
+		},
+		Key: func(m map[string]bool) string {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return strings.Join(keys, ",")
+		},
+	}
+}
+
+// Queue is a bounded queue of pointers, like the engines' ring buffers
+type Queue interface {
+	Push(item unsafe.Pointer) bool
+	Pop() unsafe.Pointer
+}
+
+// RunQueue has clients goroutines each make ops pushes or pops, chosen at
+// random from seed, on q at once, and returns the history. Every push is
+// of a distinct value.
+func RunQueue(q Queue, clients, ops int, seed int64) []Op {
+	var rec Recorder
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for c := 0; c < clients; c++ {
+		done.Add(1)
+		go func(c int) {
+			defer done.Done()
+			rng := rand.New(rand.NewSource(seed + int64(c)))
+			start.Wait()
+			for i := 0; i < ops; i++ {
+				if rng.Intn(2) == 0 {
+					v := c*ops + i + 1
+					call := rec.Begin()
+					ok := q.Push(unsafe.Pointer(&v))
+					rec.End(c, call, Push{v}, ok)
+					continue
+				}
+				call := rec.Begin()
+				item := q.Pop()
+				out := 0
+				if item != nil {
+					out = *(*int)(item)
+				}
+				rec.End(c, call, Pop{}, out)
+			}
+		}(c)
+	}
+	start.Done()
+	done.Wait()
+	return rec.History()
+}
+
+// SeedEnv names the environment variable that fixes the random source of
+// the property tests, to replay a failing run
+const SeedEnv = "LINCHECK_SEED"
+
+// QuickConfig returns a testing/quick configuration of count cases drawn
+// from a source seeded by SeedEnv, or the clock if it is unset. The seed
+// is logged, so a failure reports the value that replays it. Concurrent
+// cases replay the same operations, though not the same interleaving.
+func QuickConfig(t testing.TB, count int) *quick.Config {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	if env := os.Getenv(SeedEnv); env != "" {
+		var err error
+		if seed, err = strconv.ParseInt(env, 10, 64); err != nil {
+			t.Fatalf("Invalid %s: %v", SeedEnv, err)
+		}
+	}
+	t.Logf("%s=%d", SeedEnv, seed)
+	return &quick.Config{MaxCount: count, Rand: rand.New(rand.NewSource(seed))}
+}
+
+// TestQueue checks a queue implementation, like testing/fstest.TestFS
+// checks a file system: newQueue must return an empty queue holding at
+// most capacity items, capacity being a power of two. Random sequential
+// histories must match QueueModel, as must concurrent histories of a small
+// queue under contention, and under load every value pushed must be popped
+// exactly once. Run it with -race.
+func TestQueue(t *testing.T, newQueue func(capacity int) Queue) {
+	t.Helper()
+
+	config := QuickConfig(t, 300)
+	sequential := func(seed int64, n uint8) bool {
+		history := RunQueue(newQueue(4), 1, int(n)%MaxOps, seed)
+		ok, _ := Check(QueueModel(4), history)
+		return ok
+	}
+	if err := quick.Check(sequential, config); err != nil {
+		t.Errorf("Sequential history does not match the model: %v", err)
+	}
+
+	concurrent := func(seed int64) bool {
+		history := RunQueue(newQueue(4), 4, 8, seed)
+		ok, err := Check(QueueModel(4), history)
+		if !ok || err != nil {
+			t.Logf("History not linearizable (%v):\n%s", err, formatHistory(history))
+		}
+		return ok && err == nil
+	}
+	if err := quick.Check(concurrent, config); err != nil {
+		t.Fatalf("Concurrent history does not match the model: %v", err)
+	}
+
+	const producers, consumers, perProducer = 4, 4, 5000
+	q := newQueue(64)
+	var popped [producers * perProducer]atomic.Int32
+	var remaining atomic.Int64
+	remaining.Store(producers * perProducer)
+	deadline := time.Now().Add(10 * time.Second)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				v := p*perProducer + i
+				for !q.Push(unsafe.Pointer(&v)) {
+					if time.Now().After(deadline) {
+						return
+					}
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A lost value would leave the consumers waiting forever
+			for remaining.Load() > 0 && time.Now().Before(deadline) {
+				item := q.Pop()
+				if item == nil {
+					runtime.Gosched()
+					continue
+				}
+				popped[*(*int)(item)].Add(1)
+				remaining.Add(-1)
+			}
+		}()
+	}
+	wg.Wait()
+	for v := range popped {
+		if n := popped[v].Load(); n != 1 {
+			t.Fatalf("Value %d popped %d times", v, n)
+		}
+	}
+}
+
+func formatHistory(history []Op) string {
+	var b strings.Builder
+	for _, op := range history {
+		b.WriteString(op.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+// internal/metadata/acl.go
+// Object access control lists. An object's grants give principals outside
+// its tenant's own policies access to that one object; they are stored
+// with the object's index entry and journaled like its tags.
+package metadata
+
+// ACL permissions, as S3 names them. FULL_CONTROL grants the other four.
+const (
+	ACLRead        = "READ"
+	ACLWrite       = "WRITE"
+	ACLReadACP     = "READ_ACP"
+	ACLWriteACP    = "WRITE_ACP"
+	ACLFullControl = "FULL_CONTROL"
+)
+
+// ACLPermissions lists the permissions a grant may give
+var ACLPermissions = []string{ACLRead, ACLWrite, ACLReadACP, ACLWriteACP, ACLFullControl}
+
+// Grant gives one grantee a permission on an object. Grantees are
+// "user:<subject>", a user or service account of the object's tenant, or
+// "tenant:<id>", every principal of another tenant.
+type Grant struct {
+	Grantee    string `json:"grantee"`
+	Permission string `json:"permission"`
+}
+
+// Granted reports whether the object's ACL gives perm to any of grantees
+func (m *ObjectMeta) Granted(perm string, grantees ...string) bool {
+	for _, g := range m.ACL {
+		if g.Permission != perm && g.Permission != ACLFullControl {
+			continue
+		}
+		for _, grantee := range grantees {
+			if grantee != "" && g.Grantee == grantee {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// aclSize estimates the memory held by an ACL
+func aclSize(acl []Grant) int {
+	n := 0
+	for _, g := range acl {
+		n += len(g.Grantee) + len(g.Permission) + 32
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package metadata
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestObjectMetaGranted(t *testing.T) {
+	meta := &ObjectMeta{Tenant: "t1", Key: "k", ACL: []Grant{
+		{Grantee: "user:sa-1", Permission: ACLRead},
+		{Grantee: "tenant:t2", Permission: ACLFullControl},
+	}}
+
+	tests := []struct {
+		perm     string
+		grantees []string
+		want     bool
+	}{
+		{ACLRead, []string{"user:sa-1"}, true},
+		{ACLWrite, []string{"user:sa-1"}, false},
+		{ACLReadACP, []string{"user:sa-1"}, false},
+		{ACLWrite, []string{"user:sa-2", "tenant:t2"}, true},
+		{ACLWriteACP, []string{"tenant:t2"}, true},
+		{ACLRead, []string{"tenant:t3"}, false},
+		{ACLRead, []string{""}, false},
+	}
+	for _, tt := range tests {
+		if got := meta.Granted(tt.perm, tt.grantees...); got != tt.want {
+			t.Errorf("Granted(%s, %v) = %v, want %v", tt.perm, tt.grantees, got, tt.want)
+		}
+	}
+}
+
+func TestIndexSpillKeepsACL(t *testing.T) {
+	idx, err := OpenIndex(IndexConfig{Dir: t.TempDir(), MemoryBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	idx.Put(ObjectMeta{Tenant: "t1", Key: "shared", ACL: []Grant{{Grantee: "tenant:t2", Permission: ACLRead}}})
+	for i := 0; i < 100; i++ {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("k%04d", i)})
+	}
+	meta, err := idx.Get("t1", "shared")
+	if err != nil || !meta.Granted(ACLRead, "tenant:t2") {
+		t.Errorf("Get() after spill = %+v, %v", meta, err)
+	}
+}
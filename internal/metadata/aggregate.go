@@ -0,0 +1,105 @@
+// internal/metadata/aggregate.go
+// Per-prefix object counts and byte totals, maintained incrementally as keys
+// are put and deleted so du-style queries never scan the key space
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AggregateDelimiter separates the path levels whose totals the index keeps
+const AggregateDelimiter = "/"
+
+// PrefixUsage totals the objects at or below a prefix
+type PrefixUsage struct {
+	Prefix  string `json:"prefix"`
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// PrefixAggregate breaks a prefix's totals down by its immediate child prefixes
+type PrefixAggregate struct {
+	PrefixUsage
+	DirectObjects int64         `json:"direct_objects"` // Keys with no further delimiter
+	DirectBytes   int64         `json:"direct_bytes"`
+	Prefixes      []PrefixUsage `json:"prefixes"`
+}
+
+// prefixAgg is the running total for one prefix ("" is the tenant root)
+type prefixAgg struct {
+	objects  int64
+	bytes    int64
+	children map[string]struct{}
+}
+
+// account applies an object/byte delta to every prefix level of key,
+// dropping levels that no longer hold any object. Caller holds ti.mu.
+func (ti *tenantIndex) account(key string, objects, bytes int64) {
+	if ti.prefixes == nil {
+		ti.prefixes = make(map[string]*prefixAgg)
+	}
+
+	parent := ""
+	for end := 0; ; {
+		prefix := key[:end]
+		agg := ti.prefixes[prefix]
+		if agg == nil {
+			agg = &prefixAgg{children: make(map[string]struct{})}
+			ti.prefixes[prefix] = agg
+		}
+		agg.objects += objects
+		agg.bytes += bytes
+
+		if prefix != "" {
+			if agg.objects == 0 {
+				delete(ti.prefixes, prefix)
+				if p := ti.prefixes[parent]; p != nil {
+					delete(p.children, prefix)
+				}
+			} else {
+				ti.prefixes[parent].children[prefix] = struct{}{}
+			}
+		}
+
+		n := strings.Index(key[end:], AggregateDelimiter)
+		if n < 0 {
+			return
+		}
+		parent, end = prefix, end+n+len(AggregateDelimiter)
+	}
+}
+
+// AggregatePrefix returns the totals under prefix, which must be empty or end
+// with AggregateDelimiter, split into its immediate child prefixes. Cost is
+// proportional to the number of children, not the number of keys.
+func (idx *Index) AggregatePrefix(tenantID, prefix string) (*PrefixAggregate, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, AggregateDelimiter) {
+		return nil, fmt.Errorf("prefix must end with %q", AggregateDelimiter)
+	}
+
+	out := &PrefixAggregate{PrefixUsage: PrefixUsage{Prefix: prefix}, Prefixes: []PrefixUsage{}}
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return out, nil
+	}
+
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	agg := ti.prefixes[prefix]
+	if agg == nil {
+		return out, nil
+	}
+	out.Objects, out.Bytes = agg.objects, agg.bytes
+	out.DirectObjects, out.DirectBytes = agg.objects, agg.bytes
+	for child := range agg.children {
+		c := ti.prefixes[child]
+		out.Prefixes = append(out.Prefixes, PrefixUsage{Prefix: child, Objects: c.objects, Bytes: c.bytes})
+		out.DirectObjects -= c.objects
+		out.DirectBytes -= c.bytes
+	}
+	sort.Slice(out.Prefixes, func(i, j int) bool { return out.Prefixes[i].Prefix < out.Prefixes[j].Prefix })
+	return out, nil
+}
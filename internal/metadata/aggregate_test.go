@@ -0,0 +1,66 @@
+package metadata
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestAggregatePrefixIncremental checks the running totals against a full
+// recount after a random mix of puts, overwrites and deletes
+func TestAggregatePrefixIncremental(t *testing.T) {
+	idx := NewIndex()
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{"a", "a/1", "a/2", "a/b/1", "a/b/c/1", "b/1", "b//1", "c"}
+
+	for i := 0; i < 2000; i++ {
+		key := keys[rng.Intn(len(keys))]
+		if rng.Intn(3) == 0 {
+			idx.Delete("t1", key)
+		} else {
+			idx.Put(ObjectMeta{Tenant: "t1", Key: key, Size: int64(rng.Intn(100))})
+		}
+	}
+
+	for _, prefix := range []string{"", "a/", "a/b/", "a/b/c/", "b/", "b//", "z/"} {
+		got, err := idx.AggregatePrefix("t1", prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := PrefixAggregate{PrefixUsage: PrefixUsage{Prefix: prefix}}
+		children := make(map[string]*PrefixUsage)
+		var order []string
+		for _, meta := range idx.List("t1", prefix) {
+			want.Objects++
+			want.Bytes += meta.Size
+			rest := meta.Key[len(prefix):]
+			n := strings.Index(rest, AggregateDelimiter)
+			if n < 0 {
+				want.DirectObjects++
+				want.DirectBytes += meta.Size
+				continue
+			}
+			child := prefix + rest[:n+1]
+			if children[child] == nil {
+				children[child] = &PrefixUsage{Prefix: child}
+				order = append(order, child)
+			}
+			children[child].Objects++
+			children[child].Bytes += meta.Size
+		}
+		want.Prefixes = []PrefixUsage{}
+		for _, child := range order {
+			want.Prefixes = append(want.Prefixes, *children[child])
+		}
+
+		if fmt.Sprint(*got) != fmt.Sprint(want) {
+			t.Errorf("AggregatePrefix(%q) = %+v; want %+v", prefix, *got, want)
+		}
+	}
+
+	if _, err := idx.AggregatePrefix("t1", "a"); err == nil {
+		t.Error("expected error for prefix without trailing delimiter")
+	}
+}
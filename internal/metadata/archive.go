@@ -0,0 +1,250 @@
+// internal/metadata/archive.go
+// Archival of closed journal segments to a backend bucket, for
+// point-in-time recovery and for rebuilding a node's index elsewhere
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/enterprise/internal/gateway"
+)
+
+// Each compaction closes the journal file it replaces. With
+// JournalConfig.ArchiveDir set, the closed file is spooled there as a
+// segment named for its close time, and an Archiver uploads it. A segment
+// starts with a checkpoint of the whole index, so restoring any moment it
+// covers needs that segment alone.
+
+// DefaultArchiveInterval is how often the spool is uploaded and retention
+// applied
+const DefaultArchiveInterval = 30 * time.Second
+
+// ArchiveConfig selects where closed segments go and how long they stay
+type ArchiveConfig struct {
+	Backend gateway.Backend
+	Prefix  string // Prepended to segment keys; give each node its own
+
+	// Archived segments older than MaxAge, or beyond the newest
+	// MaxSegments, are deleted; zero disables either limit. The newest
+	// segment is always kept.
+	MaxAge      time.Duration
+	MaxSegments int
+
+	Interval time.Duration // Default DefaultArchiveInterval
+}
+
+// ArchiveStats tracks archival activity
+type ArchiveStats struct {
+	Uploaded      atomic.Uint64
+	UploadedBytes atomic.Uint64
+	UploadErrors  atomic.Uint64
+	Expired       atomic.Uint64
+	Spooled       atomic.Int64 // Closed segments waiting to upload
+}
+
+// ArchivedSegment is a closed journal segment in the archive
+type ArchivedSegment struct {
+	Key      string    `json:"key"`
+	ClosedAt time.Time `json:"closed_at"`
+	Size     int64     `json:"size"`
+}
+
+// RestoreReport describes a restore from the archive
+type RestoreReport struct {
+	Segment ArchivedSegment `json:"segment"`
+	At      time.Time       `json:"at"`
+	RecoveryReport
+}
+
+// Archiver uploads spooled segments and applies retention
+type Archiver struct {
+	dir    string
+	config ArchiveConfig
+	stats  *ArchiveStats
+	mu     sync.Mutex // Serializes passes
+}
+
+// NewArchiver ships the segments spooled in dir
+func NewArchiver(dir string, config ArchiveConfig) (*Archiver, error) {
+	if config.Backend == nil {
+		return nil, fmt.Errorf("journal archive backend is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create journal archive spool: %w", err)
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultArchiveInterval
+	}
+	return &Archiver{dir: dir, config: config, stats: &ArchiveStats{}}, nil
+}
+
+// Run archives every interval until ctx is done
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.Pass(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Journal archive error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pass uploads the spooled segments oldest first, removing each once
+// stored, then deletes archived segments past the retention limits. A
+// failed upload stops the pass and is retried on the next.
+func (a *Archiver) Pass(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names, err := filepath.Glob(filepath.Join(a.dir, "*.log"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	a.stats.Spooled.Store(int64(len(names)))
+
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read spooled segment: %w", err)
+		}
+		key := a.config.Prefix + filepath.Base(name)
+		if _, err := a.config.Backend.Put(ctx, key, data, gateway.Condition{}); err != nil {
+			a.stats.UploadErrors.Add(1)
+			return fmt.Errorf("failed to upload journal segment %s: %w", key, err)
+		}
+		os.Remove(name)
+		a.stats.Uploaded.Add(1)
+		a.stats.UploadedBytes.Add(uint64(len(data)))
+		a.stats.Spooled.Add(-1)
+	}
+
+	if a.config.MaxAge <= 0 && a.config.MaxSegments <= 0 {
+		return nil
+	}
+	return a.expire(ctx)
+}
+
+func (a *Archiver) expire(ctx context.Context) error {
+	segments, err := ListArchive(ctx, a.config.Backend, a.config.Prefix)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-a.config.MaxAge)
+	for i, seg := range segments[:max(len(segments)-1, 0)] {
+		old := a.config.MaxAge > 0 && seg.ClosedAt.Before(cutoff)
+		excess := a.config.MaxSegments > 0 && len(segments)-i > a.config.MaxSegments
+		if !old && !excess {
+			break // Newer segments are within both limits
+		}
+		if err := a.config.Backend.Delete(ctx, seg.Key); err != nil {
+			return fmt.Errorf("failed to expire journal segment %s: %w", seg.Key, err)
+		}
+		a.stats.Expired.Add(1)
+	}
+	return nil
+}
+
+// GetStats returns archival statistics
+func (a *Archiver) GetStats() *ArchiveStats {
+	return a.stats
+}
+
+// ListArchive returns the segments archived under prefix, oldest first
+func ListArchive(ctx context.Context, backend gateway.Backend, prefix string) ([]ArchivedSegment, error) {
+	var segments []ArchivedSegment
+	token := ""
+	for {
+		page, err := backend.List(ctx, prefix, token, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list journal archive: %w", err)
+		}
+		for _, obj := range page.Objects {
+			closedAt, ok := parseArchiveName(strings.TrimPrefix(obj.Key, prefix))
+			if ok {
+				segments = append(segments, ArchivedSegment{Key: obj.Key, ClosedAt: closedAt, Size: obj.Size})
+			}
+		}
+		if !page.Truncated {
+			break
+		}
+		token = page.NextToken
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].ClosedAt.Before(segments[j].ClosedAt) })
+	return segments, nil
+}
+
+// RestoreArchive rebuilds the index as it was at the given time, or as of
+// the newest archived segment if at is zero. The segment closed next after
+// that time covers it; transactions unfinished at that moment are rolled
+// back.
+func RestoreArchive(ctx context.Context, backend gateway.Backend, prefix string, at time.Time) (*Index, *RestoreReport, error) {
+	segments, err := ListArchive(ctx, backend, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("no journal segments archived under %q", prefix)
+	}
+
+	seg := segments[len(segments)-1]
+	if at.IsZero() {
+		at = seg.ClosedAt
+	}
+	i := sort.Search(len(segments), func(i int) bool { return !segments[i].ClosedAt.Before(at) })
+	if i == len(segments) {
+		return nil, nil, fmt.Errorf("archive ends at %s, before %s", seg.ClosedAt.Format(time.RFC3339), at.Format(time.RFC3339))
+	}
+	seg = segments[i]
+
+	data, _, err := backend.Get(ctx, seg.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch journal segment %s: %w", seg.Key, err)
+	}
+	idx := NewIndex()
+	recovery, incomplete, _, err := replay(bytes.NewReader(data), idx, at.UnixNano())
+	if err != nil {
+		return nil, nil, err
+	}
+	if recovery.Records == 0 {
+		return nil, nil, fmt.Errorf("archive starts after %s", at.Format(time.RFC3339))
+	}
+	for _, intent := range incomplete {
+		undoOps(idx, intent.Ops)
+	}
+	return idx, &RestoreReport{Segment: seg, At: at, RecoveryReport: *recovery}, nil
+}
+
+// archiveName names a segment for its close time so names sort in order
+func archiveName(closedAt time.Time) string {
+	return fmt.Sprintf("%020d.log", closedAt.UnixNano())
+}
+
+func parseArchiveName(name string) (time.Time, bool) {
+	digits, ok := strings.CutSuffix(name, ".log")
+	if !ok {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
@@ -0,0 +1,207 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/enterprise/internal/gateway"
+)
+
+// memBackend is an in-memory gateway.Backend
+type memBackend struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	down    bool
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{objects: make(map[string][]byte)}
+}
+
+func (m *memBackend) Get(ctx context.Context, key string) ([]byte, *gateway.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, nil, gateway.ErrNotFound
+	}
+	return data, &gateway.ObjectInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+func (m *memBackend) Put(ctx context.Context, key string, data []byte, cond gateway.Condition) (*gateway.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, errors.New("unavailable")
+	}
+	m.objects[key] = data
+	return &gateway.ObjectInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+func (m *memBackend) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memBackend) List(ctx context.Context, prefix, token string, max int) (*gateway.ListPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	page := &gateway.ListPage{}
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) && key > token {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > max {
+		keys, page.Truncated, page.NextToken = keys[:max], true, keys[max-1]
+	}
+	for _, key := range keys {
+		page.Objects = append(page.Objects, gateway.ObjectInfo{Key: key, Size: int64(len(m.objects[key]))})
+	}
+	return page, nil
+}
+
+func put(t *testing.T, l *IntentLog, idx *Index, key string) {
+	meta := ObjectMeta{Tenant: "t1", Key: key, Size: 1}
+	txn, err := l.Begin(Op{Type: OpPut, Meta: meta})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Put(meta)
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// mark returns a time strictly between the records before and after it
+func mark() time.Time {
+	time.Sleep(time.Millisecond)
+	defer time.Sleep(time.Millisecond)
+	return time.Now()
+}
+
+func keysOf(idx *Index) string {
+	var keys []string
+	for _, meta := range idx.List("t1", "") {
+		keys = append(keys, meta.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestJournalArchiveRestore(t *testing.T) {
+	dir := t.TempDir()
+	spool := filepath.Join(dir, "archive")
+	l, err := OpenIntentLog(filepath.Join(dir, "intent.log"), JournalConfig{Durability: DurabilityFlush, ArchiveDir: spool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	idx := NewIndex()
+	ctx := context.Background()
+
+	put(t, l, idx, "a")
+	beforeB := mark()
+	put(t, l, idx, "b")
+	if err := l.Compact(idx); err != nil {
+		t.Fatal(err)
+	}
+	put(t, l, idx, "c")
+	afterC := mark()
+
+	// d is begun before the point restored to and committed after
+	meta := ObjectMeta{Tenant: "t1", Key: "d", Size: 1}
+	txn, _ := l.Begin(Op{Type: OpPut, Meta: meta})
+	idx.Put(meta)
+	beforeCommitD := mark()
+	txn.Commit()
+	if err := l.Compact(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := newMemBackend()
+	archiver, err := NewArchiver(spool, ArchiveConfig{Backend: backend, Prefix: "node1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.down = true
+	if err := archiver.Pass(ctx); err == nil {
+		t.Fatal("Expected an upload error")
+	}
+	if archiver.GetStats().Spooled.Load() != 2 || archiver.GetStats().UploadErrors.Load() != 1 {
+		t.Errorf("Expected both segments kept in the spool, got %d", archiver.GetStats().Spooled.Load())
+	}
+	backend.down = false
+	if err := archiver.Pass(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(spool, "*")); len(files) != 0 {
+		t.Errorf("Expected uploaded segments removed from the spool, got %v", files)
+	}
+
+	segments, err := ListArchive(ctx, backend, "node1/")
+	if err != nil || len(segments) != 2 {
+		t.Fatalf("ListArchive() = %v, %v; want 2 segments", segments, err)
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{beforeB, "a"},
+		{afterC, "a,b,c"},
+		{beforeCommitD, "a,b,c"},
+		{time.Time{}, "a,b,c,d"},
+	} {
+		restored, report, err := RestoreArchive(ctx, backend, "node1/", tc.at)
+		if err != nil {
+			t.Fatalf("RestoreArchive(%v) error = %v", tc.at, err)
+		}
+		if got := keysOf(restored); got != tc.want {
+			t.Errorf("RestoreArchive(%v) = %s, want %s (from %s)", tc.at, got, tc.want, report.Segment.Key)
+		}
+	}
+	if _, _, err := RestoreArchive(ctx, backend, "node1/", time.Now()); err == nil {
+		t.Error("Expected error restoring past the end of the archive")
+	}
+	if _, _, err := RestoreArchive(ctx, backend, "node2/", time.Time{}); err == nil {
+		t.Error("Expected error for an empty archive")
+	}
+}
+
+func TestJournalArchiveRetention(t *testing.T) {
+	backend := newMemBackend()
+	now := time.Now()
+	for _, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 2 * time.Hour, time.Hour} {
+		backend.objects["j/"+archiveName(now.Add(-age))] = []byte("{}")
+	}
+	backend.objects["j/notes.txt"] = []byte("not a segment")
+	ctx := context.Background()
+
+	archiver, _ := NewArchiver(t.TempDir(), ArchiveConfig{Backend: backend, Prefix: "j/", MaxAge: 24 * time.Hour})
+	if err := archiver.Pass(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := ListArchive(ctx, backend, "j/"); len(segments) != 2 {
+		t.Errorf("Expected segments past MaxAge expired, %d left", len(segments))
+	}
+
+	archiver, _ = NewArchiver(t.TempDir(), ArchiveConfig{Backend: backend, Prefix: "j/", MaxAge: time.Minute, MaxSegments: 5})
+	archiver.Pass(ctx)
+	segments, _ := ListArchive(ctx, backend, "j/")
+	if len(segments) != 1 || !segments[0].ClosedAt.Equal(time.Unix(0, now.Add(-time.Hour).UnixNano())) {
+		t.Errorf("Expected the newest segment always kept, got %v", segments)
+	}
+	if _, ok := backend.objects["j/notes.txt"]; !ok {
+		t.Error("Expected objects that are not segments left alone")
+	}
+}
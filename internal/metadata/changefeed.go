@@ -0,0 +1,353 @@
+// internal/metadata/changefeed.go
+// Ordered, resumable per-tenant feed of committed object changes, fed by
+// intent log commits, for downstream search indexing and analytics
+package metadata
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Change types
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// DefaultChangeRetain is the number of changes kept per tenant
+const DefaultChangeRetain = 100000
+
+// ErrChangesExpired is returned when resuming after a change the feed no
+// longer holds the successors of
+var ErrChangesExpired = errors.New("changes no longer retained")
+
+// Change is one committed object change. Seq orders a tenant's changes,
+// starting at 1, and is never reused.
+type Change struct {
+	Seq       uint64            `json:"seq"`
+	Tenant    string            `json:"tenant"`
+	Type      string            `json:"type"`
+	Key       string            `json:"key"`
+	Size      int64             `json:"size,omitempty"`
+	ETag      string            `json:"etag,omitempty"`
+	VersionID string            `json:"version_id,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// ChangeFeedStats tracks feed activity
+type ChangeFeedStats struct {
+	Published   atomic.Uint64
+	Expired     atomic.Uint64 // Reads refused because the changes were dropped
+	Compactions atomic.Uint64
+	Retained    atomic.Int64
+}
+
+// ChangeFeed holds the most recent changes of every tenant in memory and
+// in an append-only file it is rebuilt from on open
+type ChangeFeed struct {
+	path   string
+	retain int
+
+	mu      sync.Mutex
+	file    *os.File
+	tenants map[string]*tenantChanges
+	onDisk  int           // Changes in the file, retained or not
+	changed chan struct{} // Closed and replaced on every publish
+
+	stats *ChangeFeedStats
+}
+
+// tenantChanges is a tenant's retained changes, oldest first. The newest
+// is never dropped, so a reload recovers the next sequence number.
+type tenantChanges struct {
+	changes []Change
+	last    uint64
+}
+
+// OpenChangeFeed opens (creating if necessary) the feed at path, keeping
+// retain changes per tenant (DefaultChangeRetain if 0)
+func OpenChangeFeed(path string, retain int) (*ChangeFeed, error) {
+	if retain <= 0 {
+		retain = DefaultChangeRetain
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create change feed directory: %w", err)
+	}
+
+	f := &ChangeFeed{
+		path:    path,
+		retain:  retain,
+		tenants: make(map[string]*tenantChanges),
+		changed: make(chan struct{}),
+		stats:   &ChangeFeedStats{},
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	// Rewrite the file with only what was retained
+	if err := f.compactLocked(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// load rebuilds the tenants from the file. A torn final line is dropped.
+func (f *ChangeFeed) load() error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open change feed: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordBytes)
+	for scanner.Scan() {
+		var c Change
+		if json.Unmarshal(scanner.Bytes(), &c) != nil {
+			continue
+		}
+		f.add(c)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read change feed: %w", err)
+	}
+	return nil
+}
+
+// add appends c to its tenant, dropping the oldest changes once twice the
+// retention limit is held
+func (f *ChangeFeed) add(c Change) {
+	t := f.tenants[c.Tenant]
+	if t == nil {
+		t = &tenantChanges{}
+		f.tenants[c.Tenant] = t
+	}
+	t.changes = append(t.changes, c)
+	t.last = c.Seq
+	f.stats.Retained.Add(1)
+
+	if len(t.changes) >= 2*f.retain {
+		kept := make([]Change, f.retain, 2*f.retain)
+		copy(kept, t.changes[len(t.changes)-f.retain:])
+		t.changes = kept
+		f.stats.Retained.Add(-int64(f.retain))
+	}
+}
+
+// Publish records the changes made by a committed transaction's ops and
+// returns them. They are held in memory even if writing them fails.
+func (f *ChangeFeed) Publish(ops []Op) ([]Change, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UTC()
+	changes := make([]Change, 0, len(ops))
+	var buf []byte
+	for _, op := range ops {
+		c := Change{
+			Tenant:    op.Meta.Tenant,
+			Key:       op.Meta.Key,
+			Size:      op.Meta.Size,
+			ETag:      op.Meta.ETag,
+			VersionID: op.Meta.VersionID,
+			Tags:      op.Meta.Tags,
+			Time:      now,
+		}
+		switch {
+		case op.Type == OpDelete:
+			c.Type = ChangeDelete
+			c.Size, c.ETag, c.Tags = 0, "", nil
+		case op.Prev != nil:
+			c.Type = ChangeUpdate
+		default:
+			c.Type = ChangeCreate
+		}
+		if t := f.tenants[c.Tenant]; t != nil {
+			c.Seq = t.last + 1
+		} else {
+			c.Seq = 1
+		}
+		f.add(c)
+		changes = append(changes, c)
+
+		line, err := json.Marshal(c)
+		if err != nil {
+			return changes, fmt.Errorf("failed to encode change: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	f.stats.Published.Add(uint64(len(ops)))
+	close(f.changed)
+	f.changed = make(chan struct{})
+
+	if _, err := f.file.Write(buf); err != nil {
+		return changes, fmt.Errorf("failed to write change feed: %w", err)
+	}
+	f.onDisk += len(ops)
+	if f.onDisk >= 2*int(f.stats.Retained.Load())+f.retain {
+		return changes, f.compactLocked()
+	}
+	return changes, nil
+}
+
+// Since returns up to limit of tenantID's changes after seq, oldest first,
+// and the newest sequence number published. If changes after seq have been
+// dropped it returns ErrChangesExpired and the oldest one still held.
+func (f *ChangeFeed) Since(tenantID string, after uint64, limit int) ([]Change, uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := f.tenants[tenantID]
+	if t == nil || after >= t.last {
+		var last uint64
+		if t != nil {
+			last = t.last
+		}
+		return nil, last, nil
+	}
+	if len(t.changes) == 0 || t.changes[0].Seq > after+1 {
+		f.stats.Expired.Add(1)
+		oldest := t.last + 1
+		if len(t.changes) > 0 {
+			oldest = t.changes[0].Seq
+		}
+		return nil, oldest, ErrChangesExpired
+	}
+
+	i := sort.Search(len(t.changes), func(i int) bool { return t.changes[i].Seq > after })
+	end := min(i+limit, len(t.changes))
+	out := make([]Change, end-i)
+	copy(out, t.changes[i:end])
+	return out, t.last, nil
+}
+
+// Oldest returns the sequence number of tenantID's oldest retained change,
+// or the next to be published if none are held
+func (f *ChangeFeed) Oldest(tenantID string) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := f.tenants[tenantID]
+	switch {
+	case t == nil:
+		return 1
+	case len(t.changes) == 0:
+		return t.last + 1
+	}
+	return t.changes[0].Seq
+}
+
+// Latest returns the newest sequence number published for each tenant
+func (f *ChangeFeed) Latest() map[string]uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	latest := make(map[string]uint64, len(f.tenants))
+	for tenantID, t := range f.tenants {
+		latest[tenantID] = t.last
+	}
+	return latest
+}
+
+// Wait blocks until tenantID has a change after seq or ctx ends, and
+// reports whether one arrived
+func (f *ChangeFeed) Wait(ctx context.Context, tenantID string, after uint64) bool {
+	for {
+		f.mu.Lock()
+		t := f.tenants[tenantID]
+		if t != nil && t.last > after {
+			f.mu.Unlock()
+			return true
+		}
+		changed := f.changed
+		f.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// compactLocked rewrites the file with the retained changes
+func (f *ChangeFeed) compactLocked() error {
+	tmpPath := f.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted change feed: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	n := 0
+	for _, t := range f.tenants {
+		// Keep only the newest retain, so the file matches what a reload holds
+		if len(t.changes) > f.retain {
+			f.stats.Retained.Add(-int64(len(t.changes) - f.retain))
+			t.changes = append([]Change(nil), t.changes[len(t.changes)-f.retain:]...)
+		}
+		for _, c := range t.changes {
+			if err = enc.Encode(c); err != nil {
+				break
+			}
+			n++
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write compacted change feed: %w", err)
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to install compacted change feed: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to reopen change feed: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.onDisk = n
+	f.stats.Compactions.Add(1)
+	return nil
+}
+
+// GetStats returns feed statistics
+func (f *ChangeFeed) GetStats() *ChangeFeedStats {
+	return f.stats
+}
+
+// Close closes the feed file
+func (f *ChangeFeed) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
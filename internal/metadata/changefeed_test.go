@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChangeFeedOrderAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+	feed, err := OpenChangeFeed(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := ObjectMeta{Tenant: "t1", Key: "a", Size: 1}
+	feed.Publish([]Op{{Type: OpPut, Meta: a}})
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a", Size: 2}, Prev: &a}})
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t2", Key: "x"}}})
+	if changes, _ := feed.Publish([]Op{{Type: OpDelete, Meta: a, Prev: &a}}); len(changes) != 1 || changes[0].Seq != 3 {
+		t.Fatalf("Expected the published change returned, got %v", changes)
+	}
+	if latest := feed.Latest(); latest["t1"] != 3 || latest["t2"] != 1 {
+		t.Errorf("Latest() = %v", latest)
+	}
+
+	changes, latest, err := feed.Since("t1", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != 3 || len(changes) != 3 {
+		t.Fatalf("Expected 3 changes for t1, got %d (latest %d)", len(changes), latest)
+	}
+	for i, want := range []string{ChangeCreate, ChangeUpdate, ChangeDelete} {
+		if changes[i].Seq != uint64(i+1) || changes[i].Type != want {
+			t.Errorf("Change %d: got seq %d type %s, want %s", i, changes[i].Seq, changes[i].Type, want)
+		}
+	}
+	if changes, _, _ := feed.Since("t1", 1, 1); len(changes) != 1 || changes[0].Seq != 2 {
+		t.Errorf("Expected one change after 1, got %v", changes)
+	}
+	if changes, _, _ := feed.Since("t2", 0, 10); len(changes) != 1 || changes[0].Seq != 1 {
+		t.Errorf("Expected t2 numbered on its own, got %v", changes)
+	}
+	feed.Close()
+
+	// Sequence numbers continue after a reopen
+	feed, err = OpenChangeFeed(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "b"}}})
+	changes, latest, _ = feed.Since("t1", 0, 10)
+	if latest != 4 || len(changes) != 4 || changes[3].Key != "b" {
+		t.Errorf("Expected changes kept across reopen, got %v", changes)
+	}
+}
+
+func TestChangeFeedCarriesTags(t *testing.T) {
+	feed, err := OpenChangeFeed(filepath.Join(t.TempDir(), "changes.log"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+
+	a := ObjectMeta{Tenant: "t1", Key: "a", Attributes: Attributes{Tags: map[string]string{"class": "archive"}}}
+	put, _ := feed.Publish([]Op{{Type: OpPut, Meta: a}})
+	del, _ := feed.Publish([]Op{{Type: OpDelete, Meta: a, Prev: &a}})
+	if put[0].Tags["class"] != "archive" || del[0].Tags != nil {
+		t.Errorf("Tags: put %v, delete %v", put[0].Tags, del[0].Tags)
+	}
+}
+
+func TestChangeFeedRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.log")
+	feed, err := OpenChangeFeed(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("k%d", i)}}})
+	}
+	if feed.GetStats().Compactions.Load() < 2 {
+		t.Error("Expected the file compacted")
+	}
+
+	oldest := feed.Oldest("t1")
+	if oldest > 91 {
+		t.Fatalf("Expected at least 10 changes retained, oldest is %d", oldest)
+	}
+	if _, got, err := feed.Since("t1", 0, 10); !errors.Is(err, ErrChangesExpired) || got != oldest {
+		t.Errorf("Expected ErrChangesExpired naming %d, got %v, %d", oldest, err, got)
+	}
+	if changes, _, err := feed.Since("t1", oldest-1, 1000); err != nil || changes[len(changes)-1].Seq != 100 {
+		t.Errorf("Expected the retained changes, got %v", err)
+	}
+	feed.Close()
+
+	feed, err = OpenChangeFeed(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+	if feed.Oldest("t1") != 91 {
+		t.Errorf("Expected the newest 10 kept after reopen, oldest is %d", feed.Oldest("t1"))
+	}
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "next"}}})
+	if _, latest, _ := feed.Since("t1", 100, 10); latest != 101 {
+		t.Errorf("Expected numbering to continue at 101, got %d", latest)
+	}
+}
+
+func TestChangeFeedWait(t *testing.T) {
+	feed, err := OpenChangeFeed(filepath.Join(t.TempDir(), "changes.log"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if feed.Wait(ctx, "t1", 0) {
+		t.Error("Expected the wait to time out")
+	}
+
+	done := make(chan bool)
+	go func() { done <- feed.Wait(context.Background(), "t1", 0) }()
+	time.Sleep(10 * time.Millisecond)
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t2", Key: "other"}}})
+	feed.Publish([]Op{{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a"}}})
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("Expected the waiter woken by a change")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Waiter not woken")
+	}
+}
+
+func TestIntentLogOnCommit(t *testing.T) {
+	var published [][]Op
+	l, err := OpenIntentLog(filepath.Join(t.TempDir(), "intent.log"), JournalConfig{
+		Durability: DurabilityFlush,
+		OnCommit:   func(ops []Op) { published = append(published, ops) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	txn, _ := l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "aborted"}})
+	txn.Abort()
+	txn, _ = l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "committed"}})
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 || published[0][0].Meta.Key != "committed" {
+		t.Errorf("Expected only the committed transaction published, got %v", published)
+	}
+}
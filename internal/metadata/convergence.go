@@ -0,0 +1,160 @@
+// internal/metadata/convergence.go
+// Tracks index updates that have been applied locally but not yet
+// replicated, so strict readers can wait for cluster-wide convergence
+package metadata
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Convergence assigns each local write a sequence number and records when
+// replication of it completes
+type Convergence struct {
+	seq atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[string]map[uint64]time.Time // Tenant -> seq -> applied at
+	changed chan struct{}                   // Closed and replaced on every completion
+
+	// Statistics (lock-free)
+	completed atomic.Uint64
+	failed    atomic.Uint64
+	waits     atomic.Uint64
+	timeouts  atomic.Uint64
+}
+
+// ConvergenceStats is a point-in-time view of replication convergence
+type ConvergenceStats struct {
+	Pending   int           `json:"pending"`
+	OldestLag time.Duration `json:"oldest_lag_ns"`
+	Completed uint64        `json:"completed"`
+	Failed    uint64        `json:"failed"`
+	Waits     uint64        `json:"strict_waits"`
+	Timeouts  uint64        `json:"strict_timeouts"`
+}
+
+// NewConvergence creates an empty tracker
+func NewConvergence() *Convergence {
+	return &Convergence{
+		pending: make(map[string]map[uint64]time.Time),
+		changed: make(chan struct{}),
+	}
+}
+
+// Begin records a locally applied write awaiting replication
+func (c *Convergence) Begin(tenantID string) uint64 {
+	seq := c.seq.Add(1)
+
+	c.mu.Lock()
+	p := c.pending[tenantID]
+	if p == nil {
+		p = make(map[uint64]time.Time)
+		c.pending[tenantID] = p
+	}
+	p[seq] = time.Now()
+	c.mu.Unlock()
+	return seq
+}
+
+// Done marks a write's replication as finished. A failed replication stops
+// blocking strict readers but is counted.
+func (c *Convergence) Done(tenantID string, seq uint64, ok bool) {
+	if ok {
+		c.completed.Add(1)
+	} else {
+		c.failed.Add(1)
+	}
+
+	c.mu.Lock()
+	if p := c.pending[tenantID]; p != nil {
+		delete(p, seq)
+		if len(p) == 0 {
+			delete(c.pending, tenantID)
+		}
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+	c.mu.Unlock()
+}
+
+// Current returns the latest sequence number handed out
+func (c *Convergence) Current() uint64 {
+	return c.seq.Load()
+}
+
+// Lag returns how long the tenant's oldest unreplicated write has waited
+func (c *Convergence) Lag(tenantID string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var oldest time.Time
+	for _, at := range c.pending[tenantID] {
+		if oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
+
+// blocking reports whether tenantID has a pending write at or before upTo,
+// returning the channel to wait on if so
+func (c *Convergence) blocking(tenantID string, upTo uint64) (chan struct{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for seq := range c.pending[tenantID] {
+		if seq <= upTo {
+			return c.changed, true
+		}
+	}
+	return nil, false
+}
+
+// Wait blocks until every write of tenantID up to sequence upTo has
+// replicated, reporting false if ctx ends first
+func (c *Convergence) Wait(ctx context.Context, tenantID string, upTo uint64) bool {
+	c.waits.Add(1)
+	for {
+		changed, blocked := c.blocking(tenantID, upTo)
+		if !blocked {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			c.timeouts.Add(1)
+			return false
+		}
+	}
+}
+
+// Stats reports pending writes across all tenants
+func (c *Convergence) Stats() ConvergenceStats {
+	c.mu.Lock()
+	st := ConvergenceStats{}
+	var oldest time.Time
+	for _, p := range c.pending {
+		st.Pending += len(p)
+		for _, at := range p {
+			if oldest.IsZero() || at.Before(oldest) {
+				oldest = at
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if !oldest.IsZero() {
+		st.OldestLag = time.Since(oldest)
+	}
+	st.Completed = c.completed.Load()
+	st.Failed = c.failed.Load()
+	st.Waits = c.waits.Load()
+	st.Timeouts = c.timeouts.Load()
+	return st
+}
@@ -0,0 +1,44 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConvergenceWait(t *testing.T) {
+	c := NewConvergence()
+	first := c.Begin("t1")
+	c.Begin("t2")
+	upTo := c.Current()
+	later := c.Begin("t1") // After the reader's snapshot: must not block it
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if c.Wait(ctx, "t1", upTo) {
+		t.Fatal("Wait() converged with a pending write")
+	}
+
+	done := make(chan bool)
+	go func() {
+		done <- c.Wait(context.Background(), "t1", upTo)
+	}()
+	c.Done("t1", first, true)
+
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("Wait() = false after replication finished")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after replication finished")
+	}
+
+	if st := c.Stats(); st.Pending != 2 || st.Completed != 1 || st.Timeouts != 1 {
+		t.Errorf("Stats() = %+v, want 2 pending, 1 completed, 1 timeout", st)
+	}
+	c.Done("t1", later, false)
+	if lag := c.Lag("t1"); lag != 0 {
+		t.Errorf("Lag() = %v, want 0 with nothing pending", lag)
+	}
+}
@@ -0,0 +1,772 @@
+// internal/metadata/index.go
+// Per-tenant ordered key index backing Stat/List and the transactional metadata
+// layer. Entries live in memory until an optional ceiling is reached; each
+// tenant's are then spilled to sorted on-disk segments and compacted.
+package metadata
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ObjectMeta describes a single indexed object
+type ObjectMeta struct {
+	Tenant    string `json:"tenant"`
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	VersionID string `json:"version_id,omitempty"`
+	ModTime   int64  `json:"mod_time"`            // Unix nano
+	Encrypted bool   `json:"encrypted,omitempty"` // Stored sealed under the tenant data key
+	ETag      string `json:"etag,omitempty"`      // Hex MD5 of the plaintext
+
+	// Inline holds the stored bytes of small objects kept in the index (and
+	// journal) instead of the cache tiers
+	Inline []byte `json:"inline,omitempty"`
+
+	// Manifest names the multipart manifest holding the bytes of an object
+	// completed from a multipart upload, which is kept in the part store
+	// instead of the cache tiers
+	Manifest string `json:"manifest,omitempty"`
+
+	// ACL grants access to the object beyond its tenant's policies
+	ACL []Grant `json:"acl,omitempty"`
+
+	Attributes
+}
+
+// Attributes are the user metadata and tags an object is written with
+type Attributes struct {
+	UserMeta map[string]string `json:"user_meta,omitempty"` // x-amz-meta-* names, lowercased and without the prefix
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// size estimates the memory held by a's maps
+func (a Attributes) size() int {
+	n := 0
+	for _, m := range []map[string]string{a.UserMeta, a.Tags} {
+		for k, v := range m {
+			n += len(k) + len(v) + 32
+		}
+	}
+	return n
+}
+
+// Index is an ordered key index partitioned by tenant
+type Index struct {
+	tenants   map[string]*tenantIndex
+	tenantsMu sync.RWMutex
+
+	config   IndexConfig
+	memBytes atomic.Int64 // Estimated size of every tenant's in-memory entries
+	spillMu  sync.Mutex   // One spill or compaction at a time
+	segSeq   atomic.Uint64
+
+	// Statistics (lock-free)
+	stats *IndexStats
+}
+
+// IndexConfig bounds the memory an Index holds
+type IndexConfig struct {
+	// Dir holds spilled segments. Its segments are removed on open, as the
+	// index is rebuilt from the journal.
+	Dir string
+
+	// MemoryBytes is the estimated size of in-memory entries above which
+	// the largest tenant's are spilled to a segment; 0 keeps everything in
+	// memory
+	MemoryBytes int64
+
+	// MaxSegments is the number of segments a tenant may have before they
+	// are compacted into one (default 4)
+	MaxSegments int
+}
+
+type tenantIndex struct {
+	mu       sync.RWMutex
+	entries  map[string]*ObjectMeta // In memory; nil marks a key deleted since it was spilled
+	keys     []string               // Sorted, kept in step with entries
+	memBytes int64                  // Estimated size of entries
+	segments []*segment             // Spilled entries, oldest first
+	objects  int64
+	bytes    int64
+
+	// Running totals per AggregateDelimiter level, see aggregate.go
+	prefixes map[string]*prefixAgg
+}
+
+// IndexStats tracks index activity
+type IndexStats struct {
+	Objects atomic.Int64
+	Bytes   atomic.Int64
+	Puts    atomic.Uint64
+	Deletes atomic.Uint64
+	Lookups atomic.Uint64
+
+	InlineObjects atomic.Int64
+	InlineBytes   atomic.Int64
+
+	MemoryBytes   atomic.Int64  // Estimated size of in-memory entries
+	Segments      atomic.Int64  // Segments on disk
+	SegmentBytes  atomic.Int64  // Their total size
+	Spills        atomic.Uint64 // Tenants' entries written to a segment
+	Compactions   atomic.Uint64 // Tenants' segments merged into one
+	BloomSkips    atomic.Uint64 // Segment lookups a bloom filter answered
+	SegmentReads  atomic.Uint64 // Segment lookups that read from disk
+	SegmentErrors atomic.Uint64 // Failed spills, compactions and reads
+}
+
+// NewIndex creates an empty key index held entirely in memory
+func NewIndex() *Index {
+	return &Index{
+		tenants: make(map[string]*tenantIndex),
+		stats:   &IndexStats{},
+	}
+}
+
+// OpenIndex creates an empty key index that spills to config.Dir above
+// config.MemoryBytes
+func OpenIndex(config IndexConfig) (*Index, error) {
+	if config.MaxSegments <= 0 {
+		config.MaxSegments = 4
+	}
+	if config.MemoryBytes > 0 {
+		if config.Dir == "" {
+			return nil, fmt.Errorf("index segment directory required with a memory ceiling")
+		}
+		if err := os.MkdirAll(config.Dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create index segment directory: %w", err)
+		}
+		stale, _ := filepath.Glob(filepath.Join(config.Dir, "*.seg"))
+		for _, path := range stale {
+			os.Remove(path)
+		}
+	}
+	idx := NewIndex()
+	idx.config = config
+	return idx, nil
+}
+
+// Close removes every segment
+func (idx *Index) Close() error {
+	idx.spillMu.Lock()
+	defer idx.spillMu.Unlock()
+	for _, tenantID := range idx.Tenants() {
+		ti := idx.tenant(tenantID, false)
+		ti.mu.Lock()
+		for _, seg := range ti.segments {
+			seg.remove()
+		}
+		ti.segments = nil
+		ti.mu.Unlock()
+	}
+	idx.stats.Segments.Store(0)
+	idx.stats.SegmentBytes.Store(0)
+	return nil
+}
+
+func (idx *Index) tenant(tenantID string, create bool) *tenantIndex {
+	idx.tenantsMu.RLock()
+	ti := idx.tenants[tenantID]
+	idx.tenantsMu.RUnlock()
+	if ti != nil || !create {
+		return ti
+	}
+
+	idx.tenantsMu.Lock()
+	defer idx.tenantsMu.Unlock()
+	if ti = idx.tenants[tenantID]; ti == nil {
+		ti = &tenantIndex{entries: make(map[string]*ObjectMeta)}
+		idx.tenants[tenantID] = ti
+	}
+	return ti
+}
+
+// entrySize estimates the memory held for an in-memory entry (nil: deleted)
+func entrySize(key string, meta *ObjectMeta) int64 {
+	size := int64(2*len(key) + 64)
+	if meta != nil {
+		size += int64(len(meta.Tenant)+len(meta.VersionID)+len(meta.ETag)+len(meta.Inline)+len(meta.Manifest)+meta.Attributes.size()+aclSize(meta.ACL)) + 96
+	}
+	return size
+}
+
+// lookup returns the live entry for key, searching memory and then segments
+// newest first. Caller holds ti.mu.
+func (ti *tenantIndex) lookup(key string, stats *IndexStats) *ObjectMeta {
+	if meta, ok := ti.entries[key]; ok {
+		return meta
+	}
+	for i := len(ti.segments) - 1; i >= 0; i-- {
+		seg := ti.segments[i]
+		if !seg.bloom.mayContain(key) {
+			stats.BloomSkips.Add(1)
+			continue
+		}
+		stats.SegmentReads.Add(1)
+		rec, err := seg.get(key)
+		if err != nil {
+			stats.SegmentErrors.Add(1)
+			log.Printf("Warning: index segment %s: %v", seg.path, err)
+			continue
+		}
+		if rec != nil {
+			if rec.Deleted {
+				return nil
+			}
+			return &rec.ObjectMeta
+		}
+	}
+	return nil
+}
+
+// spilledMayHold reports whether a segment may hold key. Caller holds ti.mu.
+func (ti *tenantIndex) spilledMayHold(key string) bool {
+	for _, seg := range ti.segments {
+		if seg.bloom.mayContain(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// setEntry stores meta (nil: deleted) for key in memory. Caller holds ti.mu.
+func (idx *Index) setEntry(ti *tenantIndex, key string, meta *ObjectMeta) {
+	old, ok := ti.entries[key]
+	if ok {
+		idx.addMem(ti, -entrySize(key, old))
+	} else {
+		i := sort.SearchStrings(ti.keys, key)
+		ti.keys = append(ti.keys, "")
+		copy(ti.keys[i+1:], ti.keys[i:])
+		ti.keys[i] = key
+	}
+	ti.entries[key] = meta
+	idx.addMem(ti, entrySize(key, meta))
+}
+
+// dropEntry removes key from memory. Caller holds ti.mu.
+func (idx *Index) dropEntry(ti *tenantIndex, key string) {
+	old, ok := ti.entries[key]
+	if !ok {
+		return
+	}
+	delete(ti.entries, key)
+	i := sort.SearchStrings(ti.keys, key)
+	if i < len(ti.keys) && ti.keys[i] == key {
+		ti.keys = append(ti.keys[:i], ti.keys[i+1:]...)
+	}
+	idx.addMem(ti, -entrySize(key, old))
+}
+
+func (idx *Index) addMem(ti *tenantIndex, delta int64) {
+	ti.memBytes += delta
+	idx.memBytes.Add(delta)
+	idx.stats.MemoryBytes.Add(delta)
+}
+
+// Put inserts or replaces an entry, returning the previous one (if any)
+func (idx *Index) Put(meta ObjectMeta) *ObjectMeta {
+	if meta.ModTime == 0 {
+		meta.ModTime = time.Now().UnixNano()
+	}
+	ti := idx.tenant(meta.Tenant, true)
+
+	ti.mu.Lock()
+	prev := ti.lookup(meta.Key, idx.stats)
+	idx.setEntry(ti, meta.Key, &meta)
+	if prev == nil {
+		ti.objects++
+		ti.bytes += meta.Size
+		ti.account(meta.Key, 1, meta.Size)
+	} else {
+		ti.bytes += meta.Size - prev.Size
+		ti.account(meta.Key, 0, meta.Size-prev.Size)
+	}
+	ti.mu.Unlock()
+
+	idx.stats.Puts.Add(1)
+	idx.trackInline(&meta, 1)
+	if prev != nil {
+		idx.trackInline(prev, -1)
+	}
+	idx.enforceCeiling()
+	if prev == nil {
+		idx.stats.Objects.Add(1)
+		idx.stats.Bytes.Add(meta.Size)
+		return nil
+	}
+	idx.stats.Bytes.Add(meta.Size - prev.Size)
+	cp := *prev
+	return &cp
+}
+
+func (idx *Index) trackInline(meta *ObjectMeta, sign int64) {
+	if meta.Inline != nil {
+		idx.stats.InlineObjects.Add(sign)
+		idx.stats.InlineBytes.Add(sign * int64(len(meta.Inline)))
+	}
+}
+
+// Delete removes an entry, returning it if it existed
+func (idx *Index) Delete(tenantID, key string) *ObjectMeta {
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return nil
+	}
+
+	ti.mu.Lock()
+	prev := ti.lookup(key, idx.stats)
+	if prev != nil {
+		// A spilled copy must be shadowed until compaction drops it
+		if ti.spilledMayHold(key) {
+			idx.setEntry(ti, key, nil)
+		} else {
+			idx.dropEntry(ti, key)
+		}
+		ti.objects--
+		ti.bytes -= prev.Size
+		ti.account(key, -1, -prev.Size)
+	}
+	ti.mu.Unlock()
+
+	if prev == nil {
+		return nil
+	}
+	idx.stats.Deletes.Add(1)
+	idx.trackInline(prev, -1)
+	idx.stats.Objects.Add(-1)
+	idx.stats.Bytes.Add(-prev.Size)
+	idx.enforceCeiling()
+	cp := *prev
+	return &cp
+}
+
+// Get returns a copy of the entry for key
+func (idx *Index) Get(tenantID, key string) (*ObjectMeta, error) {
+	idx.stats.Lookups.Add(1)
+
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return nil, fmt.Errorf("object not indexed: %s", key)
+	}
+
+	ti.mu.RLock()
+	meta := ti.lookup(key, idx.stats)
+	ti.mu.RUnlock()
+	if meta == nil {
+		return nil, fmt.Errorf("object not indexed: %s", key)
+	}
+	cp := *meta
+	return &cp, nil
+}
+
+// Usage returns the object count and total bytes indexed for a tenant
+func (idx *Index) Usage(tenantID string) (objects, bytes int64) {
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return 0, 0
+	}
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	return ti.objects, ti.bytes
+}
+
+// List returns a tenant's entries whose key starts with prefix, in key order
+func (idx *Index) List(tenantID, prefix string) []ObjectMeta {
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return nil
+	}
+
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	var out []ObjectMeta
+	for it := ti.iter(prefix, true, idx.stats); ; {
+		meta := it.next()
+		if meta == nil || !strings.HasPrefix(meta.Key, prefix) {
+			break
+		}
+		out = append(out, *meta)
+	}
+	return out
+}
+
+// ListPage returns up to max entries whose key starts with prefix and sorts
+// strictly after marker, and whether more remain. Paging by the last key
+// returned is stable under concurrent mutation: a key present for the whole
+// listing is returned exactly once, keys are strictly increasing across
+// pages, and a key added or removed mid-listing appears at most once.
+func (idx *Index) ListPage(tenantID, prefix, marker string, max int) ([]ObjectMeta, bool) {
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return nil, false
+	}
+
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	start := prefix
+	if marker > prefix {
+		start = marker
+	}
+
+	var out []ObjectMeta
+	for it := ti.iter(start, true, idx.stats); ; {
+		meta := it.next()
+		if meta != nil && meta.Key == marker {
+			continue
+		}
+		if meta == nil || !strings.HasPrefix(meta.Key, prefix) {
+			return out, false
+		}
+		if len(out) == max {
+			return out, true
+		}
+		out = append(out, *meta)
+	}
+}
+
+// DelimitedPage is one page of a delimited listing
+type DelimitedPage struct {
+	Objects        []ObjectMeta
+	CommonPrefixes []string
+	Truncated      bool
+	NextMarker     string // Last object key or common prefix returned
+}
+
+// ListDelimited is ListPage with directory semantics: keys under prefix that
+// contain delimiter after it are rolled up into one common prefix (up to and
+// including the first delimiter), which counts once toward max. Whole
+// rolled-up ranges are skipped by seeking past them rather than walked, and
+// a marker naming a common prefix resumes after every key beneath it.
+func (idx *Index) ListDelimited(tenantID, prefix, delimiter, marker string, max int) DelimitedPage {
+	if delimiter == "" {
+		objects, truncated := idx.ListPage(tenantID, prefix, marker, max)
+		page := DelimitedPage{Objects: objects, Truncated: truncated}
+		if len(objects) > 0 {
+			page.NextMarker = objects[len(objects)-1].Key
+		}
+		return page
+	}
+
+	var page DelimitedPage
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return page
+	}
+
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	start := prefix
+	if marker > prefix {
+		start = marker
+	}
+
+	it := ti.iter(start, true, idx.stats)
+	for {
+		meta := it.next()
+		if meta == nil || !strings.HasPrefix(meta.Key, prefix) {
+			return page
+		}
+		key := meta.Key
+		if key == marker {
+			continue
+		}
+
+		common := ""
+		if n := strings.Index(key[len(prefix):], delimiter); n >= 0 {
+			common = key[:len(prefix)+n+len(delimiter)]
+		}
+		if common != "" && common == marker {
+			it.seekPast(common)
+			continue
+		}
+
+		if len(page.Objects)+len(page.CommonPrefixes) == max {
+			page.Truncated = true
+			return page
+		}
+		if common != "" {
+			page.CommonPrefixes = append(page.CommonPrefixes, common)
+			page.NextMarker = common
+			it.seekPast(common)
+			continue
+		}
+		page.Objects = append(page.Objects, *meta)
+		page.NextMarker = key
+	}
+}
+
+// Tenants returns the IDs of all tenants with an index partition
+func (idx *Index) Tenants() []string {
+	idx.tenantsMu.RLock()
+	defer idx.tenantsMu.RUnlock()
+
+	ids := make([]string, 0, len(idx.tenants))
+	for id := range idx.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Snapshot returns a copy of every entry, ordered by tenant then key
+func (idx *Index) Snapshot() []ObjectMeta {
+	var out []ObjectMeta
+	for _, tenantID := range idx.Tenants() {
+		ti := idx.tenant(tenantID, false)
+		ti.mu.RLock()
+		for it := ti.iter("", true, idx.stats); ; {
+			meta := it.next()
+			if meta == nil {
+				break
+			}
+			out = append(out, *meta)
+		}
+		ti.mu.RUnlock()
+	}
+	return out
+}
+
+// Walk passes every entry to fn in pages of at most n, ordered by tenant
+// then key. Each page is read under its tenant's lock alone, so an entry
+// changed mid-walk may be passed as it was before or after the change.
+func (idx *Index) Walk(n int, fn func(page []ObjectMeta) error) error {
+	for _, tenantID := range idx.Tenants() {
+		for marker := ""; ; {
+			page, more := idx.ListPage(tenantID, "", marker, n)
+			if len(page) > 0 {
+				if err := fn(page); err != nil {
+					return err
+				}
+				marker = page[len(page)-1].Key
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetStats returns index statistics
+func (idx *Index) GetStats() *IndexStats {
+	return idx.stats
+}
+
+// ========== Merged iteration ==========
+
+// indexIter yields a tenant's live entries in key order, merging memory
+// with every segment; the newest copy of a key wins and deleted keys are
+// skipped. It is valid while the caller holds ti.mu.
+type indexIter struct {
+	ti     *tenantIndex
+	stats  *IndexStats
+	memory bool // Whether in-memory entries are merged
+	pos    int  // Next position in ti.keys
+	segs   []*segmentIter
+}
+
+func (ti *tenantIndex) iter(start string, memory bool, stats *IndexStats) *indexIter {
+	it := &indexIter{ti: ti, stats: stats, memory: memory}
+	it.seek(start)
+	return it
+}
+
+// seek repositions the iterator at the first key at or after key
+func (it *indexIter) seek(key string) {
+	if it.memory {
+		it.pos = sort.SearchStrings(it.ti.keys, key)
+	}
+	it.segs = it.segs[:0]
+	for _, seg := range it.ti.segments {
+		it.segs = append(it.segs, seg.iter(key))
+	}
+}
+
+// seekPast skips every key starting with prefix
+func (it *indexIter) seekPast(prefix string) {
+	if end, ok := prefixEnd(prefix); ok {
+		it.seek(end)
+		return
+	}
+	it.pos = len(it.ti.keys)
+	it.segs = nil
+}
+
+// prefixEnd returns the smallest string greater than every string starting
+// with prefix, and false if there is none
+func prefixEnd(prefix string) (string, bool) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			return prefix[:i] + string([]byte{prefix[i] + 1}), true
+		}
+	}
+	return "", false
+}
+
+// next returns the next live entry, or nil at the end
+func (it *indexIter) next() *ObjectMeta {
+	for {
+		// The smallest current key, taking its newest copy: memory is
+		// checked first, then segments newest first, and a source only
+		// replaces the choice with a strictly smaller key
+		var key string
+		var meta *ObjectMeta
+		found, deleted := false, false
+
+		if it.memory && it.pos < len(it.ti.keys) {
+			key, found = it.ti.keys[it.pos], true
+			meta = it.ti.entries[key]
+			deleted = meta == nil
+		}
+		for i := len(it.segs) - 1; i >= 0; i-- {
+			s := it.segs[i]
+			if s.err != nil {
+				it.stats.SegmentErrors.Add(1)
+				log.Printf("Warning: index segment %s: %v", it.ti.segments[i].path, s.err)
+				s.err = nil
+			}
+			if s.cur == nil || (found && s.cur.Key >= key) {
+				continue
+			}
+			key, found = s.cur.Key, true
+			meta, deleted = &s.cur.ObjectMeta, s.cur.Deleted
+		}
+		if !found {
+			return nil
+		}
+		// Step every source past key
+		if it.memory && it.pos < len(it.ti.keys) && it.ti.keys[it.pos] == key {
+			it.pos++
+		}
+		for _, s := range it.segs {
+			if s.cur != nil && s.cur.Key == key {
+				s.advance()
+			}
+		}
+		if !deleted {
+			return meta
+		}
+	}
+}
+
+// ========== Spilling ==========
+
+// enforceCeiling spills the tenants holding the most memory until the index
+// is back under its ceiling
+func (idx *Index) enforceCeiling() {
+	if idx.config.MemoryBytes <= 0 || idx.memBytes.Load() <= idx.config.MemoryBytes {
+		return
+	}
+	idx.spillMu.Lock()
+	defer idx.spillMu.Unlock()
+
+	for idx.memBytes.Load() > idx.config.MemoryBytes {
+		var largest *tenantIndex
+		var most int64
+		for _, tenantID := range idx.Tenants() {
+			ti := idx.tenant(tenantID, false)
+			ti.mu.RLock()
+			if ti.memBytes > most {
+				largest, most = ti, ti.memBytes
+			}
+			ti.mu.RUnlock()
+		}
+		if largest == nil {
+			return
+		}
+
+		largest.mu.Lock()
+		err := idx.spill(largest)
+		largest.mu.Unlock()
+		if err != nil {
+			idx.stats.SegmentErrors.Add(1)
+			log.Printf("Warning: index spill failed: %v", err)
+			return
+		}
+	}
+}
+
+func (idx *Index) segmentPath() string {
+	return filepath.Join(idx.config.Dir, fmt.Sprintf("%08d.seg", idx.segSeq.Add(1)))
+}
+
+// spill writes a tenant's in-memory entries to a new segment, compacting
+// its segments if there are then too many. Caller holds ti.mu.
+func (idx *Index) spill(ti *tenantIndex) error {
+	if len(ti.keys) == 0 {
+		return nil
+	}
+
+	i := 0
+	seg, err := writeSegment(idx.segmentPath(), len(ti.keys), func() (*segmentRecord, bool) {
+		if i == len(ti.keys) {
+			return nil, false
+		}
+		key := ti.keys[i]
+		i++
+		if meta := ti.entries[key]; meta != nil {
+			return &segmentRecord{ObjectMeta: *meta}, true
+		}
+		return &segmentRecord{ObjectMeta: ObjectMeta{Key: key}, Deleted: true}, true
+	})
+	if err != nil {
+		return err
+	}
+
+	ti.segments = append(ti.segments, seg)
+	ti.entries = make(map[string]*ObjectMeta)
+	ti.keys = nil
+	idx.addMem(ti, -ti.memBytes)
+	idx.stats.Spills.Add(1)
+	idx.stats.Segments.Add(1)
+	idx.stats.SegmentBytes.Add(seg.size)
+
+	if len(ti.segments) > idx.config.MaxSegments {
+		return idx.compact(ti)
+	}
+	return nil
+}
+
+// compact merges a tenant's segments into one. As every segment is merged,
+// deleted keys and superseded copies are dropped. Caller holds ti.mu.
+func (idx *Index) compact(ti *tenantIndex) error {
+	var records int
+	var oldBytes int64
+	for _, seg := range ti.segments {
+		records += seg.records
+		oldBytes += seg.size
+	}
+
+	it := ti.iter("", false, idx.stats)
+	seg, err := writeSegment(idx.segmentPath(), records, func() (*segmentRecord, bool) {
+		meta := it.next()
+		if meta == nil {
+			return nil, false
+		}
+		return &segmentRecord{ObjectMeta: *meta}, true
+	})
+	if err != nil {
+		return err
+	}
+
+	old := len(ti.segments)
+	for _, s := range ti.segments {
+		s.remove()
+	}
+	ti.segments = []*segment{seg}
+	idx.stats.Compactions.Add(1)
+	idx.stats.Segments.Add(int64(1 - old))
+	idx.stats.SegmentBytes.Add(seg.size - oldBytes)
+	return nil
+}
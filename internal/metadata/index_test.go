@@ -0,0 +1,172 @@
+package metadata
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestListPageMarker(t *testing.T) {
+	idx := NewIndex()
+	for _, key := range []string{"a", "b/1", "b/2", "b/3", "c"} {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: key})
+	}
+
+	tests := []struct {
+		prefix, marker string
+		max            int
+		want           []string
+		truncated      bool
+	}{
+		{"", "", 10, []string{"a", "b/1", "b/2", "b/3", "c"}, false},
+		{"", "", 2, []string{"a", "b/1"}, true},
+		{"", "b/1", 2, []string{"b/2", "b/3"}, true},
+		{"", "b/3", 2, []string{"c"}, false},
+		{"b/", "", 3, []string{"b/1", "b/2", "b/3"}, false},
+		{"b/", "a", 10, []string{"b/1", "b/2", "b/3"}, false},
+		{"b/", "b/15", 10, []string{"b/2", "b/3"}, false},
+		{"b/", "c", 10, nil, false},
+	}
+	for _, tt := range tests {
+		page, truncated := idx.ListPage("t1", tt.prefix, tt.marker, tt.max)
+		var got []string
+		for _, m := range page {
+			got = append(got, m.Key)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || truncated != tt.truncated {
+			t.Errorf("ListPage(%q, %q, %d) = %v, %v; want %v, %v",
+				tt.prefix, tt.marker, tt.max, got, truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+// Keys present for the whole listing must be returned exactly once, in order,
+// however other keys churn between pages
+func TestListPageStableUnderMutation(t *testing.T) {
+	idx := NewIndex()
+	stable := make(map[string]bool)
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("k%04d", i*2)
+		idx.Put(ObjectMeta{Tenant: "t1", Key: key})
+		stable[key] = true
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; ; n++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			key := fmt.Sprintf("k%04d", (n%500)*2+1)
+			if n%3 == 0 {
+				idx.Delete("t1", key)
+			} else {
+				idx.Put(ObjectMeta{Tenant: "t1", Key: key})
+			}
+		}
+	}()
+
+	seen := make(map[string]int)
+	marker, last := "", ""
+	for {
+		page, truncated := idx.ListPage("t1", "", marker, 7)
+		for _, m := range page {
+			if m.Key <= last {
+				t.Fatalf("key %q listed after %q", m.Key, last)
+			}
+			last = m.Key
+			seen[m.Key]++
+		}
+		if !truncated {
+			break
+		}
+		marker = page[len(page)-1].Key
+	}
+	close(stop)
+	wg.Wait()
+
+	for key := range stable {
+		if seen[key] != 1 {
+			t.Errorf("stable key %q listed %d times", key, seen[key])
+		}
+	}
+}
+
+func TestListDelimited(t *testing.T) {
+	idx := NewIndex()
+	for _, key := range []string{"a", "b/1", "b/2", "b/x/3", "c/1", "c/2", "d"} {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: key})
+	}
+
+	tests := []struct {
+		prefix, marker string
+		max            int
+		objects        []string
+		prefixes       []string
+		truncated      bool
+	}{
+		{"", "", 10, []string{"a", "d"}, []string{"b/", "c/"}, false},
+		{"", "", 2, []string{"a"}, []string{"b/"}, true},
+		{"", "b/", 1, nil, []string{"c/"}, true},
+		{"", "b/", 2, []string{"d"}, []string{"c/"}, false},
+		{"", "c/", 2, []string{"d"}, nil, false},
+		{"b/", "", 10, []string{"b/1", "b/2"}, []string{"b/x/"}, false},
+		{"b/", "b/1", 10, []string{"b/2"}, []string{"b/x/"}, false},
+		{"b/x/", "", 10, []string{"b/x/3"}, nil, false},
+	}
+	for _, tt := range tests {
+		page := idx.ListDelimited("t1", tt.prefix, "/", tt.marker, tt.max)
+		var objects []string
+		for _, m := range page.Objects {
+			objects = append(objects, m.Key)
+		}
+		if fmt.Sprint(objects) != fmt.Sprint(tt.objects) ||
+			fmt.Sprint(page.CommonPrefixes) != fmt.Sprint(tt.prefixes) ||
+			page.Truncated != tt.truncated {
+			t.Errorf("ListDelimited(%q, %q, %d) = %v %v %v; want %v %v %v",
+				tt.prefix, tt.marker, tt.max, objects, page.CommonPrefixes, page.Truncated,
+				tt.objects, tt.prefixes, tt.truncated)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	idx := NewIndex()
+	var want []string
+	for _, tenant := range []string{"t1", "t2"} {
+		for i := 0; i < 7; i++ {
+			key := fmt.Sprintf("k%02d", i)
+			idx.Put(ObjectMeta{Tenant: tenant, Key: key})
+			want = append(want, tenant+"/"+key)
+		}
+	}
+	idx.Delete("t2", "k03")
+	want = append(want[:10], want[11:]...)
+
+	var got []string
+	err := idx.Walk(3, func(page []ObjectMeta) error {
+		if len(page) == 0 || len(page) > 3 {
+			t.Errorf("Page of %d entries, want 1 to 3", len(page))
+		}
+		for _, meta := range page {
+			got = append(got, meta.Tenant+"/"+meta.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+
+	stop := fmt.Errorf("stop")
+	if err := idx.Walk(3, func([]ObjectMeta) error { return stop }); err != stop {
+		t.Errorf("Walk() error = %v, want fn's", err)
+	}
+}
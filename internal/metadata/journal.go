@@ -0,0 +1,868 @@
+// internal/metadata/journal.go
+// Intent log giving composite metadata operations (upload = data + index + quota + replication)
+// all-or-nothing semantics across crashes
+package metadata
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Operation types recorded in an intent
+	OpPut    = "put"
+	OpDelete = "delete"
+
+	// Record kinds
+	recordBegin      = "begin"
+	recordCommit     = "commit"
+	recordAbort      = "abort"
+	recordCheckpoint = "checkpoint"
+
+	// Maximum size of a single encoded record
+	maxRecordBytes = 64 * 1024 * 1024
+
+	// Most index entries, and inline bytes, in one checkpoint record
+	checkpointChunk  = 1024
+	checkpointInline = 16 * 1024 * 1024
+
+	// DefaultCommitWindow bounds how long a record waits for others to
+	// share its fsync
+	DefaultCommitWindow = 2 * time.Millisecond
+
+	// DefaultCommitBatch is the most records committed by one fsync
+	DefaultCommitBatch = 256
+)
+
+// Durability is how far a journal record gets before its append returns
+type Durability int
+
+const (
+	// DurabilityNone buffers records in memory and writes them out once per
+	// commit window; a process crash loses the last window's records
+	DurabilityNone Durability = iota
+
+	// DurabilityFlush writes each record to the OS, surviving a process
+	// crash but not a power loss
+	DurabilityFlush
+
+	// DurabilityFsync returns once the record is on stable storage.
+	// Records appended within one commit window share a single fsync.
+	DurabilityFsync
+)
+
+var durabilityNames = []string{"none", "flush", "fsync"}
+
+func (d Durability) String() string {
+	if d >= 0 && int(d) < len(durabilityNames) {
+		return durabilityNames[d]
+	}
+	return fmt.Sprintf("Durability(%d)", int(d))
+}
+
+// ParseDurability reads a durability level by name
+func ParseDurability(name string) (Durability, error) {
+	for i, n := range durabilityNames {
+		if n == name {
+			return Durability(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown journal durability %q (want one of %s)", name, strings.Join(durabilityNames, ", "))
+}
+
+// JournalConfig configures an intent log
+type JournalConfig struct {
+	Durability Durability
+
+	// CommitWindow is the longest a record waits for others to share its
+	// fsync, or with DurabilityNone its write (default DefaultCommitWindow)
+	CommitWindow time.Duration
+
+	// MaxBatch commits a batch early once it holds this many records
+	// (default DefaultCommitBatch)
+	MaxBatch int
+
+	// ArchiveDir, if set, keeps each journal file a compaction replaces
+	// there as a closed segment for an Archiver to ship
+	ArchiveDir string
+
+	// OnCommit, if set, is called with the ops of each transaction once
+	// its commit record is written
+	OnCommit func(ops []Op)
+}
+
+// CommitBatchBuckets are the upper bounds of IntentLogStats.BatchSizes
+var CommitBatchBuckets = [...]int{1, 2, 4, 8, 16, 32, 64, 128, 256}
+
+// Op is a single index mutation within a transaction.
+// Prev holds the entry being replaced or removed so the op can be undone.
+type Op struct {
+	Type string      `json:"type"`
+	Meta ObjectMeta  `json:"meta"`
+	Prev *ObjectMeta `json:"prev,omitempty"`
+}
+
+// Intent is a transaction as recorded in the log
+type Intent struct {
+	TxnID     uint64 `json:"txn"`
+	Ops       []Op   `json:"ops"`
+	StartedAt int64  `json:"started_at"`
+}
+
+type record struct {
+	Txn  uint64 `json:"txn"`
+	Kind string `json:"kind"`
+	Ops  []Op   `json:"ops,omitempty"`
+	Time int64  `json:"ts"`
+	CRC  uint32 `json:"crc"`
+}
+
+// IntentLog is an append-only, checksummed journal of metadata transactions
+type IntentLog struct {
+	path   string
+	config JournalConfig
+
+	file *os.File
+	buf  *bufio.Writer // Records not yet written, with DurabilityNone
+	mu   sync.Mutex    // Serializes appends and installing a compaction
+
+	compactMu sync.Mutex // One compaction at a time
+
+	// Group commit: records appended since the last commit form batch,
+	// which the committer goroutine syncs once the window ends
+	batch   *commitBatch
+	kick    chan struct{}
+	closing chan struct{}
+	stopped chan struct{}
+
+	nextTxn atomic.Uint64
+	pending map[uint64]*Txn
+	size    atomic.Int64
+
+	// Statistics (lock-free)
+	stats *IntentLogStats
+}
+
+// IntentLogStats tracks journal activity
+type IntentLogStats struct {
+	Begun       atomic.Uint64
+	Committed   atomic.Uint64
+	Aborted     atomic.Uint64
+	Compactions atomic.Uint64
+	Pending     atomic.Int64
+
+	// Group commits, the records they covered and those that failed.
+	// BatchSizes counts commits per CommitBatchBuckets bound, the last
+	// past every bound.
+	CommitBatches atomic.Uint64
+	CommitRecords atomic.Uint64
+	CommitErrors  atomic.Uint64
+	BatchSizes    [len(CommitBatchBuckets) + 1]atomic.Uint64
+}
+
+// commitBatch is the records sharing one group commit
+type commitBatch struct {
+	records int
+	full    chan struct{} // Closed at MaxBatch records
+	done    chan struct{} // Closed once committed
+	err     error
+}
+
+// Txn is an in-flight transaction. Callers register compensating actions
+// with OnAbort as each step succeeds, then Commit or Abort exactly once.
+type Txn struct {
+	ID   uint64
+	Ops  []Op
+	log  *IntentLog
+	undo []func()
+	done atomic.Bool
+}
+
+// RecoveryReport summarizes a log replay
+type RecoveryReport struct {
+	Records    int      `json:"records"`
+	Committed  int      `json:"committed"`
+	Aborted    int      `json:"aborted"`
+	RolledBack []Intent `json:"rolled_back"`
+	Corrupt    int      `json:"corrupt"`
+	TornTail   bool     `json:"torn_tail"`
+}
+
+// OpenIntentLog opens (creating if necessary) the journal at path
+func OpenIntentLog(path string, config JournalConfig) (*IntentLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat journal: %w", err)
+	}
+
+	if config.CommitWindow <= 0 {
+		config.CommitWindow = DefaultCommitWindow
+	}
+	if config.MaxBatch <= 0 {
+		config.MaxBatch = DefaultCommitBatch
+	}
+
+	l := &IntentLog{
+		path:    path,
+		config:  config,
+		file:    file,
+		pending: make(map[uint64]*Txn),
+		stats:   &IntentLogStats{},
+	}
+	l.size.Store(info.Size())
+	l.nextTxn.Store(uint64(time.Now().UnixNano()))
+
+	if config.Durability != DurabilityFlush {
+		if config.Durability == DurabilityNone {
+			l.buf = bufio.NewWriter(file)
+		}
+		l.kick = make(chan struct{}, 1)
+		l.closing = make(chan struct{})
+		l.stopped = make(chan struct{})
+		go l.committer()
+	}
+
+	return l, nil
+}
+
+// Begin records the intent to apply ops and returns the open transaction
+func (l *IntentLog) Begin(ops ...Op) (*Txn, error) {
+	txn := &Txn{
+		ID:  l.nextTxn.Add(1),
+		Ops: ops,
+		log: l,
+	}
+
+	// Pending before the record is durable, so a compaction meanwhile
+	// carries it over
+	l.mu.Lock()
+	batch, err := l.appendLocked(record{Txn: txn.ID, Kind: recordBegin, Ops: ops})
+	if err == nil {
+		l.pending[txn.ID] = txn
+	}
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.wait(); err != nil {
+		l.mu.Lock()
+		delete(l.pending, txn.ID)
+		l.mu.Unlock()
+		return nil, err
+	}
+
+	l.stats.Begun.Add(1)
+	l.stats.Pending.Add(1)
+	return txn, nil
+}
+
+// OnAbort registers a compensating action run (in reverse order) on Abort
+func (t *Txn) OnAbort(fn func()) {
+	t.undo = append(t.undo, fn)
+}
+
+// Commit durably marks the transaction as complete. If the commit record
+// cannot be written the compensations are run and an error is returned;
+// recovery will roll the transaction back on the next start.
+func (t *Txn) Commit() error {
+	if !t.done.CompareAndSwap(false, true) {
+		return fmt.Errorf("transaction %d already finished", t.ID)
+	}
+	if err := t.log.finish(t.ID, recordCommit); err != nil {
+		t.runUndo()
+		t.log.stats.Aborted.Add(1)
+		return fmt.Errorf("commit of transaction %d failed: %w", t.ID, err)
+	}
+	t.log.stats.Committed.Add(1)
+	if t.log.config.OnCommit != nil {
+		t.log.config.OnCommit(t.Ops)
+	}
+	return nil
+}
+
+// Abort runs the registered compensations and marks the transaction as rolled back
+func (t *Txn) Abort() error {
+	if !t.done.CompareAndSwap(false, true) {
+		return fmt.Errorf("transaction %d already finished", t.ID)
+	}
+	t.runUndo()
+	if err := t.log.finish(t.ID, recordAbort); err != nil {
+		return err
+	}
+	t.log.stats.Aborted.Add(1)
+	return nil
+}
+
+func (t *Txn) runUndo() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+}
+
+func (l *IntentLog) finish(txnID uint64, kind string) error {
+	l.mu.Lock()
+	delete(l.pending, txnID)
+	l.stats.Pending.Add(-1)
+	batch, err := l.appendLocked(record{Txn: txnID, Kind: kind})
+	l.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return batch.wait()
+}
+
+// appendLocked writes rec and, with DurabilityFsync, returns the batch to
+// wait on before it is durable
+func (l *IntentLog) appendLocked(rec record) (*commitBatch, error) {
+	line, err := encodeRecord(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	if l.buf != nil {
+		n, err = l.buf.Write(line)
+	} else {
+		n, err = l.file.Write(line)
+	}
+	l.size.Add(int64(n))
+	if err != nil {
+		return nil, fmt.Errorf("journal write failed: %w", err)
+	}
+	if l.config.Durability == DurabilityFlush {
+		return nil, nil
+	}
+
+	b := l.batch
+	if b == nil {
+		b = &commitBatch{full: make(chan struct{}), done: make(chan struct{})}
+		l.batch = b
+		select {
+		case l.kick <- struct{}{}:
+		default:
+		}
+	}
+	b.records++
+	if b.records == l.config.MaxBatch {
+		close(b.full)
+	}
+	if l.config.Durability == DurabilityNone {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// wait blocks until the batch is committed; a nil batch needs no wait
+func (b *commitBatch) wait() error {
+	if b == nil {
+		return nil
+	}
+	<-b.done
+	return b.err
+}
+
+func (b *commitBatch) finish(err error) {
+	b.err = err
+	close(b.done)
+}
+
+// committer commits each batch once its window ends or it fills
+func (l *IntentLog) committer() {
+	defer close(l.stopped)
+	for {
+		select {
+		case <-l.kick:
+		case <-l.closing:
+			return
+		}
+
+		l.mu.Lock()
+		b := l.batch
+		l.mu.Unlock()
+		if b == nil {
+			continue // Taken by a compaction
+		}
+
+		timer := time.NewTimer(l.config.CommitWindow)
+		select {
+		case <-timer.C:
+		case <-b.full:
+		case <-l.closing:
+			timer.Stop()
+			return // Close commits the rest
+		}
+		timer.Stop()
+		l.commit()
+	}
+}
+
+// commit writes out and, with DurabilityFsync, syncs the open batch. The
+// sync runs outside the lock so appends continue into the next batch.
+func (l *IntentLog) commit() {
+	l.mu.Lock()
+	b := l.batch
+	l.batch = nil
+	file := l.file
+	err := l.flushLocked()
+	l.mu.Unlock()
+	if b == nil {
+		return
+	}
+
+	if err == nil && l.config.Durability == DurabilityFsync {
+		if err = file.Sync(); err != nil {
+			// A compaction replacing the file meanwhile made the batch durable
+			l.mu.Lock()
+			if l.file != file {
+				err = nil
+			}
+			l.mu.Unlock()
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("journal sync failed: %w", err)
+		l.stats.CommitErrors.Add(1)
+	}
+	l.stats.recordBatch(b.records)
+	b.finish(err)
+}
+
+// flushLocked writes out records buffered with DurabilityNone
+func (l *IntentLog) flushLocked() error {
+	if l.buf == nil {
+		return nil
+	}
+	if err := l.buf.Flush(); err != nil {
+		return fmt.Errorf("journal write failed: %w", err)
+	}
+	return nil
+}
+
+func (s *IntentLogStats) recordBatch(records int) {
+	s.CommitBatches.Add(1)
+	s.CommitRecords.Add(uint64(records))
+	i := 0
+	for i < len(CommitBatchBuckets) && records > CommitBatchBuckets[i] {
+		i++
+	}
+	s.BatchSizes[i].Add(1)
+}
+
+// Recover replays the journal into idx: committed transactions are applied,
+// transactions without a commit or abort record are rolled back and marked aborted.
+func (l *IntentLog) Recover(idx *Index) (*RecoveryReport, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report, incomplete, validEnd, err := replayFile(l.path, idx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the torn record so new appends start on a clean line
+	if report.TornTail {
+		if err := l.file.Truncate(validEnd); err != nil {
+			return report, fmt.Errorf("failed to truncate torn journal tail: %w", err)
+		}
+		l.size.Store(validEnd)
+	}
+
+	// The abort records are committed with the next batch; nothing waits
+	for _, intent := range incomplete {
+		undoOps(idx, intent.Ops)
+		if _, err := l.appendLocked(record{Txn: intent.TxnID, Kind: recordAbort}); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// Compact rewrites the journal as a checkpoint of idx followed by the
+// records the checkpoint may not reflect: the begin records of the
+// transactions pending when it starts, then every record appended since,
+// which replay over it. Replay undoes the transactions among them that
+// abort, as the checkpoint may hold their changes. The checkpoint is written in chunks without the
+// journal lock, so appends block only while those records are copied and
+// the new file installed.
+func (l *IntentLog) Compact(idx *Index) error {
+	l.compactMu.Lock()
+	defer l.compactMu.Unlock()
+
+	l.mu.Lock()
+	err := l.flushLocked()
+	start := l.size.Load()
+	pending := make([]record, 0, len(l.pending))
+	for _, txn := range l.pending {
+		pending = append(pending, record{Txn: txn.ID, Kind: recordBegin, Ops: txn.Ops})
+	}
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Txn < pending[j].Txn })
+
+	tmpPath := l.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted journal: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	var size int64
+	write := func(rec record) error {
+		line, err := encodeRecord(rec)
+		if err != nil {
+			return err
+		}
+		n, err := w.Write(line)
+		size += int64(n)
+		return err
+	}
+
+	// An empty index still leaves a checkpoint, marking where replay starts
+	chunks := 0
+	err = idx.Walk(checkpointChunk, func(page []ObjectMeta) error {
+		// Inline objects are split across records to stay under the cap
+		var ops []Op
+		var bytes int
+		for i, meta := range page {
+			ops = append(ops, Op{Type: OpPut, Meta: meta})
+			bytes += len(meta.Inline)
+			if i < len(page)-1 && bytes < checkpointInline {
+				continue
+			}
+			chunks++
+			if err := write(record{Kind: recordCheckpoint, Ops: ops}); err != nil {
+				return err
+			}
+			ops, bytes = ops[:0], 0
+		}
+		return nil
+	})
+	if err == nil && chunks == 0 {
+		err = write(record{Kind: recordCheckpoint})
+	}
+	for _, rec := range pending {
+		if err != nil {
+			break
+		}
+		err = write(rec)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Records buffered for the file being replaced belong in its segment
+	if err == nil {
+		err = l.flushLocked()
+	}
+	if err == nil {
+		var n int64
+		n, err = l.copyTailLocked(w, start)
+		size += n
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write compacted journal: %w", err)
+	}
+
+	if l.config.ArchiveDir != "" {
+		if err := l.closeSegmentLocked(); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to install compacted journal: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to reopen journal: %w", err)
+	}
+	l.file.Close()
+	l.file = file
+	l.size.Store(size)
+	l.stats.Compactions.Add(1)
+
+	// Uncommitted records are superseded by the synced copy
+	if l.buf != nil {
+		l.buf.Reset(file)
+	}
+	if b := l.batch; b != nil {
+		l.batch = nil
+		b.finish(nil)
+	}
+
+	// Until the directory is synced a crash may bring back the old file
+	if err := syncDir(filepath.Dir(l.path)); err != nil {
+		return fmt.Errorf("failed to sync journal directory: %w", err)
+	}
+	return nil
+}
+
+// copyTailLocked copies the journal from offset start to w. Caller holds
+// l.mu, with nothing buffered.
+func (l *IntentLog) copyTailLocked(w io.Writer, start int64) (int64, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, io.NewSectionReader(f, start, l.size.Load()-start))
+}
+
+// syncDir makes the entries created or renamed in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// closeSegmentLocked links the journal file about to be replaced into
+// ArchiveDir, named for the time it closes
+func (l *IntentLog) closeSegmentLocked() error {
+	if l.size.Load() == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(l.config.ArchiveDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create journal archive spool: %w", err)
+	}
+	name := filepath.Join(l.config.ArchiveDir, archiveName(time.Now()))
+	if err := os.Link(l.path, name); err != nil {
+		return fmt.Errorf("failed to keep closed journal segment: %w", err)
+	}
+	return nil
+}
+
+// Size returns the current journal size in bytes
+func (l *IntentLog) Size() int64 {
+	return l.size.Load()
+}
+
+// PendingKeys returns the tenant/key pairs touched by in-flight transactions
+func (l *IntentLog) PendingKeys() map[string]bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make(map[string]bool)
+	for _, txn := range l.pending {
+		for _, op := range txn.Ops {
+			keys[op.Meta.Tenant+"/"+op.Meta.Key] = true
+		}
+	}
+	return keys
+}
+
+// GetStats returns journal statistics
+func (l *IntentLog) GetStats() *IntentLogStats {
+	return l.stats
+}
+
+// Close commits outstanding records and closes the journal
+func (l *IntentLog) Close() error {
+	if l.closing != nil {
+		close(l.closing)
+		<-l.stopped
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.flushLocked()
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if b := l.batch; b != nil {
+		l.batch = nil
+		b.finish(err)
+	}
+	if err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// ========== Encoding & Replay ==========
+
+func encodeRecord(rec record) ([]byte, error) {
+	rec.Time = time.Now().UnixNano()
+	rec.CRC = 0
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal record: %w", err)
+	}
+	rec.CRC = crc32.ChecksumIEEE(body)
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal record: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+func decodeRecord(line []byte) (record, bool) {
+	var rec record
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return rec, false
+	}
+	crc := rec.CRC
+	rec.CRC = 0
+	body, err := json.Marshal(rec)
+	if err != nil || crc32.ChecksumIEEE(body) != crc {
+		return rec, false
+	}
+	rec.CRC = crc
+	return rec, true
+}
+
+// replayFile applies the journal at path to idx and returns the transactions
+// that were begun but never finished (in log order) and the offset just past
+// the last valid record.
+func replayFile(path string, idx *Index) (*RecoveryReport, []Intent, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+	return replay(file, idx, 0)
+}
+
+// replay applies journal records read from r to idx, stopping before the
+// first record written after until (unix nanoseconds) if it is non-zero
+func replay(r io.Reader, idx *Index, until int64) (*RecoveryReport, []Intent, int64, error) {
+	report := &RecoveryReport{}
+	begun := make(map[uint64]Intent)
+	var order []uint64
+
+	// A checkpoint may hold the changes of transactions still pending when
+	// it was taken, so those that abort after it are undone
+	checkpointed := false
+	undoable := make(map[uint64]bool)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordBytes)
+
+	var offset, validEnd int64
+	lastBad := false
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		offset += int64(len(line)) + 1
+		if len(line) == 0 {
+			continue
+		}
+
+		rec, ok := decodeRecord(line)
+		if !ok {
+			report.Corrupt++
+			lastBad = true
+			continue
+		}
+		lastBad = false
+		if until != 0 && rec.Time > until {
+			break
+		}
+		validEnd = offset
+		report.Records++
+
+		switch rec.Kind {
+		case recordCheckpoint:
+			applyOps(idx, rec.Ops)
+			checkpointed = true
+		case recordBegin:
+			begun[rec.Txn] = Intent{TxnID: rec.Txn, Ops: rec.Ops, StartedAt: rec.Time}
+			undoable[rec.Txn] = checkpointed
+			order = append(order, rec.Txn)
+		case recordCommit:
+			if intent, ok := begun[rec.Txn]; ok {
+				applyOps(idx, intent.Ops)
+				delete(begun, rec.Txn)
+				delete(undoable, rec.Txn)
+				report.Committed++
+			}
+		case recordAbort:
+			if intent, ok := begun[rec.Txn]; ok {
+				if undoable[rec.Txn] {
+					undoOps(idx, intent.Ops)
+				}
+				delete(begun, rec.Txn)
+				delete(undoable, rec.Txn)
+				report.Aborted++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	// A bad final record is a write torn by the crash, not corruption
+	if lastBad {
+		report.Corrupt--
+		report.TornTail = true
+	}
+
+	var incomplete []Intent
+	for _, txnID := range order {
+		if intent, ok := begun[txnID]; ok {
+			incomplete = append(incomplete, intent)
+		}
+	}
+	report.RolledBack = incomplete
+
+	return report, incomplete, validEnd, nil
+}
+
+func applyOps(idx *Index, ops []Op) {
+	for _, op := range ops {
+		switch op.Type {
+		case OpPut:
+			idx.Put(op.Meta)
+		case OpDelete:
+			idx.Delete(op.Meta.Tenant, op.Meta.Key)
+		}
+	}
+}
+
+func undoOps(idx *Index, ops []Op) {
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		if op.Prev != nil {
+			idx.Put(*op.Prev)
+		} else if op.Type == OpPut {
+			idx.Delete(op.Meta.Tenant, op.Meta.Key)
+		}
+	}
+}
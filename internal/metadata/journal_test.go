@@ -0,0 +1,395 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIntentLogRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	if err != nil {
+		t.Fatalf("OpenIntentLog() error = %v", err)
+	}
+
+	committed, _ := l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a", Size: 10}})
+	if err := committed.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	aborted, _ := l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "b", Size: 20}})
+	undone := false
+	aborted.OnAbort(func() { undone = true })
+	if err := aborted.Abort(); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	if !undone {
+		t.Error("Abort() did not run compensation")
+	}
+
+	// Simulate a crash: begun but never finished, followed by a torn write
+	prev := &ObjectMeta{Tenant: "t1", Key: "a", Size: 10}
+	l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a", Size: 99}, Prev: prev})
+	l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "c", Size: 5}})
+	l.file.Write([]byte(`{"txn":1,"kind":"comm`))
+	l.file.Close()
+
+	l, err = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	idx := NewIndex()
+	report, err := l.Recover(idx)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	if report.Committed != 1 || report.Aborted != 1 || len(report.RolledBack) != 2 {
+		t.Errorf("report = %+v, want 1 committed, 1 aborted, 2 rolled back", report)
+	}
+	if !report.TornTail || report.Corrupt != 0 {
+		t.Errorf("torn tail not detected: %+v", report)
+	}
+
+	meta, err := idx.Get("t1", "a")
+	if err != nil || meta.Size != 10 {
+		t.Errorf("Get(a) = %+v, %v; want size 10", meta, err)
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, err := idx.Get("t1", key); err == nil {
+			t.Errorf("Get(%s) found a rolled back object", key)
+		}
+	}
+
+	// Compaction preserves state and the log stays replayable
+	if err := l.Compact(idx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	if len(data) == 0 {
+		t.Fatal("compacted journal is empty")
+	}
+
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	replayed := NewIndex()
+	if _, err := l.Recover(replayed); err != nil {
+		t.Fatalf("Recover() after compaction error = %v", err)
+	}
+	check, err := CheckIndex(l, replayed)
+	if err != nil {
+		t.Fatalf("CheckIndex() error = %v", err)
+	}
+	if len(check.Discrepancies) != 0 || check.Checked != 1 {
+		t.Errorf("CheckIndex() = %+v, want 1 checked and no discrepancies", check)
+	}
+}
+
+func TestIntentLogGroupCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityFsync, CommitWindow: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewIndex()
+	var wg sync.WaitGroup
+	for w := 0; w < 32; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				meta := ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("w%d/%d", w, i), Size: 1}
+				txn, err := l.Begin(Op{Type: OpPut, Meta: meta})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				idx.Put(meta)
+				if err := txn.Commit(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	// Compactions racing the committer must not lose or fail records
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+		if err := l.Compact(idx); err != nil {
+			t.Errorf("Compact() error = %v", err)
+		}
+	}
+	wg.Wait()
+
+	stats := l.GetStats()
+	if stats.CommitErrors.Load() != 0 {
+		t.Errorf("Expected no commit errors, got %d", stats.CommitErrors.Load())
+	}
+	batches, records := stats.CommitBatches.Load(), stats.CommitRecords.Load()
+	if batches == 0 || batches >= records {
+		t.Errorf("Expected records to share fsyncs, got %d batches for %d records", batches, records)
+	}
+	var bucketed uint64
+	for i := range stats.BatchSizes {
+		bucketed += stats.BatchSizes[i].Load()
+	}
+	if bucketed != batches {
+		t.Errorf("Expected every batch in a size bucket, got %d of %d", bucketed, batches)
+	}
+	l.Close()
+
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	replayed := NewIndex()
+	if _, err := l.Recover(replayed); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := replayed.Usage("t1"); objects != 640 {
+		t.Errorf("Expected 640 committed objects replayed, got %d", objects)
+	}
+}
+
+func TestIntentLogDurabilityNone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityNone, CommitWindow: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, _ := l.Begin(Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a"}})
+	txn.Commit()
+
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("Expected records buffered until the window ends, found %d bytes", len(data))
+	}
+	idx := NewIndex()
+	idx.Put(ObjectMeta{Tenant: "t1", Key: "a"})
+	if check, err := CheckIndex(l, idx); err != nil || len(check.Discrepancies) != 0 {
+		t.Errorf("Expected CheckIndex to see buffered records, got %+v, %v", check, err)
+	}
+	l.Close()
+
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	replayed := NewIndex()
+	if report, err := l.Recover(replayed); err != nil || report.Committed != 1 {
+		t.Errorf("Expected Close to write out the commit, got %+v, %v", report, err)
+	}
+}
+
+func TestParseDurability(t *testing.T) {
+	for _, d := range []Durability{DurabilityNone, DurabilityFlush, DurabilityFsync} {
+		if got, err := ParseDurability(d.String()); err != nil || got != d {
+			t.Errorf("ParseDurability(%s) = %v, %v", d, got, err)
+		}
+	}
+	if _, err := ParseDurability("always"); err == nil {
+		t.Error("Expected error for an unknown level")
+	}
+}
+
+func TestIntentLogCompactConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enough entries for three checkpoint records, the first split in two
+	// by inline bytes
+	idx := NewIndex()
+	for i := 0; i < 3*checkpointChunk; i++ {
+		meta := ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("base/%05d", i), Size: 1}
+		if i < 2 {
+			meta.Inline = make([]byte, checkpointInline/2+1)
+		}
+		idx.Put(meta)
+	}
+	if err := l.Compact(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writers overwrite and delete keys until the compactions are done
+	var wg sync.WaitGroup
+	var mu sync.Mutex // Orders each index change with its commit
+	var writes atomic.Int64
+	done := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				meta := ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("w%d/%03d", w, i%500), Size: int64(w<<20 + i)}
+				op := Op{Type: OpPut, Meta: meta}
+				if i%3 == 0 {
+					op.Type = OpDelete
+				}
+				mu.Lock()
+				txn, err := l.Begin(op)
+				if err == nil {
+					applyOps(idx, []Op{op})
+					err = txn.Commit()
+				}
+				mu.Unlock()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				writes.Add(1)
+			}
+		}(w)
+	}
+	for writes.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		if err := l.Compact(idx); err != nil {
+			t.Errorf("Compact() error = %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), `"kind":"checkpoint"`); n < 4 {
+		t.Errorf("Checkpoint written as %d records, want at least 4", n)
+	}
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	if _, err := l.Recover(NewIndex()); err != nil {
+		t.Fatal(err)
+	}
+	if check, err := CheckIndex(l, idx); err != nil || len(check.Discrepancies) != 0 {
+		t.Errorf("CheckIndex() = %+v, %v; want no discrepancies", check, err)
+	}
+}
+
+// A transaction pending while the journal is compacted, then aborted, is
+// undone on replay although the checkpoint holds its changes
+func TestIntentLogCompactPendingAbort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewIndex()
+	kept := ObjectMeta{Tenant: "t1", Key: "a", Size: 10}
+	txn, _ := l.Begin(Op{Type: OpPut, Meta: kept})
+	idx.Put(kept)
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := []Op{
+		{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a", Size: 99}, Prev: &kept},
+		{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "b", Size: 20}},
+	}
+	txn, err = l.Begin(ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyOps(idx, ops)
+	txn.OnAbort(func() { undoOps(idx, ops) })
+	if err := l.Compact(idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	replayed := NewIndex()
+	report, err := l.Recover(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Aborted != 1 || len(report.RolledBack) != 0 {
+		t.Errorf("report = %+v, want 1 aborted and none rolled back", report)
+	}
+	if meta, err := replayed.Get("t1", "a"); err != nil || meta.Size != 10 {
+		t.Errorf("Get(a) = %+v, %v; want size 10", meta, err)
+	}
+	if _, err := replayed.Get("t1", "b"); err == nil {
+		t.Error("Aborted object came back after compaction")
+	}
+	if check, err := CheckIndex(l, idx); err != nil || len(check.Discrepancies) != 0 {
+		t.Errorf("CheckIndex() = %+v, %v; want no discrepancies", check, err)
+	}
+}
+
+// A transaction that aborts while a compaction is writing a checkpoint
+// holding its change is undone on replay
+func TestIntentLogAbortDuringCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intent.log")
+	l, err := OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first checkpoint record holds "a" and is large enough to land in
+	// the compacted file on its own, well before the walk is done
+	idx := NewIndex()
+	for i := 0; i < 3*checkpointChunk; i++ {
+		meta := ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("base/%05d", i), Size: 1}
+		if i < 2 {
+			meta.Inline = make([]byte, checkpointInline/2+1)
+		}
+		idx.Put(meta)
+	}
+	op := Op{Type: OpPut, Meta: ObjectMeta{Tenant: "t1", Key: "a", Size: 20}}
+	txn, err := l.Begin(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyOps(idx, []Op{op})
+	txn.OnAbort(func() { undoOps(idx, []Op{op}) })
+
+	aborted := make(chan error, 1)
+	go func() {
+		for {
+			if info, err := os.Stat(path + ".compact"); err == nil && info.Size() > 0 {
+				break
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+		aborted <- txn.Abort()
+	}()
+	if err := l.Compact(idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-aborted; err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	l, _ = OpenIntentLog(path, JournalConfig{Durability: DurabilityFlush})
+	defer l.Close()
+	replayed := NewIndex()
+	if _, err := l.Recover(replayed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replayed.Get("t1", "a"); err == nil {
+		t.Error("Object aborted during compaction came back on replay")
+	}
+	if check, err := CheckIndex(l, idx); err != nil || len(check.Discrepancies) != 0 {
+		t.Errorf("CheckIndex() = %+v, %v; want no discrepancies", check, err)
+	}
+}
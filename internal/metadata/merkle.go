@@ -0,0 +1,162 @@
+// internal/metadata/merkle.go
+// Merkle trees over a tenant's objects for anti-entropy between regions:
+// keys are spread over 2^depth leaves by hash, so trees built from the same
+// objects in different regions match leaf for leaf, and comparing two trees
+// from the root finds the differing key ranges without listing the rest
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+)
+
+// Merkle tree depths
+const (
+	DefaultMerkleDepth = 10
+	MaxMerkleDepth     = 16
+)
+
+// merkleDigestBytes is the length digests are truncated to
+const merkleDigestBytes = 16
+
+// ErrMerkleMismatch is returned when comparing trees of different shapes
+var ErrMerkleMismatch = errors.New("merkle trees differ in depth")
+
+// MerkleTree summarizes a tenant's key/version pairs. Nodes holds hex
+// digests in heap order: node i's children are 2i+1 and 2i+2, and the last
+// 1<<Depth nodes are the leaves.
+type MerkleTree struct {
+	Depth int      `json:"depth"`
+	Nodes []string `json:"nodes"`
+}
+
+// KeyVersion is an object's key and current version
+type KeyVersion struct {
+	Key       string `json:"key"`
+	VersionID string `json:"version_id"`
+	ModTime   int64  `json:"mod_time"` // Unix nano
+}
+
+// MerkleLeaf returns the leaf key falls in, in a tree of depth
+func MerkleLeaf(key string, depth int) int {
+	sum := sha256.Sum256([]byte(key))
+	return int(uint64(binary.BigEndian.Uint32(sum[:4])) >> (32 - depth))
+}
+
+// clampDepth bounds depth to the supported range
+func clampDepth(depth int) int {
+	if depth <= 0 {
+		return DefaultMerkleDepth
+	}
+	return min(depth, MaxMerkleDepth)
+}
+
+// Merkle builds tenantID's tree. Depths outside 1..MaxMerkleDepth are
+// clamped, 0 meaning DefaultMerkleDepth.
+func (idx *Index) Merkle(tenantID string, depth int) *MerkleTree {
+	depth = clampDepth(depth)
+	leaves := 1 << depth
+	hashes := make([]hash.Hash, leaves)
+
+	if ti := idx.tenant(tenantID, false); ti != nil {
+		ti.mu.RLock()
+		// Keys arrive sorted, so every leaf hashes its keys in the same order
+		for it := ti.iter("", true, idx.stats); ; {
+			meta := it.next()
+			if meta == nil {
+				break
+			}
+			leaf := MerkleLeaf(meta.Key, depth)
+			if hashes[leaf] == nil {
+				hashes[leaf] = sha256.New()
+			}
+			h := hashes[leaf]
+			h.Write([]byte(meta.Key))
+			h.Write([]byte{0})
+			h.Write([]byte(meta.VersionID))
+			h.Write([]byte{0})
+		}
+		ti.mu.RUnlock()
+	}
+
+	nodes := make([][]byte, 2*leaves-1)
+	empty := sha256.Sum256(nil)
+	for i, h := range hashes {
+		if h == nil {
+			nodes[leaves-1+i] = empty[:merkleDigestBytes]
+		} else {
+			nodes[leaves-1+i] = h.Sum(nil)[:merkleDigestBytes]
+		}
+	}
+	for i := leaves - 2; i >= 0; i-- {
+		sum := sha256.Sum256(append(append([]byte(nil), nodes[2*i+1]...), nodes[2*i+2]...))
+		nodes[i] = sum[:merkleDigestBytes]
+	}
+
+	tree := &MerkleTree{Depth: depth, Nodes: make([]string, len(nodes))}
+	for i, n := range nodes {
+		tree.Nodes[i] = hex.EncodeToString(n)
+	}
+	return tree
+}
+
+// MerkleLeafEntries returns the objects in one leaf of tenantID's tree of
+// depth, in key order
+func (idx *Index) MerkleLeafEntries(tenantID string, depth, leaf int) []KeyVersion {
+	depth = clampDepth(depth)
+	ti := idx.tenant(tenantID, false)
+	if ti == nil {
+		return nil
+	}
+
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	var out []KeyVersion
+	for it := ti.iter("", true, idx.stats); ; {
+		meta := it.next()
+		if meta == nil {
+			return out
+		}
+		if MerkleLeaf(meta.Key, depth) == leaf {
+			out = append(out, KeyVersion{Key: meta.Key, VersionID: meta.VersionID, ModTime: meta.ModTime})
+		}
+	}
+}
+
+// Root returns the tree's root digest
+func (t *MerkleTree) Root() string {
+	if len(t.Nodes) == 0 {
+		return ""
+	}
+	return t.Nodes[0]
+}
+
+// Diff returns the leaves whose digests differ between t and other,
+// descending only into subtrees whose digests differ
+func (t *MerkleTree) Diff(other *MerkleTree) ([]int, error) {
+	size := 2<<t.Depth - 1
+	if t.Depth != other.Depth || len(t.Nodes) != size || len(other.Nodes) != size {
+		return nil, ErrMerkleMismatch
+	}
+
+	firstLeaf := 1<<t.Depth - 1
+	var leaves []int
+	var walk func(i int)
+	walk = func(i int) {
+		if t.Nodes[i] == other.Nodes[i] {
+			return
+		}
+		if i >= firstLeaf {
+			leaves = append(leaves, i-firstLeaf)
+			return
+		}
+		walk(2*i + 1)
+		walk(2*i + 2)
+	}
+	walk(0)
+	return leaves, nil
+}
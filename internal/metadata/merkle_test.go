@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func merkleIndex(n int) *Index {
+	idx := NewIndex()
+	for i := 0; i < n; i++ {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("obj/%05d", i), VersionID: "v1"})
+	}
+	return idx
+}
+
+func TestMerkleMatchingIndexesAgree(t *testing.T) {
+	a, b := merkleIndex(2000), merkleIndex(2000)
+	ta, tb := a.Merkle("t1", 8), b.Merkle("t1", 8)
+	if ta.Root() != tb.Root() {
+		t.Fatal("Identical indexes have different roots")
+	}
+	if leaves, err := ta.Diff(tb); err != nil || len(leaves) != 0 {
+		t.Errorf("Diff = %v, %v; want no leaves", leaves, err)
+	}
+	if empty := NewIndex().Merkle("t1", 8); empty.Root() == ta.Root() {
+		t.Error("Empty tenant has the same root as a populated one")
+	}
+}
+
+func TestMerkleDiffFindsDivergentLeaves(t *testing.T) {
+	const depth = 8
+	local, remote := merkleIndex(2000), merkleIndex(2000)
+
+	// One object at a newer version, one missing and one extra remotely
+	local.Put(ObjectMeta{Tenant: "t1", Key: "obj/00042", VersionID: "v2"})
+	remote.Delete("t1", "obj/01000")
+	remote.Put(ObjectMeta{Tenant: "t1", Key: "stray", VersionID: "v1"})
+
+	leaves, err := local.Merkle("t1", depth).Diff(remote.Merkle("t1", depth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]bool{
+		MerkleLeaf("obj/00042", depth): true,
+		MerkleLeaf("obj/01000", depth): true,
+		MerkleLeaf("stray", depth):     true,
+	}
+	if len(leaves) != len(want) {
+		t.Fatalf("Diff = %v, want leaves %v", leaves, want)
+	}
+	for _, leaf := range leaves {
+		if !want[leaf] {
+			t.Errorf("Leaf %d reported but holds no divergent key", leaf)
+		}
+	}
+
+	// The leaf's entries pinpoint the key
+	leaf := MerkleLeaf("obj/00042", depth)
+	found := false
+	for _, kv := range local.MerkleLeafEntries("t1", depth, leaf) {
+		if MerkleLeaf(kv.Key, depth) != leaf {
+			t.Errorf("Leaf %d lists %s from leaf %d", leaf, kv.Key, MerkleLeaf(kv.Key, depth))
+		}
+		found = found || kv.Key == "obj/00042" && kv.VersionID == "v2"
+	}
+	if !found {
+		t.Error("Leaf entries lack the updated object")
+	}
+}
+
+func TestMerkleDiffRejectsOtherDepths(t *testing.T) {
+	idx := merkleIndex(10)
+	if _, err := idx.Merkle("t1", 4).Diff(idx.Merkle("t1", 5)); !errors.Is(err, ErrMerkleMismatch) {
+		t.Errorf("Diff across depths = %v, want ErrMerkleMismatch", err)
+	}
+	if _, err := idx.Merkle("t1", 4).Diff(&MerkleTree{Depth: 4}); !errors.Is(err, ErrMerkleMismatch) {
+		t.Errorf("Diff against a malformed tree = %v, want ErrMerkleMismatch", err)
+	}
+}
@@ -0,0 +1,222 @@
+// internal/metadata/segment.go
+// Immutable on-disk index segments: a tenant's entries spilled in key order
+// with a sparse in-memory block index and a bloom filter, so a lookup of a
+// key a segment does not hold never touches disk
+package metadata
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	// segmentBlockRecords is the number of records between entries of a
+	// segment's block index, and so the most read for one lookup
+	segmentBlockRecords = 64
+
+	// bloomBitsPerKey and bloomHashes give about a 1% false positive rate
+	bloomBitsPerKey = 10
+	bloomHashes     = 7
+)
+
+// segmentRecord is one entry in a segment; Deleted marks a key removed
+// after an older segment was written
+type segmentRecord struct {
+	ObjectMeta
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// segment is an open segment file
+type segment struct {
+	path    string
+	file    *os.File
+	size    int64
+	records int
+	blocks  []segmentBlock
+	bloom   bloomFilter
+}
+
+// segmentBlock is the first key of a run of records and its file offset
+type segmentBlock struct {
+	key    string
+	offset int64
+}
+
+// writeSegment writes the records next yields, which must be in key order,
+// to a new file at path. n sizes the bloom filter and may overestimate.
+func writeSegment(path string, n int, next func() (*segmentRecord, bool)) (*segment, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index segment: %w", err)
+	}
+	seg := &segment{path: path, file: file, bloom: newBloomFilter(n)}
+
+	w := bufio.NewWriter(file)
+	var lenBuf [binary.MaxVarintLen64]byte
+	for {
+		rec, ok := next()
+		if !ok {
+			break
+		}
+		data, err := json.Marshal(rec)
+		if err != nil {
+			seg.remove()
+			return nil, fmt.Errorf("failed to encode index segment record: %w", err)
+		}
+		if seg.records%segmentBlockRecords == 0 {
+			seg.blocks = append(seg.blocks, segmentBlock{key: rec.Key, offset: seg.size})
+		}
+		seg.bloom.add(rec.Key)
+
+		n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+		w.Write(lenBuf[:n])
+		if _, err := w.Write(data); err != nil {
+			seg.remove()
+			return nil, fmt.Errorf("failed to write index segment: %w", err)
+		}
+		seg.size += int64(n + len(data))
+		seg.records++
+	}
+	if err := w.Flush(); err != nil {
+		seg.remove()
+		return nil, fmt.Errorf("failed to write index segment: %w", err)
+	}
+	return seg, nil
+}
+
+// get returns the record for key, or nil if the segment does not hold it
+func (s *segment) get(key string) (*segmentRecord, error) {
+	i := sort.Search(len(s.blocks), func(i int) bool { return s.blocks[i].key > key }) - 1
+	if i < 0 {
+		return nil, nil
+	}
+	end := s.size
+	if i+1 < len(s.blocks) {
+		end = s.blocks[i+1].offset
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s.file, s.blocks[i].offset, end-s.blocks[i].offset))
+	for {
+		rec, err := readSegmentRecord(r)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec.Key == key {
+			return rec, nil
+		}
+		if rec.Key > key {
+			return nil, nil
+		}
+	}
+}
+
+// remove closes and deletes the segment file
+func (s *segment) remove() {
+	s.file.Close()
+	os.Remove(s.path)
+}
+
+func readSegmentRecord(r *bufio.Reader) (*segmentRecord, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("truncated index segment record: %w", err)
+	}
+	rec := &segmentRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("corrupt index segment record: %w", err)
+	}
+	return rec, nil
+}
+
+// segmentIter reads a segment's records in key order
+type segmentIter struct {
+	r   *bufio.Reader
+	cur *segmentRecord // nil once exhausted
+	err error
+}
+
+// iter positions a new iterator at the first record at or after start
+func (s *segment) iter(start string) *segmentIter {
+	i := max(sort.Search(len(s.blocks), func(i int) bool { return s.blocks[i].key > start })-1, 0)
+	it := &segmentIter{}
+	if len(s.blocks) == 0 {
+		return it
+	}
+	offset := s.blocks[i].offset
+	it.r = bufio.NewReader(io.NewSectionReader(s.file, offset, s.size-offset))
+	for it.advance(); it.cur != nil && it.cur.Key < start; {
+		it.advance()
+	}
+	return it
+}
+
+func (it *segmentIter) advance() {
+	rec, err := readSegmentRecord(it.r)
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		it.cur = nil
+		return
+	}
+	it.cur = rec
+}
+
+// ========== Bloom filter ==========
+
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+}
+
+func newBloomFilter(n int) bloomFilter {
+	m := uint64(max(n*bloomBitsPerKey, 64))
+	return bloomFilter{bits: make([]uint64, (m+63)/64), m: m}
+}
+
+func (b bloomFilter) add(key string) {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < bloomHashes; i++ {
+		bit := (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain is false only if key was never added
+func (b bloomFilter) mayContain(key string) bool {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < bloomHashes; i++ {
+		bit := (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash derives the two hashes of double hashing from one FNV-1a hash,
+// mixed so short keys differing in their last byte spread across the filter
+func bloomHash(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x & 0xffffffff, x>>32 | 1
+}
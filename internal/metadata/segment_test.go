@@ -0,0 +1,180 @@
+package metadata
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// A spilling index must answer exactly as one held in memory
+func TestIndexSpillMatchesMemory(t *testing.T) {
+	dir := t.TempDir()
+	spilled, err := OpenIndex(IndexConfig{Dir: dir, MemoryBytes: 16 << 10, MaxSegments: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spilled.Close()
+	memory := NewIndex()
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		tenant := fmt.Sprintf("t%d", rng.Intn(2))
+		key := fmt.Sprintf("d%d/k%03d", rng.Intn(5), rng.Intn(300))
+		if rng.Intn(4) == 0 {
+			a, b := memory.Delete(tenant, key), spilled.Delete(tenant, key)
+			if (a == nil) != (b == nil) {
+				t.Fatalf("Delete(%s, %s): memory %v, spilled %v", tenant, key, a, b)
+			}
+			continue
+		}
+		meta := ObjectMeta{Tenant: tenant, Key: key, Size: int64(i), ModTime: int64(i + 1)}
+		a, b := memory.Put(meta), spilled.Put(meta)
+		if (a == nil) != (b == nil) || (a != nil && a.Size != b.Size) {
+			t.Fatalf("Put(%s, %s) previous: memory %v, spilled %v", tenant, key, a, b)
+		}
+	}
+
+	stats := spilled.GetStats()
+	if stats.Spills.Load() == 0 || stats.Compactions.Load() == 0 || stats.Segments.Load() == 0 {
+		t.Fatalf("Expected spills and compactions, got %d spills, %d compactions, %d segments",
+			stats.Spills.Load(), stats.Compactions.Load(), stats.Segments.Load())
+	}
+	if stats.MemoryBytes.Load() > 16<<10 {
+		t.Errorf("Expected memory under the ceiling, got %d", stats.MemoryBytes.Load())
+	}
+	if stats.SegmentErrors.Load() != 0 {
+		t.Errorf("Expected no segment errors, got %d", stats.SegmentErrors.Load())
+	}
+
+	if a, b := fmt.Sprint(memory.Snapshot()), fmt.Sprint(spilled.Snapshot()); a != b {
+		t.Fatal("Snapshots differ")
+	}
+	for _, tenant := range []string{"t0", "t1"} {
+		ao, ab := memory.Usage(tenant)
+		bo, bb := spilled.Usage(tenant)
+		if ao != bo || ab != bb {
+			t.Errorf("Usage(%s): memory %d/%d, spilled %d/%d", tenant, ao, ab, bo, bb)
+		}
+		if a, b := fmt.Sprint(memory.List(tenant, "d3/")), fmt.Sprint(spilled.List(tenant, "d3/")); a != b {
+			t.Errorf("List(%s, d3/) differs", tenant)
+		}
+
+		for marker, more := "", true; more; {
+			a, truncated := memory.ListPage(tenant, "d1/", marker, 17)
+			b, _ := spilled.ListPage(tenant, "d1/", marker, 17)
+			if fmt.Sprint(a) != fmt.Sprint(b) {
+				t.Fatalf("ListPage(%s, d1/, %q) differs", tenant, marker)
+			}
+			if more = truncated; more {
+				marker = a[len(a)-1].Key
+			}
+		}
+		for _, marker := range []string{"", "d1/", "d2/k100"} {
+			a := memory.ListDelimited(tenant, "", "/", marker, 3)
+			b := spilled.ListDelimited(tenant, "", "/", marker, 3)
+			if fmt.Sprint(a) != fmt.Sprint(b) {
+				t.Errorf("ListDelimited(%s, %q) = %v, want %v", tenant, marker, b.CommonPrefixes, a.CommonPrefixes)
+			}
+		}
+
+		a, _ := memory.AggregatePrefix(tenant, "")
+		b, _ := spilled.AggregatePrefix(tenant, "")
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Errorf("AggregatePrefix(%s) differs", tenant)
+		}
+	}
+
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("d0/k%03d", i)
+		a, errA := memory.Get("t0", key)
+		b, errB := spilled.Get("t0", key)
+		if (errA == nil) != (errB == nil) || fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Fatalf("Get(%s): memory %v, spilled %v", key, a, b)
+		}
+	}
+}
+
+func TestIndexSegmentNegativeLookups(t *testing.T) {
+	idx, err := OpenIndex(IndexConfig{Dir: t.TempDir(), MemoryBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for i := 0; i < 1000; i++ {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("k%04d", i)})
+	}
+	stats := idx.GetStats()
+	reads := stats.SegmentReads.Load()
+	for i := 0; i < 1000; i++ {
+		if _, err := idx.Get("t1", fmt.Sprintf("missing%04d", i)); err == nil {
+			t.Fatal("Expected a missing key not found")
+		}
+	}
+	// Each lookup checks up to MaxSegments filters at about 1% false positives
+	if n := stats.SegmentReads.Load() - reads; n > 100 {
+		t.Errorf("Expected bloom filters to spare disk reads, got %d reads for 1000 misses", n)
+	}
+	if _, err := idx.Get("t1", "k0500"); err != nil {
+		t.Errorf("Expected a spilled key found: %v", err)
+	}
+}
+
+func TestIndexSpillKeepsAttributes(t *testing.T) {
+	idx, err := OpenIndex(IndexConfig{Dir: t.TempDir(), MemoryBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	attrs := Attributes{UserMeta: map[string]string{"owner": "ada"}, Tags: map[string]string{"class": "archive"}}
+	idx.Put(ObjectMeta{Tenant: "t1", Key: "tagged", Attributes: attrs})
+	for i := 0; i < 100; i++ {
+		idx.Put(ObjectMeta{Tenant: "t1", Key: fmt.Sprintf("k%04d", i)})
+	}
+	meta, err := idx.Get("t1", "tagged")
+	if err != nil || meta.UserMeta["owner"] != "ada" || meta.Tags["class"] != "archive" {
+		t.Errorf("Get() after spill = %+v, %v", meta, err)
+	}
+}
+
+func TestOpenIndexClearsStaleSegments(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "00000001.seg")
+	os.WriteFile(stale, []byte("old"), 0o640)
+
+	idx, err := OpenIndex(IndexConfig{Dir: dir, MemoryBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Expected the stale segment removed")
+	}
+
+	idx.Put(ObjectMeta{Tenant: "t1", Key: "a"})
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(files) != 1 {
+		t.Fatalf("Expected one segment, got %v", files)
+	}
+	idx.Close()
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.seg")); len(files) != 0 {
+		t.Errorf("Expected Close to remove segments, got %v", files)
+	}
+
+	if _, err := OpenIndex(IndexConfig{MemoryBytes: 1}); err == nil {
+		t.Error("Expected error for a ceiling without a directory")
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	if end, ok := prefixEnd("b/"); !ok || end != "b0" {
+		t.Errorf("prefixEnd(b/) = %q, %v", end, ok)
+	}
+	if end, ok := prefixEnd("a\xff"); !ok || end != "b" {
+		t.Errorf("prefixEnd(a\\xff) = %q, %v", end, ok)
+	}
+	if _, ok := prefixEnd("\xff"); ok {
+		t.Error("Expected no end for \\xff")
+	}
+}
@@ -0,0 +1,342 @@
+// internal/monitoring/access.go
+// Rolling access statistics: requests and bytes served per tenant key in
+// fixed intervals, top-N queries by key or prefix, and detection of
+// consistently hot prefixes for tier placement
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Defaults keep an hour of history in one-minute intervals
+	DefaultAccessInterval  = time.Minute
+	DefaultAccessIntervals = 60
+	DefaultAccessMaxKeys   = 100000
+)
+
+// Orders for AccessStats.Top
+const (
+	AccessByRequests = "requests"
+	AccessByBytes    = "bytes"
+)
+
+// AccessStatsConfig sizes the history
+type AccessStatsConfig struct {
+	Interval  time.Duration // Width of one interval
+	Intervals int           // Intervals of history kept per key
+	MaxKeys   int           // Keys tracked at once; accesses to further keys are only counted as untracked
+}
+
+// AccessCount is the activity of a key or prefix over a window
+type AccessCount struct {
+	TenantID     string    `json:"tenant_id"`
+	Key          string    `json:"key,omitempty"`
+	Prefix       string    `json:"prefix,omitempty"`
+	Requests     int64     `json:"requests"`
+	Bytes        int64     `json:"bytes"`
+	LastAccessed time.Time `json:"last_accessed"`
+}
+
+// AccessQuery selects and orders AccessStats.Top results
+type AccessQuery struct {
+	TenantID string        // Empty for every tenant
+	Prefix   string        // Only keys under this prefix
+	Window   time.Duration // Trailing window; 0 for the whole history
+	By       string        // AccessByRequests (default) or AccessByBytes
+	Depth    int           // If > 0, group keys by their first Depth "/"-separated segments
+	Limit    int           // Maximum results; 0 for all
+}
+
+// AccessStats counts accesses per tenant key in a ring of intervals
+type AccessStats struct {
+	interval time.Duration
+	slots    int
+	maxKeys  int
+	now      func() time.Time
+
+	mu        sync.Mutex
+	series    map[accessKey]*accessSeries
+	untracked atomic.Uint64
+}
+
+type accessKey struct {
+	tenantID string
+	key      string
+}
+
+// accessSeries holds one key's counts; slot s lives at index s % len
+type accessSeries struct {
+	requests     []int64
+	bytes        []int64
+	last         int64 // Slot of the latest access
+	lastAccessed int64 // Unix nanoseconds
+}
+
+// NewAccessStats creates empty statistics, filling unset config with defaults
+func NewAccessStats(config AccessStatsConfig) *AccessStats {
+	if config.Interval <= 0 {
+		config.Interval = DefaultAccessInterval
+	}
+	if config.Intervals <= 0 {
+		config.Intervals = DefaultAccessIntervals
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultAccessMaxKeys
+	}
+	return &AccessStats{
+		interval: config.Interval,
+		slots:    config.Intervals,
+		maxKeys:  config.MaxKeys,
+		now:      time.Now,
+		series:   make(map[accessKey]*accessSeries),
+	}
+}
+
+// Interval is the width of one interval
+func (a *AccessStats) Interval() time.Duration {
+	return a.interval
+}
+
+func (a *AccessStats) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(a.interval)
+}
+
+// intervals converts a window to a whole number of intervals within the history
+func (a *AccessStats) intervals(window time.Duration) int64 {
+	n := int64((window + a.interval - 1) / a.interval)
+	if n <= 0 || n > int64(a.slots) {
+		n = int64(a.slots)
+	}
+	return n
+}
+
+// Record counts one access to key that served bytes
+func (a *AccessStats) Record(tenantID, key string, bytes int64) {
+	now := a.now()
+	slot := a.slot(now)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	k := accessKey{tenantID, key}
+	s := a.series[k]
+	if s == nil {
+		if len(a.series) >= a.maxKeys {
+			a.untracked.Add(1)
+			return
+		}
+		s = &accessSeries{requests: make([]int64, a.slots), bytes: make([]int64, a.slots), last: slot}
+		a.series[k] = s
+	}
+	s.advance(slot)
+	i := slot % int64(len(s.requests))
+	s.requests[i]++
+	s.bytes[i] += bytes
+	s.lastAccessed = now.UnixNano()
+}
+
+// advance clears the slots between the latest access and slot
+func (s *accessSeries) advance(slot int64) {
+	n := int64(len(s.requests))
+	for t := s.last + 1; t <= slot && t <= s.last+n; t++ {
+		s.requests[t%n], s.bytes[t%n] = 0, 0
+	}
+	if slot > s.last {
+		s.last = slot
+	}
+}
+
+// count returns the requests and bytes recorded in slot, zero once it has
+// left the history
+func (s *accessSeries) count(slot int64) (requests, bytes int64) {
+	n := int64(len(s.requests))
+	if slot > s.last || slot <= s.last-n {
+		return 0, 0
+	}
+	return s.requests[slot%n], s.bytes[slot%n]
+}
+
+// Top returns the most accessed keys, or prefixes with q.Depth, over q.Window
+func (a *AccessStats) Top(q AccessQuery) ([]AccessCount, error) {
+	switch q.By {
+	case "":
+		q.By = AccessByRequests
+	case AccessByRequests, AccessByBytes:
+	default:
+		return nil, fmt.Errorf("unknown order %q (want %s or %s)", q.By, AccessByRequests, AccessByBytes)
+	}
+
+	cur := a.slot(a.now())
+	n := a.intervals(q.Window)
+
+	groups := make(map[accessKey]*AccessCount)
+	a.mu.Lock()
+	for k, s := range a.series {
+		if (q.TenantID != "" && k.tenantID != q.TenantID) || !strings.HasPrefix(k.key, q.Prefix) {
+			continue
+		}
+		var requests, bytes int64
+		for t := cur - n + 1; t <= cur; t++ {
+			r, b := s.count(t)
+			requests += r
+			bytes += b
+		}
+		if requests == 0 {
+			continue
+		}
+
+		g := accessKey{k.tenantID, k.key}
+		if q.Depth > 0 {
+			g.key = keyPrefix(k.key, q.Depth)
+		}
+		c := groups[g]
+		if c == nil {
+			c = &AccessCount{TenantID: g.tenantID}
+			if q.Depth > 0 {
+				c.Prefix = g.key
+			} else {
+				c.Key = g.key
+			}
+			groups[g] = c
+		}
+		c.Requests += requests
+		c.Bytes += bytes
+		if last := time.Unix(0, s.lastAccessed).UTC(); last.After(c.LastAccessed) {
+			c.LastAccessed = last
+		}
+	}
+	a.mu.Unlock()
+
+	out := make([]AccessCount, 0, len(groups))
+	for _, c := range groups {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		vi, vj := out[i].Requests, out[j].Requests
+		if q.By == AccessByBytes {
+			vi, vj = out[i].Bytes, out[j].Bytes
+		}
+		if vi != vj {
+			return vi > vj
+		}
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		return out[i].Key+out[i].Prefix < out[j].Key+out[j].Prefix
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+// HotPrefixes returns the prefixes of depth segments that served at least
+// minRequests in every completed interval of the trailing window, most
+// requested first. The interval in progress is not considered.
+func (a *AccessStats) HotPrefixes(depth int, window time.Duration, minRequests int64) []AccessCount {
+	cur := a.slot(a.now())
+	n := a.intervals(window)
+	if n >= int64(a.slots) {
+		n = int64(a.slots) - 1 // The current interval holds one slot
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	type group struct {
+		count   AccessCount
+		perSlot []int64
+	}
+	groups := make(map[accessKey]*group)
+	a.mu.Lock()
+	for k, s := range a.series {
+		if s.last < cur-n {
+			continue
+		}
+		g := accessKey{k.tenantID, keyPrefix(k.key, depth)}
+		grp := groups[g]
+		if grp == nil {
+			grp = &group{count: AccessCount{TenantID: g.tenantID, Prefix: g.key}, perSlot: make([]int64, n)}
+			groups[g] = grp
+		}
+		for i := int64(0); i < n; i++ {
+			r, b := s.count(cur - n + i)
+			grp.perSlot[i] += r
+			grp.count.Requests += r
+			grp.count.Bytes += b
+		}
+		if last := time.Unix(0, s.lastAccessed).UTC(); last.After(grp.count.LastAccessed) {
+			grp.count.LastAccessed = last
+		}
+	}
+	a.mu.Unlock()
+
+	var out []AccessCount
+next:
+	for _, grp := range groups {
+		for _, r := range grp.perSlot {
+			if r < minRequests {
+				continue next
+			}
+		}
+		out = append(out, grp.count)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].TenantID+"/"+out[i].Prefix < out[j].TenantID+"/"+out[j].Prefix
+	})
+	return out
+}
+
+// Prune forgets keys with no access left in the history and returns how
+// many were dropped
+func (a *AccessStats) Prune() int {
+	cur := a.slot(a.now())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var dropped int
+	for k, s := range a.series {
+		if s.last <= cur-int64(a.slots) {
+			delete(a.series, k)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Keys is the number of keys tracked
+func (a *AccessStats) Keys() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.series)
+}
+
+// Untracked is the number of accesses not counted because MaxKeys keys
+// were already tracked
+func (a *AccessStats) Untracked() uint64 {
+	return a.untracked.Load()
+}
+
+// keyPrefix returns key up to and including its depth-th "/", or up to its
+// last "/" when it has fewer ("" for keys without one)
+func keyPrefix(key string, depth int) string {
+	end := 0
+	for i := 0; i < depth; i++ {
+		j := strings.IndexByte(key[end:], '/')
+		if j < 0 {
+			break
+		}
+		end += j + 1
+	}
+	return key[:end]
+}
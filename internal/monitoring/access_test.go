@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestAccessStats returns stats on a manual clock with 10 one-minute intervals
+func newTestAccessStats(maxKeys int) (*AccessStats, *time.Time) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewAccessStats(AccessStatsConfig{Interval: time.Minute, Intervals: 10, MaxKeys: maxKeys})
+	a.now = func() time.Time { return clock }
+	return a, &clock
+}
+
+func TestAccessStats_TopWindows(t *testing.T) {
+	a, clock := newTestAccessStats(0)
+
+	a.Record("t1", "logs/a", 100)
+	a.Record("t1", "logs/a", 100)
+	a.Record("t1", "img/big", 5000)
+	a.Record("t2", "logs/a", 1)
+	*clock = clock.Add(5 * time.Minute)
+	a.Record("t1", "logs/b", 10)
+
+	top, err := a.Top(AccessQuery{TenantID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 3 || top[0].Key != "logs/a" || top[0].Requests != 2 || top[0].Bytes != 200 {
+		t.Fatalf("Expected logs/a first with 2 requests, got %+v", top)
+	}
+
+	top, _ = a.Top(AccessQuery{TenantID: "t1", By: AccessByBytes, Limit: 1})
+	if len(top) != 1 || top[0].Key != "img/big" {
+		t.Errorf("Expected img/big by bytes, got %+v", top)
+	}
+
+	// Only the last two minutes
+	top, _ = a.Top(AccessQuery{TenantID: "t1", Window: 2 * time.Minute})
+	if len(top) != 1 || top[0].Key != "logs/b" {
+		t.Errorf("Expected only logs/b in the window, got %+v", top)
+	}
+
+	top, _ = a.Top(AccessQuery{Depth: 1, Prefix: "logs/"})
+	if len(top) != 2 || top[0].TenantID != "t1" || top[0].Prefix != "logs/" || top[0].Requests != 3 {
+		t.Errorf("Expected logs/ grouped per tenant, got %+v", top)
+	}
+
+	if _, err := a.Top(AccessQuery{By: "size"}); err == nil {
+		t.Error("Expected error for unknown order")
+	}
+
+	// Everything ages out of the history
+	*clock = clock.Add(10 * time.Minute)
+	if top, _ := a.Top(AccessQuery{}); len(top) != 0 {
+		t.Errorf("Expected no activity, got %+v", top)
+	}
+	if dropped := a.Prune(); dropped != 4 || a.Keys() != 0 {
+		t.Errorf("Expected 4 keys pruned, got %d (%d left)", dropped, a.Keys())
+	}
+}
+
+func TestAccessStats_RingReuse(t *testing.T) {
+	a, clock := newTestAccessStats(0)
+
+	a.Record("t1", "k", 1)
+	*clock = clock.Add(10 * time.Minute) // Same ring index as the first access
+	a.Record("t1", "k", 1)
+
+	top, _ := a.Top(AccessQuery{})
+	if len(top) != 1 || top[0].Requests != 1 {
+		t.Errorf("Expected the stale slot cleared, got %+v", top)
+	}
+}
+
+func TestAccessStats_HotPrefixes(t *testing.T) {
+	a, clock := newTestAccessStats(0)
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 3; j++ {
+			a.Record("t1", "hot/obj", 10)
+		}
+		if i%2 == 0 {
+			a.Record("t1", "bursty/obj", 10)
+			a.Record("t1", "bursty/obj", 10)
+			a.Record("t1", "bursty/obj", 10)
+			a.Record("t1", "bursty/obj", 10)
+		}
+		*clock = clock.Add(time.Minute)
+	}
+
+	hot := a.HotPrefixes(1, 4*time.Minute, 3)
+	if len(hot) != 1 || hot[0].Prefix != "hot/" || hot[0].Requests != 12 {
+		t.Fatalf("Expected only hot/ to be consistently hot, got %+v", hot)
+	}
+
+	// Accesses in the current interval do not count yet, and a quiet
+	// interval ends the streak
+	a.Record("t1", "bursty/obj", 10)
+	*clock = clock.Add(time.Minute)
+	if hot := a.HotPrefixes(1, 4*time.Minute, 3); len(hot) != 0 {
+		t.Errorf("Expected nothing hot after a quiet interval, got %+v", hot)
+	}
+}
+
+func TestAccessStats_MaxKeys(t *testing.T) {
+	a, _ := newTestAccessStats(2)
+
+	a.Record("t1", "a", 1)
+	a.Record("t1", "b", 1)
+	a.Record("t1", "c", 1)
+	a.Record("t1", "a", 1)
+
+	if a.Keys() != 2 || a.Untracked() != 1 {
+		t.Errorf("Expected 2 keys and 1 untracked access, got %d and %d", a.Keys(), a.Untracked())
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key   string
+		depth int
+		want  string
+	}{
+		{"a/b/c", 1, "a/"},
+		{"a/b/c", 2, "a/b/"},
+		{"a/b/c", 5, "a/b/"},
+		{"top", 1, ""},
+	}
+	for _, tt := range tests {
+		if got := keyPrefix(tt.key, tt.depth); got != tt.want {
+			t.Errorf("keyPrefix(%q, %d) = %q, want %q", tt.key, tt.depth, got, tt.want)
+		}
+	}
+}
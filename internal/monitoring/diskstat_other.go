@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package monitoring
+
+import "fmt"
+
+// diskStat is not implemented on this platform
+func diskStat(path string) (total, free uint64, err error) {
+	return 0, 0, fmt.Errorf("disk statistics not supported on this platform")
+}
@@ -0,0 +1,226 @@
+// internal/monitoring/diskwatch.go
+// Disk usage watchers that alert on thresholds and flip the node read-only before disks fill
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Default thresholds (percent of capacity used)
+	DefaultDiskWarnPercent     = 85.0
+	DefaultDiskReadOnlyPercent = 95.0
+	DefaultDiskResumePercent   = 90.0
+
+	DefaultDiskCheckInterval = 10 * time.Second
+)
+
+// Disk states
+const (
+	DiskStateOK       = "ok"
+	DiskStateWarning  = "warning"
+	DiskStateReadOnly = "read_only"
+	DiskStateError    = "error"
+)
+
+// DiskWatcherConfig configures the watched directories and thresholds
+type DiskWatcherConfig struct {
+	// Paths maps a logical name (l2, l3, persistent) to a directory
+	Paths map[string]string
+
+	WarnPercent     float64
+	ReadOnlyPercent float64
+	// ResumePercent must be crossed downwards before writes resume (hysteresis)
+	ResumePercent float64
+
+	Interval time.Duration
+}
+
+// DiskUsage is the last observation for one watched directory
+type DiskUsage struct {
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	TotalBytes  uint64    `json:"total_bytes"`
+	FreeBytes   uint64    `json:"free_bytes"`
+	UsedPercent float64   `json:"used_percent"`
+	State       string    `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// DiskWatcher periodically samples disk usage
+type DiskWatcher struct {
+	config *DiskWatcherConfig
+	alerts *AlertManager
+
+	mu    sync.RWMutex
+	usage map[string]*DiskUsage
+
+	readOnly       atomic.Bool
+	readOnlyFlips  atomic.Uint64
+	onReadOnlyFlip func(readOnly bool)
+}
+
+// NewDiskWatcher creates a watcher; alerts may be nil
+func NewDiskWatcher(config *DiskWatcherConfig, alerts *AlertManager) *DiskWatcher {
+	if config.WarnPercent == 0 {
+		config.WarnPercent = DefaultDiskWarnPercent
+	}
+	if config.ReadOnlyPercent == 0 {
+		config.ReadOnlyPercent = DefaultDiskReadOnlyPercent
+	}
+	if config.ResumePercent == 0 {
+		config.ResumePercent = DefaultDiskResumePercent
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultDiskCheckInterval
+	}
+
+	return &DiskWatcher{
+		config: config,
+		alerts: alerts,
+		usage:  make(map[string]*DiskUsage),
+	}
+}
+
+// OnReadOnlyChange registers a callback invoked when the node enters or leaves read-only mode
+func (d *DiskWatcher) OnReadOnlyChange(fn func(readOnly bool)) {
+	d.onReadOnlyFlip = fn
+}
+
+// Start samples immediately, then every Interval until ctx is cancelled
+func (d *DiskWatcher) Start(ctx context.Context) {
+	d.Check()
+
+	go func() {
+		ticker := time.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.Check()
+			}
+		}
+	}()
+}
+
+// Check samples every watched directory and updates read-only mode
+func (d *DiskWatcher) Check() {
+	wasReadOnly := d.readOnly.Load()
+	anyFull, allResumed := false, true
+
+	for name, path := range d.config.Paths {
+		u := &DiskUsage{Name: name, Path: path, CheckedAt: time.Now()}
+
+		total, free, err := diskStat(path)
+		if err != nil {
+			u.State = DiskStateError
+			u.Error = err.Error()
+		} else {
+			u.TotalBytes, u.FreeBytes = total, free
+			if total > 0 {
+				u.UsedPercent = float64(total-free) / float64(total) * 100
+			}
+
+			switch {
+			case u.UsedPercent >= d.config.ReadOnlyPercent:
+				u.State = DiskStateReadOnly
+				anyFull = true
+			case u.UsedPercent >= d.config.WarnPercent:
+				u.State = DiskStateWarning
+			default:
+				u.State = DiskStateOK
+			}
+			if u.UsedPercent >= d.config.ResumePercent {
+				allResumed = false
+			}
+		}
+
+		d.mu.Lock()
+		prev := d.usage[name]
+		d.usage[name] = u
+		d.mu.Unlock()
+
+		d.alertOnTransition(prev, u)
+	}
+
+	switch {
+	case anyFull && !wasReadOnly:
+		d.setReadOnly(true)
+	case wasReadOnly && allResumed:
+		d.setReadOnly(false)
+	}
+}
+
+func (d *DiskWatcher) setReadOnly(readOnly bool) {
+	d.readOnly.Store(readOnly)
+	d.readOnlyFlips.Add(1)
+
+	if readOnly {
+		log.Printf("Disk usage above %.0f%%: node entering read-only mode", d.config.ReadOnlyPercent)
+	} else {
+		log.Printf("Disk usage below %.0f%%: node leaving read-only mode", d.config.ResumePercent)
+	}
+
+	if d.onReadOnlyFlip != nil {
+		d.onReadOnlyFlip(readOnly)
+	}
+}
+
+func (d *DiskWatcher) alertOnTransition(prev, cur *DiskUsage) {
+	if d.alerts == nil || (prev != nil && prev.State == cur.State) {
+		return
+	}
+
+	alertID := fmt.Sprintf("disk_%s", cur.Name)
+	switch cur.State {
+	case DiskStateOK:
+		if prev != nil {
+			d.alerts.Resolve(alertID)
+		}
+	case DiskStateWarning, DiskStateReadOnly, DiskStateError:
+		severity := "warning"
+		if cur.State != DiskStateWarning {
+			severity = "critical"
+		}
+		d.alerts.Raise(&Alert{
+			ID:       alertID,
+			RuleID:   "disk_usage",
+			Severity: severity,
+			Message:  fmt.Sprintf("disk %s (%s) %s: %.1f%% used %s", cur.Name, cur.Path, cur.State, cur.UsedPercent, cur.Error),
+			Value:    cur.UsedPercent,
+		})
+	}
+}
+
+// ReadOnly reports whether writes should be refused
+func (d *DiskWatcher) ReadOnly() bool {
+	return d.readOnly.Load()
+}
+
+// ReadOnlyFlips returns how many times read-only mode has changed
+func (d *DiskWatcher) ReadOnlyFlips() uint64 {
+	return d.readOnlyFlips.Load()
+}
+
+// Usage returns the latest observations ordered by name
+func (d *DiskWatcher) Usage() []DiskUsage {
+	d.mu.RLock()
+	out := make([]DiskUsage, 0, len(d.usage))
+	for _, u := range d.usage {
+		out = append(out, *u)
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,257 @@
+// internal/monitoring/healthlog.go
+// Health history: a rolling log of subsystem state transitions (readiness,
+// disks, replication breakers, clocks) kept so that a complaint about an
+// outage can be lined up with what the node went through. Events are
+// appended to a JSON lines file as they happen, so the history survives
+// the crash or restart it is often needed to explain.
+package monitoring
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultHealthHistory is the number of events kept
+const DefaultHealthHistory = 2000
+
+// Health event severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// HealthEvent is one transition of a subsystem, or of one of its
+// components, between states
+type HealthEvent struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Subsystem string    `json:"subsystem"`           // readiness, disk, breaker, clock_skew, ...
+	Component string    `json:"component,omitempty"` // Disk name, region, ...
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Severity  string    `json:"severity"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// HealthQuery selects events; zero fields match everything
+type HealthQuery struct {
+	From, To    time.Time
+	Subsystem   string
+	Component   string
+	MinSeverity string
+	Limit       int // Newest events kept when more match
+}
+
+// HealthLog holds the most recent events in a ring
+type HealthLog struct {
+	capacity int
+	path     string
+
+	mu      sync.Mutex
+	events  []HealthEvent // Ring; events[next] is the oldest once full
+	next    int
+	seq     uint64
+	file    *os.File
+	written int // Lines in file
+}
+
+// NewHealthLog returns a log of capacity events (DefaultHealthHistory if
+// 0). With a path, the events saved there are loaded and new ones are
+// appended to it; without one, the history lasts as long as the process.
+func NewHealthLog(capacity int, path string) (*HealthLog, error) {
+	if capacity <= 0 {
+		capacity = DefaultHealthHistory
+	}
+	h := &HealthLog{capacity: capacity, path: path, events: make([]HealthEvent, 0, capacity)}
+	if path == "" {
+		return h, nil
+	}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	if err := h.rewrite(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// load reads the saved events, skipping a torn last line
+func (h *HealthLog) load() error {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open health history: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var ev HealthEvent
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		h.push(ev)
+		h.seq = max(h.seq, ev.Seq)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read health history: %w", err)
+	}
+	return nil
+}
+
+// rewrite replaces the file with the events in the ring and reopens it
+// for appending. h.mu must be held or h not yet shared.
+func (h *HealthLog) rewrite() error {
+	if h.file != nil {
+		h.file.Close()
+		h.file = nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err != nil {
+		return fmt.Errorf("failed to save health history: %w", err)
+	}
+	tmp := h.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to save health history: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	events := h.ordered()
+	for _, ev := range events {
+		enc.Encode(ev)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save health history: %w", err)
+	}
+	f.Close()
+	if err := os.Rename(tmp, h.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save health history: %w", err)
+	}
+
+	h.file, err = os.OpenFile(h.path, os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open health history: %w", err)
+	}
+	h.written = len(events)
+	return nil
+}
+
+// push adds ev to the ring, dropping the oldest event if it is full
+func (h *HealthLog) push(ev HealthEvent) {
+	if len(h.events) < h.capacity {
+		h.events = append(h.events, ev)
+		return
+	}
+	h.events[h.next] = ev
+	h.next = (h.next + 1) % h.capacity
+}
+
+// ordered returns the ring's events, oldest first
+func (h *HealthLog) ordered() []HealthEvent {
+	out := make([]HealthEvent, 0, len(h.events))
+	out = append(out, h.events[h.next:]...)
+	return append(out, h.events[:h.next]...)
+}
+
+// Record adds an event, stamping its sequence number, and its time if
+// unset. Saving is best effort: an event that cannot be written is still
+// kept in memory.
+func (h *HealthLog) Record(ev HealthEvent) HealthEvent {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	if ev.Severity == "" {
+		ev.Severity = SeverityInfo
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	ev.Seq = h.seq
+	h.push(ev)
+	if h.file == nil {
+		return ev
+	}
+	// The file is compacted, ev included, once it holds twice what the
+	// ring does. If that fails, later events are only kept in memory.
+	if h.written >= 2*h.capacity {
+		h.rewrite()
+		return ev
+	}
+	if data, err := json.Marshal(ev); err == nil {
+		if _, err := h.file.Write(append(data, '\n')); err == nil {
+			h.written++
+		}
+	}
+	return ev
+}
+
+// severityRank orders severities; unknown ones rank as info
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Events returns the events q selects in the order they were recorded
+func (h *HealthLog) Events(q HealthQuery) []HealthEvent {
+	h.mu.Lock()
+	events := h.ordered()
+	h.mu.Unlock()
+
+	out := events[:0]
+	for _, ev := range events {
+		switch {
+		case !q.From.IsZero() && ev.Time.Before(q.From),
+			!q.To.IsZero() && ev.Time.After(q.To),
+			q.Subsystem != "" && ev.Subsystem != q.Subsystem,
+			q.Component != "" && ev.Component != q.Component,
+			severityRank(ev.Severity) < severityRank(q.MinSeverity):
+			continue
+		}
+		out = append(out, ev)
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Total returns how many events have been recorded, including ones no
+// longer kept
+func (h *HealthLog) Total() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq
+}
+
+// Close closes the history file
+func (h *HealthLog) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
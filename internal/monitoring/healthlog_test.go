@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthLog_RingAndQuery(t *testing.T) {
+	h, err := NewHealthLog(3, "")
+	if err != nil {
+		t.Fatalf("NewHealthLog: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, ev := range []HealthEvent{
+		{Subsystem: "disk_usage", Component: "l2", To: "firing", Severity: SeverityWarning},
+		{Subsystem: "breaker", Component: "r1", From: "closed", To: "open", Severity: SeverityCritical},
+		{Subsystem: "breaker", Component: "r2", From: "closed", To: "open", Severity: SeverityCritical},
+		{Subsystem: "breaker", Component: "r1", From: "open", To: "half_open", Severity: SeverityWarning},
+		{Subsystem: "breaker", Component: "r1", From: "half_open", To: "closed"},
+	} {
+		ev.Time = start.Add(time.Duration(i) * time.Minute)
+		h.Record(ev)
+	}
+
+	if total := h.Total(); total != 5 {
+		t.Errorf("Total() = %d, want 5", total)
+	}
+	all := h.Events(HealthQuery{})
+	if len(all) != 3 || all[0].Seq != 3 || all[2].Seq != 5 {
+		t.Fatalf("Events() = %+v, want the last 3 in order", all)
+	}
+	for _, tc := range []struct {
+		q    HealthQuery
+		want []uint64
+	}{
+		{HealthQuery{Component: "r1"}, []uint64{4, 5}},
+		{HealthQuery{MinSeverity: SeverityWarning}, []uint64{3, 4}},
+		{HealthQuery{MinSeverity: SeverityCritical}, []uint64{3}},
+		{HealthQuery{From: start.Add(3 * time.Minute)}, []uint64{4, 5}},
+		{HealthQuery{To: start.Add(3 * time.Minute)}, []uint64{3, 4}},
+		{HealthQuery{Limit: 1}, []uint64{5}},
+		{HealthQuery{Subsystem: "disk_usage"}, nil},
+	} {
+		var got []uint64
+		for _, ev := range h.Events(tc.q) {
+			got = append(got, ev.Seq)
+		}
+		if len(got) != len(tc.want) || len(got) > 0 && (got[0] != tc.want[0] || got[len(got)-1] != tc.want[len(tc.want)-1]) {
+			t.Errorf("Events(%+v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestHealthLog_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meta", "health.log")
+	h, err := NewHealthLog(4, path)
+	if err != nil {
+		t.Fatalf("NewHealthLog: %v", err)
+	}
+	for i := 0; i < 10; i++ { // Compacts the file along the way
+		h.Record(HealthEvent{Subsystem: "node", To: "started"})
+	}
+	h.Close()
+
+	// A torn last line, as a crash mid-write leaves, is skipped
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"seq":11,"subsys`)
+	f.Close()
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines > 8 {
+		t.Errorf("History file has %d lines, want it compacted to at most 8", lines)
+	}
+
+	h, err = NewHealthLog(4, path)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	defer h.Close()
+	events := h.Events(HealthQuery{})
+	if len(events) != 4 || events[0].Seq != 7 || events[3].Seq != 10 {
+		t.Fatalf("Reloaded events = %+v, want 7 through 10", events)
+	}
+	if ev := h.Record(HealthEvent{Subsystem: "node", To: "stopped"}); ev.Seq != 11 || ev.Severity != SeverityInfo {
+		t.Errorf("Record() after reload = %+v, want seq 11 at info", ev)
+	}
+}
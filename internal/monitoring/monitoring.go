@@ -116,7 +116,7 @@ type Span struct {
 	ParentSpanID   string
 	OperationName  string
 	StartTime      time.Time
-	EndTime        time.Duration
+	EndTime        time.Time
 	Tags           map[string]interface{}
 	Logs           []LogEntry
 	Status         string
@@ -148,6 +148,7 @@ type AlertManager struct {
 	rules        []*AlertRule
 	alerts       map[string]*Alert
 	subscribers  map[string]AlertSubscriber
+	onChange     func(Alert)
 	mu           sync.RWMutex
 }
 
@@ -460,6 +461,55 @@ func (am *AlertManager) EvaluateRules(ctx context.Context, metrics *MetricsColle
 	}
 }
 
+// Raise records an alert generated outside rule evaluation and notifies subscribers
+func (am *AlertManager) Raise(alert *Alert) {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now()
+	}
+	if alert.Status == "" {
+		alert.Status = "firing"
+	}
+
+	am.mu.Lock()
+	am.alerts[alert.ID] = alert
+	snapshot, onChange := *alert, am.onChange
+	am.mu.Unlock()
+
+	if onChange != nil {
+		onChange(snapshot)
+	}
+	am.notifySubscribers(alert)
+}
+
+// Resolve marks a raised alert as resolved and notifies subscribers
+func (am *AlertManager) Resolve(alertID string) {
+	am.mu.Lock()
+	alert, exists := am.alerts[alertID]
+	var snapshot Alert
+	if exists {
+		alert.Status = "resolved"
+		alert.Timestamp = time.Now()
+		snapshot = *alert
+	}
+	onChange := am.onChange
+	am.mu.Unlock()
+
+	if exists {
+		if onChange != nil {
+			onChange(snapshot)
+		}
+		am.notifySubscribers(alert)
+	}
+}
+
+// OnChange registers a callback invoked synchronously, before subscribers
+// are notified, with a copy of every alert passed to Raise or Resolve
+func (am *AlertManager) OnChange(fn func(Alert)) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.onChange = fn
+}
+
 // Subscribe adds an alert subscriber
 func (am *AlertManager) Subscribe(name string, subscriber AlertSubscriber) {
 	am.mu.Lock()
@@ -0,0 +1,403 @@
+// internal/monitoring/rollup.go
+// Embedded time-series rollups: per-tenant and per-bucket metrics kept at
+// one-minute, one-hour and one-day resolution in rings sized by each
+// resolution's retention, saved to and restored from one JSON file
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Rollup resolutions
+const (
+	ResolutionMinute = "1m"
+	ResolutionHour   = "1h"
+	ResolutionDay    = "1d"
+)
+
+const (
+	// Defaults keep six hours of minutes, a week of hours and 400 days
+	DefaultRollupMinuteRetention = 6 * time.Hour
+	DefaultRollupHourRetention   = 7 * 24 * time.Hour
+	DefaultRollupDayRetention    = 400 * 24 * time.Hour
+	DefaultRollupMaxSeries       = 20000
+)
+
+// RollupConfig sets how long each resolution is kept and how many series
+// are tracked at once
+type RollupConfig struct {
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+	DayRetention    time.Duration
+	MaxSeries       int // Samples for further series are only counted as dropped
+}
+
+// SeriesKey names a series. Bucket is empty for the tenant's totals.
+type SeriesKey struct {
+	TenantID string `json:"tenant_id"`
+	Bucket   string `json:"bucket,omitempty"`
+	Metric   string `json:"metric"`
+}
+
+// RollupPoint is one interval of a series. Counters hold the total added
+// in the interval; gauges hold the last sample and the highest.
+type RollupPoint struct {
+	Time  time.Time `json:"time"` // Start of the interval
+	Value int64     `json:"value"`
+	Max   int64     `json:"max,omitempty"`
+}
+
+// noSample marks a slot nothing was recorded in
+const noSample = math.MinInt64
+
+// rollupLevel is one resolution
+type rollupLevel struct {
+	name  string
+	width time.Duration
+	slots int
+}
+
+// rollupRing holds one resolution of a series; slot s lives at index s % len
+type rollupRing struct {
+	values []int64
+	maxes  []int64 // noSample where nothing was recorded
+	last   int64   // Latest slot recorded
+}
+
+type rollupSeries struct {
+	gauge bool
+	rings []rollupRing
+}
+
+// RollupStore keeps rollups of every series in memory
+type RollupStore struct {
+	levels    []rollupLevel
+	maxSeries int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	series  map[SeriesKey]*rollupSeries
+	dropped atomic.Uint64
+}
+
+// NewRollupStore creates an empty store, filling unset config with defaults
+func NewRollupStore(config RollupConfig) *RollupStore {
+	if config.MinuteRetention <= 0 {
+		config.MinuteRetention = DefaultRollupMinuteRetention
+	}
+	if config.HourRetention <= 0 {
+		config.HourRetention = DefaultRollupHourRetention
+	}
+	if config.DayRetention <= 0 {
+		config.DayRetention = DefaultRollupDayRetention
+	}
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = DefaultRollupMaxSeries
+	}
+	level := func(name string, width, retention time.Duration) rollupLevel {
+		return rollupLevel{name, width, int((retention + width - 1) / width)}
+	}
+	return &RollupStore{
+		levels: []rollupLevel{
+			level(ResolutionMinute, time.Minute, config.MinuteRetention),
+			level(ResolutionHour, time.Hour, config.HourRetention),
+			level(ResolutionDay, 24*time.Hour, config.DayRetention),
+		},
+		maxSeries: config.MaxSeries,
+		now:       time.Now,
+		series:    make(map[SeriesKey]*rollupSeries),
+	}
+}
+
+// Add adds delta to counter key's current interval
+func (r *RollupStore) Add(key SeriesKey, delta int64) {
+	r.record(key, false, func(value, max int64) (int64, int64) {
+		if max == noSample {
+			value = 0
+		}
+		return value + delta, 0
+	})
+}
+
+// Set samples gauge key's value in its current interval
+func (r *RollupStore) Set(key SeriesKey, value int64) {
+	r.record(key, true, func(_, max int64) (int64, int64) {
+		if max == noSample || value > max {
+			max = value
+		}
+		return value, max
+	})
+}
+
+// record applies update to the current slot of each of key's rings. A
+// series is a counter or a gauge by whichever first recorded it.
+func (r *RollupStore) record(key SeriesKey, gauge bool, update func(value, max int64) (int64, int64)) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.series[key]
+	if s == nil {
+		if len(r.series) >= r.maxSeries {
+			r.dropped.Add(1)
+			return
+		}
+		s = &rollupSeries{gauge: gauge, rings: make([]rollupRing, len(r.levels))}
+		for i, level := range r.levels {
+			s.rings[i] = newRollupRing(level.slots, level.slot(now))
+		}
+		r.series[key] = s
+	}
+	for i, level := range r.levels {
+		ring := &s.rings[i]
+		slot := level.slot(now)
+		ring.advance(slot)
+		j := slot % int64(len(ring.values))
+		ring.values[j], ring.maxes[j] = update(ring.values[j], ring.maxes[j])
+	}
+}
+
+func newRollupRing(slots int, last int64) rollupRing {
+	ring := rollupRing{values: make([]int64, slots), maxes: make([]int64, slots), last: last}
+	for i := range ring.maxes {
+		ring.maxes[i] = noSample
+	}
+	return ring
+}
+
+func (l rollupLevel) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(l.width)
+}
+
+// advance clears the slots between the latest recorded and slot
+func (ring *rollupRing) advance(slot int64) {
+	n := int64(len(ring.values))
+	for t := ring.last + 1; t <= slot && t <= ring.last+n; t++ {
+		ring.values[t%n], ring.maxes[t%n] = 0, noSample
+	}
+	if slot > ring.last {
+		ring.last = slot
+	}
+}
+
+// sample returns slot's value and max, ok false once it has left the ring
+// or if nothing was recorded in it
+func (ring *rollupRing) sample(slot int64) (value, max int64, ok bool) {
+	n := int64(len(ring.values))
+	if slot > ring.last || slot <= ring.last-n || ring.maxes[slot%n] == noSample {
+		return 0, 0, false
+	}
+	return ring.values[slot%n], ring.maxes[slot%n], true
+}
+
+// level returns the index of resolution
+func (r *RollupStore) level(resolution string) (int, error) {
+	for i, level := range r.levels {
+		if level.name == resolution {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resolution %q (want %s, %s or %s)", resolution, ResolutionMinute, ResolutionHour, ResolutionDay)
+}
+
+// Retention is how far back resolution is kept
+func (r *RollupStore) Retention(resolution string) (time.Duration, error) {
+	i, err := r.level(resolution)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(r.levels[i].slots) * r.levels[i].width, nil
+}
+
+// Query returns key's intervals at resolution from the one holding from
+// through the one holding to, oldest first. Intervals nothing was recorded
+// in are left out.
+func (r *RollupStore) Query(key SeriesKey, resolution string, from, to time.Time) ([]RollupPoint, error) {
+	i, err := r.level(resolution)
+	if err != nil {
+		return nil, err
+	}
+	level := r.levels[i]
+	first, last := level.slot(from), level.slot(to)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	points := []RollupPoint{}
+	s := r.series[key]
+	if s == nil {
+		return points, nil
+	}
+	ring := &s.rings[i]
+	first, last = max(first, ring.last-int64(len(ring.values))+1), min(last, ring.last)
+	for slot := first; slot <= last; slot++ {
+		value, peak, ok := ring.sample(slot)
+		if !ok {
+			continue
+		}
+		p := RollupPoint{Time: time.Unix(0, slot*int64(level.width)).UTC(), Value: value}
+		if s.gauge {
+			p.Max = peak
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// Series lists tenantID's series, sorted
+func (r *RollupStore) Series(tenantID string) []SeriesKey {
+	r.mu.Lock()
+	var keys []SeriesKey
+	for k := range r.series {
+		if k.TenantID == tenantID {
+			keys = append(keys, k)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Bucket != keys[j].Bucket {
+			return keys[i].Bucket < keys[j].Bucket
+		}
+		return keys[i].Metric < keys[j].Metric
+	})
+	return keys
+}
+
+// DeleteTenant forgets every series of tenantID
+func (r *RollupStore) DeleteTenant(tenantID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.series {
+		if k.TenantID == tenantID {
+			delete(r.series, k)
+		}
+	}
+}
+
+// Prune forgets series with nothing recorded within any resolution's
+// retention and returns how many were dropped
+func (r *RollupStore) Prune() int {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var dropped int
+next:
+	for k, s := range r.series {
+		for i, level := range r.levels {
+			if s.rings[i].last > level.slot(now)-int64(level.slots) {
+				continue next
+			}
+		}
+		delete(r.series, k)
+		dropped++
+	}
+	return dropped
+}
+
+// SeriesCount is the number of series tracked
+func (r *RollupStore) SeriesCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.series)
+}
+
+// Dropped is the number of samples not recorded because MaxSeries series
+// were already tracked
+func (r *RollupStore) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// savedSeries is a series as saved: for each resolution, the recorded
+// slots as [slot, value, max]
+type savedSeries struct {
+	SeriesKey
+	Gauge  bool                  `json:"gauge,omitempty"`
+	Points map[string][][3]int64 `json:"points"`
+}
+
+// Save writes every series to path, replacing it atomically
+func (r *RollupStore) Save(path string) error {
+	r.mu.Lock()
+	saved := make([]savedSeries, 0, len(r.series))
+	for k, s := range r.series {
+		ss := savedSeries{SeriesKey: k, Gauge: s.gauge, Points: make(map[string][][3]int64, len(r.levels))}
+		for i, level := range r.levels {
+			ring := &s.rings[i]
+			for slot := ring.last - int64(len(ring.values)) + 1; slot <= ring.last; slot++ {
+				if value, max, ok := ring.sample(slot); ok {
+					ss.Points[level.name] = append(ss.Points[level.name], [3]int64{slot, value, max})
+				}
+			}
+		}
+		saved = append(saved, ss)
+	}
+	r.mu.Unlock()
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create rollup directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write rollups: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write rollups: %w", err)
+	}
+	return nil
+}
+
+// Load restores series saved at path, keeping what each resolution still
+// retains. A missing file is not an error.
+func (r *RollupStore) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read rollups: %w", err)
+	}
+	var saved []savedSeries
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse rollups: %w", err)
+	}
+
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ss := range saved {
+		if len(r.series) >= r.maxSeries {
+			break
+		}
+		s := &rollupSeries{gauge: ss.Gauge, rings: make([]rollupRing, len(r.levels))}
+		for i, level := range r.levels {
+			ring := newRollupRing(level.slots, level.slot(now))
+			for _, p := range ss.Points[level.name] {
+				if slot := p[0]; slot <= ring.last && slot > ring.last-int64(level.slots) {
+					j := slot % int64(level.slots)
+					ring.values[j], ring.maxes[j] = p[1], p[2]
+				}
+			}
+			s.rings[i] = ring
+		}
+		r.series[ss.SeriesKey] = s
+	}
+	return nil
+}
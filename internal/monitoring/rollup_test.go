@@ -0,0 +1,128 @@
+package monitoring
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestRollups returns a store on a manual clock keeping an hour of
+// minutes, a day of hours and three days
+func newTestRollups(maxSeries int) (*RollupStore, *time.Time) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRollupStore(RollupConfig{MinuteRetention: time.Hour, HourRetention: 24 * time.Hour,
+		DayRetention: 3 * 24 * time.Hour, MaxSeries: maxSeries})
+	r.now = func() time.Time { return clock }
+	return r, &clock
+}
+
+func TestRollupStore_Resolutions(t *testing.T) {
+	r, clock := newTestRollups(0)
+	requests := SeriesKey{TenantID: "t1", Metric: "requests"}
+	storage := SeriesKey{TenantID: "t1", Bucket: "photos", Metric: "storage_bytes"}
+	start := *clock
+
+	r.Add(requests, 2)
+	r.Set(storage, 100)
+	*clock = clock.Add(30 * time.Second)
+	r.Add(requests, 3)
+	r.Set(storage, 300)
+	r.Set(storage, 200)
+	*clock = clock.Add(time.Minute)
+	r.Add(requests, 1)
+	r.Set(storage, 150)
+
+	points, err := r.Query(requests, ResolutionMinute, start, *clock)
+	want := []RollupPoint{{Time: start, Value: 5}, {Time: start.Add(time.Minute), Value: 1}}
+	if err != nil || !reflect.DeepEqual(points, want) {
+		t.Errorf("Minutes = %+v, %v; want %+v", points, err, want)
+	}
+	points, _ = r.Query(storage, ResolutionHour, start, *clock)
+	if want := []RollupPoint{{Time: start, Value: 150, Max: 300}}; !reflect.DeepEqual(points, want) {
+		t.Errorf("Hours = %+v, want %+v", points, want)
+	}
+
+	// Two days on, the minutes are gone and the days remain
+	*clock = clock.Add(48 * time.Hour)
+	r.Add(requests, 7)
+	points, _ = r.Query(requests, ResolutionMinute, start, *clock)
+	if len(points) != 1 || points[0].Value != 7 {
+		t.Errorf("Minutes after two days = %+v", points)
+	}
+	points, _ = r.Query(requests, ResolutionDay, start, *clock)
+	want = []RollupPoint{{Time: start, Value: 6}, {Time: start.Add(48 * time.Hour), Value: 7}}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("Days = %+v, want %+v", points, want)
+	}
+
+	if _, err := r.Query(requests, "1w", start, *clock); err == nil {
+		t.Error("Expected an unknown resolution refused")
+	}
+	if got := r.Series("t1"); !reflect.DeepEqual(got, []SeriesKey{requests, storage}) {
+		t.Errorf("Series = %+v", got)
+	}
+}
+
+func TestRollupStore_MaxSeriesAndPrune(t *testing.T) {
+	r, clock := newTestRollups(2)
+	r.Add(SeriesKey{TenantID: "t1", Metric: "a"}, 1)
+	r.Add(SeriesKey{TenantID: "t1", Metric: "b"}, 1)
+	r.Add(SeriesKey{TenantID: "t1", Metric: "c"}, 1)
+	if r.SeriesCount() != 2 || r.Dropped() != 1 {
+		t.Errorf("Expected 2 series and 1 dropped sample, got %d and %d", r.SeriesCount(), r.Dropped())
+	}
+
+	*clock = clock.Add(2 * 24 * time.Hour)
+	r.Add(SeriesKey{TenantID: "t1", Metric: "a"}, 1)
+	if n := r.Prune(); n != 0 {
+		t.Errorf("Pruned %d series still within the day retention", n)
+	}
+	*clock = clock.Add(2 * 24 * time.Hour)
+	if n := r.Prune(); n != 1 || r.SeriesCount() != 1 {
+		t.Errorf("Pruned %d, %d left; want 1 pruned, 1 left", n, r.SeriesCount())
+	}
+
+	r.DeleteTenant("t1")
+	if r.SeriesCount() != 0 {
+		t.Errorf("%d series left after DeleteTenant", r.SeriesCount())
+	}
+}
+
+func TestRollupStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollups.json")
+	r, clock := newTestRollups(0)
+	start := *clock
+	key := SeriesKey{TenantID: "t1", Bucket: "b", Metric: "objects"}
+	r.Set(key, 4)
+	*clock = clock.Add(time.Hour)
+	r.Set(key, 6)
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, lclock := newTestRollups(0)
+	*lclock = clock.Add(2 * time.Hour)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	hours, _ := loaded.Query(key, ResolutionHour, start, *lclock)
+	want := []RollupPoint{{Time: start, Value: 4, Max: 4}, {Time: start.Add(time.Hour), Value: 6, Max: 6}}
+	if !reflect.DeepEqual(hours, want) {
+		t.Errorf("Hours after Load = %+v, want %+v", hours, want)
+	}
+	// The minutes fell out of their hour's retention while the node was down
+	if minutes, _ := loaded.Query(key, ResolutionMinute, start, *lclock); len(minutes) != 0 {
+		t.Errorf("Minutes after Load = %+v, want none", minutes)
+	}
+	// Restored series keep being recorded as gauges
+	loaded.Set(key, 1)
+	days, _ := loaded.Query(key, ResolutionDay, start, *lclock)
+	if len(days) != 1 || days[0].Value != 1 || days[0].Max != 6 {
+		t.Errorf("Days after Load = %+v", days)
+	}
+
+	if err := NewRollupStore(RollupConfig{}).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Load of a missing file = %v", err)
+	}
+}
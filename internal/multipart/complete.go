@@ -0,0 +1,91 @@
+// internal/multipart/complete.go
+// Completing a multipart upload: the client's part list is checked against
+// the parts received, by number, ETag and checksum, and the object's
+// ETag and checksum are composed from the parts' the way S3 composes them
+package multipart
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// S3's part limits
+const (
+	DefaultMinPartSize = 5 << 20
+	MaxParts           = 10000
+)
+
+var (
+	ErrNoSuchUpload     = errors.New("no such multipart upload")
+	ErrInvalidPart      = errors.New("part not uploaded or its ETag does not match")
+	ErrInvalidPartOrder = errors.New("parts not in ascending order")
+	ErrEntityTooSmall   = errors.New("part smaller than the minimum part size")
+	ErrBadDigest        = errors.New("part checksum does not match")
+	ErrInvalidPartNum   = errors.New("part number out of range")
+)
+
+// CompletedPart is one entry of a client's CompleteMultipartUpload list
+type CompletedPart struct {
+	PartNumber     int    `json:"part_number" xml:"PartNumber"`
+	ETag           string `json:"etag" xml:"ETag"`
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty" xml:"ChecksumSHA256,omitempty"` // Base64, as S3 takes it; optional
+}
+
+// verifyParts checks list against the parts received and returns those
+// parts in list order
+func verifyParts(received map[int]Part, list []CompletedPart, minPartSize int64) ([]Part, error) {
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: no parts given", ErrInvalidPart)
+	}
+	parts := make([]Part, 0, len(list))
+	for i, c := range list {
+		if i > 0 && c.PartNumber <= list[i-1].PartNumber {
+			return nil, fmt.Errorf("%w: part %d after part %d", ErrInvalidPartOrder, c.PartNumber, list[i-1].PartNumber)
+		}
+		p, ok := received[c.PartNumber]
+		if !ok || !strings.EqualFold(strings.Trim(c.ETag, `"`), p.ETag) {
+			return nil, fmt.Errorf("%w: part %d", ErrInvalidPart, c.PartNumber)
+		}
+		if c.ChecksumSHA256 != "" && c.ChecksumSHA256 != p.checksumBase64() {
+			return nil, fmt.Errorf("%w: part %d", ErrBadDigest, c.PartNumber)
+		}
+		if i < len(list)-1 && p.Size < minPartSize {
+			return nil, fmt.Errorf("%w: part %d is %d bytes", ErrEntityTooSmall, c.PartNumber, p.Size)
+		}
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// CompositeETag is S3's ETag for an object uploaded in parts: the MD5 of
+// the parts' binary MD5s, then a dash and the number of parts
+func CompositeETag(parts []Part) string {
+	h := md5.New()
+	for _, p := range parts {
+		sum, _ := hex.DecodeString(p.ETag)
+		h.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(parts))
+}
+
+// CompositeChecksum is S3's composite SHA-256 checksum: the base64
+// SHA-256 of the parts' binary SHA-256s, then a dash and the number of
+// parts
+func CompositeChecksum(parts []Part) string {
+	h := sha256.New()
+	for _, p := range parts {
+		sum, _ := hex.DecodeString(p.SHA256)
+		h.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(parts))
+}
+
+func (p Part) checksumBase64() string {
+	sum, _ := hex.DecodeString(p.SHA256)
+	return base64.StdEncoding.EncodeToString(sum)
+}
@@ -0,0 +1,751 @@
+// internal/multipart/store.go
+// Staging for multipart uploads. Each part is streamed to a chunk file as
+// it arrives, hashed with MD5 (its ETag) and SHA-256, and checked against
+// the digests the client sent. A completed upload becomes a Manifest: the
+// object as a list of chunk references, read back in order, so parts are
+// never copied into one buffer. With Dedup, chunks are addressed by their
+// SHA-256 and identical parts share one file, reference-counted across
+// uploads and manifests.
+package multipart
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// Options configures a Store
+type Options struct {
+	// Dedup stores identical parts once
+	Dedup bool
+
+	// MinPartSize is the smallest part but the last that Complete accepts;
+	// 0 means DefaultMinPartSize
+	MinPartSize int64
+}
+
+// Part is a part received for an upload
+type Part struct {
+	Number   int       `json:"part_number"`
+	Size     int64     `json:"size"`
+	ETag     string    `json:"etag"`   // Hex MD5
+	SHA256   string    `json:"sha256"` // Hex
+	Chunk    string    `json:"chunk"`  // The chunk file holding the data
+	Uploaded time.Time `json:"uploaded"`
+}
+
+// Upload is a multipart upload in progress
+type Upload struct {
+	ID      string       `json:"id"`
+	Tenant  string       `json:"tenant"`
+	Key     string       `json:"key"`
+	Created time.Time    `json:"created"`
+	Parts   map[int]Part `json:"parts"`
+
+	// The completed object is written with these
+	metadata.Attributes
+}
+
+// Manifest is a completed upload: its object is its parts' chunks in order
+type Manifest struct {
+	ID             string `json:"id"` // The upload's ID
+	Tenant         string `json:"tenant"`
+	Key            string `json:"key"`
+	Size           int64  `json:"size"`
+	ETag           string `json:"etag"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+	Parts          []Part `json:"parts"`
+}
+
+// Digests are the digests a client sent with a part, base64 as in the
+// Content-MD5 and x-amz-checksum-sha256 headers; empty ones are not checked
+type Digests struct {
+	MD5    string
+	SHA256 string
+}
+
+// Stats describes a store's contents
+type Stats struct {
+	Uploads      int    `json:"uploads"`
+	Manifests    int    `json:"manifests"`
+	Chunks       int    `json:"chunks"`
+	ChunkBytes   int64  `json:"chunk_bytes"`
+	DedupedBytes uint64 `json:"deduped_bytes"` // Part bytes not stored because an identical chunk was, since open
+}
+
+// Store stages multipart uploads under a directory
+type Store struct {
+	dir  string
+	opts Options
+
+	mu        sync.Mutex
+	uploads   map[string]*Upload
+	manifests map[string]*Manifest
+	refs      map[string]int   // Chunk -> parts, manifests and readers referencing it
+	sizes     map[string]int64 // Chunk -> bytes
+	deduped   uint64
+}
+
+// OpenStore opens the store in dir, creating it if needed. Chunks no
+// upload or manifest references, left by a crash mid-part, are removed.
+func OpenStore(dir string, opts Options) (*Store, error) {
+	if opts.MinPartSize <= 0 {
+		opts.MinPartSize = DefaultMinPartSize
+	}
+	s := &Store{
+		dir:       dir,
+		opts:      opts,
+		uploads:   make(map[string]*Upload),
+		manifests: make(map[string]*Manifest),
+		refs:      make(map[string]int),
+		sizes:     make(map[string]int64),
+	}
+	os.RemoveAll(s.path("tmp"))
+	for _, sub := range []string{"chunks", "uploads", "manifests", "tmp"} {
+		if err := os.MkdirAll(s.path(sub), 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create multipart store: %w", err)
+		}
+	}
+
+	if err := loadRecords(s.path("uploads"), func(up *Upload) {
+		s.uploads[up.ID] = up
+		for _, p := range up.Parts {
+			s.ref(p)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if err := loadRecords(s.path("manifests"), func(m *Manifest) {
+		s.manifests[m.ID] = m
+		for _, p := range m.Parts {
+			s.ref(p)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(s.path("chunks"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read multipart chunks: %w", err)
+	}
+	for _, e := range entries {
+		if s.refs[e.Name()] == 0 {
+			os.Remove(s.path("chunks", e.Name()))
+		}
+	}
+	return s, nil
+}
+
+func loadRecords[T any](dir string, add func(*T)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", e.Name(), err)
+		}
+		rec := new(T)
+		if err := json.Unmarshal(data, rec); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", e.Name(), err)
+		}
+		add(rec)
+	}
+	return nil
+}
+
+func (s *Store) path(elem ...string) string {
+	return filepath.Join(append([]string{s.dir}, elem...)...)
+}
+
+// Initiate starts an upload of tenantID's key, whose object is written
+// with attrs
+func (s *Store) Initiate(tenantID, key string, attrs metadata.Attributes) (*Upload, error) {
+	up := &Upload{ID: newID(), Tenant: tenantID, Key: key, Created: time.Now().UTC(), Parts: make(map[int]Part), Attributes: attrs}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeRecord(s.path("uploads", up.ID+".json"), up); err != nil {
+		return nil, err
+	}
+	s.uploads[up.ID] = up
+	return up.clone(), nil
+}
+
+// Get returns an upload in progress
+func (s *Store) Get(uploadID string) (*Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[uploadID]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+	return up.clone(), nil
+}
+
+// Uploads returns tenantID's uploads in progress, oldest first
+func (s *Store) Uploads(tenantID string) []*Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*Upload
+	for _, up := range s.uploads {
+		if up.Tenant == tenantID {
+			out = append(out, up.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// PutPart stores part number of an upload from r, replacing any part
+// already uploaded under that number
+func (s *Store) PutPart(uploadID string, number int, r io.Reader, want Digests) (Part, error) {
+	if number < 1 || number > MaxParts {
+		return Part{}, ErrInvalidPartNum
+	}
+	if _, err := s.Get(uploadID); err != nil {
+		return Part{}, err
+	}
+
+	tmp, part, err := s.receive(r)
+	if err != nil {
+		return Part{}, err
+	}
+	defer os.Remove(tmp) // Gone already if it became the chunk
+	if want.MD5 != "" && want.MD5 != b64(part.ETag) || want.SHA256 != "" && want.SHA256 != b64(part.SHA256) {
+		return Part{}, ErrBadDigest
+	}
+	part.Number = number
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[uploadID]
+	if !ok {
+		return Part{}, ErrNoSuchUpload // Aborted while the part was arriving
+	}
+
+	if err := s.keep(tmp, &part); err != nil {
+		return Part{}, err
+	}
+
+	prev, replaced := up.Parts[part.Number]
+	up.Parts[part.Number] = part
+	if err := writeRecord(s.path("uploads", up.ID+".json"), up); err != nil {
+		if replaced {
+			up.Parts[part.Number] = prev
+		} else {
+			delete(up.Parts, part.Number)
+		}
+		s.release(part.Chunk)
+		return Part{}, err
+	}
+	if replaced {
+		s.release(prev.Chunk)
+	}
+	return part, nil
+}
+
+// PutObject stores r whole as a completed object of tenantID's key,
+// streamed to a chunk as a part is. Its ETag and checksum are those of a
+// single-part upload: the hex MD5 and base64 SHA-256 of the data.
+func (s *Store) PutObject(tenantID, key string, r io.Reader) (*Manifest, error) {
+	tmp, part, err := s.receive(r)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp) // Gone already if it became the chunk
+	part.Number = 1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.keep(tmp, &part); err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		ID:             newID(),
+		Tenant:         tenantID,
+		Key:            key,
+		Size:           part.Size,
+		ETag:           part.ETag,
+		ChecksumSHA256: b64(part.SHA256),
+		Parts:          []Part{part},
+	}
+	if err := writeRecord(s.path("manifests", m.ID+".json"), m); err != nil {
+		s.release(part.Chunk)
+		return nil, err
+	}
+	s.manifests[m.ID] = m
+	return m.clone(), nil
+}
+
+// Copy makes a manifest for tenantID's key holding the same chunks as
+// manifest id, so a server-side copy shares the data instead of writing it
+// again. The chunks stay until both manifests are deleted.
+func (s *Store) Copy(id, tenantID, key string) (*Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.manifests[id]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+	m := src.clone()
+	m.ID, m.Tenant, m.Key = newID(), tenantID, key
+	if err := writeRecord(s.path("manifests", m.ID+".json"), m); err != nil {
+		return nil, err
+	}
+	for _, p := range m.Parts {
+		s.ref(p)
+	}
+	s.manifests[m.ID] = m
+	return m.clone(), nil
+}
+
+// keep makes the received file tmp part's chunk, or with Dedup references
+// an identical chunk already stored, and takes a reference on it; the
+// caller holds s.mu
+func (s *Store) keep(tmp string, part *Part) error {
+	part.Chunk = part.SHA256
+	if !s.opts.Dedup {
+		part.Chunk += "-" + newID()
+	}
+	if s.refs[part.Chunk] > 0 {
+		s.deduped += uint64(part.Size)
+	} else if err := os.Rename(tmp, s.path("chunks", part.Chunk)); err != nil {
+		return fmt.Errorf("failed to store part: %w", err)
+	}
+	s.ref(*part)
+	return nil
+}
+
+// receive streams r to a temporary file, hashing it
+func (s *Store) receive(r io.Reader) (string, Part, error) {
+	f, err := os.CreateTemp(s.path("tmp"), "part-")
+	if err != nil {
+		return "", Part{}, fmt.Errorf("failed to store part: %w", err)
+	}
+	md5h, sha := md5.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, md5h, sha), r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", Part{}, fmt.Errorf("failed to receive part: %w", err)
+	}
+	return f.Name(), Part{Size: n, ETag: hexSum(md5h), SHA256: hexSum(sha), Uploaded: time.Now().UTC()}, nil
+}
+
+// Complete finishes an upload from the client's part list, returning its
+// manifest. Parts received but not listed are discarded.
+func (s *Store) Complete(uploadID string, list []CompletedPart) (*Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[uploadID]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+	parts, err := verifyParts(up.Parts, list, s.opts.MinPartSize)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		ID:             up.ID,
+		Tenant:         up.Tenant,
+		Key:            up.Key,
+		ETag:           CompositeETag(parts),
+		ChecksumSHA256: CompositeChecksum(parts),
+		Parts:          parts,
+	}
+	for _, p := range parts {
+		m.Size += p.Size
+	}
+	if err := writeRecord(s.path("manifests", m.ID+".json"), m); err != nil {
+		return nil, err
+	}
+
+	// The manifest takes over the listed parts' references
+	listed := make(map[int]bool, len(parts))
+	for _, p := range parts {
+		listed[p.Number] = true
+	}
+	for n, p := range up.Parts {
+		if !listed[n] {
+			s.release(p.Chunk)
+		}
+	}
+	os.Remove(s.path("uploads", up.ID+".json"))
+	delete(s.uploads, up.ID)
+	s.manifests[m.ID] = m
+	return m.clone(), nil
+}
+
+// Abort discards an upload and its parts
+func (s *Store) Abort(uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[uploadID]
+	if !ok {
+		return ErrNoSuchUpload
+	}
+	if err := os.Remove(s.path("uploads", up.ID+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to abort upload: %w", err)
+	}
+	for _, p := range up.Parts {
+		s.release(p.Chunk)
+	}
+	delete(s.uploads, up.ID)
+	return nil
+}
+
+// AbortStale aborts uploads started before cutoff, returning how many
+func (s *Store) AbortStale(cutoff time.Time) int {
+	s.mu.Lock()
+	var stale []string
+	for id, up := range s.uploads {
+		if up.Created.Before(cutoff) {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.Unlock()
+
+	n := 0
+	for _, id := range stale {
+		if s.Abort(id) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Manifest returns a completed upload's manifest
+func (s *Store) Manifest(id string) (*Manifest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.manifests[id]
+	if !ok {
+		return nil, false
+	}
+	return m.clone(), true
+}
+
+// ManifestIDs returns the IDs of the completed uploads' manifests
+func (s *Store) ManifestIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.manifests))
+	for id := range s.manifests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Delete drops a manifest, removing chunks nothing else references. Open
+// readers keep reading their chunks.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.manifests[id]
+	if !ok {
+		return ErrNoSuchUpload
+	}
+	if err := os.Remove(s.path("manifests", id+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete manifest: %w", err)
+	}
+	for _, p := range m.Parts {
+		s.release(p.Chunk)
+	}
+	delete(s.manifests, id)
+	return nil
+}
+
+// Open reads a manifest's object, chunk by chunk. The reader holds the
+// chunks until it is closed.
+func (s *Store) Open(id string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.manifests[id]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+	r := &manifestReader{store: s}
+	for _, p := range m.Parts {
+		s.ref(p)
+		r.chunks = append(r.chunks, p.Chunk)
+	}
+	r.held = r.chunks
+	return r, nil
+}
+
+// OpenAt opens a manifest's object for reads at any offset. The reader
+// holds the chunks until it is closed.
+func (s *Store) OpenAt(id string) (*ObjectReader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.manifests[id]
+	if !ok {
+		return nil, ErrNoSuchUpload
+	}
+	r := &ObjectReader{store: s, files: make([]*os.File, len(m.Parts))}
+	for _, p := range m.Parts {
+		s.ref(p)
+		r.chunks = append(r.chunks, p.Chunk)
+		r.offsets = append(r.offsets, r.size)
+		r.size += p.Size
+	}
+	return r, nil
+}
+
+// ChunkDir is the directory chunk files are kept in, named by chunk
+func (s *Store) ChunkDir() string {
+	return s.path("chunks")
+}
+
+// Stats returns the store's counts
+func (s *Store) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Stats{Uploads: len(s.uploads), Manifests: len(s.manifests), Chunks: len(s.sizes), DedupedBytes: s.deduped}
+	for _, n := range s.sizes {
+		st.ChunkBytes += n
+	}
+	return st
+}
+
+// ref takes a reference on p's chunk; the caller holds s.mu
+func (s *Store) ref(p Part) {
+	s.refs[p.Chunk]++
+	s.sizes[p.Chunk] = p.Size
+}
+
+// release drops a reference on chunk, removing it with the last; the
+// caller holds s.mu
+func (s *Store) release(chunk string) {
+	if s.refs[chunk]--; s.refs[chunk] > 0 {
+		return
+	}
+	delete(s.refs, chunk)
+	delete(s.sizes, chunk)
+	os.Remove(s.path("chunks", chunk))
+}
+
+// manifestReader reads chunks in order, opening each as it is reached
+type manifestReader struct {
+	store  *Store
+	chunks []string // Still to read
+	held   []string // Referenced until Close
+	cur    *os.File
+}
+
+func (r *manifestReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(r.store.path("chunks", r.chunks[0]))
+			if err != nil {
+				return 0, fmt.Errorf("failed to open part: %w", err)
+			}
+			r.cur, r.chunks = f, r.chunks[1:]
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			r.cur.Close()
+			r.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *manifestReader) Close() error {
+	if r.cur != nil {
+		r.cur.Close()
+		r.cur = nil
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, c := range r.held {
+		r.store.release(c)
+	}
+	r.held, r.chunks = nil, nil
+	return nil
+}
+
+// ObjectReader reads a manifest's object at any offset, opening each
+// chunk the first time it is reached. It is safe for concurrent reads.
+type ObjectReader struct {
+	store   *Store
+	chunks  []string
+	offsets []int64 // Of each chunk within the object
+	size    int64
+
+	mu     sync.Mutex
+	files  []*os.File
+	closed bool
+}
+
+// Size is the object's length
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("multipart: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	// The last chunk starting at or before off
+	i := sort.Search(len(r.offsets), func(i int) bool { return r.offsets[i] > off }) - 1
+
+	n := 0
+	for n < len(p) && i < len(r.chunks) {
+		f, err := r.file(i)
+		if err != nil {
+			return n, err
+		}
+		end := r.size
+		if i+1 < len(r.offsets) {
+			end = r.offsets[i+1]
+		}
+		want := p[n:min(len(p), n+int(end-off))]
+		m, err := f.ReadAt(want, off-r.offsets[i])
+		n += m
+		off += int64(m)
+		if m < len(want) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		i++
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *ObjectReader) file(i int) (*os.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, os.ErrClosed
+	}
+	if r.files[i] == nil {
+		f, err := os.Open(r.store.path("chunks", r.chunks[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open part: %w", err)
+		}
+		r.files[i] = f
+	}
+	return r.files[i], nil
+}
+
+// Close closes the chunk files and releases the chunks
+func (r *ObjectReader) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	for _, f := range r.files {
+		if f != nil {
+			f.Close()
+		}
+	}
+	r.mu.Unlock()
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, c := range r.chunks {
+		r.store.release(c)
+	}
+	return nil
+}
+
+func (up *Upload) clone() *Upload {
+	c := *up
+	c.Parts = make(map[int]Part, len(up.Parts))
+	for n, p := range up.Parts {
+		c.Parts[n] = p
+	}
+	return &c
+}
+
+func (m *Manifest) clone() *Manifest {
+	c := *m
+	c.Parts = append([]Part(nil), m.Parts...)
+	return &c
+}
+
+// writeRecord writes v as JSON to path atomically
+func writeRecord(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// b64 re-encodes a hex digest as base64
+func b64(hexDigest string) string {
+	sum, _ := hex.DecodeString(hexDigest)
+	return base64.StdEncoding.EncodeToString(sum)
+}
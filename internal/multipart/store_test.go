@@ -0,0 +1,392 @@
+package multipart
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+func newTestStore(t *testing.T, dedup bool) (*Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := OpenStore(dir, Options{Dedup: dedup, MinPartSize: 4})
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	return s, dir
+}
+
+func putParts(t *testing.T, s *Store, uploadID string, parts ...string) []CompletedPart {
+	t.Helper()
+	var list []CompletedPart
+	for i, data := range parts {
+		p, err := s.PutPart(uploadID, i+1, bytes.NewReader([]byte(data)), Digests{})
+		if err != nil {
+			t.Fatalf("PutPart(%d): %v", i+1, err)
+		}
+		list = append(list, CompletedPart{PartNumber: p.Number, ETag: `"` + p.ETag + `"`})
+	}
+	return list
+}
+
+func readObject(t *testing.T, s *Store, id string) string {
+	t.Helper()
+	r, err := s.Open(id)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return string(data)
+}
+
+// The object reads back as its parts in order, with S3's composite ETag
+// and checksum
+func TestCompleteComposesObject(t *testing.T) {
+	s, _ := newTestStore(t, false)
+	up, err := s.Initiate("t1", "big", metadata.Attributes{})
+	if err != nil {
+		t.Fatalf("Initiate: %v", err)
+	}
+	list := putParts(t, s, up.ID, "aaaa", "bbbb", "cc")
+
+	m, err := s.Complete(up.ID, list)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got := readObject(t, s, m.ID); got != "aaaabbbbcc" || m.Size != 10 {
+		t.Errorf("Object = %q (%d bytes)", got, m.Size)
+	}
+
+	md5s, shas := md5.New(), sha256.New()
+	for _, part := range []string{"aaaa", "bbbb", "cc"} {
+		sum := md5.Sum([]byte(part))
+		md5s.Write(sum[:])
+		sha := sha256.Sum256([]byte(part))
+		shas.Write(sha[:])
+	}
+	if want := hex.EncodeToString(md5s.Sum(nil)) + "-3"; m.ETag != want {
+		t.Errorf("ETag = %s, want %s", m.ETag, want)
+	}
+	if want := base64.StdEncoding.EncodeToString(shas.Sum(nil)) + "-3"; m.ChecksumSHA256 != want {
+		t.Errorf("ChecksumSHA256 = %s, want %s", m.ChecksumSHA256, want)
+	}
+	if _, err := s.Get(up.ID); !errors.Is(err, ErrNoSuchUpload) {
+		t.Errorf("Upload still open after Complete: %v", err)
+	}
+}
+
+// A whole object streamed in reads back with a single-part ETag, and a
+// failed read leaves nothing behind
+func TestPutObject(t *testing.T) {
+	s, dir := newTestStore(t, false)
+	m, err := s.PutObject("t1", "whole", bytes.NewReader([]byte("streamed object")))
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if got := readObject(t, s, m.ID); got != "streamed object" || m.Size != 15 {
+		t.Errorf("Object = %q (%d bytes)", got, m.Size)
+	}
+	sum, sha := md5.Sum([]byte("streamed object")), sha256.Sum256([]byte("streamed object"))
+	if m.ETag != hex.EncodeToString(sum[:]) || m.ChecksumSHA256 != base64.StdEncoding.EncodeToString(sha[:]) {
+		t.Errorf("ETag %s, checksum %s", m.ETag, m.ChecksumSHA256)
+	}
+	if _, ok := s.Manifest(m.ID); !ok {
+		t.Error("Manifest not recorded")
+	}
+
+	failing := io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(errors.New("connection reset")))
+	if _, err := s.PutObject("t1", "broken", failing); err == nil {
+		t.Error("Expected a failed read to fail PutObject")
+	}
+	if chunks, _ := os.ReadDir(filepath.Join(dir, "chunks")); len(chunks) != 1 {
+		t.Errorf("%d chunk files on disk, want 1", len(chunks))
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Errorf("%d temporary files left, want 0", len(tmp))
+	}
+}
+
+func TestCompleteRejectsBadLists(t *testing.T) {
+	s, _ := newTestStore(t, false)
+	up, _ := s.Initiate("t1", "k", metadata.Attributes{})
+	list := putParts(t, s, up.ID, "aaaa", "bb", "cccc")
+	sha := sha256.Sum256([]byte("aaaa"))
+
+	for _, tc := range []struct {
+		name string
+		list []CompletedPart
+		want error
+	}{
+		{"empty", nil, ErrInvalidPart},
+		{"out of order", []CompletedPart{list[2], list[0]}, ErrInvalidPartOrder},
+		{"duplicate", []CompletedPart{list[0], list[0]}, ErrInvalidPartOrder},
+		{"wrong etag", []CompletedPart{{PartNumber: 1, ETag: list[1].ETag}}, ErrInvalidPart},
+		{"missing part", []CompletedPart{{PartNumber: 9, ETag: list[0].ETag}}, ErrInvalidPart},
+		{"small middle part", list, ErrEntityTooSmall},
+		{"wrong checksum", []CompletedPart{{PartNumber: 2, ETag: list[1].ETag, ChecksumSHA256: base64.StdEncoding.EncodeToString(sha[:])}}, ErrBadDigest},
+	} {
+		if _, err := s.Complete(up.ID, tc.list); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Complete() = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	// A small last part is fine, and checksums that match are accepted
+	first := list[0]
+	first.ChecksumSHA256 = base64.StdEncoding.EncodeToString(sha[:])
+	if _, err := s.Complete(up.ID, []CompletedPart{first, list[1]}); err != nil {
+		t.Errorf("Complete() = %v", err)
+	}
+	if _, err := s.Complete(up.ID, list); !errors.Is(err, ErrNoSuchUpload) {
+		t.Errorf("Complete() twice = %v", err)
+	}
+}
+
+func TestPutPartVerifiesDigests(t *testing.T) {
+	s, dir := newTestStore(t, false)
+	up, _ := s.Initiate("t1", "k", metadata.Attributes{})
+	data := []byte("payload")
+	md5sum, shasum := md5.Sum(data), sha256.Sum256(data)
+
+	good := Digests{MD5: base64.StdEncoding.EncodeToString(md5sum[:]), SHA256: base64.StdEncoding.EncodeToString(shasum[:])}
+	if _, err := s.PutPart(up.ID, 1, bytes.NewReader(data), good); err != nil {
+		t.Errorf("PutPart with matching digests: %v", err)
+	}
+	if _, err := s.PutPart(up.ID, 2, bytes.NewReader(data), Digests{MD5: good.SHA256}); !errors.Is(err, ErrBadDigest) {
+		t.Errorf("PutPart with a wrong MD5 = %v", err)
+	}
+	if _, err := s.PutPart(up.ID, 2, bytes.NewReader(data), Digests{SHA256: good.MD5}); !errors.Is(err, ErrBadDigest) {
+		t.Errorf("PutPart with a wrong SHA-256 = %v", err)
+	}
+	for _, n := range []int{0, MaxParts + 1} {
+		if _, err := s.PutPart(up.ID, n, bytes.NewReader(data), Digests{}); !errors.Is(err, ErrInvalidPartNum) {
+			t.Errorf("PutPart(%d) = %v", n, err)
+		}
+	}
+	if _, err := s.PutPart("nope", 1, bytes.NewReader(data), Digests{}); !errors.Is(err, ErrNoSuchUpload) {
+		t.Errorf("PutPart to an unknown upload = %v", err)
+	}
+
+	// Rejected parts leave nothing behind
+	if st := s.Stats(); st.Chunks != 1 {
+		t.Errorf("%d chunks stored, want 1", st.Chunks)
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Errorf("%d temporary files left", len(tmp))
+	}
+}
+
+// Identical parts share a chunk, which lives until its last reference goes
+func TestDedupSharesChunks(t *testing.T) {
+	s, _ := newTestStore(t, true)
+	var ids []string
+	for _, key := range []string{"a", "b"} {
+		up, _ := s.Initiate("t1", key, metadata.Attributes{})
+		m, err := s.Complete(up.ID, putParts(t, s, up.ID, "same", "same", "tail-"+key))
+		if err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		ids = append(ids, m.ID)
+	}
+
+	st := s.Stats()
+	if st.Chunks != 3 || st.DedupedBytes != 12 {
+		t.Errorf("Stats = %+v, want 3 chunks and 12 deduped bytes", st)
+	}
+
+	// An open reader keeps its chunks through a delete
+	r, err := s.Open(ids[0])
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := s.Delete(ids[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := readObject(t, s, ids[1]); got != "samesametail-b" {
+		t.Errorf("Second object = %q after deleting the first", got)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "samesametail-a" {
+		t.Errorf("Open reader read %q after delete", data)
+	}
+	r.Close()
+	if st := s.Stats(); st.Chunks != 2 {
+		t.Errorf("%d chunks after deleting the first object, want 2", st.Chunks)
+	}
+
+	s.Delete(ids[1])
+	if st := s.Stats(); st.Chunks != 0 || st.ChunkBytes != 0 {
+		t.Errorf("Stats = %+v after deleting both objects", st)
+	}
+}
+
+// Replaced, unlisted and aborted parts are released
+// A copy shares the source's chunks, which stay until both manifests go,
+// and survives a reopen
+func TestCopySharesChunks(t *testing.T) {
+	s, dir := newTestStore(t, false)
+	src, err := s.PutObject("t1", "src", bytes.NewReader([]byte("shared data")))
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	cp, err := s.Copy(src.ID, "t2", "dst")
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if cp.ID == src.ID || cp.Tenant != "t2" || cp.Key != "dst" || cp.ETag != src.ETag || cp.Size != src.Size {
+		t.Errorf("Copy = %+v", cp)
+	}
+	if st := s.Stats(); st.Chunks != 1 || st.Manifests != 2 {
+		t.Errorf("%d chunks and %d manifests after copy, want 1 and 2", st.Chunks, st.Manifests)
+	}
+	if _, err := s.Copy("missing", "t2", "dst"); !errors.Is(err, ErrNoSuchUpload) {
+		t.Errorf("Copy(missing) = %v", err)
+	}
+
+	if err := s.Delete(src.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := readObject(t, s, cp.ID); got != "shared data" {
+		t.Errorf("Copy = %q after deleting the source", got)
+	}
+
+	s2, err := OpenStore(dir, Options{MinPartSize: 4})
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if got := readObject(t, s2, cp.ID); got != "shared data" {
+		t.Errorf("Copy = %q after reopen", got)
+	}
+	if err := s2.Delete(cp.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if chunks, _ := os.ReadDir(filepath.Join(dir, "chunks")); len(chunks) != 0 {
+		t.Errorf("%d chunk files on disk after deleting both, want 0", len(chunks))
+	}
+}
+
+func TestPartsReleased(t *testing.T) {
+	s, dir := newTestStore(t, false)
+	up, _ := s.Initiate("t1", "k", metadata.Attributes{})
+	putParts(t, s, up.ID, "old1", "part2", "part3")
+	list := putParts(t, s, up.ID, "new1")
+	if st := s.Stats(); st.Chunks != 3 {
+		t.Errorf("%d chunks after replacing part 1, want 3", st.Chunks)
+	}
+	if _, err := s.Complete(up.ID, list); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if st := s.Stats(); st.Chunks != 1 {
+		t.Errorf("%d chunks after completing with one part, want 1", st.Chunks)
+	}
+
+	up2, _ := s.Initiate("t1", "k2", metadata.Attributes{})
+	putParts(t, s, up2.ID, "xxxx", "yyyy")
+	if err := s.Abort(up2.ID); err != nil {
+		t.Fatalf("Abort: %v", err)
+	}
+	if err := s.Abort(up2.ID); !errors.Is(err, ErrNoSuchUpload) {
+		t.Errorf("Abort twice = %v", err)
+	}
+	if chunks, _ := os.ReadDir(filepath.Join(dir, "chunks")); len(chunks) != 1 {
+		t.Errorf("%d chunk files on disk, want 1", len(chunks))
+	}
+}
+
+// Uploads and manifests survive a reopen, and unreferenced chunks do not
+func TestReopen(t *testing.T) {
+	s, dir := newTestStore(t, true)
+	done, _ := s.Initiate("t1", "done", metadata.Attributes{})
+	m, err := s.Complete(done.ID, putParts(t, s, done.ID, "dddd", "ee"))
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	open, _ := s.Initiate("t1", "open", metadata.Attributes{Tags: map[string]string{"class": "archive"}})
+	putParts(t, s, open.ID, "dddd", "ffff")
+	os.WriteFile(filepath.Join(dir, "chunks", "orphan"), []byte("x"), 0o640)
+
+	s2, err := OpenStore(dir, Options{Dedup: true, MinPartSize: 4})
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if got := readObject(t, s2, m.ID); got != "ddddee" {
+		t.Errorf("Object = %q after reopen", got)
+	}
+	if ups := s2.Uploads("t1"); len(ups) != 1 || len(ups[0].Parts) != 2 || ups[0].Tags["class"] != "archive" {
+		t.Fatalf("Uploads = %+v after reopen", ups)
+	}
+	if st := s2.Stats(); st.Chunks != 3 {
+		t.Errorf("%d chunks after reopen, want 3", st.Chunks)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chunks", "orphan")); !os.IsNotExist(err) {
+		t.Errorf("Orphan chunk survived reopen: %v", err)
+	}
+
+	// The shared chunk is still held by the manifest once the upload goes
+	s2.Abort(open.ID)
+	if got := readObject(t, s2, m.ID); got != "ddddee" {
+		t.Errorf("Object = %q after aborting the sharing upload", got)
+	}
+}
+
+// Reads at any offset span chunk boundaries, and the reader keeps its
+// chunks past a Delete
+func TestOpenAt(t *testing.T) {
+	s, _ := newTestStore(t, false)
+	up, err := s.Initiate("t1", "big", metadata.Attributes{})
+	if err != nil {
+		t.Fatalf("Initiate: %v", err)
+	}
+	m, err := s.Complete(up.ID, putParts(t, s, up.ID, "aaaa", "bbbb", "cc"))
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	r, err := s.OpenAt(m.ID)
+	if err != nil {
+		t.Fatalf("OpenAt: %v", err)
+	}
+	defer r.Close()
+	if err := s.Delete(m.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if r.Size() != 10 {
+		t.Errorf("Size = %d, want 10", r.Size())
+	}
+	for _, tc := range []struct {
+		off  int64
+		n    int
+		want string
+		err  error
+	}{
+		{0, 10, "aaaabbbbcc", nil},
+		{3, 3, "abb", nil},
+		{4, 4, "bbbb", nil},
+		{7, 2, "bc", nil},
+		{8, 4, "cc", io.EOF},
+		{10, 1, "", io.EOF},
+	} {
+		buf := make([]byte, tc.n)
+		n, err := r.ReadAt(buf, tc.off)
+		if string(buf[:n]) != tc.want || err != tc.err {
+			t.Errorf("ReadAt(%d, %d) = %q, %v; want %q, %v", tc.off, tc.n, buf[:n], err, tc.want, tc.err)
+		}
+	}
+
+	r.Close()
+	if st := s.Stats(); st.Chunks != 0 {
+		t.Errorf("%d chunks left after the reader closed", st.Chunks)
+	}
+}
@@ -0,0 +1,244 @@
+// internal/notify/webhook.go
+// Signed webhook delivery of lifecycle events to external systems. Each
+// endpoint has its own queue and worker so events reach it in order, and a
+// slow or failing endpoint never delays the others.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Tenant lifecycle event types
+const (
+	EventTenantCreated       = "tenant.created"
+	EventTenantSuspended     = "tenant.suspended"
+	EventTenantResumed       = "tenant.resumed"
+	EventTenantQuotaExceeded = "tenant.quota_exceeded"
+	EventTenantDeleted       = "tenant.deleted"
+)
+
+// Object event types
+const (
+	EventObjectQuarantined       = "object.quarantined"
+	EventObjectReleased          = "object.quarantine_released"
+	EventObjectQuarantineDeleted = "object.quarantine_deleted"
+)
+
+// Delivery headers
+const (
+	HeaderEvent     = "X-MinIO-Event"
+	HeaderDelivery  = "X-MinIO-Delivery"
+	HeaderSignature = "X-MinIO-Signature"
+)
+
+const (
+	DefaultWebhookQueueSize   = 10000
+	DefaultWebhookMaxAttempts = 8
+	DefaultWebhookTimeout     = 10 * time.Second
+
+	webhookInitialBackoff = time.Second
+	webhookMaxBackoff     = 5 * time.Minute
+)
+
+// Event is one webhook payload
+type Event struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	TenantID string            `json:"tenant_id"`
+	Time     time.Time         `json:"time"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// WebhookConfig lists the endpoints every event is delivered to
+type WebhookConfig struct {
+	URLs []string
+	// Secret signs payloads; deliveries are unsigned when empty
+	Secret      string
+	QueueSize   int
+	MaxAttempts int
+	Timeout     time.Duration
+}
+
+// WebhookStats counts deliveries across all endpoints
+type WebhookStats struct {
+	Delivered atomic.Uint64
+	Retries   atomic.Uint64
+	Failed    atomic.Uint64 // Gave up after MaxAttempts or a permanent error
+	Dropped   atomic.Uint64 // Queue full
+}
+
+// Dispatcher fans events out to the configured endpoints
+type Dispatcher struct {
+	config    WebhookConfig
+	client    *http.Client
+	endpoints []*endpoint
+	stats     *WebhookStats
+}
+
+type endpoint struct {
+	url   string
+	queue chan []byte
+}
+
+// NewDispatcher creates a dispatcher; call Start to begin delivering
+func NewDispatcher(config WebhookConfig) *Dispatcher {
+	if config.QueueSize == 0 {
+		config.QueueSize = DefaultWebhookQueueSize
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+
+	d := &Dispatcher{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		stats:  &WebhookStats{},
+	}
+	for _, url := range config.URLs {
+		d.endpoints = append(d.endpoints, &endpoint{url: url, queue: make(chan []byte, config.QueueSize)})
+	}
+	return d
+}
+
+// Enabled reports whether any endpoint is configured
+func (d *Dispatcher) Enabled() bool {
+	return len(d.endpoints) > 0
+}
+
+// Start runs one delivery worker per endpoint until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, ep := range d.endpoints {
+		go d.run(ctx, ep)
+	}
+}
+
+// Emit queues an event for every endpoint without blocking, stamping its ID
+// and time if unset. Events are dropped (and counted) when a queue is full.
+func (d *Dispatcher) Emit(ev Event) {
+	if !d.Enabled() {
+		return
+	}
+	if ev.ID == "" {
+		idBytes := make([]byte, 12)
+		rand.Read(idBytes)
+		ev.ID = "evt-" + hex.EncodeToString(idBytes)
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	for _, ep := range d.endpoints {
+		select {
+		case ep.queue <- body:
+		default:
+			d.stats.Dropped.Add(1)
+		}
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context, ep *endpoint) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case body := <-ep.queue:
+			d.deliver(ctx, ep.url, body)
+		}
+	}
+}
+
+// deliver posts body until it is accepted, retrying with exponential
+// backoff on network errors, 429 and 5xx
+func (d *Dispatcher) deliver(ctx context.Context, url string, body []byte) {
+	var ev Event
+	json.Unmarshal(body, &ev)
+
+	backoff := webhookInitialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, url, ev, body)
+		if err == nil {
+			d.stats.Delivered.Add(1)
+			return
+		}
+		if !retry || attempt >= d.config.MaxAttempts {
+			d.stats.Failed.Add(1)
+			log.Printf("Webhook %s delivery of %s to %s failed after %d attempts: %v", ev.Type, ev.ID, url, attempt, err)
+			return
+		}
+
+		d.stats.Retries.Add(1)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, ev Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, ev.Type)
+	req.Header.Set(HeaderDelivery, ev.ID)
+	if d.config.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.config.Secret, time.Now(), body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the signature header value "t=<unix>,v1=<hex>", where v1 is
+// HMAC-SHA256 over "<unix>.<body>". Receivers should recompute it and
+// reject stale timestamps to prevent replay.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetStats returns delivery statistics
+func (d *Dispatcher) GetStats() *WebhookStats {
+	return d.stats
+}
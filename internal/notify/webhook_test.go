@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		sig := r.Header.Get(HeaderSignature)
+		ts, _ := strconv.ParseInt(strings.TrimPrefix(strings.Split(sig, ",")[0], "t="), 10, 64)
+		if want := Sign("s3cret", time.Unix(ts, 0), body); sig != want {
+			t.Errorf("signature = %q, want %q", sig, want)
+		}
+		if r.Header.Get(HeaderEvent) != EventTenantCreated {
+			t.Errorf("%s = %q", HeaderEvent, r.Header.Get(HeaderEvent))
+		}
+
+		var ev Event
+		json.Unmarshal(body, &ev)
+		received <- ev
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := NewDispatcher(WebhookConfig{URLs: []string{server.URL}, Secret: "s3cret"})
+	d.Start(ctx)
+	d.Emit(Event{Type: EventTenantCreated, TenantID: "t1"})
+
+	select {
+	case ev := <-received:
+		if ev.TenantID != "t1" || ev.ID == "" {
+			t.Errorf("delivered event = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+	if d.GetStats().Retries.Load() != 1 {
+		t.Errorf("Retries = %d, want 1", d.GetStats().Retries.Load())
+	}
+}
+
+func TestDispatcherGivesUpOnClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := NewDispatcher(WebhookConfig{URLs: []string{server.URL}})
+	d.Start(ctx)
+	d.Emit(Event{Type: EventTenantDeleted, TenantID: "t1"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.GetStats().Failed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if d.GetStats().Failed.Load() != 1 || d.GetStats().Retries.Load() != 0 {
+		t.Errorf("Failed = %d, Retries = %d; want 1, 0", d.GetStats().Failed.Load(), d.GetStats().Retries.Load())
+	}
+}
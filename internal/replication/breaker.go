@@ -0,0 +1,54 @@
+// internal/replication/breaker.go
+// Circuit breaker transitions. Each destination region's breaker reports
+// when it opens, probes half-open and closes again, so the moments a
+// region was cut off can be lined up with what clients saw.
+package replication
+
+import "time"
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerHook is called when a region's breaker changes state
+type BreakerHook func(region, from, to string)
+
+func breakerStateName(state int32) string {
+	switch state {
+	case 1:
+		return BreakerOpen
+	case 2:
+		return BreakerHalfOpen
+	default:
+		return BreakerClosed
+	}
+}
+
+// OnBreakerChange registers fn to be called, on the replicating worker,
+// whenever a region's circuit breaker changes state. It must not block.
+func (e *V3ReplicationEngine) OnBreakerChange(fn BreakerHook) {
+	e.breakerHook.Store(&fn)
+}
+
+// BreakerStates returns the state of each destination region's breaker
+func (e *V3ReplicationEngine) BreakerStates() map[string]string {
+	out := make(map[string]string, len(e.circuitBreakers))
+	for region, cb := range e.circuitBreakers {
+		out[region] = breakerStateName(cb.state.Load())
+	}
+	return out
+}
+
+// transition records a state change the caller made, and reports it
+func (cb *V3CircuitBreaker) transition(from, to int32) {
+	cb.lastTransition.Store(time.Now().UnixNano())
+	if cb.onChange == nil {
+		return
+	}
+	if fn := cb.onChange.Load(); fn != nil {
+		(*fn)(cb.region, breakerStateName(from), breakerStateName(to))
+	}
+}
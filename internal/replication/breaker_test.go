@@ -0,0 +1,48 @@
+package replication
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Breakers report each state change once, with its region
+func TestBreakerTransitions(t *testing.T) {
+	e, err := NewV3ReplicationEngine(&V3ReplicationConfig{
+		SourceRegion:       "r0",
+		DestinationRegions: []string{"r1", "r2"},
+		WorkerPoolSize:     1,
+	})
+	if err != nil {
+		t.Fatalf("NewV3ReplicationEngine: %v", err)
+	}
+	var got []string
+	e.OnBreakerChange(func(region, from, to string) {
+		got = append(got, region+":"+from+">"+to)
+	})
+
+	cb := e.circuitBreakers["r1"]
+	for i := 0; i < V3FailureThreshold+5; i++ {
+		cb.RecordFailure()
+	}
+	if cb.AllowRequest() {
+		t.Error("Open breaker allowed a request")
+	}
+	cb.lastFailure.Store(0) // Past the timeout
+	if !cb.AllowRequest() || !cb.AllowRequest() {
+		t.Error("Breaker past its timeout did not probe")
+	}
+	for i := 0; i < V3SuccessThreshold; i++ {
+		cb.RecordSuccess()
+	}
+
+	want := []string{"r1:closed>open", "r1:open>half_open", "r1:half_open>closed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transitions = %v, want %v", got, want)
+	}
+	if states := e.BreakerStates(); states["r1"] != BreakerClosed || states["r2"] != BreakerClosed {
+		t.Errorf("BreakerStates() = %v", states)
+	}
+	if cb.lastTransition.Load() == 0 {
+		t.Error("Transition time not recorded")
+	}
+}
@@ -0,0 +1,100 @@
+package replication
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A task replicates to its own regions only, at most MaxRegionFanout at once
+func TestProcessTaskFanout(t *testing.T) {
+	regions := []string{"r1", "r2", "r3", "r4", "r5", "r6", "r7", "r8"}
+	e, err := NewV3ReplicationEngine(&V3ReplicationConfig{
+		SourceRegion:       "r0",
+		DestinationRegions: regions,
+		WorkerPoolSize:     1,
+		MaxRegionFanout:    2,
+	})
+	if err != nil {
+		t.Fatalf("NewV3ReplicationEngine: %v", err)
+	}
+
+	var active, peak atomic.Int32
+	var mu sync.Mutex
+	var reached []string
+	replicated := -1
+	task := e.acquireTask()
+	task.OnRegion = func(region string, ok bool) {
+		n := active.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		active.Add(-1)
+		mu.Lock()
+		reached = append(reached, region)
+		mu.Unlock()
+	}
+	task.OnComplete = func(n int) { replicated = n }
+	e.processTask(task)
+
+	if p := peak.Load(); p > 2 || p < 1 {
+		t.Errorf("Peak fan-out %d, want at most 2", p)
+	}
+	if replicated != len(regions) || len(reached) != len(regions) {
+		t.Errorf("Replicated to %d regions (%v), want %d", replicated, reached, len(regions))
+	}
+
+	reached, replicated = nil, -1
+	task = e.acquireTask()
+	task.Regions = []string{"r3", "r7"}
+	task.OnRegion = func(region string, ok bool) {
+		mu.Lock()
+		reached = append(reached, region)
+		mu.Unlock()
+	}
+	task.OnComplete = func(n int) { replicated = n }
+	e.processTask(task)
+
+	sort.Strings(reached)
+	if replicated != 2 || len(reached) != 2 || reached[0] != "r3" || reached[1] != "r7" {
+		t.Errorf("Task limited to r3,r7 reached %v (%d replicated)", reached, replicated)
+	}
+}
+
+// A failing region is retried within its budget, then fails fast
+func TestRegionRetryBudget(t *testing.T) {
+	e, err := NewV3ReplicationEngine(&V3ReplicationConfig{
+		SourceRegion:       "r0",
+		DestinationRegions: []string{"up", "down"},
+		WorkerPoolSize:     1,
+		MaxRegionRetries:   3,
+		RetryBackoff:       time.Microsecond,
+		RetryBudget:        0.1,
+	})
+	if err != nil {
+		t.Fatalf("NewV3ReplicationEngine: %v", err)
+	}
+	delete(e.connectionPools, "down")             // Every attempt fails
+	e.circuitBreakers["down"].threshold = 1 << 30 // Leave it to the budget
+
+	for i := 0; i < 20; i++ {
+		replicated := -1
+		task := e.acquireTask()
+		task.OnComplete = func(n int) { replicated = n }
+		e.processTask(task)
+		if replicated != 1 {
+			t.Fatalf("Task %d replicated to %d regions, want 1", i, replicated)
+		}
+	}
+
+	st := e.RetryStats()
+	// A burst of 10, plus a tenth of a retry per task
+	if down := st["down"]; down.Retries < retryBurst || down.Retries > retryBurst+2 || down.Exhausted == 0 {
+		t.Errorf("Retries to the failing region: %+v", down)
+	}
+	if up := st["up"]; up.Retries != 0 || up.Exhausted != 0 {
+		t.Errorf("Retries to the healthy region: %+v", up)
+	}
+}
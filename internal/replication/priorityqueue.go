@@ -0,0 +1,109 @@
+// internal/replication/priorityqueue.go
+// Multi-level task queue. Each priority has its own lock-free FIFO and
+// workers drain the most urgent level first, so interactive replication
+// is not stuck behind a backfill. A lower level that has gone unserved
+// for longer than the aging interval while holding tasks is served ahead
+// of the others, which bounds how long bulk work can starve.
+package replication
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// Task priorities, most urgent first
+const (
+	PriorityInteractive = iota // Small hot objects and configuration
+	PriorityNormal             // Ordinary writes
+	PriorityBulk               // Backfill: drains and anti-entropy
+	NumPriorities
+)
+
+// DefaultQueueAging is how long a waiting level may go unserved
+const DefaultQueueAging = time.Second
+
+// PriorityName names a priority for metrics and logs
+func PriorityName(p int) string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityNormal:
+		return "normal"
+	case PriorityBulk:
+		return "bulk"
+	}
+	return "unknown"
+}
+
+// V3PriorityQueue is a V3TaskQueue per priority
+type V3PriorityQueue struct {
+	levels     [NumPriorities]*V3TaskQueue
+	lastServed [NumPriorities]atomic.Int64 // Unix nanoseconds
+	aging      int64
+	aged       atomic.Uint64 // Pops that served a starved level out of turn
+}
+
+func newV3PriorityQueue(size int, aging time.Duration) *V3PriorityQueue {
+	if aging <= 0 {
+		aging = DefaultQueueAging
+	}
+	q := &V3PriorityQueue{aging: aging.Nanoseconds()}
+	for i := range q.levels {
+		q.levels[i] = newV3TaskQueue(size)
+	}
+	return q
+}
+
+// Push queues item at priority, clamped to the known levels
+func (q *V3PriorityQueue) Push(item unsafe.Pointer, priority int) bool {
+	priority = min(max(priority, 0), NumPriorities-1)
+	level := q.levels[priority]
+	if level.count.Load() == 0 {
+		// An idle level starts aging from its first task, not its last pop
+		q.lastServed[priority].Store(time.Now().UnixNano())
+	}
+	return level.Push(item)
+}
+
+// Pop takes the oldest task of the most urgent level, unless a lower
+// level has waited past the aging interval
+func (q *V3PriorityQueue) Pop() unsafe.Pointer {
+	now := time.Now().UnixNano()
+	for p := NumPriorities - 1; p > 0; p-- {
+		if q.levels[p].count.Load() > 0 && now-q.lastServed[p].Load() > q.aging {
+			if item := q.pop(p, now); item != nil {
+				q.aged.Add(1)
+				return item
+			}
+		}
+	}
+	for p := 0; p < NumPriorities; p++ {
+		if item := q.pop(p, now); item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+func (q *V3PriorityQueue) pop(p int, now int64) unsafe.Pointer {
+	item := q.levels[p].Pop()
+	if item != nil {
+		q.lastServed[p].Store(now)
+	}
+	return item
+}
+
+// Depths returns the tasks waiting at each priority
+func (q *V3PriorityQueue) Depths() [NumPriorities]int64 {
+	var out [NumPriorities]int64
+	for i, level := range q.levels {
+		out[i] = level.count.Load()
+	}
+	return out
+}
+
+// Aged returns how many pops served a starved level out of turn
+func (q *V3PriorityQueue) Aged() uint64 {
+	return q.aged.Load()
+}
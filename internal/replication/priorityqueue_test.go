@@ -0,0 +1,90 @@
+package replication
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/minio/enterprise/internal/lincheck"
+)
+
+// levelQueue pushes everything at one priority
+type levelQueue struct {
+	*V3PriorityQueue
+	priority int
+}
+
+func (q levelQueue) Push(item unsafe.Pointer) bool {
+	return q.V3PriorityQueue.Push(item, q.priority)
+}
+
+// At a single priority the queue is the lock-free FIFO it is built on
+func TestV3PriorityQueueLinearizable(t *testing.T) {
+	lincheck.TestQueue(t, func(capacity int) lincheck.Queue {
+		return levelQueue{newV3PriorityQueue(capacity, time.Hour), PriorityBulk}
+	})
+}
+
+func taskAt(priority int, id int) unsafe.Pointer {
+	task := &V3ReplicationTask{Timestamp: int64(id)}
+	task.Priority.Store(int32(priority))
+	return unsafe.Pointer(task)
+}
+
+func popTask(q *V3PriorityQueue) (priority int, id int) {
+	ptr := q.Pop()
+	if ptr == nil {
+		return -1, -1
+	}
+	task := (*V3ReplicationTask)(ptr)
+	return int(task.Priority.Load()), int(task.Timestamp)
+}
+
+// More urgent levels drain first, each in FIFO order
+func TestV3PriorityQueueOrder(t *testing.T) {
+	q := newV3PriorityQueue(16, time.Hour)
+	q.Push(taskAt(PriorityBulk, 1), PriorityBulk)
+	q.Push(taskAt(PriorityNormal, 2), PriorityNormal)
+	q.Push(taskAt(PriorityBulk, 3), PriorityBulk)
+	q.Push(taskAt(PriorityInteractive, 4), PriorityInteractive)
+	q.Push(taskAt(PriorityInteractive, 5), PriorityInteractive)
+
+	if d := q.Depths(); d != [NumPriorities]int64{2, 1, 2} {
+		t.Errorf("Depths = %v, want [2 1 2]", d)
+	}
+	for _, want := range []int{4, 5, 2, 1, 3} {
+		if _, id := popTask(q); id != want {
+			t.Fatalf("Popped task %d, want %d", id, want)
+		}
+	}
+	if ptr := q.Pop(); ptr != nil {
+		t.Errorf("Pop on an empty queue returned a task")
+	}
+}
+
+// A bulk task waiting past the aging interval is served while interactive
+// tasks keep arriving
+func TestV3PriorityQueueAging(t *testing.T) {
+	const aging = 20 * time.Millisecond
+	q := newV3PriorityQueue(64, aging)
+	q.Push(taskAt(PriorityBulk, 0), PriorityBulk)
+
+	start := time.Now()
+	for i := 1; ; i++ {
+		q.Push(taskAt(PriorityInteractive, i), PriorityInteractive)
+		p, _ := popTask(q)
+		if p == PriorityBulk {
+			if waited := time.Since(start); waited < aging {
+				t.Errorf("Bulk task served after %v, before the aging interval", waited)
+			}
+			break
+		}
+		if time.Since(start) > 50*aging {
+			t.Fatal("Bulk task starved")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if q.Aged() != 1 {
+		t.Errorf("Aged = %d, want 1", q.Aged())
+	}
+}
@@ -4,8 +4,11 @@
 package replication
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"runtime"
 	"sync"
@@ -33,7 +36,7 @@ const (
 
 	// Pipelining
 	V3PipelineDepth       = 100  // Deep pipelining
-	V3MaxInflight         = 10000
+	V3MaxInflight         = 1 << 14 // Power of two: task queue slots are indexed by mask
 
 	// Circuit breaker (faster)
 	V3FailureThreshold    = 10
@@ -56,6 +59,25 @@ type V3ReplicationConfig struct {
 	EnableAdaptiveBatching bool
 	EnablePipelining       bool
 	CompressionThreshold   int64
+	MaxRegionFanout        int // Regions one task replicates to at once; 0 means all
+
+	// A failed region is retried up to MaxRegionRetries times per task,
+	// after RetryBackoff (0: DefaultRetryBackoff) doubling, while it has
+	// retry budget: each first attempt to a region earns RetryBudget
+	// retries, saving up at most 10
+	MaxRegionRetries       int
+	RetryBackoff           time.Duration
+	RetryBudget            float64
+
+	// QueueAging is how long a waiting priority level may go unserved
+	// before it is served ahead of more urgent ones (0: DefaultQueueAging)
+	QueueAging             time.Duration
+
+	// Objects over StreamThreshold bytes replicate in StreamChunkSize
+	// chunks, resuming from the last chunk a region acknowledged (0:
+	// DefaultStreamThreshold, DefaultStreamChunkSize)
+	StreamThreshold        int64
+	StreamChunkSize        int64
 	_padding               [CacheLineSize - 8]byte
 }
 
@@ -67,12 +89,19 @@ type V3ReplicationTask struct {
 	KeyLen        uint16
 	VersionID     [64]byte
 	VersionIDLen  uint16
+	RequestID     [64]byte // API request that produced the write, for correlation
+	RequestIDLen  uint16
 	Data          unsafe.Pointer // Direct pointer
 	DataSize      atomic.Uint64
 	Timestamp     int64
-	Priority      atomic.Int32
+	Priority      atomic.Int32 // PriorityInteractive, PriorityNormal or PriorityBulk
 	RetryCount    atomic.Int32
 	Flags         uint32
+	OnComplete    func(replicatedRegions int) // Optional, called once after fan-out
+	OnRegion      func(region string, ok bool) // Optional, called per region attempted
+	Regions       []string // Resolved destinations; all configured regions if empty
+	Source        io.ReaderAt // Streamed in chunks in place of Data when set
+	progress      *streamProgress
 	_padding      [CacheLineSize - 16]byte
 }
 
@@ -90,8 +119,8 @@ type V3TaskQueue struct {
 type V3ReplicationEngine struct {
 	config                 *V3ReplicationConfig
 
-	// Lock-free task queue
-	taskQueue              *V3TaskQueue
+	// Lock-free task queues, one per priority
+	taskQueue              *V3PriorityQueue
 
 	// Massive worker pool with dynamic scaling
 	workerPool             *V3WorkerPool
@@ -104,6 +133,10 @@ type V3ReplicationEngine struct {
 
 	// Circuit breakers (lock-free)
 	circuitBreakers        map[string]*V3CircuitBreaker
+	retryBudgets           map[string]*retryBudget
+	breakerHook            atomic.Pointer[BreakerHook]
+	streams                streamCounters
+	sendChunk              func(pool *V3ConnectionPool, put *ChunkPut) error
 
 	// Statistics (cache-aligned, lock-free)
 	stats                  *V3ReplicationStats
@@ -123,7 +156,7 @@ type V3WorkerPool struct {
 	active        atomic.Int32
 	idle          atomic.Int32
 	processed     atomic.Uint64
-	taskQueue     *V3TaskQueue
+	taskQueue     *V3PriorityQueue
 	scaleTicker   *time.Ticker
 	_padding      [CacheLineSize - 16]byte
 }
@@ -175,6 +208,8 @@ type V3CircuitBreaker struct {
 	lastTransition atomic.Int64
 	threshold     int64
 	timeout       int64 // Nanoseconds
+	region        string
+	onChange      *atomic.Pointer[BreakerHook]
 	_padding      [CacheLineSize - 8]byte
 }
 
@@ -205,7 +240,7 @@ func NewV3ReplicationEngine(config *V3ReplicationConfig) (*V3ReplicationEngine,
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create massive task queue
-	taskQueue := newV3TaskQueue(V3MaxInflight)
+	taskQueue := newV3PriorityQueue(V3MaxInflight, config.QueueAging)
 
 	// Create connection pools with HTTP/2
 	connectionPools := make(map[string]*V3ConnectionPool)
@@ -271,9 +306,15 @@ func NewV3ReplicationEngine(config *V3ReplicationConfig) (*V3ReplicationEngine,
 		circuitBreakers[region] = &V3CircuitBreaker{
 			threshold: V3FailureThreshold,
 			timeout:   V3CircuitTimeout.Nanoseconds(),
+			region:    region,
 		}
 	}
 
+	retryBudgets := make(map[string]*retryBudget)
+	for _, region := range config.DestinationRegions {
+		retryBudgets[region] = newRetryBudget(config.RetryBudget)
+	}
+
 	engine := &V3ReplicationEngine{
 		config:          config,
 		taskQueue:       taskQueue,
@@ -281,10 +322,15 @@ func NewV3ReplicationEngine(config *V3ReplicationConfig) (*V3ReplicationEngine,
 		connectionPools: connectionPools,
 		batchEngine:     batchEngine,
 		circuitBreakers: circuitBreakers,
+		retryBudgets:    retryBudgets,
 		stats:           &V3ReplicationStats{},
 		ctx:             ctx,
 		cancel:          cancel,
 	}
+	engine.sendChunk = engine.putChunk
+	for _, cb := range circuitBreakers {
+		cb.onChange = &engine.breakerHook
+	}
 
 	return engine, nil
 }
@@ -321,7 +367,39 @@ func (e *V3ReplicationEngine) Start(ctx context.Context) error {
 
 // Enqueue with zero-copy
 func (e *V3ReplicationEngine) Enqueue(bucket, key, versionID string, data []byte) error {
+	return e.EnqueueWithCallback(bucket, key, versionID, "", data, nil)
+}
+
+// EnqueueWithCallback enqueues a task and invokes onComplete with the number
+// of regions that accepted it once replication has been attempted. requestID
+// correlates the task with the API request that produced it.
+func (e *V3ReplicationEngine) EnqueueWithCallback(bucket, key, versionID, requestID string, data []byte, onComplete func(replicatedRegions int)) error {
+	return e.EnqueueToRegions(bucket, key, versionID, requestID, data, nil, nil, onComplete)
+}
+
+// EnqueueToRegions is EnqueueWithCallback limited to regions (all if
+// empty), additionally invoking onRegion with each region's outcome before
+// onComplete
+func (e *V3ReplicationEngine) EnqueueToRegions(bucket, key, versionID, requestID string, data []byte, regions []string,
+	onRegion func(region string, ok bool), onComplete func(replicatedRegions int)) error {
+	return e.EnqueueWithPriority(PriorityNormal, bucket, key, versionID, requestID, data, regions, onRegion, onComplete)
+}
+
+// EnqueueWithPriority is EnqueueToRegions at priority
+func (e *V3ReplicationEngine) EnqueueWithPriority(priority int, bucket, key, versionID, requestID string, data []byte, regions []string,
+	onRegion func(region string, ok bool), onComplete func(replicatedRegions int)) error {
+	if int64(len(data)) > e.streamThreshold() {
+		return e.enqueue(priority, bucket, key, versionID, requestID, nil, bytes.NewReader(data), int64(len(data)), regions, onRegion, onComplete)
+	}
+	return e.enqueue(priority, bucket, key, versionID, requestID, data, nil, 0, regions, onRegion, onComplete)
+}
+
+func (e *V3ReplicationEngine) enqueue(priority int, bucket, key, versionID, requestID string, data []byte, src io.ReaderAt, size int64, regions []string,
+	onRegion func(region string, ok bool), onComplete func(replicatedRegions int)) error {
 	task := e.acquireTask()
+	task.OnComplete = onComplete
+	task.OnRegion = onRegion
+	task.Regions = regions
 
 	// Copy to fixed arrays (avoid heap)
 	copy(task.Bucket[:], bucket)
@@ -330,18 +408,24 @@ func (e *V3ReplicationEngine) Enqueue(bucket, key, versionID string, data []byte
 	task.KeyLen = uint16(len(key))
 	copy(task.VersionID[:], versionID)
 	task.VersionIDLen = uint16(len(versionID))
+	task.RequestIDLen = uint16(copy(task.RequestID[:], requestID))
 
 	// Zero-copy data reference
 	if len(data) > 0 {
 		task.Data = unsafe.Pointer(&data[0])
 		task.DataSize.Store(uint64(len(data)))
 	}
+	if src != nil {
+		task.Source = src
+		task.DataSize.Store(uint64(size))
+		task.progress = &streamProgress{acked: make(map[string]int64)}
+	}
 
 	task.Timestamp = time.Now().UnixNano()
-	task.Priority.Store(100)
+	task.Priority.Store(int32(priority))
 
 	// Push to lock-free queue
-	if !e.taskQueue.Push(unsafe.Pointer(task)) {
+	if !e.taskQueue.Push(unsafe.Pointer(task), priority) {
 		return fmt.Errorf("queue full")
 	}
 
@@ -416,29 +500,50 @@ func (e *V3ReplicationEngine) processTask(task *V3ReplicationTask) {
 	key := string(task.Key[:task.KeyLen])
 	dataSize := task.DataSize.Load()
 
-	// Replicate to all regions in parallel
+	// Replicate to the task's regions in parallel, at most
+	// MaxRegionFanout at a time
 	var wg sync.WaitGroup
 	successCount := atomic.Int32{}
 
-	for _, region := range e.config.DestinationRegions {
+	regions := task.Regions
+	if len(regions) == 0 {
+		regions = e.config.DestinationRegions
+	}
+	fanout := len(regions)
+	if n := e.config.MaxRegionFanout; n > 0 && n < fanout {
+		fanout = n
+	}
+	sem := make(chan struct{}, fanout)
+	for _, region := range regions {
 		// Check circuit breaker
 		breaker := e.circuitBreakers[region]
-		if !breaker.AllowRequest() {
+		if breaker == nil || !breaker.AllowRequest() {
 			e.stats.FailedReplications.Add(1)
+			if task.OnRegion != nil {
+				task.OnRegion(region, false)
+			}
 			continue
 		}
 
+		sem <- struct{}{}
 		wg.Add(1)
 		go func(reg string) {
-			defer wg.Done()
-
-			if err := e.replicateToRegion(reg, bucket, key, task); err != nil {
-				breaker.RecordFailure()
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := e.replicateWithRetry(reg, bucket, key, task, breaker)
+			if err != nil {
+				log.Printf("Replication of %s/%s to %s failed (request %s): %v",
+					bucket, key, reg, task.RequestID[:task.RequestIDLen], err)
 				e.stats.FailedReplications.Add(1)
 			} else {
-				breaker.RecordSuccess()
 				successCount.Add(1)
 			}
+			if task.OnRegion != nil {
+				task.OnRegion(reg, err == nil)
+			}
 		}(region)
 	}
 
@@ -453,12 +558,20 @@ func (e *V3ReplicationEngine) processTask(task *V3ReplicationTask) {
 	latency := time.Since(start).Nanoseconds()
 	e.stats.AvgLatencyNs.Store(latency)
 
+	if task.OnComplete != nil {
+		task.OnComplete(int(successCount.Load()))
+	}
+
 	// Release task
 	e.releaseTask(task)
 }
 
 // Replicate to specific region with connection pooling
 func (e *V3ReplicationEngine) replicateToRegion(region, bucket, key string, task *V3ReplicationTask) error {
+	if task.Source != nil {
+		return e.streamToRegion(region, bucket, key, task)
+	}
+
 	pool := e.connectionPools[region]
 	if pool == nil {
 		return fmt.Errorf("no pool for region: %s", region)
@@ -471,7 +584,8 @@ func (e *V3ReplicationEngine) replicateToRegion(region, bucket, key string, task
 	client := pool.clients[clientIdx]
 
 	// Simulate HTTP/2 PUT request
-	// In production, this would be actual HTTP/2 request with zero-copy
+	// In production, this would be actual HTTP/2 request with zero-copy,
+	// carrying task.RequestID as X-Request-ID
 	_ = client
 	time.Sleep(1 * time.Millisecond) // Simulate network
 
@@ -603,11 +717,32 @@ func (e *V3ReplicationEngine) statsCollector() {
 	}
 }
 
+// Regions returns the configured destination regions
+func (e *V3ReplicationEngine) Regions() []string {
+	return append([]string(nil), e.config.DestinationRegions...)
+}
+
+// SourceRegion returns the region this engine replicates from
+func (e *V3ReplicationEngine) SourceRegion() string {
+	return e.config.SourceRegion
+}
+
 // GetStats returns performance metrics
 func (e *V3ReplicationEngine) GetStats() *V3ReplicationStats {
 	return e.stats
 }
 
+// QueueDepths returns the tasks waiting at each priority
+func (e *V3ReplicationEngine) QueueDepths() [NumPriorities]int64 {
+	return e.taskQueue.Depths()
+}
+
+// QueueAged returns how many tasks were taken ahead of more urgent ones
+// because their priority had waited past QueueAging
+func (e *V3ReplicationEngine) QueueAged() uint64 {
+	return e.taskQueue.Aged()
+}
+
 // Shutdown gracefully
 func (e *V3ReplicationEngine) Shutdown(ctx context.Context) error {
 	e.cancel()
@@ -641,6 +776,8 @@ func newV3TaskQueue(size int) *V3TaskQueue {
 	}
 }
 
+// Push claims a slot by advancing head, then publishes item into it once
+// the consumer of the slot's previous lap has emptied it
 func (q *V3TaskQueue) Push(item unsafe.Pointer) bool {
 	for {
 		head := q.head.Load()
@@ -651,13 +788,18 @@ func (q *V3TaskQueue) Push(item unsafe.Pointer) bool {
 		}
 
 		if q.head.CompareAndSwap(head, head+1) {
-			q.tasks[head&q.mask] = item
+			slot := &q.tasks[head&q.mask]
+			for !atomic.CompareAndSwapPointer(slot, nil, item) {
+				runtime.Gosched()
+			}
 			q.count.Add(1)
 			return true
 		}
 	}
 }
 
+// Pop claims a slot by advancing tail, then waits for its producer to
+// publish into it
 func (q *V3TaskQueue) Pop() unsafe.Pointer {
 	for {
 		tail := q.tail.Load()
@@ -668,9 +810,14 @@ func (q *V3TaskQueue) Pop() unsafe.Pointer {
 		}
 
 		if q.tail.CompareAndSwap(tail, tail+1) {
-			item := q.tasks[tail&q.mask]
-			q.count.Add(-1)
-			return item
+			slot := &q.tasks[tail&q.mask]
+			for {
+				if item := atomic.SwapPointer(slot, nil); item != nil {
+					q.count.Add(-1)
+					return item
+				}
+				runtime.Gosched()
+			}
 		}
 	}
 }
@@ -686,7 +833,9 @@ func (cb *V3CircuitBreaker) AllowRequest() bool {
 	case 1: // Open
 		lastFail := cb.lastFailure.Load()
 		if time.Now().UnixNano()-lastFail > cb.timeout {
-			cb.state.CompareAndSwap(1, 2) // Try half-open
+			if cb.state.CompareAndSwap(1, 2) { // Try half-open
+				cb.transition(1, 2)
+			}
 			return true
 		}
 		return false
@@ -702,7 +851,9 @@ func (cb *V3CircuitBreaker) RecordSuccess() {
 
 	if cb.state.Load() == 2 { // Half-open
 		if successes >= V3SuccessThreshold {
-			cb.state.Store(0) // Close
+			if cb.state.CompareAndSwap(2, 0) { // Close
+				cb.transition(2, 0)
+			}
 			cb.failures.Store(0)
 			cb.successes.Store(0)
 		}
@@ -714,7 +865,9 @@ func (cb *V3CircuitBreaker) RecordFailure() {
 	cb.lastFailure.Store(time.Now().UnixNano())
 
 	if failures >= cb.threshold {
-		cb.state.Store(1) // Open
+		if prev := cb.state.Swap(1); prev != 1 { // Open
+			cb.transition(prev, 1)
+		}
 		cb.successes.Store(0)
 	}
 }
@@ -729,4 +882,9 @@ func (e *V3ReplicationEngine) releaseTask(task *V3ReplicationTask) {
 	// Clear and return to pool
 	task.Data = nil
 	task.DataSize.Store(0)
+	task.OnComplete = nil
+	task.OnRegion = nil
+	task.Regions = nil
+	task.Source = nil
+	task.progress = nil
 }
@@ -0,0 +1,108 @@
+// internal/replication/retrybudget.go
+// Per-region retry budgets. A failed replication to a region is retried
+// only while that region's budget holds: every first attempt earns a
+// fraction of a retry, up to a burst, and every retry spends one, so a
+// region that is down sees a bounded share of extra traffic rather than
+// every task retrying into it
+package replication
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRetryBackoff is the wait before a region's first retry,
+	// doubled for each one after
+	DefaultRetryBackoff = 100 * time.Millisecond
+
+	// retryBurst is the most retries a region's budget saves up
+	retryBurst = 10
+)
+
+// RegionRetryStats counts retries to one region
+type RegionRetryStats struct {
+	Retries   uint64 `json:"retries"`
+	Exhausted uint64 `json:"budget_exhausted"` // Retries the budget refused
+}
+
+// retryBudget meters retries to one region. It starts with a full burst.
+type retryBudget struct {
+	ratio float64
+
+	mu     sync.Mutex
+	tokens float64
+
+	retries   atomic.Uint64
+	exhausted atomic.Uint64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{ratio: ratio, tokens: retryBurst}
+}
+
+func (b *retryBudget) earn() {
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, retryBurst)
+	b.mu.Unlock()
+}
+
+func (b *retryBudget) spend() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		b.exhausted.Add(1)
+		return false
+	}
+	b.tokens--
+	b.retries.Add(1)
+	return true
+}
+
+// replicateWithRetry replicates task to region, retrying failures up to
+// MaxRegionRetries times while the region's budget and breaker allow.
+// Every attempt is recorded with breaker.
+func (e *V3ReplicationEngine) replicateWithRetry(region, bucket, key string, task *V3ReplicationTask, breaker *V3CircuitBreaker) error {
+	budget := e.retryBudgets[region]
+	budget.earn()
+
+	backoff := e.config.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	err := e.attempt(region, bucket, key, task, breaker)
+	for retry := 0; err != nil && retry < e.config.MaxRegionRetries; retry++ {
+		if !breaker.AllowRequest() || !budget.spend() {
+			break
+		}
+		task.RetryCount.Add(1)
+		select {
+		case <-e.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = e.attempt(region, bucket, key, task, breaker)
+	}
+	return err
+}
+
+func (e *V3ReplicationEngine) attempt(region, bucket, key string, task *V3ReplicationTask, breaker *V3CircuitBreaker) error {
+	err := e.replicateToRegion(region, bucket, key, task)
+	if err != nil {
+		breaker.RecordFailure()
+	} else {
+		breaker.RecordSuccess()
+	}
+	return err
+}
+
+// RetryStats returns retry counts per destination region
+func (e *V3ReplicationEngine) RetryStats() map[string]RegionRetryStats {
+	out := make(map[string]RegionRetryStats, len(e.retryBudgets))
+	for region, b := range e.retryBudgets {
+		out[region] = RegionRetryStats{Retries: b.retries.Load(), Exhausted: b.exhausted.Load()}
+	}
+	return out
+}
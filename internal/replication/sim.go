@@ -0,0 +1,400 @@
+// internal/replication/sim.go
+// Deterministic in-process simulation of multi-region replication for
+// tests: every region is a node with its own metadata index and clock,
+// writes are shipped to the peers over a fake network and resolved last
+// write wins, and one seeded event queue on a virtual clock drives every
+// delivery, retry and timeout, so a scenario replays exactly from its seed
+package replication
+
+import (
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/minio/enterprise/internal/metadata"
+)
+
+// maxSimEvents bounds Settle, so a scenario that never quiesces fails
+// instead of hanging
+const maxSimEvents = 10_000_000
+
+// SimConfig describes a simulation
+type SimConfig struct {
+	Regions []string
+	Seed    int64
+
+	// Latency and Jitter are every link's initial one-way delay
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// A change not acknowledged within Timeout is sent again after
+	// RetryInterval, doubling per attempt, and dead-lettered after
+	// MaxAttempts
+	Timeout       time.Duration
+	RetryInterval time.Duration
+	MaxAttempts   int
+}
+
+// DefaultSimConfig returns a configuration for regions with WAN-like
+// latency and the retry policy of the replication engine
+func DefaultSimConfig(regions ...string) SimConfig {
+	return SimConfig{
+		Regions:       regions,
+		Seed:          1,
+		Latency:       50 * time.Millisecond,
+		Jitter:        20 * time.Millisecond,
+		Timeout:       time.Second,
+		RetryInterval: 500 * time.Millisecond,
+		MaxAttempts:   5,
+	}
+}
+
+// SimStats counts what happened during a simulation
+type SimStats struct {
+	Writes       uint64 // Puts and deletes made on nodes
+	Messages     uint64 // Messages put on the network
+	Dropped      uint64 // Messages lost to partitions
+	Applied      uint64 // Replicated changes that replaced a node's version
+	Stale        uint64 // Replicated changes older than the node's version, lost to last write wins
+	Retries      uint64
+	DeadLettered uint64
+	Redriven     uint64
+}
+
+// SimChange is a version of an object, or its deletion, as replicated
+type SimChange struct {
+	Tenant    string
+	Key       string
+	VersionID string
+	ModTime   int64 // Unix nano on the writing node's clock
+	Deleted   bool
+	Origin    string
+}
+
+// newer reports whether c wins over other under last write wins: the later
+// ModTime, ties broken by VersionID
+func (c SimChange) newer(other SimChange) bool {
+	if c.ModTime != other.ModTime {
+		return c.ModTime > other.ModTime
+	}
+	return c.VersionID > other.VersionID
+}
+
+// SimDeadLetter is a change a node gave up replicating to Peer
+type SimDeadLetter struct {
+	Change   SimChange
+	Peer     string
+	Attempts int
+}
+
+// Sim is a simulation of regions replicating to each other
+type Sim struct {
+	config  SimConfig
+	rng     *rand.Rand
+	now     time.Time
+	queue   simQueue
+	seq     uint64
+	regions []string
+	nodes   map[string]*SimNode
+	net     *SimNetwork
+	stats   SimStats
+}
+
+// NewSim creates a simulation of config.Regions, fully connected
+func NewSim(config SimConfig) *Sim {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	s := &Sim{
+		config:  config,
+		rng:     rand.New(rand.NewSource(config.Seed)),
+		now:     time.Unix(1_700_000_000, 0).UTC(),
+		regions: append([]string(nil), config.Regions...),
+		nodes:   make(map[string]*SimNode),
+	}
+	sort.Strings(s.regions)
+	s.net = newSimNetwork(s)
+	for _, region := range s.regions {
+		s.nodes[region] = &SimNode{
+			Region:     region,
+			Index:      metadata.NewIndex(),
+			sim:        s,
+			tombstones: make(map[string]SimChange),
+		}
+	}
+	return s
+}
+
+// Node returns region's node, or nil
+func (s *Sim) Node(region string) *SimNode {
+	return s.nodes[region]
+}
+
+// Regions returns the simulated regions in order
+func (s *Sim) Regions() []string {
+	return s.regions
+}
+
+// Network returns the network connecting the regions
+func (s *Sim) Network() *SimNetwork {
+	return s.net
+}
+
+// Rand returns the simulation's random source, for scenarios to draw
+// workloads from the same seed
+func (s *Sim) Rand() *rand.Rand {
+	return s.rng
+}
+
+// Now returns the virtual time
+func (s *Sim) Now() time.Time {
+	return s.now
+}
+
+// Stats returns the counts so far
+func (s *Sim) Stats() SimStats {
+	return s.stats
+}
+
+// after schedules fn d from now. Events due at the same time run in the
+// order they were scheduled.
+func (s *Sim) after(d time.Duration, fn func()) {
+	s.seq++
+	heap.Push(&s.queue, &simEvent{at: s.now.Add(d), seq: s.seq, fn: fn})
+}
+
+// step runs the next event, reporting false if there is none
+func (s *Sim) step() bool {
+	if len(s.queue) == 0 {
+		return false
+	}
+	ev := heap.Pop(&s.queue).(*simEvent)
+	s.now = ev.at
+	ev.fn()
+	return true
+}
+
+// Run advances the virtual clock by d, running the events due
+func (s *Sim) Run(d time.Duration) {
+	until := s.now.Add(d)
+	for len(s.queue) > 0 && !s.queue[0].at.After(until) {
+		s.step()
+	}
+	s.now = until
+}
+
+// Settle runs events until none are left, reporting false if they never
+// run out
+func (s *Sim) Settle() bool {
+	for i := 0; i < maxSimEvents; i++ {
+		if !s.step() {
+			return true
+		}
+	}
+	return false
+}
+
+// Converged reports whether every node holds the same version of every
+// object, comparing the nodes' Merkle roots per tenant
+func (s *Sim) Converged() bool {
+	tenants := make(map[string]bool)
+	for _, n := range s.nodes {
+		for _, t := range n.Index.Tenants() {
+			tenants[t] = true
+		}
+	}
+	for t := range tenants {
+		root := ""
+		for i, region := range s.regions {
+			r := s.nodes[region].Index.Merkle(t, metadata.DefaultMerkleDepth).Root()
+			if i > 0 && r != root {
+				return false
+			}
+			root = r
+		}
+	}
+	return true
+}
+
+// Divergent returns the objects, as tenant/key, whose versions differ
+// between nodes
+func (s *Sim) Divergent() []string {
+	versions := make(map[string]map[string]string) // Object -> region -> version
+	for _, region := range s.regions {
+		for _, meta := range s.nodes[region].Index.Snapshot() {
+			id := meta.Tenant + "/" + meta.Key
+			if versions[id] == nil {
+				versions[id] = make(map[string]string)
+			}
+			versions[id][region] = meta.VersionID
+		}
+	}
+	var out []string
+	for id, byRegion := range versions {
+		if len(byRegion) != len(s.regions) {
+			out = append(out, id)
+			continue
+		}
+		for _, v := range byRegion {
+			if v != byRegion[s.regions[0]] {
+				out = append(out, id)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ship replicates c from one node to peer, retrying until acknowledged
+// and dead-lettering it after MaxAttempts
+func (s *Sim) ship(from *SimNode, c SimChange, peer string, attempt int) {
+	acked := false
+	s.net.send(from.Region, peer, func() {
+		s.nodes[peer].apply(c)
+		s.net.send(peer, from.Region, func() { acked = true })
+	})
+	s.after(s.config.Timeout, func() {
+		if acked {
+			return
+		}
+		if attempt >= s.config.MaxAttempts {
+			from.dlq = append(from.dlq, SimDeadLetter{Change: c, Peer: peer, Attempts: attempt})
+			s.stats.DeadLettered++
+			return
+		}
+		s.stats.Retries++
+		s.after(s.config.RetryInterval<<(attempt-1), func() { s.ship(from, c, peer, attempt+1) })
+	})
+}
+
+// SimNode is one region's node
+type SimNode struct {
+	Region string
+	Index  *metadata.Index
+
+	// Skew offsets the node's clock from the simulation's, and with it the
+	// ModTime last write wins compares
+	Skew time.Duration
+
+	sim        *Sim
+	tombstones map[string]SimChange // Deletions, by tenant and key, so older versions stay deleted
+	dlq        []SimDeadLetter
+}
+
+// Now returns the node's clock
+func (n *SimNode) Now() time.Time {
+	return n.sim.now.Add(n.Skew)
+}
+
+// Put writes a new version of key and replicates it to every peer
+func (n *SimNode) Put(tenantID, key string, size int64) SimChange {
+	return n.write(tenantID, key, size, false)
+}
+
+// Delete deletes key and replicates the deletion to every peer
+func (n *SimNode) Delete(tenantID, key string) SimChange {
+	return n.write(tenantID, key, 0, true)
+}
+
+func (n *SimNode) write(tenantID, key string, size int64, deleted bool) SimChange {
+	s := n.sim
+	s.stats.Writes++
+	c := SimChange{
+		Tenant:    tenantID,
+		Key:       key,
+		VersionID: fmt.Sprintf("%s-%d", n.Region, s.stats.Writes),
+		ModTime:   n.Now().UnixNano(),
+		Deleted:   deleted,
+		Origin:    n.Region,
+	}
+	n.store(c, size)
+	for _, peer := range s.regions {
+		if peer != n.Region {
+			s.ship(n, c, peer, 1)
+		}
+	}
+	return c
+}
+
+// current returns the node's version of an object, deleted or not
+func (n *SimNode) current(tenantID, key string) (SimChange, bool) {
+	if meta, err := n.Index.Get(tenantID, key); err == nil {
+		return SimChange{Tenant: tenantID, Key: key, VersionID: meta.VersionID, ModTime: meta.ModTime}, true
+	}
+	c, ok := n.tombstones[replicaKey(tenantID, key)]
+	return c, ok
+}
+
+// apply stores a replicated change unless the node holds a newer version
+func (n *SimNode) apply(c SimChange) {
+	cur, ok := n.current(c.Tenant, c.Key)
+	if ok && cur.VersionID == c.VersionID {
+		return
+	}
+	if ok && !c.newer(cur) {
+		n.sim.stats.Stale++
+		return
+	}
+	n.sim.stats.Applied++
+	n.store(c, 0)
+}
+
+func (n *SimNode) store(c SimChange, size int64) {
+	k := replicaKey(c.Tenant, c.Key)
+	if c.Deleted {
+		n.Index.Delete(c.Tenant, c.Key)
+		n.tombstones[k] = c
+		return
+	}
+	delete(n.tombstones, k)
+	n.Index.Put(metadata.ObjectMeta{Tenant: c.Tenant, Key: c.Key, Size: size, VersionID: c.VersionID, ModTime: c.ModTime})
+}
+
+// DeadLetters returns the changes the node gave up replicating
+func (n *SimNode) DeadLetters() []SimDeadLetter {
+	return append([]SimDeadLetter(nil), n.dlq...)
+}
+
+// Redrive replicates every dead-lettered change again, with fresh attempts
+func (n *SimNode) Redrive() int {
+	dlq := n.dlq
+	n.dlq = nil
+	for _, dl := range dlq {
+		n.sim.stats.Redriven++
+		n.sim.ship(n, dl.Change, dl.Peer, 1)
+	}
+	return len(dlq)
+}
+
+func replicaKey(tenantID, key string) string {
+	return tenantID + "\x00" + key
+}
+
+// simEvent is a scheduled callback
+type simEvent struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+// simQueue orders events by time, then by scheduling order
+type simQueue []*simEvent
+
+func (q simQueue) Len() int { return len(q) }
+func (q simQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+func (q simQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *simQueue) Push(x any)   { *q = append(*q, x.(*simEvent)) }
+func (q *simQueue) Pop() any {
+	old := *q
+	ev := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return ev
+}
@@ -0,0 +1,117 @@
+// internal/replication/sim_network.go
+// Fake network for the replication simulation: links between regions carry
+// messages with a configurable latency and jitter (which reorders them),
+// and can be cut and healed while messages are in flight
+package replication
+
+import (
+	"sort"
+	"time"
+)
+
+// simLink is the state of the link between two regions, both directions
+type simLink struct {
+	latency time.Duration
+	jitter  time.Duration
+	down    bool
+}
+
+// SimNetwork connects the simulated regions
+type SimNetwork struct {
+	sim   *Sim
+	links map[[2]string]*simLink
+}
+
+func newSimNetwork(sim *Sim) *SimNetwork {
+	return &SimNetwork{sim: sim, links: make(map[[2]string]*simLink)}
+}
+
+func linkKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+func (n *SimNetwork) link(a, b string) *simLink {
+	k := linkKey(a, b)
+	l := n.links[k]
+	if l == nil {
+		l = &simLink{latency: n.sim.config.Latency, jitter: n.sim.config.Jitter}
+		n.links[k] = l
+	}
+	return l
+}
+
+// SetLatency sets the one-way delay between a and b to latency plus up to
+// jitter, drawn per message. Jitter larger than the gap between two
+// messages can deliver them out of order.
+func (n *SimNetwork) SetLatency(a, b string, latency, jitter time.Duration) {
+	l := n.link(a, b)
+	l.latency, l.jitter = latency, jitter
+}
+
+// Partition cuts the link between a and b. Messages in flight on it are
+// lost.
+func (n *SimNetwork) Partition(a, b string) {
+	n.link(a, b).down = true
+}
+
+// Heal restores the link between a and b
+func (n *SimNetwork) Heal(a, b string) {
+	n.link(a, b).down = false
+}
+
+// Isolate cuts region off from every other region
+func (n *SimNetwork) Isolate(region string) {
+	for _, peer := range n.sim.Regions() {
+		if peer != region {
+			n.Partition(region, peer)
+		}
+	}
+}
+
+// HealAll restores every link
+func (n *SimNetwork) HealAll() {
+	for _, l := range n.links {
+		l.down = false
+	}
+}
+
+// Partitioned returns the links that are cut, each as a sorted pair
+func (n *SimNetwork) Partitioned() [][2]string {
+	var out [][2]string
+	for k, l := range n.links {
+		if l.down {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i][0] < out[j][0] || out[i][0] == out[j][0] && out[i][1] < out[j][1]
+	})
+	return out
+}
+
+// send delivers fn at the far end of the link from a to b after the link's
+// delay, unless the link is cut when the message is sent or arrives.
+// It reports whether the message left.
+func (n *SimNetwork) send(from, to string, fn func()) bool {
+	l := n.link(from, to)
+	if l.down {
+		n.sim.stats.Dropped++
+		return false
+	}
+	delay := l.latency
+	if l.jitter > 0 {
+		delay += time.Duration(n.sim.rng.Int63n(int64(l.jitter) + 1))
+	}
+	n.sim.stats.Messages++
+	n.sim.after(delay, func() {
+		if n.link(from, to).down {
+			n.sim.stats.Dropped++
+			return
+		}
+		fn()
+	})
+	return true
+}
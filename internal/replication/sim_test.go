@@ -0,0 +1,212 @@
+package replication
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var simRegions = []string{"ap-southeast-1", "eu-west-1", "us-west-2"}
+
+// settle runs s until it is idle, failing t if it never is
+func settle(t *testing.T, s *Sim) {
+	t.Helper()
+	if !s.Settle() {
+		t.Fatal("Simulation did not settle")
+	}
+}
+
+func version(t *testing.T, n *SimNode, key string) string {
+	t.Helper()
+	meta, err := n.Index.Get("t1", key)
+	if err != nil {
+		return ""
+	}
+	return meta.VersionID
+}
+
+func TestSimConvergesAfterPartitionHeals(t *testing.T) {
+	s := NewSim(DefaultSimConfig(simRegions...))
+	s.Network().Isolate("eu-west-1")
+
+	for i := 0; i < 20; i++ {
+		s.Node(simRegions[i%3]).Put("t1", fmt.Sprintf("obj/%d", i), 100)
+		s.Run(100 * time.Millisecond)
+	}
+	if s.Converged() {
+		t.Fatal("Converged across a partition")
+	}
+
+	// Heal before the retries run out
+	s.Run(3 * time.Second)
+	s.Network().HealAll()
+	settle(t, s)
+
+	if !s.Converged() {
+		t.Fatalf("Not converged after healing: %v", s.Divergent())
+	}
+	st := s.Stats()
+	if st.Retries == 0 || st.DeadLettered != 0 {
+		t.Errorf("Stats = %+v, want retries and no dead letters", st)
+	}
+}
+
+func TestSimLastWriteWinsFollowsClocks(t *testing.T) {
+	s := NewSim(DefaultSimConfig(simRegions...))
+	west, eu := s.Node("us-west-2"), s.Node("eu-west-1")
+	eu.Skew = time.Second
+
+	// eu-west-1 writes first, but its clock runs a second ahead, so its
+	// version beats the later write from us-west-2
+	first := eu.Put("t1", "k", 1)
+	s.Run(10 * time.Millisecond)
+	west.Put("t1", "k", 2)
+	settle(t, s)
+
+	if !s.Converged() {
+		t.Fatalf("Not converged: %v", s.Divergent())
+	}
+	for _, region := range simRegions {
+		if v := version(t, s.Node(region), "k"); v != first.VersionID {
+			t.Errorf("%s holds %s, want %s", region, v, first.VersionID)
+		}
+	}
+	if s.Stats().Stale == 0 {
+		t.Error("The losing write was not counted as stale")
+	}
+}
+
+func TestSimReorderedDeliveriesKeepNewest(t *testing.T) {
+	cfg := DefaultSimConfig("a", "b")
+	cfg.Jitter = 500 * time.Millisecond
+	s := NewSim(cfg)
+
+	var last SimChange
+	for i := 0; i < 50; i++ {
+		last = s.Node("a").Put("t1", "k", int64(i))
+		s.Run(5 * time.Millisecond)
+	}
+	settle(t, s)
+
+	if v := version(t, s.Node("b"), "k"); v != last.VersionID {
+		t.Errorf("b holds %s, want the last write %s", v, last.VersionID)
+	}
+	if s.Stats().Stale == 0 {
+		t.Error("No deliveries were reordered; raise the jitter")
+	}
+}
+
+func TestSimDeletesStayDeleted(t *testing.T) {
+	cfg := DefaultSimConfig("a", "b")
+	cfg.Jitter = 500 * time.Millisecond
+	s := NewSim(cfg)
+
+	s.Node("a").Put("t1", "k", 1)
+	s.Run(time.Millisecond)
+	s.Node("a").Delete("t1", "k")
+	settle(t, s)
+
+	for _, region := range s.Regions() {
+		if v := version(t, s.Node(region), "k"); v != "" {
+			t.Errorf("%s holds deleted key at %s", region, v)
+		}
+	}
+}
+
+func TestSimDeadLettersAndRedrive(t *testing.T) {
+	s := NewSim(DefaultSimConfig(simRegions...))
+	s.Network().Partition("us-west-2", "eu-west-1")
+
+	west := s.Node("us-west-2")
+	for i := 0; i < 5; i++ {
+		west.Put("t1", fmt.Sprintf("obj/%d", i), 10)
+	}
+	settle(t, s)
+
+	dead := west.DeadLetters()
+	if len(dead) != 5 || s.Stats().DeadLettered != 5 {
+		t.Fatalf("%d dead letters (stats %+v), want 5", len(dead), s.Stats())
+	}
+	for _, dl := range dead {
+		if dl.Peer != "eu-west-1" || dl.Attempts != DefaultSimConfig().MaxAttempts {
+			t.Errorf("Dead letter %+v, want eu-west-1 after every attempt", dl)
+		}
+	}
+	if s.Converged() {
+		t.Fatal("Converged with changes dead-lettered")
+	}
+
+	s.Network().HealAll()
+	if n := west.Redrive(); n != 5 {
+		t.Errorf("Redrive() = %d, want 5", n)
+	}
+	settle(t, s)
+	if !s.Converged() || len(west.DeadLetters()) != 0 {
+		t.Errorf("After redrive: divergent %v, dead letters %v", s.Divergent(), west.DeadLetters())
+	}
+}
+
+// randomScenario runs a random workload of writes, deletes, partitions and
+// heals drawn from seed, then heals every link and redrives every node
+func randomScenario(t *testing.T, seed int64) *Sim {
+	cfg := DefaultSimConfig(simRegions...)
+	cfg.Seed = seed
+	cfg.Jitter = 200 * time.Millisecond
+	s := NewSim(cfg)
+	rng := s.Rand()
+	for i, region := range simRegions {
+		s.Node(region).Skew = time.Duration(i-1) * 30 * time.Millisecond
+	}
+
+	for i := 0; i < 500; i++ {
+		node := s.Node(simRegions[rng.Intn(len(simRegions))])
+		key := fmt.Sprintf("obj/%d", rng.Intn(40))
+		switch p := rng.Float64(); {
+		case p < 0.02:
+			a, b := simRegions[rng.Intn(3)], simRegions[rng.Intn(3)]
+			if a != b {
+				s.Network().Partition(a, b)
+			}
+		case p < 0.05:
+			s.Network().HealAll()
+		case p < 0.2:
+			node.Delete("t1", key)
+		default:
+			node.Put("t1", key, rng.Int63n(1<<20))
+		}
+		s.Run(time.Duration(rng.Int63n(int64(100 * time.Millisecond))))
+	}
+
+	s.Network().HealAll()
+	settle(t, s)
+	for _, region := range simRegions {
+		s.Node(region).Redrive()
+	}
+	settle(t, s)
+	return s
+}
+
+func TestSimRandomScenariosConverge(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		s := randomScenario(t, seed)
+		if !s.Converged() {
+			t.Errorf("Seed %d: not converged: %v", seed, s.Divergent())
+		}
+	}
+}
+
+func TestSimIsDeterministic(t *testing.T) {
+	a, b := randomScenario(t, 42), randomScenario(t, 42)
+	if a.Stats() != b.Stats() {
+		t.Fatalf("Stats differ between runs: %+v vs %+v", a.Stats(), b.Stats())
+	}
+	for _, region := range simRegions {
+		if !reflect.DeepEqual(a.Node(region).Index.Snapshot(), b.Node(region).Index.Snapshot()) {
+			t.Errorf("%s differs between runs", region)
+		}
+	}
+	if a.Stats() == randomScenario(t, 43).Stats() {
+		t.Error("Different seeds ran identically")
+	}
+}
@@ -0,0 +1,175 @@
+// internal/replication/stream.go
+// Streaming replication for large objects. A task whose object is over
+// the stream threshold carries a reader over the stored bytes rather than
+// one buffer, and ships to each region in fixed-size chunks, each with
+// its SHA-256. A region acknowledges every chunk, and a retry resumes
+// from the last acknowledged offset instead of the start, so a failure
+// late in a multi-gigabyte transfer costs one chunk, not the object.
+package replication
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultStreamThreshold is the object size above which replication
+	// streams rather than shipping one buffer
+	DefaultStreamThreshold = 16 << 20
+
+	// DefaultStreamChunkSize is the size of each streamed chunk
+	DefaultStreamChunkSize = 8 << 20
+)
+
+// ErrChunkChecksum is returned when a chunk arrives not matching its SHA-256
+var ErrChunkChecksum = errors.New("replication: chunk checksum mismatch")
+
+// ChunkPut is one chunk of a streamed object on its way to a region
+type ChunkPut struct {
+	Region    string
+	Bucket    string
+	Key       string
+	VersionID string
+	Offset    int64 // Of Data within the object
+	Total     int64 // Object size
+	Data      []byte
+	SHA256    [sha256.Size]byte
+}
+
+// StreamStats counts streamed replication across regions
+type StreamStats struct {
+	Chunks  uint64 `json:"chunks"`
+	Bytes   uint64 `json:"bytes"`
+	Resumes uint64 `json:"resumes"` // Attempts that continued a partial transfer
+}
+
+type streamCounters struct {
+	chunks  atomic.Uint64
+	bytes   atomic.Uint64
+	resumes atomic.Uint64
+}
+
+// streamProgress records the bytes each region has acknowledged
+type streamProgress struct {
+	mu    sync.Mutex
+	acked map[string]int64
+}
+
+func (p *streamProgress) offset(region string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.acked[region]
+}
+
+func (p *streamProgress) ack(region string, offset int64) {
+	p.mu.Lock()
+	p.acked[region] = offset
+	p.mu.Unlock()
+}
+
+// EnqueueStream queues replication of the size bytes of src, read in
+// chunks as each region is sent them. src must stay readable until
+// onComplete is called.
+func (e *V3ReplicationEngine) EnqueueStream(priority int, bucket, key, versionID, requestID string, src io.ReaderAt, size int64, regions []string,
+	onRegion func(region string, ok bool), onComplete func(replicatedRegions int)) error {
+	return e.enqueue(priority, bucket, key, versionID, requestID, nil, src, size, regions, onRegion, onComplete)
+}
+
+func (e *V3ReplicationEngine) streamThreshold() int64 {
+	if e.config.StreamThreshold > 0 {
+		return e.config.StreamThreshold
+	}
+	return DefaultStreamThreshold
+}
+
+func (e *V3ReplicationEngine) streamChunkSize() int64 {
+	if e.config.StreamChunkSize > 0 {
+		return e.config.StreamChunkSize
+	}
+	return DefaultStreamChunkSize
+}
+
+// streamToRegion sends task's object to region from the last offset the
+// region acknowledged, one checksummed chunk at a time
+func (e *V3ReplicationEngine) streamToRegion(region, bucket, key string, task *V3ReplicationTask) error {
+	pool := e.connectionPools[region]
+	if pool == nil {
+		return fmt.Errorf("no pool for region: %s", region)
+	}
+
+	total := int64(task.DataSize.Load())
+	offset := task.progress.offset(region)
+	if offset > 0 {
+		e.streams.resumes.Add(1)
+	}
+
+	buf := make([]byte, min(e.streamChunkSize(), total-offset))
+	for offset < total {
+		chunk := buf[:min(int64(len(buf)), total-offset)]
+		n, err := task.Source.ReadAt(chunk, offset)
+		if n < len(chunk) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("read at %d: %w", offset, err)
+		}
+
+		put := &ChunkPut{
+			Region:    region,
+			Bucket:    bucket,
+			Key:       key,
+			VersionID: string(task.VersionID[:task.VersionIDLen]),
+			Offset:    offset,
+			Total:     total,
+			Data:      chunk,
+			SHA256:    sha256.Sum256(chunk),
+		}
+		if err := e.sendChunk(pool, put); err != nil {
+			pool.errors.Add(1)
+			return fmt.Errorf("chunk at %d: %w", offset, err)
+		}
+
+		offset += int64(n)
+		task.progress.ack(region, offset)
+		e.streams.chunks.Add(1)
+		e.streams.bytes.Add(uint64(n))
+	}
+	return nil
+}
+
+// putChunk ships one chunk to the region's pool
+func (e *V3ReplicationEngine) putChunk(pool *V3ConnectionPool, put *ChunkPut) error {
+	start := time.Now()
+
+	clientIdx := pool.nextClient.Add(1) % uint64(pool.clientCount)
+	client := pool.clients[clientIdx]
+
+	// Simulate HTTP/2 PUT of the chunk
+	// In production, this would carry Offset and Total in a Content-Range
+	// header and SHA256 as X-Chunk-SHA256; the region verifies the digest
+	// before acknowledging, as done here
+	_ = client
+	if sha256.Sum256(put.Data) != put.SHA256 {
+		return ErrChunkChecksum
+	}
+	time.Sleep(1 * time.Millisecond) // Simulate network
+
+	pool.requests.Add(1)
+	pool.lastSuccess.Store(time.Now().UnixNano())
+	pool.avgLatency.Store(time.Since(start).Nanoseconds())
+	return nil
+}
+
+// StreamStats returns streamed replication totals
+func (e *V3ReplicationEngine) StreamStats() StreamStats {
+	return StreamStats{
+		Chunks:  e.streams.chunks.Load(),
+		Bytes:   e.streams.bytes.Load(),
+		Resumes: e.streams.resumes.Load(),
+	}
+}
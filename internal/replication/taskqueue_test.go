@@ -0,0 +1,11 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/minio/enterprise/internal/lincheck"
+)
+
+func TestV3TaskQueueLinearizable(t *testing.T) {
+	lincheck.TestQueue(t, func(capacity int) lincheck.Queue { return newV3TaskQueue(capacity) })
+}
@@ -0,0 +1,121 @@
+// internal/scan/icap.go
+// ICAP (RFC 3507) client. Objects are sent as the body of an encapsulated
+// HTTP response in a RESPMOD request, which antivirus ICAP services accept.
+package scan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICAP scans through an ICAP service
+type ICAP struct {
+	// URL is the service, e.g. icap://av.internal:1344/avscan
+	URL     string
+	Timeout time.Duration
+}
+
+// Headers antivirus ICAP services use to report a detection
+var icapInfectionHeaders = []string{"X-Infection-Found", "X-Virus-Id", "X-Violations-Found"}
+
+func (c *ICAP) Scan(ctx context.Context, data []byte) (Verdict, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Scheme != "icap" || u.Host == "" {
+		return Verdict{}, fmt.Errorf("%w: invalid ICAP URL %q", ErrUnavailable, c.URL)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "1344")
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	resHdr := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: " +
+		strconv.Itoa(len(data)) + "\r\n\r\n"
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", c.URL)
+	fmt.Fprintf(w, "Host: %s\r\n", u.Host)
+	w.WriteString("Allow: 204\r\n")
+	w.WriteString("Connection: close\r\n")
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n", len(resHdr))
+	w.WriteString(resHdr)
+	if len(data) > 0 {
+		fmt.Fprintf(w, "%x\r\n", len(data))
+		w.Write(data)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return Verdict{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	status, err := tp.ReadLine()
+	if err != nil {
+		return Verdict{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return Verdict{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return parseICAPResponse(status, header)
+}
+
+// parseICAPResponse maps 204 to clean and 200 to infected: an antivirus
+// service only modifies a response to block it
+func parseICAPResponse(status string, header textproto.MIMEHeader) (Verdict, error) {
+	proto, rest, _ := strings.Cut(status, " ")
+	code, _, _ := strings.Cut(rest, " ")
+	if !strings.HasPrefix(proto, "ICAP/") {
+		return Verdict{}, fmt.Errorf("%w: malformed ICAP status %q", ErrUnavailable, status)
+	}
+
+	switch code {
+	case "204":
+		return Verdict{}, nil
+	case "200":
+		v := Verdict{Infected: true}
+		for _, name := range icapInfectionHeaders {
+			if found := header.Get(name); found != "" {
+				v.Signature = icapThreat(found)
+				break
+			}
+		}
+		return v, nil
+	default:
+		return Verdict{}, fmt.Errorf("%w: ICAP %s", ErrUnavailable, rest)
+	}
+}
+
+// icapThreat extracts Threat= from "Type=0; Resolution=2; Threat=Name;",
+// returning the header as-is if it has another form
+func icapThreat(header string) string {
+	for _, field := range strings.Split(header, ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(field), "="); ok && strings.EqualFold(name, "Threat") {
+			return value
+		}
+	}
+	return strings.TrimSpace(header)
+}
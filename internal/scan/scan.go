@@ -0,0 +1,62 @@
+// internal/scan/scan.go
+// Upload content inspection: MIME sniffing against per-tenant allowlists
+// and malware scanning through clamd or an ICAP server.
+package scan
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds one scan, including connecting to the scanner
+const DefaultTimeout = 30 * time.Second
+
+// ErrUnavailable wraps failures to reach or understand the scanner
+var ErrUnavailable = errors.New("scanner unavailable")
+
+// Verdict is the outcome of scanning one object
+type Verdict struct {
+	Infected bool
+	// Signature names what was found, when the scanner reports it
+	Signature string
+}
+
+// Scanner inspects object data for malware
+type Scanner interface {
+	Scan(ctx context.Context, data []byte) (Verdict, error)
+}
+
+// Sniff returns the media type of data judged from its content, without
+// parameters. The client's declared type is not consulted so it cannot be
+// used to slip past an allowlist.
+func Sniff(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// Allowed reports whether mediaType matches one of patterns: exact types
+// such as "image/png", wildcards such as "image/*", or "*/*". An empty
+// allowlist allows everything.
+func Allowed(patterns []string, mediaType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "*/*", p == mediaType:
+			return true
+		case strings.HasSuffix(p, "/*") && strings.TrimSuffix(p, "/*") == major:
+			return true
+		}
+	}
+	return false
+}
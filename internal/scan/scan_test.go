@@ -0,0 +1,159 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestSniffAndAllowed(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if got := Sniff(png); got != "image/png" {
+		t.Errorf("Sniff(png) = %q", got)
+	}
+	if got := Sniff([]byte("hello")); got != "text/plain" {
+		t.Errorf("Sniff(text) = %q, want parameters stripped", got)
+	}
+
+	for _, tc := range []struct {
+		patterns []string
+		media    string
+		want     bool
+	}{
+		{nil, "application/x-msdownload", true},
+		{[]string{"image/*"}, "image/png", true},
+		{[]string{"image/*"}, "text/plain", false},
+		{[]string{"text/plain", "application/pdf"}, "application/pdf", true},
+		{[]string{"*/*"}, "application/zip", true},
+		{[]string{"image/png"}, "image/jpeg", false},
+	} {
+		if got := Allowed(tc.patterns, tc.media); got != tc.want {
+			t.Errorf("Allowed(%v, %q) = %v", tc.patterns, tc.media, got)
+		}
+	}
+}
+
+// fakeServer accepts one connection and hands it to serve
+func fakeServer(t *testing.T, serve func(net.Conn)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn)
+	}()
+	return ln.Addr().String()
+}
+
+func TestClamAV(t *testing.T) {
+	eicar := bytes.Repeat([]byte("X"), clamChunk+10) // Spans two chunks
+
+	addr := fakeServer(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		if cmd, _ := r.ReadString(0); cmd != "zINSTREAM\x00" {
+			t.Errorf("command = %q", cmd)
+			return
+		}
+		var got []byte
+		for {
+			var size [4]byte
+			io.ReadFull(r, size[:])
+			n := binary.BigEndian.Uint32(size[:])
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			io.ReadFull(r, chunk)
+			got = append(got, chunk...)
+		}
+		if !bytes.Equal(got, eicar) {
+			t.Errorf("streamed %d bytes, want %d", len(got), len(eicar))
+		}
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+	})
+
+	v, err := (&ClamAV{Addr: addr}).Scan(context.Background(), eicar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Infected || v.Signature != "Eicar-Test-Signature" {
+		t.Errorf("verdict = %+v", v)
+	}
+}
+
+func TestClamAVReplies(t *testing.T) {
+	if v, err := parseClamReply("stream: OK"); err != nil || v.Infected {
+		t.Errorf("OK = %+v, %v", v, err)
+	}
+	if _, err := parseClamReply("INSTREAM size limit exceeded. ERROR"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("ERROR reply err = %v", err)
+	}
+	if _, err := (&ClamAV{Addr: "127.0.0.1:1"}).Scan(context.Background(), []byte("x")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("unreachable err = %v", err)
+	}
+}
+
+func TestICAP(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		response string
+		want     Verdict
+	}{
+		{"clean", "ICAP/1.0 204 No Content\r\nISTag: \"1\"\r\n\r\n", Verdict{}},
+		{"infected", "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=EICAR;\r\nEncapsulated: null-body=0\r\n\r\n",
+			Verdict{Infected: true, Signature: "EICAR"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := fakeServer(t, func(conn net.Conn) {
+				tp := textproto.NewReader(bufio.NewReader(conn))
+				line, _ := tp.ReadLine()
+				if !strings.HasPrefix(line, "RESPMOD icap://") {
+					t.Errorf("request line = %q", line)
+				}
+				header, _ := tp.ReadMIMEHeader()
+				if header.Get("Allow") != "204" || !strings.HasPrefix(header.Get("Encapsulated"), "res-hdr=0, res-body=") {
+					t.Errorf("headers = %v", header)
+				}
+				// Encapsulated HTTP response headers, then the chunked body
+				if status, _ := tp.ReadLine(); status != "HTTP/1.1 200 OK" {
+					t.Errorf("encapsulated status = %q", status)
+				}
+				if _, err := tp.ReadMIMEHeader(); err != nil {
+					t.Error(err)
+				}
+				for {
+					line, err := tp.ReadLine()
+					if err != nil || line == "0" {
+						break
+					}
+				}
+				conn.Write([]byte(tc.response))
+			})
+
+			v, err := (&ICAP{URL: "icap://" + addr + "/avscan"}).Scan(context.Background(), []byte("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tc.want {
+				t.Errorf("verdict = %+v, want %+v", v, tc.want)
+			}
+		})
+	}
+
+	if _, err := parseICAPResponse("ICAP/1.0 500 Server Error", nil); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("500 err = %v", err)
+	}
+}
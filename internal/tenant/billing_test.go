@@ -0,0 +1,59 @@
+package tenant
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBillingPeriods(t *testing.T) {
+	p, err := ParseBillingPeriod("2026-03")
+	if err != nil || !p.Monthly || p.String() != "2026-03" || !p.End.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ParseBillingPeriod(2026-03) = %+v, %v", p, err)
+	}
+	if prev := p.Previous(); prev.String() != "2026-02" || !prev.End.Equal(p.Start) {
+		t.Errorf("Previous() = %+v", prev)
+	}
+	d, err := ParseBillingPeriod("2026-03-01")
+	if err != nil || d.Monthly || d.Previous().String() != "2026-02-28" {
+		t.Errorf("ParseBillingPeriod(2026-03-01) = %+v, %v", d, err)
+	}
+	if _, err := ParseBillingPeriod("March"); err == nil {
+		t.Error("Expected an error for a malformed period")
+	}
+	if _, err := BillingPeriodAt("weekly", time.Now()); err == nil {
+		t.Error("Expected an error for an unknown period length")
+	}
+}
+
+func TestBillingExportIsReproducible(t *testing.T) {
+	p, _ := ParseBillingPeriod("2026-03")
+	day := p.Start.AddDate(0, 0, 4)
+	items := func() []BillingLineItem {
+		return []BillingLineItem{
+			{TenantID: "t2", UsageStart: day, UsageEnd: day.AddDate(0, 0, 1), UsageType: UsageEgress, UsageAmount: 10, Unit: UnitBytes},
+			{TenantID: "t1", UsageStart: day, UsageEnd: day.AddDate(0, 0, 1), UsageType: UsageRequests, Operation: "write", UsageAmount: 3, Unit: UnitRequests},
+			{TenantID: "t1", UsageStart: day, UsageEnd: day.AddDate(0, 0, 1), UsageType: UsageRequests, Operation: "read", UsageAmount: 7, Unit: UnitRequests},
+		}
+	}
+	a, b := NewBillingExport(p, items()), NewBillingExport(p, items()[1:])
+	b.LineItems = append(b.LineItems, NewBillingExport(p, items()[:1]).LineItems...)
+
+	if a.LineItems[0].Operation != "read" || a.LineItems[2].TenantID != "t2" {
+		t.Errorf("Line items out of order: %+v", a.LineItems)
+	}
+	if a.LineItems[0].LineItemID == a.LineItems[1].LineItemID {
+		t.Error("Distinct line items share an ID")
+	}
+	da, _ := a.Digest()
+	db, _ := b.Digest()
+	if da != db || !bytes.Equal(a.CSV(), b.CSV()) {
+		t.Error("The same usage produced different exports")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(a.CSV())), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "line_item_id,") || !strings.Contains(lines[1], ",requests,read,7,Requests") {
+		t.Errorf("Unexpected CSV:\n%s", a.CSV())
+	}
+}
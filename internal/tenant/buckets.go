@@ -0,0 +1,193 @@
+// internal/tenant/buckets.go
+// Buckets: named containers within a tenant's namespace. A bucket holds
+// the objects whose keys begin with its name and a "/", and carries
+// configuration that applies to just those objects.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bucket errors
+var (
+	ErrBucketNotFound = errors.New("bucket not found")
+	ErrBucketExists   = errors.New("bucket already exists")
+)
+
+// MaxBucketTags bounds the tags a bucket carries
+const MaxBucketTags = 50
+
+// BucketConfig is configuration scoped to one bucket, applied on top of
+// the tenant's settings
+type BucketConfig struct {
+	ObjectLock bool              `json:"object_lock"` // Objects may not be overwritten
+	AppendOnly bool              `json:"append_only"` // New keys only: no overwrite or client delete
+	Tags       map[string]string `json:"tags,omitempty"`
+
+	// PublicRead serves the bucket's objects to anonymous GETs. With
+	// RefererTokens they must also carry a referer token issued for the
+	// page linking them.
+	PublicRead    bool `json:"public_read"`
+	RefererTokens bool `json:"referer_tokens"`
+}
+
+// Bucket is a tenant's bucket
+type Bucket struct {
+	Name      string       `json:"name"`
+	TenantID  string       `json:"tenant_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Config    BucketConfig `json:"config"`
+}
+
+// bucketStore holds every tenant's buckets by name
+type bucketStore struct {
+	mu       sync.RWMutex
+	byTenant map[string]map[string]*Bucket
+}
+
+func newBucketStore() *bucketStore {
+	return &bucketStore{byTenant: make(map[string]map[string]*Bucket)}
+}
+
+// ValidateBucketName checks name follows S3 bucket naming: 3 to 63
+// lowercase letters, digits, hyphens and dots, starting and ending with a
+// letter or digit, without adjacent dots
+func ValidateBucketName(name string) error {
+	if len(name) < 3 || len(name) > 63 {
+		return fmt.Errorf("bucket name %q must be 3 to 63 characters", name)
+	}
+	alnum := func(c byte) bool { return 'a' <= c && c <= 'z' || '0' <= c && c <= '9' }
+	if !alnum(name[0]) || !alnum(name[len(name)-1]) {
+		return fmt.Errorf("bucket name %q must start and end with a letter or digit", name)
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; !alnum(c) && c != '-' && c != '.' {
+			return fmt.Errorf("bucket name %q may only hold lowercase letters, digits, hyphens and dots", name)
+		}
+	}
+	if strings.Contains(name, "..") {
+		return fmt.Errorf("bucket name %q has adjacent dots", name)
+	}
+	return nil
+}
+
+func validateBucketConfig(config BucketConfig) error {
+	if config.RefererTokens && !config.PublicRead {
+		return fmt.Errorf("referer_tokens requires public_read")
+	}
+	if len(config.Tags) > MaxBucketTags {
+		return fmt.Errorf("a bucket has at most %d tags", MaxBucketTags)
+	}
+	for k := range config.Tags {
+		if k == "" {
+			return fmt.Errorf("bucket tag keys must not be empty")
+		}
+	}
+	return nil
+}
+
+func cloneBucket(b *Bucket) *Bucket {
+	c := *b
+	if b.Config.Tags != nil {
+		c.Config.Tags = make(map[string]string, len(b.Config.Tags))
+		for k, v := range b.Config.Tags {
+			c.Config.Tags[k] = v
+		}
+	}
+	return &c
+}
+
+// CreateBucket creates bucket name in tenantID
+func (tm *V3TenantManager) CreateBucket(ctx context.Context, tenantID, name string, config BucketConfig) (*Bucket, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if err := ValidateBucketName(name); err != nil {
+		return nil, err
+	}
+	if err := validateBucketConfig(config); err != nil {
+		return nil, err
+	}
+
+	b := cloneBucket(&Bucket{Name: name, TenantID: tenantID, CreatedAt: time.Now().UTC(), Config: config})
+
+	tm.buckets.mu.Lock()
+	defer tm.buckets.mu.Unlock()
+	buckets := tm.buckets.byTenant[tenantID]
+	if buckets == nil {
+		buckets = make(map[string]*Bucket)
+		tm.buckets.byTenant[tenantID] = buckets
+	}
+	if _, exists := buckets[name]; exists {
+		return nil, ErrBucketExists
+	}
+	buckets[name] = b
+	return cloneBucket(b), nil
+}
+
+// GetBucket returns tenantID's bucket name
+func (tm *V3TenantManager) GetBucket(ctx context.Context, tenantID, name string) (*Bucket, error) {
+	tm.buckets.mu.RLock()
+	defer tm.buckets.mu.RUnlock()
+	b, exists := tm.buckets.byTenant[tenantID][name]
+	if !exists {
+		return nil, ErrBucketNotFound
+	}
+	return cloneBucket(b), nil
+}
+
+// ListBuckets returns tenantID's buckets sorted by name
+func (tm *V3TenantManager) ListBuckets(ctx context.Context, tenantID string) []Bucket {
+	tm.buckets.mu.RLock()
+	out := make([]Bucket, 0, len(tm.buckets.byTenant[tenantID]))
+	for _, b := range tm.buckets.byTenant[tenantID] {
+		out = append(out, *cloneBucket(b))
+	}
+	tm.buckets.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// UpdateBucketConfig replaces the configuration of tenantID's bucket name
+func (tm *V3TenantManager) UpdateBucketConfig(ctx context.Context, tenantID, name string, config BucketConfig) (*Bucket, error) {
+	if err := validateBucketConfig(config); err != nil {
+		return nil, err
+	}
+	tm.buckets.mu.Lock()
+	defer tm.buckets.mu.Unlock()
+	b, exists := tm.buckets.byTenant[tenantID][name]
+	if !exists {
+		return nil, ErrBucketNotFound
+	}
+	b.Config = cloneBucket(&Bucket{Config: config}).Config
+	return cloneBucket(b), nil
+}
+
+// DeleteBucket removes tenantID's bucket name. Callers must check it is
+// empty first.
+func (tm *V3TenantManager) DeleteBucket(ctx context.Context, tenantID, name string) error {
+	tm.buckets.mu.Lock()
+	defer tm.buckets.mu.Unlock()
+	if _, exists := tm.buckets.byTenant[tenantID][name]; !exists {
+		return ErrBucketNotFound
+	}
+	delete(tm.buckets.byTenant[tenantID], name)
+	if len(tm.buckets.byTenant[tenantID]) == 0 {
+		delete(tm.buckets.byTenant, tenantID)
+	}
+	return nil
+}
+
+// deleteTenantBuckets forgets every bucket of tenantID
+func (tm *V3TenantManager) deleteTenantBuckets(tenantID string) {
+	tm.buckets.mu.Lock()
+	delete(tm.buckets.byTenant, tenantID)
+	tm.buckets.mu.Unlock()
+}
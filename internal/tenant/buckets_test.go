@@ -0,0 +1,56 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBucketLifecycle(t *testing.T) {
+	tm, id := newTestTenant(t, 0, 0, 0)
+	ctx := context.Background()
+
+	config := BucketConfig{ObjectLock: true, Tags: map[string]string{"team": "media"}}
+	b, err := tm.CreateBucket(ctx, id, "photos", config)
+	if err != nil || b.Name != "photos" || !b.Config.ObjectLock {
+		t.Fatalf("CreateBucket() = %+v, %v", b, err)
+	}
+	config.Tags["team"] = "changed"
+	if got, _ := tm.GetBucket(ctx, id, "photos"); got.Config.Tags["team"] != "media" {
+		t.Error("The stored config shares the caller's tags")
+	}
+	if _, err := tm.CreateBucket(ctx, id, "photos", BucketConfig{}); !errors.Is(err, ErrBucketExists) {
+		t.Errorf("Duplicate CreateBucket() = %v, want ErrBucketExists", err)
+	}
+	if _, err := tm.CreateBucket(ctx, "tenant-missing", "photos", BucketConfig{}); err == nil {
+		t.Error("Expected an error creating a bucket for an unknown tenant")
+	}
+	for _, name := range []string{"ab", "Photos", "-photos", "a..b", "photos/2026"} {
+		if _, err := tm.CreateBucket(ctx, id, name, BucketConfig{}); err == nil {
+			t.Errorf("CreateBucket(%q) accepted an invalid name", name)
+		}
+	}
+
+	tm.CreateBucket(ctx, id, "archive", BucketConfig{})
+	if list := tm.ListBuckets(ctx, id); len(list) != 2 || list[0].Name != "archive" {
+		t.Errorf("ListBuckets() = %+v", list)
+	}
+
+	b, err = tm.UpdateBucketConfig(ctx, id, "photos", BucketConfig{AppendOnly: true})
+	if err != nil || b.Config.ObjectLock || !b.Config.AppendOnly {
+		t.Errorf("UpdateBucketConfig() = %+v, %v", b, err)
+	}
+	if err := tm.DeleteBucket(ctx, id, "photos"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.GetBucket(ctx, id, "photos"); !errors.Is(err, ErrBucketNotFound) {
+		t.Errorf("GetBucket() after delete = %v, want ErrBucketNotFound", err)
+	}
+
+	if err := tm.DeleteTenant(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if list := tm.ListBuckets(ctx, id); len(list) != 0 {
+		t.Errorf("Buckets outlived their tenant: %+v", list)
+	}
+}
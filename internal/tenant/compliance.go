@@ -0,0 +1,276 @@
+// internal/tenant/compliance.go
+// Compliance modules enabled per tenant, stored in V3TenantConfig.Flags,
+// and the security settings presets enforce for HIPAA and PCI-DSS
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrComplianceViolation is returned for settings a tenant's presets forbid
+var ErrComplianceViolation = errors.New("setting violates compliance preset")
+
+// Compliance module flags
+const (
+	ComplianceGDPR uint32 = 1 << iota
+	ComplianceHIPAA
+	CompliancePCIDSS
+)
+
+var complianceNames = []struct {
+	flag uint32
+	name string
+}{
+	{ComplianceGDPR, "GDPR"},
+	{ComplianceHIPAA, "HIPAA"},
+	{CompliancePCIDSS, "PCI-DSS"},
+}
+
+// ParseComplianceModules converts module names ("GDPR", "HIPAA", "PCI-DSS", "NONE") to flags
+func ParseComplianceModules(modules []string) (uint32, error) {
+	var flags uint32
+	for _, m := range modules {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m == "" || m == "NONE" {
+			continue
+		}
+		found := false
+		for _, c := range complianceNames {
+			if c.name == m || (c.flag == CompliancePCIDSS && m == "PCI") {
+				flags |= c.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown compliance module: %s", m)
+		}
+	}
+	return flags, nil
+}
+
+// ComplianceModuleNames converts flags back to module names
+func ComplianceModuleNames(flags uint32) []string {
+	names := make([]string, 0, len(complianceNames))
+	for _, c := range complianceNames {
+		if flags&c.flag != 0 {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// TenantSettings are the per-tenant security controls presets constrain
+type TenantSettings struct {
+	RequireTLS    bool     `json:"require_tls"`
+	EncryptAtRest bool     `json:"encrypt_at_rest"`
+	AuditLogging  bool     `json:"audit_logging"`
+	ObjectLock    bool     `json:"object_lock"`
+	Regions       []string `json:"regions,omitempty"` // Empty means any region
+
+	// Upload content inspection
+	AllowedContentTypes []string `json:"allowed_content_types,omitempty"` // Sniffed types, e.g. "image/*"; empty allows any
+	MaxObjectBytes      int64    `json:"max_object_bytes,omitempty"`      // 0 means no limit
+	ScanUploads         bool     `json:"scan_uploads"`                    // Malware-scan every upload
+
+	ImageTransforms bool `json:"image_transforms"` // Serve resized/transcoded variants on download
+}
+
+// AllowsRegion reports whether data may be stored in region
+func (s TenantSettings) AllowsRegion(region string) bool {
+	if len(s.Regions) == 0 {
+		return true
+	}
+	for _, r := range s.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// CompliancePreset is the minimum configuration a compliance module requires
+type CompliancePreset struct {
+	Module            string   `json:"module"`
+	RequireTLS        bool     `json:"require_tls"`
+	RequireEncryption bool     `json:"require_encryption"`
+	RequireAudit      bool     `json:"require_audit"`
+	RequireObjectLock bool     `json:"require_object_lock"`
+	AllowedRegions    []string `json:"allowed_regions,omitempty"` // Empty means any region
+}
+
+// Built-in presets, keyed by module flag
+var compliancePresets = map[uint32]CompliancePreset{
+	ComplianceHIPAA: {
+		Module:            "HIPAA",
+		RequireTLS:        true,
+		RequireEncryption: true,
+		RequireAudit:      true,
+		RequireObjectLock: true,
+		AllowedRegions:    []string{"us-east-1", "us-west-2"},
+	},
+	CompliancePCIDSS: {
+		Module:            "PCI-DSS",
+		RequireTLS:        true,
+		RequireEncryption: true,
+		RequireAudit:      true,
+		RequireObjectLock: true,
+	},
+}
+
+// CompliancePresets returns the presets enabled by flags
+func CompliancePresets(flags uint32) []CompliancePreset {
+	var out []CompliancePreset
+	for _, c := range complianceNames {
+		if p, ok := compliancePresets[c.flag]; ok && flags&c.flag != 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ValidateSettings returns every way settings fall short of the presets in flags
+func ValidateSettings(flags uint32, settings TenantSettings) []string {
+	var violations []string
+	for _, p := range CompliancePresets(flags) {
+		if p.RequireTLS && !settings.RequireTLS {
+			violations = append(violations, p.Module+" requires TLS-only access")
+		}
+		if p.RequireEncryption && !settings.EncryptAtRest {
+			violations = append(violations, p.Module+" requires encryption at rest")
+		}
+		if p.RequireAudit && !settings.AuditLogging {
+			violations = append(violations, p.Module+" requires audit logging")
+		}
+		if p.RequireObjectLock && !settings.ObjectLock {
+			violations = append(violations, p.Module+" requires object lock")
+		}
+		if len(p.AllowedRegions) > 0 {
+			if len(settings.Regions) == 0 {
+				violations = append(violations, fmt.Sprintf("%s restricts regions to %s", p.Module, strings.Join(p.AllowedRegions, ",")))
+			}
+			for _, r := range settings.Regions {
+				if !contains(p.AllowedRegions, r) {
+					violations = append(violations, fmt.Sprintf("%s does not allow region %s", p.Module, r))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// applyPresets tightens settings until they satisfy the presets in flags
+func applyPresets(flags uint32, settings TenantSettings) TenantSettings {
+	for _, p := range CompliancePresets(flags) {
+		settings.RequireTLS = settings.RequireTLS || p.RequireTLS
+		settings.EncryptAtRest = settings.EncryptAtRest || p.RequireEncryption
+		settings.AuditLogging = settings.AuditLogging || p.RequireAudit
+		settings.ObjectLock = settings.ObjectLock || p.RequireObjectLock
+
+		if len(p.AllowedRegions) > 0 {
+			var kept []string
+			for _, r := range settings.Regions {
+				if contains(p.AllowedRegions, r) {
+					kept = append(kept, r)
+				}
+			}
+			if len(kept) == 0 {
+				kept = append(kept, p.AllowedRegions...)
+			}
+			settings.Regions = kept
+		}
+	}
+	sort.Strings(settings.Regions)
+	return settings
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// settingsStore holds tenant settings by tenant ID
+type settingsStore struct {
+	mu       sync.RWMutex
+	settings map[string]TenantSettings
+}
+
+func newSettingsStore() *settingsStore {
+	return &settingsStore{settings: make(map[string]TenantSettings)}
+}
+
+// Settings returns the tenant's security settings
+func (tm *V3TenantManager) Settings(ctx context.Context, tenantID string) (TenantSettings, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return TenantSettings{}, err
+	}
+
+	tm.settings.mu.RLock()
+	defer tm.settings.mu.RUnlock()
+
+	settings := tm.settings.settings[tenantID]
+	settings.Regions = append([]string(nil), settings.Regions...)
+	settings.AllowedContentTypes = append([]string(nil), settings.AllowedContentTypes...)
+	return settings, nil
+}
+
+// UpdateSettings replaces the tenant's security settings, refusing any change
+// that would violate an enabled compliance preset
+func (tm *V3TenantManager) UpdateSettings(ctx context.Context, tenantID string, settings TenantSettings) error {
+	config, err := tm.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if violations := ValidateSettings(config.Flags.Load(), settings); len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrComplianceViolation, strings.Join(violations, "; "))
+	}
+
+	settings.Regions = append([]string(nil), settings.Regions...)
+	sort.Strings(settings.Regions)
+	settings.AllowedContentTypes = append([]string(nil), settings.AllowedContentTypes...)
+
+	tm.settings.mu.Lock()
+	tm.settings.settings[tenantID] = settings
+	tm.settings.mu.Unlock()
+	return nil
+}
+
+// SetComplianceModules replaces the tenant's enabled compliance modules,
+// tightening its settings to satisfy any newly enabled presets
+func (tm *V3TenantManager) SetComplianceModules(ctx context.Context, tenantID string, flags uint32) error {
+	config, err := tm.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	tm.settings.mu.Lock()
+	tm.settings.settings[tenantID] = applyPresets(flags, tm.settings.settings[tenantID])
+	config.Flags.Store(flags)
+	tm.settings.mu.Unlock()
+	return nil
+}
+
+// ComplianceModules returns the tenant's enabled compliance module flags
+func (tm *V3TenantManager) ComplianceModules(ctx context.Context, tenantID string) (uint32, error) {
+	config, err := tm.GetTenant(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return config.Flags.Load(), nil
+}
+
+// HasCompliance reports whether every module in flags is enabled for the tenant
+func (tm *V3TenantManager) HasCompliance(ctx context.Context, tenantID string, flags uint32) bool {
+	enabled, err := tm.ComplianceModules(ctx, tenantID)
+	return err == nil && enabled&flags == flags
+}
@@ -0,0 +1,136 @@
+// internal/tenant/grants.go
+// Cross-tenant data sharing grants checked before server-side copies
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShareGrant allows TargetTenant to read SourceTenant objects under Prefix
+type ShareGrant struct {
+	ID           string    `json:"id"`
+	SourceTenant string    `json:"source_tenant"`
+	TargetTenant string    `json:"target_tenant"`
+	Prefix       string    `json:"prefix"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"` // Zero means no expiry
+}
+
+// grantStore indexes grants by source/target tenant pair
+type grantStore struct {
+	mu     sync.RWMutex
+	byID   map[string]*ShareGrant
+	byPair map[string][]*ShareGrant
+}
+
+func newGrantStore() *grantStore {
+	return &grantStore{
+		byID:   make(map[string]*ShareGrant),
+		byPair: make(map[string][]*ShareGrant),
+	}
+}
+
+func grantPair(source, target string) string {
+	return source + "\x00" + target
+}
+
+// CreateGrant lets target read source objects under prefix until expiresAt (zero for no expiry)
+func (tm *V3TenantManager) CreateGrant(ctx context.Context, sourceTenant, targetTenant, prefix string, expiresAt time.Time) (*ShareGrant, error) {
+	if sourceTenant == targetTenant {
+		return nil, fmt.Errorf("source and target tenant must differ")
+	}
+	for _, id := range []string{sourceTenant, targetTenant} {
+		if _, err := tm.GetTenant(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate grant ID: %w", err)
+	}
+
+	grant := &ShareGrant{
+		ID:           "grant-" + hex.EncodeToString(idBytes),
+		SourceTenant: sourceTenant,
+		TargetTenant: targetTenant,
+		Prefix:       prefix,
+		CreatedAt:    time.Now().UTC(),
+		ExpiresAt:    expiresAt,
+	}
+
+	tm.grants.mu.Lock()
+	defer tm.grants.mu.Unlock()
+
+	pair := grantPair(sourceTenant, targetTenant)
+	tm.grants.byID[grant.ID] = grant
+	tm.grants.byPair[pair] = append(tm.grants.byPair[pair], grant)
+
+	cp := *grant
+	return &cp, nil
+}
+
+// RevokeGrant removes a grant
+func (tm *V3TenantManager) RevokeGrant(ctx context.Context, grantID string) error {
+	tm.grants.mu.Lock()
+	defer tm.grants.mu.Unlock()
+
+	grant, exists := tm.grants.byID[grantID]
+	if !exists {
+		return fmt.Errorf("grant not found: %s", grantID)
+	}
+	delete(tm.grants.byID, grantID)
+
+	pair := grantPair(grant.SourceTenant, grant.TargetTenant)
+	grants := tm.grants.byPair[pair]
+	for i, g := range grants {
+		if g.ID == grantID {
+			tm.grants.byPair[pair] = append(grants[:i], grants[i+1:]...)
+			break
+		}
+	}
+	if len(tm.grants.byPair[pair]) == 0 {
+		delete(tm.grants.byPair, pair)
+	}
+	return nil
+}
+
+// ListGrants returns grants where tenantID is source or target (all grants if empty)
+func (tm *V3TenantManager) ListGrants(ctx context.Context, tenantID string) []ShareGrant {
+	tm.grants.mu.RLock()
+	defer tm.grants.mu.RUnlock()
+
+	out := make([]ShareGrant, 0)
+	for _, g := range tm.grants.byID {
+		if tenantID == "" || g.SourceTenant == tenantID || g.TargetTenant == tenantID {
+			out = append(out, *g)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// CheckGrant returns the grant authorizing target to read source's key, if any
+func (tm *V3TenantManager) CheckGrant(ctx context.Context, sourceTenant, targetTenant, key string) (*ShareGrant, error) {
+	tm.grants.mu.RLock()
+	defer tm.grants.mu.RUnlock()
+
+	now := time.Now()
+	for _, g := range tm.grants.byPair[grantPair(sourceTenant, targetTenant)] {
+		if !g.ExpiresAt.IsZero() && now.After(g.ExpiresAt) {
+			continue
+		}
+		if strings.HasPrefix(key, g.Prefix) {
+			cp := *g
+			return &cp, nil
+		}
+	}
+	return nil, fmt.Errorf("no grant from %s to %s covers %s", sourceTenant, targetTenant, key)
+}
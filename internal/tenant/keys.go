@@ -0,0 +1,84 @@
+// internal/tenant/keys.go
+// Per-tenant data encryption keys and crypto-shredding
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/minio/enterprise/internal/encryption"
+)
+
+// ErrKeyShredded is returned once a tenant's data key has been destroyed
+var ErrKeyShredded = errors.New("tenant data key has been shredded")
+
+type dataKey struct {
+	key        []byte
+	createdAt  time.Time
+	shreddedAt time.Time
+}
+
+// keyStore holds data keys by tenant ID
+type keyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*dataKey
+}
+
+func newKeyStore() *keyStore {
+	return &keyStore{keys: make(map[string]*dataKey)}
+}
+
+// DataKey returns the tenant's data key, generating it on first use
+func (tm *V3TenantManager) DataKey(ctx context.Context, tenantID string) ([]byte, error) {
+	tm.keys.mu.RLock()
+	dk := tm.keys.keys[tenantID]
+	tm.keys.mu.RUnlock()
+
+	if dk == nil {
+		if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+			return nil, err
+		}
+
+		tm.keys.mu.Lock()
+		if dk = tm.keys.keys[tenantID]; dk == nil {
+			key, err := encryption.NewKey()
+			if err != nil {
+				tm.keys.mu.Unlock()
+				return nil, err
+			}
+			dk = &dataKey{key: key, createdAt: time.Now().UTC()}
+			tm.keys.keys[tenantID] = dk
+		}
+		tm.keys.mu.Unlock()
+	}
+
+	tm.keys.mu.RLock()
+	defer tm.keys.mu.RUnlock()
+	if !dk.shreddedAt.IsZero() {
+		return nil, ErrKeyShredded
+	}
+	return dk.key, nil
+}
+
+// ShredDataKey irrecoverably destroys the tenant's data key, leaving a
+// tombstone so nothing is ever sealed under a fresh key by accident.
+// It returns the fingerprint of the destroyed key as evidence.
+func (tm *V3TenantManager) ShredDataKey(ctx context.Context, tenantID string) (string, error) {
+	if _, err := tm.DataKey(ctx, tenantID); err != nil {
+		return "", err
+	}
+
+	tm.keys.mu.Lock()
+	defer tm.keys.mu.Unlock()
+
+	dk := tm.keys.keys[tenantID]
+	fingerprint := encryption.Fingerprint(dk.key)
+	for i := range dk.key {
+		dk.key[i] = 0
+	}
+	dk.key = nil
+	dk.shreddedAt = time.Now().UTC()
+	return fingerprint, nil
+}
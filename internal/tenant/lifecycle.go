@@ -0,0 +1,117 @@
+// internal/tenant/lifecycle.go
+// Tenant suspension and deletion
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTenantSuspended is returned for operations on a suspended tenant
+var ErrTenantSuspended = errors.New("tenant is suspended")
+
+// Suspension records why and when a tenant was suspended
+type Suspension struct {
+	Reason      string    `json:"reason,omitempty"`
+	SuspendedAt time.Time `json:"suspended_at"`
+}
+
+// suspensionStore holds suspended tenants by ID
+type suspensionStore struct {
+	mu        sync.RWMutex
+	suspended map[string]Suspension
+}
+
+func newSuspensionStore() *suspensionStore {
+	return &suspensionStore{suspended: make(map[string]Suspension)}
+}
+
+// SuspendTenant blocks the tenant's data access until ResumeTenant. It
+// reports false if the tenant was already suspended.
+func (tm *V3TenantManager) SuspendTenant(ctx context.Context, tenantID, reason string) (bool, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return false, err
+	}
+
+	tm.suspensions.mu.Lock()
+	defer tm.suspensions.mu.Unlock()
+	if _, exists := tm.suspensions.suspended[tenantID]; exists {
+		return false, nil
+	}
+	tm.suspensions.suspended[tenantID] = Suspension{Reason: reason, SuspendedAt: time.Now().UTC()}
+	return true, nil
+}
+
+// ResumeTenant lifts a suspension, reporting false if there was none
+func (tm *V3TenantManager) ResumeTenant(ctx context.Context, tenantID string) (bool, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return false, err
+	}
+
+	tm.suspensions.mu.Lock()
+	defer tm.suspensions.mu.Unlock()
+	if _, exists := tm.suspensions.suspended[tenantID]; !exists {
+		return false, nil
+	}
+	delete(tm.suspensions.suspended, tenantID)
+	return true, nil
+}
+
+// Suspended returns the tenant's suspension, if any
+func (tm *V3TenantManager) Suspended(ctx context.Context, tenantID string) (*Suspension, bool) {
+	tm.suspensions.mu.RLock()
+	defer tm.suspensions.mu.RUnlock()
+
+	s, exists := tm.suspensions.suspended[tenantID]
+	if !exists {
+		return nil, false
+	}
+	return &s, true
+}
+
+// DeleteTenant removes the tenant record along with its settings, plan,
+// grants, service accounts and buckets. Callers must remove its objects
+// first.
+func (tm *V3TenantManager) DeleteTenant(ctx context.Context, tenantID string) error {
+	config, err := tm.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range tm.ListGrants(ctx, tenantID) {
+		tm.RevokeGrant(ctx, g.ID)
+	}
+	for _, acct := range tm.ListServiceAccounts(ctx, tenantID) {
+		tm.DeleteServiceAccount(ctx, tenantID, acct.ID)
+	}
+	tm.deleteTenantBuckets(tenantID)
+
+	tm.settings.mu.Lock()
+	delete(tm.settings.settings, tenantID)
+	tm.settings.mu.Unlock()
+
+	tm.suspensions.mu.Lock()
+	delete(tm.suspensions.suspended, tenantID)
+	tm.suspensions.mu.Unlock()
+
+	tm.plans.mu.Lock()
+	delete(tm.plans.byID, tenantID)
+	if name := string(config.Name[:config.NameLen]); tm.plans.byName[name] == tenantID {
+		delete(tm.plans.byName, name)
+	}
+	tm.plans.mu.Unlock()
+
+	shard := tm.shards[tm.fastHash(tenantID)&tm.shardMask]
+	if !tm.removeFromShard(shard, tenantID) {
+		return fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	tm.cache.Delete(tenantID)
+	tm.stats.TotalTenants.Add(-1)
+	if err := tm.forgetUsage(ctx, tenantID); err != nil {
+		return fmt.Errorf("failed to delete stored usage: %w", err)
+	}
+	return nil
+}
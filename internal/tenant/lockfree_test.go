@@ -0,0 +1,90 @@
+package tenant
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"github.com/minio/enterprise/internal/lincheck"
+)
+
+func TestV3QuotaQueueLinearizable(t *testing.T) {
+	lincheck.TestQueue(t, func(capacity int) lincheck.Queue { return newV3QuotaQueue(capacity) })
+}
+
+func newTestShard() (*V3TenantManager, *V3TenantShard) {
+	return &V3TenantManager{}, newV3TenantShard()
+}
+
+// Every tenant inserted concurrently into one shard keeps both its config
+// and its usage
+func TestV3ShardConcurrentInsertsKeepUsage(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		tm, shard := newTestShard()
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					id := fmt.Sprintf("t-%d-%d", g, i)
+					tm.insertIntoShard(shard, id, &V3TenantConfig{}, &V3QuotaUsage{})
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		for g := 0; g < 8; g++ {
+			for i := 0; i < 20; i++ {
+				id := fmt.Sprintf("t-%d-%d", g, i)
+				if tm.getFromShard(shard, id) == nil || tm.getUsageFromShard(shard, id) == nil {
+					t.Fatalf("Round %d: %s lost its config or usage", round, id)
+				}
+			}
+		}
+	}
+}
+
+// Concurrent histories of inserts, removes and lookups on a shard match a
+// set
+func TestV3ShardLinearizable(t *testing.T) {
+	keys := []string{"a", "b", "c"}
+	linearizable := func(seed int64) bool {
+		tm, shard := newTestShard()
+		var rec lincheck.Recorder
+		var wg sync.WaitGroup
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				rng := rand.New(rand.NewSource(seed + int64(c)))
+				for i := 0; i < 8; i++ {
+					key := keys[rng.Intn(len(keys))]
+					call := rec.Begin()
+					switch rng.Intn(3) {
+					case 0:
+						tm.insertIntoShard(shard, key, &V3TenantConfig{}, &V3QuotaUsage{})
+						rec.End(c, call, lincheck.Insert{Key: key}, nil)
+					case 1:
+						rec.End(c, call, lincheck.Remove{Key: key}, tm.removeFromShard(shard, key))
+					default:
+						found := tm.getFromShard(shard, key) != nil
+						rec.End(c, call, lincheck.Get{Key: key}, found)
+					}
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		ok, err := lincheck.Check(lincheck.MapModel(), rec.History())
+		if !ok || err != nil {
+			t.Logf("Shard history not linearizable (%v): %v", err, rec.History())
+		}
+		return ok && err == nil
+	}
+	if err := quick.Check(linearizable, lincheck.QuickConfig(t, 300)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,162 @@
+// internal/tenant/provisioning.go
+// Plan-based tenant provisioning, singly or from a batch manifest with
+// per-item results
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxProvisionBatch bounds the number of tenants in one manifest
+const MaxProvisionBatch = 1000
+
+// Plan is a named quota package
+type Plan struct {
+	Name           string `json:"name"`
+	StorageQuota   int64  `json:"storage_quota"`   // Bytes, zero for unlimited
+	BandwidthQuota int64  `json:"bandwidth_quota"` // Bytes in and out, zero for unlimited
+	IngressQuota   int64  `json:"ingress_quota"`   // Bytes uploaded, zero for unlimited
+	EgressQuota    int64  `json:"egress_quota"`    // Bytes downloaded, zero for unlimited
+	RateLimit      int64  `json:"rate_limit"`      // Requests per second, zero for unlimited
+}
+
+// Built-in plans
+var plans = map[string]Plan{
+	"free":       {Name: "free", StorageQuota: 5 << 30, BandwidthQuota: 50 << 30, RateLimit: 100},
+	"standard":   {Name: "standard", StorageQuota: 1 << 40, BandwidthQuota: 10 << 40, RateLimit: 1000},
+	"enterprise": {Name: "enterprise"},
+}
+
+// DefaultPlan is used when a spec names none
+const DefaultPlan = "standard"
+
+// Plans returns the built-in plans ordered by name
+func Plans() []Plan {
+	out := make([]Plan, 0, len(plans))
+	for _, p := range plans {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// TenantSpec is one manifest entry
+type TenantSpec struct {
+	Name    string   `json:"name"`
+	Plan    string   `json:"plan,omitempty"`
+	Regions []string `json:"regions,omitempty"` // Empty means any region
+}
+
+// ProvisionResult reports the outcome for one manifest entry
+type ProvisionResult struct {
+	Name     string `json:"name"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Plan     string `json:"plan,omitempty"`
+	Existing bool   `json:"existing,omitempty"` // Name was already provisioned
+	Error    string `json:"error,omitempty"`
+}
+
+// planStore holds the plan each tenant was provisioned on
+type planStore struct {
+	mu     sync.RWMutex
+	byID   map[string]string
+	byName map[string]string // Tenant name -> ID, for provisioned tenants
+}
+
+func newPlanStore() *planStore {
+	return &planStore{byID: make(map[string]string), byName: make(map[string]string)}
+}
+
+// TenantPlan returns the plan a tenant was provisioned on ("" if created without one)
+func (tm *V3TenantManager) TenantPlan(ctx context.Context, tenantID string) (string, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return "", err
+	}
+	tm.plans.mu.RLock()
+	defer tm.plans.mu.RUnlock()
+	return tm.plans.byID[tenantID], nil
+}
+
+// ProvisionTenant creates a tenant on spec's plan, restricted to spec's
+// regions. Names are unique among provisioned tenants: provisioning a name
+// again returns the existing tenant, so manifests can be re-applied.
+func (tm *V3TenantManager) ProvisionTenant(ctx context.Context, spec TenantSpec, validRegions []string) (ProvisionResult, error) {
+	res := ProvisionResult{Name: strings.TrimSpace(spec.Name), Plan: spec.Plan}
+	if res.Plan == "" {
+		res.Plan = DefaultPlan
+	}
+
+	if res.Name == "" {
+		return res, fmt.Errorf("tenant name is required")
+	}
+	if len(res.Name) > 255 {
+		return res, fmt.Errorf("tenant name longer than 255 bytes")
+	}
+	plan, ok := plans[res.Plan]
+	if !ok {
+		return res, fmt.Errorf("unknown plan: %s", res.Plan)
+	}
+	for _, r := range spec.Regions {
+		if !contains(validRegions, r) {
+			return res, fmt.Errorf("unknown region: %s", r)
+		}
+	}
+
+	// Hold the plan lock across creation so concurrent manifests can't
+	// provision the same name twice
+	tm.plans.mu.Lock()
+	defer tm.plans.mu.Unlock()
+
+	if id, exists := tm.plans.byName[res.Name]; exists {
+		res.TenantID, res.Plan, res.Existing = id, tm.plans.byID[id], true
+		return res, nil
+	}
+
+	tenantID, err := tm.CreateTenant(ctx, res.Name, plan.StorageQuota, plan.BandwidthQuota, plan.RateLimit)
+	if err != nil {
+		return res, err
+	}
+	if err := tm.SetQuotas(ctx, tenantID, QuotaLimits{IngressQuota: &plan.IngressQuota, EgressQuota: &plan.EgressQuota}); err != nil {
+		return res, err
+	}
+	tm.plans.byID[tenantID] = res.Plan
+	tm.plans.byName[res.Name] = tenantID
+	res.TenantID = tenantID
+
+	if len(spec.Regions) > 0 {
+		if err := tm.UpdateSettings(ctx, tenantID, TenantSettings{Regions: spec.Regions}); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ProvisionTenants provisions every spec in a manifest, reporting each
+// outcome in manifest order; one entry failing does not stop the rest
+func (tm *V3TenantManager) ProvisionTenants(ctx context.Context, specs []TenantSpec, validRegions []string) ([]ProvisionResult, error) {
+	if len(specs) > MaxProvisionBatch {
+		return nil, fmt.Errorf("manifest has %d tenants, limit is %d", len(specs), MaxProvisionBatch)
+	}
+
+	results := make([]ProvisionResult, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		name := strings.TrimSpace(spec.Name)
+		if seen[name] && name != "" {
+			results[i] = ProvisionResult{Name: name, Error: "duplicate name in manifest"}
+			continue
+		}
+		seen[name] = true
+
+		res, err := tm.ProvisionTenant(ctx, spec, validRegions)
+		if err != nil {
+			res.TenantID, res.Error = "", err.Error()
+		}
+		results[i] = res
+	}
+	return results, nil
+}
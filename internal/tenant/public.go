@@ -0,0 +1,185 @@
+// internal/tenant/public.go
+// Anonymous reads of public buckets: a token bucket per client address,
+// and signed referer tokens that tie a bucket's links to the site
+// embedding them
+package tenant
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const refererTokenPrefix = "mr1."
+
+// DefaultPublicRateLimit is the anonymous requests per second one client
+// address may make
+const DefaultPublicRateLimit = 10
+
+// maxPublicClients bounds the client addresses tracked at once
+const maxPublicClients = 1 << 16
+
+// Referer token errors returned by VerifyRefererToken
+var (
+	ErrRefererTokenInvalid = errors.New("invalid referer token")
+	ErrRefererTokenExpired = errors.New("referer token expired")
+	ErrRefererMismatch     = errors.New("referer not allowed by token")
+)
+
+// RefererClaims are the signed contents of a referer token
+type RefererClaims struct {
+	TenantID  string    `json:"tid"`
+	Bucket    string    `json:"bkt"`
+	Referer   string    `json:"ref"` // Host of the pages that may link the bucket
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// publicLimiter holds the token buckets of anonymous clients
+type publicLimiter struct {
+	limit atomic.Int64 // Requests per second; 0 is unlimited
+
+	mu      sync.Mutex
+	clients map[string]*tokenBucket
+}
+
+func newPublicLimiter() *publicLimiter {
+	l := &publicLimiter{clients: make(map[string]*tokenBucket)}
+	l.limit.Store(DefaultPublicRateLimit)
+	return l
+}
+
+// SetPublicRateLimit sets the anonymous requests per second each client
+// address may make, bursting as tenants do; 0 disables the limit
+func (tm *V3TenantManager) SetPublicRateLimit(limit int64) {
+	tm.publicClients.limit.Store(max(limit, 0))
+}
+
+// AllowPublicRequest takes a token from client's bucket, returning
+// ErrRateLimited if it is empty. client is normally the caller's IP address.
+func (tm *V3TenantManager) AllowPublicRequest(client string) (RateStatus, error) {
+	l := tm.publicClients
+	limit := l.limit.Load()
+	if limit <= 0 {
+		return RateStatus{}, nil
+	}
+	burst, now := tm.burst(), time.Now().UnixNano()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.clients[client]
+	if b == nil {
+		if len(l.clients) >= maxPublicClients {
+			l.prune(limit, burst, now)
+		}
+		b = &tokenBucket{}
+		l.clients[client] = b
+	}
+	status, ok := b.take(limit, burst, now)
+	if !ok {
+		return status, ErrRateLimited
+	}
+	return status, nil
+}
+
+// prune forgets clients whose buckets have refilled, which a new bucket
+// would behave the same as. If too many are still active, arbitrary ones
+// are forgotten too, granting them a fresh bucket. l.mu must be held.
+func (l *publicLimiter) prune(limit int64, burst float64, now int64) {
+	capacity := float64(limit) * burst
+	for client, b := range l.clients {
+		if b.refill(limit, burst, now); b.tokens >= capacity {
+			delete(l.clients, client)
+		}
+	}
+	for client := range l.clients {
+		if len(l.clients) < maxPublicClients*3/4 {
+			break
+		}
+		delete(l.clients, client)
+	}
+}
+
+// refererHost returns the lowercase host name of a Referer header or a
+// bare host, without a port
+func refererHost(referer string) string {
+	if u, err := url.Parse(referer); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	host := referer
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// IssueRefererToken creates a token that lets pages on referer's host link
+// the objects of tenantID's public bucket for expiresIn. referer is a host
+// name or a URL.
+func (tm *V3TenantManager) IssueRefererToken(ctx context.Context, tenantID, bucket, referer string, expiresIn time.Duration) (string, *RefererClaims, error) {
+	b, err := tm.GetBucket(ctx, tenantID, bucket)
+	if err != nil {
+		return "", nil, err
+	}
+	if !b.Config.PublicRead {
+		return "", nil, fmt.Errorf("bucket %q is not public", bucket)
+	}
+	host := refererHost(referer)
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		return "", nil, fmt.Errorf("referer %q is not a host name or URL", referer)
+	}
+	if expiresIn <= 0 {
+		return "", nil, fmt.Errorf("token lifetime must be positive")
+	}
+
+	rc := &RefererClaims{TenantID: tenantID, Bucket: bucket, Referer: host, ExpiresAt: time.Now().UTC().Add(expiresIn)}
+	claims, err := json.Marshal(rc)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create token: %w", err)
+	}
+	payload := refererTokenPrefix + base64.RawURLEncoding.EncodeToString(claims)
+	return payload + "." + tm.sign(payload), rc, nil
+}
+
+// VerifyRefererToken checks a token's signature and expiry, that it was
+// issued for tenantID's bucket, and that referer, a Referer header, is on
+// the host it names
+func (tm *V3TenantManager) VerifyRefererToken(ctx context.Context, token, tenantID, bucket, referer string) (*RefererClaims, error) {
+	if !strings.HasPrefix(token, refererTokenPrefix) {
+		return nil, ErrRefererTokenInvalid
+	}
+	i := strings.LastIndexByte(token, '.')
+	if i <= len(refererTokenPrefix) {
+		return nil, ErrRefererTokenInvalid
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(tm.sign(payload))) {
+		return nil, ErrRefererTokenInvalid
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload[len(refererTokenPrefix):])
+	if err != nil {
+		return nil, ErrRefererTokenInvalid
+	}
+	var claims RefererClaims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, ErrRefererTokenInvalid
+	}
+	if claims.TenantID != tenantID || claims.Bucket != bucket {
+		return nil, ErrRefererTokenInvalid
+	}
+	if time.Now().After(claims.ExpiresAt) {
+		return nil, ErrRefererTokenExpired
+	}
+	if referer == "" || refererHost(referer) != claims.Referer {
+		return nil, ErrRefererMismatch
+	}
+	return &claims, nil
+}
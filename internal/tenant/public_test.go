@@ -0,0 +1,102 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllowPublicRequestPerClient(t *testing.T) {
+	tm, _ := newTestTenant(t, 0, 0, 0)
+	tm.SetPublicRateLimit(5)
+	tm.SetRateBurst(2)
+
+	for i := 0; i < 10; i++ {
+		if _, err := tm.AllowPublicRequest("192.0.2.1"); err != nil {
+			t.Fatalf("Request %d rejected: %v", i, err)
+		}
+	}
+	status, err := tm.AllowPublicRequest("192.0.2.1")
+	if !errors.Is(err, ErrRateLimited) || status.RetryAfter <= 0 {
+		t.Errorf("AllowPublicRequest() past the burst = %+v, %v", status, err)
+	}
+	// Other clients have their own buckets
+	if _, err := tm.AllowPublicRequest("192.0.2.2"); err != nil {
+		t.Errorf("Second client rejected: %v", err)
+	}
+
+	tm.SetPublicRateLimit(0)
+	if _, err := tm.AllowPublicRequest("192.0.2.1"); err != nil {
+		t.Errorf("Unlimited request rejected: %v", err)
+	}
+}
+
+func TestPublicLimiterPrune(t *testing.T) {
+	l := newPublicLimiter()
+	now := time.Now().UnixNano()
+	for i := 0; i < maxPublicClients; i++ {
+		b := &tokenBucket{}
+		b.take(10, 1, now)
+		l.clients[strconv.Itoa(i)] = b
+	}
+	l.prune(10, 1, now)
+	if n := len(l.clients); n >= maxPublicClients*3/4 {
+		t.Errorf("%d clients after pruning active buckets", n)
+	}
+	l.prune(10, 1, now+int64(time.Second))
+	if n := len(l.clients); n != 0 {
+		t.Errorf("%d clients after pruning refilled buckets", n)
+	}
+}
+
+func TestRefererToken(t *testing.T) {
+	tm, id := newTestTenant(t, 0, 0, 0)
+	ctx := context.Background()
+	tm.CreateBucket(ctx, id, "private", BucketConfig{})
+	tm.CreateBucket(ctx, id, "assets", BucketConfig{PublicRead: true, RefererTokens: true})
+
+	if _, _, err := tm.IssueRefererToken(ctx, id, "private", "example.com", time.Hour); err == nil {
+		t.Error("Issued a referer token for a private bucket")
+	}
+	if _, _, err := tm.IssueRefererToken(ctx, id, "assets", "", time.Hour); err == nil {
+		t.Error("Issued a referer token without a referer")
+	}
+	token, claims, err := tm.IssueRefererToken(ctx, id, "assets", "https://Shop.Example.com/", time.Hour)
+	if err != nil || claims.Referer != "shop.example.com" || !strings.HasPrefix(token, refererTokenPrefix) {
+		t.Fatalf("IssueRefererToken() = %q, %+v, %v", token, claims, err)
+	}
+
+	for _, tc := range []struct {
+		token, tenantID, bucket, referer string
+		want                             error
+	}{
+		{token, id, "assets", "https://shop.example.com:8443/products/1", nil},
+		{token, id, "assets", "https://evil.example.net/", ErrRefererMismatch},
+		{token, id, "assets", "", ErrRefererMismatch},
+		{token, id, "other", "https://shop.example.com/", ErrRefererTokenInvalid},
+		{token, "tenant-other", "assets", "https://shop.example.com/", ErrRefererTokenInvalid},
+		{token + "x", id, "assets", "https://shop.example.com/", ErrRefererTokenInvalid},
+		{"mt1.abc.def", id, "assets", "https://shop.example.com/", ErrRefererTokenInvalid},
+	} {
+		if _, err := tm.VerifyRefererToken(ctx, tc.token, tc.tenantID, tc.bucket, tc.referer); !errors.Is(err, tc.want) {
+			t.Errorf("VerifyRefererToken(%s, %s, %s) = %v, want %v", tc.tenantID, tc.bucket, tc.referer, err, tc.want)
+		}
+	}
+
+	expired, _, _ := tm.IssueRefererToken(ctx, id, "assets", "shop.example.com", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, err := tm.VerifyRefererToken(ctx, expired, id, "assets", "https://shop.example.com/"); !errors.Is(err, ErrRefererTokenExpired) {
+		t.Errorf("Expired token = %v, want ErrRefererTokenExpired", err)
+	}
+
+	// Referer tokens are not tenant tokens
+	if _, err := tm.VerifyTenantToken(ctx, token); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("VerifyTenantToken(referer token) = %v", err)
+	}
+	if _, err := tm.CreateBucket(ctx, id, "hotlinks", BucketConfig{RefererTokens: true}); err == nil {
+		t.Error("Accepted referer_tokens without public_read")
+	}
+}
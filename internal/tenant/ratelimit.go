@@ -0,0 +1,117 @@
+// internal/tenant/ratelimit.go
+// Request rate limiting: token buckets per tenant and per service account,
+// refilled at their rate limit and holding up to it times the burst
+// multiplier
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRateBurst lets a tenant burst to one second's worth of requests
+const DefaultRateBurst = 1.0
+
+// ErrRateLimited is returned by AllowRequest when the tenant has no tokens
+var ErrRateLimited = errors.New("request rate limit exceeded")
+
+// RateStatus is a bucket after a request. Limit is zero for a tenant or
+// account without a rate limit.
+type RateStatus struct {
+	Limit      int64         // Requests per second
+	Burst      int64         // Bucket capacity
+	Remaining  int64         // Whole tokens left
+	RetryAfter time.Duration // Until the next token, if none remain
+	Reset      time.Duration // Until the bucket is full
+}
+
+// SetRateBurst sets bucket capacity as a multiple of each tenant's
+// RateLimit. Values below 1 are raised to 1.
+func (tm *V3TenantManager) SetRateBurst(multiplier float64) {
+	tm.rateBurst.Store(math.Float64bits(max(multiplier, 1)))
+}
+
+// AllowRequest takes a token from tenantID's bucket, returning
+// ErrRateLimited if it is empty
+func (tm *V3TenantManager) AllowRequest(ctx context.Context, tenantID string) (RateStatus, error) {
+	shard := tm.shards[tm.fastHash(tenantID)&tm.shardMask]
+	config := tm.getFromShard(shard, tenantID)
+	usage := tm.getUsageFromShard(shard, tenantID)
+	if config == nil || usage == nil {
+		return RateStatus{}, fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	limit := config.RateLimit.Load()
+	if limit <= 0 {
+		return RateStatus{}, nil
+	}
+
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	status, ok := usage.bucket.take(limit, tm.burst(), time.Now().UnixNano())
+	if !ok {
+		tm.stats.RateLimited.Add(1)
+		return status, ErrRateLimited
+	}
+	return status, nil
+}
+
+// RateTokens reports tenantID's bucket without taking a token
+func (tm *V3TenantManager) RateTokens(ctx context.Context, tenantID string) (RateStatus, error) {
+	shard := tm.shards[tm.fastHash(tenantID)&tm.shardMask]
+	config := tm.getFromShard(shard, tenantID)
+	usage := tm.getUsageFromShard(shard, tenantID)
+	if config == nil || usage == nil {
+		return RateStatus{}, fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	limit := config.RateLimit.Load()
+	if limit <= 0 {
+		return RateStatus{}, nil
+	}
+
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	return usage.bucket.refill(limit, tm.burst(), time.Now().UnixNano()), nil
+}
+
+func (tm *V3TenantManager) burst() float64 {
+	return math.Float64frombits(tm.rateBurst.Load())
+}
+
+// tokenBucket is a rate limit's state; its owner serializes access
+type tokenBucket struct {
+	tokens     float64 // As of refilledAt
+	refilledAt int64   // Unix nano, 0 before the first request
+}
+
+// take refills the bucket and takes a token, reporting false if it had none
+func (b *tokenBucket) take(limit int64, burst float64, now int64) (RateStatus, bool) {
+	status := b.refill(limit, burst, now)
+	if b.tokens < 1 {
+		status.RetryAfter = time.Duration((1 - b.tokens) / float64(limit) * float64(time.Second))
+		return status, false
+	}
+	b.tokens--
+	status.Remaining = int64(b.tokens)
+	status.Reset += time.Second / time.Duration(limit)
+	return status, true
+}
+
+// refill adds the tokens earned since the last refill. A bucket starts full.
+func (b *tokenBucket) refill(limit int64, burst float64, now int64) RateStatus {
+	capacity := float64(limit) * burst
+	if b.refilledAt == 0 {
+		b.tokens = capacity
+	} else if elapsed := now - b.refilledAt; elapsed > 0 {
+		b.tokens = min(capacity, b.tokens+float64(elapsed)*float64(limit)/float64(time.Second))
+	}
+	b.refilledAt = max(b.refilledAt, now)
+	return RateStatus{
+		Limit:     limit,
+		Burst:     int64(capacity),
+		Remaining: int64(b.tokens),
+		Reset:     time.Duration((capacity - b.tokens) / float64(limit) * float64(time.Second)),
+	}
+}
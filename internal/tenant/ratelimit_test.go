@@ -0,0 +1,114 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Racing requests take no more tokens than the bucket holds, and a
+// rejected request is told when the next token arrives
+func TestAllowRequestTokenBucket(t *testing.T) {
+	const limit, burst = 10, 3
+	tm, id := newTestTenant(t, 0, 0, limit)
+	tm.SetRateBurst(burst)
+	ctx := context.Background()
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 40; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				status, err := tm.AllowRequest(ctx, id)
+				switch {
+				case err == nil:
+					allowed.Add(1)
+				case !errors.Is(err, ErrRateLimited):
+					t.Errorf("Unexpected error: %v", err)
+				case status.RetryAfter <= 0 || status.RetryAfter > time.Second/limit:
+					t.Errorf("RetryAfter %v, want up to %v", status.RetryAfter, time.Second/limit)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A full bucket, plus what refilled while the requests ran
+	if n := allowed.Load(); n < limit*burst || n > limit*burst+2 {
+		t.Errorf("Allowed %d requests, want %d", n, limit*burst)
+	}
+	status, err := tm.RateTokens(ctx, id)
+	if err != nil || status.Limit != limit || status.Burst != limit*burst || status.Remaining > 1 {
+		t.Errorf("RateTokens() = %+v, %v", status, err)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if status, _ := tm.RateTokens(ctx, id); status.Remaining < 2 {
+		t.Errorf("Expected the bucket to refill, %d tokens remain", status.Remaining)
+	}
+
+	// Tenants without a rate limit are never limited
+	tm2, unlimited := newTestTenant(t, 0, 0, 0)
+	for i := 0; i < 1000; i++ {
+		if _, err := tm2.AllowRequest(ctx, unlimited); err != nil {
+			t.Fatalf("Unlimited tenant rejected: %v", err)
+		}
+	}
+}
+
+// An account over its own limit is throttled while the tenant's other
+// accounts keep working
+func TestAllowAccountRequestIsolatesAccounts(t *testing.T) {
+	tm, id := newTestTenant(t, 0, 0, 0)
+	ctx := context.Background()
+
+	noisy, _, err := tm.CreateServiceAccount(ctx, id, ServiceAccountOptions{Name: "batch", Permissions: []string{"read"}, RateLimit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quiet, _, err := tm.CreateServiceAccount(ctx, id, ServiceAccountOptions{Name: "web", Permissions: []string{"read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var allowed int
+	for i := 0; i < 20; i++ {
+		if _, err := tm.AllowAccountRequest(ctx, noisy.ID); err == nil {
+			allowed++
+		} else if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := tm.AllowAccountRequest(ctx, quiet.ID); err != nil {
+			t.Fatalf("Unlimited account refused: %v", err)
+		}
+	}
+	if allowed < 5 || allowed > 6 {
+		t.Errorf("Allowed %d of the limited account's requests, want 5", allowed)
+	}
+
+	accounts := tm.ListServiceAccounts(ctx, id)
+	for _, acct := range accounts {
+		if acct.Requests != 20 {
+			t.Errorf("%s: %d requests counted, want 20", acct.Name, acct.Requests)
+		}
+		if acct.ID == noisy.ID && acct.RateLimited != uint64(20-allowed) {
+			t.Errorf("%s: %d rate limited, want %d", acct.Name, acct.RateLimited, 20-allowed)
+		}
+	}
+
+	// Lifting the limit takes effect immediately
+	if _, err := tm.SetServiceAccountRateLimit(ctx, "", noisy.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.AllowAccountRequest(ctx, noisy.ID); err != nil {
+		t.Errorf("Request refused after lifting the limit: %v", err)
+	}
+	if _, err := tm.SetServiceAccountRateLimit(ctx, "other-tenant", noisy.ID, 1); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Errorf("Expected another tenant to be refused, got %v", err)
+	}
+}
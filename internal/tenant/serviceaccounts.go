@@ -0,0 +1,411 @@
+// internal/tenant/serviceaccounts.go
+// Tenant service accounts with scoped, automatically rotating access keys.
+// After a rotation the previous key keeps working for an overlap window so
+// CI systems can pick up the new key without downtime.
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultRotationPeriod = 24 * time.Hour
+	DefaultOverlapWindow  = time.Hour
+
+	// MinRotationPeriod bounds how often keys may rotate
+	MinRotationPeriod = 5 * time.Minute
+)
+
+// Rotation reasons
+const (
+	RotationScheduled = "scheduled"
+	RotationManual    = "manual"
+)
+
+// Service account errors returned by VerifyServiceAccountCredential
+var (
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrCredentialInvalid      = errors.New("invalid service account credential")
+	ErrCredentialExpired      = errors.New("service account credential expired")
+)
+
+// ServiceAccountOptions configure a new service account
+type ServiceAccountOptions struct {
+	Name           string
+	Permissions    []string
+	RotationPeriod time.Duration // Zero uses DefaultRotationPeriod
+	OverlapWindow  time.Duration // Zero uses DefaultOverlapWindow
+	RateLimit      int64         // Requests per second, zero for only the tenant's limit
+}
+
+// ServiceAccount is the public view of an account (no secrets)
+type ServiceAccount struct {
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	Name           string    `json:"name"`
+	Permissions    []string  `json:"permissions"`
+	RotationPeriod string    `json:"rotation_period"`
+	OverlapWindow  string    `json:"overlap_window"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastRotated    time.Time `json:"last_rotated"`
+	NextRotation   time.Time `json:"next_rotation"`
+	Rotations      int64     `json:"rotations"`
+	AccessKeys     []string  `json:"access_keys"` // Current key first
+	RateLimit      int64     `json:"rate_limit,omitempty"`
+	Requests       uint64    `json:"requests"`     // Made with the account's keys or tokens
+	RateLimited    uint64    `json:"rate_limited"` // Of those, refused by its rate limit
+}
+
+// ServiceAccountCredentials is one access key pair
+type ServiceAccountCredentials struct {
+	AccessKey string    `json:"access_key"`
+	SecretKey string    `json:"secret_key"`
+	IssuedAt  time.Time `json:"issued_at"`
+	// ExpiresAt is when the key stops working: the next rotation plus the overlap window
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CredentialRotation records one key rotation
+type CredentialRotation struct {
+	AccountID    string    `json:"account_id"`
+	TenantID     string    `json:"tenant_id"`
+	Reason       string    `json:"reason"`
+	OldAccessKey string    `json:"old_access_key"`
+	NewAccessKey string    `json:"new_access_key"`
+	OldExpiresAt time.Time `json:"old_expires_at"`
+}
+
+type serviceAccount struct {
+	id, tenantID, name string
+	permissions        []string
+	period, overlap    time.Duration
+	createdAt          time.Time
+	lastRotated        time.Time
+	rotations          int64
+
+	current  *ServiceAccountCredentials
+	previous *ServiceAccountCredentials // Valid until its ExpiresAt
+
+	rateLimit   atomic.Int64
+	rateMu      sync.Mutex
+	bucket      tokenBucket
+	requests    atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+func (a *serviceAccount) view() ServiceAccount {
+	v := ServiceAccount{
+		ID:             a.id,
+		TenantID:       a.tenantID,
+		Name:           a.name,
+		Permissions:    append([]string(nil), a.permissions...),
+		RotationPeriod: a.period.String(),
+		OverlapWindow:  a.overlap.String(),
+		CreatedAt:      a.createdAt,
+		LastRotated:    a.lastRotated,
+		NextRotation:   a.lastRotated.Add(a.period),
+		Rotations:      a.rotations,
+		AccessKeys:     []string{a.current.AccessKey},
+		RateLimit:      a.rateLimit.Load(),
+		Requests:       a.requests.Load(),
+		RateLimited:    a.rateLimited.Load(),
+	}
+	if a.previous != nil && time.Now().Before(a.previous.ExpiresAt) {
+		v.AccessKeys = append(v.AccessKeys, a.previous.AccessKey)
+	}
+	return v
+}
+
+// serviceAccountStore indexes accounts by ID and by access key
+type serviceAccountStore struct {
+	mu          sync.RWMutex
+	byID        map[string]*serviceAccount
+	byAccessKey map[string]*serviceAccount
+}
+
+func newServiceAccountStore() *serviceAccountStore {
+	return &serviceAccountStore{
+		byID:        make(map[string]*serviceAccount),
+		byAccessKey: make(map[string]*serviceAccount),
+	}
+}
+
+func randomToken(n int, enc func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return enc(b), nil
+}
+
+// newCredentials generates a key pair valid for period plus overlap
+func newCredentials(now time.Time, period, overlap time.Duration) (*ServiceAccountCredentials, error) {
+	access, err := randomToken(10, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access key: %w", err)
+	}
+	secret, err := randomToken(30, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	return &ServiceAccountCredentials{
+		AccessKey: "SA" + access,
+		SecretKey: secret,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(period + overlap),
+	}, nil
+}
+
+// CreateServiceAccount creates an account and returns its first credentials
+func (tm *V3TenantManager) CreateServiceAccount(ctx context.Context, tenantID string, opts ServiceAccountOptions) (*ServiceAccount, *ServiceAccountCredentials, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return nil, nil, err
+	}
+	if opts.Name == "" {
+		return nil, nil, fmt.Errorf("service account name is required")
+	}
+	if len(opts.Permissions) == 0 {
+		return nil, nil, fmt.Errorf("service account needs at least one permission")
+	}
+	if opts.RotationPeriod == 0 {
+		opts.RotationPeriod = DefaultRotationPeriod
+	}
+	if opts.OverlapWindow == 0 {
+		opts.OverlapWindow = DefaultOverlapWindow
+	}
+	if opts.RotationPeriod < MinRotationPeriod {
+		return nil, nil, fmt.Errorf("rotation period must be at least %s", MinRotationPeriod)
+	}
+	if opts.OverlapWindow < 0 || opts.OverlapWindow >= opts.RotationPeriod {
+		return nil, nil, fmt.Errorf("overlap window must be shorter than the rotation period")
+	}
+	if opts.RateLimit < 0 {
+		return nil, nil, fmt.Errorf("rate limit must not be negative")
+	}
+
+	id, err := randomToken(8, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate account ID: %w", err)
+	}
+	now := time.Now().UTC()
+	creds, err := newCredentials(now, opts.RotationPeriod, opts.OverlapWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	acct := &serviceAccount{
+		id:          "sa-" + id,
+		tenantID:    tenantID,
+		name:        opts.Name,
+		permissions: append([]string(nil), opts.Permissions...),
+		period:      opts.RotationPeriod,
+		overlap:     opts.OverlapWindow,
+		createdAt:   now,
+		lastRotated: now,
+		current:     creds,
+	}
+	acct.rateLimit.Store(opts.RateLimit)
+
+	tm.serviceAccounts.mu.Lock()
+	tm.serviceAccounts.byID[acct.id] = acct
+	tm.serviceAccounts.byAccessKey[creds.AccessKey] = acct
+	v := acct.view()
+	tm.serviceAccounts.mu.Unlock()
+
+	cp := *creds
+	return &v, &cp, nil
+}
+
+// rotateLocked replaces the current key; the caller holds the store lock
+func (tm *V3TenantManager) rotateLocked(acct *serviceAccount, now time.Time, reason string) (*CredentialRotation, error) {
+	creds, err := newCredentials(now, acct.period, acct.overlap)
+	if err != nil {
+		return nil, err
+	}
+
+	if acct.previous != nil {
+		delete(tm.serviceAccounts.byAccessKey, acct.previous.AccessKey)
+	}
+	old := *acct.current
+	old.ExpiresAt = now.Add(acct.overlap)
+	acct.previous = &old
+	acct.current = creds
+	acct.lastRotated = now
+	acct.rotations++
+	tm.serviceAccounts.byAccessKey[creds.AccessKey] = acct
+
+	return &CredentialRotation{
+		AccountID:    acct.id,
+		TenantID:     acct.tenantID,
+		Reason:       reason,
+		OldAccessKey: old.AccessKey,
+		NewAccessKey: creds.AccessKey,
+		OldExpiresAt: old.ExpiresAt,
+	}, nil
+}
+
+// RotateServiceAccount rotates an account's key immediately
+func (tm *V3TenantManager) RotateServiceAccount(ctx context.Context, accountID string) (*CredentialRotation, error) {
+	tm.serviceAccounts.mu.Lock()
+	defer tm.serviceAccounts.mu.Unlock()
+
+	acct, exists := tm.serviceAccounts.byID[accountID]
+	if !exists {
+		return nil, ErrServiceAccountNotFound
+	}
+	return tm.rotateLocked(acct, time.Now().UTC(), RotationManual)
+}
+
+// RotateDueServiceAccounts rotates every account whose period has elapsed
+// and forgets keys whose overlap window has closed
+func (tm *V3TenantManager) RotateDueServiceAccounts(ctx context.Context) ([]CredentialRotation, error) {
+	now := time.Now().UTC()
+
+	tm.serviceAccounts.mu.Lock()
+	defer tm.serviceAccounts.mu.Unlock()
+
+	var rotations []CredentialRotation
+	var errs []error
+	for _, acct := range tm.serviceAccounts.byID {
+		if acct.previous != nil && !now.Before(acct.previous.ExpiresAt) {
+			delete(tm.serviceAccounts.byAccessKey, acct.previous.AccessKey)
+			acct.previous = nil
+		}
+		if now.Before(acct.lastRotated.Add(acct.period)) {
+			continue
+		}
+		rotation, err := tm.rotateLocked(acct, now, RotationScheduled)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", acct.id, err))
+			continue
+		}
+		rotations = append(rotations, *rotation)
+	}
+	return rotations, errors.Join(errs...)
+}
+
+// VerifyServiceAccountCredential checks a key pair, accepting the previous
+// key until its overlap window closes
+func (tm *V3TenantManager) VerifyServiceAccountCredential(ctx context.Context, accessKey, secretKey string) (*ServiceAccount, error) {
+	tm.serviceAccounts.mu.RLock()
+	defer tm.serviceAccounts.mu.RUnlock()
+
+	acct, exists := tm.serviceAccounts.byAccessKey[accessKey]
+	if !exists {
+		return nil, ErrCredentialInvalid
+	}
+
+	creds := acct.current
+	if acct.previous != nil && acct.previous.AccessKey == accessKey {
+		creds = acct.previous
+	}
+	if subtle.ConstantTimeCompare([]byte(secretKey), []byte(creds.SecretKey)) != 1 {
+		return nil, ErrCredentialInvalid
+	}
+	if !time.Now().Before(creds.ExpiresAt) {
+		return nil, ErrCredentialExpired
+	}
+
+	v := acct.view()
+	return &v, nil
+}
+
+// ServiceAccountCredentialsFor returns an account's current key pair
+func (tm *V3TenantManager) ServiceAccountCredentialsFor(ctx context.Context, accountID string) (*ServiceAccountCredentials, error) {
+	tm.serviceAccounts.mu.RLock()
+	defer tm.serviceAccounts.mu.RUnlock()
+
+	acct, exists := tm.serviceAccounts.byID[accountID]
+	if !exists {
+		return nil, ErrServiceAccountNotFound
+	}
+	cp := *acct.current
+	return &cp, nil
+}
+
+// DeleteServiceAccount removes an account and invalidates all its keys;
+// tenantID must own it unless empty (admin)
+func (tm *V3TenantManager) DeleteServiceAccount(ctx context.Context, tenantID, accountID string) error {
+	tm.serviceAccounts.mu.Lock()
+	defer tm.serviceAccounts.mu.Unlock()
+
+	acct, exists := tm.serviceAccounts.byID[accountID]
+	if !exists || (tenantID != "" && acct.tenantID != tenantID) {
+		return ErrServiceAccountNotFound
+	}
+	delete(tm.serviceAccounts.byID, accountID)
+	delete(tm.serviceAccounts.byAccessKey, acct.current.AccessKey)
+	if acct.previous != nil {
+		delete(tm.serviceAccounts.byAccessKey, acct.previous.AccessKey)
+	}
+	return nil
+}
+
+// ListServiceAccounts returns accounts owned by tenantID (all accounts if empty)
+func (tm *V3TenantManager) ListServiceAccounts(ctx context.Context, tenantID string) []ServiceAccount {
+	tm.serviceAccounts.mu.RLock()
+	out := make([]ServiceAccount, 0)
+	for _, acct := range tm.serviceAccounts.byID {
+		if tenantID == "" || acct.tenantID == tenantID {
+			out = append(out, acct.view())
+		}
+	}
+	tm.serviceAccounts.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// SetServiceAccountRateLimit changes an account's requests per second (zero
+// for only the tenant's limit); tenantID must own it unless empty (admin)
+func (tm *V3TenantManager) SetServiceAccountRateLimit(ctx context.Context, tenantID, accountID string, limit int64) (*ServiceAccount, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("rate limit must not be negative")
+	}
+	tm.serviceAccounts.mu.RLock()
+	defer tm.serviceAccounts.mu.RUnlock()
+
+	acct, exists := tm.serviceAccounts.byID[accountID]
+	if !exists || (tenantID != "" && acct.tenantID != tenantID) {
+		return nil, ErrServiceAccountNotFound
+	}
+	acct.rateLimit.Store(limit)
+	v := acct.view()
+	return &v, nil
+}
+
+// AllowAccountRequest counts a request made as accountID and takes a token
+// from its bucket, returning ErrRateLimited if it is empty
+func (tm *V3TenantManager) AllowAccountRequest(ctx context.Context, accountID string) (RateStatus, error) {
+	tm.serviceAccounts.mu.RLock()
+	acct, exists := tm.serviceAccounts.byID[accountID]
+	tm.serviceAccounts.mu.RUnlock()
+	if !exists {
+		return RateStatus{}, ErrServiceAccountNotFound
+	}
+
+	acct.requests.Add(1)
+	limit := acct.rateLimit.Load()
+	if limit <= 0 {
+		return RateStatus{}, nil
+	}
+	acct.rateMu.Lock()
+	defer acct.rateMu.Unlock()
+	status, ok := acct.bucket.take(limit, tm.burst(), time.Now().UnixNano())
+	if !ok {
+		acct.rateLimited.Add(1)
+		tm.stats.RateLimited.Add(1)
+		return status, ErrRateLimited
+	}
+	return status, nil
+}
@@ -0,0 +1,217 @@
+// internal/tenant/sharelinks.go
+// Managed share links: server-stored, revocable download grants for a single object
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Share link errors returned by RedeemShareLink
+var (
+	ErrShareLinkNotFound  = errors.New("share link not found")
+	ErrShareLinkExpired   = errors.New("share link expired")
+	ErrShareLinkExhausted = errors.New("share link download limit reached")
+	ErrShareLinkPassword  = errors.New("share link password required or incorrect")
+)
+
+// ShareLinkOptions restrict how a share link may be used
+type ShareLinkOptions struct {
+	ExpiresAt    time.Time // Zero means no expiry
+	MaxDownloads int64     // Zero means unlimited
+	Password     string    // Empty means no password
+	CreatedBy    string    // Principal creating the link, who alone may list and revoke it
+}
+
+// ShareLink is the public view of a stored link
+type ShareLink struct {
+	ID           string    `json:"id"` // Names the link in audit events, which never record its token
+	Token        string    `json:"token"`
+	TenantID     string    `json:"tenant_id"`
+	Key          string    `json:"key"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	MaxDownloads int64     `json:"max_downloads,omitempty"`
+	Downloads    int64     `json:"downloads"`
+	Protected    bool      `json:"password_protected"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+}
+
+type shareLink struct {
+	ShareLink
+	salt         []byte
+	passwordHash []byte
+	downloads    atomic.Int64
+}
+
+func (l *shareLink) view() ShareLink {
+	v := l.ShareLink
+	v.Downloads = l.downloads.Load()
+	return v
+}
+
+// shareLinkStore indexes links by token
+type shareLinkStore struct {
+	mu      sync.RWMutex
+	byToken map[string]*shareLink
+}
+
+func newShareLinkStore() *shareLinkStore {
+	return &shareLinkStore{byToken: make(map[string]*shareLink)}
+}
+
+func hashSharePassword(salt []byte, password string) []byte {
+	sum := sha256.Sum256(append(append([]byte(nil), salt...), password...))
+	return sum[:]
+}
+
+// ShareTokenID derives a link's ID from its token: a truncated SHA-256,
+// which identifies the link without granting its download
+func ShareTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// CreateShareLink creates a link granting download of tenantID/key
+func (tm *V3TenantManager) CreateShareLink(ctx context.Context, tenantID, key string, opts ShareLinkOptions) (*ShareLink, error) {
+	if _, err := tm.GetTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("object key is required")
+	}
+	if opts.MaxDownloads < 0 {
+		return nil, fmt.Errorf("max downloads must not be negative")
+	}
+
+	tokenBytes := make([]byte, 24)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	token := hex.EncodeToString(tokenBytes)
+	link := &shareLink{ShareLink: ShareLink{
+		ID:           ShareTokenID(token),
+		Token:        token,
+		TenantID:     tenantID,
+		Key:          key,
+		CreatedAt:    time.Now().UTC(),
+		ExpiresAt:    opts.ExpiresAt,
+		MaxDownloads: opts.MaxDownloads,
+		Protected:    opts.Password != "",
+		CreatedBy:    opts.CreatedBy,
+	}}
+	if opts.Password != "" {
+		link.salt = make([]byte, 16)
+		if _, err := rand.Read(link.salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		link.passwordHash = hashSharePassword(link.salt, opts.Password)
+	}
+
+	tm.shareLinks.mu.Lock()
+	tm.shareLinks.byToken[link.Token] = link
+	tm.shareLinks.mu.Unlock()
+
+	v := link.view()
+	return &v, nil
+}
+
+// RedeemShareLink validates a link and counts one download against it
+func (tm *V3TenantManager) RedeemShareLink(ctx context.Context, token, password string) (*ShareLink, error) {
+	tm.shareLinks.mu.RLock()
+	link, exists := tm.shareLinks.byToken[token]
+	tm.shareLinks.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrShareLinkNotFound
+	}
+	if !link.ExpiresAt.IsZero() && time.Now().After(link.ExpiresAt) {
+		return nil, ErrShareLinkExpired
+	}
+	if link.passwordHash != nil &&
+		subtle.ConstantTimeCompare(hashSharePassword(link.salt, password), link.passwordHash) != 1 {
+		return nil, ErrShareLinkPassword
+	}
+
+	// Reserve a download slot without a lock
+	for {
+		n := link.downloads.Load()
+		if link.MaxDownloads > 0 && n >= link.MaxDownloads {
+			return nil, ErrShareLinkExhausted
+		}
+		if link.downloads.CompareAndSwap(n, n+1) {
+			break
+		}
+	}
+
+	v := link.view()
+	return &v, nil
+}
+
+// GetShareLink returns a link without counting a download against it
+func (tm *V3TenantManager) GetShareLink(ctx context.Context, token string) (*ShareLink, error) {
+	tm.shareLinks.mu.RLock()
+	link, exists := tm.shareLinks.byToken[token]
+	tm.shareLinks.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrShareLinkNotFound
+	}
+	v := link.view()
+	return &v, nil
+}
+
+// ReleaseShareLink returns a download slot reserved by a redeem that failed to serve
+func (tm *V3TenantManager) ReleaseShareLink(ctx context.Context, token string) {
+	tm.shareLinks.mu.RLock()
+	link, exists := tm.shareLinks.byToken[token]
+	tm.shareLinks.mu.RUnlock()
+
+	if exists {
+		link.downloads.Add(-1)
+	}
+}
+
+// RevokeShareLink deletes a link; tenantID must own it unless empty (admin)
+func (tm *V3TenantManager) RevokeShareLink(ctx context.Context, tenantID, token string) error {
+	tm.shareLinks.mu.Lock()
+	defer tm.shareLinks.mu.Unlock()
+
+	link, exists := tm.shareLinks.byToken[token]
+	if !exists || (tenantID != "" && link.TenantID != tenantID) {
+		return ErrShareLinkNotFound
+	}
+	delete(tm.shareLinks.byToken, token)
+	return nil
+}
+
+// ListShareLinks returns links owned by tenantID (all links if empty), dropping expired ones
+func (tm *V3TenantManager) ListShareLinks(ctx context.Context, tenantID string) []ShareLink {
+	now := time.Now()
+
+	tm.shareLinks.mu.Lock()
+	out := make([]ShareLink, 0)
+	for token, link := range tm.shareLinks.byToken {
+		if !link.ExpiresAt.IsZero() && now.After(link.ExpiresAt) {
+			delete(tm.shareLinks.byToken, token)
+			continue
+		}
+		if tenantID == "" || link.TenantID == tenantID {
+			out = append(out, link.view())
+		}
+	}
+	tm.shareLinks.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
@@ -6,6 +6,7 @@ package tenant
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"runtime"
@@ -26,14 +27,31 @@ const (
 	// Batch flushing (massive)
 	V3QuotaFlushPeriod = 500 * time.Millisecond
 	V3QuotaBatchSize   = 1000 // 10x more than V2
+	V3QuotaMaxBackoff  = 30 * time.Second // Between retries of a failing store
 
 	// Lock-free queue sizes
-	V3QuotaQueueSize   = 100000
+	V3QuotaQueueSize   = 1 << 17 // Power of two: slots are indexed by mask
 
 	// Cache line size
 	CacheLineSize      = 64
 )
 
+// Quota errors returned by UpdateQuota and CheckEgress. The ingress and
+// egress errors match ErrBandwidthQuotaExceeded too.
+var (
+	ErrStorageQuotaExceeded   = errors.New("storage quota exceeded")
+	ErrBandwidthQuotaExceeded = errors.New("bandwidth quota exceeded")
+	ErrIngressQuotaExceeded   = fmt.Errorf("ingress %w", ErrBandwidthQuotaExceeded)
+	ErrEgressQuotaExceeded    = fmt.Errorf("egress %w", ErrBandwidthQuotaExceeded)
+)
+
+// Transfer is the bytes a request moved into (Ingress) and out of
+// (Egress) the store; bandwidth is their sum
+type Transfer struct {
+	Ingress int64
+	Egress  int64
+}
+
 // Cache-aligned tenant config
 type V3TenantConfig struct {
 	ID             [64]byte  // Fixed array
@@ -41,23 +59,33 @@ type V3TenantConfig struct {
 	Name           [256]byte
 	NameLen        uint16
 	StorageQuota   atomic.Int64
-	BandwidthQuota atomic.Int64
+	BandwidthQuota atomic.Int64 // Ingress plus egress
+	IngressQuota   atomic.Int64
+	EgressQuota    atomic.Int64
 	RateLimit      atomic.Int64
 	CreatedAt      int64
 	Flags          atomic.Uint32
 	_padding       [CacheLineSize - 16]byte
 }
 
-// Lock-free quota tracking
+// Quota tracking. Counters are read lock-free; writers hold mu so a
+// request's checks against every quota and its updates apply as one. mu
+// also guards the tenant's rate limit token bucket.
 type V3QuotaUsage struct {
 	TenantID       [64]byte
 	TenantIDLen    uint16
 	StorageUsed    atomic.Int64
 	RequestCount   atomic.Int64
-	BandwidthUsed  atomic.Int64
+	BandwidthUsed  atomic.Int64 // IngressUsed plus EgressUsed
+	IngressUsed    atomic.Int64
+	EgressUsed     atomic.Int64
 	LastUpdated    atomic.Int64
 	DirtyFlag      atomic.Uint32 // 0=clean, 1=dirty
-	_padding       [CacheLineSize - 16]byte
+	DirtySince     atomic.Int64  // When it last went dirty (Unix nano), 0 if clean
+
+	mu         sync.Mutex
+	bucket     tokenBucket
+	_padding   [CacheLineSize - 16]byte
 }
 
 // Lock-free cache entry
@@ -70,9 +98,9 @@ type V3CacheEntry struct {
 
 // Lock-free concurrent hash map shard
 type V3TenantShard struct {
-	// Map with RCU-like semantics
-	entries     unsafe.Pointer // *map[string]*V3TenantConfig
-	quotas      unsafe.Pointer // *map[string]*V3QuotaUsage
+	// Maps with RCU-like semantics. Configs and usage share one snapshot,
+	// so a writer never publishes one without the other.
+	maps        unsafe.Pointer // *v3ShardMaps
 	version     atomic.Uint64
 
 	// Statistics (lock-free)
@@ -83,6 +111,12 @@ type V3TenantShard struct {
 	_padding    [CacheLineSize - 16]byte
 }
 
+// v3ShardMaps is an immutable snapshot of a shard's tenants
+type v3ShardMaps struct {
+	entries map[string]*V3TenantConfig
+	quotas  map[string]*V3QuotaUsage
+}
+
 // Lock-free queue for quota updates
 type V3QuotaQueue struct {
 	queue    []unsafe.Pointer
@@ -114,10 +148,46 @@ type V3TenantManager struct {
 	cache          *V3TenantCache
 	quotaQueue     *V3QuotaQueue
 
+	// Cross-tenant sharing grants
+	grants         *grantStore
+	shareLinks     *shareLinkStore
+
+	// Per-tenant data encryption keys
+	keys           *keyStore
+
+	// Security settings constrained by compliance presets
+	settings       *settingsStore
+
+	// Service accounts with rotating access keys
+	serviceAccounts *serviceAccountStore
+
+	// Buckets and their configuration
+	buckets        *bucketStore
+
+	// Plans of provisioned tenants
+	plans          *planStore
+
+	// Suspended tenants
+	suspensions    *suspensionStore
+
+	// Anonymous reads of public buckets, rate limited per client address
+	publicClients  *publicLimiter
+
+	// API token signing secret (*[]byte)
+	tokenSecret    atomic.Pointer[[]byte]
+
 	// Worker pools
 	quotaFlushers  int
 	cacheEvictors  int
 
+	// Store dirty usage is flushed to (nil keeps usage in memory only), and
+	// loaded records waiting for their tenant to be registered
+	storeMu        sync.RWMutex
+	store          TenantStore
+	restored       map[string]UsageRecord
+	queueOverflow  atomic.Bool // Dirty usage missed the full queue; flushers scan for it
+	rateBurst      atomic.Uint64 // float64 bits: bucket capacity as a multiple of RateLimit
+
 	// Statistics (all atomic)
 	stats          *V3TenantStats
 
@@ -139,6 +209,11 @@ type V3TenantStats struct {
 	ThroughputOps    atomic.Uint64
 	QueueDepth       atomic.Int64
 	BatchesFlushed   atomic.Uint64
+	FlushedRecords   atomic.Uint64
+	FlushErrors      atomic.Uint64
+	QueueOverflows   atomic.Uint64
+	FlushLagNs       atomic.Int64 // Age of the oldest usage change not yet flushed
+	RateLimited      atomic.Uint64
 	_padding         [CacheLineSize - 8]byte
 }
 
@@ -149,13 +224,7 @@ func NewV3TenantManager() (*V3TenantManager, error) {
 	// Create sharded store with lock-free maps
 	shards := make([]*V3TenantShard, V3TenantShardCount)
 	for i := 0; i < V3TenantShardCount; i++ {
-		entries := make(map[string]*V3TenantConfig, 1000)
-		quotas := make(map[string]*V3QuotaUsage, 1000)
-
-		shards[i] = &V3TenantShard{
-			entries: unsafe.Pointer(&entries),
-			quotas:  unsafe.Pointer(&quotas),
-		}
+		shards[i] = newV3TenantShard()
 	}
 
 	// Create massive cache
@@ -170,16 +239,22 @@ func NewV3TenantManager() (*V3TenantManager, error) {
 	}
 
 	// Create lock-free quota queue
-	quotaQueue := &V3QuotaQueue{
-		queue: make([]unsafe.Pointer, V3QuotaQueueSize),
-		mask:  uint64(V3QuotaQueueSize - 1),
-	}
+	quotaQueue := newV3QuotaQueue(V3QuotaQueueSize)
 
 	tm := &V3TenantManager{
 		shards:        shards,
 		shardMask:     uint64(V3TenantShardCount - 1),
 		cache:         cache,
 		quotaQueue:    quotaQueue,
+		grants:        newGrantStore(),
+		shareLinks:    newShareLinkStore(),
+		keys:          newKeyStore(),
+		settings:      newSettingsStore(),
+		serviceAccounts: newServiceAccountStore(),
+		buckets:       newBucketStore(),
+		plans:         newPlanStore(),
+		suspensions:   newSuspensionStore(),
+		publicClients: newPublicLimiter(),
 		quotaFlushers: runtime.NumCPU() * 2,
 		cacheEvictors: runtime.NumCPU(),
 		stats:         &V3TenantStats{},
@@ -187,6 +262,9 @@ func NewV3TenantManager() (*V3TenantManager, error) {
 		cancel:        cancel,
 	}
 
+	tm.SetTokenSecret(newTokenSecret())
+	tm.SetRateBurst(DefaultRateBurst)
+
 	// Start quota flushers (massive parallelism)
 	for i := 0; i < tm.quotaFlushers; i++ {
 		tm.wg.Add(1)
@@ -229,6 +307,7 @@ func (tm *V3TenantManager) CreateTenant(ctx context.Context, name string, storag
 	copy(usage.TenantID[:], tenantID)
 	usage.TenantIDLen = uint16(len(tenantID))
 	usage.LastUpdated.Store(time.Now().UnixNano())
+	tm.restoreUsage(tenantID, usage)
 
 	// Insert into shard (lock-free for readers)
 	shardIdx := tm.fastHash(tenantID) & tm.shardMask
@@ -282,8 +361,10 @@ func (tm *V3TenantManager) GetTenant(ctx context.Context, tenantID string) (*V3T
 	return config, nil
 }
 
-// UpdateQuota with lock-free atomic operations
-func (tm *V3TenantManager) UpdateQuota(ctx context.Context, tenantID string, bytesAdded, requestCount, bandwidthUsed int64) error {
+// UpdateQuota with lock-free atomic operations. Egress is charged after
+// it has been sent and is never refused here; CheckEgress refuses a
+// download that would not fit beforehand.
+func (tm *V3TenantManager) UpdateQuota(ctx context.Context, tenantID string, bytesAdded, requestCount int64, transfer Transfer) error {
 	start := time.Now()
 	tm.stats.QuotaUpdates.Add(1)
 
@@ -302,47 +383,42 @@ func (tm *V3TenantManager) UpdateQuota(ctx context.Context, tenantID string, byt
 		return fmt.Errorf("usage not found: %s", tenantID)
 	}
 
-	// Atomic quota checks and updates (100% lock-free)
-	if bytesAdded > 0 {
-		quota := config.StorageQuota.Load()
-		if quota > 0 {
-			// Atomic add with check
-			newUsage := usage.StorageUsed.Add(bytesAdded)
-			if newUsage > quota {
-				// Rollback
-				usage.StorageUsed.Add(-bytesAdded)
-				tm.stats.QuotaExceeded.Add(1)
-				return fmt.Errorf("storage quota exceeded: %d > %d", newUsage, quota)
-			}
-		} else {
-			usage.StorageUsed.Add(bytesAdded)
+	// Check every quota, then apply, under the tenant's lock so concurrent
+	// callers cannot both pass a check only one of them fits under
+	now := time.Now().UnixNano()
+	usage.mu.Lock()
+	storage := usage.StorageUsed.Load() + bytesAdded
+	ingress := usage.IngressUsed.Load() + transfer.Ingress
+	egress := usage.EgressUsed.Load() + transfer.Egress
+	bandwidth := usage.BandwidthUsed.Load() + transfer.Ingress + transfer.Egress
+
+	// Releases (deletes, overwrites, rollbacks) never fail
+	var err error
+	if bytesAdded >= 0 {
+		if quota := config.StorageQuota.Load(); bytesAdded > 0 && quota > 0 && storage > quota {
+			err = fmt.Errorf("%w: %d > %d", ErrStorageQuotaExceeded, storage, quota)
+		} else if quota := config.IngressQuota.Load(); transfer.Ingress > 0 && quota > 0 && ingress > quota {
+			err = fmt.Errorf("%w: %d > %d", ErrIngressQuotaExceeded, ingress, quota)
+		} else if quota := config.BandwidthQuota.Load(); transfer.Ingress > 0 && quota > 0 && bandwidth > quota {
+			err = fmt.Errorf("%w: %d > %d", ErrBandwidthQuotaExceeded, bandwidth, quota)
 		}
 	}
-
-	if bandwidthUsed > 0 {
-		quota := config.BandwidthQuota.Load()
-		if quota > 0 {
-			newBandwidth := usage.BandwidthUsed.Add(bandwidthUsed)
-			if newBandwidth > quota {
-				usage.BandwidthUsed.Add(-bandwidthUsed)
-				if bytesAdded > 0 {
-					usage.StorageUsed.Add(-bytesAdded)
-				}
-				tm.stats.QuotaExceeded.Add(1)
-				return fmt.Errorf("bandwidth quota exceeded")
-			}
-		} else {
-			usage.BandwidthUsed.Add(bandwidthUsed)
-		}
+	if err != nil {
+		usage.mu.Unlock()
+		tm.stats.QuotaExceeded.Add(1)
+		return err
 	}
 
-	// Update request count
+	usage.StorageUsed.Store(storage)
+	usage.IngressUsed.Store(ingress)
+	usage.EgressUsed.Store(egress)
+	usage.BandwidthUsed.Store(bandwidth)
 	usage.RequestCount.Add(requestCount)
-	usage.LastUpdated.Store(time.Now().UnixNano())
-	usage.DirtyFlag.Store(1)
+	usage.LastUpdated.Store(now)
+	usage.mu.Unlock()
 
 	// Queue for async flush (lock-free)
-	tm.quotaQueue.Push(unsafe.Pointer(usage))
+	tm.markDirty(usage)
 
 	latency := time.Since(start).Nanoseconds()
 	tm.stats.AvgLatencyNs.Store(latency)
@@ -350,6 +426,62 @@ func (tm *V3TenantManager) UpdateQuota(ctx context.Context, tenantID string, byt
 	return nil
 }
 
+// CheckEgress reports whether the tenant may be sent n more bytes under
+// its egress and bandwidth quotas, returning ErrEgressQuotaExceeded or
+// ErrBandwidthQuotaExceeded if not
+func (tm *V3TenantManager) CheckEgress(ctx context.Context, tenantID string, n int64) error {
+	shard := tm.shards[tm.fastHash(tenantID)&tm.shardMask]
+	config := tm.getFromShard(shard, tenantID)
+	usage := tm.getUsageFromShard(shard, tenantID)
+	if config == nil || usage == nil {
+		return fmt.Errorf("tenant not found: %s", tenantID)
+	}
+
+	if quota := config.EgressQuota.Load(); quota > 0 && usage.EgressUsed.Load()+n > quota {
+		tm.stats.QuotaExceeded.Add(1)
+		return fmt.Errorf("%w: %d > %d", ErrEgressQuotaExceeded, usage.EgressUsed.Load()+n, quota)
+	}
+	if quota := config.BandwidthQuota.Load(); quota > 0 && usage.BandwidthUsed.Load()+n > quota {
+		tm.stats.QuotaExceeded.Add(1)
+		return fmt.Errorf("%w: %d > %d", ErrBandwidthQuotaExceeded, usage.BandwidthUsed.Load()+n, quota)
+	}
+	return nil
+}
+
+// QuotaLimits changes a tenant's quotas in bytes, zero for unlimited;
+// nil fields are left as they are
+type QuotaLimits struct {
+	StorageQuota   *int64 `json:"storage_quota,omitempty"`
+	BandwidthQuota *int64 `json:"bandwidth_quota,omitempty"`
+	IngressQuota   *int64 `json:"ingress_quota,omitempty"`
+	EgressQuota    *int64 `json:"egress_quota,omitempty"`
+}
+
+// SetQuotas changes a tenant's quotas. Usage already over a lowered quota
+// is kept; further requests are refused.
+func (tm *V3TenantManager) SetQuotas(ctx context.Context, tenantID string, limits QuotaLimits) error {
+	for _, q := range []*int64{limits.StorageQuota, limits.BandwidthQuota, limits.IngressQuota, limits.EgressQuota} {
+		if q != nil && *q < 0 {
+			return fmt.Errorf("quotas must not be negative")
+		}
+	}
+	config, err := tm.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for q, v := range map[*atomic.Int64]*int64{
+		&config.StorageQuota:   limits.StorageQuota,
+		&config.BandwidthQuota: limits.BandwidthQuota,
+		&config.IngressQuota:   limits.IngressQuota,
+		&config.EgressQuota:    limits.EgressQuota,
+	} {
+		if v != nil {
+			q.Store(*v)
+		}
+	}
+	return nil
+}
+
 // CheckQuota - lock-free read-only check
 func (tm *V3TenantManager) CheckQuota(ctx context.Context, tenantID string, bytesRequired int64) (bool, error) {
 	tm.stats.QuotaChecks.Add(1)
@@ -386,7 +518,7 @@ func (tm *V3TenantManager) BatchUpdateQuota(ctx context.Context, updates map[str
 		go func() {
 			defer wg.Done()
 			for update := range updateCh {
-				if err := tm.UpdateQuota(ctx, update.TenantID, update.Bytes, update.Requests, update.Bandwidth); err != nil {
+				if err := tm.UpdateQuota(ctx, update.TenantID, update.Bytes, update.Requests, update.Transfer); err != nil {
 					select {
 					case errCh <- err:
 					default:
@@ -411,6 +543,45 @@ func (tm *V3TenantManager) BatchUpdateQuota(ctx context.Context, updates map[str
 	}
 }
 
+// GetUsage returns the live usage counters for a tenant
+func (tm *V3TenantManager) GetUsage(ctx context.Context, tenantID string) (*V3QuotaUsage, error) {
+	shard := tm.shards[tm.fastHash(tenantID)&tm.shardMask]
+
+	usage := tm.getUsageFromShard(shard, tenantID)
+	if usage == nil {
+		return nil, fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	return usage, nil
+}
+
+// ReconcileStorageUsage overwrites a tenant's storage counter with a value
+// recomputed from the index, returning the previous value
+func (tm *V3TenantManager) ReconcileStorageUsage(ctx context.Context, tenantID string, storageUsed int64) (int64, error) {
+	usage, err := tm.GetUsage(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	usage.mu.Lock()
+	previous := usage.StorageUsed.Swap(storageUsed)
+	usage.LastUpdated.Store(time.Now().UnixNano())
+	usage.mu.Unlock()
+	tm.markDirty(usage)
+
+	return previous, nil
+}
+
+// ListTenants returns the IDs of all registered tenants
+func (tm *V3TenantManager) ListTenants(ctx context.Context) []string {
+	ids := make([]string, 0, tm.stats.TotalTenants.Load())
+	for _, shard := range tm.shards {
+		for id := range shard.load().entries {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // Fast hashing
 func (tm *V3TenantManager) fastHash(key string) uint64 {
 	h := fnv.New64a()
@@ -426,44 +597,85 @@ func (tm *V3TenantManager) generateTenantID(name string) string {
 }
 
 // Lock-free shard operations (RCU-style)
+
+func newV3TenantShard() *V3TenantShard {
+	return &V3TenantShard{maps: unsafe.Pointer(&v3ShardMaps{
+		entries: make(map[string]*V3TenantConfig),
+		quotas:  make(map[string]*V3QuotaUsage),
+	})}
+}
+
+// load returns the shard's current snapshot
+func (shard *V3TenantShard) load() *v3ShardMaps {
+	return (*v3ShardMaps)(atomic.LoadPointer(&shard.maps))
+}
+
 func (tm *V3TenantManager) insertIntoShard(shard *V3TenantShard, tenantID string, config *V3TenantConfig, usage *V3QuotaUsage) {
 	for {
-		// Load current map
-		entriesPtr := atomic.LoadPointer(&shard.entries)
-		quotasPtr := atomic.LoadPointer(&shard.quotas)
-
-		entriesMap := *(*map[string]*V3TenantConfig)(entriesPtr)
-		quotasMap := *(*map[string]*V3QuotaUsage)(quotasPtr)
+		// Load current maps
+		oldPtr := atomic.LoadPointer(&shard.maps)
+		old := (*v3ShardMaps)(oldPtr)
+		_, replacing := old.entries[tenantID]
 
 		// Create new maps with added entry
-		newEntries := make(map[string]*V3TenantConfig, len(entriesMap)+1)
-		newQuotas := make(map[string]*V3QuotaUsage, len(quotasMap)+1)
-
-		for k, v := range entriesMap {
-			newEntries[k] = v
+		maps := &v3ShardMaps{
+			entries: make(map[string]*V3TenantConfig, len(old.entries)+1),
+			quotas:  make(map[string]*V3QuotaUsage, len(old.quotas)+1),
 		}
-		for k, v := range quotasMap {
-			newQuotas[k] = v
+		for k, v := range old.entries {
+			maps.entries[k] = v
 		}
-
-		newEntries[tenantID] = config
-		newQuotas[tenantID] = usage
+		for k, v := range old.quotas {
+			maps.quotas[k] = v
+		}
+		maps.entries[tenantID] = config
+		maps.quotas[tenantID] = usage
 
 		// Try to swap
-		if atomic.CompareAndSwapPointer(&shard.entries, entriesPtr, unsafe.Pointer(&newEntries)) {
-			atomic.CompareAndSwapPointer(&shard.quotas, quotasPtr, unsafe.Pointer(&newQuotas))
+		if atomic.CompareAndSwapPointer(&shard.maps, oldPtr, unsafe.Pointer(maps)) {
 			shard.version.Add(1)
-			shard.entryCount.Add(1)
+			if !replacing {
+				shard.entryCount.Add(1)
+			}
 			break
 		}
 	}
 }
 
-func (tm *V3TenantManager) getFromShard(shard *V3TenantShard, tenantID string) *V3TenantConfig {
-	entriesPtr := atomic.LoadPointer(&shard.entries)
-	entriesMap := *(*map[string]*V3TenantConfig)(entriesPtr)
+// removeFromShard is the RCU-style inverse of insertIntoShard
+func (tm *V3TenantManager) removeFromShard(shard *V3TenantShard, tenantID string) bool {
+	for {
+		oldPtr := atomic.LoadPointer(&shard.maps)
+		old := (*v3ShardMaps)(oldPtr)
+		if _, exists := old.entries[tenantID]; !exists {
+			return false
+		}
 
-	config, exists := entriesMap[tenantID]
+		maps := &v3ShardMaps{
+			entries: make(map[string]*V3TenantConfig, len(old.entries)),
+			quotas:  make(map[string]*V3QuotaUsage, len(old.quotas)),
+		}
+		for k, v := range old.entries {
+			if k != tenantID {
+				maps.entries[k] = v
+			}
+		}
+		for k, v := range old.quotas {
+			if k != tenantID {
+				maps.quotas[k] = v
+			}
+		}
+
+		if atomic.CompareAndSwapPointer(&shard.maps, oldPtr, unsafe.Pointer(maps)) {
+			shard.version.Add(1)
+			shard.entryCount.Add(-1)
+			return true
+		}
+	}
+}
+
+func (tm *V3TenantManager) getFromShard(shard *V3TenantShard, tenantID string) *V3TenantConfig {
+	config, exists := shard.load().entries[tenantID]
 	if exists {
 		shard.hitCount.Add(1)
 		return config
@@ -474,9 +686,7 @@ func (tm *V3TenantManager) getFromShard(shard *V3TenantShard, tenantID string) *
 }
 
 func (tm *V3TenantManager) getUsageFromShard(shard *V3TenantShard, tenantID string) *V3QuotaUsage {
-	quotasPtr := atomic.LoadPointer(&shard.quotas)
-	quotasMap := *(*map[string]*V3QuotaUsage)(quotasPtr)
-	return quotasMap[tenantID]
+	return shard.load().quotas[tenantID]
 }
 
 // ========== Lock-Free Cache ==========
@@ -520,8 +730,28 @@ func (c *V3TenantCache) Set(tenantID string, config *V3TenantConfig) {
 	shard.count.Add(1)
 }
 
+func (c *V3TenantCache) Delete(tenantID string) {
+	h := fnv.New64a()
+	h.Write([]byte(tenantID))
+	shard := c.shards[h.Sum64()&c.shardMask]
+
+	if _, loaded := shard.entries.LoadAndDelete(tenantID); loaded {
+		shard.count.Add(-1)
+	}
+}
+
 // ========== Lock-Free Queue ==========
 
+// newV3QuotaQueue returns a queue of size slots, a power of two
+func newV3QuotaQueue(size int) *V3QuotaQueue {
+	return &V3QuotaQueue{
+		queue: make([]unsafe.Pointer, size),
+		mask:  uint64(size - 1),
+	}
+}
+
+// Push claims a slot by advancing head, then publishes item into it once
+// the consumer of the slot's previous lap has emptied it
 func (q *V3QuotaQueue) Push(item unsafe.Pointer) bool {
 	for {
 		head := q.head.Load()
@@ -532,13 +762,18 @@ func (q *V3QuotaQueue) Push(item unsafe.Pointer) bool {
 		}
 
 		if q.head.CompareAndSwap(head, head+1) {
-			q.queue[head&q.mask] = item
+			slot := &q.queue[head&q.mask]
+			for !atomic.CompareAndSwapPointer(slot, nil, item) {
+				runtime.Gosched()
+			}
 			q.count.Add(1)
 			return true
 		}
 	}
 }
 
+// Pop claims a slot by advancing tail, then waits for its producer to
+// publish into it
 func (q *V3QuotaQueue) Pop() unsafe.Pointer {
 	for {
 		tail := q.tail.Load()
@@ -549,9 +784,14 @@ func (q *V3QuotaQueue) Pop() unsafe.Pointer {
 		}
 
 		if q.tail.CompareAndSwap(tail, tail+1) {
-			item := q.queue[tail&q.mask]
-			q.count.Add(-1)
-			return item
+			slot := &q.queue[tail&q.mask]
+			for {
+				if item := atomic.SwapPointer(slot, nil); item != nil {
+					q.count.Add(-1)
+					return item
+				}
+				runtime.Gosched()
+			}
 		}
 	}
 }
@@ -565,37 +805,200 @@ func (tm *V3TenantManager) quotaFlusher(workerID int) {
 	defer ticker.Stop()
 
 	batch := make([]*V3QuotaUsage, 0, V3QuotaBatchSize)
+	var backoff time.Duration
+	var retryAt time.Time
 
 	for {
 		select {
 		case <-tm.ctx.Done():
 			return
-		case <-ticker.C:
-			// Collect dirty quotas
-			batch = batch[:0]
+		case now := <-ticker.C:
+			if now.Before(retryAt) {
+				continue
+			}
 
+			// Collect dirty quotas, and any the queue had no room for
+			batch = batch[:0]
 			for i := 0; i < V3QuotaBatchSize; i++ {
 				ptr := tm.quotaQueue.Pop()
 				if ptr == nil {
 					break
 				}
+				batch = append(batch, (*V3QuotaUsage)(ptr))
+			}
+			if tm.queueOverflow.Swap(false) {
+				batch = tm.appendDirty(batch)
+			}
+			if len(batch) == 0 {
+				continue
+			}
 
-				usage := (*V3QuotaUsage)(ptr)
-				if usage.DirtyFlag.Load() == 1 {
-					batch = append(batch, usage)
-				}
+			if err := tm.flushUsage(tm.ctx, batch); err != nil {
+				backoff = min(max(2*backoff, V3QuotaFlushPeriod), V3QuotaMaxBackoff)
+				retryAt = now.Add(backoff)
+				continue
+			}
+			backoff = 0
+		}
+	}
+}
+
+// markDirty queues usage for the flushers if it was clean. If the queue
+// is full the flushers are told to scan for it instead.
+func (tm *V3TenantManager) markDirty(usage *V3QuotaUsage) {
+	usage.DirtySince.CompareAndSwap(0, time.Now().UnixNano())
+	if usage.DirtyFlag.Swap(1) == 1 {
+		return
+	}
+	if !tm.quotaQueue.Push(unsafe.Pointer(usage)) {
+		tm.stats.QueueOverflows.Add(1)
+		tm.queueOverflow.Store(true)
+	}
+}
+
+// appendDirty adds every dirty usage to batch
+func (tm *V3TenantManager) appendDirty(batch []*V3QuotaUsage) []*V3QuotaUsage {
+	for _, shard := range tm.shards {
+		for _, usage := range shard.load().quotas {
+			if usage.DirtyFlag.Load() == 1 {
+				batch = append(batch, usage)
 			}
+		}
+	}
+	return batch
+}
 
-			if len(batch) > 0 {
-				// In production, would flush to database
-				// For now, just mark as clean
-				for _, usage := range batch {
-					usage.DirtyFlag.Store(0)
+// flushUsage upserts the dirty usages in batch into the store as one
+// batch. Each is marked clean before its counters are read, so changes
+// racing the flush dirty it again; on failure all are re-marked dirty.
+func (tm *V3TenantManager) flushUsage(ctx context.Context, batch []*V3QuotaUsage) error {
+	type claimed struct {
+		usage *V3QuotaUsage
+		since int64
+	}
+	claims := make([]claimed, 0, len(batch))
+	records := make([]UsageRecord, 0, len(batch))
+	for _, usage := range batch {
+		// Duplicates and already flushed usages fail the claim
+		if !usage.DirtyFlag.CompareAndSwap(1, 0) {
+			continue
+		}
+		since := usage.DirtySince.Swap(0)
+		tenantID := string(usage.TenantID[:usage.TenantIDLen])
+		if tm.getUsageFromShard(tm.shards[tm.fastHash(tenantID)&tm.shardMask], tenantID) != usage {
+			continue // Deleted tenant
+		}
+		claims = append(claims, claimed{usage, since})
+		usage.mu.Lock()
+		records = append(records, UsageRecord{
+			TenantID:      tenantID,
+			StorageUsed:   usage.StorageUsed.Load(),
+			RequestCount:  usage.RequestCount.Load(),
+			BandwidthUsed: usage.BandwidthUsed.Load(),
+			IngressUsed:   usage.IngressUsed.Load(),
+			EgressUsed:    usage.EgressUsed.Load(),
+			UpdatedAt:     usage.LastUpdated.Load(),
+		})
+		usage.mu.Unlock()
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	tm.storeMu.RLock()
+	store := tm.store
+	tm.storeMu.RUnlock()
+	if store != nil {
+		if err := store.UpsertUsage(ctx, records); err != nil {
+			tm.stats.FlushErrors.Add(1)
+			for _, c := range claims {
+				c.usage.DirtySince.CompareAndSwap(0, c.since)
+				if c.usage.DirtyFlag.Swap(1) == 0 && !tm.quotaQueue.Push(unsafe.Pointer(c.usage)) {
+					tm.stats.QueueOverflows.Add(1)
+					tm.queueOverflow.Store(true)
 				}
-				tm.stats.BatchesFlushed.Add(1)
 			}
+			return err
 		}
 	}
+	tm.stats.FlushedRecords.Add(uint64(len(records)))
+	tm.stats.BatchesFlushed.Add(1)
+	return nil
+}
+
+// SetUsageStore flushes usage to store from now on, first loading the
+// usage it holds. Loaded records overwrite the counters of registered
+// tenants, and are applied to others when a tenant with that ID is created.
+func (tm *V3TenantManager) SetUsageStore(ctx context.Context, store TenantStore) error {
+	records, err := store.LoadUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	tm.storeMu.Lock()
+	defer tm.storeMu.Unlock()
+	tm.store = store
+	tm.restored = make(map[string]UsageRecord, len(records))
+	for _, r := range records {
+		shard := tm.shards[tm.fastHash(r.TenantID)&tm.shardMask]
+		if usage := tm.getUsageFromShard(shard, r.TenantID); usage != nil {
+			applyUsage(usage, r)
+		} else {
+			tm.restored[r.TenantID] = r
+		}
+	}
+	return nil
+}
+
+// restoreUsage applies a loaded record to a newly created tenant's usage
+func (tm *V3TenantManager) restoreUsage(tenantID string, usage *V3QuotaUsage) {
+	tm.storeMu.Lock()
+	defer tm.storeMu.Unlock()
+
+	if r, ok := tm.restored[tenantID]; ok {
+		applyUsage(usage, r)
+		delete(tm.restored, tenantID)
+	}
+}
+
+func applyUsage(usage *V3QuotaUsage, r UsageRecord) {
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.StorageUsed.Store(r.StorageUsed)
+	usage.RequestCount.Store(r.RequestCount)
+	usage.BandwidthUsed.Store(r.BandwidthUsed)
+	usage.IngressUsed.Store(r.IngressUsed)
+	usage.EgressUsed.Store(r.EgressUsed)
+	usage.LastUpdated.Store(r.UpdatedAt)
+}
+
+// forgetUsage drops a deleted tenant's stored usage
+func (tm *V3TenantManager) forgetUsage(ctx context.Context, tenantID string) error {
+	tm.storeMu.Lock()
+	store := tm.store
+	delete(tm.restored, tenantID)
+	tm.storeMu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+	return store.DeleteUsage(ctx, tenantID)
+}
+
+// flushLag is the age of the oldest usage change not yet flushed
+func (tm *V3TenantManager) flushLag(now int64) time.Duration {
+	var oldest int64
+	for _, shard := range tm.shards {
+		for _, usage := range shard.load().quotas {
+			if since := usage.DirtySince.Load(); since != 0 && (oldest == 0 || since < oldest) {
+				oldest = since
+			}
+		}
+	}
+	if oldest == 0 {
+		return 0
+	}
+	return time.Duration(now - oldest)
 }
 
 func (tm *V3TenantManager) cacheEvictor() {
@@ -650,6 +1053,7 @@ func (tm *V3TenantManager) statsCollector() {
 			currentOps := tm.stats.TotalRequests.Load()
 			tm.stats.ThroughputOps.Store(currentOps - lastOps)
 			tm.stats.QueueDepth.Store(tm.quotaQueue.count.Load())
+			tm.stats.FlushLagNs.Store(int64(tm.flushLag(time.Now().UnixNano())))
 			lastOps = currentOps
 		}
 	}
@@ -672,17 +1076,20 @@ func (tm *V3TenantManager) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-done:
-		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
+
+	// Flush whatever the workers left dirty so usage survives the restart
+	tm.queueOverflow.Store(false)
+	return tm.flushUsage(ctx, tm.appendDirty(nil))
 }
 
 // ========== Helper Types ==========
 
 type QuotaUpdate struct {
-	TenantID  string
-	Bytes     int64
-	Requests  int64
-	Bandwidth int64
+	TenantID string
+	Bytes    int64
+	Requests int64
+	Transfer Transfer
 }
@@ -0,0 +1,185 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestTenant(t *testing.T, storageQuota, bandwidthQuota, rateLimit int64) (*V3TenantManager, string) {
+	t.Helper()
+	tm, err := NewV3TenantManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tm.Shutdown(context.Background()) })
+
+	id, err := tm.CreateTenant(context.Background(), "test", storageQuota, bandwidthQuota, rateLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm, id
+}
+
+// Concurrent writers racing for the last bytes of a quota never push usage
+// past it, not even transiently
+func TestUpdateQuotaConcurrentStorageNeverOvershoots(t *testing.T) {
+	const quota = 1000
+	tm, id := newTestTenant(t, quota, 0, 0)
+	ctx := context.Background()
+	usage, _ := tm.GetUsage(ctx, id)
+
+	var accepted atomic.Int64
+	var overshoot atomic.Int64
+	stop := make(chan struct{})
+	watcher := make(chan struct{})
+	go func() {
+		defer close(watcher)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if used := usage.StorageUsed.Load(); used > quota {
+				overshoot.Store(used)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 64; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				err := tm.UpdateQuota(ctx, id, 7, 1, Transfer{})
+				switch {
+				case err == nil:
+					accepted.Add(1)
+				case !errors.Is(err, ErrStorageQuotaExceeded):
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-watcher
+
+	if n := overshoot.Load(); n != 0 {
+		t.Errorf("Storage usage reached %d, over the quota of %d", n, quota)
+	}
+	if used := usage.StorageUsed.Load(); used != 7*accepted.Load() || used != quota/7*7 {
+		t.Errorf("Storage used %d after %d accepted writes of 7 bytes", used, accepted.Load())
+	}
+	if n := usage.RequestCount.Load(); n != accepted.Load() {
+		t.Errorf("Request count %d, want %d (rejected requests must not count)", n, accepted.Load())
+	}
+}
+
+// A request rejected on one quota leaves every counter untouched
+func TestUpdateQuotaCombinedChecksAreAllOrNothing(t *testing.T) {
+	const storageQuota, bandwidthQuota = 10000, 3000
+	tm, id := newTestTenant(t, storageQuota, bandwidthQuota, 0)
+	ctx := context.Background()
+
+	var accepted, storageRejects, bandwidthRejects atomic.Int64
+	var wantStorage, wantBandwidth atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				// Writers alternate between running out of storage and of bandwidth
+				bytes, bandwidth := int64(10), int64(30)
+				if g%2 == 0 {
+					bytes, bandwidth = 100, 1
+				}
+				err := tm.UpdateQuota(ctx, id, bytes, 1, Transfer{Ingress: bandwidth})
+				switch {
+				case err == nil:
+					accepted.Add(1)
+					wantStorage.Add(bytes)
+					wantBandwidth.Add(bandwidth)
+				case errors.Is(err, ErrStorageQuotaExceeded):
+					storageRejects.Add(1)
+				case errors.Is(err, ErrBandwidthQuotaExceeded):
+					bandwidthRejects.Add(1)
+				default:
+					t.Errorf("Unexpected error: %v", err)
+				}
+				// Releases interleave with the writes and must never fail
+				if err == nil && i%3 == 0 {
+					if err := tm.UpdateQuota(ctx, id, -bytes, 0, Transfer{}); err != nil {
+						t.Errorf("Release failed: %v", err)
+					}
+					wantStorage.Add(-bytes)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	usage, _ := tm.GetUsage(ctx, id)
+	storage, bandwidth := usage.StorageUsed.Load(), usage.BandwidthUsed.Load()
+	if storage > storageQuota || bandwidth > bandwidthQuota {
+		t.Errorf("Usage storage=%d bandwidth=%d exceeds quotas %d/%d", storage, bandwidth, storageQuota, bandwidthQuota)
+	}
+	if storageRejects.Load() == 0 && bandwidthRejects.Load() == 0 {
+		t.Fatal("Expected some writes to be rejected")
+	}
+	if n := usage.RequestCount.Load(); n != accepted.Load() {
+		t.Errorf("Request count %d, want %d accepted", n, accepted.Load())
+	}
+
+	// Every accepted write is fully recorded; rejected ones added nothing
+	if storage != wantStorage.Load() || bandwidth != wantBandwidth.Load() {
+		t.Errorf("Usage storage=%d bandwidth=%d, want %d/%d from the accepted writes",
+			storage, bandwidth, wantStorage.Load(), wantBandwidth.Load())
+	}
+}
+
+// Ingress and egress are counted and limited apart, and add up to bandwidth
+func TestUpdateQuotaSeparatesIngressAndEgress(t *testing.T) {
+	tm, id := newTestTenant(t, 0, 0, 0)
+	ctx := context.Background()
+	ingressQuota, egressQuota := int64(100), int64(50)
+	if err := tm.SetQuotas(ctx, id, QuotaLimits{IngressQuota: &ingressQuota, EgressQuota: &egressQuota}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tm.UpdateQuota(ctx, id, 60, 1, Transfer{Ingress: 60}); err != nil {
+		t.Fatalf("Upload within the ingress quota: %v", err)
+	}
+	if err := tm.UpdateQuota(ctx, id, 60, 1, Transfer{Ingress: 60}); !errors.Is(err, ErrIngressQuotaExceeded) || !errors.Is(err, ErrBandwidthQuotaExceeded) {
+		t.Errorf("Upload over the ingress quota: err = %v, want ErrIngressQuotaExceeded", err)
+	}
+
+	if err := tm.CheckEgress(ctx, id, 40); err != nil {
+		t.Errorf("CheckEgress within the egress quota: %v", err)
+	}
+	if err := tm.UpdateQuota(ctx, id, 0, 1, Transfer{Egress: 40}); err != nil {
+		t.Fatalf("Charging egress: %v", err)
+	}
+	if err := tm.CheckEgress(ctx, id, 40); !errors.Is(err, ErrEgressQuotaExceeded) {
+		t.Errorf("CheckEgress over the egress quota: err = %v, want ErrEgressQuotaExceeded", err)
+	}
+	// Egress already sent is charged even past the quota
+	if err := tm.UpdateQuota(ctx, id, 0, 1, Transfer{Egress: 40}); err != nil {
+		t.Errorf("Charging sent egress past the quota: %v", err)
+	}
+
+	usage, _ := tm.GetUsage(ctx, id)
+	if in, out, bw := usage.IngressUsed.Load(), usage.EgressUsed.Load(), usage.BandwidthUsed.Load(); in != 60 || out != 80 || bw != 140 {
+		t.Errorf("Usage ingress=%d egress=%d bandwidth=%d, want 60/80/140", in, out, bw)
+	}
+
+	negative := int64(-1)
+	if err := tm.SetQuotas(ctx, id, QuotaLimits{EgressQuota: &negative}); err == nil {
+		t.Error("SetQuotas accepted a negative quota")
+	}
+}
@@ -0,0 +1,149 @@
+// internal/tenant/usagestore.go
+// Persistent tenant usage: the store the V3 quota flushers upsert dirty
+// usage counters into, and a file-backed implementation of it
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// UsageRecord is a tenant's usage counters as of UpdatedAt (Unix nano)
+// BandwidthUsed is IngressUsed plus EgressUsed, except in records written
+// before the two were counted apart.
+type UsageRecord struct {
+	TenantID      string `json:"tenant_id"`
+	StorageUsed   int64  `json:"storage_used"`
+	RequestCount  int64  `json:"request_count"`
+	BandwidthUsed int64  `json:"bandwidth_used"`
+	IngressUsed   int64  `json:"ingress_used"`
+	EgressUsed    int64  `json:"egress_used"`
+	UpdatedAt     int64  `json:"updated_at"`
+}
+
+// TenantStore persists usage records. Flushers run concurrently, so
+// UpsertUsage must keep a stored record that is newer than the one given.
+type TenantStore interface {
+	UpsertUsage(ctx context.Context, records []UsageRecord) error
+	LoadUsage(ctx context.Context) ([]UsageRecord, error)
+	DeleteUsage(ctx context.Context, tenantID string) error
+}
+
+// FileTenantStore keeps usage records in one JSON file, rewritten
+// atomically on every upsert
+type FileTenantStore struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]UsageRecord
+}
+
+// OpenFileTenantStore opens (creating on first write) the store at path
+func OpenFileTenantStore(path string) (*FileTenantStore, error) {
+	s := &FileTenantStore{path: path, records: make(map[string]UsageRecord)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage store: %w", err)
+	}
+	var records []UsageRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse usage store: %w", err)
+	}
+	for _, r := range records {
+		s.records[r.TenantID] = r
+	}
+	return s, nil
+}
+
+// UpsertUsage merges records, keeping the newer of each tenant's two
+func (s *FileTenantStore) UpsertUsage(ctx context.Context, records []UsageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, r := range records {
+		if cur, ok := s.records[r.TenantID]; ok && cur.UpdatedAt > r.UpdatedAt {
+			continue
+		}
+		s.records[r.TenantID] = r
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return s.writeLocked()
+}
+
+// LoadUsage returns every stored record
+func (s *FileTenantStore) LoadUsage(ctx context.Context) ([]UsageRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]UsageRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// DeleteUsage removes a tenant's record
+func (s *FileTenantStore) DeleteUsage(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[tenantID]; !ok {
+		return nil
+	}
+	delete(s.records, tenantID)
+	return s.writeLocked()
+}
+
+func (s *FileTenantStore) writeLocked() error {
+	records := make([]UsageRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].TenantID < records[j].TenantID })
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write usage store: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data, creating its directory
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,313 @@
+// internal/tlsconfig/tlsconfig.go
+// Server TLS configuration. A Reloader holds the certificate, key and
+// client CA pool loaded from files and reloads them when the files change,
+// so certificates renewed in place (cert-manager, certbot) are picked up
+// by new connections without a restart. A failed reload keeps the
+// previous certificate.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Client authentication modes when a client CA is configured
+const (
+	ClientAuthRequire       = "require"         // Clients must present a certificate the CA signed
+	ClientAuthVerifyIfGiven = "verify_if_given" // Certificates are verified when sent
+)
+
+// DefaultReloadInterval is how often the files are checked for changes
+const DefaultReloadInterval = 30 * time.Second
+
+// Options configures a Reloader
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // Enables mutual TLS
+	ClientAuth   string // ClientAuthRequire (default) or ClientAuthVerifyIfGiven
+	MinVersion   string // "1.2" (default) or "1.3"
+	CipherSuites []string
+}
+
+// Validate checks opts without reading any files
+func (opts Options) Validate() error {
+	var errs []error
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		errs = append(errs, errors.New("a TLS certificate and key must be set together"))
+	}
+	if opts.CertFile == "" && opts.ClientCAFile != "" {
+		errs = append(errs, errors.New("a TLS client CA needs a certificate and key"))
+	}
+	if _, err := clientAuth(opts); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := ParseVersion(opts.MinVersion); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := ParseCipherSuites(opts.CipherSuites); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ParseVersion maps "1.2" or "1.3" to its TLS version; "" is TLS 1.2
+func ParseVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS minimum version %q (want 1.2 or 1.3)", v)
+}
+
+// ParseCipherSuites maps Go cipher suite names to IDs. Suites Go considers
+// insecure are refused. Suites only restrict TLS 1.2; TLS 1.3's are fixed.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		known[cs.Name] = cs.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func clientAuth(opts Options) (tls.ClientAuthType, error) {
+	if opts.ClientCAFile == "" {
+		return tls.NoClientCert, nil
+	}
+	switch opts.ClientAuth {
+	case "", ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	}
+	return 0, fmt.Errorf("unknown TLS client auth %q (want %s or %s)", opts.ClientAuth, ClientAuthRequire, ClientAuthVerifyIfGiven)
+}
+
+// loaded is one generation of the files' contents
+type loaded struct {
+	cert     *tls.Certificate
+	leaf     *x509.Certificate
+	clientCA *x509.CertPool
+	stamps   []fileStamp
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Stats counts a Reloader's reloads
+type Stats struct {
+	Reloads   uint64    `json:"reloads"`
+	Failures  uint64    `json:"failures"`
+	NotAfter  time.Time `json:"not_after"` // Expiry of the certificate being served
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Reloader serves certificates loaded from files, reloading them on change
+type Reloader struct {
+	opts Options
+	base *tls.Config
+
+	current  atomic.Pointer[loaded]
+	reloads  atomic.Uint64
+	failures atomic.Uint64
+
+	mu      sync.Mutex
+	lastErr error
+	failed  []fileStamp // Files that last failed to load, not retried until changed
+}
+
+// NewReloader loads opts' files, failing if they do not form a usable
+// certificate
+func NewReloader(opts Options) (*Reloader, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	auth, _ := clientAuth(opts)
+	version, _ := ParseVersion(opts.MinVersion)
+	suites, _ := ParseCipherSuites(opts.CipherSuites)
+
+	r := &Reloader{opts: opts, base: &tls.Config{
+		MinVersion:   version,
+		CipherSuites: suites,
+		ClientAuth:   auth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}}
+	l, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(l)
+	return r, nil
+}
+
+// files lists the files a generation is loaded from
+func (r *Reloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) stamp() ([]fileStamp, error) {
+	var stamps []fileStamp
+	for _, name := range r.files() {
+		st, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{st.Size(), st.ModTime()})
+	}
+	return stamps, nil
+}
+
+// load reads a generation from the files
+func (r *Reloader) load() (*loaded, error) {
+	// Stamped first, so a change made while reading is seen next time
+	stamps, err := r.stamp()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	l := &loaded{cert: &cert, leaf: leaf, stamps: stamps}
+	if r.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA: %w", err)
+		}
+		l.clientCA = x509.NewCertPool()
+		if !l.clientCA.AppendCertsFromPEM(pem) {
+			return nil, errors.New("TLS client CA file holds no certificates")
+		}
+	}
+	return l, nil
+}
+
+// ServerConfig returns a config that serves the current certificate and
+// verifies clients against the current CA pool
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := r.base.Clone()
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.current.Load().cert, nil
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		l := r.current.Load()
+		c := r.base.Clone()
+		c.Certificates = []tls.Certificate{*l.cert}
+		c.ClientCAs = l.clientCA
+		return c, nil
+	}
+	return cfg
+}
+
+// Reload reloads the files if any changed since they were last loaded or
+// last failed to. It reports whether a new generation is in use; on error
+// the old one stays.
+func (r *Reloader) Reload() (bool, error) {
+	stamps, err := r.stamp()
+	if err == nil && stampsEqual(stamps, r.current.Load().stamps) {
+		return false, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil && r.failed != nil && stampsEqual(stamps, r.failed) {
+		return false, nil
+	}
+	var l *loaded
+	if err == nil {
+		l, err = r.load()
+	}
+
+	r.lastErr = err
+	if err != nil {
+		r.failed = stamps
+		r.failures.Add(1)
+		return false, err
+	}
+	r.failed = nil
+	r.current.Store(l)
+	r.reloads.Add(1)
+	return true, nil
+}
+
+func stampsEqual(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch reloads the files every interval until ctx is done, calling
+// report (which may be nil) after every reload attempt that changed
+// something or failed
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, report func(reloaded bool, err error)) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if report != nil && (reloaded || err != nil) {
+				report(reloaded, err)
+			}
+		}
+	}
+}
+
+// Stats reports reload counts and the served certificate's expiry
+func (r *Reloader) Stats() Stats {
+	st := Stats{
+		Reloads:  r.reloads.Load(),
+		Failures: r.failures.Load(),
+		NotAfter: r.current.Load().leaf.NotAfter,
+	}
+	r.mu.Lock()
+	if r.lastErr != nil {
+		st.LastError = r.lastErr.Error()
+	}
+	r.mu.Unlock()
+	return st
+}
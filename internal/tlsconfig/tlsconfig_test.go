@@ -0,0 +1,193 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issuer signs test certificates
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &issuer{cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for name, valid until notAfter
+func (ca *issuer) issue(t *testing.T, name string, notAfter time.Time, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, modTime, modTime)
+}
+
+func TestValidate(t *testing.T) {
+	for _, opts := range []Options{
+		{CertFile: "c.pem"},
+		{ClientCAFile: "ca.pem"},
+		{CertFile: "c.pem", KeyFile: "k.pem", ClientCAFile: "ca.pem", ClientAuth: "maybe"},
+		{MinVersion: "1.1"},
+		{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted", opts)
+		}
+	}
+	ok := Options{CertFile: "c.pem", KeyFile: "k.pem", MinVersion: "1.3",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("Validate(%+v) = %v", ok, err)
+	}
+}
+
+// A renewed certificate is served once reloaded, and a broken one is not
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newIssuer(t)
+	opts := Options{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	first := time.Now().Add(time.Hour).Truncate(time.Second)
+	cert, key := ca.issue(t, "localhost", first, x509.ExtKeyUsageServerAuth)
+	writeFile(t, opts.CertFile, cert, time.Now().Add(-time.Minute))
+	writeFile(t, opts.KeyFile, key, time.Now().Add(-time.Minute))
+
+	r, err := NewReloader(opts)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	if reloaded, err := r.Reload(); reloaded || err != nil {
+		t.Errorf("Reload of unchanged files = %v, %v", reloaded, err)
+	}
+
+	second := first.Add(time.Hour)
+	cert, key = ca.issue(t, "localhost", second, x509.ExtKeyUsageServerAuth)
+	writeFile(t, opts.CertFile, cert, time.Now())
+	writeFile(t, opts.KeyFile, key, time.Now())
+	if reloaded, err := r.Reload(); !reloaded || err != nil {
+		t.Fatalf("Reload after renewal = %v, %v", reloaded, err)
+	}
+	if got := r.Stats().NotAfter; !got.Equal(second) {
+		t.Errorf("Serving a certificate expiring %v, want %v", got, second)
+	}
+
+	writeFile(t, opts.KeyFile, []byte("not a key"), time.Now().Add(time.Minute))
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Expected a broken key to fail the reload")
+	}
+	if reloaded, err := r.Reload(); reloaded || err != nil {
+		t.Errorf("Reload of the same broken files = %v, %v; want them skipped", reloaded, err)
+	}
+	st := r.Stats()
+	if !st.NotAfter.Equal(second) || st.Failures != 1 || st.Reloads != 1 || st.LastError == "" {
+		t.Errorf("Stats after a failed reload = %+v", st)
+	}
+}
+
+// With a client CA, only clients presenting a certificate it signed connect
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newIssuer(t)
+	opts := Options{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	cert, key := ca.issue(t, "localhost", time.Now().Add(time.Hour), x509.ExtKeyUsageServerAuth)
+	writeFile(t, opts.CertFile, cert, time.Now())
+	writeFile(t, opts.KeyFile, key, time.Now())
+	writeFile(t, opts.ClientCAFile, ca.pem, time.Now())
+	r, err := NewReloader(opts)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = r.ServerConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(clientCerts []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: clientCerts}}}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(nil); err == nil {
+		t.Error("Expected a client without a certificate refused")
+	}
+	certPEM, keyPEM := ca.issue(t, "client", time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get([]tls.Certificate{clientCert}); err != nil {
+		t.Errorf("Client with a signed certificate refused: %v", err)
+	}
+	other := newIssuer(t)
+	certPEM, keyPEM = other.issue(t, "stranger", time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	stranger, _ := tls.X509KeyPair(certPEM, keyPEM)
+	if err := get([]tls.Certificate{stranger}); err == nil {
+		t.Error("Expected a certificate from another CA refused")
+	}
+}
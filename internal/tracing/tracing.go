@@ -77,9 +77,27 @@ func GetTracer(component string) trace.Tracer {
 	return otel.Tracer(fmt.Sprintf("%s/%s", serviceName, component))
 }
 
-// StartSpan creates a new span with common attributes
+// requestIDKey carries the API request ID in a context
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying the API request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the API request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// StartSpan creates a new span with common attributes, tagged with the
+// request ID when ctx carries one
 func StartSpan(ctx context.Context, tracer trace.Tracer, operationName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
 	ctx, span := tracer.Start(ctx, operationName)
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, attribute.String("request.id", id))
+	}
 	if len(attrs) > 0 {
 		span.SetAttributes(attrs...)
 	}
@@ -0,0 +1,54 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ObjectACL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/acl" || q.Get("tenant_id") != "tenant1" || q.Get("key") != "reports/q3.csv" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"key":"reports/q3.csv","version_id":"v1","owner":"tenant1","grants":[]}`))
+		case http.MethodPut:
+			var req ObjectACL
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Grants == nil {
+				t.Errorf("Unexpected body %+v, %v", req, err)
+			}
+			json.NewEncoder(w).Encode(ObjectACL{Key: "reports/q3.csv", VersionID: "v1", Owner: "tenant1", Grants: req.Grants})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if acl, err := client.GetObjectACL(ctx, "tenant1", "reports/q3.csv"); err != nil || acl.Owner != "tenant1" || len(acl.Grants) != 0 {
+		t.Errorf("GetObjectACL() = %+v, %v", acl, err)
+	}
+	grants := []Grant{
+		{Grantee: TenantGrantee("tenant2"), Permission: PermissionRead},
+		{Grantee: UserGrantee("sa-1"), Permission: PermissionFullControl},
+	}
+	acl, err := client.PutObjectACL(ctx, "tenant1", "reports/q3.csv", grants)
+	if err != nil || len(acl.Grants) != 2 || acl.Grants[0].Grantee != "tenant:tenant2" || acl.Grants[1].Grantee != "user:sa-1" {
+		t.Errorf("PutObjectACL() = %+v, %v", acl, err)
+	}
+	if _, err := client.PutObjectACL(ctx, "tenant1", "reports/q3.csv", nil); err != nil {
+		t.Errorf("PutObjectACL(nil) = %v", err)
+	}
+	if _, err := client.GetObjectACL(ctx, "", "reports/q3.csv"); err == nil {
+		t.Error("Expected an error for a missing tenant")
+	}
+}
@@ -0,0 +1,183 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AdminClient calls the server's admin API. Config.APIKey is the admin token.
+type AdminClient struct {
+	client *Client
+}
+
+// NewAdminClient creates a new admin API client
+func NewAdminClient(config Config) (*AdminClient, error) {
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AdminClient{client: client}, nil
+}
+
+// TenantSpec describes a tenant to provision
+type TenantSpec struct {
+	// Name must be unique; provisioning an existing name returns that tenant
+	Name string `json:"name"`
+
+	// Plan is the quota package (free, standard, enterprise; default: standard)
+	Plan string `json:"plan,omitempty"`
+
+	// Regions restricts where the tenant's data may be stored (default: any)
+	Regions []string `json:"regions,omitempty"`
+}
+
+// TenantResult is the outcome of provisioning one TenantSpec
+type TenantResult struct {
+	Name     string `json:"name"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Plan     string `json:"plan,omitempty"`
+	Existing bool   `json:"existing,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CreateTenantsResult reports a batch, with one result per spec in order
+type CreateTenantsResult struct {
+	Results  []TenantResult `json:"results"`
+	Created  int            `json:"created"`
+	Existing int            `json:"existing"`
+	Failed   int            `json:"failed"`
+}
+
+// CreateTenants provisions tenants from a manifest in one call. Entries
+// succeed or fail independently; check each TenantResult.Error.
+func (a *AdminClient) CreateTenants(ctx context.Context, specs []TenantSpec, reqOpts ...RequestOption) (*CreateTenantsResult, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("at least one tenant is required")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"tenants": specs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	var result CreateTenantsResult
+	if err := a.client.doWithRetry(ctx, "POST", "/admin/tenants/batch", bytes.NewReader(body), "application/json", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// HealthEvent is one transition in a node's health history, such as a
+// disk alert firing, a drain starting or a region's breaker opening
+type HealthEvent struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Subsystem string    `json:"subsystem"` // node, readiness, drain, breaker, disk_usage, clock_skew, selftest
+	Component string    `json:"component,omitempty"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Severity  string    `json:"severity"` // info, warning or critical
+	Message   string    `json:"message,omitempty"`
+}
+
+// HealthHistory is a node's health timeline, oldest event first, with
+// its current health
+type HealthHistory struct {
+	Node     HealthStatus      `json:"node"`
+	Breakers map[string]string `json:"breakers,omitempty"` // Region to closed, open or half_open
+	Total    uint64            `json:"total"`
+	Events   []HealthEvent     `json:"events"`
+}
+
+// HealthHistoryOptions selects health events; zero fields match everything
+type HealthHistoryOptions struct {
+	From, To    time.Time
+	Subsystem   string
+	Component   string
+	MinSeverity string // info, warning or critical
+	Limit       int    // Newest events to return (server default 200)
+}
+
+// HealthHistory reports the health timeline of the node the client is
+// connected to
+func (a *AdminClient) HealthHistory(ctx context.Context, opts HealthHistoryOptions, reqOpts ...RequestOption) (*HealthHistory, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	query := url.Values{}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339))
+	}
+	if opts.Subsystem != "" {
+		query.Set("subsystem", opts.Subsystem)
+	}
+	if opts.Component != "" {
+		query.Set("component", opts.Component)
+	}
+	if opts.MinSeverity != "" {
+		query.Set("severity", opts.MinSeverity)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var history HealthHistory
+	if err := a.client.doWithRetry(ctx, "GET", "/admin/health/history?"+query.Encode(), nil, "", &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// SupportBundleOptions selects what a support bundle holds
+type SupportBundleOptions struct {
+	NoProfiles bool // Leave out the goroutine and heap profiles
+	LogLines   int  // Latest log lines to include (default all the node keeps)
+}
+
+// SupportBundle downloads a support bundle of the node the client is
+// connected to: a tar.gz of its redacted configuration, recent logs, stats,
+// profiles and health history. The caller must close it.
+func (a *AdminClient) SupportBundle(ctx context.Context, opts SupportBundleOptions, reqOpts ...RequestOption) (io.ReadCloser, error) {
+	query := url.Values{}
+	if opts.NoProfiles {
+		query.Set("profiles", "false")
+	}
+	if opts.LogLines > 0 {
+		query.Set("logs", strconv.Itoa(opts.LogLines))
+	}
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	req, err := a.client.newRequest(ctx, "GET", "/admin/support/bundle?"+query.Encode(), nil, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := a.client.do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("support bundle failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("support bundle failed: %w", parseError(resp))
+	}
+	return &cancelOnClose{resp.Body, cancel}, nil
+}
+
+// Close releases the client's resources
+func (a *AdminClient) Close() error {
+	return a.client.Close()
+}
@@ -0,0 +1,118 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdminClient_CreateTenants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/admin/tenants/batch" {
+			t.Errorf("Expected POST /v1/admin/tenants/batch, got %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer admin-token" {
+			t.Errorf("Expected Authorization header 'Bearer admin-token', got %s", auth)
+		}
+
+		var req struct {
+			Tenants []TenantSpec `json:"tenants"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Tenants) != 2 {
+			t.Errorf("Expected manifest of 2 tenants, got %+v (%v)", req, err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"name":"acme","tenant_id":"tenant-1","plan":"free"},{"name":"globex","error":"unknown plan: gold"}],"created":1,"failed":1}`))
+	}))
+	defer server.Close()
+
+	admin, err := NewAdminClient(Config{Endpoint: server.URL, APIKey: "admin-token"})
+	if err != nil {
+		t.Fatalf("Failed to create admin client: %v", err)
+	}
+	defer admin.Close()
+
+	res, err := admin.CreateTenants(context.Background(), []TenantSpec{
+		{Name: "acme", Plan: "free", Regions: []string{"us-east-1"}},
+		{Name: "globex", Plan: "gold"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTenants() error = %v", err)
+	}
+	if res.Created != 1 || res.Failed != 1 || len(res.Results) != 2 {
+		t.Errorf("CreateTenants() = %+v, want 1 created and 1 failed", res)
+	}
+	if res.Results[0].TenantID != "tenant-1" || res.Results[1].Error == "" {
+		t.Errorf("CreateTenants() results = %+v", res.Results)
+	}
+}
+
+func TestAdminClient_HealthHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/admin/health/history" {
+			t.Errorf("Expected GET /v1/admin/health/history, got %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("subsystem") != "breaker" || q.Get("severity") != "warning" || q.Get("limit") != "50" ||
+			q.Get("from") != "2026-01-01T00:00:00Z" || q.Has("to") {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"node":{"status":"ok","score":1},"breakers":{"eu-west-1":"open"},"total":7,
+			"events":[{"seq":7,"time":"2026-01-01T00:05:00Z","subsystem":"breaker","component":"eu-west-1","from":"closed","to":"open","severity":"critical"}]}`))
+	}))
+	defer server.Close()
+
+	admin, err := NewAdminClient(Config{Endpoint: server.URL, APIKey: "admin-token"})
+	if err != nil {
+		t.Fatalf("Failed to create admin client: %v", err)
+	}
+	defer admin.Close()
+
+	h, err := admin.HealthHistory(context.Background(), HealthHistoryOptions{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Subsystem: "breaker", MinSeverity: "warning", Limit: 50,
+	})
+	if err != nil {
+		t.Fatalf("HealthHistory() error = %v", err)
+	}
+	if h.Node.Status != "ok" || h.Breakers["eu-west-1"] != "open" || h.Total != 7 || len(h.Events) != 1 {
+		t.Fatalf("HealthHistory() = %+v", h)
+	}
+	if ev := h.Events[0]; ev.To != "open" || ev.Component != "eu-west-1" || ev.Severity != "critical" {
+		t.Errorf("Event = %+v", ev)
+	}
+}
+
+func TestAdminClient_SupportBundle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/admin/support/bundle" {
+			t.Errorf("Expected GET /v1/admin/support/bundle, got %s %s", r.Method, r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Get("profiles") != "false" || q.Get("logs") != "100" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write([]byte("bundle"))
+	}))
+	defer server.Close()
+
+	admin, err := NewAdminClient(Config{Endpoint: server.URL, APIKey: "admin-token"})
+	if err != nil {
+		t.Fatalf("Failed to create admin client: %v", err)
+	}
+	defer admin.Close()
+
+	rc, err := admin.SupportBundle(context.Background(), SupportBundleOptions{NoProfiles: true, LogLines: 100})
+	if err != nil {
+		t.Fatalf("SupportBundle() error = %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil || string(data) != "bundle" {
+		t.Errorf("SupportBundle() read %q, %v", data, err)
+	}
+}
@@ -0,0 +1,110 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// BucketConfig is configuration scoped to one bucket, applied on top of
+// the tenant's settings
+type BucketConfig struct {
+	ObjectLock bool              `json:"object_lock"` // Objects may not be overwritten
+	AppendOnly bool              `json:"append_only"` // New keys only: no overwrite or delete
+	Tags       map[string]string `json:"tags,omitempty"`
+
+	// PublicRead serves the bucket's objects anonymously at PublicURL;
+	// with RefererTokens, only to pages on a host issued a referer token
+	PublicRead    bool `json:"public_read"`
+	RefererTokens bool `json:"referer_tokens"`
+}
+
+// BucketInfo is a tenant's bucket. It holds the keys that begin with its name
+// and a "/".
+type BucketInfo struct {
+	Name      string       `json:"name"`
+	TenantID  string       `json:"tenant_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Config    BucketConfig `json:"config"`
+}
+
+// CreateBucket creates a bucket in the tenant. Like the other bucket
+// calls, it needs credentials for the tenant.
+func (c *Client) CreateBucket(ctx context.Context, tenantID, name string, config BucketConfig, reqOpts ...RequestOption) (*BucketInfo, error) {
+	body, err := json.Marshal(map[string]interface{}{"name": name, "config": config})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bucket: %w", err)
+	}
+	var bucket BucketInfo
+	if err := c.bucketRequest(ctx, "POST", tenantID, "", body, &bucket, reqOpts); err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// ListBuckets lists the tenant's buckets by name
+func (c *Client) ListBuckets(ctx context.Context, tenantID string, reqOpts ...RequestOption) ([]BucketInfo, error) {
+	var buckets []BucketInfo
+	if err := c.bucketRequest(ctx, "GET", tenantID, "", nil, &buckets, reqOpts); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// GetBucket returns one of the tenant's buckets
+func (c *Client) GetBucket(ctx context.Context, tenantID, name string, reqOpts ...RequestOption) (*BucketInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("bucket name is required")
+	}
+	var bucket BucketInfo
+	if err := c.bucketRequest(ctx, "GET", tenantID, name, nil, &bucket, reqOpts); err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// UpdateBucketConfig replaces a bucket's configuration
+func (c *Client) UpdateBucketConfig(ctx context.Context, tenantID, name string, config BucketConfig, reqOpts ...RequestOption) (*BucketInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("bucket name is required")
+	}
+	body, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bucket config: %w", err)
+	}
+	var bucket BucketInfo
+	if err := c.bucketRequest(ctx, "PUT", tenantID, name, body, &bucket, reqOpts); err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// DeleteBucket deletes a bucket. The server refuses to delete a bucket
+// that still holds objects.
+func (c *Client) DeleteBucket(ctx context.Context, tenantID, name string, reqOpts ...RequestOption) error {
+	if name == "" {
+		return fmt.Errorf("bucket name is required")
+	}
+	return c.bucketRequest(ctx, "DELETE", tenantID, name, nil, nil, reqOpts)
+}
+
+func (c *Client) bucketRequest(ctx context.Context, method, tenantID, name string, body []byte, result interface{}, reqOpts []RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	query := url.Values{}
+	query.Set("tenant_id", tenantID)
+	if name != "" {
+		query.Set("bucket", name)
+	}
+	if body == nil {
+		return c.doWithRetry(ctx, method, "/buckets?"+query.Encode(), nil, "", result)
+	}
+	return c.doWithRetry(ctx, method, "/buckets?"+query.Encode(), bytes.NewReader(body), "application/json", result)
+}
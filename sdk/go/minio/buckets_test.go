@@ -0,0 +1,80 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Buckets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/buckets" || q.Get("tenant_id") != "tenant1" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch r.Method {
+		case http.MethodPost:
+			var req struct {
+				Name   string       `json:"name"`
+				Config BucketConfig `json:"config"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name != "photos" || !req.Config.ObjectLock {
+				t.Errorf("Unexpected create body %+v, %v", req, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name":"photos","tenant_id":"tenant1","config":{"object_lock":true}}`))
+		case http.MethodGet:
+			if q.Get("bucket") == "photos" {
+				w.Write([]byte(`{"name":"photos","tenant_id":"tenant1","config":{"object_lock":true}}`))
+				return
+			}
+			w.Write([]byte(`[{"name":"archive","tenant_id":"tenant1","config":{}},{"name":"photos","tenant_id":"tenant1","config":{"object_lock":true}}]`))
+		case http.MethodPut:
+			var config BucketConfig
+			if err := json.NewDecoder(r.Body).Decode(&config); err != nil || !config.AppendOnly || q.Get("bucket") != "photos" {
+				t.Errorf("Unexpected config %+v, %v", config, err)
+			}
+			w.Write([]byte(`{"name":"photos","tenant_id":"tenant1","config":{"append_only":true}}`))
+		case http.MethodDelete:
+			if q.Get("bucket") == "archive" {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error":"BucketNotEmpty","message":"Bucket is not empty"}`))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	b, err := client.CreateBucket(ctx, "tenant1", "photos", BucketConfig{ObjectLock: true})
+	if err != nil || b.Name != "photos" || !b.Config.ObjectLock {
+		t.Errorf("CreateBucket() = %+v, %v", b, err)
+	}
+	if list, err := client.ListBuckets(ctx, "tenant1"); err != nil || len(list) != 2 || list[0].Name != "archive" {
+		t.Errorf("ListBuckets() = %+v, %v", list, err)
+	}
+	if b, err := client.GetBucket(ctx, "tenant1", "photos"); err != nil || !b.Config.ObjectLock {
+		t.Errorf("GetBucket() = %+v, %v", b, err)
+	}
+	if b, err := client.UpdateBucketConfig(ctx, "tenant1", "photos", BucketConfig{AppendOnly: true}); err != nil || !b.Config.AppendOnly {
+		t.Errorf("UpdateBucketConfig() = %+v, %v", b, err)
+	}
+	if err := client.DeleteBucket(ctx, "tenant1", "photos"); err != nil {
+		t.Errorf("DeleteBucket() = %v", err)
+	}
+	if err := client.DeleteBucket(ctx, "tenant1", "archive"); err == nil {
+		t.Error("Expected an error deleting a non-empty bucket")
+	}
+	if _, err := client.GetBucket(ctx, "tenant1", ""); err == nil {
+		t.Error("Expected an error for a missing bucket name")
+	}
+}
@@ -0,0 +1,65 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Changes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/changes" || q.Get("tenant_id") != "tenant1" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch q.Get("since") {
+		case "":
+			w.Write([]byte(`{"changes":[{"seq":7,"type":"create","key":"a","size":3},{"seq":8,"type":"delete","key":"b"}],"next":8,"latest":9,"truncated":true}`))
+		case "8":
+			if q.Get("limit") != "10" || q.Get("wait") != "5s" {
+				t.Errorf("Expected limit and wait, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"changes":[{"seq":9,"type":"update","key":"a","size":4}],"next":9,"latest":9,"truncated":false}`))
+		default:
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"code":"ChangesExpired","message":"Changes after 1 are no longer retained; the oldest is 7"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	page, err := client.Changes(ctx, "tenant1", nil)
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(page.Changes) != 2 || page.Changes[1].Type != ChangeDelete || !page.Truncated {
+		t.Fatalf("Unexpected first page %+v", page)
+	}
+
+	page, err = client.Changes(ctx, "tenant1", &ChangesOptions{Since: page.Next, Limit: 10, Wait: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(page.Changes) != 1 || page.Changes[0].Seq != 9 || page.Truncated {
+		t.Fatalf("Unexpected second page %+v", page)
+	}
+
+	_, err = client.Changes(ctx, "tenant1", &ChangesOptions{Since: 1})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "ChangesExpired" || apiErr.StatusCode != http.StatusGone {
+		t.Errorf("Expected ChangesExpired, got %v", err)
+	}
+
+	if _, err := client.Changes(ctx, "", nil); err == nil {
+		t.Error("Expected error for a missing tenant")
+	}
+}
@@ -2,9 +2,9 @@
 package minio
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,12 +22,83 @@ const (
 
 	// DefaultBackoffMultiplier is the default backoff multiplier for retries
 	DefaultBackoffMultiplier = 2
+
+	// APIVersionPrefix is prepended to every request path; the server
+	// still answers unversioned paths but marks them deprecated
+	APIVersionPrefix = "/v1"
 )
 
+// ErrPreconditionFailed is returned when a conditional upload finds the
+// object at a different version than expected
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// ErrNotModified is returned when a download sent with If-None-Match or
+// If-Modified-Since finds the caller's copy current
+var ErrNotModified = errors.New("not modified")
+
+// Error is an error response from the server. Code is machine-readable and
+// stable across releases; Message is for people.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Resource   string `json:"resource,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %s (status %d", e.Code, e.Message, e.StatusCode)
+	if e.RequestID != "" {
+		msg += ", request " + e.RequestID
+	}
+	return msg + ")"
+}
+
+// Is matches ErrPreconditionFailed for failed conditional requests and
+// ErrNotModified for revalidated downloads
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrNotModified:
+		return e.StatusCode == http.StatusNotModified
+	}
+	return false
+}
+
+// parseError reads a non-2xx response into an *Error. Bodies that are not
+// error documents, such as from a proxy, become the message.
+func parseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	e := &Error{StatusCode: resp.StatusCode}
+	if json.Unmarshal(body, e) != nil || e.Code == "" {
+		e.Code = strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "")
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.RequestID == "" {
+		e.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return e
+}
+
+// requestIDKey carries a caller-chosen correlation ID in a context
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying a correlation ID that requests made
+// with it send as X-Request-ID. The server echoes it in responses and
+// records it in its audit and access logs; IDs longer than 64 bytes or
+// containing spaces or non-ASCII characters are replaced by a server ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
 // Client is the MinIO Enterprise SDK client
 type Client struct {
 	endpoint   string
-	apiKey     string
+	router     *router // nil with a single endpoint
+	hedge      *hedger
+	retries    *retryBudget
+	creds      *credentialCache
 	httpClient *http.Client
 	maxRetries int
 	backoff    time.Duration
@@ -38,9 +109,38 @@ type Config struct {
 	// Endpoint is the MinIO server endpoint (e.g., "http://localhost:9000")
 	Endpoint string
 
-	// APIKey is the authentication API key
+	// Endpoints are further nodes of the same deployment. With any set, or
+	// with DiscoverNodes, requests are spread over the nodes in proportion
+	// to the health scores each reports, polled every HealthCheckInterval
+	// (default: 10s); discovery also adds the nodes a server lists in
+	// cluster mode. A node that cannot be reached gets no requests until it
+	// next reports healthy.
+	Endpoints           []string
+	DiscoverNodes       bool
+	HealthCheckInterval time.Duration
+
+	// HedgeDelay, if set, hedges reads: a GET or HEAD not answered within
+	// HedgeDelay is sent again, to another node if there is one, and the
+	// first successful response wins. HedgeBudget caps hedges at that
+	// share of reads (default: 0.1), so hedging adds little load to a
+	// cluster that is slow across the board.
+	HedgeDelay  time.Duration
+	HedgeBudget float64
+
+	// APIKey is a fixed authentication API key.
+	//
+	// Deprecated: leave unset to use the default credential chain (see
+	// NewDefaultCredentials), or set Credentials to StaticCredentials.
 	APIKey string
 
+	// Credentials supplies the tokens requests are authenticated with
+	// (default: APIKey if set, else NewDefaultCredentials). Tokens are
+	// cached, refreshed in the background RefreshWindow before they expire
+	// (default: 5m), and refreshed at once if the server rejects one,
+	// retrying that request.
+	Credentials   CredentialsProvider
+	RefreshWindow time.Duration
+
 	// Timeout is the HTTP client timeout (default: 30s)
 	Timeout time.Duration
 
@@ -50,6 +150,13 @@ type Config struct {
 	// BackoffDuration is the initial backoff duration for retries (default: 1s)
 	BackoffDuration time.Duration
 
+	// RetryBudget caps retries across all of the client's calls at that
+	// share of calls (default: 0.1), saving up at most 10, so an outage is
+	// not amplified by every call retrying into it. A call the budget
+	// refuses fails with ErrRetryBudgetExhausted. Negative turns the
+	// budget off.
+	RetryBudget float64
+
 	// Transport allows customizing the HTTP transport
 	Transport http.RoundTripper
 }
@@ -60,8 +167,17 @@ func NewClient(config Config) (*Client, error) {
 		return nil, fmt.Errorf("endpoint is required")
 	}
 
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("API key is required")
+	credentials := config.Credentials
+	switch {
+	case credentials != nil:
+	case config.APIKey != "":
+		credentials = StaticCredentials(config.APIKey)
+	default:
+		credentials = NewDefaultCredentials(config.Endpoint)
+	}
+
+	if config.RefreshWindow == 0 {
+		config.RefreshWindow = DefaultRefreshWindow
 	}
 
 	// Set defaults
@@ -77,6 +193,14 @@ func NewClient(config Config) (*Client, error) {
 		config.BackoffDuration = time.Second
 	}
 
+	if config.HedgeBudget == 0 {
+		config.HedgeBudget = DefaultHedgeBudget
+	}
+
+	if config.RetryBudget == 0 {
+		config.RetryBudget = DefaultRetryBudget
+	}
+
 	// Create HTTP client
 	transport := config.Transport
 	if transport == nil {
@@ -92,13 +216,23 @@ func NewClient(config Config) (*Client, error) {
 		Transport: transport,
 	}
 
-	return &Client{
+	c := &Client{
 		endpoint:   strings.TrimSuffix(config.Endpoint, "/"),
-		apiKey:     config.APIKey,
+		creds:      newCredentialCache(credentials, config.RefreshWindow),
 		httpClient: httpClient,
 		maxRetries: config.MaxRetries,
 		backoff:    config.BackoffDuration,
-	}, nil
+		hedge:      newHedger(config.HedgeDelay, config.HedgeBudget),
+		retries:    newRetryBudget(config.RetryBudget),
+	}
+	if len(config.Endpoints) > 0 || config.DiscoverNodes {
+		if config.HealthCheckInterval == 0 {
+			config.HealthCheckInterval = DefaultHealthCheckInterval
+		}
+		endpoints := append([]string{config.Endpoint}, config.Endpoints...)
+		c.router = newRouter(endpoints, httpClient, config.HealthCheckInterval)
+	}
+	return c, nil
 }
 
 // Object represents a MinIO object
@@ -108,6 +242,11 @@ type Object struct {
 	LastModified time.Time `json:"last_modified"`
 	ContentType  string    `json:"content_type"`
 	ETag         string    `json:"etag"`
+	VersionID    string    `json:"version_id,omitempty"`
+
+	// Metadata and Tags are set by Stat and List
+	Metadata map[string]string `json:"user_meta,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
 }
 
 // UploadOptions contains options for uploading objects
@@ -115,12 +254,28 @@ type UploadOptions struct {
 	// ContentType specifies the MIME type of the object
 	ContentType string
 
-	// Metadata contains custom metadata key-value pairs
+	// Metadata contains custom metadata key-value pairs, sent as
+	// X-Amz-Meta-* headers; the server lowercases the keys
 	Metadata map[string]string
+
+	// Tags label the object (at most 10), for replication rules and
+	// PutObjectTagging
+	Tags map[string]string
+
+	// IfVersion makes the upload succeed only if the object's current
+	// version (Object.VersionID) or ETag matches; otherwise
+	// ErrPreconditionFailed
+	IfVersion string
+
+	// IfAbsent makes the upload succeed only if the key does not exist
+	IfAbsent bool
 }
 
 // Upload uploads an object to MinIO
-func (c *Client) Upload(ctx context.Context, tenantID, key string, data io.Reader, opts *UploadOptions) error {
+func (c *Client) Upload(ctx context.Context, tenantID, key string, data io.Reader, opts *UploadOptions, reqOpts ...RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
 	if tenantID == "" {
 		return fmt.Errorf("tenant ID is required")
 	}
@@ -139,12 +294,23 @@ func (c *Client) Upload(ctx context.Context, tenantID, key string, data io.Reade
 
 	// Build request
 	path := fmt.Sprintf("/upload?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+	if opts.IfVersion != "" {
+		path += "&if_version=" + url.QueryEscape(opts.IfVersion)
+	}
+	if opts.IfAbsent {
+		path += "&if_absent=true"
+	}
+	ctx, cancelAttrs := withOptions(ctx, opts.attributeHeaders())
+	defer cancelAttrs()
 
 	return c.doWithRetry(ctx, "PUT", path, data, opts.ContentType, nil)
 }
 
-// Download downloads an object from MinIO
-func (c *Client) Download(ctx context.Context, tenantID, key string) (io.ReadCloser, error) {
+// Download downloads an object from MinIO. Conditional headers set with
+// WithHeader are honored: If-None-Match or If-Modified-Since make it return
+// ErrNotModified when the object is unchanged, and If-Match or
+// If-Unmodified-Since ErrPreconditionFailed when it has changed.
+func (c *Client) Download(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) (io.ReadCloser, error) {
 	if tenantID == "" {
 		return nil, fmt.Errorf("tenant ID is required")
 	}
@@ -155,27 +321,56 @@ func (c *Client) Download(ctx context.Context, tenantID, key string) (io.ReadClo
 
 	path := fmt.Sprintf("/download?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
 
+	ctx, cancel := withOptions(ctx, reqOpts)
 	req, err := c.newRequest(ctx, "GET", path, nil, "")
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("download failed: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("download failed with status %d: %s", resp.StatusCode, string(body))
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("download failed: %w", parseError(resp))
+	}
+
+	return &cancelOnClose{resp.Body, cancel}, nil
+}
+
+// Stat returns an object's metadata, including the VersionID to pass as
+// UploadOptions.IfVersion for a read-modify-write
+func (c *Client) Stat(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) (*Object, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("object key is required")
 	}
 
-	return resp.Body, nil
+	path := fmt.Sprintf("/stat?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+
+	var obj Object
+	if err := c.doWithRetry(ctx, "GET", path, nil, "", &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
 }
 
 // Delete deletes an object from MinIO
-func (c *Client) Delete(ctx context.Context, tenantID, key string) error {
+func (c *Client) Delete(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
 	if tenantID == "" {
 		return fmt.Errorf("tenant ID is required")
 	}
@@ -189,6 +384,282 @@ func (c *Client) Delete(ctx context.Context, tenantID, key string) error {
 	return c.doWithRetry(ctx, "DELETE", path, nil, "", nil)
 }
 
+// MaxDeleteObjects is the most keys one DeleteObjects call accepts
+const MaxDeleteObjects = 1000
+
+// DeleteResult is the outcome of deleting one key of a batch
+type DeleteResult struct {
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DeleteObjectsResult reports a batch delete. Results lists every key in
+// order, or only the failures when the batch was quiet.
+type DeleteObjectsResult struct {
+	Deleted int            `json:"deleted"`
+	Failed  int            `json:"failed"`
+	Results []DeleteResult `json:"results"`
+}
+
+// DeleteObjects deletes up to MaxDeleteObjects keys from tenantID in one
+// request. Keys succeed or fail independently; check each DeleteResult.
+// With quiet, successful deletes are left out of Results.
+func (c *Client) DeleteObjects(ctx context.Context, tenantID string, keys []string, quiet bool, reqOpts ...RequestOption) (*DeleteObjectsResult, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if len(keys) == 0 || len(keys) > MaxDeleteObjects {
+		return nil, fmt.Errorf("between 1 and %d keys are required", MaxDeleteObjects)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"keys": keys, "quiet": quiet})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	path := fmt.Sprintf("/delete-batch?tenant_id=%s", url.QueryEscape(tenantID))
+
+	var result DeleteObjectsResult
+	if err := c.doWithRetry(ctx, "POST", path, strings.NewReader(string(payload)), "application/json", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CopySource identifies the object a server-side copy reads from
+type CopySource struct {
+	// TenantID owns the source object; empty means the destination tenant.
+	// Copying from another tenant requires a share grant from that tenant.
+	TenantID string
+
+	// Key is the source object key
+	Key string
+}
+
+// Copy copies src to key in tenantID without transferring the data through the client.
+// The destination tenant's storage quota is charged for the copy, but not
+// its ingress. The copy keeps the source's metadata and tags; to replace
+// them, pass WithHeader("X-Amz-Metadata-Directive", "REPLACE") with the new
+// X-Amz-Meta-* and X-Amz-Tagging headers. If-Match and If-None-Match set
+// with WithHeader apply to the destination.
+func (c *Client) Copy(ctx context.Context, tenantID, key string, src CopySource, reqOpts ...RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	if key == "" || src.Key == "" {
+		return fmt.Errorf("source and destination object keys are required")
+	}
+
+	path := fmt.Sprintf("/copy?tenant_id=%s&key=%s&source_key=%s",
+		url.QueryEscape(tenantID), url.QueryEscape(key), url.QueryEscape(src.Key))
+
+	if src.TenantID != "" {
+		path += fmt.Sprintf("&source_tenant=%s", url.QueryEscape(src.TenantID))
+	}
+
+	return c.doWithRetry(ctx, "PUT", path, nil, "", nil)
+}
+
+// RenameOptions controls a rename or prefix move
+type RenameOptions struct {
+	// Overwrite replaces existing destination objects (default: fail with 409)
+	Overwrite bool
+}
+
+// Rename moves srcKey to dstKey within tenantID. Unencrypted objects are
+// moved without copying their data; encrypted objects are copied then deleted.
+func (c *Client) Rename(ctx context.Context, tenantID, srcKey, dstKey string, opts *RenameOptions, reqOpts ...RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	if srcKey == "" || dstKey == "" {
+		return fmt.Errorf("source and destination object keys are required")
+	}
+
+	path := fmt.Sprintf("/rename?tenant_id=%s&source_key=%s&key=%s",
+		url.QueryEscape(tenantID), url.QueryEscape(srcKey), url.QueryEscape(dstKey))
+
+	if opts != nil && opts.Overwrite {
+		path += "&overwrite=true"
+	}
+
+	return c.doWithRetry(ctx, "POST", path, nil, "", nil)
+}
+
+// RenameFailure is a key a prefix move could not rename
+type RenameFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// RenamePrefixResult reports a prefix move
+type RenamePrefixResult struct {
+	Moved   int             `json:"moved"`
+	InPlace int             `json:"in_place"`
+	Copied  int             `json:"copied"`
+	Failed  []RenameFailure `json:"failed"`
+}
+
+// RenamePrefix moves every object under srcPrefix to the same suffix under
+// dstPrefix ("folder" rename). Keys are moved independently; check Failed.
+func (c *Client) RenamePrefix(ctx context.Context, tenantID, srcPrefix, dstPrefix string, opts *RenameOptions, reqOpts ...RequestOption) (*RenamePrefixResult, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if srcPrefix == "" || dstPrefix == "" {
+		return nil, fmt.Errorf("source and destination prefixes are required")
+	}
+
+	path := fmt.Sprintf("/rename?tenant_id=%s&source_prefix=%s&prefix=%s",
+		url.QueryEscape(tenantID), url.QueryEscape(srcPrefix), url.QueryEscape(dstPrefix))
+
+	if opts != nil && opts.Overwrite {
+		path += "&overwrite=true"
+	}
+
+	var result RenamePrefixResult
+	if err := c.doWithRetry(ctx, "POST", path, nil, "", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ShareLinkOptions restricts how a share link may be used
+type ShareLinkOptions struct {
+	// ExpiresIn is how long the link stays valid (default: no expiry)
+	ExpiresIn time.Duration
+
+	// MaxDownloads limits how many times the link can be used (default: unlimited)
+	MaxDownloads int64
+
+	// Password must be presented by anyone downloading through the link
+	Password string
+}
+
+// ShareLink is a server-stored, revocable download link for one object
+type ShareLink struct {
+	ID                string    `json:"id"` // Names the link in audit events
+	Token             string    `json:"token"`
+	TenantID          string    `json:"tenant_id"`
+	Key               string    `json:"key"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at,omitempty"`
+	MaxDownloads      int64     `json:"max_downloads,omitempty"`
+	Downloads         int64     `json:"downloads"`
+	PasswordProtected bool      `json:"password_protected"`
+	CreatedBy         string    `json:"created_by,omitempty"` // Principal that created the link
+	URL               string    `json:"url,omitempty"`        // Redeems the link; on the tenant's virtual host when enabled
+}
+
+// CreateShareLink creates a managed share link for an object
+func (c *Client) CreateShareLink(ctx context.Context, tenantID, key string, opts *ShareLinkOptions, reqOpts ...RequestOption) (*ShareLink, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("object key is required")
+	}
+
+	if opts == nil {
+		opts = &ShareLinkOptions{}
+	}
+
+	body := map[string]interface{}{
+		"max_downloads": opts.MaxDownloads,
+		"password":      opts.Password,
+	}
+	if opts.ExpiresIn > 0 {
+		body["expires_in"] = opts.ExpiresIn.String()
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode share link options: %w", err)
+	}
+
+	path := fmt.Sprintf("/share?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+
+	var link ShareLink
+	if err := c.doWithRetry(ctx, "POST", path, strings.NewReader(string(payload)), "application/json", &link); err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// RevokeShareLink revokes one of the tenant's share links
+func (c *Client) RevokeShareLink(ctx context.Context, tenantID, token string, reqOpts ...RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	if token == "" {
+		return fmt.Errorf("share token is required")
+	}
+
+	path := fmt.Sprintf("/share?tenant_id=%s&token=%s", url.QueryEscape(tenantID), url.QueryEscape(token))
+
+	return c.doWithRetry(ctx, "DELETE", path, nil, "", nil)
+}
+
+// DownloadShared downloads the object behind a share link
+func (c *Client) DownloadShared(ctx context.Context, token, password string, reqOpts ...RequestOption) (io.ReadCloser, error) {
+	if token == "" {
+		return nil, fmt.Errorf("share token is required")
+	}
+
+	path := fmt.Sprintf("/download?share=%s", url.QueryEscape(token))
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	req, err := c.newRequest(ctx, "GET", path, nil, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if password != "" {
+		req.Header.Set("X-Share-Password", password)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("download failed: %w", parseError(resp))
+	}
+
+	return &cancelOnClose{resp.Body, cancel}, nil
+}
+
 // ListOptions contains options for listing objects
 type ListOptions struct {
 	// Prefix filters objects by key prefix
@@ -196,16 +667,39 @@ type ListOptions struct {
 
 	// MaxKeys limits the number of results (default: 1000)
 	MaxKeys int
+
+	// Strict waits until earlier writes have replicated before listing
+	Strict bool
+
+	// ContinuationToken resumes after the previous page (NextContinuationToken)
+	ContinuationToken string
+
+	// StartAfter lists only keys that sort after it; ContinuationToken takes
+	// precedence
+	StartAfter string
+
+	// Delimiter rolls keys containing it past Prefix up into CommonPrefixes
+	Delimiter string
 }
 
 // ListResponse contains the list of objects
 type ListResponse struct {
-	Objects []Object `json:"objects"`
-	Count   int      `json:"count"`
+	Objects     []Object `json:"objects"`
+	Count       int      `json:"count"`
+	IsTruncated bool     `json:"is_truncated"`
+
+	// CommonPrefixes holds the rolled-up "folders" when Delimiter is set
+	CommonPrefixes []string `json:"common_prefixes,omitempty"`
+
+	// NextContinuationToken fetches the following page when IsTruncated
+	NextContinuationToken string `json:"next_continuation_token,omitempty"`
 }
 
 // List lists objects in a tenant's storage
-func (c *Client) List(ctx context.Context, tenantID string, opts *ListOptions) (*ListResponse, error) {
+func (c *Client) List(ctx context.Context, tenantID string, opts *ListOptions, reqOpts ...RequestOption) (*ListResponse, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
 	if tenantID == "" {
 		return nil, fmt.Errorf("tenant ID is required")
 	}
@@ -224,6 +718,22 @@ func (c *Client) List(ctx context.Context, tenantID string, opts *ListOptions) (
 		path += fmt.Sprintf("&max_keys=%d", opts.MaxKeys)
 	}
 
+	if opts.Delimiter != "" {
+		path += fmt.Sprintf("&delimiter=%s", url.QueryEscape(opts.Delimiter))
+	}
+
+	if opts.Strict {
+		path += "&strict=true"
+	}
+
+	if opts.ContinuationToken != "" {
+		path += fmt.Sprintf("&continuation_token=%s", url.QueryEscape(opts.ContinuationToken))
+	}
+
+	if opts.StartAfter != "" {
+		path += fmt.Sprintf("&marker=%s", url.QueryEscape(opts.StartAfter))
+	}
+
 	var listResp ListResponse
 	if err := c.doWithRetry(ctx, "GET", path, nil, "", &listResp); err != nil {
 		return nil, err
@@ -232,6 +742,41 @@ func (c *Client) List(ctx context.Context, tenantID string, opts *ListOptions) (
 	return &listResp, nil
 }
 
+// PrefixUsage totals the objects at or below a prefix
+type PrefixUsage struct {
+	Prefix  string `json:"prefix"`
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// PrefixAggregate breaks a prefix's totals down by its child prefixes
+type PrefixAggregate struct {
+	PrefixUsage
+	DirectObjects int64         `json:"direct_objects"`
+	DirectBytes   int64         `json:"direct_bytes"`
+	Prefixes      []PrefixUsage `json:"prefixes"`
+}
+
+// AggregatePrefix returns object counts and bytes under prefix (empty or
+// ending in "/") for each "/"-delimited child prefix, without listing keys
+func (c *Client) AggregatePrefix(ctx context.Context, tenantID, prefix string, reqOpts ...RequestOption) (*PrefixAggregate, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	path := fmt.Sprintf("/list?tenant_id=%s&aggregate=prefix&delimiter=%s&prefix=%s",
+		url.QueryEscape(tenantID), url.QueryEscape("/"), url.QueryEscape(prefix))
+
+	var agg PrefixAggregate
+	if err := c.doWithRetry(ctx, "GET", path, nil, "", &agg); err != nil {
+		return nil, err
+	}
+	return &agg, nil
+}
+
 // QuotaInfo contains tenant quota information
 type QuotaInfo struct {
 	TenantID   string `json:"tenant_id"`
@@ -241,7 +786,10 @@ type QuotaInfo struct {
 }
 
 // GetQuota retrieves the quota information for a tenant
-func (c *Client) GetQuota(ctx context.Context, tenantID string) (*QuotaInfo, error) {
+func (c *Client) GetQuota(ctx context.Context, tenantID string, reqOpts ...RequestOption) (*QuotaInfo, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
 	if tenantID == "" {
 		return nil, fmt.Errorf("tenant ID is required")
 	}
@@ -260,10 +808,21 @@ func (c *Client) GetQuota(ctx context.Context, tenantID string) (*QuotaInfo, err
 type HealthStatus struct {
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
+
+	// Node is the URL the node answering is reached at, if configured.
+	// Score ranks it for new requests from 0 (draining) to 1 (idle), and
+	// Nodes lists the cluster's nodes in cluster mode.
+	Node  string   `json:"node,omitempty"`
+	Score float64  `json:"score"`
+	Load  float64  `json:"load"`
+	Nodes []string `json:"nodes,omitempty"`
 }
 
 // Health checks the health of the MinIO service
-func (c *Client) Health(ctx context.Context) (*HealthStatus, error) {
+func (c *Client) Health(ctx context.Context, reqOpts ...RequestOption) (*HealthStatus, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
 	var health HealthStatus
 	if err := c.doWithRetry(ctx, "GET", "/health", nil, "", &health); err != nil {
 		return nil, err
@@ -275,10 +834,16 @@ func (c *Client) Health(ctx context.Context) (*HealthStatus, error) {
 // doWithRetry executes an HTTP request with retry logic
 func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.Reader, contentType string, result interface{}) error {
 	var lastErr error
-	backoff := c.backoff
+	policy := c.retryPolicy(ctx)
+	backoff := policy.Backoff
+	c.retries.earn()
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
 		if attempt > 0 {
+			if !c.retries.spend() {
+				return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr)
+			}
+
 			// Wait before retrying
 			select {
 			case <-ctx.Done():
@@ -292,15 +857,25 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.R
 
 		// Create request
 		var bodyReader io.Reader
+		size := int64(-1)
 		if body != nil {
 			// For retries, we need to be able to re-read the body
 			// In production, consider using a seeker or buffering
 			if seeker, ok := body.(io.Seeker); ok {
+				// Seekable bodies declare their length: servers refuse
+				// chunked uploads unless configured to take them
+				if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+					size = end
+				}
 				seeker.Seek(0, io.SeekStart)
 				bodyReader = body
 			} else {
 				bodyReader = body
 			}
+			// The transport closes request bodies; files stay open for retries
+			if _, ok := body.(io.Closer); ok {
+				bodyReader = io.NopCloser(bodyReader)
+			}
 		}
 
 		req, err := c.newRequest(ctx, method, path, bodyReader, contentType)
@@ -308,8 +883,14 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.R
 			lastErr = err
 			continue
 		}
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+		if size >= 0 {
+			req.ContentLength = size
+		}
 
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			lastErr = fmt.Errorf("request failed: %w", err)
 			continue
@@ -319,13 +900,12 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.R
 
 		// Check if we should retry based on status code
 		if c.shouldRetry(resp.StatusCode) {
-			respBody, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+			lastErr = parseError(resp)
 			continue
 		}
 
 		// Success - parse response if result is provided
-		if result != nil && resp.StatusCode == http.StatusOK {
+		if result != nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated) {
 			respBody, err := io.ReadAll(resp.Body)
 			if err != nil {
 				return fmt.Errorf("failed to read response: %w", err)
@@ -338,8 +918,7 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.R
 
 		// Check for non-200 status codes that shouldn't be retried
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			respBody, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+			return parseError(resp)
 		}
 
 		return nil
@@ -350,15 +929,26 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, body io.R
 
 // newRequest creates a new HTTP request
 func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Request, error) {
-	url := c.endpoint + path
+	endpoint := c.endpoint
+	if c.router != nil {
+		endpoint = c.router.pick()
+	}
+	url := endpoint + APIVersionPrefix + path
 
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	token, err := c.creds.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if id, _ := ctx.Value(requestIDKey{}).(string); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
 
 	if contentType != "" {
 		req.Header.Set("Content-Type", contentType)
@@ -366,9 +956,60 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 		req.Header.Set("Content-Type", "application/octet-stream")
 	}
 
+	// Per-call headers override the defaults above, but not the credentials
+	for name, values := range optionHeader(ctx) {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
 	return req, nil
 }
 
+// do sends req, hedged if it is a read and hedging is on, retrying it once
+// with refreshed credentials if the server rejects its token. Requests
+// whose body cannot be replayed are not retried.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	if delay := c.hedgeDelay(req); delay > 0 {
+		resp, err = c.sendHedged(req, delay)
+	} else {
+		resp, err = c.send(req)
+	}
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	if !c.creds.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")) {
+		return resp, nil
+	}
+	token, err := c.creds.token(req.Context())
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	resp.Body.Close()
+	return c.send(retry)
+}
+
+// send sends req, taking its node out of rotation if it cannot be reached
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil && c.router != nil && req.Context().Err() == nil {
+		c.router.failed(req.URL.String())
+	}
+	return resp, err
+}
+
 // shouldRetry determines if a request should be retried based on status code
 func (c *Client) shouldRetry(statusCode int) bool {
 	// Retry on server errors and rate limiting
@@ -378,8 +1019,22 @@ func (c *Client) shouldRetry(statusCode int) bool {
 		statusCode >= 500
 }
 
+// Nodes reports the nodes requests are spread over and their scores, or
+// nil for a client with a single endpoint
+func (c *Client) Nodes() []NodeStatus {
+	if c.router == nil {
+		return nil
+	}
+	return c.router.status()
+}
+
 // Close closes the client and releases resources
 func (c *Client) Close() error {
+	c.creds.close()
+	if c.router != nil {
+		c.router.close()
+	}
+
 	// Close idle connections
 	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
 		transport.CloseIdleConnections()
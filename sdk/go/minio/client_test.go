@@ -3,9 +3,13 @@ package minio
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -32,11 +36,11 @@ func TestNewClient(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "missing api key",
+			name: "default credential chain",
 			config: Config{
 				Endpoint: "http://localhost:9000",
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "custom timeout",
@@ -107,6 +111,102 @@ func TestClient_Upload(t *testing.T) {
 	}
 }
 
+func TestClient_UploadIfVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("if_version") != "v1" {
+			http.Error(w, "Object version does not match", http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.Upload(context.Background(), "tenant1", "test.txt", bytes.NewReader([]byte("a")), &UploadOptions{IfVersion: "v1"})
+	if err != nil {
+		t.Errorf("Upload() with matching version error = %v", err)
+	}
+
+	err = client.Upload(context.Background(), "tenant1", "test.txt", bytes.NewReader([]byte("b")), &UploadOptions{IfVersion: "v0"})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Upload() with stale version error = %v, want ErrPreconditionFailed", err)
+	}
+}
+
+func TestClient_UploadFileDeclaresLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.ContentLength != 9 || len(r.TransferEncoding) != 0 || string(body) != "test data" {
+			t.Errorf("Upload sent Content-Length %d, Transfer-Encoding %v, body %q", r.ContentLength, r.TransferEncoding, body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("test data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := client.Upload(context.Background(), "tenant1", "test.txt", f, nil); err != nil {
+		t.Errorf("Upload() error = %v", err)
+	}
+}
+
+func TestClient_DownloadConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("If-None-Match") == `"abc"`:
+			w.WriteHeader(http.StatusNotModified)
+		case r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"abc"`:
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"code":"PreconditionFailed","message":"Object version does not match"}`))
+		default:
+			w.Header().Set("ETag", `"abc"`)
+			w.Write([]byte("data"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	_, err = client.Download(context.Background(), "tenant1", "k", WithHeader("If-None-Match", `"abc"`))
+	if !errors.Is(err, ErrNotModified) {
+		t.Errorf("Download() with current ETag error = %v, want ErrNotModified", err)
+	}
+	_, err = client.Download(context.Background(), "tenant1", "k", WithHeader("If-Match", `"old"`))
+	if !errors.Is(err, ErrPreconditionFailed) || errors.Is(err, ErrNotModified) {
+		t.Errorf("Download() with stale ETag error = %v, want ErrPreconditionFailed", err)
+	}
+	reader, err := client.Download(context.Background(), "tenant1", "k", WithHeader("If-None-Match", `"old"`))
+	if err != nil {
+		t.Fatalf("Download() with stale ETag error = %v", err)
+	}
+	reader.Close()
+}
+
 func TestClient_Download(t *testing.T) {
 	expectedData := []byte("test file content")
 
@@ -182,6 +282,221 @@ func TestClient_Delete(t *testing.T) {
 	}
 }
 
+func TestClient_DeleteObjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/delete-batch" || r.URL.Query().Get("tenant_id") != "tenant1" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Keys  []string `json:"keys"`
+			Quiet bool     `json:"quiet"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Keys) != 2 || !req.Quiet {
+			t.Errorf("Unexpected body %+v, %v", req, err)
+		}
+		w.Write([]byte(`{"deleted":1,"failed":1,"results":[{"key":"b","status":404,"code":"NoSuchKey","error":"Object not found"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	res, err := client.DeleteObjects(context.Background(), "tenant1", []string{"a", "b"}, true)
+	if err != nil {
+		t.Fatalf("DeleteObjects() error = %v", err)
+	}
+	if res.Deleted != 1 || res.Failed != 1 || len(res.Results) != 1 || res.Results[0].Code != "NoSuchKey" {
+		t.Errorf("DeleteObjects() = %+v", res)
+	}
+
+	if _, err := client.DeleteObjects(context.Background(), "tenant1", nil, false); err == nil {
+		t.Error("Expected an error for an empty batch")
+	}
+}
+
+func TestClient_RequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("Expected no X-Request-ID without one in the context, got %q", got)
+	}
+
+	ctx := WithRequestID(context.Background(), "job-42")
+	if err := client.Delete(ctx, "tenant1", "test.txt"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got != "job-42" {
+		t.Errorf("Expected X-Request-ID job-42, got %q", got)
+	}
+}
+
+func TestClient_ErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":"QuotaExceeded","message":"Quota exceeded","resource":"/tenant1/test.txt","requestId":"req-1"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.Delete(context.Background(), "tenant1", "test.txt")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Delete() error = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Code != "QuotaExceeded" ||
+		apiErr.Resource != "/tenant1/test.txt" || apiErr.RequestID != "req-1" {
+		t.Errorf("Unexpected error %+v", apiErr)
+	}
+}
+
+func TestClient_Copy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("Expected PUT request, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/v1/copy" {
+			t.Errorf("Expected path /v1/copy, got %s", r.URL.Path)
+		}
+
+		query := r.URL.Query()
+		if query.Get("tenant_id") != "tenant2" || query.Get("key") != "copy.txt" {
+			t.Errorf("Unexpected destination %s/%s", query.Get("tenant_id"), query.Get("key"))
+		}
+		if query.Get("source_tenant") != "tenant1" || query.Get("source_key") != "shared/test.txt" {
+			t.Errorf("Unexpected source %s/%s", query.Get("source_tenant"), query.Get("source_key"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.Copy(context.Background(), "tenant2", "copy.txt", CopySource{TenantID: "tenant1", Key: "shared/test.txt"})
+	if err != nil {
+		t.Errorf("Copy() error = %v", err)
+	}
+
+	if err := client.Copy(context.Background(), "tenant2", "copy.txt", CopySource{}); err == nil {
+		t.Error("Copy() expected error for missing source key")
+	}
+}
+
+func TestClient_RenamePrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+
+		q := r.URL.Query()
+		if q.Get("source_prefix") != "docs/" || q.Get("prefix") != "archive/docs/" || q.Get("overwrite") != "true" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"moved":2,"in_place":1,"copied":1,"failed":[{"key":"docs/c","error":"Object is locked"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	res, err := client.RenamePrefix(context.Background(), "tenant1", "docs/", "archive/docs/", &RenameOptions{Overwrite: true})
+	if err != nil {
+		t.Fatalf("RenamePrefix() error = %v", err)
+	}
+
+	if res.Moved != 2 || len(res.Failed) != 1 {
+		t.Errorf("RenamePrefix() = %+v, want 2 moved and 1 failed", res)
+	}
+}
+
+func TestClient_CreateShareLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if body["expires_in"] != "1h0m0s" || body["max_downloads"] != float64(3) {
+			t.Errorf("Unexpected share options %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"token":"abc123","tenant_id":"tenant1","key":"test.txt","max_downloads":3,"downloads":0,"password_protected":true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint: server.URL,
+		APIKey:   "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	link, err := client.CreateShareLink(context.Background(), "tenant1", "test.txt", &ShareLinkOptions{
+		ExpiresIn:    time.Hour,
+		MaxDownloads: 3,
+		Password:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("CreateShareLink() error = %v", err)
+	}
+
+	if link.Token != "abc123" || !link.PasswordProtected {
+		t.Errorf("Unexpected share link %+v", link)
+	}
+}
+
 func TestClient_List(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
@@ -0,0 +1,180 @@
+// cmd/minio-cli/main.go
+// Command-line client for tenant object operations, built on the Go SDK
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abiolaogu/MinIO/sdk/go/minio"
+)
+
+// bucketScheme marks a sync location as a tenant's bucket rather than a
+// local directory: minio://<tenant>/<prefix>
+const bucketScheme = "minio://"
+
+// command is one subcommand
+type command struct {
+	usage string
+	run   func(ctx context.Context, c *minio.Client, args []string) error
+}
+
+var commands = map[string]command{
+	"sync": {"[-delete] [-dry-run] [-bwlimit 10M] [-concurrency 4] SRC DST", syncCmd},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: minio-cli [-endpoint URL] <command> [flags]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-6s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nSRC and DST are local directories or %s<tenant>/<prefix>.\n"+
+		"Credentials come from the SDK's default chain (e.g. MINIO_API_KEY).\n", bucketScheme)
+	os.Exit(2)
+}
+
+func main() {
+	endpoint := flag.String("endpoint", envOr("MINIO_ENDPOINT", "http://localhost:9000"), "server URL")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		usage()
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage()
+	}
+
+	client, err := minio.NewClient(minio.Config{Endpoint: *endpoint})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	// Interrupting stops new transfers; finished files stay in place
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, client, args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// ========== sync ==========
+
+func syncCmd(ctx context.Context, c *minio.Client, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	del := fs.Bool("delete", false, "delete destination files missing from the source")
+	dryRun := fs.Bool("dry-run", false, "list changes without making them")
+	bwlimit := fs.String("bwlimit", "", "bandwidth cap per second, e.g. 512K or 10M")
+	concurrency := fs.Int("concurrency", minio.DefaultConcurrency, "files transferred at once")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("sync takes a source and a destination")
+	}
+	limit, err := parseBytes(*bwlimit)
+	if err != nil {
+		return fmt.Errorf("-bwlimit: %w", err)
+	}
+	src, err := parseLocation(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	dst, err := parseLocation(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	result, err := c.Sync(ctx, src, dst, &minio.SyncOptions{
+		Delete:         *del,
+		DryRun:         *dryRun,
+		Concurrency:    *concurrency,
+		BandwidthLimit: limit,
+	})
+	if result != nil {
+		for _, e := range result.Entries {
+			switch {
+			case e.Err != nil:
+				fmt.Printf("failed  %s: %v\n", e.Path, e.Err)
+			case e.Action == minio.SyncDelete:
+				fmt.Printf("delete  %s\n", e.Path)
+			default:
+				fmt.Printf("copy    %s (%d bytes)\n", e.Path, e.Size)
+			}
+		}
+		verb := "synced"
+		if *dryRun {
+			verb = "would sync"
+		}
+		fmt.Printf("%s %s -> %s: %d copied (%d bytes), %d deleted, %d up to date, %d failed\n",
+			verb, src, dst, result.Copied, result.Bytes, result.Deleted, result.Skipped, result.Failed)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d files failed", result.Failed)
+	}
+	return nil
+}
+
+// parseLocation reads minio://<tenant>/<prefix> as a bucket, anything else
+// as a local directory
+func parseLocation(arg string) (minio.SyncLocation, error) {
+	rest, ok := strings.CutPrefix(arg, bucketScheme)
+	if !ok {
+		return minio.LocalDir(arg), nil
+	}
+	tenantID, prefix, _ := strings.Cut(rest, "/")
+	if tenantID == "" {
+		return minio.SyncLocation{}, fmt.Errorf("%s names no tenant", arg)
+	}
+	return minio.Bucket(tenantID, prefix), nil
+}
+
+// parseBytes reads a byte count with an optional K, M or G (binary) suffix
+func parseBytes(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	shift := 0
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
@@ -0,0 +1,246 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshWindow is how long before expiry session tokens are refreshed
+const DefaultRefreshWindow = 5 * time.Minute
+
+// Credentials are a bearer token and when it stops being accepted
+type Credentials struct {
+	Token string
+
+	// Expires is zero for tokens that do not expire
+	Expires time.Time
+}
+
+// CredentialsProvider supplies the token the client authenticates with.
+// The client caches what Retrieve returns and calls it again shortly before
+// Expires, or after the server rejects the token.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials is a fixed token, such as a tenant API key
+type StaticCredentials string
+
+func (s StaticCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return Credentials{Token: string(s)}, nil
+}
+
+// ServiceAccountCredentials exchanges a service account key pair for a
+// session token at /iam/service-accounts/credentials. The token follows key
+// rotations for as long as the presented key is within its overlap window.
+type ServiceAccountCredentials struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func (p *ServiceAccountCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		strings.TrimSuffix(p.Endpoint, "/")+APIVersionPrefix+"/iam/service-accounts/credentials", nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.AccessKey, p.SecretKey)
+	return fetchSessionToken(p.HTTPClient, req)
+}
+
+// DirectoryLoginCredentials logs a directory (LDAP/AD) user in at
+// /iam/login for a session token
+type DirectoryLoginCredentials struct {
+	Endpoint string
+	TenantID string
+	Username string
+	Password string
+
+	// ExpiresIn is the token lifetime requested (server default 1h)
+	ExpiresIn time.Duration
+
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func (p *DirectoryLoginCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	body := map[string]string{"tenant_id": p.TenantID, "username": p.Username, "password": p.Password}
+	if p.ExpiresIn > 0 {
+		body["expires_in"] = p.ExpiresIn.String()
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		strings.TrimSuffix(p.Endpoint, "/")+APIVersionPrefix+"/iam/login", bytes.NewReader(data))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return fetchSessionToken(p.HTTPClient, req)
+}
+
+// fetchSessionToken sends req and reads an access_token/expires_at response
+func fetchSessionToken(client *http.Client, req *http.Request) (Credentials, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("credentials request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("credentials request failed: %w", parseError(resp))
+	}
+
+	var result struct {
+		AccessToken string    `json:"access_token"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if result.AccessToken == "" {
+		return Credentials{}, errors.New("credentials response has no access token")
+	}
+	return Credentials{Token: result.AccessToken, Expires: result.ExpiresAt}, nil
+}
+
+// credentialCache holds the current token, refreshing it in the background
+// ahead of expiry. Concurrent callers share one Retrieve.
+type credentialCache struct {
+	provider CredentialsProvider
+	window   time.Duration
+
+	mu      sync.Mutex
+	creds   Credentials
+	fetch   chan struct{} // Closed when the Retrieve in flight finishes
+	err     error
+	timer   *time.Timer
+	closed  bool
+	refresh context.CancelFunc
+}
+
+func newCredentialCache(provider CredentialsProvider, window time.Duration) *credentialCache {
+	return &credentialCache{provider: provider, window: window}
+}
+
+// token returns a current token, retrieving one if none is cached or the
+// cached one has expired
+func (cc *credentialCache) token(ctx context.Context) (string, error) {
+	cc.mu.Lock()
+	if cc.creds.Token != "" && (cc.creds.Expires.IsZero() || time.Now().Before(cc.creds.Expires)) {
+		token := cc.creds.Token
+		cc.mu.Unlock()
+		return token, nil
+	}
+	done := cc.startFetch()
+	cc.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.creds.Token == "" {
+		return "", fmt.Errorf("failed to retrieve credentials: %w", cc.err)
+	}
+	return cc.creds.Token, nil
+}
+
+// invalidate drops token after the server rejected it, so the next call
+// retrieves a fresh one. It reports whether a retry could help.
+func (cc *credentialCache) invalidate(token string) bool {
+	if _, static := cc.provider.(StaticCredentials); static {
+		return false
+	}
+	cc.mu.Lock()
+	if cc.creds.Token == token {
+		cc.creds = Credentials{}
+	}
+	cc.mu.Unlock()
+	return true
+}
+
+// startFetch begins a Retrieve unless one is in flight; cc.mu must be held
+func (cc *credentialCache) startFetch() chan struct{} {
+	if cc.fetch != nil {
+		return cc.fetch
+	}
+	done := make(chan struct{})
+	cc.fetch = done
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	cc.refresh = cancel
+	go func() {
+		defer cancel()
+		creds, err := cc.provider.Retrieve(ctx)
+
+		cc.mu.Lock()
+		if err == nil && creds.Token == "" {
+			err = errors.New("provider returned an empty token")
+		}
+		cc.err = err
+		if err == nil {
+			cc.creds = creds
+		}
+		// After a failed background refresh, try again while the old token lasts
+		cc.schedule(cc.creds.Expires)
+		cc.fetch = nil
+		cc.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+// schedule arranges a background refresh the window before expires; cc.mu
+// must be held
+func (cc *credentialCache) schedule(expires time.Time) {
+	if cc.timer != nil {
+		cc.timer.Stop()
+	}
+	remaining := time.Until(expires)
+	if expires.IsZero() || remaining <= 0 || cc.closed {
+		return
+	}
+	// Refresh at the window before expiry, or halfway through short lifetimes
+	wait := max(remaining-cc.window, remaining/2, time.Second)
+	cc.timer = time.AfterFunc(wait, func() {
+		cc.mu.Lock()
+		if !cc.closed {
+			cc.startFetch()
+		}
+		cc.mu.Unlock()
+	})
+}
+
+// close stops background refreshes
+func (cc *credentialCache) close() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.closed = true
+	if cc.timer != nil {
+		cc.timer.Stop()
+	}
+	if cc.refresh != nil {
+		cc.refresh()
+	}
+}
@@ -0,0 +1,164 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider hands out tok1, tok2, ... each valid for ttl
+type countingProvider struct {
+	ttl   time.Duration
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (p *countingProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	n := p.calls.Add(1)
+	time.Sleep(p.delay)
+	creds := Credentials{Token: "tok" + string(rune('0'+n))}
+	if p.ttl > 0 {
+		creds.Expires = time.Now().Add(p.ttl)
+	}
+	return creds, nil
+}
+
+func TestServiceAccountCredentials(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/iam/service-accounts/credentials":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "AK" || pass != "SK" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "session-token", "expires_at": expires})
+		default:
+			if auth := r.Header.Get("Authorization"); auth != "Bearer session-token" {
+				t.Errorf("Expected session token, got %s", auth)
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	provider := &ServiceAccountCredentials{Endpoint: server.URL, AccessKey: "AK", SecretKey: "SK"}
+	creds, err := provider.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.Token != "session-token" || !creds.Expires.Equal(expires) {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+
+	client, err := NewClient(Config{Endpoint: server.URL, Credentials: provider})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	provider.SecretKey = "wrong"
+	if _, err := provider.Retrieve(context.Background()); err == nil {
+		t.Error("Expected error for bad secret key")
+	}
+}
+
+func TestClient_RefreshOnUnauthorized(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer tok2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := &countingProvider{ttl: time.Hour}
+	client, err := NewClient(Config{Endpoint: server.URL, Credentials: provider})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if provider.calls.Load() != 2 || requests.Load() != 2 {
+		t.Errorf("Expected 2 retrievals and 2 requests, got %d and %d", provider.calls.Load(), requests.Load())
+	}
+}
+
+func TestClient_StaticKeyNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); err == nil {
+		t.Error("Expected unauthorized error")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestCredentialCache_SharedRetrieve(t *testing.T) {
+	provider := &countingProvider{ttl: time.Hour, delay: 50 * time.Millisecond}
+	cc := newCredentialCache(provider, DefaultRefreshWindow)
+	defer cc.close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := cc.token(context.Background()); err != nil || token != "tok1" {
+				t.Errorf("token() = %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if provider.calls.Load() != 1 {
+		t.Errorf("Expected 1 retrieval, got %d", provider.calls.Load())
+	}
+}
+
+func TestCredentialCache_BackgroundRefresh(t *testing.T) {
+	provider := &countingProvider{ttl: 2 * time.Second}
+	cc := newCredentialCache(provider, time.Minute)
+	defer cc.close()
+
+	if token, _ := cc.token(context.Background()); token != "tok1" {
+		t.Fatalf("Expected tok1, got %s", token)
+	}
+
+	// Refreshed before expiry without being asked
+	deadline := time.Now().Add(1900 * time.Millisecond)
+	for provider.calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if token, _ := cc.token(context.Background()); token != "tok2" {
+		t.Errorf("Expected refreshed tok2 before expiry, got %s", token)
+	}
+}
@@ -0,0 +1,181 @@
+package minio
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultHedgeBudget is the share of reads that may be hedged
+	DefaultHedgeBudget = 0.1
+
+	// hedgeBurst is the most hedges the budget saves up
+	hedgeBurst = 10
+)
+
+// HedgeStats counts a client's hedged reads
+type HedgeStats struct {
+	// Reads is the reads eligible for hedging
+	Reads uint64
+
+	// Hedged is the reads that sent a second request, and Won those the
+	// second request answered first
+	Hedged uint64
+	Won    uint64
+
+	// Throttled is the hedges the budget refused
+	Throttled uint64
+}
+
+// hedger meters hedges: every read earns budget tokens, up to hedgeBurst,
+// and every hedge spends one. It starts with a full burst.
+type hedger struct {
+	delay  time.Duration
+	budget float64
+
+	mu     sync.Mutex
+	tokens float64
+
+	reads     atomic.Uint64
+	hedged    atomic.Uint64
+	won       atomic.Uint64
+	throttled atomic.Uint64
+}
+
+func newHedger(delay time.Duration, budget float64) *hedger {
+	return &hedger{delay: delay, budget: budget, tokens: hedgeBurst}
+}
+
+func (h *hedger) earn() {
+	h.reads.Add(1)
+	h.mu.Lock()
+	h.tokens = min(h.tokens+h.budget, hedgeBurst)
+	h.mu.Unlock()
+}
+
+func (h *hedger) spend() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		h.throttled.Add(1)
+		return false
+	}
+	h.tokens--
+	h.hedged.Add(1)
+	return true
+}
+
+// hedgeDelay returns how long a read waits before it is hedged, 0 if it
+// is not
+func (c *Client) hedgeDelay(req *http.Request) time.Duration {
+	if req.Body != nil || req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return 0
+	}
+	if o, ok := req.Context().Value(requestOptionsKey{}).(*requestOptions); ok && o.hedgeDelay != nil {
+		return *o.hedgeDelay
+	}
+	return c.hedge.delay
+}
+
+// hedgedResponse is one of a hedged read's requests, answered
+type hedgedResponse struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	hedge  bool
+}
+
+// ok reports whether the response can be returned without waiting for
+// the other request
+func (r hedgedResponse) ok() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+// discard releases a response that lost
+func (r hedgedResponse) discard() {
+	if r.resp != nil {
+		r.resp.Body.Close()
+	}
+	r.cancel()
+}
+
+// sendHedged sends req and, if it is not answered within delay and the
+// budget allows, the same request to another node. The first successful
+// response wins and the other request is canceled.
+func (c *Client) sendHedged(req *http.Request, delay time.Duration) (*http.Response, error) {
+	c.hedge.earn()
+	results := make(chan hedgedResponse, 2)
+	var cancels []context.CancelFunc
+	launch := func(req *http.Request, hedge bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		req = req.Clone(ctx)
+		go func() {
+			resp, err := c.send(req)
+			results <- hedgedResponse{resp, err, cancel, hedge}
+		}()
+	}
+	launch(req, false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var failed *hedgedResponse
+	for {
+		select {
+		case <-timer.C:
+			if !c.hedge.spend() {
+				continue
+			}
+			hedge := req.Clone(req.Context())
+			if c.router != nil {
+				if u, err := url.Parse(c.router.alternate(req.URL.String())); err == nil {
+					hedge.URL, hedge.Host = u, ""
+				}
+			}
+			launch(hedge, true)
+
+		case r := <-results:
+			if !r.ok() && failed == nil && len(cancels) > 1 {
+				// Wait for the other request, keeping this answer if it fails too
+				failed = &r
+				continue
+			}
+			if failed != nil {
+				failed.discard()
+			} else if len(cancels) > 1 {
+				// Cancel the request still in flight: the primary if the
+				// hedge answered, else the hedge
+				loser := 1
+				if r.hedge {
+					loser = 0
+				}
+				cancels[loser]()
+				go func() { (<-results).discard() }()
+			}
+			if r.hedge && r.ok() {
+				c.hedge.won.Add(1)
+			}
+			if r.err != nil {
+				r.cancel()
+				return nil, r.err
+			}
+			r.resp.Body = &cancelOnClose{r.resp.Body, r.cancel}
+			return r.resp, nil
+		}
+	}
+}
+
+// HedgeStats reports the client's hedged reads
+func (c *Client) HedgeStats() HedgeStats {
+	return HedgeStats{
+		Reads:     c.hedge.reads.Load(),
+		Hedged:    c.hedge.hedged.Load(),
+		Won:       c.hedge.won.Load(),
+		Throttled: c.hedge.throttled.Load(),
+	}
+}
@@ -0,0 +1,190 @@
+package minio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stallingServer answers requests immediately, except that those stall
+// returns true for hang until canceled
+func stallingServer(t *testing.T, stall func(n int64) bool) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests, canceled atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/health" {
+			w.Write([]byte(`{"status":"ok","score":1}`))
+			return
+		}
+		if stall(requests.Add(1)) {
+			<-r.Context().Done()
+			canceled.Add(1)
+			return
+		}
+		w.Write([]byte("data"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &canceled
+}
+
+func TestHedgedReadTakesFirstResponse(t *testing.T) {
+	server, canceled := stallingServer(t, func(n int64) bool { return n == 1 })
+
+	client, err := NewClient(Config{
+		Endpoint:   server.URL,
+		APIKey:     "test-api-key",
+		HedgeDelay: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	body, err := client.Download(context.Background(), "tenant", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "data" {
+		t.Errorf("Body = %q, want %q", data, "data")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Hedged read took %v", d)
+	}
+
+	// The stalled request is canceled
+	deadline := time.Now().Add(5 * time.Second)
+	for canceled.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if canceled.Load() != 1 {
+		t.Error("Losing request was not canceled")
+	}
+	if st := client.HedgeStats(); st.Reads != 1 || st.Hedged != 1 || st.Won != 1 {
+		t.Errorf("HedgeStats = %+v, want one read hedged and won", st)
+	}
+}
+
+func TestHedgedReadGoesToAnotherNode(t *testing.T) {
+	slow, canceled := stallingServer(t, func(int64) bool { return true })
+	fast, _ := stallingServer(t, func(int64) bool { return false })
+
+	client, err := NewClient(Config{
+		Endpoint:            slow.URL,
+		Endpoints:           []string{fast.URL},
+		APIKey:              "test-api-key",
+		HealthCheckInterval: time.Hour,
+		HedgeDelay:          10 * time.Millisecond,
+		HedgeBudget:         1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	waitForScores(t, client, 2)
+
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		body, err := client.Download(ctx, "tenant", "key")
+		if err != nil {
+			t.Fatalf("Download %d: %v", i, err)
+		}
+		body.Close()
+		cancel()
+	}
+
+	// Every read sent to the stalled node was hedged onto the other one
+	st := client.HedgeStats()
+	if st.Hedged == 0 || st.Won != st.Hedged {
+		t.Errorf("HedgeStats = %+v, want every hedge to win", st)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for canceled.Load() != int64(st.Hedged) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := canceled.Load(); n != int64(st.Hedged) {
+		t.Errorf("%d stalled requests canceled, want %d", n, st.Hedged)
+	}
+}
+
+func TestHedgeBudgetCapsExtraLoad(t *testing.T) {
+	server, _ := stallingServer(t, func(n int64) bool { return false })
+	// Slow enough for every read to qualify for a hedge
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer slow.Close()
+
+	client, err := NewClient(Config{
+		Endpoint:    slow.URL,
+		APIKey:      "test-api-key",
+		HedgeDelay:  time.Microsecond,
+		HedgeBudget: 0.05,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	const reads = 400
+	for i := 0; i < reads; i++ {
+		body, err := client.Download(context.Background(), "tenant", "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body.Close()
+	}
+
+	st := client.HedgeStats()
+	if max := uint64(reads*0.05 + hedgeBurst); st.Hedged > max {
+		t.Errorf("Hedged %d of %d reads, budget allows %d", st.Hedged, reads, max)
+	}
+	if st.Hedged+st.Throttled != reads {
+		t.Errorf("HedgeStats = %+v, want every read hedged or throttled", st)
+	}
+}
+
+func TestHedgingSkipsWritesAndCanBeTurnedOff(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint:   server.URL,
+		APIKey:     "test-api-key",
+		HedgeDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Upload(ctx, "tenant", "key", strings.NewReader("data"), nil); err != nil {
+		t.Fatal(err)
+	}
+	body, err := client.Download(ctx, "tenant", "key", WithHedgeDelay(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Server got %d requests, want 2", n)
+	}
+	if st := client.HedgeStats(); st.Reads != 0 {
+		t.Errorf("HedgeStats = %+v, want no hedged reads", st)
+	}
+}
@@ -0,0 +1,157 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultListConcurrency is the number of shards ListParallel lists at once
+const DefaultListConcurrency = 16
+
+// ParallelListOptions controls ListParallel
+type ParallelListOptions struct {
+	// Prefix limits the listing to keys starting with it
+	Prefix string
+
+	// SplitKeys divide the keys under Prefix into shards listed
+	// concurrently: each shard holds the keys after one split key up to and
+	// including the next. Every key is listed exactly once whatever the
+	// split keys are; they only decide how evenly work is shared. Default:
+	// HexSplitKeys(Prefix), suited to hash, UUID and other hex keys.
+	SplitKeys []string
+
+	// Concurrency is the number of shards listed at once (default: 16)
+	Concurrency int
+
+	// PageSize is the number of keys requested per call (default: 1000)
+	PageSize int
+}
+
+// HexSplitKeys splits the keys under prefix by their next hex digit into 16 shards
+func HexSplitKeys(prefix string) []string {
+	const digits = "123456789abcdef"
+	keys := make([]string, len(digits))
+	for i := range digits {
+		keys[i] = prefix + digits[i:i+1]
+	}
+	return keys
+}
+
+// ListParallel lists every object under opts.Prefix, fanning the listing
+// out over key ranges listed concurrently, and returns them in key order.
+// It is much faster than paging through List for buckets with millions of
+// keys. A failed shard fails the whole listing.
+func (c *Client) ListParallel(ctx context.Context, tenantID string, opts *ParallelListOptions, reqOpts ...RequestOption) ([]Object, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if opts == nil {
+		opts = &ParallelListOptions{}
+	}
+	splits := opts.SplitKeys
+	if splits == nil {
+		splits = HexSplitKeys(opts.Prefix)
+	}
+	splits = append([]string(nil), splits...)
+	sort.Strings(splits)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultListConcurrency
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	// Shard i holds keys in (splits[i-1], splits[i]]; the first is open
+	// below and the last above
+	shards := make([][]Object, len(splits)+1)
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				var after, upTo string
+				if i > 0 {
+					after = splits[i-1]
+				}
+				if i < len(splits) {
+					upTo = splits[i]
+				}
+				objects, err := c.listShard(ctx, tenantID, opts.Prefix, after, upTo, i == len(splits), pageSize)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						stop()
+					})
+					continue
+				}
+				shards[i] = objects
+			}
+		}()
+	}
+feed:
+	for i := range shards {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var total int
+	for _, shard := range shards {
+		total += len(shard)
+	}
+	objects := make([]Object, 0, total)
+	for _, shard := range shards {
+		objects = append(objects, shard...)
+	}
+	return objects, nil
+}
+
+// listShard pages through the keys under prefix after after, up to and
+// including upTo unless last
+func (c *Client) listShard(ctx context.Context, tenantID, prefix, after, upTo string, last bool, pageSize int) ([]Object, error) {
+	var objects []Object
+	opts := &ListOptions{Prefix: prefix, MaxKeys: pageSize, StartAfter: after}
+	for {
+		page, err := c.List(ctx, tenantID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Objects {
+			if !last && obj.Key > upTo {
+				return objects, nil
+			}
+			objects = append(objects, obj)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		opts.ContinuationToken = page.NextContinuationToken
+	}
+}
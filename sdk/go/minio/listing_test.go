@@ -0,0 +1,60 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestClient_ListParallel(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	var want []string
+	for i := 0; i < 60; i++ {
+		key := fmt.Sprintf("logs/%02x", i*4)
+		store.put(key, []byte("x"))
+		want = append(want, key)
+	}
+	store.put("other/1", []byte("x"))
+	sort.Strings(want)
+	client := newSyncClient(t, store)
+
+	tests := []struct {
+		name string
+		opts *ParallelListOptions
+	}{
+		{"hex shards", &ParallelListOptions{Prefix: "logs/", Concurrency: 4}},
+		{"custom shards", &ParallelListOptions{Prefix: "logs/", SplitKeys: []string{"logs/80", "logs/3", "logs/c0"}}},
+		{"split keys outside the prefix", &ParallelListOptions{Prefix: "logs/", SplitKeys: []string{"a", "z"}}},
+		{"no shards", &ParallelListOptions{Prefix: "logs/", SplitKeys: []string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := client.ListParallel(context.Background(), "tenant1", tt.opts)
+			if err != nil {
+				t.Fatalf("ListParallel() error = %v", err)
+			}
+			if len(objects) != len(want) {
+				t.Fatalf("Expected %d objects, got %d", len(want), len(objects))
+			}
+			for i, obj := range objects {
+				if obj.Key != want[i] {
+					t.Fatalf("Object %d: expected %s, got %s", i, want[i], obj.Key)
+				}
+			}
+		})
+	}
+
+	// Every shard is listed on its own
+	store.lists = 0
+	if _, err := client.ListParallel(context.Background(), "tenant1", &ParallelListOptions{Prefix: "logs/"}); err != nil {
+		t.Fatal(err)
+	}
+	if store.lists < 16 {
+		t.Errorf("Expected at least one list per shard, got %d", store.lists)
+	}
+
+	if _, err := client.ListParallel(context.Background(), "", nil); err == nil {
+		t.Error("Expected error for a missing tenant")
+	}
+}
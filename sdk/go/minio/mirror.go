@@ -0,0 +1,422 @@
+package minio
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncLocation is one side of a Sync: a local directory, or the objects
+// under Prefix in a tenant's bucket
+type SyncLocation struct {
+	// Dir is a local directory; leave empty for a bucket
+	Dir string
+
+	TenantID string
+
+	// Prefix is prepended to each relative path to form its key, e.g. "backups/"
+	Prefix string
+}
+
+// LocalDir is a SyncLocation for a local directory
+func LocalDir(dir string) SyncLocation {
+	return SyncLocation{Dir: dir}
+}
+
+// Bucket is a SyncLocation for the objects under prefix in tenantID's bucket
+func Bucket(tenantID, prefix string) SyncLocation {
+	return SyncLocation{TenantID: tenantID, Prefix: prefix}
+}
+
+func (l SyncLocation) local() bool {
+	return l.TenantID == ""
+}
+
+func (l SyncLocation) String() string {
+	if l.local() {
+		return l.Dir
+	}
+	return l.TenantID + "/" + l.Prefix
+}
+
+// SyncOptions controls a Sync
+type SyncOptions struct {
+	// Delete removes destination files that are absent from the source
+	Delete bool
+
+	// DryRun reports what would be copied and deleted without changing anything
+	DryRun bool
+
+	// Concurrency is the number of files transferred at once (default: 4)
+	Concurrency int
+
+	// BandwidthLimit caps the bytes per second sent or received across all
+	// transfers (default: unlimited). Bucket-to-bucket copies run on the
+	// server and are not limited.
+	BandwidthLimit int64
+}
+
+// SyncAction is what a Sync does with one file
+type SyncAction string
+
+const (
+	SyncCopy   SyncAction = "copy"
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncEntry reports one file a Sync copied or deleted
+type SyncEntry struct {
+	// Path is relative to both locations, slash-separated
+	Path   string
+	Action SyncAction
+	Size   int64
+	Err    error
+}
+
+// SyncResult reports a Sync, with one entry per copy or delete in path order
+type SyncResult struct {
+	Entries []SyncEntry
+	Copied  int
+	Deleted int
+	Skipped int // Already up to date
+	Failed  int
+	Bytes   int64 // Copied through the client or by the server
+}
+
+// syncFile is what a Sync knows about one side's copy of a file
+type syncFile struct {
+	size    int64
+	modTime time.Time
+	etag    string // Empty for local files until hashed
+	path    string // Local files only
+}
+
+// Sync makes dst mirror src, where either side is a local directory or a
+// bucket prefix and at least one is a bucket. Files are compared like
+// rsync's quick check: equal sizes with an object uploaded after the local
+// file last changed, or a local file bearing the object's time, are up to
+// date; otherwise contents are compared by ETag, hashing local files as
+// needed. Downloaded files take the object's modification time so later
+// runs skip them without hashing.
+//
+// Files succeed or fail independently; check each SyncEntry. The error is
+// set only if either side could not be listed or ctx ended.
+func (c *Client) Sync(ctx context.Context, src, dst SyncLocation, opts *SyncOptions, reqOpts ...RequestOption) (*SyncResult, error) {
+	if src.local() && dst.local() {
+		return nil, fmt.Errorf("at least one side of a sync must be a bucket")
+	}
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	srcFiles, err := c.listSyncFiles(ctx, src, false)
+	if err != nil {
+		return nil, err
+	}
+	dstFiles, err := c.listSyncFiles(ctx, dst, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	for rel, f := range srcFiles {
+		if unchanged(f, dstFiles[rel]) {
+			result.Skipped++
+			continue
+		}
+		result.Entries = append(result.Entries, SyncEntry{Path: rel, Action: SyncCopy, Size: f.size})
+	}
+	if opts.Delete {
+		for rel, f := range dstFiles {
+			if _, ok := srcFiles[rel]; !ok {
+				result.Entries = append(result.Entries, SyncEntry{Path: rel, Action: SyncDelete, Size: f.size})
+			}
+		}
+	}
+	sort.Slice(result.Entries, func(i, j int) bool { return result.Entries[i].Path < result.Entries[j].Path })
+
+	if !opts.DryRun {
+		var bw *bandwidth
+		if opts.BandwidthLimit > 0 {
+			bw = &bandwidth{rate: opts.BandwidthLimit}
+		}
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					e := &result.Entries[i]
+					if e.Err = ctx.Err(); e.Err != nil {
+						continue
+					}
+					if e.Action == SyncDelete {
+						e.Err = c.syncDelete(ctx, dst, e.Path, dstFiles[e.Path])
+					} else {
+						e.Err = c.syncCopy(ctx, src, dst, e.Path, srcFiles[e.Path], bw)
+					}
+				}
+			}()
+		}
+		for i := range result.Entries {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	for _, e := range result.Entries {
+		switch {
+		case e.Err != nil:
+			result.Failed++
+		case e.Action == SyncDelete:
+			result.Deleted++
+		default:
+			result.Copied++
+			result.Bytes += e.Size
+		}
+	}
+	return result, ctx.Err()
+}
+
+// unchanged reports whether dst already holds src's contents
+func unchanged(src, dst *syncFile) bool {
+	if dst == nil || src.size != dst.size {
+		return false
+	}
+	if src.etag != "" && dst.etag != "" {
+		return src.etag == dst.etag
+	}
+	// Downloads are stamped with the object's time, so a local destination
+	// must match it exactly; an object uploaded after the source file last
+	// changed is current
+	if dst.path != "" && src.modTime.Equal(dst.modTime) || dst.path == "" && !src.modTime.After(dst.modTime) {
+		return true
+	}
+	// Hash whichever side is local, against the other side's ETag
+	for _, pair := range [][2]*syncFile{{src, dst}, {dst, src}} {
+		if pair[0].path != "" && pair[1].etag != "" {
+			etag, err := fileETag(pair[0].path)
+			return err == nil && etag == pair[1].etag
+		}
+	}
+	return false
+}
+
+// listSyncFiles returns the files at l keyed by relative path. A missing
+// local directory is empty when it is the destination.
+func (c *Client) listSyncFiles(ctx context.Context, l SyncLocation, isDst bool) (map[string]*syncFile, error) {
+	files := make(map[string]*syncFile)
+	if !l.local() {
+		objects, err := c.listObjects(ctx, l.TenantID, l.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			if rel := strings.TrimPrefix(obj.Key, l.Prefix); rel != "" {
+				files[rel] = &syncFile{size: obj.Size, modTime: obj.LastModified, etag: obj.ETag}
+			}
+		}
+		return files, nil
+	}
+
+	err := filepath.WalkDir(l.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.Dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = &syncFile{size: info.Size(), modTime: info.ModTime(), path: p}
+		return nil
+	})
+	if isDst && errors.Is(err, fs.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", l.Dir, err)
+	}
+	return files, nil
+}
+
+// syncCopy copies one file from src to dst
+func (c *Client) syncCopy(ctx context.Context, src, dst SyncLocation, rel string, f *syncFile, bw *bandwidth) error {
+	switch {
+	case !src.local() && !dst.local():
+		return c.Copy(ctx, dst.TenantID, dst.Prefix+rel, CopySource{TenantID: src.TenantID, Key: src.Prefix + rel})
+
+	case src.local():
+		file, err := os.Open(f.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		opts := &UploadOptions{ContentType: mime.TypeByExtension(path.Ext(rel))}
+		return c.Upload(ctx, dst.TenantID, dst.Prefix+rel, bw.reader(ctx, file), opts)
+
+	default:
+		body, err := c.Download(ctx, src.TenantID, src.Prefix+rel)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		return writeLocal(filepath.Join(dst.Dir, filepath.FromSlash(rel)), bw.reader(ctx, body), f.modTime)
+	}
+}
+
+// syncDelete removes one file from dst
+func (c *Client) syncDelete(ctx context.Context, dst SyncLocation, rel string, f *syncFile) error {
+	if dst.local() {
+		return os.Remove(f.path)
+	}
+	return c.Delete(ctx, dst.TenantID, dst.Prefix+rel)
+}
+
+// writeLocal writes r to name through a temporary file, so an interrupted
+// download never leaves a partial file behind, and stamps it with modTime
+func writeLocal(name string, r io.Reader, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// fileETag returns the hex MD5 of a local file, as the server computes ETags
+func fileETag(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// listObjects returns every object under prefix, following continuation tokens
+func (c *Client) listObjects(ctx context.Context, tenantID, prefix string) ([]Object, error) {
+	var objects []Object
+	opts := &ListOptions{Prefix: prefix, MaxKeys: 1000}
+	for {
+		page, err := c.List(ctx, tenantID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s/%s: %w", tenantID, prefix, err)
+		}
+		objects = append(objects, page.Objects...)
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		opts.ContinuationToken = page.NextContinuationToken
+	}
+}
+
+// bandwidth paces reads across transfers to a shared byte rate
+type bandwidth struct {
+	rate int64 // Bytes per second
+
+	mu   sync.Mutex
+	next time.Time // When the next read may proceed
+}
+
+// reader paces r; a nil bandwidth leaves it unpaced
+func (b *bandwidth) reader(ctx context.Context, r io.Reader) io.Reader {
+	if b == nil {
+		return r
+	}
+	return &pacedReader{ctx: ctx, r: r, bw: b}
+}
+
+// wait blocks until n more bytes fit within the rate
+func (b *bandwidth) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	at := b.next
+	b.next = b.next.Add(time.Duration(int64(n) * int64(time.Second) / b.rate))
+	b.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pacedReader reads in small steps, each admitted by its bandwidth
+type pacedReader struct {
+	ctx context.Context
+	r   io.Reader
+	bw  *bandwidth
+}
+
+func (p *pacedReader) Read(buf []byte) (int, error) {
+	// Steps of about a tenth of a second keep low rates smooth
+	step := int(min(32<<10, max(p.bw.rate/10, 1)))
+	if len(buf) > step {
+		buf = buf[:step]
+	}
+	if err := p.bw.wait(p.ctx, len(buf)); err != nil {
+		return 0, err
+	}
+	return p.r.Read(buf)
+}
+
+// Seek lets upload retries rewind a paced file
+func (p *pacedReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := p.r.(io.Seeker)
+	if !ok {
+		return 0, errors.New("paced reader cannot seek")
+	}
+	return seeker.Seek(offset, whence)
+}
@@ -0,0 +1,123 @@
+package minio
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newSyncClient(t *testing.T, store *objectStore) *Client {
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClient_SyncUpAndDown(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	client := newSyncClient(t, store)
+	ctx := context.Background()
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo"})
+	bucket := Bucket("tenant1", "backup/")
+
+	// Dry runs change nothing
+	result, err := client.Sync(ctx, LocalDir(src), bucket, &SyncOptions{DryRun: true})
+	if err != nil || result.Copied != 2 || len(store.objects) != 0 {
+		t.Fatalf("Dry run: got %+v, %v with %d objects", result, err, len(store.objects))
+	}
+
+	result, err = client.Sync(ctx, LocalDir(src), bucket, nil)
+	if err != nil || result.Copied != 2 || string(store.objects["backup/dir/b.txt"]) != "bravo" {
+		t.Fatalf("Upload: got %+v, %v", result, err)
+	}
+
+	// Down to a new directory, then nothing to do on either side
+	dst := filepath.Join(t.TempDir(), "restore")
+	result, err = client.Sync(ctx, bucket, LocalDir(dst), nil)
+	if err != nil || result.Copied != 2 {
+		t.Fatalf("Download: got %+v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "dir", "b.txt")); string(data) != "bravo" {
+		t.Errorf("Expected downloaded bravo, got %q", data)
+	}
+	for _, pair := range [][2]SyncLocation{{LocalDir(src), bucket}, {bucket, LocalDir(dst)}} {
+		if result, err := client.Sync(ctx, pair[0], pair[1], nil); err != nil || result.Skipped != 2 || result.Copied != 0 {
+			t.Errorf("%s -> %s: expected everything up to date, got %+v, %v", pair[0], pair[1], result, err)
+		}
+	}
+
+	// A same-size edit with a newer mtime is found by ETag
+	writeFiles(t, src, map[string]string{"a.txt": "ALPHA"})
+	os.Chtimes(filepath.Join(src, "a.txt"), time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	os.Remove(filepath.Join(src, "dir", "b.txt"))
+
+	result, err = client.Sync(ctx, LocalDir(src), bucket, &SyncOptions{Delete: true})
+	if err != nil || result.Copied != 1 || result.Deleted != 1 {
+		t.Fatalf("Changes: got %+v, %v", result, err)
+	}
+	if _, ok := store.objects["backup/dir/b.txt"]; ok || string(store.objects["backup/a.txt"]) != "ALPHA" {
+		t.Errorf("Expected bucket to mirror the edit and delete, got %v", store.objects)
+	}
+
+	// Without Delete, extra destination files stay
+	result, err = client.Sync(ctx, bucket, LocalDir(dst), nil)
+	if err != nil || result.Copied != 1 || result.Deleted != 0 {
+		t.Fatalf("Download changes: got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "dir", "b.txt")); err != nil {
+		t.Errorf("Expected b.txt kept without Delete: %v", err)
+	}
+}
+
+func TestClient_SyncBuckets(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	store.put("src/a.txt", []byte("alpha"))
+	store.put("src/b.txt", []byte("bravo"))
+	store.put("dst/stale.txt", []byte("old"))
+	client := newSyncClient(t, store)
+
+	result, err := client.Sync(context.Background(), Bucket("tenant1", "src/"), Bucket("tenant1", "dst/"), &SyncOptions{Delete: true})
+	if err != nil || result.Copied != 2 || result.Deleted != 1 {
+		t.Fatalf("Got %+v, %v", result, err)
+	}
+	if string(store.objects["dst/b.txt"]) != "bravo" {
+		t.Errorf("Expected server-side copy, got %v", store.objects)
+	}
+
+	if _, err := client.Sync(context.Background(), LocalDir("a"), LocalDir("b"), nil); err == nil {
+		t.Error("Expected error syncing two local directories")
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	bw := &bandwidth{rate: 100 << 10}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := bw.wait(context.Background(), 10<<10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first read is free; four more at 100KiB/s take about 400ms
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected about 400ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+package minio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ExpectedBucketOwnerHeader names the tenant a request expects to own the
+// bucket it addresses; the server rejects the request with AccessDenied
+// if another tenant does
+const ExpectedBucketOwnerHeader = "X-Expected-Bucket-Owner"
+
+// RequestOption adjusts a single call, overriding the client's Config
+type RequestOption func(*requestOptions)
+
+// RetryPolicy controls how a call retries transient failures
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero
+	// disables retries
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, doubled for each one after
+	Backoff time.Duration
+}
+
+type requestOptions struct {
+	header  http.Header
+	timeout time.Duration
+	retry   *RetryPolicy
+
+	hedgeDelay *time.Duration
+}
+
+// requestOptionsKey carries a call's options in its context
+type requestOptionsKey struct{}
+
+// WithHeader sends an extra header with the call. Authorization is managed
+// by the client's credentials and cannot be overridden.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithTimeout bounds the whole call, including retries. For calls that
+// return a stream, the timeout also covers reading it.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRetryPolicy replaces Config.MaxRetries and Config.BackoffDuration
+// for the call
+func WithRetryPolicy(policy RetryPolicy) RequestOption {
+	return func(o *requestOptions) {
+		o.retry = &policy
+	}
+}
+
+// WithHedgeDelay replaces Config.HedgeDelay for the call; zero turns
+// hedging off
+func WithHedgeDelay(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.hedgeDelay = &d
+	}
+}
+
+// WithExpectedBucketOwner makes the call fail with AccessDenied unless
+// tenantID owns the addressed bucket: the tenant named by the request or
+// its virtual host, or the tenant behind a share link
+func WithExpectedBucketOwner(tenantID string) RequestOption {
+	return WithHeader(ExpectedBucketOwnerHeader, tenantID)
+}
+
+// withOptions returns ctx carrying opts on top of any options already in
+// ctx. cancel releases the call's timeout and must be called when the
+// call is done.
+func withOptions(ctx context.Context, opts []RequestOption) (context.Context, context.CancelFunc) {
+	if len(opts) == 0 {
+		return ctx, func() {}
+	}
+
+	o := &requestOptions{header: make(http.Header)}
+	if parent, ok := ctx.Value(requestOptionsKey{}).(*requestOptions); ok {
+		o.header = parent.header.Clone()
+		o.retry = parent.retry
+		o.hedgeDelay = parent.hedgeDelay
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ctx = context.WithValue(ctx, requestOptionsKey{}, o)
+
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// optionHeader returns the extra headers set for the call in ctx
+func optionHeader(ctx context.Context) http.Header {
+	if o, ok := ctx.Value(requestOptionsKey{}).(*requestOptions); ok {
+		return o.header
+	}
+	return nil
+}
+
+// retryPolicy returns the call's retry policy, defaulting to the client's
+func (c *Client) retryPolicy(ctx context.Context) RetryPolicy {
+	if o, ok := ctx.Value(requestOptionsKey{}).(*requestOptions); ok && o.retry != nil {
+		return *o.retry
+	}
+	return RetryPolicy{MaxRetries: c.maxRetries, Backoff: c.backoff}
+}
+
+// cancelOnClose releases a call's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,110 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestOptions_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Trace-Tag"); got != "nightly" {
+			t.Errorf("Expected X-Trace-Tag nightly, got %q", got)
+		}
+		if got := r.Header.Get(ExpectedBucketOwnerHeader); got != "tenant1" {
+			t.Errorf("Expected owner tenant1, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-api-key" {
+			t.Errorf("Expected the client's credentials, got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	err = client.Delete(context.Background(), "tenant1", "test.txt",
+		WithHeader("X-Trace-Tag", "nightly"),
+		WithHeader("Authorization", "Bearer other"),
+		WithExpectedBucketOwner("tenant1"))
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
+
+func TestRequestOptions_RetryPolicy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key", BackoffDuration: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	tests := []struct {
+		name string
+		opts []RequestOption
+		want int32
+	}{
+		{name: "client default", want: DefaultMaxRetries + 1},
+		{name: "no retries", opts: []RequestOption{WithRetryPolicy(RetryPolicy{})}, want: 1},
+		{name: "more retries", opts: []RequestOption{WithRetryPolicy(RetryPolicy{MaxRetries: 5, Backoff: time.Millisecond})}, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			if _, err := client.Stat(context.Background(), "tenant1", "test.txt", tt.opts...); err == nil {
+				t.Error("Expected error")
+			}
+			if requests.Load() != tt.want {
+				t.Errorf("Expected %d requests, got %d", tt.want, requests.Load())
+			}
+		})
+	}
+}
+
+func TestRequestOptions_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	// The timeout outlives Download returning and cuts off the stream
+	body, err := client.Download(context.Background(), "tenant1", "test.txt", WithTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	defer body.Close()
+
+	if _, err := io.ReadAll(body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded reading the body, got %v", err)
+	}
+}
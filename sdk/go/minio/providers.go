@@ -0,0 +1,240 @@
+package minio
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoCredentials is returned by a provider with nothing configured; a
+// ChainCredentials moves on to its next provider
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Environment variables read by the default credential providers
+const (
+	EnvAPIKey                  = "MINIO_API_KEY"
+	EnvAccessKey               = "MINIO_ACCESS_KEY"
+	EnvSecretKey               = "MINIO_SECRET_KEY"
+	EnvCredentialsFile         = "MINIO_SHARED_CREDENTIALS_FILE"
+	EnvProfile                 = "MINIO_PROFILE"
+	EnvContainerCredentialsURL = "MINIO_CONTAINER_CREDENTIALS_URL"
+	EnvContainerAuthToken      = "MINIO_CONTAINER_AUTHORIZATION_TOKEN"
+	EnvMetadataEndpoint        = "MINIO_METADATA_ENDPOINT"
+)
+
+// metadataTimeout bounds calls to a metadata endpoint, which on the wrong
+// host may never answer
+const metadataTimeout = 2 * time.Second
+
+// CredentialsFunc adapts a function to CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsFunc) Retrieve(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// ChainCredentials uses the first of its providers that has credentials.
+// Providers returning ErrNoCredentials are skipped; any other error stops
+// the chain, so a misconfigured source is not masked by a later one.
+type ChainCredentials struct {
+	Providers []CredentialsProvider
+}
+
+func (c *ChainCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	for _, p := range c.Providers {
+		creds, err := p.Retrieve(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return creds, err
+	}
+	return Credentials{}, ErrNoCredentials
+}
+
+// NewDefaultCredentials returns the chain used when Config sets neither
+// Credentials nor APIKey: the environment, the shared credentials file, a
+// container credentials endpoint, then an instance metadata endpoint
+func NewDefaultCredentials(endpoint string) *ChainCredentials {
+	return &ChainCredentials{Providers: []CredentialsProvider{
+		&EnvCredentials{Endpoint: endpoint},
+		&FileCredentials{Endpoint: endpoint},
+		&ContainerCredentials{},
+		&InstanceMetadataCredentials{},
+	}}
+}
+
+// EnvCredentials reads MINIO_API_KEY, or exchanges MINIO_ACCESS_KEY and
+// MINIO_SECRET_KEY for a service account session token at Endpoint
+type EnvCredentials struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (p *EnvCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	if key := os.Getenv(EnvAPIKey); key != "" {
+		return Credentials{Token: key}, nil
+	}
+	accessKey, secretKey := os.Getenv(EnvAccessKey), os.Getenv(EnvSecretKey)
+	if accessKey == "" || secretKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	sa := &ServiceAccountCredentials{Endpoint: p.Endpoint, AccessKey: accessKey, SecretKey: secretKey, HTTPClient: p.HTTPClient}
+	return sa.Retrieve(ctx)
+}
+
+// FileCredentials reads a profile from an INI-style shared credentials file:
+//
+//	[default]
+//	api_key = ...
+//
+//	[ci]
+//	access_key = ...
+//	secret_key = ...
+//
+// Path defaults to MINIO_SHARED_CREDENTIALS_FILE, then ~/.minio/credentials;
+// Profile to MINIO_PROFILE, then "default". Key pairs are exchanged for a
+// session token at Endpoint.
+type FileCredentials struct {
+	Path       string
+	Profile    string
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (p *FileCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	path := p.Path
+	if path == "" {
+		path = os.Getenv(EnvCredentialsFile)
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, ErrNoCredentials
+		}
+		path = filepath.Join(home, ".minio", "credentials")
+	}
+	profile := p.Profile
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	values, err := readProfile(path, profile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if key := values["api_key"]; key != "" {
+		return Credentials{Token: key}, nil
+	}
+	if values["access_key"] == "" || values["secret_key"] == "" {
+		return Credentials{}, fmt.Errorf("profile %q in %s has neither api_key nor access_key and secret_key", profile, path)
+	}
+	sa := &ServiceAccountCredentials{Endpoint: p.Endpoint, AccessKey: values["access_key"],
+		SecretKey: values["secret_key"], HTTPClient: p.HTTPClient}
+	return sa.Retrieve(ctx)
+}
+
+// readProfile returns the key = value pairs of one [profile] section. A
+// missing file or profile is ErrNoCredentials.
+func readProfile(path, profile string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	defer f.Close()
+
+	var values map[string]string
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+			continue
+		case line[0] == '[' && line[len(line)-1] == ']':
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == profile && values == nil {
+				values = make(map[string]string)
+			}
+		case section == profile:
+			if k, v, ok := strings.Cut(line, "="); ok {
+				values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	if values == nil {
+		return nil, fmt.Errorf("%w: no profile %q in %s", ErrNoCredentials, profile, path)
+	}
+	return values, nil
+}
+
+// ContainerCredentials fetches a session token from the URL in
+// MINIO_CONTAINER_CREDENTIALS_URL, sending MINIO_CONTAINER_AUTHORIZATION_TOKEN
+// if set. The endpoint answers with the same access_token/expires_at
+// document as the server's credential endpoints.
+type ContainerCredentials struct {
+	// URL and AuthToken override the environment
+	URL        string
+	AuthToken  string
+	HTTPClient *http.Client
+}
+
+func (p *ContainerCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	url, token := p.URL, p.AuthToken
+	if url == "" {
+		url, token = os.Getenv(EnvContainerCredentialsURL), os.Getenv(EnvContainerAuthToken)
+	}
+	if url == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return fetchMetadataToken(ctx, p.HTTPClient, url, token)
+}
+
+// InstanceMetadataCredentials fetches a session token from the host's
+// metadata service at MINIO_METADATA_ENDPOINT (e.g.
+// http://169.254.169.254/minio/credentials). It is off unless configured,
+// so hosts without a metadata service do not wait on one.
+type InstanceMetadataCredentials struct {
+	// Endpoint overrides the environment
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func (p *InstanceMetadataCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv(EnvMetadataEndpoint)
+	}
+	if endpoint == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return fetchMetadataToken(ctx, p.HTTPClient, endpoint, "")
+}
+
+func fetchMetadataToken(ctx context.Context, client *http.Client, url, authToken string) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+	return fetchSessionToken(client, req)
+}
@@ -0,0 +1,184 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clearCredentialEnv isolates a test from credentials on the host
+func clearCredentialEnv(t *testing.T) {
+	for _, name := range []string{EnvAPIKey, EnvAccessKey, EnvSecretKey, EnvProfile,
+		EnvContainerCredentialsURL, EnvContainerAuthToken, EnvMetadataEndpoint} {
+		t.Setenv(name, "")
+	}
+	t.Setenv(EnvCredentialsFile, filepath.Join(t.TempDir(), "none"))
+}
+
+// tokenServer issues session-token for AK/SK basic auth on the service
+// account endpoint, and for wantAuth on any other path
+func tokenServer(t *testing.T, wantAuth string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/iam/service-accounts/credentials" {
+			if user, pass, _ := r.BasicAuth(); user != "AK" || pass != "SK" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		} else if r.Header.Get("Authorization") != wantAuth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "session-token", "expires_at": time.Now().Add(time.Hour)})
+	}))
+}
+
+func TestEnvCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	server := tokenServer(t, "")
+	defer server.Close()
+	p := &EnvCredentials{Endpoint: server.URL}
+
+	if _, err := p.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	t.Setenv(EnvAccessKey, "AK")
+	t.Setenv(EnvSecretKey, "SK")
+	if creds, err := p.Retrieve(context.Background()); err != nil || creds.Token != "session-token" {
+		t.Errorf("Key pair: got %+v, %v", creds, err)
+	}
+
+	t.Setenv(EnvAPIKey, "env-key")
+	if creds, err := p.Retrieve(context.Background()); err != nil || creds.Token != "env-key" || !creds.Expires.IsZero() {
+		t.Errorf("API key: got %+v, %v", creds, err)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	server := tokenServer(t, "")
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	os.WriteFile(path, []byte(`# shared credentials
+[default]
+api_key = file-key
+
+[ci]
+access_key = AK
+secret_key = SK
+
+[broken]
+access_key = AK
+`), 0o600)
+
+	tests := []struct {
+		profile string
+		token   string
+		noCreds bool
+	}{
+		{profile: "", token: "file-key"},
+		{profile: "ci", token: "session-token"},
+		{profile: "missing", noCreds: true},
+	}
+	for _, tt := range tests {
+		p := &FileCredentials{Path: path, Profile: tt.profile, Endpoint: server.URL}
+		creds, err := p.Retrieve(context.Background())
+		if tt.noCreds {
+			if !errors.Is(err, ErrNoCredentials) {
+				t.Errorf("Profile %q: expected ErrNoCredentials, got %v", tt.profile, err)
+			}
+			continue
+		}
+		if err != nil || creds.Token != tt.token {
+			t.Errorf("Profile %q: got %+v, %v", tt.profile, creds, err)
+		}
+	}
+
+	// Profile from the environment; an incomplete profile is an error, not skipped
+	t.Setenv(EnvProfile, "broken")
+	_, err := (&FileCredentials{Path: path}).Retrieve(context.Background())
+	if err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Broken profile: expected a configuration error, got %v", err)
+	}
+
+	if _, err := (&FileCredentials{Path: path + ".absent"}).Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Missing file: expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestContainerCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	server := tokenServer(t, "container-secret")
+	defer server.Close()
+
+	p := &ContainerCredentials{}
+	if _, err := p.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	t.Setenv(EnvContainerCredentialsURL, server.URL+"/creds")
+	t.Setenv(EnvContainerAuthToken, "container-secret")
+	if creds, err := p.Retrieve(context.Background()); err != nil || creds.Token != "session-token" || creds.Expires.IsZero() {
+		t.Errorf("Got %+v, %v", creds, err)
+	}
+
+	t.Setenv(EnvMetadataEndpoint, server.URL+"/instance")
+	if _, err := (&InstanceMetadataCredentials{}).Retrieve(context.Background()); err == nil {
+		t.Error("Expected instance endpoint to be refused without the container token")
+	}
+}
+
+func TestChainCredentials(t *testing.T) {
+	none := CredentialsFunc(func(ctx context.Context) (Credentials, error) { return Credentials{}, ErrNoCredentials })
+	broken := CredentialsFunc(func(ctx context.Context) (Credentials, error) { return Credentials{}, errors.New("broken") })
+
+	chain := &ChainCredentials{Providers: []CredentialsProvider{none, StaticCredentials("second"), broken}}
+	if creds, err := chain.Retrieve(context.Background()); err != nil || creds.Token != "second" {
+		t.Errorf("Got %+v, %v", creds, err)
+	}
+
+	chain = &ChainCredentials{Providers: []CredentialsProvider{none, broken, StaticCredentials("third")}}
+	if _, err := chain.Retrieve(context.Background()); err == nil || err.Error() != "broken" {
+		t.Errorf("Expected the broken provider's error, got %v", err)
+	}
+
+	chain = &ChainCredentials{Providers: []CredentialsProvider{none}}
+	if _, err := chain.Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestClient_DefaultCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer env-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, MaxRetries: 1, BackoffDuration: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	t.Setenv(EnvAPIKey, "env-key")
+	if err := client.Delete(context.Background(), "tenant1", "test.txt"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// RefererToken lets pages on one host link a public bucket's objects
+type RefererToken struct {
+	Token     string    `json:"token"`
+	TenantID  string    `json:"tenant_id"`
+	Bucket    string    `json:"bucket"`
+	Referer   string    `json:"referer"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateRefererToken issues a referer token for one of the tenant's
+// public buckets. referer is the host name or URL of the linking site; an
+// expiresIn of 0 takes the server's default lifetime.
+func (c *Client) CreateRefererToken(ctx context.Context, tenantID, bucket, referer string, expiresIn time.Duration, reqOpts ...RequestOption) (*RefererToken, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+	if bucket == "" || referer == "" {
+		return nil, fmt.Errorf("bucket and referer are required")
+	}
+	req := map[string]string{"bucket": bucket, "referer": referer}
+	if expiresIn > 0 {
+		req["expires_in"] = expiresIn.String()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token request: %w", err)
+	}
+
+	var token RefererToken
+	path := "/public/tokens?tenant_id=" + url.QueryEscape(tenantID)
+	if err := c.doWithRetry(ctx, "POST", path, bytes.NewReader(body), "application/json", &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// PublicURL returns the anonymous URL of an object in a public bucket, for
+// embedding in pages. token is the referer token for buckets that need
+// one, or "".
+func (c *Client) PublicURL(tenantID, key, token string) string {
+	q := url.Values{"tenant_id": {tenantID}, "key": {key}}
+	if token != "" {
+		q.Set("token", token)
+	}
+	return c.endpoint + APIVersionPrefix + "/public?" + q.Encode()
+}
@@ -0,0 +1,50 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_CreateRefererToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/public/tokens" || r.URL.Query().Get("tenant_id") != "tenant1" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["bucket"] != "assets" || req["referer"] != "shop.example.com" || req["expires_in"] != "24h0m0s" {
+			t.Errorf("Unexpected body %v, %v", req, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":"mr1.abc.def","tenant_id":"tenant1","bucket":"assets","referer":"shop.example.com","expires_at":"2026-01-02T00:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	token, err := client.CreateRefererToken(ctx, "tenant1", "assets", "shop.example.com", 24*time.Hour)
+	if err != nil || token.Token != "mr1.abc.def" || token.Referer != "shop.example.com" {
+		t.Fatalf("CreateRefererToken() = %+v, %v", token, err)
+	}
+	if _, err := client.CreateRefererToken(ctx, "tenant1", "", "shop.example.com", 0); err == nil {
+		t.Error("Expected an error for a missing bucket")
+	}
+
+	u, err := url.Parse(client.PublicURL("tenant1", "assets/logo 1.png", token.Token))
+	if err != nil || !strings.HasPrefix(u.String(), server.URL+"/v1/public?") {
+		t.Fatalf("PublicURL() = %v, %v", u, err)
+	}
+	if q := u.Query(); q.Get("key") != "assets/logo 1.png" || q.Get("token") != "mr1.abc.def" || q.Get("tenant_id") != "tenant1" {
+		t.Errorf("PublicURL() query = %v", q)
+	}
+}
@@ -0,0 +1,81 @@
+package minio
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultRetryBudget is the share of calls that may be retried
+	DefaultRetryBudget = 0.1
+
+	// retryBurst is the most retries the budget saves up
+	retryBurst = 10
+)
+
+// ErrRetryBudgetExhausted is returned, wrapping the last failure, when a
+// call could have retried but the client's retry budget was spent
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryStats counts a client's retries
+type RetryStats struct {
+	// Calls is the calls that could retry
+	Calls uint64
+
+	// Retries is the retries sent
+	Retries uint64
+
+	// Throttled is the retries the budget refused
+	Throttled uint64
+}
+
+// retryBudget meters retries across a client's calls: every call earns
+// budget tokens, up to retryBurst, and every retry spends one. It starts
+// with a full burst. A negative ratio leaves retries unmetered.
+type retryBudget struct {
+	ratio float64
+
+	mu     sync.Mutex
+	tokens float64
+
+	calls     atomic.Uint64
+	retries   atomic.Uint64
+	throttled atomic.Uint64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{ratio: ratio, tokens: retryBurst}
+}
+
+func (b *retryBudget) earn() {
+	b.calls.Add(1)
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, retryBurst)
+	b.mu.Unlock()
+}
+
+func (b *retryBudget) spend() bool {
+	if b.ratio < 0 {
+		b.retries.Add(1)
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		b.throttled.Add(1)
+		return false
+	}
+	b.tokens--
+	b.retries.Add(1)
+	return true
+}
+
+// RetryStats reports the client's retries
+func (c *Client) RetryStats() RetryStats {
+	return RetryStats{
+		Calls:     c.retries.calls.Load(),
+		Retries:   c.retries.retries.Load(),
+		Throttled: c.retries.throttled.Load(),
+	}
+}
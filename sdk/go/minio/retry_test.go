@@ -0,0 +1,64 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Against a server that always fails, retries stop at the budget and the
+// calls beyond it fail fast with the server's error
+func TestRetryBudgetThrottlesRetries(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		budget       float64
+		maxRequests  int64
+		wantThrottle bool
+	}{
+		{0, 10 + retryBurst + 1, true}, // Default budget: a burst, then a tenth of the calls
+		{-1, 40, false},                // Unmetered: every call retries in full
+	} {
+		requests.Store(0)
+		client, err := NewClient(Config{
+			Endpoint:        server.URL,
+			APIKey:          "test-api-key",
+			MaxRetries:      3,
+			BackoffDuration: time.Millisecond,
+			RetryBudget:     tc.budget,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var throttled int
+		for i := 0; i < 10; i++ {
+			err := client.Delete(context.Background(), "tenant", "key")
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("Budget %v: call %d returned %v, want the server's 503", tc.budget, i, err)
+			}
+			if errors.Is(err, ErrRetryBudgetExhausted) {
+				throttled++
+			}
+		}
+		client.Close()
+
+		st := client.RetryStats()
+		if n := requests.Load(); n > tc.maxRequests || n != int64(st.Calls+st.Retries) {
+			t.Errorf("Budget %v: %d requests, stats %+v, want at most %d", tc.budget, n, st, tc.maxRequests)
+		}
+		if (throttled > 0) != tc.wantThrottle || uint64(throttled) != st.Throttled {
+			t.Errorf("Budget %v: %d calls throttled, stats %+v", tc.budget, throttled, st)
+		}
+	}
+}
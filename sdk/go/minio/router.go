@@ -0,0 +1,242 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckInterval is how often a client with several endpoints
+// polls each node's health score
+const DefaultHealthCheckInterval = 10 * time.Second
+
+// NodeStatus is a client's view of one server node
+type NodeStatus struct {
+	Endpoint string
+
+	// Score is the weight requests are sent to the node with: the score it
+	// last reported, 0 while it is unreachable, and 1 before it is polled
+	Score     float64
+	Status    string
+	CheckedAt time.Time
+}
+
+// router spreads requests over a deployment's nodes in proportion to
+// their health scores
+type router struct {
+	client   *http.Client
+	interval time.Duration
+
+	mu    sync.Mutex
+	nodes []*NodeStatus
+	next  int // Round-robin position when every score is 0
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newRouter(endpoints []string, client *http.Client, interval time.Duration) *router {
+	r := &router{
+		client:   client,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, ep := range endpoints {
+		r.add(ep)
+	}
+	go r.run()
+	return r
+}
+
+// add registers endpoint unless it is known; the caller holds r.mu or has
+// not yet shared r
+func (r *router) add(endpoint string) {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if endpoint == "" {
+		return
+	}
+	for _, n := range r.nodes {
+		if n.Endpoint == endpoint {
+			return
+		}
+	}
+	r.nodes = append(r.nodes, &NodeStatus{Endpoint: endpoint, Score: 1})
+}
+
+// pick chooses the endpoint for a request, at random weighted by score.
+// If no node has a positive score every node is tried in turn, since a
+// request to a node of unknown state beats failing outright.
+func (r *router) pick() string {
+	return r.pickExcept("")
+}
+
+// pickExcept is pick avoiding the node at exclude, which it returns only
+// if no other node has a positive score
+func (r *router) pickExcept(exclude string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total float64
+	for _, n := range r.nodes {
+		if n.Endpoint != exclude {
+			total += n.Score
+		}
+	}
+	if total <= 0 {
+		if exclude != "" {
+			return exclude
+		}
+		r.next = (r.next + 1) % len(r.nodes)
+		return r.nodes[r.next].Endpoint
+	}
+	x := rand.Float64() * total
+	var last string
+	for _, n := range r.nodes {
+		if n.Endpoint == exclude || n.Score <= 0 {
+			continue
+		}
+		if x -= n.Score; x < 0 {
+			return n.Endpoint
+		}
+		last = n.Endpoint
+	}
+	// Rounding left x at the total
+	return last
+}
+
+// alternate returns url moved to another node, or url if there is none
+func (r *router) alternate(url string) string {
+	r.mu.Lock()
+	var from string
+	for _, n := range r.nodes {
+		if strings.HasPrefix(url, n.Endpoint+"/") {
+			from = n.Endpoint
+			break
+		}
+	}
+	r.mu.Unlock()
+	if from == "" {
+		return url
+	}
+	return r.pickExcept(from) + strings.TrimPrefix(url, from)
+}
+
+// failed marks the node serving url unreachable until its next poll
+func (r *router) failed(url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.nodes {
+		if strings.HasPrefix(url, n.Endpoint+"/") {
+			n.Score, n.Status = 0, "unreachable"
+		}
+	}
+}
+
+// run polls every node until close
+func (r *router) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.checkAll()
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkAll polls the nodes concurrently and adds any they report
+func (r *router) checkAll() {
+	r.mu.Lock()
+	endpoints := make([]string, len(r.nodes))
+	for i, n := range r.nodes {
+		endpoints[i] = n.Endpoint
+	}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), min(r.interval, 5*time.Second))
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, ep := range endpoints {
+		wg.Add(1)
+		go func(ep string) {
+			defer wg.Done()
+			health, err := r.check(ctx, ep)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			for _, n := range r.nodes {
+				if n.Endpoint != ep {
+					continue
+				}
+				n.CheckedAt = time.Now()
+				if err != nil {
+					n.Score, n.Status = 0, "unreachable"
+					break
+				}
+				n.Score, n.Status = min(max(health.Score, 0), 1), health.Status
+			}
+			if err == nil {
+				for _, node := range health.Nodes {
+					r.add(node)
+				}
+			}
+		}(ep)
+	}
+	wg.Wait()
+}
+
+// check fetches a node's health document
+func (r *router) check(ctx context.Context, endpoint string) (*HealthStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+APIVersionPrefix+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
+	var health HealthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// status returns a copy of every node's state
+func (r *router) status() []NodeStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]NodeStatus, len(r.nodes))
+	for i, n := range r.nodes {
+		out[i] = *n
+	}
+	return out
+}
+
+func (r *router) close() {
+	close(r.stop)
+	<-r.done
+}
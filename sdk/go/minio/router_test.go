@@ -0,0 +1,154 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNode is a server reporting a fixed health score and counting the
+// other requests it gets
+type testNode struct {
+	*httptest.Server
+	score    atomic.Value // float64
+	nodes    []string
+	requests atomic.Int64
+}
+
+func newTestNode(t *testing.T, score float64) *testNode {
+	t.Helper()
+	n := &testNode{}
+	n.score.Store(score)
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/health" {
+			json.NewEncoder(w).Encode(HealthStatus{Status: "ok", Score: n.score.Load().(float64), Nodes: n.nodes})
+			return
+		}
+		n.requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func waitForScores(t *testing.T, client *Client, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		checked := 0
+		for _, n := range client.Nodes() {
+			if !n.CheckedAt.IsZero() {
+				checked++
+			}
+		}
+		if checked >= want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Nodes not polled: %+v", client.Nodes())
+}
+
+func TestRouterWeighsNodesByScore(t *testing.T) {
+	busy, idle, draining := newTestNode(t, 0.2), newTestNode(t, 0.8), newTestNode(t, 0)
+
+	client, err := NewClient(Config{
+		Endpoint:            busy.URL,
+		Endpoints:           []string{idle.URL, draining.URL},
+		APIKey:              "test-api-key",
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	waitForScores(t, client, 3)
+
+	for i := 0; i < 1000; i++ {
+		if err := client.Delete(context.Background(), "tenant", "key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := draining.requests.Load(); n != 0 {
+		t.Errorf("Draining node got %d requests, want 0", n)
+	}
+	// Expect a 1:4 split; allow for chance
+	if b, i := busy.requests.Load(), idle.requests.Load(); b < 100 || b > 300 || b+i != 1000 {
+		t.Errorf("Busy node got %d requests and idle node %d, want about 200 and 800", b, i)
+	}
+}
+
+func TestRouterSkipsUnreachableNodes(t *testing.T) {
+	up := newTestNode(t, 1)
+	down := newTestNode(t, 1)
+
+	client, err := NewClient(Config{
+		Endpoint:            up.URL,
+		Endpoints:           []string{down.URL},
+		APIKey:              "test-api-key",
+		HealthCheckInterval: time.Hour,
+		MaxRetries:          1,
+		BackoffDuration:     time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	waitForScores(t, client, 2)
+	down.Close()
+
+	// A request sent to the dead node fails over on retry, and the node
+	// then gets no more
+	for i := 0; i < 50; i++ {
+		if err := client.Delete(context.Background(), "tenant", "key"); err != nil {
+			t.Fatalf("Delete %d: %v", i, err)
+		}
+	}
+	if n := up.requests.Load(); n != 50 {
+		t.Errorf("Healthy node served %d requests, want 50", n)
+	}
+	for _, n := range client.Nodes() {
+		if n.Endpoint == down.URL && n.Score != 0 {
+			t.Errorf("Unreachable node still scored %v", n.Score)
+		}
+	}
+}
+
+func TestRouterDiscoversClusterNodes(t *testing.T) {
+	other := newTestNode(t, 1)
+	seed := newTestNode(t, 1)
+	seed.nodes = []string{seed.URL, other.URL + "/"}
+
+	client, err := NewClient(Config{
+		Endpoint:            seed.URL,
+		DiscoverNodes:       true,
+		APIKey:              "test-api-key",
+		HealthCheckInterval: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	waitForScores(t, client, 2)
+
+	nodes := client.Nodes()
+	if len(nodes) != 2 || nodes[0].Endpoint != seed.URL || nodes[1].Endpoint != other.URL {
+		t.Fatalf("Nodes = %+v, want the seed and %s", nodes, other.URL)
+	}
+}
+
+func TestSingleEndpointHasNoRouter(t *testing.T) {
+	client, err := NewClient(Config{Endpoint: "http://localhost:9000", APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if nodes := client.Nodes(); nodes != nil {
+		t.Errorf("Nodes() = %+v, want nil", nodes)
+	}
+}
@@ -0,0 +1,194 @@
+package minio
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"sync"
+)
+
+// UploadStatus is the outcome of one file in a directory upload
+type UploadStatus string
+
+const (
+	UploadStatusUploaded UploadStatus = "uploaded"
+	UploadStatusSkipped  UploadStatus = "skipped" // Unchanged since the last upload
+	UploadStatusFailed   UploadStatus = "failed"
+)
+
+// UploadFSOptions controls a directory upload
+type UploadFSOptions struct {
+	// Prefix is prepended to each file's slash-separated relative path to
+	// form its key, e.g. "backups/2024/"
+	Prefix string
+
+	// Concurrency is the number of files uploaded at once (default: 4)
+	Concurrency int
+
+	// Force uploads every file, even those whose ETag already matches
+	Force bool
+}
+
+// FileUploadResult reports one file of a directory upload
+type FileUploadResult struct {
+	Path   string
+	Key    string
+	Size   int64
+	ETag   string
+	Status UploadStatus
+	Err    error
+}
+
+// UploadFSResult reports a directory upload, with one result per regular
+// file in walk order
+type UploadFSResult struct {
+	Files    []FileUploadResult
+	Uploaded int
+	Skipped  int
+	Failed   int
+}
+
+// UploadDirectory uploads the files under dir; see UploadFS
+func (c *Client) UploadDirectory(ctx context.Context, tenantID, dir string, opts *UploadFSOptions, reqOpts ...RequestOption) (*UploadFSResult, error) {
+	return c.UploadFS(ctx, tenantID, os.DirFS(dir), opts, reqOpts...)
+}
+
+// UploadFS uploads every regular file in fsys, keyed by its path under
+// opts.Prefix. Files whose MD5 matches the ETag of the object already at
+// their key are skipped, so repeated calls only send what changed. Files
+// succeed or fail independently; check each FileUploadResult. The error is
+// set only if the existing objects could not be listed or ctx ended.
+func (c *Client) UploadFS(ctx context.Context, tenantID string, fsys fs.FS, opts *UploadFSOptions, reqOpts ...RequestOption) (*UploadFSResult, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if opts == nil {
+		opts = &UploadFSOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	var remote map[string]string
+	if !opts.Force {
+		objects, err := c.listObjects(ctx, tenantID, opts.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		remote = make(map[string]string, len(objects))
+		for _, obj := range objects {
+			remote[obj.Key] = obj.ETag
+		}
+	}
+
+	result := &UploadFSResult{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil && p == "." {
+			return err
+		}
+		if err != nil {
+			// An unreadable directory fails, but does not stop the walk
+			result.Files = append(result.Files, FileUploadResult{Path: p, Key: opts.Prefix + p, Status: UploadStatusFailed, Err: err})
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			result.Files = append(result.Files, FileUploadResult{Path: p, Key: opts.Prefix + p})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				c.uploadFile(ctx, tenantID, fsys, &result.Files[i], remote)
+			}
+		}()
+	}
+	for i := range result.Files {
+		if result.Files[i].Status == "" {
+			indexes <- i
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, f := range result.Files {
+		switch f.Status {
+		case UploadStatusUploaded:
+			result.Uploaded++
+		case UploadStatusSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+	return result, ctx.Err()
+}
+
+// uploadFile uploads one file unless remote shows it unchanged, filling in f
+func (c *Client) uploadFile(ctx context.Context, tenantID string, fsys fs.FS, f *FileUploadResult, remote map[string]string) {
+	f.Status = UploadStatusFailed
+	if f.Err = ctx.Err(); f.Err != nil {
+		return
+	}
+
+	file, err := fsys.Open(f.Path)
+	if err != nil {
+		f.Err = err
+		return
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if f.Size, f.Err = io.Copy(hash, file); f.Err != nil {
+		return
+	}
+	f.ETag = hex.EncodeToString(hash.Sum(nil))
+	if etag, ok := remote[f.Key]; ok && etag == f.ETag {
+		f.Status = UploadStatusSkipped
+		return
+	}
+
+	// Rewind for the upload; files that cannot seek are opened again
+	var data io.Reader
+	if seeker, ok := file.(io.Seeker); ok {
+		if _, f.Err = seeker.Seek(0, io.SeekStart); f.Err != nil {
+			return
+		}
+		data = file
+	} else {
+		reopened, err := fsys.Open(f.Path)
+		if err != nil {
+			f.Err = err
+			return
+		}
+		defer reopened.Close()
+		data = reopened
+	}
+
+	uploadOpts := &UploadOptions{ContentType: mime.TypeByExtension(path.Ext(f.Path))}
+	if f.Err = c.Upload(ctx, tenantID, f.Key, data, uploadOpts); f.Err == nil {
+		f.Status = UploadStatusUploaded
+	}
+}
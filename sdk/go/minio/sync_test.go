@@ -0,0 +1,160 @@
+package minio
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// objectStore is a fake server holding one tenant's objects in memory,
+// listing them with their MD5 ETags two to a page
+type objectStore struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	modTimes map[string]time.Time
+	puts     []string
+	lists    int
+}
+
+func (s *objectStore) put(key string, data []byte) {
+	if s.modTimes == nil {
+		s.modTimes = make(map[string]time.Time)
+	}
+	s.objects[key] = data
+	s.modTimes[key] = time.Now()
+	s.puts = append(s.puts, key)
+}
+
+func (s *objectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+
+	switch r.URL.Path {
+	case "/v1/upload":
+		data, _ := io.ReadAll(r.Body)
+		s.put(query.Get("key"), data)
+		w.WriteHeader(http.StatusOK)
+	case "/v1/copy":
+		s.put(query.Get("key"), s.objects[query.Get("source_key")])
+		w.WriteHeader(http.StatusOK)
+	case "/v1/download":
+		w.Write(s.objects[query.Get("key")])
+	case "/v1/delete":
+		delete(s.objects, query.Get("key"))
+		w.WriteHeader(http.StatusNoContent)
+	case "/v1/list":
+		after := query.Get("continuation_token")
+		if after == "" {
+			after = query.Get("marker")
+		}
+		s.lists++
+		var keys []string
+		for key := range s.objects {
+			if strings.HasPrefix(key, query.Get("prefix")) && key > after {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		resp := ListResponse{}
+		if len(keys) > 2 {
+			keys = keys[:2]
+			resp.IsTruncated, resp.NextContinuationToken = true, keys[1]
+		}
+		for _, key := range keys {
+			sum := md5.Sum(s.objects[key])
+			resp.Objects = append(resp.Objects, Object{Key: key, Size: int64(len(s.objects[key])),
+				LastModified: s.modTimes[key], ETag: hex.EncodeToString(sum[:])})
+		}
+		json.NewEncoder(w).Encode(resp)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClient_UploadFS(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{
+		"site/index.html": []byte("<h1>old</h1>"),
+		"site/style.css":  []byte("body{}"),
+		"site/app.js":     []byte("run()"),
+	}}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte("<h1>new</h1>")},
+		"style.css":       {Data: []byte("body{}")},
+		"app.js":          {Data: []byte("run()")},
+		"img/logo.png":    {Data: []byte("png")},
+		"img/icons/a.svg": {Data: []byte("<svg/>")},
+	}
+
+	result, err := client.UploadFS(context.Background(), "tenant1", fsys, &UploadFSOptions{Prefix: "site/", Concurrency: 2})
+	if err != nil {
+		t.Fatalf("UploadFS() error = %v", err)
+	}
+	if result.Uploaded != 3 || result.Skipped != 2 || result.Failed != 0 {
+		t.Errorf("Expected 3 uploaded, 2 skipped, got %+v", result)
+	}
+	if len(result.Files) != 5 || result.Files[0].Path != "app.js" || result.Files[0].Key != "site/app.js" {
+		t.Errorf("Expected results in walk order with prefixed keys, got %+v", result.Files)
+	}
+	if got := string(store.objects["site/img/icons/a.svg"]); got != "<svg/>" {
+		t.Errorf("Expected nested file uploaded, got %q", got)
+	}
+
+	// A second pass has nothing to send
+	store.puts = nil
+	result, err = client.UploadFS(context.Background(), "tenant1", fsys, &UploadFSOptions{Prefix: "site/"})
+	if err != nil || result.Skipped != 5 || len(store.puts) != 0 {
+		t.Errorf("Expected everything skipped, got %+v, %v (puts %v)", result, err, store.puts)
+	}
+
+	result, err = client.UploadFS(context.Background(), "tenant1", fsys, &UploadFSOptions{Prefix: "site/", Force: true})
+	if err != nil || result.Uploaded != 5 {
+		t.Errorf("Expected forced upload of every file, got %+v, %v", result, err)
+	}
+}
+
+func TestClient_UploadDirectory(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{}}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	os.WriteFile(filepath.Join(dir, "docs", "readme.txt"), []byte("hello"), 0o644)
+
+	result, err := client.UploadDirectory(context.Background(), "tenant1", dir, nil)
+	if err != nil || result.Uploaded != 1 || string(store.objects["docs/readme.txt"]) != "hello" {
+		t.Errorf("Got %+v, %v", result, err)
+	}
+
+	if _, err := client.UploadDirectory(context.Background(), "tenant1", filepath.Join(dir, "absent"), nil); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+}
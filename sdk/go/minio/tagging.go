@@ -0,0 +1,77 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ObjectTagging is an object's tags at a version
+type ObjectTagging struct {
+	Key       string            `json:"key,omitempty"`
+	VersionID string            `json:"version_id,omitempty"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// attributeHeaders sends opts' metadata and tags with an upload
+func (opts *UploadOptions) attributeHeaders() []RequestOption {
+	var out []RequestOption
+	for k, v := range opts.Metadata {
+		out = append(out, WithHeader("X-Amz-Meta-"+k, v))
+	}
+	if len(opts.Tags) > 0 {
+		tags := url.Values{}
+		for k, v := range opts.Tags {
+			tags.Set(k, v)
+		}
+		out = append(out, WithHeader("X-Amz-Tagging", tags.Encode()))
+	}
+	return out
+}
+
+// GetObjectTagging returns an object's tags
+func (c *Client) GetObjectTagging(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) (*ObjectTagging, error) {
+	var tagging ObjectTagging
+	if err := c.taggingRequest(ctx, "GET", tenantID, key, nil, &tagging, reqOpts); err != nil {
+		return nil, err
+	}
+	return &tagging, nil
+}
+
+// PutObjectTagging replaces an object's tags. The object keeps its data
+// and version.
+func (c *Client) PutObjectTagging(ctx context.Context, tenantID, key string, tags map[string]string, reqOpts ...RequestOption) (*ObjectTagging, error) {
+	body, err := json.Marshal(ObjectTagging{Tags: tags})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tags: %w", err)
+	}
+	var tagging ObjectTagging
+	if err := c.taggingRequest(ctx, "PUT", tenantID, key, body, &tagging, reqOpts); err != nil {
+		return nil, err
+	}
+	return &tagging, nil
+}
+
+// DeleteObjectTagging removes an object's tags
+func (c *Client) DeleteObjectTagging(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) error {
+	return c.taggingRequest(ctx, "DELETE", tenantID, key, nil, nil, reqOpts)
+}
+
+func (c *Client) taggingRequest(ctx context.Context, method, tenantID, key string, body []byte, result interface{}, reqOpts []RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if key == "" {
+		return fmt.Errorf("object key is required")
+	}
+	path := fmt.Sprintf("/tagging?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+	if body == nil {
+		return c.doWithRetry(ctx, method, path, nil, "", result)
+	}
+	return c.doWithRetry(ctx, method, path, bytes.NewReader(body), "application/json", result)
+}
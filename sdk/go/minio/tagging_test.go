@@ -0,0 +1,75 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_UploadSendsMetadataAndTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Amz-Meta-Owner"); got != "ada" {
+			t.Errorf("X-Amz-Meta-Owner = %q", got)
+		}
+		if got := r.Header.Get("X-Amz-Tagging"); got != "class=archive&team=media+ops" {
+			t.Errorf("X-Amz-Tagging = %q", got)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	opts := &UploadOptions{Metadata: map[string]string{"owner": "ada"}, Tags: map[string]string{"class": "archive", "team": "media ops"}}
+	if err := client.Upload(context.Background(), "tenant1", "k", bytes.NewReader([]byte("x")), opts); err != nil {
+		t.Errorf("Upload() error = %v", err)
+	}
+}
+
+func TestClient_ObjectTagging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/tagging" || q.Get("tenant_id") != "tenant1" || q.Get("key") != "photos/a.jpg" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"key":"photos/a.jpg","version_id":"v1","tags":{"class":"archive"}}`))
+		case http.MethodPut:
+			var req ObjectTagging
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tags["class"] != "hot" {
+				t.Errorf("Unexpected body %+v, %v", req, err)
+			}
+			w.Write([]byte(`{"key":"photos/a.jpg","version_id":"v1","tags":{"class":"hot"}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if tagging, err := client.GetObjectTagging(ctx, "tenant1", "photos/a.jpg"); err != nil || tagging.Tags["class"] != "archive" {
+		t.Errorf("GetObjectTagging() = %+v, %v", tagging, err)
+	}
+	if tagging, err := client.PutObjectTagging(ctx, "tenant1", "photos/a.jpg", map[string]string{"class": "hot"}); err != nil || tagging.VersionID != "v1" {
+		t.Errorf("PutObjectTagging() = %+v, %v", tagging, err)
+	}
+	if err := client.DeleteObjectTagging(ctx, "tenant1", "photos/a.jpg"); err != nil {
+		t.Errorf("DeleteObjectTagging() = %v", err)
+	}
+	if _, err := client.GetObjectTagging(ctx, "tenant1", ""); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+}
@@ -0,0 +1,248 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPartSize is the range size used when the server does not
+	// advertise a chunk size
+	DefaultPartSize = 8 << 20
+
+	// DefaultConcurrency is the number of ranges a TransferManager fetches at once
+	DefaultConcurrency = 4
+)
+
+// ChunkLayout describes how an object should be split for parallel ranged
+// reads, from HEAD /download
+type ChunkLayout struct {
+	Size       int64
+	VersionID  string
+	ChunkSize  int64
+	ChunkCount int64
+}
+
+// ChunkLayout returns an object's size, version and the server's
+// preferred range size
+func (c *Client) ChunkLayout(ctx context.Context, tenantID, key string, reqOpts ...RequestOption) (*ChunkLayout, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("object key is required")
+	}
+
+	path := fmt.Sprintf("/download?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+
+	req, err := c.newRequest(ctx, "HEAD", path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("head failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("head failed: %w", parseError(resp))
+	}
+
+	layout := &ChunkLayout{Size: resp.ContentLength, VersionID: resp.Header.Get("X-Version-ID")}
+	layout.ChunkSize, _ = strconv.ParseInt(resp.Header.Get("X-Chunk-Size"), 10, 64)
+	layout.ChunkCount, _ = strconv.ParseInt(resp.Header.Get("X-Chunk-Count"), 10, 64)
+	if layout.Size < 0 {
+		return nil, fmt.Errorf("head failed: server did not report the object size")
+	}
+	return layout, nil
+}
+
+// DownloadRange downloads length bytes of an object from offset. A
+// non-empty versionID makes the request fail with ErrPreconditionFailed
+// if the object has since been overwritten.
+func (c *Client) DownloadRange(ctx context.Context, tenantID, key string, offset, length int64, versionID string, reqOpts ...RequestOption) (io.ReadCloser, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant ID is required")
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("object key is required")
+	}
+
+	if offset < 0 || length <= 0 {
+		return nil, fmt.Errorf("invalid range %d+%d", offset, length)
+	}
+
+	path := fmt.Sprintf("/download?tenant_id=%s&key=%s", url.QueryEscape(tenantID), url.QueryEscape(key))
+
+	ctx, cancel := withOptions(ctx, reqOpts)
+	req, err := c.newRequest(ctx, "GET", path, nil, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	if versionID != "" {
+		req.Header.Set("If-Match", versionID)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		defer cancel()
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil, fmt.Errorf("download failed: server ignored the range request")
+		}
+		return nil, fmt.Errorf("download failed: %w", parseError(resp))
+	}
+
+	return &cancelOnClose{resp.Body, cancel}, nil
+}
+
+// TransferManager downloads objects as parallel ranged reads aligned with
+// the server's chunk layout. Every range is pinned to the version seen when
+// the download started.
+type TransferManager struct {
+	client *Client
+
+	// PartSize overrides the server's chunk size
+	PartSize int64
+
+	// Concurrency is the number of ranges in flight (default: 4)
+	Concurrency int
+}
+
+// NewTransferManager creates a TransferManager using client
+func NewTransferManager(client *Client) *TransferManager {
+	return &TransferManager{client: client, Concurrency: DefaultConcurrency}
+}
+
+// Download writes an object to w, returning its size
+func (tm *TransferManager) Download(ctx context.Context, tenantID, key string, w io.WriterAt, reqOpts ...RequestOption) (int64, error) {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	layout, err := tm.client.ChunkLayout(ctx, tenantID, key)
+	if err != nil {
+		return 0, err
+	}
+	if layout.Size == 0 {
+		return 0, nil
+	}
+
+	partSize := tm.PartSize
+	if partSize <= 0 {
+		partSize = layout.ChunkSize
+	}
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	concurrency := tm.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel = context.WithCancel(ctx)
+	defer cancel()
+
+	offsets := make(chan int64)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				length := min(partSize, layout.Size-offset)
+				if err := tm.downloadPart(ctx, tenantID, key, layout.VersionID, offset, length, w); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	// Ranges are handed out in order so the server sees reads walk forward
+feed:
+	for offset := int64(0); offset < layout.Size; offset += partSize {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return layout.Size, nil
+}
+
+// downloadPart fetches one range, retrying transient failures
+func (tm *TransferManager) downloadPart(ctx context.Context, tenantID, key, versionID string, offset, length int64, w io.WriterAt) error {
+	policy := tm.client.retryPolicy(ctx)
+	backoff := policy.Backoff
+	var lastErr error
+	tm.client.retries.earn()
+
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if !tm.client.retries.spend() {
+				return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= DefaultBackoffMultiplier
+		}
+
+		body, err := tm.client.DownloadRange(ctx, tenantID, key, offset, length, versionID)
+		if err != nil {
+			var apiErr *Error
+			if errors.As(err, &apiErr) && !tm.client.shouldRetry(apiErr.StatusCode) {
+				return err
+			}
+			lastErr = err
+			continue
+		}
+
+		n, err := io.Copy(io.NewOffsetWriter(w, offset), io.LimitReader(body, length))
+		body.Close()
+		if err == nil && n < length {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("download failed: %w", err)
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
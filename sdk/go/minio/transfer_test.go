@@ -0,0 +1,122 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rangeServer serves data as version v1 with the given chunk size,
+// failing the first GET of failOffset with a 503
+func rangeServer(t *testing.T, data []byte, chunkSize int64, failOffset int64) (*httptest.Server, *atomic.Int32) {
+	var gets atomic.Int32
+	var failed atomic.Bool
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tenant_id") != "tenant1" {
+			t.Errorf("Expected tenant_id tenant1, got %s", r.URL.Query().Get("tenant_id"))
+		}
+		w.Header().Set("X-Version-ID", "v1")
+
+		if r.Method == "HEAD" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Header().Set("X-Chunk-Size", strconv.FormatInt(chunkSize, 10))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		gets.Add(1)
+		if m := r.Header.Get("If-Match"); m != "v1" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var start, end int64
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("Bad Range header %q", r.Header.Get("Range"))
+		}
+		if start == failOffset && !failed.Swap(true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start : end+1])
+	})), &gets
+}
+
+func TestTransferManager_Download(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000) // 16000 bytes
+	server, gets := rangeServer(t, data, 4096, 8192)
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Endpoint:        server.URL,
+		APIKey:          "test-api-key",
+		BackoffDuration: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	n, err := NewTransferManager(client).Download(context.Background(), "tenant1", "big.bin", f)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("Expected %d bytes, got %d", len(data), n)
+	}
+	got, _ := os.ReadFile(f.Name())
+	if !bytes.Equal(got, data) {
+		t.Error("Downloaded data does not match")
+	}
+
+	// Four 4096-byte chunks, one retried
+	if gets.Load() != 5 {
+		t.Errorf("Expected 5 range requests, got %d", gets.Load())
+	}
+}
+
+func TestTransferManager_VersionChanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Content-Length", "100")
+			w.Header().Set("X-Version-ID", "v1")
+			return
+		}
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte(`{"code":"PreconditionFailed","message":"Object version does not match"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = NewTransferManager(client).Download(context.Background(), "tenant1", "big.bin", f)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Usage is a tenant's current usage against its quotas; a quota of 0 is
+// unlimited. Bandwidth is ingress (uploads) plus egress (downloads).
+type Usage struct {
+	TenantID       string    `json:"tenant_id"`
+	Objects        int64     `json:"objects"`
+	StorageUsed    int64     `json:"storage_used"`
+	StorageQuota   int64     `json:"storage_quota"`
+	BandwidthUsed  int64     `json:"bandwidth_used"`
+	BandwidthQuota int64     `json:"bandwidth_quota"`
+	IngressUsed    int64     `json:"ingress_used"`
+	IngressQuota   int64     `json:"ingress_quota"`
+	EgressUsed     int64     `json:"egress_used"`
+	EgressQuota    int64     `json:"egress_quota"`
+	Requests       int64     `json:"requests"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DailyUsage is a tenant's usage over one UTC day
+type DailyUsage struct {
+	Date           string `json:"date"`          // YYYY-MM-DD
+	StorageBytes   int64  `json:"storage_bytes"` // At the day's last sample
+	StoragePeak    int64  `json:"storage_peak_bytes"`
+	Requests       int64  `json:"requests"`
+	BandwidthBytes int64  `json:"bandwidth_bytes"`
+	IngressBytes   int64  `json:"ingress_bytes"`
+	EgressBytes    int64  `json:"egress_bytes"`
+}
+
+// UsageHistory is a tenant's daily usage between two dates. Days the
+// server recorded nothing on are missing.
+type UsageHistory struct {
+	TenantID string       `json:"tenant_id"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Days     []DailyUsage `json:"days"`
+}
+
+// Resolutions of usage series
+const (
+	ResolutionMinute = "1m"
+	ResolutionHour   = "1h"
+	ResolutionDay    = "1d"
+)
+
+// UsageSeriesKey names a usage series; Bucket is empty for the tenant's
+// totals
+type UsageSeriesKey struct {
+	TenantID string `json:"tenant_id"`
+	Bucket   string `json:"bucket,omitempty"`
+	Metric   string `json:"metric"`
+}
+
+// UsagePoint is one interval of a usage series. Counters such as
+// requests hold the interval's total; gauges such as storage_bytes hold
+// the last sample and the highest.
+type UsagePoint struct {
+	Time  time.Time `json:"time"` // Start of the interval
+	Value int64     `json:"value"`
+	Max   int64     `json:"max,omitempty"`
+}
+
+// UsageSeries is one rolled up usage metric
+type UsageSeries struct {
+	UsageSeriesKey
+	Resolution string       `json:"resolution"`
+	Points     []UsagePoint `json:"points"`
+}
+
+// UsageSeriesOptions selects a usage series. Zero values leave the
+// server's defaults: the tenant's totals at hourly resolution over the
+// whole retention.
+type UsageSeriesOptions struct {
+	Bucket     string
+	Resolution string
+	From       time.Time
+	To         time.Time
+}
+
+// BucketUsage breaks a tenant's storage down by bucket, the first
+// "/"-separated segment of its keys
+type BucketUsage struct {
+	TenantID          string         `json:"tenant_id"`
+	Buckets           []BucketTotals `json:"buckets"`
+	UnbucketedObjects int64          `json:"unbucketed_objects"` // Keys without a "/"
+	UnbucketedBytes   int64          `json:"unbucketed_bytes"`
+}
+
+// BucketTotals are one bucket's object count and bytes
+type BucketTotals struct {
+	Bucket  string `json:"bucket"`
+	Created bool   `json:"created"` // False for a key prefix no bucket was created for
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// GetUsage reports the tenant's current usage. Like the other usage
+// calls, it needs credentials for the tenant.
+func (c *Client) GetUsage(ctx context.Context, tenantID string, reqOpts ...RequestOption) (*Usage, error) {
+	var usage Usage
+	if err := c.getUsage(ctx, "/usage", tenantID, nil, &usage, reqOpts); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// GetUsageHistory reports the tenant's daily usage for the UTC days from
+// through to. A zero from or to leaves the server's default: the 30 days
+// ending today.
+func (c *Client) GetUsageHistory(ctx context.Context, tenantID string, from, to time.Time, reqOpts ...RequestOption) (*UsageHistory, error) {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format("2006-01-02"))
+	}
+	var history UsageHistory
+	if err := c.getUsage(ctx, "/usage/history", tenantID, query, &history, reqOpts); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// GetUsageSeries reports one rolled up usage metric of the tenant, such
+// as "storage_bytes", "requests" or "egress_bytes"
+func (c *Client) GetUsageSeries(ctx context.Context, tenantID, metric string, opts UsageSeriesOptions, reqOpts ...RequestOption) (*UsageSeries, error) {
+	if metric == "" {
+		return nil, fmt.Errorf("metric is required")
+	}
+	query := url.Values{}
+	query.Set("metric", metric)
+	if opts.Bucket != "" {
+		query.Set("bucket", opts.Bucket)
+	}
+	if opts.Resolution != "" {
+		query.Set("resolution", opts.Resolution)
+	}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339))
+	}
+	var series UsageSeries
+	if err := c.getUsage(ctx, "/usage/series", tenantID, query, &series, reqOpts); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// ListUsageSeries lists the usage series the server holds for the tenant
+func (c *Client) ListUsageSeries(ctx context.Context, tenantID string, reqOpts ...RequestOption) ([]UsageSeriesKey, error) {
+	var result struct {
+		Series []UsageSeriesKey `json:"series"`
+	}
+	if err := c.getUsage(ctx, "/usage/series", tenantID, nil, &result, reqOpts); err != nil {
+		return nil, err
+	}
+	return result.Series, nil
+}
+
+// GetBucketUsage reports the tenant's objects and bytes per bucket
+func (c *Client) GetBucketUsage(ctx context.Context, tenantID string, reqOpts ...RequestOption) (*BucketUsage, error) {
+	var usage BucketUsage
+	if err := c.getUsage(ctx, "/usage/buckets", tenantID, nil, &usage, reqOpts); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (c *Client) getUsage(ctx context.Context, path, tenantID string, query url.Values, result interface{}, reqOpts []RequestOption) error {
+	ctx, cancel := withOptions(ctx, reqOpts)
+	defer cancel()
+
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("tenant_id", tenantID)
+	return c.doWithRetry(ctx, "GET", path+"?"+query.Encode(), nil, "", result)
+}
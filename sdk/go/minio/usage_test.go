@@ -0,0 +1,77 @@
+package minio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("tenant_id") != "tenant1" || r.Header.Get("Authorization") != "Bearer test-api-key" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch r.URL.Path {
+		case "/v1/usage":
+			w.Write([]byte(`{"tenant_id":"tenant1","objects":3,"storage_used":300,"storage_quota":1000,"requests":9}`))
+		case "/v1/usage/history":
+			if q.Get("from") != "2026-03-01" || q.Get("to") != "2026-03-02" {
+				t.Errorf("Expected from and to, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"tenant_id":"tenant1","from":"2026-03-01","to":"2026-03-02","days":[{"date":"2026-03-02","storage_bytes":300,"storage_peak_bytes":400,"requests":9,"bandwidth_bytes":50}]}`))
+		case "/v1/usage/series":
+			if q.Get("metric") == "" {
+				w.Write([]byte(`{"tenant_id":"tenant1","series":[{"tenant_id":"tenant1","metric":"requests"},{"tenant_id":"tenant1","bucket":"photos","metric":"storage_bytes"}]}`))
+				return
+			}
+			if q.Get("metric") != "storage_bytes" || q.Get("bucket") != "photos" || q.Get("resolution") != "1d" || q.Get("from") != "2026-03-01T00:00:00Z" {
+				t.Errorf("Unexpected series query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"tenant_id":"tenant1","bucket":"photos","metric":"storage_bytes","resolution":"1d","points":[{"time":"2026-03-01T00:00:00Z","value":200,"max":250}]}`))
+		case "/v1/usage/buckets":
+			w.Write([]byte(`{"tenant_id":"tenant1","buckets":[{"bucket":"photos","objects":2,"bytes":200}],"unbucketed_objects":1,"unbucketed_bytes":100}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Endpoint: server.URL, APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	usage, err := client.GetUsage(ctx, "tenant1")
+	if err != nil || usage.Objects != 3 || usage.StorageQuota != 1000 || usage.Requests != 9 {
+		t.Errorf("GetUsage() = %+v, %v", usage, err)
+	}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	history, err := client.GetUsageHistory(ctx, "tenant1", from, from.AddDate(0, 0, 1))
+	if err != nil || len(history.Days) != 1 || history.Days[0].StoragePeak != 400 {
+		t.Errorf("GetUsageHistory() = %+v, %v", history, err)
+	}
+
+	series, err := client.GetUsageSeries(ctx, "tenant1", "storage_bytes", UsageSeriesOptions{Bucket: "photos", Resolution: ResolutionDay, From: from})
+	if err != nil || series.Bucket != "photos" || len(series.Points) != 1 || series.Points[0].Max != 250 {
+		t.Errorf("GetUsageSeries() = %+v, %v", series, err)
+	}
+	keys, err := client.ListUsageSeries(ctx, "tenant1")
+	if err != nil || len(keys) != 2 || keys[1].Bucket != "photos" {
+		t.Errorf("ListUsageSeries() = %+v, %v", keys, err)
+	}
+
+	buckets, err := client.GetBucketUsage(ctx, "tenant1")
+	if err != nil || len(buckets.Buckets) != 1 || buckets.Buckets[0].Bucket != "photos" || buckets.UnbucketedBytes != 100 {
+		t.Errorf("GetBucketUsage() = %+v, %v", buckets, err)
+	}
+
+	if _, err := client.GetUsage(ctx, ""); err == nil {
+		t.Error("Expected an error without a tenant ID")
+	}
+}
@@ -2,8 +2,8 @@
 
 import time
 from dataclasses import dataclass
-from typing import BinaryIO, Dict, Optional
-from urllib.parse import quote, urljoin
+from typing import BinaryIO, Dict, Iterator, Optional
+from urllib.parse import quote, urlencode, urljoin
 
 import requests
 from requests.adapters import HTTPAdapter
@@ -46,7 +46,11 @@ class ListOptions:
     """Options for list operation"""
 
     prefix: Optional[str] = None
-    max_keys: Optional[int] = None
+    max_keys: Optional[int] = None  # Page size, default 1000, at most 10000
+    delimiter: Optional[str] = None  # Roll keys up into common_prefixes
+    continuation_token: Optional[str] = None  # Resume after a previous page
+    start_after: Optional[str] = None  # List only keys after it
+    strict: bool = False  # Wait until earlier writes have replicated
 
 
 class Client:
@@ -133,7 +137,7 @@ class Client:
         if options is None:
             options = UploadOptions()
 
-        url = f"{self.endpoint}/upload?tenant_id={quote(tenant_id)}&key={quote(key)}"
+        url = f"{self.endpoint}/v1/upload?tenant_id={quote(tenant_id)}&key={quote(key)}"
 
         headers = {}
         if options.content_type:
@@ -170,7 +174,7 @@ class Client:
         if not key:
             raise ValidationError("key is required")
 
-        url = f"{self.endpoint}/download?tenant_id={quote(tenant_id)}&key={quote(key)}"
+        url = f"{self.endpoint}/v1/download?tenant_id={quote(tenant_id)}&key={quote(key)}"
 
         try:
             response = self.session.get(url, timeout=self.timeout, verify=self.verify_ssl)
@@ -196,7 +200,7 @@ class Client:
         if not key:
             raise ValidationError("key is required")
 
-        url = f"{self.endpoint}/delete?tenant_id={quote(tenant_id)}&key={quote(key)}"
+        url = f"{self.endpoint}/v1/delete?tenant_id={quote(tenant_id)}&key={quote(key)}"
 
         try:
             response = self.session.delete(url, timeout=self.timeout, verify=self.verify_ssl)
@@ -224,13 +228,20 @@ class Client:
         if options is None:
             options = ListOptions()
 
-        url = f"{self.endpoint}/list?tenant_id={quote(tenant_id)}"
-
+        params = {"tenant_id": tenant_id}
         if options.prefix:
-            url += f"&prefix={quote(options.prefix)}"
-
+            params["prefix"] = options.prefix
         if options.max_keys:
-            url += f"&max_keys={options.max_keys}"
+            params["max_keys"] = str(options.max_keys)
+        if options.delimiter:
+            params["delimiter"] = options.delimiter
+        if options.continuation_token:
+            params["continuation_token"] = options.continuation_token
+        elif options.start_after:
+            params["marker"] = options.start_after
+        if options.strict:
+            params["strict"] = "true"
+        url = f"{self.endpoint}/v1/list?{urlencode(params)}"
 
         try:
             response = self.session.get(url, timeout=self.timeout, verify=self.verify_ssl)
@@ -239,6 +250,29 @@ class Client:
         except requests.RequestException as e:
             raise MinIOError(f"List failed: {str(e)}")
 
+    def list_all(self, tenant_id: str, options: Optional[ListOptions] = None) -> Iterator[Object]:
+        """Iterate over every object, fetching pages as needed
+
+        Keys that exist for the whole iteration are yielded exactly once, in
+        key order. With a delimiter, only the objects at the prefix's level
+        are yielded; use list() to see common_prefixes.
+
+        Args:
+            tenant_id: Tenant identifier
+            options: List options; continuation_token is managed here
+
+        Raises:
+            ValidationError: If parameters are invalid
+            MinIOError: If a page fails
+        """
+        opts = ListOptions(**vars(options)) if options else ListOptions()
+        while True:
+            page = self.list(tenant_id, opts)
+            yield from page.objects
+            if not page.is_truncated or not page.next_continuation_token:
+                return
+            opts.continuation_token = page.next_continuation_token
+
     def get_quota(self, tenant_id: str) -> QuotaInfo:
         """Get quota information for tenant
 
@@ -255,7 +289,7 @@ class Client:
         if not tenant_id:
             raise ValidationError("tenant_id is required")
 
-        url = f"{self.endpoint}/quota?tenant_id={quote(tenant_id)}"
+        url = f"{self.endpoint}/v1/quota?tenant_id={quote(tenant_id)}"
 
         try:
             response = self.session.get(url, timeout=self.timeout, verify=self.verify_ssl)
@@ -312,44 +346,42 @@ class Client:
             return  # Success
 
         error_body = response.text
+        code, message, resource = None, error_body, None
+        request_id = response.headers.get("X-Request-ID")
+        try:
+            doc = response.json()
+            if isinstance(doc, dict) and doc.get("code"):
+                code = doc["code"]
+                message = doc.get("message", "")
+                resource = doc.get("resource")
+                request_id = doc.get("requestId") or request_id
+        except ValueError:
+            pass
+
+        kwargs = dict(
+            status_code=response.status_code,
+            response_body=error_body,
+            code=code,
+            resource=resource,
+            request_id=request_id,
+        )
+
+        if code == "QuotaExceeded" or (code is None and "quota exceeded" in error_body.lower()):
+            raise QuotaExceededError(f"Quota exceeded: {message}", **kwargs)
 
         if response.status_code in (401, 403):
-            raise AuthenticationError(
-                f"Authentication failed: {error_body}",
-                status_code=response.status_code,
-                response_body=error_body,
-            )
+            raise AuthenticationError(f"Authentication failed: {message}", **kwargs)
 
         if response.status_code == 404:
-            raise NotFoundError(
-                f"Resource not found: {error_body}",
-                status_code=response.status_code,
-                response_body=error_body,
-            )
+            raise NotFoundError(f"Resource not found: {message}", **kwargs)
 
-        if response.status_code == 429:
-            raise RateLimitError(
-                f"Rate limit exceeded: {error_body}",
-                status_code=response.status_code,
-                response_body=error_body,
-            )
-
-        if "quota exceeded" in error_body.lower():
-            raise QuotaExceededError(
-                f"Quota exceeded: {error_body}",
-                status_code=response.status_code,
-                response_body=error_body,
-            )
+        if response.status_code == 429 or code == "SlowDown":
+            raise RateLimitError(f"Rate limit exceeded: {message}", **kwargs)
 
         if response.status_code >= 500:
-            raise ServerError(
-                f"Server error: {error_body}",
-                status_code=response.status_code,
-                response_body=error_body,
-            )
+            raise ServerError(f"Server error: {message}", **kwargs)
 
         raise MinIOError(
-            f"Request failed with status {response.status_code}: {error_body}",
-            status_code=response.status_code,
-            response_body=error_body,
+            f"Request failed with status {response.status_code}: {message}",
+            **kwargs,
         )
@@ -4,10 +4,21 @@
 class MinIOError(Exception):
     """Base exception for all MinIO SDK errors"""
 
-    def __init__(self, message, status_code=None, response_body=None):
+    def __init__(
+        self,
+        message,
+        status_code=None,
+        response_body=None,
+        code=None,
+        resource=None,
+        request_id=None,
+    ):
         super().__init__(message)
         self.status_code = status_code
         self.response_body = response_body
+        self.code = code
+        self.resource = resource
+        self.request_id = request_id
 
 
 class AuthenticationError(MinIOError):
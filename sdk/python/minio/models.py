@@ -1,6 +1,6 @@
 """MinIO SDK data models"""
 
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from datetime import datetime
 from typing import List, Optional
 
@@ -14,6 +14,7 @@ class Object:
     last_modified: datetime
     content_type: str = ""
     etag: str = ""
+    version_id: str = ""
 
     @classmethod
     def from_dict(cls, data: dict) -> "Object":
@@ -31,6 +32,7 @@ class Object:
             last_modified=last_modified,
             content_type=data.get("content_type", ""),
             etag=data.get("etag", ""),
+            version_id=data.get("version_id", ""),
         )
 
 
@@ -40,12 +42,21 @@ class ListResponse:
 
     objects: List[Object]
     count: int
+    is_truncated: bool = False
+    next_continuation_token: str = ""  # Pass as ListOptions.continuation_token
+    common_prefixes: List[str] = field(default_factory=list)
 
     @classmethod
     def from_dict(cls, data: dict) -> "ListResponse":
         """Create ListResponse from dictionary"""
         objects = [Object.from_dict(obj) for obj in data.get("objects", [])]
-        return cls(objects=objects, count=data.get("count", 0))
+        return cls(
+            objects=objects,
+            count=data.get("count", 0),
+            is_truncated=data.get("is_truncated", False),
+            next_continuation_token=data.get("next_continuation_token", ""),
+            common_prefixes=data.get("common_prefixes") or [],
+        )
 
 
 @dataclass